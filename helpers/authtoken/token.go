@@ -27,6 +27,14 @@ const (
 type EpinioClaims struct {
 	jwt.RegisteredClaims
 	Username string `json:"user"`
+	// Role, Namespaces and NamespaceRoles carry the access of the user, which may differ
+	// from the one of their Epinio user, if they have one at all.
+	Role           string            `json:"role,omitempty"`
+	Namespaces     []string          `json:"namespaces,omitempty"`
+	NamespaceRoles map[string]string `json:"namespace_roles,omitempty"`
+	// Scope is the scope of the API token the token was requested with, if any. Tokens of
+	// read-only API tokens do not get into applications.
+	Scope string `json:"scope,omitempty"`
 }
 
 func init() {
@@ -43,6 +51,13 @@ func init() {
 // WARNING: It should only be used to establish the websocket connection once,
 // because we can't revoke and don't check for deleted users.
 func Create(user string, s time.Duration) string {
+	return CreateWithAccess(user, "", nil, nil, "", s)
+}
+
+// CreateWithAccess is Create for users whose access is not the one of their Epinio user, if
+// they have one at all. The token carries their role, namespaces, and roles in these, and the
+// scope of the API token they authenticated with.
+func CreateWithAccess(user, role string, namespaces []string, namespaceRoles map[string]string, scope string, s time.Duration) string {
 	// seriously, don't use a long expiry time with this code
 	if s > maxExpiry {
 		return ""
@@ -52,7 +67,11 @@ func Create(user string, s time.Duration) string {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s)),
 			Issuer:    "epinio-server",
 		},
-		Username: user,
+		Username:       user,
+		Role:           role,
+		Namespaces:     namespaces,
+		NamespaceRoles: namespaceRoles,
+		Scope:          scope,
 	}
 
 	token := jwt.NewWithClaims(alg, claims)
@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/pkg/errors"
@@ -40,16 +41,30 @@ const (
 )
 
 var (
-	EpinioNamespaceLabelKey     = "app.kubernetes.io/component"
-	EpinioNamespaceLabelValue   = "epinio-namespace"
-	EpinioAPISecretLabelKey     = fmt.Sprintf("%s/%s", APISGroupName, "api-user-credentials")
-	EpinioAPISecretLabelValue   = "true"
-	EpinioAPISecretRoleLabelKey = fmt.Sprintf("%s/%s", APISGroupName, "role")
+	EpinioNamespaceLabelKey      = "app.kubernetes.io/component"
+	EpinioNamespaceLabelValue    = "epinio-namespace"
+	EpinioAPISecretLabelKey      = fmt.Sprintf("%s/%s", APISGroupName, "api-user-credentials")
+	EpinioAPISecretLabelValue    = "true"
+	EpinioAPISecretRoleLabelKey  = fmt.Sprintf("%s/%s", APISGroupName, "role")
+	EpinioAPITokenLabelKey       = fmt.Sprintf("%s/%s", APISGroupName, "api-token")
+	EpinioAPITokenLabelValue     = "true"
+	EpinioTeamLabelKey           = fmt.Sprintf("%s/%s", APISGroupName, "team")
+	EpinioTeamLabelValue         = "true"
+	EpinioSessionLabelKey        = fmt.Sprintf("%s/%s", APISGroupName, "session")
+	EpinioSessionLabelValue      = "true"
+	EpinioWebhookLabelKey        = fmt.Sprintf("%s/%s", APISGroupName, "webhook")
+	EpinioWebhookLabelValue      = "true"
+	EpinioNotificationLabelKey   = fmt.Sprintf("%s/%s", APISGroupName, "notification-target")
+	EpinioNotificationLabelValue = "true"
 )
 
 // Memoization of GetCluster
 var clusterMemo *Cluster
 
+// WrapTransport, if set, wraps the transport of the clients of the cluster, e.g. to trace
+// their requests. It has to be set before the first call of GetCluster.
+var WrapTransport func(http.RoundTripper) http.RoundTripper
+
 type Platform interface {
 	Detect(context.Context, *kubernetes.Clientset) bool
 	Describe() string
@@ -88,6 +103,11 @@ func GetCluster(ctx context.Context) (*Cluster, error) {
 		return nil, err
 	}
 
+	if WrapTransport != nil {
+		restConfig = restclient.CopyConfig(restConfig)
+		restConfig.Wrap(WrapTransport)
+	}
+
 	// copy to avoid mutating the passed-in config
 	config := restclient.CopyConfig(restConfig)
 	// set the warning handler for this client to ignore warnings
@@ -1,44 +1,151 @@
 package helpers
 
 import (
+	"bufio"
+	"io"
 	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/mholt/archiver/v3"
 	"github.com/pkg/errors"
 )
 
+// IgnoreFile is the name of the file listing the files and directories of the app sources
+// to leave out of the archive, in gitignore syntax.
+const IgnoreFile = ".epinioignore"
+
+// Tar archives the app sources in the directory. Git config files, and the files matched by
+// the patterns of the directory's ignore file are left out.
 func Tar(dir string) (string, string, error) {
+	// create a tmpDir - tarball dir and POST
+	tmpDir, err := ioutil.TempDir("", "epinio-app")
+	if err != nil {
+		return "", "", errors.Wrap(err, "can't create temp directory")
+	}
+
+	tarball := path.Join(tmpDir, "blob.tar")
+	out, err := os.Create(tarball)
+	if err != nil {
+		return tmpDir, "", errors.Wrap(err, "can't create archive")
+	}
+	defer out.Close()
+
+	tar := archiver.NewTar()
+	if err := tar.Create(out); err != nil {
+		return tmpDir, "", errors.Wrap(err, "can't create archive")
+	}
+
+	err = WalkSources(dir, func(name, fpath string, info os.FileInfo) error {
+		var file io.ReadCloser
+		if info.Mode().IsRegular() {
+			f, err := os.Open(fpath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			file = f
+		}
+
+		return tar.Write(archiver.File{
+			FileInfo: archiver.FileInfo{
+				FileInfo:   info,
+				CustomName: name,
+				SourcePath: fpath,
+			},
+			ReadCloser: file,
+		})
+	})
+	if err != nil {
+		return tmpDir, "", errors.Wrap(err, "can't create archive")
+	}
+
+	if err := tar.Close(); err != nil {
+		return tmpDir, "", errors.Wrap(err, "can't create archive")
+	}
+
+	return tmpDir, tarball, nil
+}
+
+// WalkSources calls the function for the files and directories of the app sources in the
+// directory, in lexical order, parents before their contents. The name is the path relative
+// to the directory, with slashes. Git config files, and the files matched by the patterns of
+// the directory's ignore file are skipped.
+func WalkSources(dir string, fn func(name, fpath string, info os.FileInfo) error) error {
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
-		return "", "", errors.Wrap(err, "cannot read the apps source files")
+		return errors.Wrap(err, "cannot read the apps source files")
 	}
-	sources := []string{}
+
+	ignored, err := ignorePatterns(dir)
+	if err != nil {
+		return errors.Wrap(err, "cannot read the ignore file")
+	}
+
 	for _, f := range files {
-		// The FileInfo entries returned by ReadDir provide
-		// only the base name of the file or directory they
-		// are for. We have to add back the path of the
-		// application directory to get the proper paths to
-		// the files and directories to assemble in the
-		// tarball.
 		// Ignore git config files in the app sources.
-		if f.Name() == ".git" || f.Name() == ".gitignore" || f.Name() == ".gitmodules" || f.Name() == ".gitconfig" || f.Name() == ".git-credentials" {
+		if f.Name() == ".git" || f.Name() == ".gitignore" || f.Name() == ".gitmodules" || f.Name() == ".gitconfig" || f.Name() == ".git-credentials" || f.Name() == IgnoreFile {
 			continue
 		}
-		sources = append(sources, path.Join(dir, f.Name()))
+
+		err := filepath.Walk(filepath.Join(dir, f.Name()), func(fpath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// The name in the archive is the path relative to the application
+			// directory.
+			name, err := filepath.Rel(dir, fpath)
+			if err != nil {
+				return err
+			}
+			name = filepath.ToSlash(name)
+
+			if ignored.Match(strings.Split(name, "/"), info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			return fn(name, fpath, info)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	// create a tmpDir - tarball dir and POST
-	tmpDir, err := ioutil.TempDir("", "epinio-app")
+	return nil
+}
+
+// ignorePatterns returns the matcher for the patterns of the ignore file in the directory.
+// Without ignore file nothing is matched.
+func ignorePatterns(dir string) (gitignore.Matcher, error) {
+	patterns := []gitignore.Pattern{}
+
+	file, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if os.IsNotExist(err) {
+		return gitignore.NewMatcher(patterns), nil
+	}
 	if err != nil {
-		return "", "", errors.Wrap(err, "can't create temp directory")
+		return nil, err
 	}
+	defer file.Close()
 
-	tarball := path.Join(tmpDir, "blob.tar")
-	err = archiver.Archive(sources, tarball)
-	if err != nil {
-		return tmpDir, "", errors.Wrap(err, "can't create archive")
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	return tmpDir, tarball, nil
+	return gitignore.NewMatcher(patterns), nil
 }
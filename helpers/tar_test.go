@@ -0,0 +1,80 @@
+package helpers_test
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers"
+	"github.com/mholt/archiver/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tar", func() {
+	var dir, tmpDir string
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	archived := func() []string {
+		var tarball string
+		var err error
+		tmpDir, tarball, err = helpers.Tar(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		names := []string{}
+		err = archiver.NewTar().Walk(tarball, func(f archiver.File) error {
+			header, ok := f.Header.(*tar.Header)
+			Expect(ok).To(BeTrue())
+			names = append(names, strings.TrimSuffix(header.Name, "/"))
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		sort.Strings(names)
+		return names
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "epinio-sources")
+		Expect(err).ToNot(HaveOccurred())
+
+		write("main.go", "package main")
+		write("src/app.go", "package main")
+		write("node_modules/lib/index.js", "")
+		write(".env", "SECRET=1")
+		write(".git/config", "")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	It("archives the sources relative to the directory, without git files", func() {
+		Expect(archived()).To(Equal([]string{
+			".env",
+			"main.go",
+			"node_modules",
+			"node_modules/lib",
+			"node_modules/lib/index.js",
+			"src",
+			"src/app.go",
+		}))
+	})
+
+	It("leaves out the files matched by the ignore file", func() {
+		write(helpers.IgnoreFile, "# dependencies\nnode_modules/\n.env\n*.go\n!src/*.go\n")
+
+		Expect(archived()).To(Equal([]string{
+			"src",
+			"src/app.go",
+		}))
+	})
+})
@@ -0,0 +1,99 @@
+package termui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats, chosen with the global `--output` option.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// ValidateOutputFormat checks that the format is one of the known output formats.
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case "", OutputTable, OutputJSON, OutputYAML:
+		return nil
+	}
+	return errors.Errorf("bad output format '%s', expected one of table, json, or yaml", format)
+}
+
+// Machine returns true if the user asked for machine-readable output. Commands supporting it
+// print their data with Data, instead of tables. All other messages go to stderr then, to
+// keep stdout parseable.
+func (u *UI) Machine() bool {
+	return u.output == OutputJSON || u.output == OutputYAML
+}
+
+// Data prints the value on stdout, in the machine-readable format asked for. The YAML is
+// made from the JSON, to have the same keys in both formats.
+func (u *UI) Data(value interface{}) error {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding the output")
+	}
+
+	if u.output == OutputYAML {
+		var generic interface{}
+		if err := yaml.Unmarshal(encoded, &generic); err != nil {
+			return errors.Wrap(err, "converting the output")
+		}
+		encoded, err = yaml.Marshal(generic)
+		if err != nil {
+			return errors.Wrap(err, "encoding the output")
+		}
+		_, err = fmt.Print(string(encoded))
+		return err
+	}
+
+	_, err = fmt.Println(string(encoded))
+	return err
+}
+
+// Event prints the value on stdout as a single event of a stream, in the machine-readable
+// format asked for, i.e. one line of JSON per event, or one YAML document per event.
+func (u *UI) Event(value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "encoding the event")
+	}
+
+	if u.output == OutputYAML {
+		var generic interface{}
+		if err := yaml.Unmarshal(encoded, &generic); err != nil {
+			return errors.Wrap(err, "converting the event")
+		}
+		encoded, err = yaml.Marshal(generic)
+		if err != nil {
+			return errors.Wrap(err, "encoding the event")
+		}
+		_, err = fmt.Print("---\n" + string(encoded))
+		return err
+	}
+
+	_, err = fmt.Println(string(encoded))
+	return err
+}
+
+// writer returns where messages are printed, i.e. stdout, or stderr when machine-readable
+// output is asked for.
+func (u *UI) writer() io.Writer {
+	if u.Machine() {
+		return color.Error
+	}
+	return color.Output
+}
+
+// outputFormat returns the output argument
+func outputFormat() string {
+	return viper.GetString("output")
+}
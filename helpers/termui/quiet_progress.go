@@ -0,0 +1,15 @@
+package termui
+
+// This file implements the QuietProgress form of the Progress
+// interface, used when the user asked for quiet output. It shows
+// nothing, keeping logs free of animation frames.
+
+type QuietProgress struct{}
+
+func (p *QuietProgress) Start() {}
+
+func (p *QuietProgress) Stop() {}
+
+func (p *QuietProgress) ChangeMessagef(message string, a ...interface{}) {}
+
+func (p *QuietProgress) ChangeMessage(message string) {}
@@ -39,7 +39,9 @@ const (
 // UI contains functionality for dealing with the user
 // on the CLI
 type UI struct {
-	verbosity int // Verbosity level for user messages.
+	verbosity int    // Verbosity level for user messages.
+	quiet     bool   // Only results and problems are printed.
+	output    string // Output format, see the Output* constants.
 }
 
 // Message represents a piece of information we want displayed to the user
@@ -77,6 +79,8 @@ type Progress interface {
 func NewUI() *UI {
 	return &UI{
 		verbosity: verbosity(),
+		quiet:     quiet(),
+		output:    outputFormat(),
 	}
 }
 
@@ -89,6 +93,9 @@ func (u *UI) Progressf(message string, a ...interface{}) Progress {
 // Progress creates, configures, and returns an active progress
 // meter. It accepts a fixed message.
 func (u *UI) Progress(message string) Progress {
+	if u.quiet {
+		return &QuietProgress{}
+	}
 	return NewDotProgress(u, message)
 	// return NewSpinProgress(message)
 }
@@ -164,12 +171,17 @@ func (u *Message) Msg(message string) {
 	if u.level > u.ui.verbosity {
 		return
 	}
+	// Ignore everything but results, warnings and problems when asked to be quiet.
+	if u.ui.quiet && (u.msgType == normal || u.msgType == note || u.msgType == progress) {
+		return
+	}
 
 	message = emoji.Sprint(message)
+	out := u.ui.writer()
 
 	// Print a newline before starting output, if not compact.
 	if message != "" && !u.compact {
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 
 	if !u.keepline {
@@ -194,12 +206,12 @@ func (u *Message) Msg(message string) {
 		message = color.RedString(message)
 	}
 
-	fmt.Fprintf(color.Output, "%s", message)
+	fmt.Fprintf(out, "%s", message)
 
 	for _, interaction := range u.interactions {
 		switch interaction.variant {
 		case ask:
-			fmt.Printf("> ")
+			fmt.Fprintf(out, "> ")
 			switch interaction.valueType {
 			case tBool:
 				interaction.value = readBool()
@@ -211,17 +223,17 @@ func (u *Message) Msg(message string) {
 		case show:
 			switch interaction.valueType {
 			case tBool:
-				fmt.Fprintf(color.Output, "%s: %s\n", emoji.Sprint(interaction.name), color.MagentaString("%t", interaction.value))
+				fmt.Fprintf(out, "%s: %s\n", emoji.Sprint(interaction.name), color.MagentaString("%t", interaction.value))
 			case tInt:
-				fmt.Fprintf(color.Output, "%s: %s\n", emoji.Sprint(interaction.name), color.CyanString("%d", interaction.value))
+				fmt.Fprintf(out, "%s: %s\n", emoji.Sprint(interaction.name), color.CyanString("%d", interaction.value))
 			case tString:
-				fmt.Fprintf(color.Output, "%s: %s\n", emoji.Sprint(interaction.name), color.GreenString("%s", interaction.value))
+				fmt.Fprintf(out, "%s: %s\n", emoji.Sprint(interaction.name), color.GreenString("%s", interaction.value))
 			}
 		}
 	}
 
 	for idx, headers := range u.tableHeaders {
-		table := tablewriter.NewWriter(color.Output)
+		table := tablewriter.NewWriter(out)
 		table.SetHeader(headers)
 		table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
 		table.SetCenterSeparator("|")
@@ -385,7 +397,21 @@ func readInt() int {
 	return value
 }
 
-// verbosity returns the verbosity argument
+// Quiet returns true if the user asked for results only, without notes and progress.
+func (u *UI) Quiet() bool {
+	return u.quiet
+}
+
+// verbosity returns the verbosity argument, raised by the number of verbose flags
 func verbosity() int {
-	return viper.GetInt("verbosity")
+	level := viper.GetInt("verbosity")
+	if verbose := viper.GetInt("verbose"); verbose > level {
+		level = verbose
+	}
+	return level
+}
+
+// quiet returns the quiet argument
+func quiet() bool {
+	return viper.GetBool("quiet")
 }
@@ -0,0 +1,226 @@
+// Package admission validates the Epinio resources, i.e. apps, app charts, and services, when
+// they are created or changed. Epinio checks its requests itself, the validation guards
+// against direct edits, e.g. with kubectl, putting the platform into inconsistent states.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/epinio/epinio/internal/routes"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// Group and version of the Epinio resources.
+const (
+	Group   = "application.epinio.io"
+	Version = "v1"
+)
+
+// Resources of the Epinio resources.
+var (
+	AppResource      = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "apps"}
+	AppChartResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "appcharts"}
+	ServiceResource  = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "services"}
+)
+
+// Validator validates the Epinio resources of admission requests.
+type Validator struct {
+	// Client reads the resources the validated ones refer to, or collide with.
+	Client dynamic.Interface
+	// Namespace is the namespace of Epinio, holding the app charts and catalog services.
+	Namespace string
+	// ExemptUsers are not validated, usually just Epinio itself. Epinio checks its requests,
+	// and passes through intermediate states, e.g. while renaming an app.
+	ExemptUsers []string
+}
+
+// Review validates the resource of the request, and returns the response allowing, or
+// denying, it.
+func (v *Validator) Review(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	err := v.validate(ctx, request)
+	if err == nil {
+		return &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+	}
+
+	return &admissionv1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+			Code:    422,
+		},
+	}
+}
+
+func (v *Validator) validate(ctx context.Context, request *admissionv1.AdmissionRequest) error {
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return nil
+	}
+	for _, user := range v.ExemptUsers {
+		if request.UserInfo.Username == user {
+			return nil
+		}
+	}
+
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(request.Object.Raw); err != nil {
+		return errors.Wrap(err, "bad resource")
+	}
+
+	// Changes which keep the spec, e.g. of labels, or finalizers, are not checked. They
+	// cannot make a valid resource invalid, and do not block on already broken ones.
+	if request.Operation == admissionv1.Update && len(request.OldObject.Raw) > 0 {
+		old := &unstructured.Unstructured{}
+		if err := old.UnmarshalJSON(request.OldObject.Raw); err == nil &&
+			reflect.DeepEqual(old.Object["spec"], object.Object["spec"]) {
+			return nil
+		}
+	}
+
+	switch request.Resource.Resource {
+	case AppResource.Resource:
+		return v.validateApp(ctx, object)
+	case AppChartResource.Resource:
+		return validateAppChart(object)
+	case ServiceResource.Resource:
+		return validateService(object)
+	}
+	return nil
+}
+
+// validateApp checks the name and routes of the app, that its app chart exists, and that no
+// other app has any of its routes.
+func (v *Validator) validateApp(ctx context.Context, app *unstructured.Unstructured) error {
+	if errs := validation.IsDNS1123Subdomain(app.GetName()); len(errs) > 0 {
+		return fmt.Errorf("bad app name '%s': %s", app.GetName(), errs[0])
+	}
+
+	appRoutes, _, err := unstructured.NestedStringSlice(app.Object, "spec", "routes")
+	if err != nil {
+		return errors.Wrap(err, "bad routes")
+	}
+	if err := routes.ValidateRoutes(appRoutes); err != nil {
+		return err
+	}
+
+	chartName, _, err := unstructured.NestedString(app.Object, "spec", "chartname")
+	if err != nil {
+		return errors.Wrap(err, "bad chart name")
+	}
+	if chartName != "" {
+		_, err := v.Client.Resource(AppChartResource).Namespace(v.Namespace).Get(ctx, chartName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("app chart '%s' does not exist", chartName)
+		}
+		if err != nil {
+			return errors.Wrap(err, "getting the app chart")
+		}
+	}
+
+	if len(appRoutes) == 0 {
+		return nil
+	}
+
+	apps, err := v.Client.Resource(AppResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing the apps")
+	}
+
+	wanted := map[string]string{}
+	for _, route := range appRoutes {
+		wanted[routes.FromString(route).ID()] = route
+	}
+
+	for _, other := range apps.Items {
+		if other.GetNamespace() == app.GetNamespace() && other.GetName() == app.GetName() {
+			continue
+		}
+		otherRoutes, _, err := unstructured.NestedStringSlice(other.Object, "spec", "routes")
+		if err != nil {
+			continue
+		}
+		for _, route := range otherRoutes {
+			if mine, ok := wanted[routes.FromString(route).ID()]; ok {
+				return fmt.Errorf("route '%s' is used by app '%s' in namespace '%s'",
+					mine, other.GetName(), other.GetNamespace())
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAppChart checks the name of the app chart, and that it names a helm chart.
+func validateAppChart(chart *unstructured.Unstructured) error {
+	if errs := validation.IsDNS1123Subdomain(chart.GetName()); len(errs) > 0 {
+		return fmt.Errorf("bad app chart name '%s': %s", chart.GetName(), errs[0])
+	}
+
+	helmChart, _, err := unstructured.NestedString(chart.Object, "spec", "helmChart")
+	if err != nil {
+		return errors.Wrap(err, "bad helm chart")
+	}
+	if helmChart == "" {
+		return errors.New("app chart without helm chart")
+	}
+
+	return nil
+}
+
+// validateService checks that the catalog service is named by its spec, as the catalog
+// looks services up by that name, that it names a helm chart and repository, and that its
+// values are YAML.
+func validateService(service *unstructured.Unstructured) error {
+	name, _, err := unstructured.NestedString(service.Object, "spec", "name")
+	if err != nil {
+		return errors.Wrap(err, "bad service name")
+	}
+	if name != service.GetName() {
+		return fmt.Errorf("service name '%s' differs from resource name '%s'", name, service.GetName())
+	}
+
+	helmChart, _, err := unstructured.NestedString(service.Object, "spec", "chart")
+	if err != nil {
+		return errors.Wrap(err, "bad helm chart")
+	}
+	if helmChart == "" {
+		return errors.New("service without helm chart")
+	}
+
+	repoURL, _, err := unstructured.NestedString(service.Object, "spec", "helmRepo", "url")
+	if err != nil {
+		return errors.Wrap(err, "bad helm repository")
+	}
+	if repoURL != "" {
+		if parsed, err := url.Parse(repoURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("bad helm repository url '%s'", repoURL)
+		}
+	}
+
+	values, _, err := unstructured.NestedString(service.Object, "spec", "values")
+	if err != nil {
+		return errors.Wrap(err, "bad values")
+	}
+	if strings.TrimSpace(values) != "" {
+		var parsed map[string]interface{}
+		if err := yaml.Unmarshal([]byte(values), &parsed); err != nil {
+			return errors.Wrap(err, "bad values")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package admission_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/epinio/epinio/internal/admission"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func resource(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": admission.Group + "/" + admission.Version,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+		"spec": spec,
+	}}
+}
+
+func request(operation admissionv1.Operation, gvr schema.GroupVersionResource, object *unstructured.Unstructured) *admissionv1.AdmissionRequest {
+	raw, err := object.MarshalJSON()
+	Expect(err).ToNot(HaveOccurred())
+
+	return &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Operation: operation,
+		Resource:  metav1.GroupVersionResource{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource},
+		Namespace: object.GetNamespace(),
+		Name:      object.GetName(),
+		Object:    runtime.RawExtension{Raw: raw},
+		UserInfo:  authenticationv1.UserInfo{Username: "admin"},
+	}
+}
+
+var _ = Describe("Validator", func() {
+	var validator *admission.Validator
+
+	BeforeEach(func() {
+		client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				admission.AppResource:      "AppList",
+				admission.AppChartResource: "AppChartList",
+			},
+			resource("AppChart", "epinio", "standard", map[string]interface{}{"helmChart": "epinio-application"}),
+			resource("App", "workspace", "taken", map[string]interface{}{
+				"routes": []interface{}{"taken.example.com/api"},
+			}),
+		)
+		validator = &admission.Validator{
+			Client:      client,
+			Namespace:   "epinio",
+			ExemptUsers: []string{"system:serviceaccount:epinio:epinio-server"},
+		}
+	})
+
+	review := func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		response := validator.Review(context.Background(), req)
+		Expect(response.UID).To(BeEquivalentTo("uid"))
+		return response
+	}
+
+	Describe("apps", func() {
+		It("allows apps with free routes, and a known app chart", func() {
+			app := resource("App", "workspace", "sample", map[string]interface{}{
+				"routes":    []interface{}{"taken.example.com/web", "sample.example.com"},
+				"chartname": "standard",
+			})
+			Expect(review(request(admissionv1.Create, admission.AppResource, app)).Allowed).To(BeTrue())
+		})
+
+		It("denies routes of other apps", func() {
+			app := resource("App", "other", "sample", map[string]interface{}{
+				"routes": []interface{}{"taken.example.com/api?rewrite=/"},
+			})
+			response := review(request(admissionv1.Create, admission.AppResource, app))
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(Equal("route 'taken.example.com/api?rewrite=/' is used by app 'taken' in namespace 'workspace'"))
+		})
+
+		It("allows the app to keep its own routes", func() {
+			app := resource("App", "workspace", "taken", map[string]interface{}{
+				"routes": []interface{}{"taken.example.com/api"},
+			})
+			Expect(review(request(admissionv1.Update, admission.AppResource, app)).Allowed).To(BeTrue())
+		})
+
+		It("denies unknown app charts", func() {
+			app := resource("App", "workspace", "sample", map[string]interface{}{"chartname": "missing"})
+			response := review(request(admissionv1.Create, admission.AppResource, app))
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(Equal("app chart 'missing' does not exist"))
+		})
+
+		It("denies bad names, and routes", func() {
+			app := resource("App", "workspace", "Sample_App", map[string]interface{}{})
+			Expect(review(request(admissionv1.Create, admission.AppResource, app)).Allowed).To(BeFalse())
+
+			app = resource("App", "workspace", "sample", map[string]interface{}{
+				"routes": []interface{}{"sample.example.com/api?bogus=1"},
+			})
+			Expect(review(request(admissionv1.Create, admission.AppResource, app)).Allowed).To(BeFalse())
+		})
+
+		It("allows changes keeping the spec", func() {
+			app := resource("App", "other", "sample", map[string]interface{}{
+				"routes": []interface{}{"taken.example.com/api"},
+			})
+			req := request(admissionv1.Update, admission.AppResource, app)
+			req.OldObject = req.Object
+			Expect(review(req).Allowed).To(BeTrue())
+		})
+
+		It("does not validate the exempt users", func() {
+			app := resource("App", "other", "sample", map[string]interface{}{
+				"routes": []interface{}{"taken.example.com/api"},
+			})
+			req := request(admissionv1.Create, admission.AppResource, app)
+			req.UserInfo.Username = "system:serviceaccount:epinio:epinio-server"
+			Expect(review(req).Allowed).To(BeTrue())
+		})
+	})
+
+	Describe("app charts", func() {
+		It("denies app charts without helm chart", func() {
+			chart := resource("AppChart", "epinio", "custom", map[string]interface{}{})
+			response := review(request(admissionv1.Create, admission.AppChartResource, chart))
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(Equal("app chart without helm chart"))
+		})
+	})
+
+	Describe("services", func() {
+		It("allows proper catalog services", func() {
+			service := resource("Service", "epinio", "redis", map[string]interface{}{
+				"name":     "redis",
+				"chart":    "redis",
+				"helmRepo": map[string]interface{}{"name": "bitnami", "url": "https://charts.bitnami.com/bitnami"},
+				"values":   "auth:\n  enabled: false\n",
+			})
+			Expect(review(request(admissionv1.Create, admission.ServiceResource, service)).Allowed).To(BeTrue())
+		})
+
+		It("denies services named differently than their resource", func() {
+			service := resource("Service", "epinio", "redis", map[string]interface{}{
+				"name":  "postgres",
+				"chart": "redis",
+			})
+			response := review(request(admissionv1.Create, admission.ServiceResource, service))
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(Equal("service name 'postgres' differs from resource name 'redis'"))
+		})
+
+		It("denies bad values", func() {
+			service := resource("Service", "epinio", "redis", map[string]interface{}{
+				"name":   "redis",
+				"chart":  "redis",
+				"values": "auth: [",
+			})
+			Expect(review(request(admissionv1.Create, admission.ServiceResource, service)).Allowed).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("NewHandler", func() {
+	It("answers admission reviews", func() {
+		validator := &admission.Validator{Client: fake.NewSimpleDynamicClient(runtime.NewScheme()), Namespace: "epinio"}
+		handler := admission.NewHandler(logr.Discard(), validator)
+
+		chart := resource("AppChart", "epinio", "custom", map[string]interface{}{})
+		body, err := json.Marshal(admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request:  request(admissionv1.Create, admission.AppChartResource, chart),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, admission.Path, bytes.NewReader(body)))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var review admissionv1.AdmissionReview
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &review)).To(Succeed())
+		Expect(review.Kind).To(Equal("AdmissionReview"))
+		Expect(review.Request).To(BeNil())
+		Expect(review.Response.UID).To(BeEquivalentTo("uid"))
+		Expect(review.Response.Allowed).To(BeFalse())
+	})
+})
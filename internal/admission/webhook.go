@@ -0,0 +1,122 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Names of the webhook configuration, and of its webhook.
+const (
+	WebhookConfigurationName = "epinio-admission"
+	WebhookName              = "validate.application.epinio.io"
+)
+
+// Path is the path of the webhook.
+const Path = "/validate"
+
+// NewHandler returns the handler of the webhook, answering the admission reviews of the
+// API server.
+func NewHandler(logger logr.Logger, validator *Validator) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, "bad admission review", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = validator.Review(r.Context(), review.Request)
+		if !review.Response.Allowed {
+			logger.Info("denied", "resource", review.Request.Resource.Resource,
+				"namespace", review.Request.Namespace, "name", review.Request.Name,
+				"user", review.Request.UserInfo.Username, "reason", review.Response.Result.Message)
+		}
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			logger.Error(err, "writing the admission review")
+		}
+	})
+	return mux
+}
+
+// Register makes the API server send the creations, and changes, of the Epinio resources to
+// the webhook, behind the named service of Epinio's namespace. The CA bundle verifies the
+// certificate of the webhook.
+//
+// Failures to reach the webhook are ignored. Otherwise Epinio's own resources could not be
+// installed, or upgraded, while the server is down.
+func Register(ctx context.Context, cluster *kubernetes.Cluster, namespace, service string, port int32, caBundle []byte) error {
+	path := Path
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	timeout := int32(10)
+
+	configuration := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   WebhookConfigurationName,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "epinio"},
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name: WebhookName,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: namespace,
+					Name:      service,
+					Path:      &path,
+					Port:      &port,
+				},
+				CABundle: caBundle,
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{
+					admissionregistrationv1.Create,
+					admissionregistrationv1.Update,
+				},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{Group},
+					APIVersions: []string{Version},
+					Resources: []string{
+						AppResource.Resource,
+						AppChartResource.Resource,
+						ServiceResource.Resource,
+					},
+				},
+			}},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			TimeoutSeconds:          &timeout,
+			AdmissionReviewVersions: []string{"v1"},
+		}},
+	}
+
+	configurations := cluster.Kubectl.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+
+	existing, err := configurations.Get(ctx, WebhookConfigurationName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configurations.Create(ctx, configuration, metav1.CreateOptions{})
+		return errors.Wrap(err, "creating the webhook configuration")
+	}
+	if err != nil {
+		return errors.Wrap(err, "getting the webhook configuration")
+	}
+
+	existing.Webhooks = configuration.Webhooks
+	_, err = configurations.Update(ctx, existing, metav1.UpdateOptions{})
+	return errors.Wrap(err, "updating the webhook configuration")
+}
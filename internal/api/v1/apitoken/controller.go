@@ -0,0 +1,23 @@
+// Package apitoken contains the API handlers to manage the API tokens of the users.
+package apitoken
+
+import (
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Controller represents all functionality of the API related to API tokens
+type Controller struct {
+}
+
+// toModel converts the API token into its API representation.
+func toModel(token auth.APIToken) models.APIToken {
+	return models.APIToken{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scope:      token.Scope,
+		Namespaces: token.Namespaces,
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+	}
+}
@@ -0,0 +1,71 @@
+package apitoken
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create handles the API endpoint POST /tokens
+// It issues a new API token to the user. The namespaces of the token have to be
+// namespaces of the user.
+func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	user := requestctx.User(ctx)
+
+	// Tokens cannot issue tokens, these would outlive them.
+	if requestctx.APIToken(ctx) != "" {
+		return apierror.NewAPIError("API tokens cannot create API tokens", "", http.StatusForbidden)
+	}
+
+	var request models.APITokenCreateRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if request.Name == "" {
+		return apierror.BadRequest(errors.New("name of token to create not found"))
+	}
+	if request.Scope == "" {
+		request.Scope = auth.APITokenScopeFull
+	}
+	expiry, err := time.ParseDuration(request.Expiry)
+	if err != nil {
+		return apierror.BadRequest(errors.Wrap(err, "bad expiry"))
+	}
+
+	if user.Role != "admin" {
+		for _, namespace := range request.Namespaces {
+			if user.NamespaceRole(namespace) == "" {
+				return apierror.NewAPIError("user unauthorized for namespace "+namespace, "", http.StatusForbidden)
+			}
+		}
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	token, value, err := authService.CreateAPIToken(ctx, user.Username, request.Name, request.Scope, request.Namespaces, expiry)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	// Not `response.Created`, nor `response.OKReturn`, these log the response, i.e. the
+	// token.
+	requestctx.Logger(ctx).Info("CREATED", "origin", c.Request.URL.String(), "token", token.ID)
+	c.JSON(http.StatusCreated, models.APITokenCreateResponse{
+		APIToken: toModel(token),
+		Token:    value,
+	})
+	return nil
+}
@@ -0,0 +1,34 @@
+package apitoken
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete handles the API endpoint DELETE /tokens/:token
+// It revokes the API token of the user.
+func (hc Controller) Delete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	user := requestctx.User(ctx)
+	id := c.Param("token")
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.DeleteAPIToken(ctx, user.Username, id)
+	if err == auth.ErrAPITokenNotFound {
+		return apierror.NewNotFoundError("api token not found", id)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
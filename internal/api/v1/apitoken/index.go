@@ -0,0 +1,36 @@
+package apitoken
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Index handles the API endpoint GET /tokens
+// It lists the API tokens of the user.
+func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	user := requestctx.User(ctx)
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	tokens, err := authService.GetAPITokens(ctx, user.Username)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result := models.APITokenList{}
+	for _, token := range tokens {
+		result = append(result, toModel(token))
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
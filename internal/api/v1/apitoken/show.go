@@ -0,0 +1,34 @@
+package apitoken
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Show handles the API endpoint GET /tokens/:token
+// It returns the API token of the user, without its secret.
+func (hc Controller) Show(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	user := requestctx.User(ctx)
+	id := c.Param("token")
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	token, err := authService.GetAPIToken(ctx, user.Username, id)
+	if err == auth.ErrAPITokenNotFound {
+		return apierror.NewNotFoundError("api token not found", id)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, toModel(token))
+	return nil
+}
@@ -0,0 +1,102 @@
+package application
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Adopt handles the API endpoint POST /namespaces/:namespace/applications/adopt
+// It turns an existing deployment of the namespace into an application running its image,
+// with the settings and routes found in the deployment, its services and ingresses. These
+// resources are removed once the application is deployed. A dry run only reports what would
+// be adopted.
+func (hc Controller) Adopt(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	var adoptRequest models.AppAdoptRequest
+	err = c.BindJSON(&adoptRequest)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if adoptRequest.Deployment == "" {
+		return apierror.NewBadRequest("name of deployment not found")
+	}
+	name := adoptRequest.Name
+	if name == "" {
+		name = adoptRequest.Deployment
+	}
+
+	deployment, services, ingresses, err := application.AdoptionCandidates(ctx, cluster, namespace, adoptRequest.Deployment)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierror.NewNotFoundError("deployment not found", adoptRequest.Deployment)
+		}
+		return apierror.InternalError(err)
+	}
+
+	adoption, err := application.Adopt(*deployment, services, ingresses, adoptRequest.Container)
+	if err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	result := models.AppAdoptResponse{
+		Name:          name,
+		ImageURL:      adoption.ImageURL,
+		Configuration: adoption.Configuration,
+		Adopted:       adoption.Resources(),
+		Warnings:      adoption.Warnings,
+	}
+
+	if adoptRequest.DryRun {
+		response.OKReturn(c, result)
+		return nil
+	}
+
+	apierr := hc.create(ctx, cluster, namespace, username, models.ApplicationCreateRequest{
+		Name:          name,
+		Configuration: adoption.Configuration,
+	})
+	if apierr != nil {
+		return apierr
+	}
+
+	routes, apierr := deployStable(ctx, cluster, models.DeployRequest{
+		App:      models.NewAppRef(name, namespace),
+		ImageURL: adoption.ImageURL,
+		Origin: models.ApplicationOrigin{
+			Kind:      models.OriginContainer,
+			Container: adoption.ImageURL,
+		},
+	}, username)
+	if apierr != nil {
+		return apierr
+	}
+	result.Routes = routes
+
+	// The deployment keeps serving until the application is deployed.
+	err = application.RemoveAdopted(ctx, cluster, namespace, adoption)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/domain"
+	"github.com/epinio/epinio/internal/events"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Apply handles the API endpoint POST /namespaces/:namespace/applications/apply
+// It creates the application of the manifest, or changes the existing one to match it, and
+// deploys the image of the manifest, if it changed. The changed state of an active application
+// is re-deployed. Applying the same manifest again changes nothing.
+func (hc Controller) Apply(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	var applyRequest models.ApplicationApplyRequest
+	err = c.BindJSON(&applyRequest)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if applyRequest.Name == "" {
+		return apierror.NewBadRequest("name of application not found")
+	}
+	if applyRequest.Origin.Git != nil || applyRequest.Origin.Path != "" {
+		return apierror.NewBadRequest("only container images can be applied, sources are pushed")
+	}
+	image := applyRequest.Origin.Container
+
+	appRef := models.NewAppRef(applyRequest.Name, namespace)
+	exists, err := application.Exists(ctx, cluster, appRef)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result := models.ApplicationApplyResponse{Changes: []string{}}
+	active := false
+
+	if !exists {
+		apierr := hc.create(ctx, cluster, namespace, username, models.ApplicationCreateRequest{
+			Name:          applyRequest.Name,
+			Configuration: applyRequest.Configuration,
+		})
+		if apierr != nil {
+			return apierr
+		}
+		result.Created = true
+	} else {
+		app, err := application.Lookup(ctx, cluster, namespace, applyRequest.Name)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		active = app.Workload != nil
+		if image == app.Origin.Container && active {
+			image = ""
+		}
+
+		desired := applyRequest.Configuration
+		if len(desired.Routes) == 0 {
+			route, err := domain.AppDefaultRoute(ctx, applyRequest.Name)
+			if err != nil {
+				return apierror.InternalError(err)
+			}
+			desired.Routes = []string{route}
+		}
+
+		changes, changed := application.ManifestChanges(app.Configuration, desired)
+
+		// An empty environment is no change for an update, it is cleared here.
+		if changes.Environment != nil && len(changes.Environment) == 0 {
+			err := application.EnvironmentSet(ctx, cluster, appRef, changes.Environment, true)
+			if err != nil {
+				return apierror.InternalError(err)
+			}
+		}
+
+		// The new image, if any, is deployed below, with the changes.
+		apierr := hc.update(ctx, cluster, namespace, applyRequest.Name, username, changes, image == "")
+		if apierr != nil {
+			return apierr
+		}
+		result.Changes = changed
+		result.Deployed = active && image == "" && len(changed) > 0
+	}
+
+	if image != "" {
+		applicationCR, err := application.Get(ctx, cluster, appRef)
+		if err != nil {
+			return apierror.InternalError(err, "failed to get the application resource")
+		}
+
+		err = deploy.UpdateImageURL(ctx, cluster, applicationCR, image)
+		if err != nil {
+			return apierror.InternalError(err, "failed to set application's image url")
+		}
+
+		err = application.CanarySet(ctx, cluster, appRef, nil)
+		if err != nil {
+			return apierror.InternalError(err, "failed to remove the application's canary")
+		}
+
+		origin := models.ApplicationOrigin{Kind: models.OriginContainer, Container: image}
+		routes, apierr := deploy.DeployApp(ctx, cluster, appRef, username, "", &origin, nil)
+		if apierr != nil {
+			return apierr
+		}
+
+		events.Publish(ctx, models.EventAppDeployed, namespace, applyRequest.Name,
+			map[string]string{"image": image})
+
+		result.Deployed = true
+		result.Routes = routes
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
@@ -0,0 +1,209 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/events"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// canaryRequest returns the canary deployment request with the default weight, if it has
+// none, or fails for bad weights, or thresholds.
+func canaryRequest(req models.DeployRequest) (models.DeployRequest, apierror.APIErrors) {
+	if req.Weight == 0 {
+		req.Weight = models.CanaryWeightDefault
+	}
+	if req.Weight < 0 || req.Weight > 99 {
+		return req, apierror.NewBadRequest("weight param should be integer between 1 and 99")
+	}
+	if err := application.ValidateErrorThreshold(req.ErrorThreshold); err != nil {
+		return req, apierror.NewBadRequest(err.Error())
+	}
+	return req, nil
+}
+
+// deployCanary is the part of the Deploy endpoint handling the canary strategy, see
+// canaryRequest. Instead of replacing the running version of the application, the image is
+// deployed next to it, and given the requested percentage of the route traffic. It returns
+// the routes of the application.
+func deployCanary(ctx context.Context, cluster *kubernetes.Cluster, req models.DeployRequest, username string) ([]string, apierror.APIErrors) {
+	app, err := application.Lookup(ctx, cluster, req.App.Namespace, req.App.Name)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return nil, apierror.AppIsNotKnown(req.App.Name)
+	}
+
+	if app.Workload == nil {
+		return nil, apierror.NewBadRequest("No canary possible for an application without workload")
+	}
+
+	err = application.CanarySet(ctx, cluster, req.App, &models.AppCanary{
+		ImageURL:       req.ImageURL,
+		StageID:        req.Stage.ID,
+		Origin:         req.Origin,
+		Weight:         req.Weight,
+		ErrorThreshold: req.ErrorThreshold,
+	})
+	if err != nil {
+		return nil, apierror.InternalError(err, "failed to save the application's canary")
+	}
+
+	routes, apierr := deploy.DeployApp(ctx, cluster, req.App, username, req.Stage.ID, nil, nil)
+	if apierr != nil {
+		// The failed canary is not running. Forget it.
+		if err := application.CanarySet(ctx, cluster, req.App, nil); err != nil {
+			return nil, apierror.InternalError(err, "failed to remove the application's canary")
+		}
+		return nil, apierr
+	}
+
+	events.Publish(ctx, models.EventAppDeployed, req.App.Namespace, req.App.Name,
+		map[string]string{"stage_id": req.Stage.ID, "image": req.ImageURL, "strategy": models.StrategyCanary})
+
+	return routes, nil
+}
+
+// CanaryPromote handles the API endpoint POST /namespaces/:namespace/applications/:app/canary/promote
+// It shifts more of the traffic to the canary of the application, or, for a full promotion,
+// makes the canary the new stable version of the application.
+func (hc Controller) CanaryPromote(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	var promoteRequest models.ApplicationCanaryPromoteRequest
+	if err := c.BindJSON(&promoteRequest); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if promoteRequest.Weight < 0 || promoteRequest.Weight > 100 {
+		return apierror.NewBadRequest("weight param should be integer between 0 and 100")
+	}
+
+	appRef := models.NewAppRef(appName, namespace)
+
+	canary, apierr := lookupCanary(c, cluster, appRef)
+	if apierr != nil {
+		return apierr
+	}
+
+	// Partial promotion. Only the traffic split changes.
+	if promoteRequest.Weight != 0 && promoteRequest.Weight != 100 {
+		canary.Weight = promoteRequest.Weight
+
+		err = application.CanarySet(ctx, cluster, appRef, canary)
+		if err != nil {
+			return apierror.InternalError(err, "failed to save the application's canary")
+		}
+
+		_, apierr = deploy.DeployApp(ctx, cluster, appRef, username, "", nil, nil)
+		if apierr != nil {
+			return apierr
+		}
+
+		response.OK(c)
+		return nil
+	}
+
+	// Full promotion. The canary replaces the stable version, as a regular deployment.
+	applicationCR, err := application.Get(ctx, cluster, appRef)
+	if err != nil {
+		return apierror.InternalError(err, "failed to get the application resource")
+	}
+
+	err = deploy.UpdateImageURL(ctx, cluster, applicationCR, canary.ImageURL)
+	if err != nil {
+		return apierror.InternalError(err, "failed to set application's image url")
+	}
+
+	err = application.CanarySet(ctx, cluster, appRef, nil)
+	if err != nil {
+		return apierror.InternalError(err, "failed to remove the application's canary")
+	}
+
+	_, apierr = deploy.DeployApp(ctx, cluster, appRef, username, "", &canary.Origin, nil)
+	if apierr != nil {
+		return apierr
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// CanaryAbort handles the API endpoint DELETE /namespaces/:namespace/applications/:app/canary
+// It removes the canary of the application, returning all traffic to the stable version.
+func (hc Controller) CanaryAbort(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	appRef := models.NewAppRef(appName, namespace)
+
+	if _, apierr := lookupCanary(c, cluster, appRef); apierr != nil {
+		return apierr
+	}
+
+	if apierr := deploy.AbortCanary(ctx, cluster, appRef, username); apierr != nil {
+		return apierr
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// lookupCanary returns the canary deployment of the referenced application, and fails if
+// the application or its canary do not exist.
+func lookupCanary(c *gin.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppCanary, apierror.APIErrors) {
+	ctx := c.Request.Context()
+
+	exists, err := application.Exists(ctx, cluster, appRef)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+
+	if !exists {
+		return nil, apierror.AppIsNotKnown(appRef.Name)
+	}
+
+	canary, err := application.Canary(ctx, cluster, appRef)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+
+	if canary == nil {
+		return nil, apierror.NewNotFoundError(fmt.Sprintf("application '%s' has no canary", appRef.Name))
+	}
+
+	return canary, nil
+}
@@ -1,6 +1,9 @@
 package application
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/appchart"
@@ -8,6 +11,9 @@ import (
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/configurations"
 	"github.com/epinio/epinio/internal/domain"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/internal/routes"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
@@ -36,6 +42,16 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.BadRequest(err)
 	}
 
+	if err := hc.create(ctx, cluster, namespace, username, createRequest); err != nil {
+		return err
+	}
+
+	response.Created(c)
+	return nil
+}
+
+// create creates the application of the request, without workload, after validating it.
+func (hc Controller) create(ctx context.Context, cluster *kubernetes.Cluster, namespace, username string, createRequest models.ApplicationCreateRequest) apierror.APIErrors {
 	appRef := models.NewAppRef(createRequest.Name, namespace)
 	found, err := application.Exists(ctx, cluster, appRef)
 	if err != nil {
@@ -71,39 +87,150 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		}
 	}
 
+	if createRequest.Configuration.Rollout != nil {
+		if err := validateRollout(*createRequest.Configuration.Rollout); err != nil {
+			theIssues = append(theIssues, err.Errors()...)
+		}
+	}
+
+	if err := routes.ValidateRoutes(createRequest.Configuration.Routes); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	} else if err := application.ValidateRouteSecrets(ctx, cluster, namespace,
+		createRequest.Configuration.Routes); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if err := application.ValidateRouteAnnotations(createRequest.Configuration.RouteAnnotations); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if err := application.ValidateTasks(createRequest.Configuration.Tasks); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if err := application.ValidateProcesses(createRequest.Configuration.Processes); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if createRequest.Configuration.Migration != nil {
+		if err := application.ValidateMigration(*createRequest.Configuration.Migration); err != nil {
+			theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+		}
+	}
+
+	if err := application.ValidateHooks(createRequest.Configuration.Hooks); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if pullSecret := createRequest.Configuration.PullSecret; pullSecret != nil {
+		if err := application.ValidatePullSecret(*pullSecret); err != nil {
+			theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+		} else if pullSecret.Registry != "" && pullSecret.Password == "" {
+			theIssues = append(theIssues, apierror.NewBadRequest(
+				fmt.Sprintf("pull secret for registry '%s' has no password", pullSecret.Registry)))
+		}
+	}
+
+	if createRequest.Configuration.AutoSleep != nil {
+		if err := application.ValidateAutoSleep(*createRequest.Configuration.AutoSleep); err != nil {
+			theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+		}
+	}
+
+	if err := application.ValidatePorts(createRequest.Configuration.Ports); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if createRequest.Configuration.Placement != nil {
+		if err := application.ValidatePlacement(*createRequest.Configuration.Placement); err != nil {
+			theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+		}
+	}
+
+	if createRequest.Configuration.Termination != nil {
+		if err := application.ValidateTermination(*createRequest.Configuration.Termination); err != nil {
+			theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+		}
+	}
+
+	if createRequest.Configuration.Entrypoint != nil {
+		if err := application.ValidateEntrypoint(*createRequest.Configuration.Entrypoint); err != nil {
+			theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+		}
+	}
+
+	instances := DefaultInstances
+	if createRequest.Configuration.Instances != nil {
+		instances = *createRequest.Configuration.Instances
+	}
+	if err := application.ValidateVolumes(createRequest.Configuration.Volumes, instances); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if err := application.ValidateConfigurationPaths(createRequest.Configuration.ConfigurationPaths,
+		createRequest.Configuration.Configurations); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
+	if err := application.ValidateSidecars(createRequest.Configuration.Sidecars,
+		createRequest.Configuration.Configurations); err != nil {
+		theIssues = append(theIssues, apierror.NewBadRequest(err.Error()))
+	}
+
 	if len(theIssues) > 0 {
 		return apierror.NewMultiError(theIssues)
 	}
 
-	var routes []string
+	var desiredRoutes []string
 	if len(createRequest.Configuration.Routes) > 0 {
-		routes = createRequest.Configuration.Routes
+		desiredRoutes = createRequest.Configuration.Routes
 	} else {
 		route, err := domain.AppDefaultRoute(ctx, createRequest.Name)
 		if err != nil {
 			return apierror.InternalError(err)
 		}
-		routes = []string{route}
+		desiredRoutes = []string{route}
 	}
 
-	// Finalize chart selection (system fallback), and verify existence.
+	// Finalize chart selection (namespace default, system fallback), and verify existence,
+	// and that the namespace allows it.
+
+	space, err := namespaces.Get(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err, "failed to retrieve the namespace settings")
+	}
+	spaceSettings := models.NamespaceSettings{}
+	if space != nil {
+		spaceSettings = space.Settings
+	}
 
 	chart := "standard"
 	if createRequest.Configuration.AppChart != "" {
 		chart = createRequest.Configuration.AppChart
+	} else if spaceSettings.AppChart != "" {
+		chart = spaceSettings.AppChart
 	}
 
-	found, err = appchart.Exists(ctx, cluster, chart)
+	if !spaceSettings.AppChartAllowed(chart) {
+		return apierror.NewBadRequest(fmt.Sprintf("app chart '%s' is not allowed in namespace '%s'", chart, namespace))
+	}
+
+	appChart, err := appchart.Lookup(ctx, cluster, chart)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
-	if !found {
+	if appChart == nil {
 		return apierror.AppChartIsNotKnown(chart)
 	}
 
+	chartValues := application.MergeChartValues(nil, createRequest.Configuration.ChartValues)
+	if _, err := appchart.Values(appChart, chartValues); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
 	// Arguments found OK, now we can modify the system state
 
-	err = application.Create(ctx, cluster, appRef, username, routes, chart)
+	err = application.Create(ctx, cluster, appRef, username, desiredRoutes, chart)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
@@ -118,6 +245,97 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	if createRequest.Configuration.Rollout != nil {
+		err = application.RolloutSet(ctx, cluster, appRef, *createRequest.Configuration.Rollout)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.Tasks) > 0 {
+		err = application.TasksSet(ctx, cluster, appRef, createRequest.Configuration.Tasks)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.Processes) > 0 {
+		err = application.ProcessesSet(ctx, cluster, appRef, createRequest.Configuration.Processes)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if createRequest.Configuration.Migration != nil {
+		err = application.MigrationSet(ctx, cluster, appRef, *createRequest.Configuration.Migration)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.ChartValues) > 0 {
+		err = application.ChartValuesSet(ctx, cluster, appRef, createRequest.Configuration.ChartValues)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.Hooks) > 0 {
+		err = application.HooksSet(ctx, cluster, appRef, createRequest.Configuration.Hooks)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if pullSecret := createRequest.Configuration.PullSecret; pullSecret != nil && pullSecret.Registry != "" {
+		err = application.PullSecretSet(ctx, cluster, appRef, *pullSecret)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.Volumes) > 0 {
+		err = application.VolumesSet(ctx, cluster, appRef, createRequest.Configuration.Volumes)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if createRequest.Configuration.Entrypoint != nil {
+		err = application.EntrypointSet(ctx, cluster, appRef, *createRequest.Configuration.Entrypoint)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if createRequest.Configuration.Termination != nil {
+		err = application.TerminationSet(ctx, cluster, appRef, *createRequest.Configuration.Termination)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if createRequest.Configuration.Placement != nil {
+		err = application.PlacementSet(ctx, cluster, appRef, *createRequest.Configuration.Placement)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.Ports) > 0 {
+		err = application.PortsSet(ctx, cluster, appRef, createRequest.Configuration.Ports)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.Sidecars) > 0 {
+		err = application.SidecarsSet(ctx, cluster, appRef, createRequest.Configuration.Sidecars)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
 	// Save configuration information.
 	err = application.BoundConfigurationsSet(ctx, cluster, appRef,
 		createRequest.Configuration.Configurations, true)
@@ -125,6 +343,29 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	if len(createRequest.Configuration.ConfigurationPaths) > 0 {
+		err = application.BoundConfigurationPathsSet(ctx, cluster, appRef,
+			createRequest.Configuration.ConfigurationPaths)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(createRequest.Configuration.RouteAnnotations) > 0 {
+		err = application.RouteAnnotationsSet(ctx, cluster, appRef,
+			createRequest.Configuration.RouteAnnotations)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if createRequest.Configuration.AutoSleep != nil {
+		err = application.AutoSleepSet(ctx, cluster, appRef, *createRequest.Configuration.AutoSleep)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
 	// Save environment assignments
 	err = application.EnvironmentSet(ctx, cluster, appRef,
 		createRequest.Configuration.Environment, true)
@@ -132,6 +373,7 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
-	response.Created(c)
+	events.Publish(ctx, models.EventAppCreated, appRef.Namespace, appRef.Name, nil)
+
 	return nil
 }
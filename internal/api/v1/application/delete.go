@@ -1,9 +1,12 @@
 package application
 
 import (
+	"context"
+
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/events"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
@@ -25,30 +28,93 @@ func (hc Controller) Delete(c *gin.Context) apierror.APIErrors {
 		return err
 	}
 
-	app := models.NewAppRef(appName, namespace)
+	configurations, apiErr := deleteApp(ctx, cluster, models.NewAppRef(appName, namespace))
+	if apiErr != nil {
+		return apiErr
+	}
 
-	found, err := application.Exists(ctx, cluster, app)
+	response.OKReturn(c, models.ApplicationDeleteResponse{
+		UnboundConfigurations: configurations,
+	})
+	return nil
+}
+
+// BatchDelete handles the API endpoint DELETE /namespaces/:namespace/applications
+// It removes the named applications, or all applications of the namespace, one after the
+// other, and reports the outcome per application.
+func (hc Controller) BatchDelete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+
+	var deleteRequest models.BatchDeleteRequest
+	if err := c.BindJSON(&deleteRequest); err != nil {
+		return apierror.BadRequest(err)
+	}
+	if len(deleteRequest.Names) == 0 && !deleteRequest.All {
+		return apierror.NewBadRequest("no applications to delete")
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
-	if !found {
-		return apierror.AppIsNotKnown(appName)
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
 	}
 
-	configurations, err := application.BoundConfigurationNames(ctx, cluster, app)
+	names := deleteRequest.Names
+	if deleteRequest.All {
+		apps, err := application.List(ctx, cluster, namespace)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		names = []string{}
+		for _, app := range apps {
+			names = append(names, app.Meta.Name)
+		}
+	}
+
+	resp := models.BatchDeleteResponse{Results: []models.BatchDeleteResult{}}
+	for _, name := range names {
+		result := models.BatchDeleteResult{Name: name}
+
+		configurations, apiErr := deleteApp(ctx, cluster, models.NewAppRef(name, namespace))
+		if apiErr != nil {
+			result.Error = apierror.Message(apiErr)
+		} else {
+			result.UnboundConfigurations = configurations
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	response.OKReturn(c, resp)
+	return nil
+}
+
+// deleteApp removes the referenced application, and returns the names of the configurations
+// which were bound to it.
+func deleteApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppRef) ([]string, apierror.APIErrors) {
+	found, err := application.Exists(ctx, cluster, app)
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
+	}
+	if !found {
+		return nil, apierror.AppIsNotKnown(app.Name)
 	}
 
-	resp := models.ApplicationDeleteResponse{
-		UnboundConfigurations: configurations,
+	configurations, err := application.BoundConfigurationNames(ctx, cluster, app)
+	if err != nil {
+		return nil, apierror.InternalError(err)
 	}
 
 	err = application.Delete(ctx, cluster, app)
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, resp)
-	return nil
+	events.Publish(ctx, models.EventAppDeleted, app.Namespace, app.Name, nil)
+
+	return configurations, nil
 }
@@ -1,6 +1,9 @@
 package application
 
 import (
+	"context"
+	"strings"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
@@ -8,6 +11,8 @@ import (
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/internal/operations"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
@@ -19,7 +24,9 @@ const (
 )
 
 // Deploy handles the API endpoint /namespaces/:namespace/applications/:app/deploy
-// It creates the deployment, configuration and ingress (kube) resources for the app
+// It creates the deployment, configuration and ingress (kube) resources for the app. With the
+// `async` query parameter it returns the id of the operation doing this right away, and the
+// operation waits for the app to run.
 func (hc Controller) Deploy(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 
@@ -44,26 +51,83 @@ func (hc Controller) Deploy(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err, "failed to get access to a kube client")
 	}
 
+	deployer := deployStable
+	switch req.Strategy {
+	case "":
+	case models.StrategyCanary:
+		var apierr apierror.APIErrors
+		req, apierr = canaryRequest(req)
+		if apierr != nil {
+			return apierr
+		}
+		deployer = deployCanary
+	default:
+		return apierror.NewBadRequest("unknown deployment strategy", req.Strategy)
+	}
+
+	if c.Query("async") == "true" {
+		operation, err := operations.Default.Start(ctx, models.OperationDeploy, namespace, name,
+			func(ctx context.Context, tracker *operations.Tracker) apierror.APIErrors {
+				tracker.Progress(models.OperationRunning, "creating the application resources")
+				routes, apierr := deployer(ctx, cluster, req, username)
+				if apierr != nil {
+					return apierr
+				}
+				tracker.Detail("routes", strings.Join(routes, ","))
+
+				tracker.Progress(models.OperationRunning, "waiting for the application to run")
+				return waitForRunning(ctx, cluster, namespace, name)
+			})
+		if err != nil {
+			return apierror.InternalError(err, "tracking the deployment")
+		}
+
+		response.AcceptedReturn(c, models.DeployResponse{
+			OperationID: operation.ID,
+		})
+		return nil
+	}
+
+	routes, apierr := deployer(ctx, cluster, req, username)
+	if apierr != nil {
+		return apierr
+	}
+
+	response.OKReturn(c, models.DeployResponse{
+		Routes: routes,
+	})
+	return nil
+}
+
+// deployStable is the part of the Deploy endpoint replacing the running version of the
+// application with the image. It returns the routes of the application.
+func deployStable(ctx context.Context, cluster *kubernetes.Cluster, req models.DeployRequest, username string) ([]string, apierror.APIErrors) {
 	applicationCR, err := application.Get(ctx, cluster, req.App)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return apierror.AppIsNotKnown("cannot deploy app, application resource is missing")
+			return nil, apierror.AppIsNotKnown("cannot deploy app, application resource is missing")
 		}
-		return apierror.InternalError(err, "failed to get the application resource")
+		return nil, apierror.InternalError(err, "failed to get the application resource")
 	}
 
 	err = deploy.UpdateImageURL(ctx, cluster, applicationCR, req.ImageURL)
 	if err != nil {
-		return apierror.InternalError(err, "failed to set application's image url")
+		return nil, apierror.InternalError(err, "failed to set application's image url")
+	}
+
+	// A regular deployment replaces any canary still running.
+	err = application.CanarySet(ctx, cluster, req.App, nil)
+	if err != nil {
+		return nil, apierror.InternalError(err, "failed to remove the application's canary")
 	}
 
 	routes, apierr := deploy.DeployApp(ctx, cluster, req.App, username, req.Stage.ID, &req.Origin, nil)
 	if apierr != nil {
-		return apierr
+		return nil, apierr
 	}
 
-	response.OKReturn(c, models.DeployResponse{
-		Routes: routes,
-	})
-	return nil
+	events.Publish(ctx, models.EventAppDeployed, req.App.Namespace, req.App.Name,
+		map[string]string{"stage_id": req.Stage.ID, "image": req.ImageURL})
+
+	return routes, nil
 }
@@ -0,0 +1,241 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/routes"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Domains handles the API endpoint GET /namespaces/:namespace/applications/:app/domains
+// It returns the custom domains of the specified application, with their state.
+func (hc Controller) Domains(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	exists, err := application.Exists(ctx, cluster, models.NewAppRef(appName, namespace))
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if !exists {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	domains, err := application.DomainStates(ctx, cluster, models.NewAppRef(appName, namespace))
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, domains)
+	return nil
+}
+
+// DomainAdd handles the API endpoint POST /namespaces/:namespace/applications/:app/domains
+// It adds the custom domain to the specified application, and tries to verify its ownership.
+// A verified domain becomes a route of the application, with a certificate requested from the
+// configured issuer. Repeating the request for an unverified domain retries the verification.
+func (hc Controller) DomainAdd(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	var domainRequest models.AppDomainRequest
+	if err := c.BindJSON(&domainRequest); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	name := strings.ToLower(domainRequest.Domain)
+	if err := application.ValidateDomain(name); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	domains, err := application.Domains(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	index := -1
+	for i, domain := range domains {
+		if domain.Domain == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		domain, err := application.NewDomain(name)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		domains = append(domains, domain)
+		index = len(domains) - 1
+	}
+
+	if !domains[index].Verified {
+		verified, err := application.DomainVerify(ctx, domains[index])
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		domains[index].Verified = verified
+	}
+
+	verified := domains[index].Verified
+
+	err = application.DomainsSet(ctx, cluster, app.Meta, domains)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if verified && !hasRoute(app.Configuration.Routes, name) {
+		err := application.RoutesSet(ctx, cluster, app.Meta, append(app.Configuration.Routes, name))
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+
+		if app.Workload != nil {
+			_, apierr := deploy.DeployApp(ctx, cluster, app.Meta, username, "", nil, nil)
+			if apierr != nil {
+				return apierr
+			}
+		}
+	}
+
+	states, err := application.DomainStates(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	for _, state := range states {
+		if state.Domain == name {
+			response.OKReturn(c, state)
+			return nil
+		}
+	}
+
+	return apierror.NewNotFoundError("domain not found", name)
+}
+
+// DomainRemove handles the API endpoint DELETE /namespaces/:namespace/applications/:app/domains/:domain
+// It removes the custom domain, and its route, from the specified application.
+func (hc Controller) DomainRemove(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	name := strings.ToLower(c.Param("domain"))
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	domains, err := application.Domains(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	kept := []models.AppDomain{}
+	for _, domain := range domains {
+		if domain.Domain != name {
+			kept = append(kept, domain)
+		}
+	}
+	if len(kept) == len(domains) {
+		return apierror.NewNotFoundError("domain not found", name)
+	}
+
+	err = application.DomainsSet(ctx, cluster, app.Meta, kept)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if hasRoute(app.Configuration.Routes, name) {
+		desiredRoutes := []string{}
+		for _, route := range app.Configuration.Routes {
+			if !sameRoute(route, name) {
+				desiredRoutes = append(desiredRoutes, route)
+			}
+		}
+
+		err := application.RoutesSet(ctx, cluster, app.Meta, desiredRoutes)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+
+		if app.Workload != nil {
+			_, apierr := deploy.DeployApp(ctx, cluster, app.Meta, username, "", nil, nil)
+			if apierr != nil {
+				return apierr
+			}
+		}
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// hasRoute returns true if the route is one of the routes, ignoring route options.
+func hasRoute(desiredRoutes []string, route string) bool {
+	for _, r := range desiredRoutes {
+		if sameRoute(r, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRoute returns true if the routes have the same domain and path.
+func sameRoute(a, b string) bool {
+	ra := routes.FromString(a)
+	rb := routes.FromString(b)
+	return ra.Domain == rb.Domain && ra.Path == rb.Path
+}
@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// eventsPollInterval is the time between checks for new events, when following them.
+const eventsPollInterval = 2 * time.Second
+
+// Events handles the API endpoint GET /namespaces/:namespace/applications/:app/events
+// It streams the events of the specified application over a websocket. When following, new
+// and updated events are streamed until the connection is closed.
+func (hc Controller) Events(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	log := requestctx.Logger(ctx)
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	follow := c.Query("follow") == "true"
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	appRef := models.NewAppRef(appName, namespace)
+	exists, err := application.Exists(ctx, cluster, appRef)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if !exists {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	var upgrader = newUpgrader()
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	log.Info("streaming events", "follow", follow)
+
+	err = streamEvents(ctx, conn, cluster, appRef, follow)
+	if err != nil {
+		log.V(1).Error(err, "error occurred after upgrading the websockets connection")
+	}
+
+	return nil
+}
+
+// streamEvents sends the events of the application to the websocket connection, and closes
+// it. When following, it polls for new and updated events until the client closes the
+// connection, or the context is done.
+func streamEvents(ctx context.Context, conn *websocket.Conn, cluster *kubernetes.Cluster, appRef models.AppRef, follow bool) error {
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Reading is required to notice the client closing the connection.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	// Maps event ids to the last version sent.
+	sent := map[string]string{}
+
+	for {
+		events, err := application.Events(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			version := fmt.Sprintf("%d/%s", event.Count, event.Time)
+			if sent[event.ID] == version {
+				continue
+			}
+			sent[event.ID] = version
+
+			msg, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return err
+			}
+		}
+
+		if !follow {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(eventsPollInterval):
+		}
+	}
+
+	return conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Time{})
+}
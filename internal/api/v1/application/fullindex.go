@@ -4,6 +4,7 @@ import (
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/listing"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 
 	"github.com/gin-gonic/gin"
@@ -11,19 +12,25 @@ import (
 
 // FullIndex handles the API endpoint GET /applications
 // It lists all the known applications in all namespaces, with and without workload.
+// The list is paged, filtered, and sorted as per the query parameters, see listing.FromQuery.
 func (hc Controller) FullIndex(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 
+	options, err := listing.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
 	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
 
-	allApps, err := application.List(ctx, cluster, "")
+	allApps, total, err := application.ListPage(ctx, cluster, "", options)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, allApps)
+	response.OKReturnList(c, allApps, allApps, total, options)
 	return nil
 }
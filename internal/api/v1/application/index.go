@@ -4,16 +4,23 @@ import (
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/listing"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/gin-gonic/gin"
 )
 
 // Index handles the API endpoint GET /namespaces/:namespace/applications
 // It lists all the known applications in the specified namespace, with and without workload.
+// The list is paged, filtered, and sorted as per the query parameters, see listing.FromQuery.
 func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 	namespace := c.Param("namespace")
 
+	options, err := listing.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
 	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -23,11 +30,11 @@ func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
 		return err
 	}
 
-	apps, err := application.List(ctx, cluster, namespace)
+	apps, total, err := application.ListPage(ctx, cluster, namespace, options)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, apps)
+	response.OKReturnList(c, apps, apps, total, options)
 	return nil
 }
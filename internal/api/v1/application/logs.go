@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 
@@ -25,7 +28,9 @@ import (
 // and                            GET /namespaces/:namespace/staging/:stage_id/logs
 // It arranges for the logs of the specified application to be
 // streamed over a websocket. Dependent on the endpoint this may be
-// either regular logs, or the app's staging logs.
+// either regular logs, or the app's staging logs. The logs of completed stagings
+// are served from the archive. The query parameters instance, since, tail, and
+// filter restrict the streamed lines, see logParameters.
 func (hc Controller) Logs(c *gin.Context) {
 	ctx := c.Request.Context()
 	log := requestctx.Logger(ctx)
@@ -77,6 +82,31 @@ func (hc Controller) Logs(c *gin.Context) {
 
 	followStr := c.Query("follow")
 
+	params, apierr := logParameters(c)
+	if apierr != nil {
+		response.Error(c, apierr)
+		return
+	}
+
+	var archived []tailer.ContainerLogLine
+	if stageID != "" {
+		log.Info("retrieve archived staging logs", "stage", stageID)
+
+		archived, err = application.StagingLogsArchived(ctx, cluster, namespace, stageID)
+		if err != nil {
+			response.Error(c, apierror.InternalError(err))
+			return
+		}
+
+		if archived != nil {
+			archived, err = application.FilterLogLines(archived, params)
+			if err != nil {
+				response.Error(c, apierror.InternalError(err))
+				return
+			}
+		}
+	}
+
 	log.Info("upgrade to web socket")
 
 	var upgrader = newUpgrader()
@@ -91,7 +121,18 @@ func (hc Controller) Logs(c *gin.Context) {
 	log.Info("streaming mode", "follow", follow)
 	log.Info("streaming begin")
 
-	err = hc.streamPodLogs(ctx, conn, namespace, appName, stageID, cluster, follow)
+	if archived != nil {
+		err = streamArchivedLogs(conn, archived)
+		if err != nil {
+			log.V(1).Error(err, "error occurred after upgrading the websockets connection")
+			return
+		}
+
+		log.Info("streaming completed")
+		return
+	}
+
+	err = hc.streamPodLogs(ctx, conn, namespace, appName, stageID, cluster, follow, params)
 	if err != nil {
 		log.V(1).Error(err, "error occurred after upgrading the websockets connection")
 		return
@@ -115,7 +156,7 @@ func (hc Controller) Logs(c *gin.Context) {
 // connection is closed. In any case it will call the cancel func that will stop
 // all the children go routines described above and then will wait for their parent
 // go routine to stop too (using another WaitGroup).
-func (hc Controller) streamPodLogs(ctx context.Context, conn *websocket.Conn, namespaceName, appName, stageID string, cluster *kubernetes.Cluster, follow bool) error {
+func (hc Controller) streamPodLogs(ctx context.Context, conn *websocket.Conn, namespaceName, appName, stageID string, cluster *kubernetes.Cluster, follow bool, params models.LogParameters) error {
 	logger := requestctx.Logger(ctx).WithName("streamer-to-websockets").V(1)
 	logChan := make(chan tailer.ContainerLogLine)
 	logCtx, logCancelFunc := context.WithCancel(ctx)
@@ -129,7 +170,7 @@ func (hc Controller) streamPodLogs(ctx context.Context, conn *websocket.Conn, na
 		}()
 
 		var tailWg sync.WaitGroup
-		err := application.Logs(logCtx, logChan, &tailWg, cluster, follow, appName, stageID, namespaceName)
+		err := application.Logs(logCtx, logChan, &tailWg, cluster, follow, appName, stageID, namespaceName, params)
 		if err != nil {
 			logger.Error(err, "setting up log routines failed")
 		}
@@ -193,6 +234,61 @@ func (hc Controller) streamPodLogs(ctx context.Context, conn *websocket.Conn, na
 	return conn.Close()
 }
 
+// logParameters returns the restrictions of the streamed log lines found in the query of the
+// request. These are the name of a single instance, the duration to go back in time, the
+// number of lines per container, and a regular expression the lines have to match.
+func logParameters(c *gin.Context) (models.LogParameters, apierror.APIErrors) {
+	params := models.LogParameters{
+		Instance: c.Query("instance"),
+		Filter:   c.Query("filter"),
+	}
+
+	if since := c.Query("since"); since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration < 0 {
+			return params, apierror.NewBadRequest("bad since, expected a non-negative duration", since)
+		}
+		params.Since = duration
+	}
+
+	if tail := c.Query("tail"); tail != "" {
+		lines, err := strconv.ParseInt(tail, 10, 64)
+		if err != nil || lines < 0 {
+			return params, apierror.NewBadRequest("bad tail, expected a non-negative number of lines", tail)
+		}
+		params.Tail = &lines
+	}
+
+	if params.Filter != "" {
+		if _, err := regexp.Compile(params.Filter); err != nil {
+			return params, apierror.NewBadRequest("bad filter, expected a regular expression", err.Error())
+		}
+	}
+
+	return params, nil
+}
+
+// streamArchivedLogs sends the archived log lines to the websocket connection, and closes it.
+func streamArchivedLogs(conn *websocket.Conn, lines []tailer.ContainerLogLine) error {
+	for _, logLine := range lines {
+		msg, err := json.Marshal(logLine)
+		if err != nil {
+			return err
+		}
+
+		if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	if err := conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Time{}); err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
 // https://pkg.go.dev/github.com/gorilla/websocket#hdr-Origin_Considerations
 // Regarding matching accessControlAllowOrigin and origin header:
 // https: //developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Access-Control-Allow-Origin
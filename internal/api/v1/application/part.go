@@ -14,12 +14,15 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/helm"
+	"github.com/epinio/epinio/internal/helmchart"
 	"github.com/epinio/epinio/internal/names"
+	"github.com/epinio/epinio/internal/registry"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/repo"
 	restclient "k8s.io/client-go/rest"
@@ -66,7 +69,7 @@ func (hc Controller) GetPart(c *gin.Context) apierror.APIErrors {
 	case "chart":
 		return fetchAppChart(c, ctx, logger, cluster, app.Meta)
 	case "image":
-		return fetchAppImage(c)
+		return fetchAppImage(c, ctx, logger, cluster, app)
 	case "values":
 		return fetchAppValues(c, logger, cluster, app.Meta)
 	}
@@ -118,8 +121,44 @@ func fetchAppChart(c *gin.Context, ctx context.Context, logger logr.Logger, clus
 	return nil
 }
 
-func fetchAppImage(c *gin.Context) apierror.APIErrors {
-	return apierror.NewBadRequest("image part not yet supported")
+// fetchAppImage returns the current image of the application as a tarball loadable by
+// `docker load`. The image is fetched from its registry with the credentials epinio has for
+// it.
+func fetchAppImage(c *gin.Context, ctx context.Context, logger logr.Logger, cluster *kubernetes.Cluster, app *models.App) apierror.APIErrors {
+	if app.ImageURL == "" {
+		return apierror.NewBadRequest("application has no image")
+	}
+
+	details, err := registry.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), registry.CredentialsSecretName)
+	if err != nil {
+		return apierror.InternalError(err, "getting the registry credentials")
+	}
+
+	var ca []byte
+	if secretName := viper.GetString("registry-certificate-secret"); secretName != "" {
+		secret, err := cluster.GetSecret(ctx, helmchart.Namespace(), secretName)
+		if err != nil {
+			return apierror.InternalError(err, "getting the registry certificate")
+		}
+		ca = secret.Data["tls.crt"]
+	}
+
+	image, err := details.OpenImage(ctx, app.ImageURL, ca)
+	if err != nil {
+		return apierror.NewAPIError(err.Error(), "", http.StatusBadGateway)
+	}
+
+	logger.Info("OK", "origin", c.Request.URL.String(), "returning", "image "+app.ImageURL)
+
+	c.Header("Content-Type", "application/x-tar")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.tar", app.Meta.Name))
+	c.Status(http.StatusOK)
+
+	// The status is sent, failures can only end the transfer early.
+	if err := image.Export(ctx, c.Writer); err != nil {
+		logger.Error(err, "exporting the image failed", "image", app.ImageURL)
+	}
+	return nil
 }
 
 func fetchAppValues(c *gin.Context, logger logr.Logger, cluster *kubernetes.Cluster, app models.AppRef) apierror.APIErrors {
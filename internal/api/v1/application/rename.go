@@ -0,0 +1,160 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/domain"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Rename handles the API endpoint POST /namespaces/:namespace/applications/:app/rename
+// It moves the application to the new name: the application resource and the secrets holding
+// environment, bindings, scaling, etc. are copied, the default route follows the name, the
+// application under the old name is deleted, and an active application is redeployed with
+// its image under the new name.
+func (hc Controller) Rename(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	log := requestctx.Logger(ctx)
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	var renameRequest models.ApplicationRenameRequest
+	if err := c.BindJSON(&renameRequest); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	newName := renameRequest.Name
+	if errs := validation.IsDNS1123Subdomain(newName); len(errs) > 0 {
+		return apierror.NewBadRequest("bad application name", strings.Join(errs, ", "))
+	}
+	if newName == appName {
+		return apierror.NewBadRequest("the new name is the name of the application")
+	}
+
+	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	newRef := models.NewAppRef(newName, namespace)
+	found, err := application.Exists(ctx, cluster, newRef)
+	if err != nil {
+		return apierror.InternalError(err, "failed to check for app resource")
+	}
+	if found {
+		return apierror.AppAlreadyKnown(newName)
+	}
+
+	staging, err := application.CurrentlyStaging(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if staging {
+		return apierror.NewBadRequest("No rename possible for an application while it is staging")
+	}
+
+	// The claims of the volumes are named after the application, their data cannot move.
+	if len(app.Configuration.Volumes) > 0 {
+		return apierror.NewBadRequest("No rename possible for an application with volumes")
+	}
+
+	canary, err := application.Canary(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if canary != nil {
+		return apierror.NewBadRequest("No rename possible for an application with canary, promote or abort it first")
+	}
+
+	oldDefault, err := domain.AppDefaultRoute(ctx, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	newDefault, err := domain.AppDefaultRoute(ctx, newName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	desiredRoutes, err := application.DesiredRoutes(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	routes := []string{}
+	for _, route := range desiredRoutes {
+		routes = append(routes, RenamedRoute(route, oldDefault, newDefault))
+	}
+
+	// Arguments found OK, now we can modify the system state
+
+	err = application.Rename(ctx, cluster, app.Meta, newName, routes)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	annotations, err := application.RouteAnnotations(ctx, cluster, newRef)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if len(annotations) > 0 {
+		renamed := models.AppRouteAnnotations{}
+		for route, routeAnnotations := range annotations {
+			renamed[RenamedRoute(route, oldDefault, newDefault)] = routeAnnotations
+		}
+		err = application.RouteAnnotationsSet(ctx, cluster, newRef, renamed)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	log.Info("renamed app resources", "namespace", namespace, "app", appName, "new name", newName)
+
+	err = application.Delete(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app.Workload != nil {
+		_, apierr := deploy.DeployApp(ctx, cluster, newRef, username, "", nil, nil)
+		if apierr != nil {
+			return apierr
+		}
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// RenamedRoute returns the route of the renamed application. Routes on the default route of
+// the application move to the default route of the new name, with their paths and options.
+// All other routes are kept.
+func RenamedRoute(route, oldDefault, newDefault string) string {
+	if route == oldDefault ||
+		strings.HasPrefix(route, oldDefault+"/") ||
+		strings.HasPrefix(route, oldDefault+"?") {
+		return newDefault + strings.TrimPrefix(route, oldDefault)
+	}
+	return route
+}
@@ -0,0 +1,31 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/application"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Application Rename API Endpoint unit tests", func() {
+
+	Describe("RenamedRoute", func() {
+		It("moves the default route to the new name", func() {
+			Expect(application.RenamedRoute("old.example.com", "old.example.com", "new.example.com")).
+				To(Equal("new.example.com"))
+		})
+
+		It("keeps path and options of the default route", func() {
+			Expect(application.RenamedRoute("old.example.com/api", "old.example.com", "new.example.com")).
+				To(Equal("new.example.com/api"))
+			Expect(application.RenamedRoute("old.example.com?rewrite=/", "old.example.com", "new.example.com")).
+				To(Equal("new.example.com?rewrite=/"))
+		})
+
+		It("keeps other routes", func() {
+			Expect(application.RenamedRoute("shop.example.com", "old.example.com", "new.example.com")).
+				To(Equal("shop.example.com"))
+			Expect(application.RenamedRoute("old.example.company", "old.example.com", "new.example.com")).
+				To(Equal("old.example.company"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/events"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,13 +32,80 @@ func (hc Controller) Restart(c *gin.Context) apierror.APIErrors {
 		return err
 	}
 
-	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if apierr := restartApp(ctx, cluster, models.NewAppRef(appName, namespace), username); apierr != nil {
+		return apierr
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// BatchRestart handles the API endpoint POST /namespaces/:namespace/applications/restart
+// It restarts the named applications, or all active applications of the namespace, one after
+// the other, and reports the outcome for each. A failed restart does not stop the others.
+func (hc Controller) BatchRestart(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	username := requestctx.User(ctx).Username
+
+	var restartRequest models.BatchRestartRequest
+	if err := c.BindJSON(&restartRequest); err != nil {
+		return apierror.BadRequest(err)
+	}
+	if len(restartRequest.Names) == 0 && !restartRequest.All {
+		return apierror.NewBadRequest("no applications to restart")
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	names := restartRequest.Names
+	if restartRequest.All {
+		apps, err := application.List(ctx, cluster, namespace)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		names = []string{}
+		for _, app := range apps {
+			// Inactive applications have nothing to restart.
+			if app.Workload != nil {
+				names = append(names, app.Meta.Name)
+			}
+		}
+	}
+
+	resp := models.BatchRestartResponse{Results: []models.BatchRestartResult{}}
+	for _, name := range names {
+		result := models.BatchRestartResult{Name: name}
+
+		apiErr := restartApp(ctx, cluster, models.NewAppRef(name, namespace), username)
+		if apiErr != nil {
+			result.Error = apierror.Message(apiErr)
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	response.OKReturn(c, resp)
+	return nil
+}
+
+// restartApp restarts the referenced application, by re-deploying it with a new restart
+// timestamp.
+func restartApp(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, username string) apierror.APIErrors {
+	app, err := application.Lookup(ctx, cluster, appRef.Namespace, appRef.Name)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
 
 	if app == nil {
-		return apierror.AppIsNotKnown(appName)
+		return apierror.AppIsNotKnown(appRef.Name)
 	}
 
 	if app.Workload == nil {
@@ -49,6 +119,7 @@ func (hc Controller) Restart(c *gin.Context) apierror.APIErrors {
 		return apierr
 	}
 
-	response.OK(c)
+	events.Publish(ctx, models.EventAppRestarted, appRef.Namespace, appRef.Name, nil)
+
 	return nil
 }
@@ -0,0 +1,158 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/events"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// Rollback handles the API endpoint POST /namespaces/:namespace/applications/:app/rollback
+// It redeploys the image of a previous revision of the application, with the environment
+// and configuration bindings recorded for that revision.
+func (hc Controller) Rollback(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	var rollbackRequest models.ApplicationRollbackRequest
+	if err := c.BindJSON(&rollbackRequest); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if rollbackRequest.Revision < 0 {
+		return apierror.NewBadRequest("revision param should be integer equal or greater than zero")
+	}
+
+	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	if app.Workload == nil {
+		return apierror.NewBadRequest("No rollback possible for an application without workload")
+	}
+
+	revisions, err := application.Revisions(ctx, cluster, app.Meta)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	target, apierr := RollbackTarget(revisions, rollbackRequest.Revision)
+	if apierr != nil {
+		return apierr
+	}
+
+	// The configurations bound at the time of the revision have to still exist.
+	for _, configurationName := range target.Configurations {
+		_, err := configurations.Lookup(ctx, cluster, namespace, configurationName)
+		if err != nil {
+			if err.Error() == "configuration not found" {
+				return apierror.ConfigurationIsNotKnown(configurationName)
+			}
+			return apierror.InternalError(err)
+		}
+	}
+
+	// Restore the state of the revision, then redeploy. When either fails the state of the
+	// running revision is put back, for the app to stay as it was.
+	restore := func() {
+		err := setRevisionState(ctx, cluster, app.Meta, app.Configuration.Environment, app.Configuration.Configurations, app.ImageURL)
+		if err != nil {
+			requestctx.Logger(ctx).Error(err, "restoring the state of the app after a failed rollback", "namespace", namespace, "app", appName)
+		}
+	}
+
+	err = setRevisionState(ctx, cluster, app.Meta, target.Environment, target.Configurations, target.ImageURL)
+	if err != nil {
+		restore()
+		return apierror.InternalError(err, "failed to restore the state of the revision")
+	}
+
+	_, apierr = deploy.RollbackApp(ctx, cluster, app.Meta, username, target.Origin)
+	if apierr != nil {
+		restore()
+		return apierr
+	}
+
+	events.Publish(ctx, models.EventAppDeployed, namespace, appName,
+		map[string]string{"image": target.ImageURL, "revision": strconv.Itoa(target.Number)})
+
+	response.OK(c)
+	return nil
+}
+
+// setRevisionState sets the environment, the bound configurations, and the image of the app,
+// i.e. the state recorded by its revisions.
+func setRevisionState(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, environment models.EnvVariableMap, configurationNames []string, imageURL string) error {
+	err := application.EnvironmentSet(ctx, cluster, appRef, environment, true)
+	if err != nil {
+		return err
+	}
+
+	err = application.BoundConfigurationsSet(ctx, cluster, appRef, configurationNames, true)
+	if err != nil {
+		return err
+	}
+
+	applicationCR, err := application.Get(ctx, cluster, appRef)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the application resource")
+	}
+
+	return errors.Wrap(deploy.UpdateImageURL(ctx, cluster, applicationCR, imageURL), "failed to set application's image url")
+}
+
+// RollbackTarget returns the revision to roll back to. A zero number selects the deployed
+// revision before the newest deployed one, i.e. before the revision currently running.
+// Failed revisions are never a target.
+func RollbackTarget(revisions models.AppRevisionList, number int) (*models.AppRevision, apierror.APIErrors) {
+	if number == 0 {
+		deployed := models.AppRevisionList{}
+		for _, revision := range revisions {
+			if !revision.Failed() {
+				deployed = append(deployed, revision)
+			}
+		}
+		if len(deployed) < 2 {
+			return nil, apierror.NewBadRequest("No previous revision to roll back to")
+		}
+		return &deployed[len(deployed)-2], nil
+	}
+
+	for i := range revisions {
+		if revisions[i].Number == number {
+			if revisions[i].Failed() {
+				return nil, apierror.NewBadRequest(fmt.Sprintf("Revision '%d' failed to deploy", number))
+			}
+			return &revisions[i], nil
+		}
+	}
+
+	return nil, apierror.NewNotFoundError(fmt.Sprintf("Revision '%d' does not exist", number))
+}
@@ -0,0 +1,53 @@
+package application_test
+
+import (
+	"net/http"
+
+	"github.com/epinio/epinio/internal/api/v1/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Application Rollback API Endpoint unit tests", func() {
+	var revisions models.AppRevisionList
+
+	BeforeEach(func() {
+		revisions = models.AppRevisionList{
+			{Number: 1, ImageURL: "one", Result: models.RevisionDeployed},
+			{Number: 2, ImageURL: "two", Result: models.RevisionDeployed},
+			{Number: 3, ImageURL: "three", Result: models.RevisionFailed},
+		}
+	})
+
+	Describe("RollbackTarget", func() {
+		It("selects the deployed revision before the running one by default", func() {
+			target, err := application.RollbackTarget(revisions, 0)
+			Expect(err).To(BeNil())
+			Expect(target.Number).To(Equal(1))
+		})
+
+		It("selects the requested revision", func() {
+			target, err := application.RollbackTarget(revisions, 2)
+			Expect(err).To(BeNil())
+			Expect(target.ImageURL).To(Equal("two"))
+		})
+
+		It("rejects a failed revision", func() {
+			_, err := application.RollbackTarget(revisions, 3)
+			Expect(err).ToNot(BeNil())
+			Expect(err.FirstStatus()).To(Equal(http.StatusBadRequest))
+		})
+
+		It("rejects an unknown revision", func() {
+			_, err := application.RollbackTarget(revisions, 7)
+			Expect(err).ToNot(BeNil())
+			Expect(err.FirstStatus()).To(Equal(http.StatusNotFound))
+		})
+
+		It("fails without a previous revision", func() {
+			_, err := application.RollbackTarget(revisions[:1], 0)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package application
 
 import (
+	"context"
+
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
@@ -29,6 +31,17 @@ func (hc Controller) Running(c *gin.Context) apierror.APIErrors {
 		return err
 	}
 
+	if err := waitForRunning(ctx, cluster, namespace, appName); err != nil {
+		return err
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// waitForRunning waits for the deployment of the application to complete, for at most
+// `duration.ToAppBuilt()`.
+func waitForRunning(ctx context.Context, cluster *kubernetes.Cluster, namespace, appName string) apierror.APIErrors {
 	app, err := application.Lookup(ctx, cluster, namespace, appName)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -49,6 +62,5 @@ func (hc Controller) Running(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
-	response.OK(c)
 	return nil
 }
@@ -33,6 +33,6 @@ func (hc Controller) Show(c *gin.Context) apierror.APIErrors {
 		return apierror.AppIsNotKnown(appName)
 	}
 
-	response.OKReturn(c, app)
+	response.OKReturnETag(c, app)
 	return nil
 }
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"net/http"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Sleep handles the API endpoint POST /namespaces/:namespace/applications/:app/sleep
+// It scales the application to zero instances, keeping the desired instances for waking it.
+func (hc Controller) Sleep(c *gin.Context) apierror.APIErrors {
+	return hc.sleepOrWake(c, true)
+}
+
+// Wake handles the API endpoint POST /namespaces/:namespace/applications/:app/wake
+// It scales a sleeping application back to its desired instances.
+func (hc Controller) Wake(c *gin.Context) apierror.APIErrors {
+	return hc.sleepOrWake(c, false)
+}
+
+// sleepOrWake puts the application of the request to sleep, or wakes it.
+func (hc Controller) sleepOrWake(c *gin.Context, sleep bool) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	if app.Workload == nil {
+		action := "wake"
+		if sleep {
+			action = "sleep"
+		}
+		return apierror.NewAPIError("No "+action+" possible for an application without workload",
+			"", http.StatusBadRequest)
+	}
+
+	var apierr apierror.APIErrors
+	if sleep {
+		apierr = deploy.Sleep(ctx, cluster, app.Meta, username)
+	} else {
+		apierr = deploy.Wake(ctx, cluster, app.Meta, username)
+	}
+	if apierr != nil {
+		return apierr
+	}
+
+	response.OK(c)
+	return nil
+}
@@ -3,6 +3,10 @@ package application
 import (
 	"context"
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -23,22 +27,51 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/duration"
+	"github.com/epinio/epinio/internal/events"
 	"github.com/epinio/epinio/internal/helmchart"
 	"github.com/epinio/epinio/internal/names"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/internal/operations"
 	"github.com/epinio/epinio/internal/registry"
 	"github.com/epinio/epinio/internal/s3manager"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 )
 
+const (
+	// stagingQueuePollInterval is the time between the checks of the position of a queued
+	// staging job, by the operation tracking it.
+	stagingQueuePollInterval = 5 * time.Second
+
+	// DefaultDockerfileImage is the image building Dockerfiles, for when the staging
+	// configuration does not specify one.
+	DefaultDockerfileImage = "gcr.io/kaniko-project/executor:v1.9.1"
+
+	// stagingSourceDir is the location of the unpacked application sources in the staging job.
+	stagingSourceDir = "/workspace/source/app"
+
+	// stagingCacheDir is the location of the build cache volume in the staging job.
+	stagingCacheDir = "/workspace/cache"
+)
+
 type stageParam struct {
 	models.AppRef
 	BlobUID             string
 	BuilderImage        string
+	Buildpacks          []string
+	Cache               string
+	Dockerfile          string
+	DockerfileImage     string
 	DownloadImage       string
 	UnpackImage         string
 	Environment         models.EnvVariableList
+	BuildEnvironment    models.EnvVariableMap
 	Owner               metav1.OwnerReference
+	Queued              bool
+	Resources           corev1.ResourceRequirements
+	Timeout             int64
+	NodeSelector        map[string]string
+	Tolerations         []corev1.Toleration
 	RegistryURL         string
 	S3ConnectionDetails s3manager.ConnectionDetails
 	Stage               models.StageRef
@@ -55,6 +88,13 @@ func (app *stageParam) ImageURL(registryURL string) string {
 	return fmt.Sprintf("%s/%s-%s:%s", registryURL, app.Namespace, app.Name, app.Stage.ID)
 }
 
+// CacheImageURL returns the URL of the container image holding the build cache of the
+// application, for when the cache is kept in the registry. Unlike the application image it is
+// shared by all stagings.
+func (app *stageParam) CacheImageURL(registryURL string) string {
+	return fmt.Sprintf("%s/%s-%s-cache", registryURL, app.Namespace, app.Name)
+}
+
 // ensurePVC creates a PVC for the application if one doesn't already exist.
 // This PVC is used to store the application source blobs (as they are uploaded
 // on the "upload" endpoint). It is also mounted in the staging pod, as the
@@ -130,19 +170,111 @@ func (hc Controller) Stage(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err, "failed to retrieve staging image refs")
 	}
 
-	// get builder image from either request, application, or default as final fallback
+	stageSettings, err := application.Staging(ctx, cluster, req.App)
+	if err != nil {
+		return apierror.InternalError(err, "failed to retrieve the application staging settings")
+	}
+
+	// get builder image from either request, application, namespace, or default as final fallback.
+	// the buildpacks follow the builder, either from request, application, or namespace.
 
 	builderImage, builderErr := getBuilderImage(req, app)
 	if builderErr != nil {
 		return builderErr
 	}
+
+	buildpacks := req.Buildpacks
+	if len(buildpacks) == 0 && req.BuilderImage == "" {
+		buildpacks = stageSettings.Buildpacks
+	}
+
+	space, err := namespaces.Get(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err, "failed to retrieve the namespace settings")
+	}
+	spaceSettings := models.NamespaceSettings{}
+	if space != nil {
+		spaceSettings = space.Settings
+	}
+
+	if builderImage == "" {
+		// First staging of the application, without a choice of builder.
+		builderImage = spaceSettings.BuilderImage
+		if len(buildpacks) == 0 {
+			buildpacks = spaceSettings.Buildpacks
+		}
+	}
 	if builderImage == "" {
 		builderImage = config.Data["builderImage"]
 	}
 
+	if err := application.ValidateBuilder(builderImage, buildpacks); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
 	downloadImage := config.Data["downloadImage"]
 	unpackImage := config.Data["unpackImage"]
 
+	dockerfileImage := config.Data["dockerfileImage"]
+	if dockerfileImage == "" {
+		dockerfileImage = DefaultDockerfileImage
+	}
+
+	// The resources of the job come from the namespace, falling back to the global settings.
+
+	globalResources, err := globalStagingResources(config.Data)
+	if err != nil {
+		return apierror.InternalError(err, "bad global staging resources")
+	}
+	resources := application.StagingResourcesMerge(spaceSettings.Staging, globalResources)
+	requirements, err := application.StagingResourceRequirements(resources)
+	if err != nil {
+		return apierror.InternalError(err, "bad staging resources")
+	}
+
+	// The job is scheduled onto the build nodes chosen by the operator, if any.
+
+	nodeSelector, tolerations, err := application.StagingPlacement(config.Data["nodeSelector"], config.Data["tolerations"])
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	// Choose between a Dockerfile build and buildpacks. The request decides, falling back
+	// to the choice of the last staging, i.e. when restaging.
+
+	dockerfile := req.Dockerfile
+	if dockerfile == "" && req.BuilderImage == "" {
+		dockerfile = stageSettings.Dockerfile
+	}
+	if dockerfile != "" {
+		if apierr := validateDockerfile(dockerfile); apierr != nil {
+			return apierr
+		}
+	}
+
+	// The build cache follows the request, or the choice of the last staging.
+
+	cache := req.Cache
+	if cache == "" {
+		cache = stageSettings.Cache
+	}
+	if cache == "" {
+		cache = models.BuildCacheVolume
+	}
+	if apierr := validateCache(cache); apierr != nil {
+		return apierr
+	}
+
+	// The build-time variables follow the request, or the choice of the last staging.
+
+	buildEnvironment := req.Environment
+	if buildEnvironment == nil {
+		buildEnvironment = stageSettings.Environment
+	}
+	if err := application.ValidateBuildEnvironment(buildEnvironment); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
 	log.Info("staging app", "namespace", namespace, "app", req)
 
 	staging, err := application.CurrentlyStaging(ctx, cluster, req.App.Namespace, req.App.Name)
@@ -210,11 +342,21 @@ func (hc Controller) Stage(c *gin.Context) apierror.APIErrors {
 	params := stageParam{
 		AppRef:              req.App,
 		BuilderImage:        builderImage,
+		Buildpacks:          buildpacks,
+		Cache:               cache,
+		Dockerfile:          dockerfile,
+		DockerfileImage:     dockerfileImage,
 		DownloadImage:       downloadImage,
 		UnpackImage:         unpackImage,
 		BlobUID:             blobUID,
 		Environment:         environment.List(),
+		BuildEnvironment:    buildEnvironment,
 		Owner:               owner,
+		Queued:              application.StagingQueued(spaceSettings),
+		Resources:           requirements,
+		Timeout:             resources.Timeout,
+		NodeSelector:        nodeSelector,
+		Tolerations:         tolerations,
 		RegistryURL:         registryPublicURL,
 		S3ConnectionDetails: s3ConnectionDetails,
 		Stage:               models.NewStage(uid),
@@ -224,9 +366,11 @@ func (hc Controller) Stage(c *gin.Context) apierror.APIErrors {
 		RegistryCASecret:    registryCertificateSecret,
 	}
 
-	err = ensurePVC(ctx, cluster, req.App)
-	if err != nil {
-		return apierror.InternalError(err, "failed to ensure a PersistenVolumeClaim for the application source and cache")
+	if cache == models.BuildCacheVolume {
+		err = ensurePVC(ctx, cluster, req.App)
+		if err != nil {
+			return apierror.InternalError(err, "failed to ensure a PersistenVolumeClaim for the application source and cache")
+		}
 	}
 
 	job, jobenv := newJobRun(params)
@@ -246,13 +390,41 @@ func (hc Controller) Stage(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err, "updating application CR with staging information")
 	}
 
+	stageSettings.Buildpacks = buildpacks
+	stageSettings.Cache = cache
+	stageSettings.Dockerfile = dockerfile
+	stageSettings.Environment = buildEnvironment
+	if err := application.StagingSet(ctx, cluster, req.App, stageSettings); err != nil {
+		return apierror.InternalError(err, "saving the application staging settings")
+	}
+
+	queuePosition := 0
+	if params.Queued {
+		positions, err := application.AdmitStaging(ctx, cluster)
+		if err != nil {
+			return apierror.InternalError(err, "admitting queued staging jobs")
+		}
+		queuePosition = positions[uid]
+	}
+
 	imageURL := params.ImageURL(params.RegistryURL)
 
-	log.Info("staged app", "namespace", helmchart.Namespace(), "app", params.AppRef, "uid", uid, "image", imageURL)
+	log.Info("staged app", "namespace", helmchart.Namespace(), "app", params.AppRef, "uid", uid, "image", imageURL, "queue", queuePosition)
+
+	operation, err := operations.Default.Start(ctx, models.OperationStage, namespace, name,
+		trackStaging(cluster, namespace, uid, queuePosition))
+	if err != nil {
+		return apierror.InternalError(err, "tracking the staging")
+	}
+
+	events.Publish(ctx, models.EventStagingStarted, params.AppRef.Namespace, params.AppRef.Name,
+		map[string]string{"stage_id": uid})
 
 	response.OKReturn(c, models.StageResponse{
-		Stage:    models.NewStage(uid),
-		ImageURL: imageURL,
+		Stage:         models.NewStage(uid),
+		ImageURL:      imageURL,
+		QueuePosition: queuePosition,
+		OperationID:   operation.ID,
 	})
 	return nil
 }
@@ -274,7 +446,17 @@ func (hc Controller) Staged(c *gin.Context) apierror.APIErrors {
 		return err
 	}
 
-	// Wait for the staging to be done, then check if it ended in failure.
+	if err := waitForStaging(ctx, cluster, namespace, id); err != nil {
+		return err
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// waitForStaging waits for the Job resource staging the app to complete, archives its logs,
+// and checks if it ended in failure.
+func waitForStaging(ctx context.Context, cluster *kubernetes.Cluster, namespace, id string) apierror.APIErrors {
 	// Select the job for this stage `id`.
 	selector := fmt.Sprintf("app.kubernetes.io/component=staging,app.kubernetes.io/part-of=%s,epinio.suse.org/stage-id=%s",
 		namespace, id)
@@ -293,6 +475,11 @@ func (hc Controller) Staged(c *gin.Context) apierror.APIErrors {
 		if err != nil {
 			return apierror.InternalError(err)
 		}
+		// Keep the logs around for after the removal of the job
+		err = application.StagingLogsArchive(ctx, cluster, namespace, id)
+		if err != nil {
+			requestctx.Logger(ctx).Error(err, "archiving staging logs", "stage", id)
+		}
 		// Check job for failure
 		failed, err := cluster.IsJobFailed(ctx, job.Name, helmchart.Namespace())
 		if err != nil {
@@ -304,7 +491,50 @@ func (hc Controller) Staged(c *gin.Context) apierror.APIErrors {
 		}
 	}
 
-	response.OK(c)
+	return nil
+}
+
+// trackStaging returns the work of the operation tracking the staging, through the queue of
+// the staging jobs, if it is queued, until its job is done.
+func trackStaging(cluster *kubernetes.Cluster, namespace, id string, position int) operations.Work {
+	return func(ctx context.Context, tracker *operations.Tracker) apierror.APIErrors {
+		tracker.Detail("stage_id", id)
+
+		for position > 0 {
+			tracker.Progress(models.OperationPending, fmt.Sprintf("queued at position %d", position))
+			time.Sleep(stagingQueuePollInterval)
+
+			positions, err := application.AdmitStaging(ctx, cluster)
+			if err != nil {
+				return apierror.InternalError(err, "admitting queued staging jobs")
+			}
+			position = positions[id]
+		}
+
+		tracker.Progress(models.OperationRunning, "building the image")
+		return waitForStaging(ctx, cluster, namespace, id)
+	}
+}
+
+// StagingQueue handles the API endpoint /namespaces/:namespace/staging/:stage_id/queue
+// It returns the position of the staging job in the queue of jobs waiting for a free slot.
+func (hc Controller) StagingQueue(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	id := c.Param("stage_id")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	positions, err := application.AdmitStaging(ctx, cluster)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, models.StagingQueueResponse{
+		Position: positions[id],
+	})
 	return nil
 }
 
@@ -403,6 +633,27 @@ func newJobRun(app stageParam) (*batchv1.Job, *corev1.Secret) {
 		},
 	}
 
+	// The build script hands the ordered list to the lifecycle, replacing the buildpack
+	// order of the builder.
+	if len(app.Buildpacks) > 0 {
+		stageEnv = append(stageEnv, corev1.EnvVar{
+			Name:  "BUILDPACKS",
+			Value: strings.Join(app.Buildpacks, ","),
+		})
+	}
+
+	// The build script keeps the cache either in the volume, or in the cache image.
+	stageEnv = append(stageEnv, corev1.EnvVar{
+		Name:  "CACHE",
+		Value: app.Cache,
+	})
+	if app.Cache == models.BuildCacheRegistry {
+		stageEnv = append(stageEnv, corev1.EnvVar{
+			Name:  "CACHEIMAGE",
+			Value: app.CacheImageURL(app.RegistryURL),
+		})
+	}
+
 	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "s3-creds",
@@ -417,7 +668,7 @@ func newJobRun(app stageParam) (*batchv1.Job, *corev1.Secret) {
 		{
 			Name:      "cache",
 			SubPath:   "cache",
-			MountPath: "/workspace/cache",
+			MountPath: stagingCacheDir,
 		},
 		{
 			Name:      "registry-creds",
@@ -500,16 +751,24 @@ func newJobRun(app stageParam) (*batchv1.Job, *corev1.Secret) {
 		},
 	}
 
+	if app.Cache != models.BuildCacheVolume {
+		volumes, volumeMounts = unmountCache(volumes, volumeMounts)
+	}
+
 	volumes, volumeMounts = mountS3Certs(volumes, volumeMounts)
 	volumes, volumeMounts = mountRegistryCerts(app, volumes, volumeMounts)
 
 	// Create job environment as a copy of the app environment, plus standard variable.
+	// The build-time variables come last, overriding runtime variables of the same name.
 	env := make(map[string][]byte)
 
 	env["CNB_PLATFORM_API"] = []byte("0.4")
 	for _, ev := range app.Environment {
 		env[ev.Name] = []byte(ev.Value)
 	}
+	for name, value := range app.BuildEnvironment {
+		env[name] = []byte(value)
+	}
 
 	jobenv := &corev1.Secret{
 		Data: env,
@@ -597,22 +856,142 @@ func newJobRun(app stageParam) (*batchv1.Job, *corev1.Secret) {
 							},
 							Env:          stageEnv,
 							VolumeMounts: volumeMounts,
+							Resources:    app.Resources,
+							// The buildpacks comply with the restricted pod security level.
 							SecurityContext: &corev1.SecurityContext{
-								RunAsUser:  pointer.Int64(1000),
-								RunAsGroup: pointer.Int64(1000),
+								RunAsUser:                pointer.Int64(1000),
+								RunAsGroup:               pointer.Int64(1000),
+								RunAsNonRoot:             pointer.Bool(true),
+								AllowPrivilegeEscalation: pointer.Bool(false),
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+								},
 							},
 						},
 					},
+					SecurityContext: &corev1.PodSecurityContext{
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
 					RestartPolicy: corev1.RestartPolicyNever,
 					Volumes:       volumes,
+					NodeSelector:  app.NodeSelector,
+					Tolerations:   app.Tolerations,
 				},
 			},
 		},
 	}
 
+	// A queued job is created suspended, and started by the queue when there is room.
+	if app.Queued {
+		job.Spec.Suspend = pointer.Bool(true)
+	}
+
+	// A job running past its deadline is stopped, and reported as failed.
+	if app.Timeout > 0 {
+		job.Spec.ActiveDeadlineSeconds = pointer.Int64(app.Timeout)
+	}
+
+	if app.Dockerfile != "" {
+		job.Spec.Template.Spec.Containers = []corev1.Container{
+			newDockerfileContainer(app, stageEnv, volumeMounts),
+		}
+	}
+
 	return job, jobenv
 }
 
+// newDockerfileContainer is a helper which creates the container building the application
+// sources with their Dockerfile, replacing the buildpack container of the staging job. The
+// container runs kaniko, pushing the image to the same location as the buildpacks would.
+func newDockerfileContainer(app stageParam, stageEnv []corev1.EnvVar, volumeMounts []corev1.VolumeMount) corev1.Container {
+	args := []string{
+		fmt.Sprintf("--dockerfile=%s", path.Join(stagingSourceDir, app.Dockerfile)),
+		fmt.Sprintf("--context=dir://%s", stagingSourceDir),
+		fmt.Sprintf("--destination=%s", app.ImageURL(app.RegistryURL)),
+	}
+
+	// kaniko caches layers only in a registry. The volume holds the base images.
+	switch app.Cache {
+	case models.BuildCacheRegistry:
+		args = append(args, "--cache=true", fmt.Sprintf("--cache-repo=%s", app.CacheImageURL(app.RegistryURL)))
+	case models.BuildCacheVolume:
+		args = append(args, fmt.Sprintf("--cache-dir=%s", stagingCacheDir))
+	}
+
+	// kaniko looks for the registry credentials in a different place than the buildpacks.
+	mounts := []corev1.VolumeMount{}
+	for _, mount := range volumeMounts {
+		if mount.Name == "registry-creds" {
+			mount.MountPath = "/kaniko/.docker/"
+		}
+		mounts = append(mounts, mount)
+	}
+
+	// kaniko is told directly about the certificate to trust.
+	if app.RegistryCASecret != "" && app.RegistryCAHash != "" {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "registry-certs",
+			MountPath: "/kaniko/certs/registry.crt",
+			SubPath:   "tls.crt",
+			ReadOnly:  true,
+		})
+
+		registryHost := strings.SplitN(app.RegistryURL, "/", 2)[0]
+		args = append(args, fmt.Sprintf("--registry-certificate=%s=/kaniko/certs/registry.crt", registryHost))
+	}
+
+	return corev1.Container{
+		Name:         "dockerfile",
+		Image:        app.DockerfileImage,
+		Args:         args,
+		Env:          stageEnv,
+		VolumeMounts: mounts,
+		Resources:    app.Resources,
+	}
+}
+
+// globalStagingResources returns the staging resources set in the staging configuration,
+// i.e. the defaults for all namespaces.
+func globalStagingResources(config map[string]string) (models.StagingResources, error) {
+	resources := models.StagingResources{
+		CPURequest:    config["cpuRequest"],
+		CPULimit:      config["cpuLimit"],
+		MemoryRequest: config["memoryRequest"],
+		MemoryLimit:   config["memoryLimit"],
+	}
+
+	if timeout := config["timeout"]; timeout != "" {
+		seconds, err := strconv.ParseInt(timeout, 10, 64)
+		if err != nil {
+			return resources, errors.Wrapf(err, "bad staging timeout '%s'", timeout)
+		}
+		resources.Timeout = seconds
+	}
+
+	return resources, application.ValidateStagingResources(resources)
+}
+
+// validateCache checks that the build cache is of a known kind.
+func validateCache(cache string) apierror.APIErrors {
+	switch cache {
+	case models.BuildCacheVolume, models.BuildCacheRegistry, models.BuildCacheNone:
+		return nil
+	}
+	return apierror.NewBadRequest(fmt.Sprintf("build cache has to be one of '%s', '%s', or '%s'",
+		models.BuildCacheVolume, models.BuildCacheRegistry, models.BuildCacheNone), cache)
+}
+
+// validateDockerfile checks that the Dockerfile path stays within the application sources.
+func validateDockerfile(dockerfile string) apierror.APIErrors {
+	clean := path.Clean(dockerfile)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return apierror.NewBadRequest("dockerfile path has to be relative to, and inside of, the application sources", dockerfile)
+	}
+	return nil
+}
+
 func getRegistryURL(ctx context.Context, cluster *kubernetes.Cluster) (string, error) {
 	cd, err := registry.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), registry.CredentialsSecretName)
 	if err != nil {
@@ -780,3 +1159,22 @@ func mountRegistryCerts(app stageParam, volumes []corev1.Volume, volumeMounts []
 
 	return volumes, volumeMounts
 }
+
+// unmountCache removes the build cache volume, for a build not keeping its cache in it.
+func unmountCache(volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) ([]corev1.Volume, []corev1.VolumeMount) {
+	keptVolumes := []corev1.Volume{}
+	for _, volume := range volumes {
+		if volume.Name != "cache" {
+			keptVolumes = append(keptVolumes, volume)
+		}
+	}
+
+	keptMounts := []corev1.VolumeMount{}
+	for _, mount := range volumeMounts {
+		if mount.Name != "cache" {
+			keptMounts = append(keptMounts, mount)
+		}
+	}
+
+	return keptVolumes, keptMounts
+}
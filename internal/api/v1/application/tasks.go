@@ -0,0 +1,97 @@
+package application
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Tasks handles the API endpoint GET /namespaces/:namespace/applications/:app/tasks
+// It returns the scheduled tasks of the specified application, with their activity.
+func (hc Controller) Tasks(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	exists, err := application.Exists(ctx, cluster, models.NewAppRef(appName, namespace))
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if !exists {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	tasks, err := application.TaskStates(ctx, cluster, models.NewAppRef(appName, namespace))
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, tasks)
+	return nil
+}
+
+// TaskRun handles the API endpoint POST /namespaces/:namespace/applications/:app/tasks/:task/run
+// It runs the specified task of the application immediately, outside of its schedule.
+func (hc Controller) TaskRun(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	appName := c.Param("app")
+	taskName := c.Param("task")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := hc.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	app, err := application.Lookup(ctx, cluster, namespace, appName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if app == nil {
+		return apierror.AppIsNotKnown(appName)
+	}
+
+	known := false
+	for _, task := range app.Configuration.Tasks {
+		if task.Name == taskName {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return apierror.NewNotFoundError("task not found", taskName)
+	}
+
+	if app.Workload == nil {
+		return apierror.NewBadRequest("Unable to run a task of an application without workload", taskName)
+	}
+
+	job, err := application.TaskRun(ctx, cluster, app.Meta, taskName)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if job == "" {
+		return apierror.NewNotFoundError("task has no cron job, the app chart does not support tasks", taskName)
+	}
+
+	response.OKReturn(c, models.AppTaskRunResponse{Job: job})
+	return nil
+}
@@ -1,7 +1,9 @@
 package application
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
@@ -11,15 +13,19 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/internal/routes"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Update handles the API endpoint PATCH /namespaces/:namespace/applications/:app
-func (hc Controller) Update(c *gin.Context) apierror.APIErrors { // nolint:gocyclo // simplification defered
+func (hc Controller) Update(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 	namespace := c.Param("namespace")
 	appName := c.Param("app")
@@ -52,24 +58,151 @@ func (hc Controller) Update(c *gin.Context) apierror.APIErrors { // nolint:gocyc
 		return apierror.BadRequest(err)
 	}
 
+	if err := hc.update(ctx, cluster, namespace, appName, username, updateRequest, true); err != nil {
+		return err
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// update applies the changes of the request to the existing application, after validating
+// them. With `redeploy` an active application is re-deployed with the changed state.
+func (hc Controller) update(ctx context.Context, cluster *kubernetes.Cluster, namespace, appName, username string, updateRequest models.ApplicationUpdateRequest, redeploy bool) apierror.APIErrors { // nolint:gocyclo // simplification defered
 	if updateRequest.Instances != nil && *updateRequest.Instances < 0 {
 		return apierror.NewBadRequest("instances param should be integer equal or greater than zero")
 	}
 
+	if updateRequest.Rollout != nil {
+		if err := validateRollout(*updateRequest.Rollout); err != nil {
+			return err
+		}
+	}
+
+	if err := routes.ValidateRoutes(updateRequest.Routes); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if err := application.ValidateRouteSecrets(ctx, cluster, namespace, updateRequest.Routes); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if err := application.ValidateRouteAnnotations(updateRequest.RouteAnnotations); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if err := application.ValidateTasks(updateRequest.Tasks); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if err := application.ValidateProcesses(updateRequest.Processes); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if updateRequest.Migration != nil {
+		if err := application.ValidateMigration(*updateRequest.Migration); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	if err := application.ValidateHooks(updateRequest.Hooks); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if updateRequest.PullSecret != nil {
+		if err := application.ValidatePullSecret(*updateRequest.PullSecret); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	if updateRequest.AutoSleep != nil {
+		if err := application.ValidateAutoSleep(*updateRequest.AutoSleep); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	if err := application.ValidatePorts(updateRequest.Ports); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if updateRequest.Placement != nil {
+		if err := application.ValidatePlacement(*updateRequest.Placement); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	if updateRequest.Termination != nil {
+		if err := application.ValidateTermination(*updateRequest.Termination); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	if updateRequest.Entrypoint != nil {
+		if err := application.ValidateEntrypoint(*updateRequest.Entrypoint); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
 	app, err := application.Lookup(ctx, cluster, namespace, appName)
 	if err != nil {
 		return apierror.InternalError(err)
 	}
 
-	// Check if the request contains any changes. Abort early if not.
+	// Volumes are checked against the instances after the update.
+	instances := *app.Configuration.Instances
+	if updateRequest.Instances != nil {
+		instances = *updateRequest.Instances
+	}
+	volumes := application.MergeVolumes(app.Configuration.Volumes, updateRequest.Volumes)
+	if err := application.ValidateVolumes(volumes, instances); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	// Sidecars may only mount the configurations bound after the update.
+	sidecars := updateRequest.Sidecars
+	if sidecars == nil {
+		sidecars = app.Configuration.Sidecars
+	}
+	bound := updateRequest.Configurations
+	if bound == nil {
+		bound = app.Configuration.Configurations
+	}
+	if err := application.ValidateSidecars(sidecars, bound); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+	if err := application.ValidateConfigurationPaths(updateRequest.ConfigurationPaths, bound); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	// A pull secret without password keeps the saved credentials, which have to exist.
+	if pullSecret := updateRequest.PullSecret; pullSecret != nil && pullSecret.Registry != "" && pullSecret.Password == "" {
+		current := app.Configuration.PullSecret
+		if current == nil || current.Registry != pullSecret.Registry || current.Username != pullSecret.Username {
+			return apierror.NewBadRequest(fmt.Sprintf("pull secret for registry '%s' has no password", pullSecret.Registry))
+		}
+	}
 
-	// if there is nothing to change
-	if updateRequest.Instances == nil &&
-		len(updateRequest.Environment) == 0 &&
-		updateRequest.Configurations == nil &&
-		len(updateRequest.Routes) == 0 &&
-		updateRequest.AppChart == "" {
-		response.OK(c)
+	// Chart values are checked against the settings of the chart used after the update.
+	chartValues := application.MergeChartValues(app.Configuration.ChartValues, updateRequest.ChartValues)
+	chartName := updateRequest.AppChart
+	if chartName == "" {
+		chartName = app.Configuration.AppChart
+	}
+	if len(chartValues) > 0 {
+		appChart, err := appchart.Lookup(ctx, cluster, chartName)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		if appChart == nil {
+			return apierror.AppChartIsNotKnown(chartName)
+		}
+		if _, err := appchart.Values(appChart, chartValues); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	// Check if the request contains any changes. Abort early if not.
+	if updateRequestEmpty(updateRequest) {
 		return nil
 	}
 
@@ -88,6 +221,14 @@ func (hc Controller) Update(c *gin.Context) apierror.APIErrors { // nolint:gocyc
 			return apierror.AppChartIsNotKnown(updateRequest.AppChart)
 		}
 
+		space, err := namespaces.Get(ctx, cluster, namespace)
+		if err != nil {
+			return apierror.InternalError(err, "failed to retrieve the namespace settings")
+		}
+		if space != nil && !space.Settings.AppChartAllowed(updateRequest.AppChart) {
+			return apierror.NewBadRequest(fmt.Sprintf("app chart '%s' is not allowed in namespace '%s'", updateRequest.AppChart, namespace))
+		}
+
 		client, err := cluster.ClientApp()
 		if err != nil {
 			return apierror.InternalError(err)
@@ -116,6 +257,97 @@ func (hc Controller) Update(c *gin.Context) apierror.APIErrors { // nolint:gocyc
 		}
 	}
 
+	if updateRequest.Rollout != nil {
+		err := application.RolloutSet(ctx, cluster, app.Meta, *updateRequest.Rollout)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Tasks != nil {
+		err := application.TasksSet(ctx, cluster, app.Meta, updateRequest.Tasks)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(updateRequest.Processes) > 0 {
+		err := application.ProcessesSet(ctx, cluster, app.Meta, updateRequest.Processes)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Migration != nil {
+		err := application.MigrationSet(ctx, cluster, app.Meta, *updateRequest.Migration)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(updateRequest.ChartValues) > 0 {
+		err := application.ChartValuesSet(ctx, cluster, app.Meta, updateRequest.ChartValues)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Hooks != nil {
+		err := application.HooksSet(ctx, cluster, app.Meta, updateRequest.Hooks)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.PullSecret != nil {
+		err := application.PullSecretSet(ctx, cluster, app.Meta, *updateRequest.PullSecret)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if len(updateRequest.Volumes) > 0 {
+		err := application.VolumesSet(ctx, cluster, app.Meta, updateRequest.Volumes)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Entrypoint != nil {
+		err := application.EntrypointSet(ctx, cluster, app.Meta, *updateRequest.Entrypoint)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Termination != nil {
+		err := application.TerminationSet(ctx, cluster, app.Meta, *updateRequest.Termination)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Placement != nil {
+		err := application.PlacementSet(ctx, cluster, app.Meta, *updateRequest.Placement)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Ports != nil {
+		err := application.PortsSet(ctx, cluster, app.Meta, updateRequest.Ports)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
+	if updateRequest.Sidecars != nil {
+		err := application.SidecarsSet(ctx, cluster, app.Meta, updateRequest.Sidecars)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
+
 	if len(updateRequest.Environment) > 0 {
 		err := application.EnvironmentSet(ctx, cluster, app.Meta, updateRequest.Environment, true)
 		if err != nil {
@@ -154,39 +386,115 @@ func (hc Controller) Update(c *gin.Context) apierror.APIErrors { // nolint:gocyc
 		}
 	}
 
-	// Only update the app if routes have been set, otherwise just leave it
-	// as it is.
-	if len(updateRequest.Routes) > 0 {
-		client, err := cluster.ClientApp()
+	if len(updateRequest.ConfigurationPaths) > 0 {
+		err := application.BoundConfigurationPathsSet(ctx, cluster, app.Meta, updateRequest.ConfigurationPaths)
 		if err != nil {
 			return apierror.InternalError(err)
 		}
+	}
 
-		routes := []string{}
-		for _, d := range updateRequest.Routes {
-			routes = append(routes, fmt.Sprintf("%q", d))
+	if updateRequest.RouteAnnotations != nil {
+		err := application.RouteAnnotationsSet(ctx, cluster, app.Meta, updateRequest.RouteAnnotations)
+		if err != nil {
+			return apierror.InternalError(err)
 		}
+	}
 
-		patch := fmt.Sprintf(`[{
-			"op": "replace",
-			"path": "/spec/routes",
-			"value": [%s] }]`,
-			strings.Join(routes, ","))
+	if updateRequest.AutoSleep != nil {
+		err := application.AutoSleepSet(ctx, cluster, app.Meta, *updateRequest.AutoSleep)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	}
 
-		_, err = client.Namespace(app.Meta.Namespace).Patch(ctx, app.Meta.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{})
+	// Only update the app if routes have been set, otherwise just leave it
+	// as it is.
+	if len(updateRequest.Routes) > 0 {
+		err := application.RoutesSet(ctx, cluster, app.Meta, updateRequest.Routes)
 		if err != nil {
 			return apierror.InternalError(err)
 		}
 	}
 
 	// With everything saved, and a workload to update, re-deploy the changed state.
-	if app.Workload != nil {
+	if redeploy && app.Workload != nil {
 		_, apierr := deploy.DeployApp(ctx, cluster, app.Meta, username, "", nil, nil)
 		if apierr != nil {
 			return apierr
 		}
 	}
 
-	response.OK(c)
+	if updateRequest.Instances != nil {
+		events.Publish(ctx, models.EventAppScaled, namespace, appName,
+			map[string]string{"instances": strconv.Itoa(int(*updateRequest.Instances))})
+	}
+	otherChanges := updateRequest
+	otherChanges.Instances = nil
+	if !updateRequestEmpty(otherChanges) {
+		events.Publish(ctx, models.EventAppUpdated, namespace, appName, nil)
+	}
+
+	return nil
+}
+
+// updateRequestEmpty returns true if the request changes nothing.
+func updateRequestEmpty(req models.ApplicationUpdateRequest) bool {
+	return req.Instances == nil &&
+		len(req.Environment) == 0 &&
+		req.Configurations == nil &&
+		len(req.Routes) == 0 &&
+		req.AppChart == "" &&
+		req.Rollout == nil &&
+		req.Tasks == nil &&
+		len(req.Processes) == 0 &&
+		req.Sidecars == nil &&
+		req.Migration == nil &&
+		req.Hooks == nil &&
+		len(req.Volumes) == 0 &&
+		len(req.ConfigurationPaths) == 0 &&
+		req.RouteAnnotations == nil &&
+		req.AutoSleep == nil &&
+		req.Ports == nil &&
+		req.Placement == nil &&
+		req.Termination == nil &&
+		req.Entrypoint == nil &&
+		len(req.ChartValues) == 0 &&
+		req.PullSecret == nil
+}
+
+// validateRollout checks that the rolling update parameters are each either a non-negative
+// integer or a percentage, and that they do not both forbid replacing instances.
+func validateRollout(rollout models.AppRollout) apierror.APIErrors {
+	zero := 0
+	for _, param := range []struct{ name, value string }{
+		{"maxunavailable", rollout.MaxUnavailable},
+		{"maxsurge", rollout.MaxSurge},
+	} {
+		name, value := param.name, param.value
+		if value == "" {
+			continue
+		}
+		if strings.HasSuffix(value, "%") {
+			if len(validation.IsValidPercent(value)) > 0 {
+				return apierror.NewBadRequest(fmt.Sprintf("%s param should be a non-negative integer or percentage", name))
+			}
+			if strings.TrimLeft(strings.TrimSuffix(value, "%"), "0") == "" {
+				zero++
+			}
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return apierror.NewBadRequest(fmt.Sprintf("%s param should be a non-negative integer or percentage", name))
+		}
+		if n == 0 {
+			zero++
+		}
+	}
+
+	if zero == 2 {
+		return apierror.NewBadRequest("maxunavailable and maxsurge params cannot both be zero")
+	}
+
 	return nil
 }
@@ -1,8 +1,14 @@
 package application
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/helmchart"
 	"github.com/epinio/epinio/internal/s3manager"
@@ -11,9 +17,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxUploadPartSize is the size limit of a part of an upload in parts, and of a chunk of a
+// delta upload. Both are passed through to the S3 store, they are not buffered.
+const maxUploadPartSize = 1024 * 1024 * 1024
+
 // Upload handles the API endpoint /namespaces/:namespace/applications/:app/store.
 // It receives the application data as a tarball and stores it. Then
-// it creates the k8s resources needed for staging
+// it creates the k8s resources needed for staging.
+// The tarball is streamed from the multipart form to the S3 store, without buffering it.
 func (hc Controller) Upload(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 	log := requestctx.Logger(ctx)
@@ -23,40 +34,389 @@ func (hc Controller) Upload(c *gin.Context) apierror.APIErrors {
 
 	log.Info("processing upload", "namespace", namespace, "app", name)
 
-	log.V(2).Info("parsing multipart form")
+	limit, apierr := uploadLimit(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+	if limit > 0 && c.Request.ContentLength > limit+multipartOverhead {
+		return uploadTooLarge(limit)
+	}
+
+	log.V(2).Info("reading multipart form")
 
-	file, fileheader, err := c.Request.FormFile("file")
+	reader, err := c.Request.MultipartReader()
 	if err != nil {
 		return apierror.BadRequest(err, "can't read multipart file input")
 	}
-	defer file.Close()
+
+	var file io.Reader
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return apierror.BadRequest(http.ErrMissingFile, "can't read multipart file input")
+		}
+		if err != nil {
+			return apierror.BadRequest(err, "can't read multipart file input")
+		}
+		if part.FormName() == "file" {
+			defer part.Close()
+			file = part
+			break
+		}
+	}
+
+	// Without a content length, e.g. for chunked requests, the limit is checked while the
+	// sources are streamed to the store.
+	var limited *limitedUpload
+	if limit > 0 {
+		limited = &limitedUpload{reader: file, limit: limit}
+		file = limited
+	}
+
+	manager, apierr := uploadManager(ctx)
+	if apierr != nil {
+		return apierr
+	}
+
+	username := requestctx.User(ctx).Username
+	blobUID, err := manager.UploadStream(ctx, file, -1, map[string]string{
+		"app": name, "namespace": namespace, "username": username,
+	})
+	if limited != nil && limited.exceeded {
+		return uploadTooLarge(limit)
+	}
+	if err != nil {
+		return apierror.InternalError(err, "uploading the application sources blob")
+	}
+
+	log.Info("uploaded app", "namespace", namespace, "app", name, "blobUID", blobUID)
+
+	response.OKReturn(c, models.UploadResponse{
+		BlobUID: blobUID,
+	})
+	return nil
+}
+
+// UploadStart handles the API endpoint POST /namespaces/:namespace/applications/:app/store/parts
+// It starts an upload of the application data in parts, for clients which cannot, or do
+// not want to, send a large tarball in one request. Failed parts can be sent again.
+func (hc Controller) UploadStart(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	log := requestctx.Logger(ctx)
+
+	namespace := c.Param("namespace")
+	name := c.Param("app")
+
+	manager, apierr := uploadManager(ctx)
+	if apierr != nil {
+		return apierr
+	}
+
+	username := requestctx.User(ctx).Username
+	blobUID, uploadID, err := manager.StartUpload(ctx, map[string]string{
+		"app": name, "namespace": namespace, "username": username,
+	})
+	if err != nil {
+		return apierror.InternalError(err, "starting the upload of the application sources blob")
+	}
+
+	log.Info("started upload", "namespace", namespace, "app", name, "blobUID", blobUID)
+
+	response.OKReturn(c, models.UploadStartResponse{
+		BlobUID:  blobUID,
+		UploadID: uploadID,
+	})
+	return nil
+}
+
+// UploadPart handles the API endpoint PUT /namespaces/:namespace/applications/:app/store/parts/:blobuid
+// It stores the part of the upload given by the query parameters `upload` and `part`. The
+// body of the request is the part. The upload is discarded when its parts together exceed the
+// upload limit of the namespace.
+func (hc Controller) UploadPart(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	blobUID := c.Param("blobuid")
+	uploadID := c.Query("upload")
+	if uploadID == "" {
+		return apierror.NewBadRequest("upload id missing")
+	}
+
+	part, err := strconv.Atoi(c.Query("part"))
+	if err != nil || part < 1 || part > s3manager.MaxParts {
+		return apierror.NewBadRequest("bad part number", c.Query("part"))
+	}
+
+	size := c.Request.ContentLength
+	if size < 0 {
+		return apierror.NewBadRequest("part size missing")
+	}
+	if size > maxUploadPartSize {
+		return apierror.NewBadRequest("part too large", strconv.FormatInt(size, 10))
+	}
+
+	limit, apierr := uploadLimit(ctx, c.Param("namespace"))
+	if apierr != nil {
+		return apierr
+	}
+	if limit > 0 && size > limit {
+		return uploadTooLarge(limit)
+	}
+
+	manager, apierr := uploadManager(ctx)
+	if apierr != nil {
+		return apierr
+	}
+
+	if limit > 0 {
+		sizes, err := manager.PartSizes(ctx, blobUID, uploadID)
+		if err != nil {
+			return apierror.InternalError(err, "sizing the application sources blob")
+		}
+		if UploadTotal(sizes, part, size) > limit {
+			if err := manager.AbortUpload(ctx, blobUID, uploadID); err != nil {
+				return apierror.InternalError(err, "aborting the upload of the application sources blob")
+			}
+			return uploadTooLarge(limit)
+		}
+	}
+
+	err = manager.UploadPart(ctx, blobUID, uploadID, part, c.Request.Body, size)
+	if err != nil {
+		return apierror.InternalError(err, "uploading a part of the application sources blob")
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// UploadFinish handles the API endpoint POST /namespaces/:namespace/applications/:app/store/parts/:blobuid
+// It assembles the parts of the upload given by the query parameter `upload` into the
+// application sources blob.
+func (hc Controller) UploadFinish(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	log := requestctx.Logger(ctx)
+
+	namespace := c.Param("namespace")
+	name := c.Param("app")
+	blobUID := c.Param("blobuid")
+	uploadID := c.Query("upload")
+	if uploadID == "" {
+		return apierror.NewBadRequest("upload id missing")
+	}
+
+	manager, apierr := uploadManager(ctx)
+	if apierr != nil {
+		return apierr
+	}
+
+	limit, apierr := uploadLimit(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+	if limit > 0 {
+		size, err := manager.UploadSize(ctx, blobUID, uploadID)
+		if err != nil {
+			return apierror.InternalError(err, "sizing the application sources blob")
+		}
+		if size > limit {
+			if err := manager.AbortUpload(ctx, blobUID, uploadID); err != nil {
+				return apierror.InternalError(err, "aborting the upload of the application sources blob")
+			}
+			return uploadTooLarge(limit)
+		}
+	}
+
+	err := manager.CompleteUpload(ctx, blobUID, uploadID)
+	if err != nil {
+		return apierror.InternalError(err, "assembling the application sources blob")
+	}
+
+	log.Info("uploaded app", "namespace", namespace, "app", name, "blobUID", blobUID)
+
+	response.OKReturn(c, models.UploadResponse{
+		BlobUID: blobUID,
+	})
+	return nil
+}
+
+// UploadAbort handles the API endpoint DELETE /namespaces/:namespace/applications/:app/store/parts/:blobuid
+// It discards the upload given by the query parameter `upload`, and its parts.
+func (hc Controller) UploadAbort(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	blobUID := c.Param("blobuid")
+	uploadID := c.Query("upload")
+	if uploadID == "" {
+		return apierror.NewBadRequest("upload id missing")
+	}
+
+	manager, apierr := uploadManager(ctx)
+	if apierr != nil {
+		return apierr
+	}
+
+	err := manager.AbortUpload(ctx, blobUID, uploadID)
+	if err != nil {
+		return apierror.InternalError(err, "aborting the upload of the application sources blob")
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// ChunksMissing handles the API endpoint POST /namespaces/:namespace/applications/:app/store/chunks
+// It returns which of the requested chunks of application sources to upload as delta are not
+// cached for the application, i.e. have to be uploaded.
+func (hc Controller) ChunksMissing(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	appRef := models.NewAppRef(c.Param("app"), c.Param("namespace"))
+
+	var req models.SourceChunksRequest
+	if err := c.BindJSON(&req); err != nil {
+		return apierror.BadRequest(err)
+	}
 
 	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err, "failed to get access to a kube client")
 	}
 
-	connectionDetails, err := s3manager.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), helmchart.S3ConnectionDetailsSecretName)
+	for _, hash := range req.Chunks {
+		if err := application.ValidateChunkHash(hash); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	missing, err := application.SourceChunksMissing(ctx, cluster, appRef, req.Chunks)
 	if err != nil {
-		return apierror.InternalError(err, "fetching the S3 connection details from the Kubernetes secret")
+		return apierror.InternalError(err, "checking the cached chunks")
 	}
-	manager, err := s3manager.New(connectionDetails)
+
+	response.OKReturn(c, models.SourceChunksResponse{
+		Missing: missing,
+	})
+	return nil
+}
+
+// ChunkUpload handles the API endpoint PUT /namespaces/:namespace/applications/:app/store/chunks/:hash
+// It caches the chunk of application sources to upload as delta. The body of the request is
+// the chunk, and has to match the hash.
+func (hc Controller) ChunkUpload(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	appRef := models.NewAppRef(c.Param("app"), c.Param("namespace"))
+	hash := c.Param("hash")
+
+	if err := application.ValidateChunkHash(hash); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	size := c.Request.ContentLength
+	if size < 0 {
+		return apierror.NewBadRequest("chunk size missing")
+	}
+	if size > maxUploadPartSize {
+		return apierror.NewBadRequest("chunk too large", strconv.FormatInt(size, 10))
+	}
+
+	limit, apierr := uploadLimit(ctx, appRef.Namespace)
+	if apierr != nil {
+		return apierr
+	}
+	if limit > 0 && size > limit {
+		return uploadTooLarge(limit)
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err, "failed to get access to a kube client")
+	}
+
+	err = application.SourceChunkStore(ctx, cluster, appRef, hash, c.Request.Body, size)
 	if err != nil {
-		return apierror.InternalError(err, "creating an S3 manager")
+		return apierror.InternalError(err, "caching the chunk")
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// UploadDelta handles the API endpoint POST /namespaces/:namespace/applications/:app/store/delta
+// It receives the application data as a list of files, with the contents of regular files
+// given as cached chunks, and stores the tarball assembled from them.
+func (hc Controller) UploadDelta(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	log := requestctx.Logger(ctx)
+
+	namespace := c.Param("namespace")
+	name := c.Param("app")
+
+	var req models.SourceDeltaRequest
+	if err := c.BindJSON(&req); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if err := application.ValidateSourceFiles(req.Files); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	limit, apierr := uploadLimit(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+	if limit > 0 && application.SourceFilesSize(req.Files) > limit {
+		return uploadTooLarge(limit)
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err, "failed to get access to a kube client")
 	}
 
 	username := requestctx.User(ctx).Username
-	blobUID, err := manager.UploadStream(ctx, file, fileheader.Size, map[string]string{
-		"app": name, "namespace": namespace, "username": username,
-	})
+	blobUID, err := application.SourceDeltaAssemble(ctx, cluster, models.NewAppRef(name, namespace), req.Files,
+		map[string]string{
+			"app": name, "namespace": namespace, "username": username,
+		})
 	if err != nil {
-		return apierror.InternalError(err, "uploading the application sources blob")
+		return apierror.InternalError(err, "assembling the application sources blob")
 	}
 
-	log.Info("uploaded app", "namespace", namespace, "app", name, "blobUID", blobUID)
+	log.Info("uploaded app", "namespace", namespace, "app", name, "blobUID", blobUID, "files", len(req.Files))
 
 	response.OKReturn(c, models.UploadResponse{
 		BlobUID: blobUID,
 	})
 	return nil
 }
+
+// UploadTotal returns the size of an upload in parts with the part of the given number and
+// size added to the stored parts. The part replaces a stored part of the same number.
+func UploadTotal(sizes map[int]int64, part int, size int64) int64 {
+	total := size
+	for number, partSize := range sizes {
+		if number != part {
+			total += partSize
+		}
+	}
+	return total
+}
+
+// uploadManager returns the manager of the S3 store holding the application sources.
+func uploadManager(ctx context.Context) (*s3manager.Manager, apierror.APIErrors) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return nil, apierror.InternalError(err, "failed to get access to a kube client")
+	}
+
+	connectionDetails, err := s3manager.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), helmchart.S3ConnectionDetailsSecretName)
+	if err != nil {
+		return nil, apierror.InternalError(err, "fetching the S3 connection details from the Kubernetes secret")
+	}
+	manager, err := s3manager.New(connectionDetails)
+	if err != nil {
+		return nil, apierror.InternalError(err, "creating an S3 manager")
+	}
+
+	return manager, nil
+}
@@ -0,0 +1,26 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/application"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Application Upload API Endpoint unit tests", func() {
+
+	Describe("UploadTotal", func() {
+		It("adds the part to the stored parts", func() {
+			sizes := map[int]int64{1: 100, 2: 100}
+			Expect(application.UploadTotal(sizes, 3, 50)).To(Equal(int64(250)))
+		})
+
+		It("replaces the stored part of the same number", func() {
+			sizes := map[int]int64{1: 100, 2: 100}
+			Expect(application.UploadTotal(sizes, 2, 50)).To(Equal(int64(150)))
+		})
+
+		It("is the size of the first part", func() {
+			Expect(application.UploadTotal(map[int]int64{}, 1, 50)).To(Equal(int64(50)))
+		})
+	})
+})
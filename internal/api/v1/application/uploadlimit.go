@@ -0,0 +1,65 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// multipartOverhead is the allowance for the envelope of the multipart form of an upload,
+// i.e. its boundaries, and the headers of its parts, over the size of the sources. Larger
+// requests are rejected from their content length, before reading them.
+const multipartOverhead = 64 * 1024
+
+// errUploadTooLarge is the error of reading more sources than the limit of the upload.
+var errUploadTooLarge = errors.New("upload too large")
+
+// uploadLimit returns the size limit of the uploads to the namespace, zero for none.
+func uploadLimit(ctx context.Context, namespace string) (int64, apierror.APIErrors) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return 0, apierror.InternalError(err, "failed to get access to a kube client")
+	}
+
+	limit, err := namespaces.UploadLimit(ctx, cluster, namespace, viper.GetString("upload-limit"))
+	if err != nil {
+		return 0, apierror.InternalError(err, "reading the upload limit of the namespace")
+	}
+
+	return limit, nil
+}
+
+// uploadTooLarge returns the error for sources exceeding the limit of the namespace.
+func uploadTooLarge(limit int64) apierror.APIError {
+	return apierror.NewAPIError(
+		fmt.Sprintf("application sources exceed the upload limit of the namespace, %s",
+			resource.NewQuantity(limit, resource.BinarySI).String()),
+		"", http.StatusRequestEntityTooLarge)
+}
+
+// limitedUpload reads the sources of an upload, failing with errUploadTooLarge as soon as
+// they exceed the limit. The store then discards what it received.
+type limitedUpload struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (u *limitedUpload) Read(p []byte) (int, error) {
+	n, err := u.reader.Read(p)
+	u.read += int64(n)
+	if u.read > u.limit {
+		u.exceeded = true
+		return n, errUploadTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/audit"
+
+	"github.com/gin-gonic/gin"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// defaultAuditLimit is the number of audit entries returned when the request sets no limit.
+const defaultAuditLimit = 100
+
+// AuditEntries handles the API endpoint /audit. It returns the recent audit entries, the
+// newest first, optionally only those of a user, or namespace.
+func AuditEntries(c *gin.Context) APIErrors {
+	limit := defaultAuditLimit
+	if value := c.Query("limit"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return NewBadRequest("bad limit, expected a non-negative number of entries", value)
+		}
+		limit = number
+	}
+
+	response.OKReturn(c, audit.Default.Recent(c.Query("user"), c.Query("namespace"), limit))
+	return nil
+}
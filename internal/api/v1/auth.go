@@ -23,12 +23,21 @@ func AuthorizationMiddleware(c *gin.Context) {
 
 	logger.Info(fmt.Sprintf("authorization request from user [%s] with role [%s] for [%s - %s]", user.Username, user.Role, method, path))
 
+	// Read-only API tokens do not get into applications, whatever the role of their user.
+	if _, interactive := InteractiveRoutes[method+" "+c.FullPath()]; interactive &&
+		requestctx.APITokenScope(c.Request.Context()) == auth.APITokenScopeRead {
+		logger.Info(fmt.Sprintf("read-only token, [%s %s] unauthorized", method, c.FullPath()))
+		response.Error(c, apierrors.NewAPIError("token is read-only", "", http.StatusForbidden))
+		c.Abort()
+		return
+	}
+
 	var authorized bool
 	switch user.Role {
 	case "admin":
 		authorized = authorizeAdmin(logger)
 	case "user":
-		authorized = authorizeUser(logger, user, path, namespace)
+		authorized = authorizeUser(logger, user, method, c.FullPath(), path, namespace)
 	}
 
 	logger.Info(fmt.Sprintf("user [%s] with role [%s] authorized [%t] for namespace [%s]", user.Username, user.Role, authorized, namespace))
@@ -45,25 +54,42 @@ func authorizeAdmin(logger logr.Logger) bool {
 	return true
 }
 
-func authorizeUser(logger logr.Logger, user auth.User, path, namespace string) bool {
+func authorizeUser(logger logr.Logger, user auth.User, method, fullPath, path, namespace string) bool {
 	logger = logger.V(1).WithName("authorizeUser")
 
-	// check if the requested path is restricted
-	if _, found := AdminRoutes[path]; found {
+	// check if the requested path, or the route matching it, is restricted
+	_, found := AdminRoutes[path]
+	if _, foundRoute := AdminRoutes[fullPath]; found || foundRoute {
 		logger.Info(fmt.Sprintf("path [%s] is an admin route, user unauthorized", path))
 		return false
 	}
 
-	// check if the user has permission on the requested namespace
+	route := method + " " + fullPath
+
+	// check if the user's role in the requested namespace permits the request
 	if namespace != "" {
-		for _, ns := range user.Namespaces {
-			if namespace == ns {
-				return true
+		role := user.NamespaceRole(namespace)
+		switch role {
+		case "":
+			logger.Info(fmt.Sprintf("namespace [%s] is not in user namespaces [%s]", namespace, strings.Join(user.Namespaces, ", ")))
+			return false
+		case auth.NamespaceRoleReader:
+			_, interactive := InteractiveRoutes[route]
+			if interactive || (method != http.MethodGet && method != http.MethodHead) {
+				logger.Info(fmt.Sprintf("user is reader of namespace [%s], [%s] unauthorized", namespace, route))
+				return false
 			}
+		case auth.NamespaceRoleDeveloper:
+			if _, found := NamespaceAdminRoutes[route]; found {
+				logger.Info(fmt.Sprintf("user is developer of namespace [%s], [%s] unauthorized", namespace, route))
+				return false
+			}
+		case auth.NamespaceRoleAdmin:
+		default:
+			logger.Info(fmt.Sprintf("unknown role [%s] in namespace [%s], user unauthorized", role, namespace))
+			return false
 		}
-
-		logger.Info(fmt.Sprintf("namespace [%s] is not in user namespaces [%s]", namespace, strings.Join(user.Namespaces, ", ")))
-		return false
+		return true
 	}
 
 	// all non-admin routes are public
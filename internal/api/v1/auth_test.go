@@ -2,12 +2,15 @@ package v1_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/epinio/epinio/helpers/authtoken"
 	v1 "github.com/epinio/epinio/internal/api/v1"
 	"github.com/epinio/epinio/internal/auth"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
 	"github.com/go-logr/stdr"
 	. "github.com/onsi/ginkgo/v2"
@@ -80,4 +83,91 @@ var _ = Describe("Authorization Middleware", func() {
 			})
 		})
 	})
+
+	Context("user has roles in namespaces", func() {
+		var router *gin.Engine
+
+		request := func(method, path string) int {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(method, path, nil)
+			Expect(err).ToNot(HaveOccurred())
+			router.ServeHTTP(w, req.Clone(ctx))
+			return w.Code
+		}
+
+		BeforeEach(func() {
+			ctx = requestctx.WithUser(ctx, auth.User{
+				Role:       "user",
+				Namespaces: []string{"workspace", "staging", "production"},
+				NamespaceRoles: map[string]string{
+					"staging":    auth.NamespaceRoleDeveloper,
+					"production": auth.NamespaceRoleReader,
+				},
+			})
+
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router = gin.New()
+			api := router.Group(v1.Root, v1.AuthorizationMiddleware)
+			api.GET("/namespaces/:namespace", ok)
+			api.PATCH("/namespaces/:namespace", ok)
+			api.DELETE("/namespaces/:namespace", ok)
+			api.POST("/namespaces/:namespace/applications", ok)
+			wapi := router.Group(v1.WsRoot, v1.AuthorizationMiddleware)
+			wapi.GET("/namespaces/:namespace/applications/:app/exec", ok)
+			wapi.GET("/namespaces/:namespace/applications/:app/logs", ok)
+		})
+
+		It("lets readers look only", func() {
+			Expect(request(http.MethodGet, v1.Root+"/namespaces/production")).To(Equal(http.StatusOK))
+			Expect(request(http.MethodGet, v1.WsRoot+"/namespaces/production/applications/app/logs")).To(Equal(http.StatusOK))
+			Expect(request(http.MethodPost, v1.Root+"/namespaces/production/applications")).To(Equal(http.StatusUnauthorized))
+			Expect(request(http.MethodGet, v1.WsRoot+"/namespaces/production/applications/app/exec")).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("lets developers do all but change the namespace", func() {
+			Expect(request(http.MethodPost, v1.Root+"/namespaces/staging/applications")).To(Equal(http.StatusOK))
+			Expect(request(http.MethodGet, v1.WsRoot+"/namespaces/staging/applications/app/exec")).To(Equal(http.StatusOK))
+			Expect(request(http.MethodPatch, v1.Root+"/namespaces/staging")).To(Equal(http.StatusUnauthorized))
+			Expect(request(http.MethodDelete, v1.Root+"/namespaces/staging")).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("lets admins of the namespace do everything", func() {
+			Expect(request(http.MethodPatch, v1.Root+"/namespaces/workspace")).To(Equal(http.StatusOK))
+			Expect(request(http.MethodDelete, v1.Root+"/namespaces/workspace")).To(Equal(http.StatusOK))
+		})
+
+		It("keeps read-only tokens out of the applications, even for admins", func() {
+			ctx = requestctx.WithUser(ctx, auth.User{Username: "admin", Role: "admin"})
+			ctx = requestctx.WithAPITokenScope(ctx, auth.APITokenScopeRead)
+
+			Expect(request(http.MethodGet, v1.WsRoot+"/namespaces/workspace/applications/app/logs")).To(Equal(http.StatusOK))
+			Expect(request(http.MethodGet, v1.WsRoot+"/namespaces/workspace/applications/app/exec")).To(Equal(http.StatusForbidden))
+		})
+
+		It("gives read-only tokens websocket tokens which cannot get into applications", func() {
+			ctx = requestctx.WithUser(ctx, auth.User{Username: "admin", Role: "admin"})
+			ctx = requestctx.WithAPITokenScope(ctx, auth.APITokenScopeRead)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, err := http.NewRequest(http.MethodGet, v1.Root+"/authtoken", nil)
+			Expect(err).ToNot(HaveOccurred())
+			c.Request = req.Clone(ctx)
+
+			Expect(v1.AuthToken(c)).To(BeNil())
+			var resp models.AuthTokenResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+
+			claims, err := authtoken.Validate(resp.Token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claims.Scope).To(Equal(auth.APITokenScopeRead))
+
+			// The websocket request authenticated with the token, as tokenAuthMiddleware does.
+			ctx = requestctx.WithLogger(context.Background(), stdr.New(nil))
+			ctx = requestctx.WithUser(ctx, auth.User{Username: claims.Username, Role: claims.Role})
+			ctx = requestctx.WithAPITokenScope(ctx, claims.Scope)
+
+			Expect(request(http.MethodGet, v1.WsRoot+"/namespaces/workspace/applications/app/exec")).To(Equal(http.StatusForbidden))
+		})
+	})
 })
@@ -15,10 +15,18 @@ import (
 // token for further logins
 func AuthToken(c *gin.Context) APIErrors {
 	requestContext := c.Request.Context()
-	user := requestctx.User(requestContext).Username
+	user := requestctx.User(requestContext)
+
+	// The access of users may differ from the one of their Epinio user, as given by the
+	// groups of users authenticated by the OIDC issuer, or the namespaces of API tokens,
+	// and users without Epinio user are not known to the websocket endpoints at all. The
+	// token carries their access, and the scope of their API token, keeping read-only
+	// tokens out of the applications.
+	token := authtoken.CreateWithAccess(user.Username, user.Role, user.Namespaces, user.NamespaceRoles,
+		requestctx.APITokenScope(requestContext), authtoken.DefaultExpiry)
 
 	response.OKReturn(c, models.AuthTokenResponse{
-		Token: authtoken.Create(user, authtoken.DefaultExpiry),
+		Token: token,
 	})
 	return nil
 }
@@ -0,0 +1,7 @@
+// Package backup contains the API handlers to back up the state of the platform, and to
+// restore it.
+package backup
+
+// Controller represents all functionality of the API related to backups
+type Controller struct {
+}
@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/backup"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/registry"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// Create handles the API endpoint POST /backups
+// It captures the state of the platform, i.e. the namespaces with their apps, configurations,
+// and services, and the users, and keeps it in the blob store. It returns the meta data of
+// the backup.
+func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	var request models.BackupCreateRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	name := request.Name
+	if name == "" {
+		name = "backup-" + time.Now().UTC().Format("20060102-150405")
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return apierror.NewBadRequest("bad backup name", strings.Join(errs, ", "))
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	registryMeta, err := registryMeta(ctx, cluster)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	client, err := dynamic.NewForConfig(cluster.RestConfig)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	archive, err := backup.Create(ctx, client, helmchart.Namespace(), name, registryMeta)
+	if err != nil {
+		return apierror.InternalError(err, "capturing the platform state")
+	}
+
+	err = backup.Store(ctx, cluster, archive)
+	if err == backup.ErrBackupExists {
+		return apierror.NewAPIError("backup exists already", name, http.StatusConflict)
+	}
+	if err != nil {
+		return apierror.InternalError(err, "storing the backup")
+	}
+
+	response.OKReturn(c, archive.Meta)
+	return nil
+}
+
+// registryMeta returns the meta data of the registry of the installation, without its
+// credentials.
+func registryMeta(ctx context.Context, cluster *kubernetes.Cluster) (models.BackupRegistry, error) {
+	details, err := registry.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), registry.CredentialsSecretName)
+	if err != nil {
+		return models.BackupRegistry{}, errors.Wrap(err, "fetching the registry connection details")
+	}
+
+	url, err := details.PublicRegistryURL()
+	if err != nil {
+		return models.BackupRegistry{}, err
+	}
+
+	return models.BackupRegistry{
+		URL:       url,
+		Namespace: details.Namespace,
+	}, nil
+}
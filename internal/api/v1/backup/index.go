@@ -0,0 +1,29 @@
+package backup
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/backup"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Index handles the API endpoint GET /backups
+// It lists the backups in the blob store, newest first.
+func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	backups, err := backup.List(ctx, cluster)
+	if err != nil {
+		return apierror.InternalError(err, "listing the backups")
+	}
+
+	response.OKReturn(c, backups)
+	return nil
+}
@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/backup"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/helmchart"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Restore handles the API endpoint POST /backups/:backup/restore
+// It creates the resources of the backup, in order, i.e. namespaces, users, configurations,
+// services, and apps, leaving existing ones as they are. The restored apps with an image are
+// deployed again. Failed deployments are reported as warnings, like images in the registry of
+// the backed up installation, if that is not the registry of this one.
+func (hc Controller) Restore(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("backup")
+	username := requestctx.User(ctx).Username
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	archive, err := backup.Fetch(ctx, cluster, name)
+	if err == backup.ErrBackupNotFound {
+		return apierror.NewNotFoundError("backup not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err, "fetching the backup")
+	}
+
+	client, err := dynamic.NewForConfig(cluster.RestConfig)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result, restored, err := backup.Restore(ctx, client, helmchart.Namespace(), archive)
+	if err != nil {
+		return apierror.InternalError(err, "restoring the backup")
+	}
+
+	registryMeta, err := registryMeta(ctx, cluster)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	result.Warnings = append(result.Warnings, backup.RegistryWarnings(archive.Meta, registryMeta.URL)...)
+
+	images := map[string]string{}
+	for _, app := range archive.Objects["apps"] {
+		image, _, _ := unstructured.NestedString(app.Object, "spec", "imageurl")
+		images[app.GetNamespace()+"/"+app.GetName()] = image
+	}
+
+	for _, appRef := range restored {
+		ref := appRef.Namespace + "/" + appRef.Name
+		if images[ref] == "" {
+			continue
+		}
+
+		_, apierr := deploy.DeployApp(ctx, cluster, appRef, username, "", nil, nil)
+		if apierr != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("deploying app %s failed: %s", ref, apierror.Message(apierr)))
+			continue
+		}
+		result.Deployed = append(result.Deployed, ref)
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// ClientCertificateCreate handles the API endpoint POST /auth/client-certificate. It issues a
+// client certificate for the mutual TLS port of the API to the user, for the certificate
+// request of the machine to enroll.
+func ClientCertificateCreate(c *gin.Context) APIErrors {
+	ctx := c.Request.Context()
+	user := requestctx.User(ctx)
+
+	secretName := viper.GetString("mtls-ca-secret")
+	if secretName == "" {
+		return NewAPIError("client certificates are not enabled", "", http.StatusNotFound)
+	}
+
+	var request models.ClientCertificateRequest
+	if err := c.BindJSON(&request); err != nil {
+		return BadRequest(err)
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	secret, err := cluster.GetSecret(ctx, helmchart.Namespace(), secretName)
+	if err != nil {
+		return InternalError(err, "getting the client CA")
+	}
+
+	ca, err := auth.NewClientCA(secret.Data["tls.crt"], secret.Data["tls.key"])
+	if err != nil {
+		return InternalError(err)
+	}
+
+	certificate, expiry, err := ca.Issue([]byte(request.CSR), user.Username, viper.GetDuration("mtls-client-certificate-ttl"))
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	url := strings.TrimSuffix(viper.GetString("mtls-url"), "/")
+
+	c.JSON(http.StatusCreated, models.ClientCertificateResponse{
+		Certificate: string(certificate),
+		ExpiresAt:   expiry,
+		URL:         url,
+		WsURL:       strings.Replace(url, "https://", "wss://", 1),
+	})
+	return nil
+}
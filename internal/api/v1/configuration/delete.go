@@ -1,6 +1,7 @@
 package configuration
 
 import (
+	"context"
 	"strings"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
@@ -42,12 +43,86 @@ func (sc Controller) Delete(c *gin.Context) apierror.APIErrors {
 		return apierror.NamespaceIsNotKnown(namespace)
 	}
 
+	boundAppNames, apiErr := deleteConfiguration(ctx, cluster, namespace, configurationName, username, deleteRequest.Unbind)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	response.OKReturn(c, models.ConfigurationDeleteResponse{
+		BoundApps: boundAppNames,
+	})
+	return nil
+}
+
+// BatchDelete handles the API end point /namespaces/:namespace/configurations (DELETE)
+// It deletes the named configurations, or all configurations of the namespace, one after the
+// other, and reports the outcome per configuration.
+func (sc Controller) BatchDelete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	username := requestctx.User(ctx).Username
+
+	var deleteRequest models.BatchDeleteRequest
+	err := c.BindJSON(&deleteRequest)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+	if len(deleteRequest.Names) == 0 && !deleteRequest.All {
+		return apierror.NewBadRequest("no configurations to delete")
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !exists {
+		return apierror.NamespaceIsNotKnown(namespace)
+	}
+
+	names := deleteRequest.Names
+	if deleteRequest.All {
+		configurationList, err := configurations.List(ctx, cluster, namespace)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		names = []string{}
+		for _, configuration := range configurationList {
+			names = append(names, configuration.Name)
+		}
+	}
+
+	resp := models.BatchDeleteResponse{Results: []models.BatchDeleteResult{}}
+	for _, name := range names {
+		result := models.BatchDeleteResult{Name: name}
+
+		boundAppNames, apiErr := deleteConfiguration(ctx, cluster, namespace, name, username, deleteRequest.Unbind)
+		result.BoundApps = boundAppNames
+		if apiErr != nil {
+			result.Error = apierror.Message(apiErr)
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	response.OKReturn(c, resp)
+	return nil
+}
+
+// deleteConfiguration deletes the named configuration, and returns the names of the
+// applications it was bound to. Bound applications are an error, unless unbinding was asked
+// for. Their names are returned with that error.
+func deleteConfiguration(ctx context.Context, cluster *kubernetes.Cluster, namespace, configurationName, username string, unbind bool) ([]string, apierror.APIErrors) {
 	configuration, err := configurations.Lookup(ctx, cluster, namespace, configurationName)
 	if err != nil && err.Error() == "configuration not found" {
-		return apierror.ConfigurationIsNotKnown(configurationName)
+		return nil, apierror.ConfigurationIsNotKnown(configurationName)
 	}
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
 	// Verify that the configuration is unbound. IOW not bound to any application.
@@ -56,18 +131,18 @@ func (sc Controller) Delete(c *gin.Context) apierror.APIErrors {
 
 	boundAppNames, err := application.BoundAppsNamesFor(ctx, cluster, namespace, configurationName)
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
 	if len(boundAppNames) > 0 {
-		if !deleteRequest.Unbind {
-			return apierror.NewBadRequest("bound applications exist", strings.Join(boundAppNames, ","))
+		if !unbind {
+			return boundAppNames, apierror.NewBadRequest("bound applications exist", strings.Join(boundAppNames, ","))
 		}
 
 		for _, appName := range boundAppNames {
 			apiErr := configurationbinding.DeleteBinding(ctx, cluster, namespace, appName, configurationName, username)
 			if apiErr != nil {
-				return apiErr
+				return nil, apiErr
 			}
 		}
 	}
@@ -76,11 +151,8 @@ func (sc Controller) Delete(c *gin.Context) apierror.APIErrors {
 
 	err = configuration.Delete(ctx)
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, models.ConfigurationDeleteResponse{
-		BoundApps: boundAppNames,
-	})
-	return nil
+	return boundAppNames, nil
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/listing"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 
 	"github.com/gin-gonic/gin"
@@ -12,9 +13,15 @@ import (
 
 // FullIndex handles the API endpoint GET /configurations
 // It lists all the known applications in all namespaces, with and without workload.
+// The list is paged, filtered, and sorted as per the query parameters, see listing.FromQuery.
 func (hc Controller) FullIndex(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 
+	options, err := listing.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
 	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -25,6 +32,11 @@ func (hc Controller) FullIndex(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	allConfigurations, total, err := allConfigurations.Page(options)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
 	appsOf, err := application.BoundAppsNames(ctx, cluster, "")
 	if err != nil {
 		return apierror.InternalError(err)
@@ -35,6 +47,6 @@ func (hc Controller) FullIndex(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, responseData)
+	response.OKReturnList(c, responseData, responseData, total, options)
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/listing"
 	"github.com/epinio/epinio/internal/namespaces"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
@@ -16,8 +17,14 @@ import (
 
 // Index handles the API end point /namespaces/:namespace/configurations
 // It returns a list of all known configuration instances
+// The list is paged, filtered, and sorted as per the query parameters, see listing.FromQuery.
 func (sc Controller) Index(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
+
+	options, err := listing.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
 	namespace := c.Param("namespace")
 
 	cluster, err := kubernetes.GetCluster(ctx)
@@ -38,6 +45,11 @@ func (sc Controller) Index(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	namespaceConfigurations, total, err := namespaceConfigurations.Page(options)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
 	appsOf, err := application.BoundAppsNames(ctx, cluster, namespace)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -48,7 +60,7 @@ func (sc Controller) Index(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, responseData)
+	response.OKReturnList(c, responseData, responseData, total, options)
 	return nil
 }
 
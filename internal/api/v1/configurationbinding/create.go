@@ -48,6 +48,18 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		}
 	}
 
+	var paths map[string]string
+	if bindRequest.Path != "" {
+		if len(bindRequest.Names) > 1 {
+			err := errors.New("Cannot bind multiple configurations to the same path")
+			return apierror.BadRequest(err)
+		}
+		paths = map[string]string{bindRequest.Names[0]: bindRequest.Path}
+		if err := application.ValidateConfigurationPaths(paths, bindRequest.Names); err != nil {
+			return apierror.BadRequest(err)
+		}
+	}
+
 	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -69,7 +81,7 @@ func (hc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.AppIsNotKnown(appName)
 	}
 
-	boundedConfigs, errors := CreateConfigurationBinding(ctx, cluster, namespace, *app, bindRequest.Names)
+	boundedConfigs, errors := CreateConfigurationBinding(ctx, cluster, namespace, *app, bindRequest.Names, paths)
 	if errors != nil {
 		return errors
 	}
@@ -89,6 +101,7 @@ func CreateConfigurationBinding(
 	namespace string,
 	app models.App,
 	configurationNames []string,
+	paths map[string]string,
 ) ([]string, apierror.APIErrors) {
 	logger := requestctx.Logger(ctx).WithName("CreateConfigurationBinding")
 
@@ -136,7 +149,15 @@ func CreateConfigurationBinding(
 
 	logger.Info(fmt.Sprintf("okToBind: %#v", okToBind))
 
-	if len(okToBind) > 0 {
+	// Already bound configurations need a redeploy only if their path changes.
+	pathChanged := false
+	for _, configurationName := range boundedConfigs {
+		if path, ok := paths[configurationName]; ok && path != app.Configuration.ConfigurationPaths[configurationName] {
+			pathChanged = true
+		}
+	}
+
+	if len(okToBind) > 0 || pathChanged {
 		// Save those that were valid and not yet bound to the
 		// application. Extends the set.
 
@@ -147,6 +168,15 @@ func CreateConfigurationBinding(
 			return nil, apierror.NewMultiError(theIssues)
 		}
 
+		if len(paths) > 0 {
+			logger.Info("BoundConfigurationPathsSet")
+			err := application.BoundConfigurationPathsSet(ctx, cluster, app.Meta, paths)
+			if err != nil {
+				theIssues = append([]apierror.APIError{apierror.InternalError(err)}, theIssues...)
+				return nil, apierror.NewMultiError(theIssues)
+			}
+		}
+
 		logger.Info("DeployApp")
 
 		// Update the workload, if there is any.
@@ -0,0 +1,93 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/viper"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/events"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+const (
+	// canaryInterval is the time between two checks of the error rates of the canaries.
+	canaryInterval = time.Minute
+
+	// canaryUser is recorded as the user rolling back the canaries failing their error
+	// threshold.
+	canaryUser = "epinio-canary"
+)
+
+// AbortCanary removes the canary of the referenced application, returning all traffic to the
+// stable version.
+func AbortCanary(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, username string) apierror.APIErrors {
+	if err := application.CanarySet(ctx, cluster, appRef, nil); err != nil {
+		return apierror.InternalError(err, "failed to remove the application's canary")
+	}
+
+	_, apierr := DeployApp(ctx, cluster, appRef, username, "", nil, nil)
+	return apierr
+}
+
+// CanaryWatchLoop periodically rolls back the canaries whose error rate reached their error
+// threshold, until the context is done.
+func CanaryWatchLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(canaryInterval)
+	defer ticker.Stop()
+
+	ctx = requestctx.WithLogger(ctx, logger)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if viper.GetString("prometheus-url") == "" {
+			continue
+		}
+
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "canary watch: no cluster")
+			continue
+		}
+
+		appRefs, err := application.ListAppRefs(ctx, cluster, "")
+		if err != nil {
+			logger.Error(err, "canary watch: listing applications failed")
+			continue
+		}
+
+		for _, appRef := range appRefs {
+			failing, rate, err := application.CanaryFailing(ctx, cluster, appRef)
+			if err != nil {
+				logger.Error(err, "canary watch: checking error rate failed", "namespace", appRef.Namespace, "app", appRef.Name)
+				continue
+			}
+			if !failing {
+				continue
+			}
+
+			logger.Info("canary rollback", "namespace", appRef.Namespace, "app", appRef.Name, "errorRate", rate)
+
+			if apierr := AbortCanary(ctx, cluster, appRef, canaryUser); apierr != nil {
+				logger.Error(apierr.Errors()[0], "canary watch: rollback failed", "namespace", appRef.Namespace, "app", appRef.Name)
+				continue
+			}
+
+			events.Publish(ctx, models.EventAppDeployed, appRef.Namespace, appRef.Name, map[string]string{
+				"strategy": models.StrategyCanary,
+				"rollback": fmt.Sprintf("error rate %.1f%% reached the threshold", rate),
+			})
+		}
+	}
+}
@@ -4,6 +4,7 @@ package deploy
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
@@ -21,6 +22,17 @@ import (
 // and associated secrets. It is the backend for the API deploypoint, as well as all the
 // mutating endpoints, i.e. configuration and app changes (bindings, environment, scaling).
 func DeployApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppRef, username, expectedStageID string, origin *models.ApplicationOrigin, start *int64) ([]string, apierror.APIErrors) {
+	return deployApp(ctx, cluster, app, username, expectedStageID, origin, start, false)
+}
+
+// RollbackApp deploys the referenced application like DeployApp does for a new revision of
+// the given origin, with the post-rollback hooks run in place of the post-deploy hooks.
+func RollbackApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppRef, username string, origin models.ApplicationOrigin) ([]string, apierror.APIErrors) {
+	return deployApp(ctx, cluster, app, username, "", &origin, nil, true)
+}
+
+// deployApp implements DeployApp and RollbackApp.
+func deployApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppRef, username, expectedStageID string, origin *models.ApplicationOrigin, start *int64, rollback bool) ([]string, apierror.APIErrors) {
 	log := requestctx.Logger(ctx)
 
 	appObj, err := application.Lookup(ctx, cluster, app.Namespace, app.Name)
@@ -42,18 +54,48 @@ func DeployApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppR
 	chartName := appObj.Configuration.AppChart
 
 	deployParams := helm.ChartParameters{
-		Context:        ctx,
-		Cluster:        cluster,
-		AppRef:         app,
-		Chart:          chartName,
-		Environment:    appObj.Configuration.Environment,
-		Configurations: appObj.Configuration.Configurations,
-		Instances:      *appObj.Configuration.Instances,
-		ImageURL:       imageURL,
-		Username:       username,
-		StageID:        stageID,
-		Routes:         routes,
-		Start:          start,
+		Context:            ctx,
+		Cluster:            cluster,
+		AppRef:             app,
+		Chart:              chartName,
+		Environment:        appObj.Configuration.Environment,
+		Configurations:     appObj.Configuration.Configurations,
+		Instances:          *appObj.Configuration.Instances,
+		Rollout:            appObj.Configuration.Rollout,
+		Tasks:              appObj.Configuration.Tasks,
+		Processes:          appObj.Configuration.Processes,
+		Sidecars:           appObj.Configuration.Sidecars,
+		Volumes:            appObj.Configuration.Volumes,
+		ConfigurationPaths: appObj.Configuration.ConfigurationPaths,
+		RouteAnnotations:   appObj.Configuration.RouteAnnotations,
+		Placement:          appObj.Configuration.Placement,
+		Termination:        appObj.Configuration.Termination,
+		Entrypoint:         appObj.Configuration.Entrypoint,
+		ChartValues:        appObj.Configuration.ChartValues,
+		ImageURL:           imageURL,
+		Username:           username,
+		StageID:            stageID,
+		Routes:             routes,
+		Start:              start,
+	}
+
+	// A sleeping application runs no instances. With auto sleep and the activator, the
+	// requests failing for lack of instances are passed to the activator, to wake it.
+	// Waking deploys the routes without, for the errors of an awake application to reach
+	// its clients.
+	sleeping, err := application.Sleeping(ctx, cluster, app)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+	if sleeping {
+		deployParams.Instances = 0
+		deployParams.Processes = sleepingProcesses(appObj.Configuration.Processes)
+	}
+	if sleeping && appObj.Configuration.AutoSleep != nil && application.ActivatorEnabled() {
+		if err := activatorServiceEnsure(ctx, cluster, app.Namespace); err != nil {
+			return nil, apierror.InternalError(err, "creating the activator service")
+		}
+		deployParams.RouteAnnotations = ActivatorAnnotations(routes, appObj.Configuration.RouteAnnotations)
 	}
 
 	log.Info("deploying app", "namespace", app.Namespace, "app", app.Name)
@@ -63,11 +105,80 @@ func DeployApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppR
 		return nil, apierror.InternalError(err, "preparing ImageURL registry for use by Kubernetes", imageURL)
 	}
 
-	err = helm.Deploy(log, deployParams)
+	canary, err := application.Canary(ctx, cluster, app)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+	if canary != nil {
+		canaryImageURL, err := replaceInternalRegistry(ctx, cluster, canary.ImageURL)
+		if err != nil {
+			return nil, apierror.InternalError(err, "preparing canary ImageURL registry for use by Kubernetes", canary.ImageURL)
+		}
+		deployParams.Canary = &models.AppCanary{
+			ImageURL:       canaryImageURL,
+			StageID:        canary.StageID,
+			Weight:         canary.Weight,
+			ErrorThreshold: canary.ErrorThreshold,
+		}
+	}
+
+	// A deployment with origin information is a new revision of the app, as opposed to
+	// the redeployments done for configuration changes. Failures are recorded as well.
+	var revision models.AppRevision
+	if origin != nil {
+		revision = models.AppRevision{
+			ImageURL:       imageURL,
+			StageID:        stageID,
+			Origin:         *origin,
+			Environment:    appObj.Configuration.Environment,
+			Configurations: appObj.Configuration.Configurations,
+			Username:       username,
+		}
+	}
+
+	// A new revision runs the migration, if any, before it is deployed. A failed migration
+	// fails the deployment, leaving the current version serving.
+	if origin != nil && appObj.Configuration.Migration != nil {
+		log.Info("running migration", "namespace", app.Namespace, "app", app.Name)
+
+		err = application.MigrationRun(ctx, cluster, app, deployParams.ImageURL,
+			*appObj.Configuration.Migration,
+			appObj.Configuration.Environment,
+			appObj.Configuration.Configurations)
+	}
+
+	// A new revision further runs its pre-deploy hooks. A failed hook fails the deployment
+	// like a failed migration does.
+	if err == nil && origin != nil {
+		var ok bool
+		revision.Hooks, ok, err = application.HooksRun(ctx, cluster, app, deployParams.ImageURL,
+			appObj.Configuration.Hooks, models.HookPreDeploy,
+			appObj.Configuration.Environment,
+			appObj.Configuration.Configurations)
+		if err == nil && !ok {
+			failed := revision.Hooks[len(revision.Hooks)-1]
+			err = errors.Errorf("pre-deploy hook '%s' failed:\n%s", failed.Name, failed.Logs)
+		}
+	}
+
+	if err == nil {
+		err = helm.Deploy(log, deployParams)
+	}
 	if err != nil {
+		if origin != nil {
+			revision.Result = models.RevisionFailed
+			if _, rerr := application.RevisionAdd(ctx, cluster, app, revision); rerr != nil {
+				log.Error(rerr, "saving the failed app revision", "namespace", app.Namespace, "app", app.Name)
+			}
+		}
 		return nil, apierror.InternalError(err)
 	}
 
+	err = application.PortServicesSync(ctx, cluster, app, appObj.Configuration.Ports)
+	if err != nil {
+		return nil, apierror.InternalError(err, "exposing the app ports")
+	}
+
 	// Delete previous staging jobs except for the current one
 	if stageID != "" {
 		log.Info("app staging drop", "namespace", app.Namespace, "app", app.Name, "stage id", stageID)
@@ -85,6 +196,29 @@ func DeployApp(ctx context.Context, cluster *kubernetes.Cluster, app models.AppR
 		}
 
 		log.Info("saved app origin", "namespace", app.Namespace, "app", app.Name, "origin", *origin)
+
+		// The post hooks run against the deployed revision. Their failures are recorded
+		// with the revision, they do not undo the deployment.
+		phase := models.HookPostDeploy
+		if rollback {
+			phase = models.HookPostRollback
+		}
+		results, _, err := application.HooksRun(ctx, cluster, app, deployParams.ImageURL,
+			appObj.Configuration.Hooks, phase,
+			appObj.Configuration.Environment,
+			appObj.Configuration.Configurations)
+		revision.Hooks = append(revision.Hooks, results...)
+		if err != nil {
+			log.Error(err, "running the post hooks", "namespace", app.Namespace, "app", app.Name, "phase", phase)
+		}
+
+		revision.Result = models.RevisionDeployed
+		number, err := application.RevisionAdd(ctx, cluster, app, revision)
+		if err != nil {
+			return nil, apierror.InternalError(err, "saving the app revision")
+		}
+
+		log.Info("saved app revision", "namespace", app.Namespace, "app", app.Name, "revision", number)
 	}
 
 	return routes, nil
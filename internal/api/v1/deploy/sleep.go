@@ -0,0 +1,298 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/routes"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+const (
+	// sleepInterval is the time between two checks for idle applications to put to sleep.
+	sleepInterval = time.Minute
+
+	// activatorRetry is the time in seconds clients are asked to wait before repeating a
+	// request for a waking application.
+	activatorRetry = 5
+
+	// activatorUser is recorded as the user deploying the applications woken by requests.
+	activatorUser = "epinio-activator"
+
+	// The annotations of the ingresses of sleeping applications, passing the requests
+	// failing for lack of instances to the activator.
+	customErrorsAnnotation   = "nginx.ingress.kubernetes.io/custom-http-errors"
+	defaultBackendAnnotation = "nginx.ingress.kubernetes.io/default-backend"
+)
+
+// waking holds the applications currently woken by the activator, to wake them only once.
+var waking sync.Map
+
+// Sleep puts the referenced application to sleep, i.e. redeploys it without instances.
+func Sleep(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, username string) apierror.APIErrors {
+	if err := application.SleepingSet(ctx, cluster, appRef, true); err != nil {
+		return apierror.InternalError(err)
+	}
+
+	_, apierr := DeployApp(ctx, cluster, appRef, username, "", nil, nil)
+	return apierr
+}
+
+// Wake wakes the referenced application, i.e. redeploys it with its desired instances.
+func Wake(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, username string) apierror.APIErrors {
+	if err := application.SleepingSet(ctx, cluster, appRef, false); err != nil {
+		return apierror.InternalError(err)
+	}
+
+	_, apierr := DeployApp(ctx, cluster, appRef, username, "", nil, nil)
+	return apierr
+}
+
+// AutoSleepLoop periodically puts the idle applications with auto sleep to sleep, until the
+// context is done.
+func AutoSleepLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(sleepInterval)
+	defer ticker.Stop()
+
+	ctx = requestctx.WithLogger(ctx, logger)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if viper.GetString("prometheus-url") == "" {
+			continue
+		}
+
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "auto sleep: no cluster")
+			continue
+		}
+
+		appRefs, err := application.ListAppRefs(ctx, cluster, "")
+		if err != nil {
+			logger.Error(err, "auto sleep: listing applications failed")
+			continue
+		}
+
+		for _, appRef := range appRefs {
+			idle, err := application.AppIdle(ctx, cluster, appRef)
+			if err != nil {
+				logger.Error(err, "auto sleep: checking traffic failed", "namespace", appRef.Namespace, "app", appRef.Name)
+				continue
+			}
+			if !idle {
+				continue
+			}
+
+			logger.Info("auto sleep", "namespace", appRef.Namespace, "app", appRef.Name)
+
+			if apierr := Sleep(ctx, cluster, appRef, activatorUser); apierr != nil {
+				logger.Error(apierr.Errors()[0], "auto sleep: sleep failed", "namespace", appRef.Namespace, "app", appRef.Name)
+			}
+		}
+	}
+}
+
+// NewActivator returns the handler for the requests which failed for lack of application
+// instances, as passed on by the ingress controller, see ActivatorAnnotations. The
+// application is found by the host of the request, among the routes passed to the activator,
+// i.e. of sleeping applications. Sleeping applications are woken. The clients are asked to
+// repeat their requests.
+func NewActivator(logger logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if host == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		ctx := requestctx.WithLogger(r.Context(), logger)
+
+		appRef, err := activatorApp(ctx, host)
+		if err != nil {
+			logger.Error(err, "activator: no application", "host", host)
+			http.NotFound(w, r)
+			return
+		}
+
+		if _, busy := waking.LoadOrStore(appRef, struct{}{}); !busy {
+			go func() {
+				defer waking.Delete(appRef)
+
+				ctx := requestctx.WithLogger(context.Background(), logger)
+				if err := activatorWake(ctx, appRef); err != nil {
+					logger.Error(err, "activator: wake failed", "namespace", appRef.Namespace, "app", appRef.Name)
+				}
+			}()
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Retry-After", strconv.Itoa(activatorRetry))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, `<html><head><meta http-equiv="refresh" content="%d"></head><body>%s is starting, please wait.</body></html>`,
+			activatorRetry, html.EscapeString(appRef.Name))
+	})
+}
+
+// ActivatorAnnotations returns the annotations of the routes of a sleeping application, with
+// the annotations passing the requests which failed for lack of instances to the activator
+// added. Awake applications are deployed with their own annotations, without these.
+func ActivatorAnnotations(desiredRoutes []string, annotations models.AppRouteAnnotations) models.AppRouteAnnotations {
+	// Annotations are keyed by domain and path, without the route options.
+	result := models.AppRouteAnnotations{}
+	for route, routeAnnotations := range annotations {
+		r := routes.FromString(route)
+		result[r.Domain+r.Path] = routeAnnotations
+	}
+
+	for _, desired := range desiredRoutes {
+		r := routes.FromString(desired)
+		routeAnnotations := map[string]string{}
+		for key, value := range result[r.Domain+r.Path] {
+			routeAnnotations[key] = value
+		}
+		routeAnnotations[customErrorsAnnotation] = "502,503"
+		routeAnnotations[defaultBackendAnnotation] = application.ActivatorService
+		result[r.Domain+r.Path] = routeAnnotations
+	}
+
+	return result
+}
+
+// activatorApp returns the application whose ingress for the host passes requests to the
+// activator. Applications which are awake, or without auto sleep, are not found, whatever
+// the request claims.
+func activatorApp(ctx context.Context, host string) (models.AppRef, error) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return models.AppRef{}, err
+	}
+
+	ingresses, err := cluster.Kubectl.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/name",
+	})
+	if err != nil {
+		return models.AppRef{}, err
+	}
+
+	for _, ingress := range ingresses.Items {
+		if ingress.Annotations[defaultBackendAnnotation] != application.ActivatorService {
+			continue
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if strings.EqualFold(rule.Host, host) {
+				return models.NewAppRef(ingress.Labels["app.kubernetes.io/name"], ingress.Namespace), nil
+			}
+		}
+	}
+
+	return models.AppRef{}, fmt.Errorf("no sleeping application has host %s", host)
+}
+
+// activatorWake wakes the application, if it is sleeping, and has auto sleep.
+func activatorWake(ctx context.Context, appRef models.AppRef) error {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	sleeping, err := application.Sleeping(ctx, cluster, appRef)
+	if err != nil || !sleeping {
+		return err
+	}
+
+	autoSleep, err := application.AutoSleep(ctx, cluster, appRef)
+	if err != nil || autoSleep == 0 {
+		return err
+	}
+
+	requestctx.Logger(ctx).Info("activator wake", "namespace", appRef.Namespace, "app", appRef.Name)
+
+	if apierr := Wake(ctx, cluster, appRef, activatorUser); apierr != nil {
+		return apierr.Errors()[0]
+	}
+	return nil
+}
+
+// sleepingProcesses returns the process types without instances.
+func sleepingProcesses(processes map[string]int32) map[string]int32 {
+	if processes == nil {
+		return nil
+	}
+	result := map[string]int32{}
+	for name := range processes {
+		result[name] = 0
+	}
+	return result
+}
+
+// activatorServiceEnsure creates or updates the service passing requests in the namespace to
+// the activator of the epinio server.
+func activatorServiceEnsure(ctx context.Context, cluster *kubernetes.Cluster, namespace string) error {
+	host := viper.GetString("activator-host")
+	port := int32(viper.GetInt("activator-port")) // nolint:gosec // port numbers fit
+
+	services := cluster.Kubectl.CoreV1().Services(namespace)
+
+	service, err := services.Get(ctx, application.ActivatorService, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	spec := v1.ServiceSpec{
+		Type:         v1.ServiceTypeExternalName,
+		ExternalName: host,
+		Ports: []v1.ServicePort{{
+			Name: "http",
+			Port: port,
+		}},
+	}
+
+	if apierrors.IsNotFound(err) {
+		_, err = services.Create(ctx, &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      application.ActivatorService,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "epinio",
+				},
+			},
+			Spec: spec,
+		}, metav1.CreateOptions{})
+		return err
+	}
+
+	if service.Spec.ExternalName == host && len(service.Spec.Ports) == 1 && service.Spec.Ports[0].Port == port {
+		return nil
+	}
+
+	service.Spec.ExternalName = host
+	service.Spec.Ports = spec.Ports
+	_, err = services.Update(ctx, service, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,70 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /tokens tokens APITokens
+// Return the API tokens of the user, without their secret values.
+// responses:
+//   200: APITokensResponse
+
+// swagger:parameters APITokens
+type APITokensParam struct{}
+
+// swagger:response APITokensResponse
+type APITokensResponse struct {
+	// in: body
+	Body models.APITokenList
+}
+
+// swagger:route GET /tokens/{Token} tokens APITokenShow
+// Return the API token `Token` of the user, without its secret.
+// responses:
+//   200: APITokenShowResponse
+
+// swagger:parameters APITokenShow
+type APITokenShowParam struct {
+	// in: path
+	Token string
+}
+
+// swagger:response APITokenShowResponse
+type APITokenShowResponse struct {
+	// in: body
+	Body models.APIToken
+}
+
+// swagger:route POST /tokens tokens APITokenCreate
+// Issue a new API token to the user. The secret value of the token is returned only once.
+// responses:
+//   201: APITokenCreateResponse
+
+// swagger:parameters APITokenCreate
+type APITokenCreateParam struct {
+	// in: body
+	Request models.APITokenCreateRequest
+}
+
+// swagger:response APITokenCreateResponse
+type APITokenCreateResponse struct {
+	// in: body
+	Body models.APITokenCreateResponse
+}
+
+// swagger:route DELETE /tokens/{Token} tokens APITokenDelete
+// Revoke the API token with id `Token`.
+// responses:
+//   200: APITokenDeleteResponse
+
+// swagger:parameters APITokenDelete
+type APITokenDeleteParam struct {
+	// in: path
+	Token string
+}
+
+// swagger:response APITokenDeleteResponse
+type APITokenDeleteResponse struct {
+	// in: body
+	Body models.Response
+}
@@ -10,7 +10,10 @@ import "github.com/epinio/epinio/pkg/api/core/v1/models"
 //   200: AppsResponse
 
 // swagger:parameters AllApps
-type AllAppsParam struct{}
+type AllAppsParam struct {
+	ListParam
+	ConditionalParam
+}
 
 // response: See Apps.
 
@@ -23,6 +26,8 @@ type AllAppsParam struct{}
 type AppsParam struct {
 	// in: path
 	Namespace string
+	ListParam
+	ConditionalParam
 }
 
 // swagger:response AppsResponse
@@ -50,6 +55,48 @@ type AppCreateResponse struct {
 	Body models.Response
 }
 
+// swagger:route POST /namespaces/{Namespace}/applications/apply application AppApply
+// Create the application of the posted manifest in the `Namespace`, or change the existing one
+// to match it. The settings missing from the manifest are reset to their defaults. A changed
+// container image is deployed, and an active application re-deployed with the changes.
+// responses:
+//   200: AppApplyResponse
+
+// swagger:parameters AppApply
+type AppApplyParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Manifest models.ApplicationApplyRequest
+}
+
+// swagger:response AppApplyResponse
+type AppApplyResponse struct {
+	// in: body
+	Body models.ApplicationApplyResponse
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/adopt application AppAdopt
+// Turn the named deployment of the `Namespace` into an application running its image, with
+// the settings and routes found in the deployment, and in its services and ingresses. These
+// resources are removed once the application is deployed. A dry run only reports them.
+// responses:
+//   200: AppAdoptResponse
+
+// swagger:parameters AppAdopt
+type AppAdoptParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Request models.AppAdoptRequest
+}
+
+// swagger:response AppAdoptResponse
+type AppAdoptResponse struct {
+	// in: body
+	Body models.AppAdoptResponse
+}
+
 // swagger:route GET /namespaces/{Namespace}/applications/{App} application AppShow
 // Return details of the named `App` in the `Namespace`.
 // responses:
@@ -61,6 +108,7 @@ type AppShowParam struct {
 	Namespace string
 	// in: path
 	App string
+	ConditionalParam
 }
 
 // swagger:response AppShowResponse
@@ -70,7 +118,7 @@ type AppShowResponse struct {
 }
 
 // swagger:route GET /namespaces/{Namespace}/applications/{App}/part/{Part} application AppPart
-// Return parts of the named `App` in the `Namespace`.
+// Return parts of the named `App` in the `Namespace`, i.e. its helm `values`, its `chart`, or its `image` as tarball loadable by `docker load`.
 // responses:
 //   200: AppPartResponse
 
@@ -101,11 +149,43 @@ type AppLogsParam struct {
 	Namespace string
 	// in: path
 	App string
+	// in: query
+	Follow bool
+	// The name of the instance to show the logs of. Default all instances.
+	// in: query
+	Instance string
+	// Show only the lines younger than the duration, e.g. `10m`.
+	// in: query
+	Since string
+	// Show only the last lines of each container.
+	// in: query
+	Tail int64
+	// Show only the lines matching the regular expression. Plain text matches as substring.
+	// in: query
+	Filter string
 }
 
 // swagger:response AppLogsResponse
 type AppLogsResponse struct{}
 
+// swagger:route GET /namespaces/{Namespace}/applications/{App}/events application AppEvents
+// Return the events of the named `App` in the `Namespace` streamed over a websocket.
+// responses:
+//   200: AppEventsResponse
+
+// swagger:parameters AppEvents
+type AppEventsParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: query
+	Follow bool
+}
+
+// swagger:response AppEventsResponse
+type AppEventsResponse struct{}
+
 // swagger:route GET /namespaces/{Namespace}/applications/{App}/exec application AppExec
 // Get a shell to the `App` in the `Namespace`.
 // responses:
@@ -177,6 +257,26 @@ type StagingCompleteResponse struct {
 	Body models.Response
 }
 
+// swagger:route GET /namespaces/{Namespace}/staging/{StageID}/queue application StagingQueue
+// Return the position of the staging process identified by `StageID` in the `Namespace` in
+// the queue of staging jobs waiting for a free slot. Zero means that it is not queued.
+// responses:
+//   200: StagingQueueResponse
+
+// swagger:parameters StagingQueue
+type StagingQueueParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	StageID string
+}
+
+// swagger:response StagingQueueResponse
+type StagingQueueResponse struct {
+	// in: body
+	Body models.StagingQueueResponse
+}
+
 // swagger:route DELETE /namespaces/{Namespace}/applications/{App} application AppDelete
 // Delete the named `App` in the `Namespace`.
 // responses:
@@ -196,6 +296,25 @@ type AppDeleteResponse struct {
 	Body models.ApplicationDeleteResponse
 }
 
+// swagger:route DELETE /namespaces/{Namespace}/applications application AppBatchDelete
+// Delete the named applications, or all applications, in the `Namespace`.
+// responses:
+//   200: AppBatchDeleteResponse
+
+// swagger:parameters AppBatchDelete
+type AppBatchDeleteParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.BatchDeleteRequest
+}
+
+// swagger:response AppBatchDeleteResponse
+type AppBatchDeleteResponse struct {
+	// in: body
+	Body models.BatchDeleteResponse
+}
+
 // swagger:route POST /namespaces/{Namespace}/applications/{App}/store application AppUpload
 // Store the named `App` in the `Namespace`.
 // responses:
@@ -215,6 +334,147 @@ type AppUploadResponse struct {
 	Body models.UploadResponse
 }
 
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/store/parts application AppUploadStart
+// Start an upload in parts of the sources of the named `App` in the `Namespace`.
+// responses:
+//   200: AppUploadStartResponse
+
+// swagger:parameters AppUploadStart
+type AppUploadStartParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppUploadStartResponse
+type AppUploadStartResponse struct {
+	// in: body
+	Body models.UploadStartResponse
+}
+
+// swagger:route PUT /namespaces/{Namespace}/applications/{App}/store/parts/{BlobUID} application AppUploadPart
+// Store the numbered `Part` of the `Upload` of the named `App` in the `Namespace`. The body is the raw part.
+// responses:
+//   200: AppUploadPartResponse
+
+// swagger:parameters AppUploadPart
+type AppUploadPartParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: path
+	BlobUID string
+	// in: query
+	Upload string
+	// in: query
+	Part int
+}
+
+// swagger:response AppUploadPartResponse
+type AppUploadPartResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/store/parts/{BlobUID} application AppUploadFinish
+// Assemble the parts of the `Upload` of the named `App` in the `Namespace`.
+// responses:
+//   200: AppUploadResponse
+
+// swagger:parameters AppUploadFinish
+type AppUploadFinishParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: path
+	BlobUID string
+	// in: query
+	Upload string
+}
+
+// swagger:route DELETE /namespaces/{Namespace}/applications/{App}/store/parts/{BlobUID} application AppUploadAbort
+// Discard the `Upload` of the named `App` in the `Namespace`, and its parts.
+// responses:
+//   200: AppUploadAbortResponse
+
+// swagger:parameters AppUploadAbort
+type AppUploadAbortParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: path
+	BlobUID string
+	// in: query
+	Upload string
+}
+
+// swagger:response AppUploadAbortResponse
+type AppUploadAbortResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/store/chunks application AppChunksMissing
+// Return which of the chunks of sources to upload as delta are not cached for the named `App` in the `Namespace`.
+// responses:
+//   200: AppChunksMissingResponse
+
+// swagger:parameters AppChunksMissing
+type AppChunksMissingParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: body
+	Configuration models.SourceChunksRequest
+}
+
+// swagger:response AppChunksMissingResponse
+type AppChunksMissingResponse struct {
+	// in: body
+	Body models.SourceChunksResponse
+}
+
+// swagger:route PUT /namespaces/{Namespace}/applications/{App}/store/chunks/{Hash} application AppChunkUpload
+// Cache the chunk of sources with the sha256 `Hash` for the named `App` in the `Namespace`. The body is the raw chunk.
+// responses:
+//   200: AppChunkUploadResponse
+
+// swagger:parameters AppChunkUpload
+type AppChunkUploadParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: path
+	Hash string
+}
+
+// swagger:response AppChunkUploadResponse
+type AppChunkUploadResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/store/delta application AppUploadDelta
+// Store the sources of the named `App` in the `Namespace`, assembled from the listed files and cached chunks.
+// responses:
+//   200: AppUploadResponse
+
+// swagger:parameters AppUploadDelta
+type AppUploadDeltaParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: body
+	Configuration models.SourceDeltaRequest
+}
+
 // swagger:route POST /namespaces/{Namespace}/applications/{App}/restart application AppRestart
 // Restart the named `App` in the `Namespace`.
 // responses:
@@ -234,6 +494,265 @@ type AppRestartResponse struct {
 	Body models.Response
 }
 
+// swagger:route POST /namespaces/{Namespace}/applications/restart application AppBatchRestart
+// Restart the named applications, or all active applications, in the `Namespace`.
+// responses:
+//   200: AppBatchRestartResponse
+
+// swagger:parameters AppBatchRestart
+type AppBatchRestartParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.BatchRestartRequest
+}
+
+// swagger:response AppBatchRestartResponse
+type AppBatchRestartResponse struct {
+	// in: body
+	Body models.BatchRestartResponse
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/sleep application AppSleep
+// Put the named `App` in the `Namespace` to sleep, i.e. scale it to zero instances.
+// responses:
+//   200: AppSleepResponse
+
+// swagger:parameters AppSleep
+type AppSleepParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppSleepResponse
+type AppSleepResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/wake application AppWake
+// Wake the sleeping `App` in the `Namespace`, i.e. scale it back to its desired instances.
+// responses:
+//   200: AppWakeResponse
+
+// swagger:parameters AppWake
+type AppWakeParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppWakeResponse
+type AppWakeResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route GET /namespaces/{Namespace}/applications/{App}/revisions application AppRevisions
+// Return the recorded deployment revisions of the named `App` in the `Namespace`.
+// responses:
+//   200: AppRevisionsResponse
+
+// swagger:parameters AppRevisions
+type AppRevisionsParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppRevisionsResponse
+type AppRevisionsResponse struct {
+	// in: body
+	Body models.AppRevisionList
+}
+
+// swagger:route GET /namespaces/{Namespace}/applications/{App}/tasks application AppTasks
+// Return the scheduled tasks of the named `App` in the `Namespace`, with their activity.
+// responses:
+//   200: AppTasksResponse
+
+// swagger:parameters AppTasks
+type AppTasksParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppTasksResponse
+type AppTasksResponse struct {
+	// in: body
+	Body models.AppTaskList
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/tasks/{Task}/run application AppTaskRun
+// Run the named `Task` of the named `App` in the `Namespace` now, outside of its schedule.
+// responses:
+//   200: AppTaskRunResponse
+
+// swagger:parameters AppTaskRun
+type AppTaskRunParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: path
+	Task string
+}
+
+// swagger:response AppTaskRunResponse
+type AppTaskRunResponse struct {
+	// in: body
+	Body models.AppTaskRunResponse
+}
+
+// swagger:route GET /namespaces/{Namespace}/applications/{App}/domains application AppDomains
+// Return the custom domains of the named `App` in the `Namespace`, with their state.
+// responses:
+//   200: AppDomainsResponse
+
+// swagger:parameters AppDomains
+type AppDomainsParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppDomainsResponse
+type AppDomainsResponse struct {
+	// in: body
+	Body models.AppDomainList
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/domains application AppDomainAdd
+// Add a custom domain to the named `App` in the `Namespace`, and verify its ownership.
+// responses:
+//   200: AppDomainAddResponse
+
+// swagger:parameters AppDomainAdd
+type AppDomainAddParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: body
+	Body models.AppDomainRequest
+}
+
+// swagger:response AppDomainAddResponse
+type AppDomainAddResponse struct {
+	// in: body
+	Body models.AppDomainStatus
+}
+
+// swagger:route DELETE /namespaces/{Namespace}/applications/{App}/domains/{Domain} application AppDomainRemove
+// Remove the custom `Domain`, and its route, from the named `App` in the `Namespace`.
+// responses:
+//   200: AppDomainRemoveResponse
+
+// swagger:parameters AppDomainRemove
+type AppDomainRemoveParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: path
+	Domain string
+}
+
+// swagger:response AppDomainRemoveResponse
+type AppDomainRemoveResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/rollback application AppRollback
+// Roll the named `App` in the `Namespace` back to a previous revision.
+// responses:
+//   200: AppRollbackResponse
+
+// swagger:parameters AppRollback
+type AppRollbackParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: body
+	Body models.ApplicationRollbackRequest
+}
+
+// swagger:response AppRollbackResponse
+type AppRollbackResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/rename application AppRename
+// Rename the named `App` in the `Namespace`, keeping its image, configuration, and bindings.
+// responses:
+//   200: AppRenameResponse
+
+// swagger:parameters AppRename
+type AppRenameParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: body
+	Body models.ApplicationRenameRequest
+}
+
+// swagger:response AppRenameResponse
+type AppRenameResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route POST /namespaces/{Namespace}/applications/{App}/canary/promote application AppCanaryPromote
+// Shift more traffic to the canary of the named `App` in the `Namespace`, or make it the stable version.
+// responses:
+//   200: AppCanaryPromoteResponse
+
+// swagger:parameters AppCanaryPromote
+type AppCanaryPromoteParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+	// in: body
+	Body models.ApplicationCanaryPromoteRequest
+}
+
+// swagger:response AppCanaryPromoteResponse
+type AppCanaryPromoteResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route DELETE /namespaces/{Namespace}/applications/{App}/canary application AppCanaryAbort
+// Remove the canary of the named `App` in the `Namespace`, returning all traffic to the stable version.
+// responses:
+//   200: AppCanaryAbortResponse
+
+// swagger:parameters AppCanaryAbort
+type AppCanaryAbortParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	App string
+}
+
+// swagger:response AppCanaryAbortResponse
+type AppCanaryAbortResponse struct {
+	// in: body
+	Body models.Response
+}
+
 // swagger:route POST /namespaces/{Namespace}/applications/{App}/import-git application AppImportGit
 // Store the named `App` from a Git repo in the `Namespace`.
 // responses:
@@ -256,7 +775,8 @@ type AppImportGitResponse struct {
 }
 
 // swagger:route POST /namespaces/{Namespace}/applications/{App}/stage application AppStage
-// Create the resources needed to stage the named `App` in the `Namespace`.
+// Create the resources needed to stage the named `App` in the `Namespace`. Return the id of the
+// operation tracking the staging.
 // responses:
 //   200: AppStageResponse
 
@@ -278,8 +798,10 @@ type AppStageResponse struct {
 
 // swagger:route POST /namespaces/{Namespace}/applications/{App}/deploy application AppDeploy
 // Create the deployment, configuration and ingress resources for the named `App` in the `Namespace`.
+// An asynchronous deployment returns the id of the operation doing it right away.
 // responses:
 //   200: AppDeployResponse
+//   202: AppDeployResponse
 
 // swagger:parameters AppDeploy
 type AppDeployParam struct {
@@ -287,6 +809,9 @@ type AppDeployParam struct {
 	Namespace string
 	// in: path
 	App string
+	// Deploy in the background, tracked by an operation
+	// in: query
+	Async bool `json:"async"`
 	// in: body
 	Body models.DeployRequest
 }
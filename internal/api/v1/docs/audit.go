@@ -0,0 +1,29 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /audit audit AuditEntries
+// Return the recent entries of the audit log, the newest first. Only admins can do this.
+// responses:
+//   200: AuditEntriesResponse
+
+// swagger:parameters AuditEntries
+type AuditEntriesParam struct {
+	// Only the entries of the user
+	// in: query
+	User string `json:"user"`
+	// Only the entries of the namespace
+	// in: query
+	Namespace string `json:"namespace"`
+	// Maximum number of entries, 100 by default, 0 for all
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// swagger:response AuditEntriesResponse
+type AuditEntriesResponse struct {
+	// in: body
+	Body models.AuditEntryList
+}
@@ -0,0 +1,57 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /backups backup Backups
+// Return the backups of the platform state in the blob store, newest first. Only admins can
+// do this.
+// responses:
+//   200: BackupsResponse
+
+// swagger:parameters Backups
+type BackupsParam struct{}
+
+// swagger:response BackupsResponse
+type BackupsResponse struct {
+	// in: body
+	Body models.BackupList
+}
+
+// swagger:route POST /backups backup BackupCreate
+// Capture the state of the platform, i.e. the namespaces with their apps, configurations, and
+// services, and the users, in a backup kept in the blob store. Return its meta data. Only
+// admins can do this.
+// responses:
+//   200: BackupCreateResponse
+
+// swagger:parameters BackupCreate
+type BackupCreateParam struct {
+	// in: body
+	Request models.BackupCreateRequest
+}
+
+// swagger:response BackupCreateResponse
+type BackupCreateResponse struct {
+	// in: body
+	Body models.Backup
+}
+
+// swagger:route POST /backups/{Backup}/restore backup BackupRestore
+// Create the resources of the `Backup`, in order, leaving existing ones as they are, and
+// deploy the restored apps. Only admins can do this.
+// responses:
+//   200: BackupRestoreResponse
+
+// swagger:parameters BackupRestore
+type BackupRestoreParam struct {
+	// in: path
+	Backup string
+}
+
+// swagger:response BackupRestoreResponse
+type BackupRestoreResponse struct {
+	// in: body
+	Body models.BackupRestoreResponse
+}
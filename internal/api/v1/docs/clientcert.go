@@ -0,0 +1,22 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route POST /auth/client-certificate clientcertificate ClientCertificateCreate
+// Issue a client certificate for the mutual TLS port of the API to the user.
+// responses:
+//   201: ClientCertificateResponse
+
+// swagger:parameters ClientCertificateCreate
+type ClientCertificateCreateParam struct {
+	// in: body
+	Request models.ClientCertificateRequest
+}
+
+// swagger:response ClientCertificateResponse
+type ClientCertificateResponse struct {
+	// in: body
+	Body models.ClientCertificateResponse
+}
@@ -0,0 +1,13 @@
+package docs
+
+//go:generate swagger generate spec
+
+// ConditionalParam holds the header of conditional requests. The responses of the endpoints
+// showing and listing apps, services, and namespaces, and listing configurations, carry an
+// `ETag`. Requests sending it
+// back get a 304 Not Modified, without body, while the response is unchanged.
+type ConditionalParam struct {
+	// ETag of the response the client has.
+	// in: header
+	IfNoneMatch string `json:"If-None-Match"`
+}
@@ -27,6 +27,25 @@ type ConfigurationDeleteResponse struct {
 	Body models.ConfigurationDeleteResponse
 }
 
+// swagger:route DELETE /namespaces/{Namespace}/configurations configuration ConfigurationBatchDelete
+// Delete the named configurations, or all configurations, in the `Namespace`.
+// responses:
+//   200: ConfigurationBatchDeleteResponse
+
+// swagger:parameters ConfigurationBatchDelete
+type ConfigurationBatchDeleteParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.BatchDeleteRequest
+}
+
+// swagger:response ConfigurationBatchDeleteResponse
+type ConfigurationBatchDeleteResponse struct {
+	// in: body
+	Body models.BatchDeleteResponse
+}
+
 // swagger:route GET /namespaces/{Namespace}/configurationapps configuration ConfigurationApps
 // Return map from configurations in the `Namespace`, to the apps in the same.
 // responses:
@@ -53,6 +72,8 @@ type ConfigurationAppsResponse struct {
 type ConfigurationsParam struct {
 	// in: path
 	Namespace string
+	ListParam
+	ConditionalParam
 }
 
 // swagger:response ConfigurationsResponse
@@ -147,6 +168,9 @@ type ConfigurationReplaceResponse struct {
 //   200: ConfigurationsResponse
 
 // swagger:parameters AllConfigurations
-type ConfigurationAllConfigurationsParam struct{}
+type ConfigurationAllConfigurationsParam struct {
+	ListParam
+	ConditionalParam
+}
 
 // response: See Configurations.
@@ -0,0 +1,26 @@
+package docs
+
+//go:generate swagger generate spec
+
+// swagger:route GET /events events Events
+// Return the platform events of the namespaces of the user, streamed over a websocket. These
+// are the changes of apps, services, and namespaces, see models.PlatformEvent.
+// responses:
+//   200: EventsResponse
+
+// swagger:parameters Events
+type EventsParam struct {
+	// Only the events of the namespace
+	// in: query
+	Namespace string `json:"namespace"`
+	// Only the events of these types, comma separated. A type also matches the types
+	// starting with it, e.g. `app` matches all events of apps
+	// in: query
+	Type string `json:"type"`
+	// Start with the recent events after the one with this id
+	// in: query
+	After uint64 `json:"after"`
+}
+
+// swagger:response EventsResponse
+type EventsResponse struct{}
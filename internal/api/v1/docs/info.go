@@ -16,3 +16,16 @@ type InfoResponse struct {
 	// in: body
 	Body models.InfoResponse
 }
+
+// OIDCConfig
+
+// swagger:route GET /auth/oidc info OIDCConfig
+// Return the OIDC issuer and client to log in with. Needs no authentication.
+// responses:
+//   200: OIDCConfigResponse
+
+// swagger:response OIDCConfigResponse
+type OIDCConfigResponse struct {
+	// in: body
+	Body models.OIDCConfigResponse
+}
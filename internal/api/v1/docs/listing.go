@@ -0,0 +1,23 @@
+package docs
+
+//go:generate swagger generate spec
+
+// ListParam holds the query parameters of the list endpoints, selecting a page of the list.
+// The number of matching items, over all pages, is in the header `X-Total-Count`.
+type ListParam struct {
+	// Number of items of the page. Default is all.
+	// in: query
+	Limit int `json:"limit"`
+	// Number of matching items before the page.
+	// in: query
+	Offset int `json:"offset"`
+	// Prefix of the names of the items.
+	// in: query
+	Name string `json:"name"`
+	// Kube label selector of the items, e.g. `team=web,tier!=test`.
+	// in: query
+	Labels string `json:"labels"`
+	// Sort order, `name`, or `created`, reversed with a leading `-`. Default is by namespace, then name.
+	// in: query
+	Sort string `json:"sort"`
+}
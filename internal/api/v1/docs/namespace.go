@@ -9,6 +9,11 @@ import "github.com/epinio/epinio/pkg/api/core/v1/models"
 // responses:
 //   200: NamespacesResponse
 
+// swagger:parameters Namespaces
+type NamespacesParam struct {
+	ConditionalParam
+}
+
 // swagger:response NamespacesResponse
 type NamespacesResponse struct {
 	// in: body
@@ -58,6 +63,7 @@ type NamespaceDeleteResponse struct {
 type NamespaceShowParam struct {
 	// in: path
 	Namespace string
+	ConditionalParam
 }
 
 // swagger:response NamespaceShowResponse
@@ -66,6 +72,104 @@ type NamespaceShowResponse struct {
 	Body models.Namespace
 }
 
+// swagger:route PATCH /namespaces/{Namespace} namespace NamespaceUpdate
+// Replace the settings of the named `Namespace`.
+// responses:
+//   200: NamespaceUpdateResponse
+
+// swagger:parameters NamespaceUpdate
+type NamespaceUpdateParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.NamespaceUpdateRequest
+}
+
+// swagger:response NamespaceUpdateResponse
+type NamespaceUpdateResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route GET /namespaces/{Namespace}/export namespace NamespaceExport
+// Return the Kubernetes manifests of the named `Namespace` and its resources, i.e. apps,
+// configurations, and services, as YAML stream. Only admins of the namespace can do this.
+// responses:
+//   200: NamespaceExportResponse
+
+// swagger:parameters NamespaceExport
+type NamespaceExportParam struct {
+	// in: path
+	Namespace string
+	// Include the data of the secrets. Default only their keys, with empty values.
+	// in: query
+	Secrets bool
+}
+
+// swagger:response NamespaceExportResponse
+type NamespaceExportResponse struct {
+	// in: body
+	Body []byte
+}
+
+// swagger:route PUT /namespaces/{Namespace}/pod-security namespace NamespacePodSecuritySet
+// Change the pod security level enforced in the named `Namespace`. Admins only.
+// responses:
+//   200: NamespacePodSecuritySetResponse
+
+// swagger:parameters NamespacePodSecuritySet
+type NamespacePodSecuritySetParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.NamespacePodSecurityRequest
+}
+
+// swagger:response NamespacePodSecuritySetResponse
+type NamespacePodSecuritySetResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route PUT /namespaces/{Namespace}/quota namespace NamespaceQuotaSet
+// Replace the quota of the named `Namespace`. An empty quota removes it. Admins only.
+// responses:
+//   200: NamespaceQuotaSetResponse
+
+// swagger:parameters NamespaceQuotaSet
+type NamespaceQuotaSetParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.NamespaceQuotaRequest
+}
+
+// swagger:response NamespaceQuotaSetResponse
+type NamespaceQuotaSetResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route PUT /namespaces/{Namespace}/upload-limit namespace NamespaceUploadLimitSet
+// Change the size limit of the application sources uploaded to the named `Namespace`. An
+// empty limit restores the default of the server. Admins only.
+// responses:
+//   200: NamespaceUploadLimitSetResponse
+
+// swagger:parameters NamespaceUploadLimitSet
+type NamespaceUploadLimitSetParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.NamespaceUploadLimitRequest
+}
+
+// swagger:response NamespaceUploadLimitSetResponse
+type NamespaceUploadLimitSetResponse struct {
+	// in: body
+	Body models.Response
+}
+
 // swagger:route GET /namespacematches/{Pattern} namespace NamespaceMatch
 // Return list of names for all controlled namespaces whose name matches the prefix `Pattern`.
 // responses:
@@ -0,0 +1,82 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /namespaces/{Namespace}/notifications notification Notifications
+// Return the notification targets of the `Namespace`. Only namespace admins can do this.
+// responses:
+//   200: NotificationsResponse
+
+// swagger:parameters Notifications
+type NotificationsParam struct {
+	// in: path
+	Namespace string
+}
+
+// swagger:response NotificationsResponse
+type NotificationsResponse struct {
+	// in: body
+	Body models.NotificationTargetList
+}
+
+// swagger:route GET /namespaces/{Namespace}/notifications/{Notification} notification NotificationShow
+// Return the `Notification` target of the `Namespace`. Only namespace admins can do this.
+// responses:
+//   200: NotificationShowResponse
+
+// swagger:parameters NotificationShow
+type NotificationShowParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	Notification string
+}
+
+// swagger:response NotificationShowResponse
+type NotificationShowResponse struct {
+	// in: body
+	Body models.NotificationTarget
+}
+
+// swagger:route PUT /namespaces/{Namespace}/notifications/{Notification} notification NotificationSet
+// Create the `Notification` target of the `Namespace`, or replace its type, url, event types,
+// and template. Only namespace admins can do this.
+// responses:
+//   200: NotificationSetResponse
+
+// swagger:parameters NotificationSet
+type NotificationSetParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	Notification string
+	// in: body
+	Request models.NotificationTargetRequest
+}
+
+// swagger:response NotificationSetResponse
+type NotificationSetResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route DELETE /namespaces/{Namespace}/notifications/{Notification} notification NotificationDelete
+// Delete the `Notification` target of the `Namespace`. Only namespace admins can do this.
+// responses:
+//   200: NotificationDeleteResponse
+
+// swagger:parameters NotificationDelete
+type NotificationDeleteParam struct {
+	// in: path
+	Namespace string
+	// in: path
+	Notification string
+}
+
+// swagger:response NotificationDeleteResponse
+type NotificationDeleteResponse struct {
+	// in: body
+	Body models.Response
+}
@@ -0,0 +1,23 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /operations/{Operation} operation OperationShow
+// Return the long-running `Operation`, e.g. a staging, with its phase, progress, and error.
+// Only the operations of the namespaces of the user are visible.
+// responses:
+//   200: OperationShowResponse
+
+// swagger:parameters OperationShow
+type OperationShowParam struct {
+	// in: path
+	Operation string
+}
+
+// swagger:response OperationShowResponse
+type OperationShowResponse struct {
+	// in: body
+	Body models.Operation
+}
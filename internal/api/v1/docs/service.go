@@ -36,7 +36,8 @@ type ServiceCatalogShowResponse struct {
 }
 
 // swagger:route POST /namespaces/{Namespace}/services service ServiceCreate
-// Create a named service of an Epinio catalog service in the `Namespace`.
+// Create a named service of an Epinio catalog service in the `Namespace`. Return the id of the
+// operation tracking its provisioning.
 // responses:
 //   200: ServiceCreateResponse
 
@@ -51,7 +52,7 @@ type ServiceCreateParam struct {
 // swagger:response ServiceCreateResponse
 type ServiceCreateResponse struct {
 	// in: body
-	Body models.Response
+	Body models.ServiceCreateResponse
 }
 
 // swagger:route GET /namespaces/{Namespace}/services service ServiceList
@@ -63,6 +64,8 @@ type ServiceCreateResponse struct {
 type ServiceListParam struct {
 	// in: path
 	Namespace string
+	ListParam
+	ConditionalParam
 }
 
 // swagger:response ServiceListResponse
@@ -88,6 +91,7 @@ type ServiceShowParam struct {
 	Namespace string
 	// in: path
 	Service string
+	ConditionalParam
 }
 
 // swagger:route DELETE /namespaces/{Namespace}/services/{Service} service ServiceDelete
@@ -111,6 +115,25 @@ type ServiceDeleteResponse struct {
 	Body models.ServiceDeleteResponse
 }
 
+// swagger:route DELETE /namespaces/{Namespace}/services service ServiceBatchDelete
+// Delete the named services, or all services, in the `Namespace`.
+// responses:
+//   200: ServiceBatchDeleteResponse
+
+// swagger:parameters ServiceBatchDelete
+type ServiceBatchDeleteParam struct {
+	// in: path
+	Namespace string
+	// in: body
+	Body models.BatchDeleteRequest
+}
+
+// swagger:response ServiceBatchDeleteResponse
+type ServiceBatchDeleteResponse struct {
+	// in: body
+	Body models.BatchDeleteResponse
+}
+
 // swagger:route POST /namespaces/{Namespace}/services/{Service}/bind service ServiceBind
 // Bind the named `Service` in the `Namespace` to an App.
 // responses:
@@ -0,0 +1,38 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route POST /auth/session session SessionCreate
+// Start a session of the user, authenticated with user and password, and return its tokens.
+// responses:
+//   201: SessionResponse
+
+// swagger:route POST /auth/session/refresh session SessionRefresh
+// Return new tokens for the session of the refresh token. Needs no other authentication.
+// responses:
+//   200: SessionResponse
+
+// swagger:parameters SessionRefresh
+type SessionRefreshParam struct {
+	// in: body
+	Request models.SessionRefreshRequest
+}
+
+// swagger:response SessionResponse
+type SessionResponse struct {
+	// in: body
+	Body models.SessionResponse
+}
+
+// swagger:route DELETE /auth/session session SessionDelete
+// End the session of the token the request is authenticated with.
+// responses:
+//   200: SessionDeleteResponse
+
+// swagger:response SessionDeleteResponse
+type SessionDeleteResponse struct {
+	// in: body
+	Body models.Response
+}
@@ -0,0 +1,39 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /state state StateExport
+// Return the state of the platform, i.e. the namespaces with their custom configurations and
+// apps, and the users, with the registry and main domain of the installation, for the
+// migration to another installation. Only admins can do this.
+// responses:
+//   200: StateExportResponse
+
+// swagger:parameters StateExport
+type StateExportParam struct{}
+
+// swagger:response StateExportResponse
+type StateExportResponse struct {
+	// in: body
+	Body models.PlatformState
+}
+
+// swagger:route POST /state/users state StateUserImport
+// Create the users of the request, with their passwords, and roles, leaving existing users as
+// they are. Only admins can do this.
+// responses:
+//   200: StateUserImportResponse
+
+// swagger:parameters StateUserImport
+type StateUserImportParam struct {
+	// in: body
+	Request models.UserImportRequest
+}
+
+// swagger:response StateUserImportResponse
+type StateUserImportResponse struct {
+	// in: body
+	Body models.UserImportResponse
+}
@@ -0,0 +1,91 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /teams team Teams
+// Return the teams. Only admins can do this.
+// responses:
+//   200: TeamsResponse
+
+// swagger:parameters Teams
+type TeamsParam struct{}
+
+// swagger:response TeamsResponse
+type TeamsResponse struct {
+	// in: body
+	Body models.TeamList
+}
+
+// swagger:route GET /teams/{Team} team TeamShow
+// Return the `Team`. Only admins can do this.
+// responses:
+//   200: TeamShowResponse
+
+// swagger:parameters TeamShow
+type TeamShowParam struct {
+	// in: path
+	Team string
+}
+
+// swagger:response TeamShowResponse
+type TeamShowResponse struct {
+	// in: body
+	Body models.Team
+}
+
+// swagger:route PUT /teams/{Team} team TeamSet
+// Create the `Team`, or replace its members and groups. Only admins can do this.
+// responses:
+//   200: TeamSetResponse
+
+// swagger:parameters TeamSet
+type TeamSetParam struct {
+	// in: path
+	Team string
+	// in: body
+	Request models.TeamRequest
+}
+
+// swagger:response TeamSetResponse
+type TeamSetResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route DELETE /teams/{Team} team TeamDelete
+// Delete the `Team`. Only admins can do this.
+// responses:
+//   200: TeamDeleteResponse
+
+// swagger:parameters TeamDelete
+type TeamDeleteParam struct {
+	// in: path
+	Team string
+}
+
+// swagger:response TeamDeleteResponse
+type TeamDeleteResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route PUT /teams/{Team}/roles team TeamRoleSet
+// Give the `Team` a role in a namespace. Only admins can do this.
+// responses:
+//   200: TeamRoleSetResponse
+
+// swagger:parameters TeamRoleSet
+type TeamRoleSetParam struct {
+	// in: path
+	Team string
+	// in: body
+	Request models.UserRoleRequest
+}
+
+// swagger:response TeamRoleSetResponse
+type TeamRoleSetResponse struct {
+	// in: body
+	Body models.Response
+}
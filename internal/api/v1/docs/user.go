@@ -0,0 +1,84 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route PUT /users/{User}/roles user UserRoleSet
+// Give the `User` a role in a namespace, permanently, or until it expires. Only admins can do
+// this.
+// responses:
+//   200: UserRoleSetResponse
+
+// swagger:parameters UserRoleSet
+type UserRoleSetParam struct {
+	// in: path
+	User string
+	// in: body
+	Request models.UserRoleRequest
+}
+
+// swagger:response UserRoleSetResponse
+type UserRoleSetResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route PUT /auth/password user PasswordChange
+// Replace the password of the user, after checking the current one. Not available to API
+// tokens, nor to users which are not Epinio users.
+// responses:
+//   200: PasswordChangeResponse
+
+// swagger:parameters PasswordChange
+type PasswordChangeParam struct {
+	// in: body
+	Request models.PasswordChangeRequest
+}
+
+// swagger:response PasswordChangeResponse
+type PasswordChangeResponse struct {
+	// in: body
+	Body models.Response
+}
+
+// swagger:route PUT /users/{User}/password user UserPasswordReset
+// Give the `User` a new password, the requested one, or a random one, and end their sessions.
+// Only admins can do this.
+// responses:
+//   200: PasswordResetResponse
+
+// swagger:parameters UserPasswordReset
+type UserPasswordResetParam struct {
+	// in: path
+	User string
+	// in: body
+	Request models.PasswordResetRequest
+}
+
+// swagger:response PasswordResetResponse
+type PasswordResetResponse struct {
+	// in: body
+	Body models.PasswordResetResponse
+}
+
+// swagger:route PUT /users/{User}/federation user UserFederationSet
+// Mark the `User` as the same person as the user of the same name at the LDAP server, or the
+// OIDC issuer, or remove the mark. Only marked users log in there with their roles, the names
+// of the others are refused there. Only admins can do this.
+// responses:
+//   200: UserFederationSetResponse
+
+// swagger:parameters UserFederationSet
+type UserFederationSetParam struct {
+	// in: path
+	User string
+	// in: body
+	Request models.UserFederationRequest
+}
+
+// swagger:response UserFederationSetResponse
+type UserFederationSetResponse struct {
+	// in: body
+	Body models.Response
+}
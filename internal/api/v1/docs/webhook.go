@@ -0,0 +1,73 @@
+package docs
+
+//go:generate swagger generate spec
+
+import "github.com/epinio/epinio/pkg/api/core/v1/models"
+
+// swagger:route GET /webhooks webhook Webhooks
+// Return the webhooks receiving the platform events. Only admins can do this.
+// responses:
+//   200: WebhooksResponse
+
+// swagger:parameters Webhooks
+type WebhooksParam struct{}
+
+// swagger:response WebhooksResponse
+type WebhooksResponse struct {
+	// in: body
+	Body models.WebhookList
+}
+
+// swagger:route GET /webhooks/{Webhook} webhook WebhookShow
+// Return the `Webhook`, without its secret. Only admins can do this.
+// responses:
+//   200: WebhookShowResponse
+
+// swagger:parameters WebhookShow
+type WebhookShowParam struct {
+	// in: path
+	Webhook string
+}
+
+// swagger:response WebhookShowResponse
+type WebhookShowResponse struct {
+	// in: body
+	Body models.Webhook
+}
+
+// swagger:route PUT /webhooks/{Webhook} webhook WebhookSet
+// Create the `Webhook`, or replace its url, event types, and secret. Return the secret the
+// events are signed with. Only admins can do this.
+// responses:
+//   200: WebhookSetResponse
+
+// swagger:parameters WebhookSet
+type WebhookSetParam struct {
+	// in: path
+	Webhook string
+	// in: body
+	Request models.WebhookRequest
+}
+
+// swagger:response WebhookSetResponse
+type WebhookSetResponse struct {
+	// in: body
+	Body models.WebhookSetResponse
+}
+
+// swagger:route DELETE /webhooks/{Webhook} webhook WebhookDelete
+// Delete the `Webhook`. Only admins can do this.
+// responses:
+//   200: WebhookDeleteResponse
+
+// swagger:parameters WebhookDelete
+type WebhookDeleteParam struct {
+	// in: path
+	Webhook string
+}
+
+// swagger:response WebhookDeleteResponse
+type WebhookDeleteResponse struct {
+	// in: body
+	Body models.Response
+}
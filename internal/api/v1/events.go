@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/internal/api/v1/application"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/spf13/viper"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// eventsPingInterval is the time between pings, keeping idle connections open through proxies.
+const eventsPingInterval = 30 * time.Second
+
+// Events handles the API endpoint GET /events. It streams the platform events the user may
+// see over a websocket, until the connection is closed. The `namespace` and `type` (comma
+// separated) query parameters restrict the events. Clients reconnecting pass the id of the
+// last event they got as `after`, to get the recent events they missed first.
+func Events(c *gin.Context) APIErrors {
+	ctx := c.Request.Context()
+	log := requestctx.Logger(ctx)
+
+	var after uint64
+	if value := c.Query("after"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return NewBadRequest("bad after, expected the id of an event", value)
+		}
+		after = id
+	}
+
+	types := []string{}
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	filter := events.Filter(requestctx.User(ctx), c.Query("namespace"), types)
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: application.CheckOriginFunc(viper.GetStringSlice("access-control-allow-origin")),
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	subscription, missed := events.Default.Subscribe(after, filter)
+	defer events.Default.Unsubscribe(subscription)
+
+	log.Info("streaming platform events", "after", after)
+
+	err = streamPlatformEvents(ctx, conn, subscription, missed)
+	if err != nil {
+		log.V(1).Error(err, "error occurred after upgrading the websockets connection")
+	}
+
+	return nil
+}
+
+// streamPlatformEvents sends the missed events, and then those of the subscription, to the
+// websocket connection, until the client closes it, or the context is done. When the
+// subscription is dropped for falling behind, the connection is closed, asking the client to
+// reconnect.
+func streamPlatformEvents(ctx context.Context, conn *websocket.Conn, subscription *events.Subscription, missed []models.PlatformEvent) error {
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Reading is required to notice the client closing the connection.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	send := func(event models.PlatformEvent) error {
+		msg, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return conn.WriteMessage(websocket.TextMessage, msg)
+	}
+
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	ping := time.NewTicker(eventsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventsPingInterval)); err != nil {
+				return err
+			}
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too far behind, reconnect"), time.Time{})
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -3,17 +3,21 @@ package namespace
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/auth"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/internal/helmchart"
 	"github.com/epinio/epinio/internal/namespaces"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/pkg/errors"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 )
 
 // Create handles the API endpoint /namespaces (POST).
@@ -38,6 +42,10 @@ func (oc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.BadRequest(err)
 	}
 
+	if err := namespaces.ValidateQuota(request.Quota); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
 	exists, err := namespaces.Exists(ctx, cluster, namespaceName)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -46,7 +54,12 @@ func (oc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.NamespaceAlreadyKnown(namespaceName)
 	}
 
-	err = namespaces.Create(ctx, cluster, namespaceName)
+	err = namespaces.Create(ctx, cluster, namespaceName, namespaces.CreateOptions{
+		PodSecurity:       viper.GetString("pod-security-level"),
+		NetworkIsolation:  viper.GetBool("network-isolation"),
+		AllowedNamespaces: allowedNamespaces(),
+		Quota:             request.Quota,
+	})
 	if err != nil {
 		return apierror.InternalError(err)
 	}
@@ -56,14 +69,33 @@ func (oc Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	events.Publish(ctx, models.EventNamespaceCreated, namespaceName, namespaceName, nil)
+
 	response.Created(c)
 	return nil
 }
 
+// allowedNamespaces returns the namespaces whose pods may reach the applications of isolated
+// namespaces. Epinio's own namespace is always allowed, for the waking of sleeping apps.
+func allowedNamespaces() []string {
+	allowed := []string{helmchart.Namespace()}
+	for _, name := range strings.Split(viper.GetString("network-allowed-namespaces"), ",") {
+		if name = strings.TrimSpace(name); name != "" && name != helmchart.Namespace() {
+			allowed = append(allowed, name)
+		}
+	}
+	return allowed
+}
+
 // addNamespaceToUser will add the namespace to the User namespaces
 func addNamespaceToUser(ctx context.Context, namespace string) error {
 	user := requestctx.User(ctx)
 
+	// Users without Epinio user have no namespaces to add to.
+	if user.IsExternal() {
+		return nil
+	}
+
 	authService, err := auth.NewAuthServiceFromContext(ctx)
 	if err != nil {
 		return errors.Wrap(err, "error creating auth service")
@@ -11,6 +11,7 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/auth"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/events"
 	"github.com/epinio/epinio/internal/namespaces"
 	"github.com/epinio/epinio/internal/services"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
@@ -76,6 +77,8 @@ func (oc Controller) Delete(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	events.Publish(ctx, models.EventNamespaceDeleted, namespace, namespace, nil)
+
 	response.OK(c)
 	return nil
 }
@@ -143,7 +146,7 @@ func deleteServices(ctx context.Context, cluster *kubernetes.Cluster, namespace
 	return kubeServiceClient.DeleteAll(ctx, namespace)
 }
 
-// deleteNamespaceFromUsers will delete the namespace from all the Users
+// deleteNamespaceFromUsers will delete the namespace from all the Users, and Teams
 func deleteNamespaceFromUsers(ctx context.Context, namespace string) error {
 	authService, err := auth.NewAuthServiceFromContext(ctx)
 	if err != nil {
@@ -155,5 +158,10 @@ func deleteNamespaceFromUsers(ctx context.Context, namespace string) error {
 		return errors.Wrap(err, fmt.Sprintf("error removing namespace [%s] from users", namespace))
 	}
 
+	err = authService.RemoveNamespaceFromTeams(ctx, namespace)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error removing namespace [%s] from teams", namespace))
+	}
+
 	return nil
 }
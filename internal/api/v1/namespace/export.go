@@ -0,0 +1,51 @@
+package namespace
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/manifests"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/dynamic"
+)
+
+// Export handles the API endpoint GET /namespaces/:namespace/export
+// It returns the manifests of the namespace and its resources, i.e. apps, configurations, and
+// services, as a YAML stream. The data of the secrets is only included with the query
+// parameter `secrets=true`.
+func (hc Controller) Export(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	secrets := c.Query("secrets") == "true"
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !exists {
+		return apierror.NamespaceIsNotKnown(namespace)
+	}
+
+	client, err := dynamic.NewForConfig(cluster.RestConfig)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	stream, err := manifests.Export(ctx, client, namespace, secrets)
+	if err != nil {
+		return apierror.InternalError(err, "exporting the manifests")
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.yaml", namespace))
+	response.OKBytes(c, stream)
+	return nil
+}
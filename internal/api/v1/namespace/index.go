@@ -2,6 +2,7 @@ package namespace
 
 import (
 	"context"
+	"sort"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
@@ -54,10 +55,13 @@ func (oc Controller) Index(c *gin.Context) apierror.APIErrors {
 			},
 			Apps:           appNames,
 			Configurations: configurationNames,
+			Settings:       namespace.Settings,
+			PodSecurity:    namespace.PodSecurity,
+			UploadLimit:    uploadLimit(namespace),
 		})
 	}
 
-	response.OKReturn(c, namespaces)
+	response.OKReturnETag(c, namespaces)
 	return nil
 }
 
@@ -71,6 +75,7 @@ func namespaceApps(ctx context.Context, cluster *kubernetes.Cluster, namespace s
 	for _, app := range appRefs {
 		appNames = append(appNames, app.Name)
 	}
+	sort.Strings(appNames)
 
 	return appNames, nil
 }
@@ -85,6 +90,7 @@ func namespaceConfigurations(ctx context.Context, cluster *kubernetes.Cluster, n
 	for _, configuration := range configurations {
 		configurationNames = append(configurationNames, configuration.Name)
 	}
+	sort.Strings(configurationNames)
 
 	return configurationNames, nil
 }
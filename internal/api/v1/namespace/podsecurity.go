@@ -0,0 +1,48 @@
+package namespace
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PodSecuritySet handles the API endpoint PUT /namespaces/:namespace/pod-security
+// It changes the pod security level enforced in the namespace, e.g. to relax it for
+// applications which cannot run with the restricted level. Only admins can do this.
+func (oc Controller) PodSecuritySet(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+
+	var request models.NamespacePodSecurityRequest
+	if err := c.BindJSON(&request); err != nil {
+		return apierror.BadRequest(err)
+	}
+	if err := namespaces.ValidatePodSecurity(request.Level); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !exists {
+		return apierror.NamespaceIsNotKnown(namespace)
+	}
+
+	err = namespaces.PodSecuritySet(ctx, cluster, namespace, request.Level)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
@@ -45,13 +45,22 @@ func (hc Controller) Show(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, models.Namespace{
+	quota, err := namespaces.Quota(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturnETag(c, models.Namespace{
 		Meta: models.MetaLite{
 			Name:      namespace,
 			CreatedAt: space.CreatedAt,
 		},
 		Apps:           appNames,
 		Configurations: configurationNames,
+		Settings:       space.Settings,
+		PodSecurity:    space.PodSecurity,
+		UploadLimit:    uploadLimit(*space),
+		Quota:          quota,
 	})
 	return nil
 }
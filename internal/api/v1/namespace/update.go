@@ -0,0 +1,101 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/appchart"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/logsink"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Update handles the API endpoint PATCH /namespaces/:namespace
+// It replaces the settings of the specified namespace.
+func (oc Controller) Update(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if !exists {
+		return apierror.NamespaceIsNotKnown(namespace)
+	}
+
+	var updateRequest models.NamespaceUpdateRequest
+	err = c.BindJSON(&updateRequest)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	settings := updateRequest.Settings
+
+	if err := application.ValidateBuilder(settings.BuilderImage, settings.Buildpacks); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if err := application.ValidateStagingResources(settings.Staging); err != nil {
+		return apierror.NewBadRequest(err.Error())
+	}
+
+	if settings.StagingConcurrency < 0 {
+		return apierror.NewBadRequest("staging concurrency cannot be negative")
+	}
+
+	if settings.LogSink != nil {
+		if err := logsink.Validate(*settings.LogSink); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	if apierr := validateAppCharts(ctx, cluster, settings); apierr != nil {
+		return apierr
+	}
+
+	err = namespaces.SettingsSet(ctx, cluster, namespace, settings)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// validateAppCharts checks that the default and allowed app charts of the namespace exist,
+// and that the default is allowed.
+func validateAppCharts(ctx context.Context, cluster *kubernetes.Cluster, settings models.NamespaceSettings) apierror.APIErrors {
+	charts := append([]string{}, settings.AllowedAppCharts...)
+	if settings.AppChart != "" {
+		charts = append(charts, settings.AppChart)
+	}
+
+	for _, chart := range charts {
+		found, err := appchart.Exists(ctx, cluster, chart)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		if !found {
+			return apierror.AppChartIsNotKnown(chart)
+		}
+	}
+
+	if settings.AppChart != "" && !settings.AppChartAllowed(settings.AppChart) {
+		return apierror.NewBadRequest(fmt.Sprintf("default app chart '%s' is not one of the allowed app charts", settings.AppChart))
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package namespace
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// UploadLimitSet handles the API endpoint PUT /namespaces/:namespace/upload-limit
+// It changes the size limit of the application sources uploaded to the namespace, replacing
+// the default limit of the server for it. Only admins can do this.
+func (oc Controller) UploadLimitSet(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+
+	var request models.NamespaceUploadLimitRequest
+	if err := c.BindJSON(&request); err != nil {
+		return apierror.BadRequest(err)
+	}
+	if request.Limit != "" {
+		if _, err := namespaces.ParseUploadLimit(request.Limit); err != nil {
+			return apierror.NewBadRequest(err.Error())
+		}
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !exists {
+		return apierror.NamespaceIsNotKnown(namespace)
+	}
+
+	err = namespaces.UploadLimitSet(ctx, cluster, namespace, request.Limit)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// uploadLimit returns the size limit of the uploads to the namespace, its own, or else the
+// default of the server.
+func uploadLimit(space namespaces.Namespace) string {
+	if space.UploadLimit != "" {
+		return space.UploadLimit
+	}
+	return viper.GetString("upload-limit")
+}
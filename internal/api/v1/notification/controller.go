@@ -0,0 +1,47 @@
+// Package notification contains the API handlers to manage the notification targets of the
+// namespaces, receiving messages about their events.
+package notification
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/internal/notifications"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Controller represents all functionality of the API related to notification targets
+type Controller struct {
+}
+
+// store returns the store of the notification targets of the namespace, after checking that
+// the namespace exists.
+func store(ctx context.Context, namespace string) (*notifications.Store, apierror.APIErrors) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, namespace)
+	if err != nil {
+		return nil, apierror.InternalError(err)
+	}
+	if !exists {
+		return nil, apierror.NamespaceIsNotKnown(namespace)
+	}
+
+	return notifications.NewStore(cluster, namespace), nil
+}
+
+// toModel converts the target into its API representation.
+func toModel(target notifications.Target) models.NotificationTarget {
+	return models.NotificationTarget{
+		Name:     target.Name,
+		Type:     target.Type,
+		URL:      target.URL,
+		Events:   target.Events,
+		Template: target.Template,
+	}
+}
@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/notifications"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete handles the API endpoint DELETE /namespaces/:namespace/notifications/:notification
+// It removes the notification target. The events are not notified to it anymore.
+func (hc Controller) Delete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	name := c.Param("notification")
+
+	targetStore, apierr := store(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+
+	err := targetStore.Delete(ctx, name)
+	if err == notifications.ErrTargetNotFound {
+		return apierror.NewNotFoundError("notification target not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
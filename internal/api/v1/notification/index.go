@@ -0,0 +1,34 @@
+package notification
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Index handles the API endpoint GET /namespaces/:namespace/notifications
+// It lists the notification targets of the namespace.
+func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+
+	targetStore, apierr := store(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+
+	targets, err := targetStore.List(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result := models.NotificationTargetList{}
+	for _, target := range targets {
+		result = append(result, toModel(target))
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
@@ -0,0 +1,55 @@
+package notification
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/notifications"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Set handles the API endpoint PUT /namespaces/:namespace/notifications/:notification
+// It creates the notification target, or replaces its settings.
+func (hc Controller) Set(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	name := c.Param("notification")
+
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return apierror.NewBadRequest("bad notification target name", strings.Join(errs, ", "))
+	}
+
+	var request models.NotificationTargetRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	target := notifications.Target{
+		Name:      name,
+		Namespace: namespace,
+		Type:      request.Type,
+		URL:       request.URL,
+		Events:    request.Events,
+		Template:  request.Template,
+	}
+	if err := target.Validate(); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	targetStore, apierr := store(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+
+	if err := targetStore.Set(ctx, target); err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
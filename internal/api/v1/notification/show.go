@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/notifications"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Show handles the API endpoint GET /namespaces/:namespace/notifications/:notification
+// It returns the notification target.
+func (hc Controller) Show(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	namespace := c.Param("namespace")
+	name := c.Param("notification")
+
+	targetStore, apierr := store(ctx, namespace)
+	if apierr != nil {
+		return apierr
+	}
+
+	target, err := targetStore.Get(ctx, name)
+	if err == notifications.ErrTargetNotFound {
+		return apierror.NewNotFoundError("notification target not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, toModel(target))
+	return nil
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// OIDCConfig handles the API endpoint /auth/oidc. It returns the OIDC issuer and client the
+// API accepts ID tokens of, for clients to log in with. It needs no authentication.
+func OIDCConfig(c *gin.Context) APIErrors {
+	issuer := viper.GetString("oidc-issuer")
+	if issuer == "" {
+		return NewNotFoundError("single sign-on is not configured")
+	}
+
+	response.OKReturn(c, models.OIDCConfigResponse{
+		Issuer:   issuer,
+		ClientID: viper.GetString("oidc-client-id"),
+	})
+	return nil
+}
@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/epinio/epinio/internal/api/v1/openapi"
+	"github.com/epinio/epinio/internal/version"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// listQuery names the query parameters of the list endpoints, see listing.FromQuery.
+var listQuery = []string{"limit", "offset", "name", "labels", "sort"}
+
+// Endpoints describes the requests and responses of the routes, for the OpenAPI document.
+// Routes without entry have neither request nor response body.
+var Endpoints = map[string]openapi.Endpoint{
+	"Info":                    {Summary: "Return the versions of Epinio and kube", Response: models.InfoResponse{}},
+	"AuthToken":               {Summary: "Return a token for the websocket endpoints", Response: models.AuthTokenResponse{}},
+	"SessionCreate":           {Summary: "Start a session of the user", Response: models.SessionResponse{}, Status: http.StatusCreated},
+	"SessionDelete":           {Summary: "End the session of the token", Response: models.Response{}},
+	"SessionRefresh":          {Summary: "Return new tokens for the session of the refresh token", Request: models.SessionRefreshRequest{}, Response: models.SessionResponse{}},
+	"OIDCConfig":              {Summary: "Return the OIDC issuer and client", Response: models.OIDCConfigResponse{}},
+	"PasswordChange":          {Summary: "Change the password of the user", Request: models.PasswordChangeRequest{}, Response: models.Response{}},
+	"ClientCertificateCreate": {Summary: "Sign a client certificate for mutual TLS", Request: models.ClientCertificateRequest{}, Response: models.ClientCertificateResponse{}, Status: http.StatusCreated},
+	"AuditEntries":            {Summary: "Return the recent audit entries", Response: models.AuditEntryList{}, Query: []string{"user", "namespace", "limit"}},
+
+	"APITokens":      {Summary: "Return the API tokens of the user", Response: models.APITokenList{}},
+	"APITokenShow":   {Summary: "Return an API token of the user", Response: models.APIToken{}},
+	"APITokenCreate": {Summary: "Create an API token", Request: models.APITokenCreateRequest{}, Response: models.APITokenCreateResponse{}, Status: http.StatusCreated},
+	"APITokenDelete": {Summary: "Revoke an API token", Response: models.Response{}},
+
+	"UserRoleSet":       {Summary: "Give a user a role in a namespace", Request: models.UserRoleRequest{}, Response: models.Response{}},
+	"UserPasswordReset": {Summary: "Reset the password of a user", Request: models.PasswordResetRequest{}, Response: models.PasswordResetResponse{}},
+	"UserFederationSet": {Summary: "Mark a user as federated, or not", Request: models.UserFederationRequest{}, Response: models.Response{}},
+
+	"Teams":       {Summary: "Return the teams", Response: models.TeamList{}},
+	"TeamShow":    {Summary: "Return a team", Response: models.Team{}},
+	"TeamSet":     {Summary: "Create, or change, a team", Request: models.TeamRequest{}, Response: models.Response{}},
+	"TeamDelete":  {Summary: "Delete a team", Response: models.Response{}},
+	"TeamRoleSet": {Summary: "Give a team a role in a namespace", Request: models.UserRoleRequest{}, Response: models.Response{}},
+
+	"Webhooks":      {Summary: "Return the webhooks", Response: models.WebhookList{}},
+	"WebhookShow":   {Summary: "Return a webhook", Response: models.Webhook{}},
+	"WebhookSet":    {Summary: "Create, or change, a webhook", Request: models.WebhookRequest{}, Response: models.WebhookSetResponse{}},
+	"WebhookDelete": {Summary: "Delete a webhook", Response: models.Response{}},
+
+	"Backups":       {Summary: "Return the backups of the platform state", Response: models.BackupList{}},
+	"BackupCreate":  {Summary: "Back up the platform state", Request: models.BackupCreateRequest{}, Response: models.Backup{}},
+	"BackupRestore": {Summary: "Restore the platform state from a backup", Response: models.BackupRestoreResponse{}},
+
+	"StateExport":     {Summary: "Export the platform state, for a migration", Response: models.PlatformState{}},
+	"StateUserImport": {Summary: "Create the users of a migration", Request: models.UserImportRequest{}, Response: models.UserImportResponse{}},
+
+	"OperationShow": {Summary: "Return a long-running operation", Response: models.Operation{}},
+
+	"AllApps":          {Summary: "Return the apps of all namespaces", Response: models.AppList{}, Query: listQuery, ETag: true},
+	"Apps":             {Summary: "Return the apps of the namespace", Response: models.AppList{}, Query: listQuery, ETag: true},
+	"AppCreate":        {Summary: "Create an app", Request: models.ApplicationCreateRequest{}, Response: models.Response{}, Status: http.StatusCreated},
+	"AppApply":         {Summary: "Create, or change, an app to match its manifest", Request: models.ApplicationApplyRequest{}, Response: models.ApplicationApplyResponse{}},
+	"AppAdopt":         {Summary: "Turn an existing deployment into an app", Request: models.AppAdoptRequest{}, Response: models.AppAdoptResponse{}},
+	"AppShow":          {Summary: "Return an app", Response: models.App{}, ETag: true},
+	"AppUpdate":        {Summary: "Change an app", Request: models.ApplicationUpdateRequest{}, Response: models.Response{}},
+	"AppDelete":        {Summary: "Delete an app", Response: models.ApplicationDeleteResponse{}},
+	"AppBatchDelete":   {Summary: "Delete apps", Request: models.BatchDeleteRequest{}, Response: models.BatchDeleteResponse{}},
+	"StagingComplete":  {Summary: "Wait for a staging to complete", Response: models.Response{}},
+	"StagingQueue":     {Summary: "Return the position of a staging in the queue", Response: models.StagingQueueResponse{}},
+	"AppUpload":        {Summary: "Upload the sources of an app", Request: openapi.Binary{}, Response: models.UploadResponse{}},
+	"AppUploadStart":   {Summary: "Start an upload of the sources of an app in parts", Response: models.UploadStartResponse{}},
+	"AppUploadPart":    {Summary: "Upload a part of the sources of an app", Request: openapi.Binary{}, Response: models.Response{}, Query: []string{"upload", "part"}},
+	"AppUploadFinish":  {Summary: "Assemble the uploaded parts of the sources of an app", Response: models.UploadResponse{}, Query: []string{"upload"}},
+	"AppUploadAbort":   {Summary: "Abort an upload in parts", Response: models.Response{}, Query: []string{"upload"}},
+	"AppChunksMissing": {Summary: "Return the source chunks unknown to the server", Request: models.SourceChunksRequest{}, Response: models.SourceChunksResponse{}},
+	"AppChunkUpload":   {Summary: "Upload a source chunk", Request: openapi.Binary{}, Response: models.Response{}},
+	"AppUploadDelta":   {Summary: "Assemble the sources of an app from their files and chunks", Request: models.SourceDeltaRequest{}, Response: models.UploadResponse{}},
+	"AppImportGit":     {Summary: "Import the sources of an app from git", Response: models.ImportGitResponse{}},
+	"AppStage":         {Summary: "Stage an app", Request: models.StageRequest{}, Response: models.StageResponse{}},
+	"AppDeploy":        {Summary: "Deploy an app", Request: models.DeployRequest{}, Response: models.DeployResponse{}, Query: []string{"async"}},
+	"AppCanaryAbort":   {Summary: "Remove the canary of an app", Response: models.Response{}},
+	"AppCanaryPromote": {Summary: "Shift traffic to the canary of an app", Request: models.ApplicationCanaryPromoteRequest{}, Response: models.Response{}},
+	"AppRestart":       {Summary: "Restart an app", Response: models.Response{}},
+	"AppBatchRestart":  {Summary: "Restart apps", Request: models.BatchRestartRequest{}, Response: models.BatchRestartResponse{}},
+	"AppRename":        {Summary: "Rename an app", Request: models.ApplicationRenameRequest{}, Response: models.Response{}},
+	"AppSleep":         {Summary: "Put an app to sleep", Response: models.Response{}},
+	"AppWake":          {Summary: "Wake a sleeping app", Response: models.Response{}},
+	"AppRevisions":     {Summary: "Return the revisions of an app", Response: models.AppRevisionList{}},
+	"AppRollback":      {Summary: "Roll an app back to a revision", Request: models.ApplicationRollbackRequest{}, Response: models.Response{}},
+	"AppTasks":         {Summary: "Return the tasks of an app", Response: models.AppTaskList{}},
+	"AppTaskRun":       {Summary: "Run a task of an app", Response: models.AppTaskRunResponse{}},
+	"AppDomains":       {Summary: "Return the custom domains of an app", Response: models.AppDomainList{}},
+	"AppDomainAdd":     {Summary: "Add a custom domain to an app", Request: models.AppDomainRequest{}, Response: models.AppDomainStatus{}},
+	"AppDomainRemove":  {Summary: "Remove a custom domain from an app", Response: models.Response{}},
+	"AppRunning":       {Summary: "Wait for an app to run", Response: models.Response{}},
+	"AppPart":          {Summary: "Download a part of an app", Response: openapi.Binary{}},
+
+	"EnvList":   {Summary: "Return the environment of an app", Response: models.EnvVariableMap{}},
+	"EnvMatch":  {Summary: "Return the environment variables matching the pattern", Response: models.EnvMatchResponse{}},
+	"EnvMatch0": {Summary: "Return all environment variable names", Response: models.EnvMatchResponse{}},
+	"EnvSet":    {Summary: "Set environment variables of an app", Request: models.EnvVariableMap{}, Response: models.Response{}},
+	"EnvShow":   {Summary: "Return an environment variable of an app", Response: models.EnvVariable{}},
+	"EnvUnset":  {Summary: "Remove an environment variable of an app", Response: models.Response{}},
+
+	"ConfigurationBindingCreate": {Summary: "Bind configurations to an app", Request: models.BindRequest{}, Response: models.BindResponse{}},
+	"ConfigurationBindingDelete": {Summary: "Unbind a configuration from an app", Response: models.Response{}},
+
+	"Namespaces":              {Summary: "Return the namespaces", Response: models.NamespaceList{}, ETag: true},
+	"NamespaceCreate":         {Summary: "Create a namespace", Request: models.NamespaceCreateRequest{}, Response: models.Response{}, Status: http.StatusCreated},
+	"NamespaceDelete":         {Summary: "Delete a namespace", Response: models.Response{}},
+	"NamespaceShow":           {Summary: "Return a namespace", Response: models.Namespace{}, ETag: true},
+	"NamespaceUpdate":         {Summary: "Change the settings of a namespace", Request: models.NamespaceUpdateRequest{}, Response: models.Response{}},
+	"NamespaceExport":         {Summary: "Return the manifests of a namespace and its resources", Response: openapi.Binary{}, Query: []string{"secrets"}},
+	"NamespacePodSecuritySet": {Summary: "Set the pod security level of a namespace", Request: models.NamespacePodSecurityRequest{}, Response: models.Response{}},
+	"NamespaceQuotaSet":       {Summary: "Set the quota of a namespace", Request: models.NamespaceQuotaRequest{}, Response: models.Response{}},
+	"NamespaceUploadLimitSet": {Summary: "Set the size limit of the uploads to a namespace", Request: models.NamespaceUploadLimitRequest{}, Response: models.Response{}},
+
+	"Notifications":      {Summary: "Return the notification targets of a namespace", Response: models.NotificationTargetList{}},
+	"NotificationShow":   {Summary: "Return a notification target of a namespace", Response: models.NotificationTarget{}},
+	"NotificationSet":    {Summary: "Create, or change, a notification target of a namespace", Request: models.NotificationTargetRequest{}, Response: models.Response{}},
+	"NotificationDelete": {Summary: "Delete a notification target of a namespace", Response: models.Response{}},
+
+	"NamespacesMatch":  {Summary: "Return the namespaces matching the pattern", Response: models.NamespacesMatchResponse{}},
+	"NamespacesMatch0": {Summary: "Return all namespace names", Response: models.NamespacesMatchResponse{}},
+
+	"ConfigurationApps":        {Summary: "Return the apps bound to the configurations of the namespace", Response: models.ConfigurationAppsResponse{}},
+	"AllConfigurations":        {Summary: "Return the configurations of all namespaces", Response: models.ConfigurationResponseList{}, Query: listQuery, ETag: true},
+	"Configurations":           {Summary: "Return the configurations of the namespace", Response: models.ConfigurationResponseList{}, Query: listQuery, ETag: true},
+	"ConfigurationShow":        {Summary: "Return a configuration", Response: models.ConfigurationResponse{}},
+	"ConfigurationCreate":      {Summary: "Create a configuration", Request: models.ConfigurationCreateRequest{}, Response: models.Response{}, Status: http.StatusCreated},
+	"ConfigurationDelete":      {Summary: "Delete a configuration", Request: models.ConfigurationDeleteRequest{}, Response: models.ConfigurationDeleteResponse{}},
+	"ConfigurationUpdate":      {Summary: "Change a configuration", Request: models.ConfigurationUpdateRequest{}, Response: models.Response{}},
+	"ConfigurationReplace":     {Summary: "Replace a configuration", Request: models.ConfigurationReplaceRequest{}, Response: models.Response{}},
+	"ConfigurationBatchDelete": {Summary: "Delete configurations", Request: models.BatchDeleteRequest{}, Response: models.BatchDeleteResponse{}},
+
+	"ServiceCatalog":     {Summary: "Return the service catalog", Response: models.ServiceCatalogResponse{}},
+	"ServiceCatalogShow": {Summary: "Return a catalog service", Response: models.ServiceCatalogShowResponse{}},
+	"ServiceCreate":      {Summary: "Create a service", Request: models.ServiceCreateRequest{}, Response: models.ServiceCreateResponse{}},
+	"ServiceList":        {Summary: "Return the services of the namespace", Response: models.ServiceListResponse{}, Query: listQuery, ETag: true},
+	"ServiceShow":        {Summary: "Return a service", Response: models.ServiceShowResponse{}, ETag: true},
+	"ServiceDelete":      {Summary: "Delete a service", Request: models.ServiceDeleteRequest{}, Response: models.ServiceDeleteResponse{}},
+	"ServiceBatchDelete": {Summary: "Delete services", Request: models.BatchDeleteRequest{}, Response: models.BatchDeleteResponse{}},
+	"ServiceBind":        {Summary: "Bind a service to an app", Request: models.ServiceBindRequest{}, Response: models.Response{}},
+	"ServiceUnbind":      {Summary: "Unbind a service from an app", Request: models.ServiceUnbindRequest{}, Response: models.Response{}},
+
+	"ChartList":   {Summary: "Return the app charts", Response: models.AppChartList{}},
+	"ChartMatch":  {Summary: "Return the app charts matching the pattern", Response: models.ChartMatchResponse{}},
+	"ChartMatch0": {Summary: "Return all app chart names", Response: models.ChartMatchResponse{}},
+	"ChartShow":   {Summary: "Return an app chart", Response: models.AppChart{}},
+}
+
+// OpenAPIDocument returns the OpenAPI document of the API. The documentation routes
+// themselves, and the websocket routes, are not part of it.
+func OpenAPIDocument() *openapi.Document {
+	document := openapi.New("Epinio", version.Version, Root)
+	document.AddAll(Routes, Endpoints, false)
+	document.AddAll(PublicRoutes, Endpoints, true)
+
+	return document
+}
+
+// OpenAPI handles the API endpoint GET /openapi.json. It returns the OpenAPI document of the
+// API. It needs no authentication.
+func OpenAPI(c *gin.Context) APIErrors {
+	c.JSON(http.StatusOK, OpenAPIDocument())
+	return nil
+}
+
+// SwaggerUI handles the API endpoint GET /docs. It returns the Swagger UI of the OpenAPI
+// document, if enabled with `--swagger-ui`. The UI is loaded from a CDN into the browser.
+func SwaggerUI(c *gin.Context) APIErrors {
+	if !viper.GetBool("swagger-ui") {
+		return NewNotFoundError("swagger ui is not enabled")
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	return nil
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Epinio API</title>
+  <meta charset="utf-8">
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,353 @@
+// Package openapi generates the OpenAPI v3 document of the API, from its routes and the
+// models of their requests and responses. Third parties generate their clients from it, and
+// validate their integrations against it.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/routes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Version is the version of the OpenAPI specification the documents follow.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document, restricted to the parts used by the API.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is the base URL of the API.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// SecurityRequirement maps the names of security schemes to their scopes.
+type SecurityRequirement map[string][]string
+
+// PathItem maps the lower-case methods of a path to their operations.
+type PathItem map[string]*Operation
+
+// Operation is a request to a route.
+type Operation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]Response    `json:"responses"`
+	Security    *[]SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter is a path, query, or header parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body of the requests of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the schema of a value. The empty schema matches all values.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the schemas of the models, and the security schemes.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way to authenticate the requests.
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Endpoint describes a route beyond its method and path. Nil models have no body. Requests
+// with a body of another content type, e.g. uploads, have a model of type Binary.
+type Endpoint struct {
+	Summary  string
+	Request  interface{}
+	Response interface{}
+	// Status is the status of successful responses, the default is 200.
+	Status int
+	// Query names the query parameters.
+	Query []string
+	// ETag marks the responses tagged for conditional requests, with `If-None-Match`.
+	ETag bool
+}
+
+// Binary is the model of raw request, and response, bodies.
+type Binary struct{}
+
+// New returns a document without paths. Requests are authenticated with user and password,
+// or with a bearer token.
+func New(title, version, server string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Servers: []Server{{URL: server}},
+		Security: []SecurityRequirement{
+			{"basicAuth": []string{}},
+			{"bearerAuth": []string{}},
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				"basicAuth":  {Type: "http", Scheme: "basic"},
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+}
+
+var paramRegex = regexp.MustCompile(`:(\w+)`)
+
+// Add adds the named route to the document, as described by the endpoint. Public routes need
+// no authentication.
+func (d *Document) Add(name string, route routes.Route, endpoint Endpoint, public bool) {
+	path := paramRegex.ReplaceAllString(route.Path, "{$1}")
+
+	operation := &Operation{
+		OperationID: name,
+		Summary:     endpoint.Summary,
+		Tags:        []string{tag(route.Path)},
+		Responses:   map[string]Response{},
+	}
+	if public {
+		operation.Security = &[]SecurityRequirement{}
+	}
+
+	for _, match := range paramRegex.FindAllStringSubmatch(route.Path, -1) {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	for _, query := range endpoint.Query {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:   query,
+			In:     "query",
+			Schema: &Schema{Type: "string"},
+		})
+	}
+
+	if endpoint.ETag {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:   "If-None-Match",
+			In:     "header",
+			Schema: &Schema{Type: "string"},
+		})
+	}
+
+	if endpoint.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  d.content(endpoint.Request),
+		}
+	}
+
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := Response{Description: http.StatusText(status)}
+	if endpoint.Response != nil {
+		response.Content = d.content(endpoint.Response)
+	}
+	operation.Responses[strconv.Itoa(status)] = response
+	if endpoint.ETag {
+		operation.Responses[strconv.Itoa(http.StatusNotModified)] = Response{Description: http.StatusText(http.StatusNotModified)}
+	}
+	operation.Responses["default"] = Response{
+		Description: "Error",
+		Content:     d.content(errorResponse{}),
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = PathItem{}
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(route.Method)] = operation
+}
+
+// AddAll adds the named routes described by the endpoints, in the order of their names.
+// Routes without endpoint are added without bodies.
+func (d *Document) AddAll(named routes.NamedRoutes, endpoints map[string]Endpoint, public bool) {
+	names := []string{}
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d.Add(name, named[name], endpoints[name], public)
+	}
+}
+
+// errorResponse is the body of the error responses, see errors.ErrorResponse.
+type errorResponse struct {
+	Errors []struct {
+		Status  int    `json:"status"`
+		Title   string `json:"title"`
+		Details string `json:"details"`
+	} `json:"errors"`
+}
+
+func (d *Document) content(model interface{}) map[string]MediaType {
+	if _, ok := model.(Binary); ok {
+		return map[string]MediaType{
+			"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+		}
+	}
+	return map[string]MediaType{
+		"application/json": {Schema: d.schema(reflect.TypeOf(model))},
+	}
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	metav1Time = reflect.TypeOf(metav1.Time{})
+)
+
+// schema returns the schema of the type. Named structs are added to the components, and
+// referenced.
+func (d *Document) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType, metav1Time:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// The placeholder stops the recursion of self-referencing models.
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	// Interfaces, and the like, can be anything.
+	return &Schema{}
+}
+
+// structSchema returns the schema of the JSON object of the struct. The fields of embedded
+// structs are inlined, as by encoding/json.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, value := range d.structSchema(embedded).Properties {
+					schema.Properties[key] = value
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = d.schema(field.Type)
+	}
+
+	return schema
+}
+
+// tag returns the tag grouping the operations of the path, its first static segment, or
+// the one after the namespace.
+func tag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		return segments[2]
+	}
+	return segments[0]
+}
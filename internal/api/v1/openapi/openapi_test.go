@@ -0,0 +1,97 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/epinio/epinio/helpers/routes"
+	"github.com/epinio/epinio/internal/api/v1/openapi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type meta struct {
+	Name string `json:"name"`
+}
+
+type thing struct {
+	meta
+	Labels   map[string]string `json:"labels,omitempty"`
+	Parts    []part            `json:"parts"`
+	Created  time.Time         `json:"created"`
+	Data     []byte            `json:"data"`
+	Ignored  string            `json:"-"`
+	Untagged int64
+	Next     *thing `json:"next,omitempty"`
+}
+
+type part struct {
+	Size int `json:"size"`
+}
+
+var _ = Describe("OpenAPI", func() {
+	var document *openapi.Document
+
+	BeforeEach(func() {
+		document = openapi.New("Test", "v1", "/api/v1")
+	})
+
+	It("describes the path parameters, and bodies, of routes", func() {
+		document.Add("ThingSet", routes.NewRoute("PUT", "/namespaces/:namespace/things/:thing", nil),
+			openapi.Endpoint{Request: thing{}, Response: part{}, Query: []string{"force"}}, false)
+
+		operation := document.Paths["/namespaces/{namespace}/things/{thing}"]["put"]
+		Expect(operation).ToNot(BeNil())
+		Expect(operation.OperationID).To(Equal("ThingSet"))
+		Expect(operation.Tags).To(Equal([]string{"things"}))
+		Expect(operation.Security).To(BeNil())
+
+		Expect(operation.Parameters).To(HaveLen(3))
+		Expect(operation.Parameters[0].Name).To(Equal("namespace"))
+		Expect(operation.Parameters[0].In).To(Equal("path"))
+		Expect(operation.Parameters[2].Name).To(Equal("force"))
+		Expect(operation.Parameters[2].In).To(Equal("query"))
+
+		Expect(operation.RequestBody.Content["application/json"].Schema.Ref).To(Equal("#/components/schemas/thing"))
+		Expect(operation.Responses["200"].Content["application/json"].Schema.Ref).To(Equal("#/components/schemas/part"))
+		Expect(operation.Responses).To(HaveKey("default"))
+	})
+
+	It("describes the conditional requests of tagged responses", func() {
+		document.Add("Thing", routes.NewRoute("GET", "/things/:thing", nil),
+			openapi.Endpoint{Response: thing{}, ETag: true}, false)
+
+		operation := document.Paths["/things/{thing}"]["get"]
+		Expect(operation.Parameters).To(HaveLen(2))
+		Expect(operation.Parameters[1].Name).To(Equal("If-None-Match"))
+		Expect(operation.Parameters[1].In).To(Equal("header"))
+		Expect(operation.Responses).To(HaveKey("304"))
+	})
+
+	It("generates the schemas of the models", func() {
+		document.Add("Thing", routes.NewRoute("GET", "/things/:thing", nil),
+			openapi.Endpoint{Response: thing{}}, false)
+
+		schema := document.Components.Schemas["thing"]
+		Expect(schema.Type).To(Equal("object"))
+		Expect(schema.Properties).To(HaveKey("name"))
+		Expect(schema.Properties).ToNot(HaveKey("Ignored"))
+		Expect(schema.Properties["labels"].AdditionalProperties.Type).To(Equal("string"))
+		Expect(schema.Properties["parts"].Items.Ref).To(Equal("#/components/schemas/part"))
+		Expect(schema.Properties["created"].Format).To(Equal("date-time"))
+		Expect(schema.Properties["data"].Format).To(Equal("byte"))
+		Expect(schema.Properties["Untagged"].Format).To(Equal("int64"))
+		Expect(schema.Properties["next"].Ref).To(Equal("#/components/schemas/thing"))
+		Expect(document.Components.Schemas["part"].Properties["size"].Type).To(Equal("integer"))
+	})
+
+	It("marks public routes as needing no authentication", func() {
+		document.AddAll(routes.NamedRoutes{
+			"Status": routes.NewRoute("GET", "/status", nil),
+		}, map[string]openapi.Endpoint{}, true)
+
+		out, err := json.Marshal(document.Paths["/status"]["get"])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring(`"security":[]`))
+	})
+})
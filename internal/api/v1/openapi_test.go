@@ -0,0 +1,34 @@
+package v1_test
+
+import (
+	"encoding/json"
+
+	v1 "github.com/epinio/epinio/internal/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAPI document", func() {
+	It("describes all routes", func() {
+		for name := range v1.Routes {
+			Expect(v1.Endpoints).To(HaveKey(name))
+		}
+		for name := range v1.PublicRoutes {
+			Expect(v1.Endpoints).To(HaveKey(name))
+		}
+	})
+
+	It("has an operation for each route", func() {
+		document := v1.OpenAPIDocument()
+		Expect(document.OpenAPI).To(Equal("3.0.3"))
+
+		count := 0
+		for _, item := range document.Paths {
+			count += len(item)
+		}
+		Expect(count).To(Equal(len(v1.Routes) + len(v1.PublicRoutes)))
+
+		_, err := json.Marshal(document)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/operations"
+	"github.com/gin-gonic/gin"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// OperationShow handles the API endpoint GET /operations/:operation. It returns the
+// long-running operation, e.g. a staging, with its phase, progress, and error, if any. The
+// operations outside of the namespaces of the user are not found.
+func OperationShow(c *gin.Context) APIErrors {
+	ctx := c.Request.Context()
+	id := c.Param("operation")
+
+	operation, ok := operations.Default.Get(id)
+	if !ok || !operations.Visible(requestctx.User(ctx), operation) {
+		return NewNotFoundError("operation not found", id)
+	}
+
+	response.OKReturn(c, operation)
+	return nil
+}
@@ -2,15 +2,27 @@
 package response
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/listing"
 	"github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	v2models "github.com/epinio/epinio/pkg/api/core/v2/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// VersionKey is the key of the gin context holding the version of the API of the request, if
+// later than v1. Handlers serve several versions, and respond in the shape of the version.
+const VersionKey = "api-version"
+
 // OK reports a generic success
 func OK(c *gin.Context) {
 	requestctx.Logger(c.Request.Context()).Info("OK",
@@ -41,6 +53,90 @@ func OKReturn(c *gin.Context, response interface{}) {
 	c.JSON(http.StatusOK, response)
 }
 
+// OKReturnETag reports a success with some data, tagged with an ETag of the data. Clients
+// sending the tag of the data they have, in `If-None-Match`, get a 304 without body when it
+// is unchanged.
+func OKReturnETag(c *gin.Context, response interface{}) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		Error(c, errors.InternalError(err))
+		return
+	}
+
+	etag := ETag(body)
+	c.Header("ETag", etag)
+
+	if NoneMatch(c.GetHeader("If-None-Match"), etag) {
+		requestctx.Logger(c.Request.Context()).Info("NOT MODIFIED",
+			"origin", c.Request.URL.String(),
+			"etag", etag,
+		)
+
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	requestctx.Logger(c.Request.Context()).Info("OK",
+		"origin", c.Request.URL.String(),
+		"returning", response,
+	)
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// OKReturnList reports a success with a page of a list. Requests of v1 get the response, with
+// the total count of matching items in a header. Requests of v2 get the items of the page in a
+// v2 Page, with the total count.
+func OKReturnList(c *gin.Context, response interface{}, items interface{}, total int, options listing.Options) {
+	c.Header(listing.TotalCountHeader, strconv.Itoa(total))
+
+	if c.GetString(VersionKey) == "v2" {
+		if value := reflect.ValueOf(items); value.Kind() == reflect.Slice && value.IsNil() {
+			items = []interface{}{}
+		}
+		OKReturnETag(c, v2models.Page{
+			Items:  items,
+			Total:  total,
+			Offset: options.Offset,
+			Limit:  options.Limit,
+		})
+		return
+	}
+
+	OKReturnETag(c, response)
+}
+
+// ETag returns the entity tag of the body of a response. The tag is a hash of the body, it
+// changes with everything reported, including the status of resources, which does not change
+// their resource version.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NoneMatch returns true if the `If-None-Match` header value matches the tag, i.e. the client
+// has the current data.
+func NoneMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptedReturn reports the start of work going on in the background, e.g. an operation,
+// with the response.
+func AcceptedReturn(c *gin.Context, response interface{}) {
+	requestctx.Logger(c.Request.Context()).Info("ACCEPTED",
+		"origin", c.Request.URL.String(),
+		"returning", response,
+	)
+
+	c.JSON(http.StatusAccepted, response)
+}
+
 // Created reports successful creation of a resource.
 func Created(c *gin.Context) {
 	requestctx.Logger(c.Request.Context()).Info("CREATED",
@@ -9,14 +9,21 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/epinio/epinio/helpers/routes"
+	"github.com/epinio/epinio/internal/api/v1/apitoken"
 	"github.com/epinio/epinio/internal/api/v1/appchart"
 	"github.com/epinio/epinio/internal/api/v1/application"
+	"github.com/epinio/epinio/internal/api/v1/backup"
 	"github.com/epinio/epinio/internal/api/v1/configuration"
 	"github.com/epinio/epinio/internal/api/v1/configurationbinding"
 	"github.com/epinio/epinio/internal/api/v1/env"
 	"github.com/epinio/epinio/internal/api/v1/namespace"
+	"github.com/epinio/epinio/internal/api/v1/notification"
 	"github.com/epinio/epinio/internal/api/v1/response"
 	"github.com/epinio/epinio/internal/api/v1/service"
+	"github.com/epinio/epinio/internal/api/v1/state"
+	"github.com/epinio/epinio/internal/api/v1/team"
+	"github.com/epinio/epinio/internal/api/v1/user"
+	"github.com/epinio/epinio/internal/api/v1/webhook"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/pkg/api/core/v1/errors"
 )
@@ -72,26 +79,131 @@ func put(path string, h gin.HandlerFunc) routes.Route {
 // AdminRoutes is the list of restricted routes, only accessible by admins
 var AdminRoutes map[string]struct{} = map[string]struct{}{}
 
+// NamespaceAdminRoutes is the list of namespaced routes only accessible by the admins of the
+// namespace, as `METHOD PATH` with the path of the route, not of the request.
+var NamespaceAdminRoutes map[string]struct{} = map[string]struct{}{}
+
+// InteractiveRoutes is the list of namespaced routes which get into applications, and so are
+// not accessible by the readers of the namespace, even though they are GET requests. The
+// format is the one of NamespaceAdminRoutes.
+var InteractiveRoutes map[string]struct{} = map[string]struct{}{}
+
+// IdempotentRoutes is the list of routes creating, or deploying, things, whose requests are run
+// at most once per idempotency key, see the idempotency package. The format is the one of
+// NamespaceAdminRoutes.
+var IdempotentRoutes map[string]struct{} = map[string]struct{}{}
+
+func init() {
+	for _, name := range []string{"AppCreate", "AppApply", "AppAdopt", "AppImportGit", "AppStage", "AppDeploy", "NamespaceCreate", "ConfigurationCreate", "ServiceCreate"} {
+		r := Routes[name]
+		IdempotentRoutes[r.Method+" "+Root+r.Path] = struct{}{}
+	}
+	for _, name := range []string{"NamespaceDelete", "NamespaceUpdate", "NamespaceExport", "AppAdopt", "Notifications", "NotificationShow", "NotificationSet", "NotificationDelete"} {
+		r := Routes[name]
+		NamespaceAdminRoutes[r.Method+" "+Root+r.Path] = struct{}{}
+	}
+	for _, name := range []string{"AppExec", "AppPortForward"} {
+		r := WsRoutes[name]
+		InteractiveRoutes[r.Method+" "+WsRoot+r.Path] = struct{}{}
+	}
+	for _, name := range []string{"AuditEntries", "UserRoleSet", "UserPasswordReset", "UserFederationSet", "Teams", "TeamShow", "TeamSet", "TeamDelete", "TeamRoleSet", "Webhooks", "WebhookShow", "WebhookSet", "WebhookDelete", "Backups", "BackupCreate", "BackupRestore", "StateExport", "StateUserImport", "NamespacePodSecuritySet", "NamespaceQuotaSet", "NamespaceUploadLimitSet"} {
+		AdminRoutes[Root+Routes[name].Path] = struct{}{}
+	}
+}
+
 var Routes = routes.NamedRoutes{
 	"Info":      get("/info", errorHandler(Info)),
 	"AuthToken": get("/authtoken", errorHandler(AuthToken)),
 
+	// Sessions of the CLI
+	"SessionCreate": post("/auth/session", errorHandler(SessionCreate)),
+	"SessionDelete": delete("/auth/session", errorHandler(SessionDelete)),
+
+	// Passwords of the users
+	"PasswordChange": put("/auth/password", errorHandler(user.Controller{}.PasswordChange)),
+
+	// Client certificates for mutual TLS
+	"ClientCertificateCreate": post("/auth/client-certificate", errorHandler(ClientCertificateCreate)),
+
+	// Audit log
+	"AuditEntries": get("/audit", errorHandler(AuditEntries)),
+
+	// API tokens
+	"APITokens":      get("/tokens", errorHandler(apitoken.Controller{}.Index)),
+	"APITokenShow":   get("/tokens/:token", errorHandler(apitoken.Controller{}.Show)),
+	"APITokenCreate": post("/tokens", errorHandler(apitoken.Controller{}.Create)),
+	"APITokenDelete": delete("/tokens/:token", errorHandler(apitoken.Controller{}.Delete)),
+
+	// Users
+	"UserRoleSet":       put("/users/:user/roles", errorHandler(user.Controller{}.RoleSet)),
+	"UserPasswordReset": put("/users/:user/password", errorHandler(user.Controller{}.PasswordReset)),
+	"UserFederationSet": put("/users/:user/federation", errorHandler(user.Controller{}.FederationSet)),
+
+	// Teams
+	"Teams":       get("/teams", errorHandler(team.Controller{}.Index)),
+	"TeamShow":    get("/teams/:team", errorHandler(team.Controller{}.Show)),
+	"TeamSet":     put("/teams/:team", errorHandler(team.Controller{}.Set)),
+	"TeamDelete":  delete("/teams/:team", errorHandler(team.Controller{}.Delete)),
+	"TeamRoleSet": put("/teams/:team/roles", errorHandler(team.Controller{}.RoleSet)),
+
+	// Webhooks receiving the platform events
+	"Webhooks":      get("/webhooks", errorHandler(webhook.Controller{}.Index)),
+	"WebhookShow":   get("/webhooks/:webhook", errorHandler(webhook.Controller{}.Show)),
+	"WebhookSet":    put("/webhooks/:webhook", errorHandler(webhook.Controller{}.Set)),
+	"WebhookDelete": delete("/webhooks/:webhook", errorHandler(webhook.Controller{}.Delete)),
+
+	// Backups of the platform state, see backup/*.go
+	"Backups":       get("/backups", errorHandler(backup.Controller{}.Index)),
+	"BackupCreate":  post("/backups", errorHandler(backup.Controller{}.Create)),
+	"BackupRestore": post("/backups/:backup/restore", errorHandler(backup.Controller{}.Restore)),
+
+	// Export and import of the platform state, for migrations, see state/*.go
+	"StateExport":     get("/state", errorHandler(state.Controller{}.Export)),
+	"StateUserImport": post("/state/users", errorHandler(state.Controller{}.UserImport)),
+
+	// Long-running operations, see operations.go
+	"OperationShow": get("/operations/:operation", errorHandler(OperationShow)),
+
 	// app controller files see application/*.go
 
-	"AllApps":         get("/applications", errorHandler(application.Controller{}.FullIndex)),
-	"Apps":            get("/namespaces/:namespace/applications", errorHandler(application.Controller{}.Index)),
-	"AppCreate":       post("/namespaces/:namespace/applications", errorHandler(application.Controller{}.Create)),
-	"AppShow":         get("/namespaces/:namespace/applications/:app", errorHandler(application.Controller{}.Show)),
-	"StagingComplete": get("/namespaces/:namespace/staging/:stage_id/complete", errorHandler(application.Controller{}.Staged)), // See stage.go
-	"AppDelete":       delete("/namespaces/:namespace/applications/:app", errorHandler(application.Controller{}.Delete)),
-	"AppUpload":       post("/namespaces/:namespace/applications/:app/store", errorHandler(application.Controller{}.Upload)), // See upload.go
-	"AppImportGit":    post("/namespaces/:namespace/applications/:app/import-git", errorHandler(application.Controller{}.ImportGit)),
-	"AppStage":        post("/namespaces/:namespace/applications/:app/stage", errorHandler(application.Controller{}.Stage)), // See stage.go
-	"AppDeploy":       post("/namespaces/:namespace/applications/:app/deploy", errorHandler(application.Controller{}.Deploy)),
-	"AppRestart":      post("/namespaces/:namespace/applications/:app/restart", errorHandler(application.Controller{}.Restart)),
-	"AppUpdate":       patch("/namespaces/:namespace/applications/:app", errorHandler(application.Controller{}.Update)),
-	"AppRunning":      get("/namespaces/:namespace/applications/:app/running", errorHandler(application.Controller{}.Running)),
-	"AppPart":         get("/namespaces/:namespace/applications/:app/part/:part", errorHandler(application.Controller{}.GetPart)),
+	"AllApps":          get("/applications", errorHandler(application.Controller{}.FullIndex)),
+	"Apps":             get("/namespaces/:namespace/applications", errorHandler(application.Controller{}.Index)),
+	"AppCreate":        post("/namespaces/:namespace/applications", errorHandler(application.Controller{}.Create)),
+	"AppApply":         post("/namespaces/:namespace/applications/apply", errorHandler(application.Controller{}.Apply)),
+	"AppAdopt":         post("/namespaces/:namespace/applications/adopt", errorHandler(application.Controller{}.Adopt)),
+	"AppShow":          get("/namespaces/:namespace/applications/:app", errorHandler(application.Controller{}.Show)),
+	"StagingComplete":  get("/namespaces/:namespace/staging/:stage_id/complete", errorHandler(application.Controller{}.Staged)),    // See stage.go
+	"StagingQueue":     get("/namespaces/:namespace/staging/:stage_id/queue", errorHandler(application.Controller{}.StagingQueue)), // See stage.go
+	"AppDelete":        delete("/namespaces/:namespace/applications/:app", errorHandler(application.Controller{}.Delete)),
+	"AppBatchDelete":   delete("/namespaces/:namespace/applications", errorHandler(application.Controller{}.BatchDelete)),
+	"AppUpload":        post("/namespaces/:namespace/applications/:app/store", errorHandler(application.Controller{}.Upload)),                       // See upload.go
+	"AppUploadStart":   post("/namespaces/:namespace/applications/:app/store/parts", errorHandler(application.Controller{}.UploadStart)),            // See upload.go
+	"AppUploadPart":    put("/namespaces/:namespace/applications/:app/store/parts/:blobuid", errorHandler(application.Controller{}.UploadPart)),     // See upload.go
+	"AppUploadFinish":  post("/namespaces/:namespace/applications/:app/store/parts/:blobuid", errorHandler(application.Controller{}.UploadFinish)),  // See upload.go
+	"AppUploadAbort":   delete("/namespaces/:namespace/applications/:app/store/parts/:blobuid", errorHandler(application.Controller{}.UploadAbort)), // See upload.go
+	"AppChunksMissing": post("/namespaces/:namespace/applications/:app/store/chunks", errorHandler(application.Controller{}.ChunksMissing)),         // See upload.go
+	"AppChunkUpload":   put("/namespaces/:namespace/applications/:app/store/chunks/:hash", errorHandler(application.Controller{}.ChunkUpload)),      // See upload.go
+	"AppUploadDelta":   post("/namespaces/:namespace/applications/:app/store/delta", errorHandler(application.Controller{}.UploadDelta)),            // See upload.go
+	"AppImportGit":     post("/namespaces/:namespace/applications/:app/import-git", errorHandler(application.Controller{}.ImportGit)),
+	"AppStage":         post("/namespaces/:namespace/applications/:app/stage", errorHandler(application.Controller{}.Stage)), // See stage.go
+	"AppDeploy":        post("/namespaces/:namespace/applications/:app/deploy", errorHandler(application.Controller{}.Deploy)),
+	"AppCanaryAbort":   delete("/namespaces/:namespace/applications/:app/canary", errorHandler(application.Controller{}.CanaryAbort)),
+	"AppCanaryPromote": post("/namespaces/:namespace/applications/:app/canary/promote", errorHandler(application.Controller{}.CanaryPromote)),
+	"AppRestart":       post("/namespaces/:namespace/applications/:app/restart", errorHandler(application.Controller{}.Restart)),
+	"AppBatchRestart":  post("/namespaces/:namespace/applications/restart", errorHandler(application.Controller{}.BatchRestart)),
+	"AppRename":        post("/namespaces/:namespace/applications/:app/rename", errorHandler(application.Controller{}.Rename)),
+	"AppSleep":         post("/namespaces/:namespace/applications/:app/sleep", errorHandler(application.Controller{}.Sleep)),
+	"AppWake":          post("/namespaces/:namespace/applications/:app/wake", errorHandler(application.Controller{}.Wake)),
+	"AppRevisions":     get("/namespaces/:namespace/applications/:app/revisions", errorHandler(application.Controller{}.Revisions)),
+	"AppRollback":      post("/namespaces/:namespace/applications/:app/rollback", errorHandler(application.Controller{}.Rollback)),
+	"AppTasks":         get("/namespaces/:namespace/applications/:app/tasks", errorHandler(application.Controller{}.Tasks)),
+	"AppTaskRun":       post("/namespaces/:namespace/applications/:app/tasks/:task/run", errorHandler(application.Controller{}.TaskRun)),
+	"AppDomains":       get("/namespaces/:namespace/applications/:app/domains", errorHandler(application.Controller{}.Domains)),
+	"AppDomainAdd":     post("/namespaces/:namespace/applications/:app/domains", errorHandler(application.Controller{}.DomainAdd)),
+	"AppDomainRemove":  delete("/namespaces/:namespace/applications/:app/domains/:domain", errorHandler(application.Controller{}.DomainRemove)),
+	"AppUpdate":        patch("/namespaces/:namespace/applications/:app", errorHandler(application.Controller{}.Update)),
+	"AppRunning":       get("/namespaces/:namespace/applications/:app/running", errorHandler(application.Controller{}.Running)),
+	"AppPart":          get("/namespaces/:namespace/applications/:app/part/:part", errorHandler(application.Controller{}.GetPart)),
 
 	// See env.go
 	"EnvList": get("/namespaces/:namespace/applications/:app/environment", errorHandler(env.Controller{}.Index)),
@@ -115,6 +227,18 @@ var Routes = routes.NamedRoutes{
 	"NamespaceCreate": post("/namespaces", errorHandler(namespace.Controller{}.Create)),
 	"NamespaceDelete": delete("/namespaces/:namespace", errorHandler(namespace.Controller{}.Delete)),
 	"NamespaceShow":   get("/namespaces/:namespace", errorHandler(namespace.Controller{}.Show)),
+	"NamespaceUpdate": patch("/namespaces/:namespace", errorHandler(namespace.Controller{}.Update)),
+	"NamespaceExport": get("/namespaces/:namespace/export", errorHandler(namespace.Controller{}.Export)),
+
+	"NamespacePodSecuritySet": put("/namespaces/:namespace/pod-security", errorHandler(namespace.Controller{}.PodSecuritySet)),
+	"NamespaceQuotaSet":       put("/namespaces/:namespace/quota", errorHandler(namespace.Controller{}.QuotaSet)),
+	"NamespaceUploadLimitSet": put("/namespaces/:namespace/upload-limit", errorHandler(namespace.Controller{}.UploadLimitSet)),
+
+	// Notification targets of a namespace, see notification/*.go
+	"Notifications":      get("/namespaces/:namespace/notifications", errorHandler(notification.Controller{}.Index)),
+	"NotificationShow":   get("/namespaces/:namespace/notifications/:notification", errorHandler(notification.Controller{}.Show)),
+	"NotificationSet":    put("/namespaces/:namespace/notifications/:notification", errorHandler(notification.Controller{}.Set)),
+	"NotificationDelete": delete("/namespaces/:namespace/notifications/:notification", errorHandler(notification.Controller{}.Delete)),
 
 	// Note, the second registration catches calls with an empty pattern!
 	"NamespacesMatch":  get("/namespacematches/:pattern", errorHandler(namespace.Controller{}.Match)),
@@ -131,6 +255,8 @@ var Routes = routes.NamedRoutes{
 	"ConfigurationUpdate":  patch("/namespaces/:namespace/configurations/:configuration", errorHandler(configuration.Controller{}.Update)),
 	"ConfigurationReplace": put("/namespaces/:namespace/configurations/:configuration", errorHandler(configuration.Controller{}.Replace)),
 
+	"ConfigurationBatchDelete": delete("/namespaces/:namespace/configurations", errorHandler(configuration.Controller{}.BatchDelete)),
+
 	// Services
 	"ServiceCatalog":     get("/services", errorHandler(service.Controller{}.Catalog)),
 	"ServiceCatalogShow": get("/services/:catalogservice", errorHandler(service.Controller{}.CatalogShow)),
@@ -138,6 +264,7 @@ var Routes = routes.NamedRoutes{
 	"ServiceList":        get("/namespaces/:namespace/services", errorHandler(service.Controller{}.List)),
 	"ServiceShow":        get("/namespaces/:namespace/services/:service", errorHandler(service.Controller{}.Show)),
 	"ServiceDelete":      delete("/namespaces/:namespace/services/:service", errorHandler(service.Controller{}.Delete)),
+	"ServiceBatchDelete": delete("/namespaces/:namespace/services", errorHandler(service.Controller{}.BatchDelete)),
 
 	// Bind a service to/from applications
 	"ServiceBind": post(
@@ -156,11 +283,28 @@ var Routes = routes.NamedRoutes{
 	"ChartShow":   get("/appcharts/:name", errorHandler(appchart.Controller{}.Show)),
 }
 
+// PublicRoutes are the API endpoints which need no authentication
+var PublicRoutes = routes.NamedRoutes{
+	"OIDCConfig":     get("/auth/oidc", errorHandler(OIDCConfig)),
+	"SessionRefresh": post("/auth/session/refresh", errorHandler(SessionRefresh)),
+}
+
+// DocRoutes are the API endpoints documenting the API, see openapi.go. They need no
+// authentication.
+var DocRoutes = routes.NamedRoutes{
+	"OpenAPI":   get("/openapi.json", errorHandler(OpenAPI)),
+	"SwaggerUI": get("/docs", errorHandler(SwaggerUI)),
+}
+
 var WsRoutes = routes.NamedRoutes{
 	"AppExec":        get("/namespaces/:namespace/applications/:app/exec", errorHandler(application.Controller{}.Exec)),
 	"AppPortForward": get("/namespaces/:namespace/applications/:app/portforward", errorHandler(application.Controller{}.PortForward)),
 	"AppLogs":        get("/namespaces/:namespace/applications/:app/logs", application.Controller{}.Logs),
+	"AppEvents":      get("/namespaces/:namespace/applications/:app/events", errorHandler(application.Controller{}.Events)),
 	"StagingLogs":    get("/namespaces/:namespace/staging/:stage_id/logs", application.Controller{}.Logs),
+
+	// Platform events, see events.go
+	"Events": get("/events", errorHandler(Events)),
 }
 
 // Lemon extends the specified router with the methods and urls
@@ -171,6 +315,17 @@ func Lemon(router *gin.RouterGroup) {
 	}
 }
 
+// Salt extends the specified router with the methods and urls
+// handling the public API endpoints
+func Salt(router *gin.RouterGroup) {
+	for _, r := range PublicRoutes {
+		router.Handle(r.Method, r.Path, r.Handler)
+	}
+	for _, r := range DocRoutes {
+		router.Handle(r.Method, r.Path, r.Handler)
+	}
+}
+
 // Spice extends the specified router with the methods and urls
 // handling the websocket API endpoints
 func Spice(router *gin.RouterGroup) {
@@ -9,6 +9,7 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/events"
 	"github.com/gin-gonic/gin"
 
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
@@ -70,13 +71,16 @@ func (ctr Controller) Bind(c *gin.Context) apierror.APIErrors {
 	logger.Info("binding service configuration")
 
 	_, errors := configurationbinding.CreateConfigurationBinding(
-		ctx, cluster, namespace, *app, configurationNames,
+		ctx, cluster, namespace, *app, configurationNames, nil,
 	)
 
 	if errors != nil {
 		return apierror.NewMultiError(errors.Errors())
 	}
 
+	events.Publish(ctx, models.EventServiceBound, namespace, serviceName,
+		map[string]string{"app": app.AppRef().Name})
+
 	response.OK(c)
 	return nil
 }
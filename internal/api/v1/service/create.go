@@ -1,18 +1,31 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/duration"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/internal/operations"
 	"github.com/epinio/epinio/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// serviceProvisionPollInterval is the time between the checks of the status of a service,
+// by the operation tracking its provisioning.
+const serviceProvisionPollInterval = 2 * time.Second
+
+// Create handles the API endpoint POST /namespaces/:namespace/services
+// It creates the service, and returns the id of the operation tracking its provisioning.
 func (ctr Controller) Create(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 	namespace := c.Param("namespace")
@@ -47,6 +60,47 @@ func (ctr Controller) Create(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
-	response.OK(c)
+	operation, err := operations.Default.Start(ctx, models.OperationServiceCreate, namespace, createRequest.Name,
+		trackProvisioning(kubeServiceClient, namespace, createRequest.Name, createRequest.CatalogService))
+	if err != nil {
+		return apierror.InternalError(err, "tracking the provisioning")
+	}
+
+	events.Publish(ctx, models.EventServiceCreated, namespace, createRequest.Name,
+		map[string]string{"catalog_service": createRequest.CatalogService})
+
+	response.OKReturn(c, models.ServiceCreateResponse{
+		Response:    models.ResponseOK,
+		OperationID: operation.ID,
+	})
 	return nil
 }
+
+// trackProvisioning returns the work of the operation tracking the provisioning of the
+// service, until its helm release is deployed, for at most `duration.ToDeployment()`. The
+// result is published as event.
+func trackProvisioning(kubeServiceClient *services.ServiceClient, namespace, name, catalogService string) operations.Work {
+	return func(ctx context.Context, tracker *operations.Tracker) apierror.APIErrors {
+		tracker.Progress(models.OperationRunning, "installing the helm chart")
+
+		err := wait.PollImmediate(serviceProvisionPollInterval, duration.ToDeployment(), func() (bool, error) {
+			service, err := kubeServiceClient.Get(ctx, namespace, name)
+			if err != nil {
+				return false, err
+			}
+			if service == nil {
+				return false, errors.New("service was deleted")
+			}
+			return service.Status == models.ServiceStatusDeployed, nil
+		})
+		if err != nil {
+			events.Publish(ctx, models.EventServiceFailed, namespace, name,
+				map[string]string{"catalog_service": catalogService, "error": err.Error()})
+			return apierror.InternalError(err, "provisioning the service")
+		}
+
+		events.Publish(ctx, models.EventServiceProvisioned, namespace, name,
+			map[string]string{"catalog_service": catalogService})
+		return nil
+	}
+}
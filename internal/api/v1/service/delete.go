@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -10,10 +11,12 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/events"
 	"github.com/epinio/epinio/internal/services"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
@@ -42,11 +45,85 @@ func (ctr Controller) Delete(c *gin.Context) apierror.APIErrors {
 		return err
 	}
 
-	apiErr := ValidateService(ctx, cluster, logger, namespace, serviceName)
+	boundAppNames, apiErr := deleteService(ctx, cluster, logger, namespace, serviceName, deleteRequest.Unbind)
 	if apiErr != nil {
 		return apiErr
 	}
 
+	response.OKReturn(c, models.ServiceDeleteResponse{
+		BoundApps: boundAppNames,
+	})
+	return nil
+}
+
+// BatchDelete handles the API end point /namespaces/:namespace/services (DELETE)
+// It deletes the named services, or all services of the namespace, one after the other, and
+// reports the outcome per service.
+func (ctr Controller) BatchDelete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	logger := requestctx.Logger(ctx).WithName("BatchDelete")
+	namespace := c.Param("namespace")
+
+	var deleteRequest models.BatchDeleteRequest
+	err := c.BindJSON(&deleteRequest)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+	if len(deleteRequest.Names) == 0 && !deleteRequest.All {
+		return apierror.NewBadRequest("no services to delete")
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	if err := ctr.validateNamespace(ctx, cluster, namespace); err != nil {
+		return err
+	}
+
+	names := deleteRequest.Names
+	if deleteRequest.All {
+		kubeServiceClient, err := services.NewKubernetesServiceClient(cluster)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		serviceList, err := kubeServiceClient.List(ctx, namespace)
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		names = []string{}
+		for _, service := range serviceList {
+			names = append(names, service.Meta.Name)
+		}
+	}
+
+	resp := models.BatchDeleteResponse{Results: []models.BatchDeleteResult{}}
+	for _, name := range names {
+		result := models.BatchDeleteResult{Name: name}
+
+		boundAppNames, apiErr := deleteService(ctx, cluster, logger, namespace, name, deleteRequest.Unbind)
+		result.BoundApps = boundAppNames
+		if apiErr != nil {
+			result.Error = apierror.Message(apiErr)
+		}
+
+		resp.Results = append(resp.Results, result)
+	}
+
+	response.OKReturn(c, resp)
+	return nil
+}
+
+// deleteService deletes the named service, and returns the names of the applications it was
+// bound to. Bound applications are an error, unless unbinding was asked for. Their names are
+// returned with that error.
+func deleteService(ctx context.Context, cluster *kubernetes.Cluster, logger logr.Logger, namespace, serviceName string, unbind bool) ([]string, apierror.APIErrors) {
+	apiErr := ValidateService(ctx, cluster, logger, namespace, serviceName)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
 	// A service has one or more associated secrets containing its attributes.
 	// Binding turned these secrets into configurations and bound them to the
 	// application.  Unbinding simply unbound them.  We may think that this means that
@@ -59,7 +136,7 @@ func (ctr Controller) Delete(c *gin.Context) apierror.APIErrors {
 
 	serviceConfigurations, err := configurations.ForService(ctx, cluster, namespace, serviceName)
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
 	logger.Info(fmt.Sprintf("configurationSecrets found %+v\n", serviceConfigurations))
@@ -67,7 +144,7 @@ func (ctr Controller) Delete(c *gin.Context) apierror.APIErrors {
 	for _, secret := range serviceConfigurations {
 		bound, err := application.BoundAppsNamesFor(ctx, cluster, namespace, secret.Name)
 		if err != nil {
-			return apierror.InternalError(err)
+			return nil, apierror.InternalError(err)
 		}
 
 		boundAppNames = append(boundAppNames, bound...)
@@ -80,8 +157,8 @@ func (ctr Controller) Delete(c *gin.Context) apierror.APIErrors {
 	// Without automatic unbind such applications are reported as error.
 
 	if len(boundAppNames) > 0 {
-		if !deleteRequest.Unbind {
-			return apierror.NewBadRequest("bound applications exist", strings.Join(boundAppNames, ","))
+		if !unbind {
+			return boundAppNames, apierror.NewBadRequest("bound applications exist", strings.Join(boundAppNames, ","))
 		}
 
 		username := requestctx.User(ctx).Username
@@ -90,27 +167,26 @@ func (ctr Controller) Delete(c *gin.Context) apierror.APIErrors {
 		for _, appName := range boundAppNames {
 			apiErr := UnbindService(ctx, cluster, logger, namespace, appName, username, serviceConfigurations)
 			if apiErr != nil {
-				return apiErr
+				return nil, apiErr
 			}
 		}
 	}
 
 	kubeServiceClient, err := services.NewKubernetesServiceClient(cluster)
 	if err != nil {
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
 	err = kubeServiceClient.Delete(ctx, namespace, serviceName)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
-			return apierror.NewNotFoundError("service not found")
+			return nil, apierror.NewNotFoundError("service not found")
 		}
 
-		return apierror.InternalError(err)
+		return nil, apierror.InternalError(err)
 	}
 
-	response.OKReturn(c, models.ServiceDeleteResponse{
-		BoundApps: boundAppNames,
-	})
-	return nil
+	events.Publish(ctx, models.EventServiceDeleted, namespace, serviceName, nil)
+
+	return boundAppNames, nil
 }
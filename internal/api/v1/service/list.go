@@ -3,16 +3,25 @@ package service
 import (
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/listing"
 	"github.com/epinio/epinio/internal/services"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/gin-gonic/gin"
 )
 
+// List handles the API endpoint GET /namespaces/:namespace/services
+// It lists the services of the namespace. The list is paged, filtered, and sorted as per the
+// query parameters, see listing.FromQuery. Services have no labels to select.
 func (ctr Controller) List(c *gin.Context) apierror.APIErrors {
 	ctx := c.Request.Context()
 	namespace := c.Param("namespace")
 
+	options, err := listing.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
 	cluster, err := kubernetes.GetCluster(ctx)
 	if err != nil {
 		return apierror.InternalError(err)
@@ -32,10 +41,27 @@ func (ctr Controller) List(c *gin.Context) apierror.APIErrors {
 		return apierror.InternalError(err)
 	}
 
+	items := make([]listing.Item, 0, len(serviceList))
+	for _, service := range serviceList {
+		items = append(items, listing.Item{
+			Name:      service.Meta.Name,
+			Namespace: service.Meta.Namespace,
+			CreatedAt: service.Meta.CreatedAt.Time,
+		})
+	}
+
+	page, total, err := options.Select(items)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
 	resp := models.ServiceListResponse{
-		Services: serviceList,
+		Services: []*models.Service{},
+	}
+	for _, i := range page {
+		resp.Services = append(resp.Services, serviceList[i])
 	}
 
-	response.OKReturn(c, resp)
+	response.OKReturnList(c, resp, resp.Services, total, options)
 	return nil
 }
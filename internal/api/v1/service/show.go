@@ -41,7 +41,7 @@ func (ctr Controller) Show(c *gin.Context) apierror.APIErrors {
 		Service: srv,
 	}
 
-	response.OKReturn(c, resp)
+	response.OKReturnETag(c, resp)
 
 	return nil
 }
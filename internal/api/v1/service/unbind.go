@@ -10,6 +10,7 @@ import (
 	"github.com/epinio/epinio/internal/application"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
 	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/events"
 	"github.com/gin-gonic/gin"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
@@ -73,6 +74,9 @@ func (ctr Controller) Unbind(c *gin.Context) apierror.APIErrors {
 		return apiErr // already apierror.MultiError
 	}
 
+	events.Publish(ctx, models.EventServiceUnbound, namespace, serviceName,
+		map[string]string{"app": app.AppRef().Name})
+
 	response.OK(c)
 	return nil
 }
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	. "github.com/epinio/epinio/pkg/api/core/v1/errors"
+)
+
+// SessionCreate handles the API endpoint POST /auth/session. It starts a session of the user,
+// and returns its tokens. Only Epinio users authenticated with user and password can do this,
+// not with a token, nor users authenticated with LDAP.
+func SessionCreate(c *gin.Context) APIErrors {
+	ctx := c.Request.Context()
+	user := requestctx.User(ctx)
+
+	if strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+		return NewAPIError("sessions cannot be started with a token", "", http.StatusForbidden)
+	}
+	// The password of LDAP users is not the one of an Epinio user, they have none, or another.
+	if _, password, ok := c.Request.BasicAuth(); ok && !user.IsPassword(password, time.Now()) {
+		return NewAPIError("sessions are not available to LDAP users", "", http.StatusForbidden)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	session, token, refreshToken, err := authService.CreateSession(ctx, user.Username,
+		viper.GetDuration("session-ttl"), viper.GetDuration("session-refresh-ttl"))
+	if err != nil {
+		return InternalError(err)
+	}
+
+	// Not response.OKReturn, the tokens are not to be logged.
+	c.JSON(http.StatusCreated, toSessionResponse(session, token, refreshToken))
+	return nil
+}
+
+// SessionRefresh handles the API endpoint POST /auth/session/refresh. It returns new tokens
+// for the session of the refresh token. It needs no other authentication.
+func SessionRefresh(c *gin.Context) APIErrors {
+	ctx := c.Request.Context()
+
+	var request models.SessionRefreshRequest
+	if err := c.BindJSON(&request); err != nil {
+		return BadRequest(err)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	session, token, refreshToken, err := authService.RefreshSession(ctx, request.RefreshToken,
+		viper.GetDuration("session-ttl"))
+	if err == auth.ErrSessionInvalid {
+		return NewAPIError("session expired, please log in again", "", http.StatusUnauthorized)
+	}
+	if err != nil {
+		return InternalError(err)
+	}
+
+	c.JSON(http.StatusOK, toSessionResponse(session, token, refreshToken))
+	return nil
+}
+
+// SessionDelete handles the API endpoint DELETE /auth/session. It ends the session of the
+// token the request is authenticated with.
+func SessionDelete(c *gin.Context) APIErrors {
+	ctx := c.Request.Context()
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !auth.IsSessionToken(token) {
+		return NewBadRequest("request not authenticated with a session token")
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	session, err := authService.ValidateSession(ctx, token)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	err = authService.DeleteSession(ctx, session.ID)
+	if err != nil && err != auth.ErrSessionNotFound {
+		return InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
+
+func toSessionResponse(session auth.Session, token, refreshToken string) models.SessionResponse {
+	return models.SessionResponse{
+		Token:            token,
+		RefreshToken:     refreshToken,
+		ExpiresAt:        session.ExpiresAt,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+	}
+}
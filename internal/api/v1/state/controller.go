@@ -0,0 +1,7 @@
+// Package state contains the API handlers to export the state of the platform, and to import
+// parts of it, for the migration of its namespaces, apps, and users to another installation.
+package state
+
+// Controller represents all functionality of the API related to the platform state
+type Controller struct {
+}
@@ -0,0 +1,153 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/domain"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/internal/registry"
+	"github.com/epinio/epinio/internal/version"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export handles the API endpoint GET /state
+// It returns the state of the platform, i.e. the namespaces with their custom configurations
+// and apps, and the users, for their migration to another installation.
+func (hc Controller) Export(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	state := models.PlatformState{
+		Version:    version.Version,
+		ExportedAt: time.Now().UTC(),
+		Namespaces: []models.NamespaceState{},
+		Users:      []models.UserState{},
+	}
+
+	// Without registry, or ingress, the images, and routes, are not re-pointed by the
+	// migration.
+	state.Registry, _ = registryURL(ctx, cluster)
+	state.Domain, _ = domain.MainDomain(ctx)
+
+	namespaceList, err := namespaces.List(ctx, cluster)
+	if err != nil {
+		return apierror.InternalError(err, "listing the namespaces")
+	}
+
+	for _, namespace := range namespaceList {
+		namespaceState, err := exportNamespace(ctx, cluster, namespace.Name)
+		if err != nil {
+			return apierror.InternalError(err, fmt.Sprintf("exporting namespace %s", namespace.Name))
+		}
+		state.Namespaces = append(state.Namespaces, namespaceState)
+	}
+	sort.Slice(state.Namespaces, func(i, j int) bool { return state.Namespaces[i].Name < state.Namespaces[j].Name })
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	users, err := authService.GetUsers(ctx)
+	if err != nil {
+		return apierror.InternalError(err, "listing the users")
+	}
+
+	for _, user := range users {
+		state.Users = append(state.Users, exportUser(user))
+	}
+	sort.Slice(state.Users, func(i, j int) bool { return state.Users[i].Username < state.Users[j].Username })
+
+	response.OKReturn(c, state)
+	return nil
+}
+
+// exportNamespace returns the state of the namespace. The configurations created by services
+// are left out, the services are not migrated.
+func exportNamespace(ctx context.Context, cluster *kubernetes.Cluster, namespace string) (models.NamespaceState, error) {
+	result := models.NamespaceState{
+		Name:           namespace,
+		Configurations: []models.ConfigurationState{},
+		Apps:           []models.AppState{},
+	}
+
+	configurationList, err := configurations.List(ctx, cluster, namespace)
+	if err != nil {
+		return result, err
+	}
+	for _, configuration := range configurationList {
+		if configuration.Labels[configurations.ConfigurationTypeLabelKey] != "custom" {
+			continue
+		}
+		data, err := configuration.Details(ctx)
+		if err != nil {
+			return result, err
+		}
+		result.Configurations = append(result.Configurations, models.ConfigurationState{
+			Name: configuration.Name,
+			Data: data,
+		})
+	}
+
+	apps, err := application.List(ctx, cluster, namespace)
+	if err != nil {
+		return result, err
+	}
+	for _, app := range apps {
+		result.Apps = append(result.Apps, models.AppState{
+			Name:          app.Meta.Name,
+			ImageURL:      app.ImageURL,
+			Configuration: app.Configuration,
+		})
+	}
+
+	return result, nil
+}
+
+// exportUser returns the state of the user. Namespaces whose temporary access by the user is
+// over are left out, the expiries of the others are not exported.
+func exportUser(user auth.User) models.UserState {
+	result := models.UserState{
+		Username:   user.Username,
+		Password:   user.Password,
+		Role:       user.Role,
+		Namespaces: map[string]string{},
+		Federated:  user.Federated,
+	}
+	for _, namespace := range user.Namespaces {
+		if role := user.NamespaceRole(namespace); role != "" {
+			result.Namespaces[namespace] = role
+		}
+	}
+	return result
+}
+
+// registryURL returns the url of the registry of the installation, with its namespace, i.e.
+// the common prefix of the images it stages.
+func registryURL(ctx context.Context, cluster *kubernetes.Cluster) (string, error) {
+	details, err := registry.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), registry.CredentialsSecretName)
+	if err != nil {
+		return "", err
+	}
+	url, err := details.PublicRegistryURL()
+	if err != nil || url == "" {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s", url, details.Namespace), nil
+}
@@ -0,0 +1,75 @@
+package state
+
+import (
+	"sort"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserImport handles the API endpoint POST /state/users
+// It creates the users of the request, with their passwords, and roles. Existing users are
+// skipped, and left unchanged.
+func (hc Controller) UserImport(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	var request models.UserImportRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	for _, user := range request.Users {
+		if user.Username == "" || user.Password == "" {
+			return apierror.NewBadRequest("user without username, or password")
+		}
+		if !auth.IsRole(user.Role) {
+			return apierror.NewBadRequest("bad role", user.Username, user.Role)
+		}
+		for namespace, role := range user.Namespaces {
+			if !auth.IsNamespaceRole(role) {
+				return apierror.NewBadRequest("bad role", user.Username, namespace, role)
+			}
+		}
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result := models.UserImportResponse{Created: []string{}}
+	for _, userState := range request.Users {
+		user := auth.User{
+			Username:  userState.Username,
+			Password:  userState.Password,
+			Role:      userState.Role,
+			Federated: userState.Federated,
+		}
+		namespaces := make([]string, 0, len(userState.Namespaces))
+		for namespace := range userState.Namespaces {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		for _, namespace := range namespaces {
+			user.SetNamespaceRole(namespace, userState.Namespaces[namespace])
+		}
+
+		err := authService.CreateUser(ctx, user)
+		if err == auth.ErrUserExists {
+			result.Skipped = append(result.Skipped, user.Username)
+			continue
+		}
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+		result.Created = append(result.Created, user.Username)
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
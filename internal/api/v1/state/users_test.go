@@ -0,0 +1,39 @@
+package state_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/epinio/epinio/internal/api/v1/state"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserImport", func() {
+	importUsers := func(body string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/state/users", strings.NewReader(body))
+
+		apierr := state.Controller{}.UserImport(c)
+		Expect(apierr).ToNot(BeNil())
+		return apierr.FirstStatus()
+	}
+
+	It("rejects users with an unknown role", func() {
+		status := importUsers(`{"users":[{"username":"jane","password":"secret","role":"root"}]}`)
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects users with an unknown role in a namespace", func() {
+		status := importUsers(`{"users":[{"username":"jane","password":"secret","role":"user","namespaces":{"workspace":"owner"}}]}`)
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects users without password", func() {
+		status := importUsers(`{"users":[{"username":"jane","role":"user"}]}`)
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+})
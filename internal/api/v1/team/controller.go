@@ -0,0 +1,23 @@
+// Package team contains the API handlers to manage the teams, groups of users sharing access to
+// namespaces.
+package team
+
+import (
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Controller represents all functionality of the API related to teams
+type Controller struct {
+}
+
+// toModel converts the team into its API representation.
+func toModel(team auth.Team) models.Team {
+	return models.Team{
+		Name:           team.Name,
+		Members:        team.Members,
+		Groups:         team.Groups,
+		Namespaces:     team.Namespaces,
+		NamespaceRoles: team.NamespaceRoles,
+	}
+}
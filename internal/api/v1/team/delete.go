@@ -0,0 +1,32 @@
+package team
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete handles the API endpoint DELETE /teams/:team
+// It removes the team. Its members lose the access given by it.
+func (hc Controller) Delete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("team")
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.DeleteTeam(ctx, name)
+	if err == auth.ErrTeamNotFound {
+		return apierror.NewNotFoundError("team not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
@@ -0,0 +1,34 @@
+package team
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Index handles the API endpoint GET /teams
+// It lists the teams.
+func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	teams, err := authService.GetTeams(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result := models.TeamList{}
+	for _, team := range teams {
+		result = append(result, toModel(team))
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
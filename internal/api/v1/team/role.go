@@ -0,0 +1,62 @@
+package team
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleSet handles the API endpoint PUT /teams/:team/roles
+// It gives the team a role in a namespace, or removes the team from it, for role "none".
+func (hc Controller) RoleSet(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("team")
+
+	var request models.UserRoleRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if request.Namespace == "" {
+		return apierror.BadRequest(errors.New("namespace of role not found"))
+	}
+	if request.Role != "none" && !auth.IsNamespaceRole(request.Role) {
+		return apierror.BadRequest(errors.Errorf("bad role '%s', expected one of reader, developer, admin, or none", request.Role))
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, request.Namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !exists && request.Role != "none" {
+		return apierror.NamespaceIsNotKnown(request.Namespace)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.SetTeamNamespaceRole(ctx, name, request.Namespace, request.Role)
+	if err == auth.ErrTeamNotFound {
+		return apierror.NewNotFoundError("team not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
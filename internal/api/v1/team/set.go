@@ -0,0 +1,43 @@
+package team
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Set handles the API endpoint PUT /teams/:team
+// It creates the team, or replaces the members and groups of the existing team.
+func (hc Controller) Set(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("team")
+
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return apierror.NewBadRequest("bad team name", strings.Join(errs, ", "))
+	}
+
+	var request models.TeamRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.SetTeamMembers(ctx, name, request.Members, request.Groups)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
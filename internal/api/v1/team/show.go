@@ -0,0 +1,32 @@
+package team
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Show handles the API endpoint GET /teams/:team
+// It returns the team.
+func (hc Controller) Show(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("team")
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	team, err := authService.GetTeam(ctx, name)
+	if err == auth.ErrTeamNotFound {
+		return apierror.NewNotFoundError("team not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, toModel(team))
+	return nil
+}
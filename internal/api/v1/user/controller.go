@@ -0,0 +1,6 @@
+// Package user contains the API handlers to manage the Epinio users.
+package user
+
+// Controller represents all functionality of the API related to users
+type Controller struct {
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FederationSet handles the API endpoint PUT /users/:user/federation
+// It marks the user as the same person as the user of the same name at the LDAP server, or the
+// OIDC issuer, for them to log in there with their roles, or removes the mark. Unmarked users
+// keep the name from the identities of the same name there.
+func (hc Controller) FederationSet(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	username := c.Param("user")
+
+	var request models.UserFederationRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.SetFederated(ctx, username, request.Federated)
+	if err == auth.ErrUserNotFound {
+		return apierror.NewNotFoundError("user not found", username)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordChange handles the API endpoint PUT /auth/password
+// It replaces the password of the user, after checking the current one, and ends their other
+// sessions. The session of the request is kept. API tokens cannot do this, and only Epinio
+// users have a password to change.
+func (hc Controller) PasswordChange(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	username := requestctx.User(ctx).Username
+
+	if requestctx.APIToken(ctx) != "" {
+		return apierror.NewAPIError("passwords cannot be changed with an API token", "", http.StatusForbidden)
+	}
+
+	var request models.PasswordChangeRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+	if err := auth.ValidatePassword(request.NewPassword); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	user, err := authService.GetUserByUsername(ctx, username)
+	if err == auth.ErrUserNotFound {
+		return apierror.NewAPIError("only Epinio users have a password to change", "", http.StatusForbidden)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !user.IsPassword(request.CurrentPassword, time.Now()) {
+		return apierror.NewAPIError("current password is wrong", "", http.StatusForbidden)
+	}
+
+	err = authService.SetPassword(ctx, username, request.NewPassword)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.DeleteUserSessions(ctx, username, requestctx.SessionID(ctx))
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	// A cookie session of the request is issued again, to stay valid after the change.
+	if _, ok := c.Get(sessions.DefaultKey); ok {
+		session := sessions.Default(c)
+		if session.Get("user") != nil {
+			session.Set(auth.CookieSessionIssuedKey, time.Now().Unix())
+			if err := session.Save(); err != nil {
+				return apierror.InternalError(err, "saving the session")
+			}
+		}
+	}
+
+	response.OK(c)
+	return nil
+}
+
+// PasswordReset handles the API endpoint PUT /users/:user/password
+// It gives the user the requested password, or a random one, and ends their sessions. It
+// returns the new password.
+func (hc Controller) PasswordReset(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	username := c.Param("user")
+
+	var request models.PasswordResetRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	password := request.Password
+	if password == "" {
+		password, err = auth.NewPassword()
+		if err != nil {
+			return apierror.InternalError(err)
+		}
+	} else if err := auth.ValidatePassword(password); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.SetPassword(ctx, username, password)
+	if err == auth.ErrUserNotFound {
+		return apierror.NewNotFoundError("user not found", username)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.DeleteUserSessions(ctx, username, "")
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	// Not response.OKReturn, the password is not to be logged.
+	c.JSON(http.StatusOK, models.PasswordResetResponse{Password: password})
+	return nil
+}
@@ -0,0 +1,77 @@
+package user
+
+import (
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/namespaces"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleSet handles the API endpoint PUT /users/:user/roles
+// It gives the user a role in a namespace, or removes the user from it, for role "none". With
+// an expiry the role is revoked after that duration.
+func (hc Controller) RoleSet(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	username := c.Param("user")
+
+	var request models.UserRoleRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	if request.Namespace == "" {
+		return apierror.BadRequest(errors.New("namespace of role not found"))
+	}
+	if request.Role != "none" && !auth.IsNamespaceRole(request.Role) {
+		return apierror.BadRequest(errors.Errorf("bad role '%s', expected one of reader, developer, admin, or none", request.Role))
+	}
+
+	var expiresAt time.Time
+	if request.Expires != "" {
+		if request.Role == "none" {
+			return apierror.BadRequest(errors.New("role none cannot expire"))
+		}
+		expires, err := time.ParseDuration(request.Expires)
+		if err != nil || expires <= 0 {
+			return apierror.BadRequest(errors.Errorf("bad expiry '%s', expected a positive duration, e.g. 72h", request.Expires))
+		}
+		expiresAt = time.Now().Add(expires)
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	exists, err := namespaces.Exists(ctx, cluster, request.Namespace)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+	if !exists && request.Role != "none" {
+		return apierror.NamespaceIsNotKnown(request.Namespace)
+	}
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = authService.GrantNamespaceRole(ctx, username, request.Namespace, request.Role, expiresAt)
+	if err == auth.ErrUserNotFound {
+		return apierror.NewNotFoundError("user not found", username)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
@@ -0,0 +1,21 @@
+// Package webhook contains the API handlers to manage the webhooks receiving the platform
+// events.
+package webhook
+
+import (
+	"github.com/epinio/epinio/internal/webhooks"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Controller represents all functionality of the API related to webhooks
+type Controller struct {
+}
+
+// toModel converts the webhook into its API representation, without secret.
+func toModel(hook webhooks.Webhook) models.Webhook {
+	return models.Webhook{
+		Name:   hook.Name,
+		URL:    hook.URL,
+		Events: hook.Events,
+	}
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/webhooks"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Delete handles the API endpoint DELETE /webhooks/:webhook
+// It removes the webhook. The events are not posted to it anymore.
+func (hc Controller) Delete(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("webhook")
+
+	store, err := webhooks.NewStoreFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	err = store.Delete(ctx, name)
+	if err == webhooks.ErrWebhookNotFound {
+		return apierror.NewNotFoundError("webhook not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OK(c)
+	return nil
+}
@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/webhooks"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Index handles the API endpoint GET /webhooks
+// It lists the webhooks.
+func (hc Controller) Index(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+
+	store, err := webhooks.NewStoreFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	hooks, err := store.List(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	result := models.WebhookList{}
+	for _, hook := range hooks {
+		result = append(result, toModel(hook))
+	}
+
+	response.OKReturn(c, result)
+	return nil
+}
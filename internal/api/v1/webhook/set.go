@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/webhooks"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Set handles the API endpoint PUT /webhooks/:webhook
+// It creates the webhook, or replaces its url and event types, and its secret, if given. It
+// returns the secret the events are signed with.
+func (hc Controller) Set(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("webhook")
+
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return apierror.NewBadRequest("bad webhook name", strings.Join(errs, ", "))
+	}
+
+	var request models.WebhookRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		return apierror.BadRequest(err)
+	}
+	if err := webhooks.ValidateURL(request.URL); err != nil {
+		return apierror.BadRequest(err)
+	}
+
+	store, err := webhooks.NewStoreFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	hook, err := store.Set(ctx, webhooks.Webhook{
+		Name:   name,
+		URL:    request.URL,
+		Secret: request.Secret,
+		Events: request.Events,
+	})
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, models.WebhookSetResponse{Secret: hook.Secret})
+	return nil
+}
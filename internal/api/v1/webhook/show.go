@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/webhooks"
+	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Show handles the API endpoint GET /webhooks/:webhook
+// It returns the webhook, without its secret.
+func (hc Controller) Show(c *gin.Context) apierror.APIErrors {
+	ctx := c.Request.Context()
+	name := c.Param("webhook")
+
+	store, err := webhooks.NewStoreFromContext(ctx)
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	hook, err := store.Get(ctx, name)
+	if err == webhooks.ErrWebhookNotFound {
+		return apierror.NewNotFoundError("webhook not found", name)
+	}
+	if err != nil {
+		return apierror.InternalError(err)
+	}
+
+	response.OKReturn(c, toModel(hook))
+	return nil
+}
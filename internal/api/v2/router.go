@@ -0,0 +1,46 @@
+// Package v2 is the implementation of Epinio's API v2. The API is versioned as a whole, but
+// v2 only serves the endpoints reshaped from v1, e.g. the list endpoints, responding with
+// pages. Clients use v1 for everything else. The handlers are those of v1, which respond in
+// the shape of the version of the request, see response.VersionKey.
+//
+// The v1 endpoints with a v2 successor are deprecated. Their responses carry the
+// `Deprecation` header, the `Link` to the successor, and, if configured, the `Sunset` date,
+// see DeprecationMiddleware. The versions, and their endpoints, are discoverable at
+// VersionsPath, for clients to use the latest version the server supports.
+package v2
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/epinio/epinio/helpers/routes"
+	apiv1 "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/internal/api/v1/response"
+)
+
+const (
+	// Version is the name of the version.
+	Version = "v2"
+	// Root is the url path prefix for all API v2 endpoints.
+	Root = "/api/v2"
+)
+
+// Routes are the endpoints of v2. They have the names, paths and handlers of the v1 endpoints
+// they succeed.
+var Routes = routes.NamedRoutes{}
+
+func init() {
+	for _, name := range []string{"AllApps", "Apps", "AllConfigurations", "Configurations", "ServiceList"} {
+		Routes[name] = apiv1.Routes[name]
+	}
+}
+
+// Lemon extends the specified router with the methods and urls handling the API v2
+// endpoints.
+func Lemon(router *gin.RouterGroup) {
+	router.Use(func(c *gin.Context) {
+		c.Set(response.VersionKey, Version)
+	})
+	for _, r := range Routes {
+		router.Handle(r.Method, r.Path, r.Handler)
+	}
+}
@@ -0,0 +1,92 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	apiv1 "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	apiv2 "github.com/epinio/epinio/internal/api/v2"
+	"github.com/epinio/epinio/internal/listing"
+	"github.com/gin-gonic/gin"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API v2", func() {
+	sunset := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	It("succeeds v1 routes with the same names and paths", func() {
+		Expect(apiv2.Routes).ToNot(BeEmpty())
+		for name, route := range apiv2.Routes {
+			Expect(apiv1.Routes).To(HaveKey(name))
+			Expect(route.Path).To(Equal(apiv1.Routes[name].Path))
+		}
+	})
+
+	It("lists the versions, and the deprecated v1 routes", func() {
+		versions := apiv2.VersionsResponse(sunset).Versions
+		Expect(versions).To(HaveLen(2))
+
+		Expect(versions[0].Version).To(Equal("v1"))
+		Expect(versions[0].Routes).To(ContainElements("Apps", "Info", "OIDCConfig"))
+		Expect(versions[0].Deprecated).To(ConsistOf("AllApps", "Apps", "AllConfigurations", "Configurations", "ServiceList"))
+		Expect(versions[0].Sunset).To(Equal("2027-06-30T00:00:00Z"))
+
+		Expect(versions[1].Version).To(Equal("v2"))
+		Expect(versions[1].Root).To(Equal("/api/v2"))
+		Expect(versions[1].Routes).To(Equal(versions[0].Deprecated))
+	})
+
+	It("marks the responses of the deprecated v1 routes", func() {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		group := router.Group(apiv1.Root, apiv2.DeprecationMiddleware(sunset))
+		group.GET(apiv1.Routes["Apps"].Path, func(c *gin.Context) { c.Status(http.StatusOK) })
+		group.GET(apiv1.Routes["Info"].Path, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/namespaces/workspace/applications", nil))
+		Expect(recorder.Header().Get("Deprecation")).To(Equal("true"))
+		Expect(recorder.Header().Get("Link")).To(Equal(`</api/v2/namespaces/workspace/applications>; rel="successor-version"`))
+		Expect(recorder.Header().Get("Sunset")).To(Equal("Wed, 30 Jun 2027 00:00:00 GMT"))
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/info", nil))
+		Expect(recorder.Header().Get("Deprecation")).To(BeEmpty())
+	})
+
+	It("responds to lists with pages in v2, and as v1 otherwise", func() {
+		list := func(c *gin.Context) {
+			items := []string{"one", "two"}
+			response.OKReturnList(c, gin.H{"things": items}, items, 5, listing.Options{Limit: 2, Offset: 2})
+		}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/v1/things", list)
+		router.GET("/api/v2/things", func(c *gin.Context) {
+			c.Set(response.VersionKey, apiv2.Version)
+			list(c)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/things", nil))
+		Expect(recorder.Header().Get(listing.TotalCountHeader)).To(Equal("5"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"things":["one","two"]}`))
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v2/things", nil))
+		var page map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &page)).To(Succeed())
+		Expect(page).To(Equal(map[string]interface{}{
+			"items":  []interface{}{"one", "two"},
+			"total":  float64(5),
+			"offset": float64(2),
+			"limit":  float64(2),
+		}))
+	})
+})
@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/epinio/epinio/helpers/routes"
+	apiv1 "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// VersionsPath is the path of the endpoint listing the versions of the API.
+const VersionsPath = "/api/versions"
+
+// Versions handles the API endpoint GET /api/versions. It lists the versions of the API, with
+// the names of their endpoints, and the deprecated ones. It needs no authentication.
+func Versions(sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.OKReturn(c, VersionsResponse(sunset))
+	}
+}
+
+// VersionsResponse returns the versions of the API. A zero sunset is no sunset.
+func VersionsResponse(sunset time.Time) models.APIVersionsResponse {
+	v1 := models.APIVersion{
+		Version:    "v1",
+		Root:       apiv1.Root,
+		Routes:     routeNames(apiv1.Routes, apiv1.PublicRoutes),
+		Deprecated: routeNames(Routes),
+	}
+	if !sunset.IsZero() {
+		v1.Sunset = sunset.Format(time.RFC3339)
+	}
+
+	return models.APIVersionsResponse{
+		Versions: []models.APIVersion{v1, {
+			Version: Version,
+			Root:    Root,
+			Routes:  routeNames(Routes),
+		}},
+	}
+}
+
+// DeprecationMiddleware marks the responses of the deprecated v1 endpoints, the ones with a
+// successor in v2. A zero sunset is no sunset.
+func DeprecationMiddleware(sunset time.Time) gin.HandlerFunc {
+	successors := map[string]string{}
+	for _, r := range Routes {
+		successors[r.Method+" "+apiv1.Root+r.Path] = r.Path
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := successors[c.Request.Method+" "+c.FullPath()]; !ok {
+			return
+		}
+
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, Root, c.Request.URL.Path[len(apiv1.Root):]))
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+}
+
+// routeNames returns the names of the routes, sorted.
+func routeNames(named ...routes.NamedRoutes) []string {
+	names := []string{}
+	for _, group := range named {
+		for name := range group {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
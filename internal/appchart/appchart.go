@@ -3,7 +3,9 @@ package appchart
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sort"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/helmchart"
@@ -13,7 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-// List returns a slice of all known app chart CRs.
+// List returns a slice of all known app chart CRs, sorted by name.
 func List(ctx context.Context, cluster *kubernetes.Cluster) (models.AppChartList, error) {
 	client, err := cluster.ClientAppChart()
 	if err != nil {
@@ -35,6 +37,7 @@ func List(ctx context.Context, cluster *kubernetes.Cluster) (models.AppChartList
 		}
 		apps = append(apps, *appChart)
 	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Meta.Name < apps[j].Meta.Name })
 
 	return apps, nil
 }
@@ -104,6 +107,11 @@ func toChart(chart *unstructured.Unstructured) (*models.AppChart, error) {
 		return nil, errors.New("helm repo should be string")
 	}
 
+	settings, err := toSettings(chart)
+	if err != nil {
+		return nil, err
+	}
+
 	createdAt := chart.GetCreationTimestamp()
 
 	return &models.AppChart{
@@ -115,5 +123,30 @@ func toChart(chart *unstructured.Unstructured) (*models.AppChart, error) {
 		ShortDescription: short,
 		HelmChart:        helmChart,
 		HelmRepo:         helmRepo,
+		Settings:         settings,
 	}, nil
 }
+
+// toSettings returns the settings declared by the app chart CR, if any.
+func toSettings(chart *unstructured.Unstructured) (map[string]models.AppChartSetting, error) {
+	raw, found, err := unstructured.NestedMap(chart.UnstructuredContent(), "spec", "settings")
+	if err != nil {
+		return nil, errors.New("settings should be map")
+	}
+	if !found {
+		return nil, nil
+	}
+
+	// Round-trip through JSON to get the typed settings.
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]models.AppChartSetting
+	if err := json.Unmarshal(encoded, &settings); err != nil {
+		return nil, errors.New("bad settings")
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,116 @@
+package appchart
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Types of app chart settings
+const (
+	SettingString  = "string"
+	SettingBool    = "bool"
+	SettingInteger = "integer"
+	SettingNumber  = "number"
+)
+
+// Values checks the user values against the settings declared by the app chart, and returns
+// them converted to the declared types, ready for use as helm values. Values for undeclared
+// settings are rejected.
+func Values(chart *models.AppChart, values map[string]string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+
+	// Sorted, for a stable choice of the reported error.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+
+		setting, ok := chart.Settings[key]
+		if !ok {
+			return nil, fmt.Errorf("app chart '%s' has no setting '%s'", chart.Meta.Name, key)
+		}
+
+		typed, err := settingValue(setting, value)
+		if err != nil {
+			return nil, fmt.Errorf("bad value '%s' for setting '%s': %s", value, key, err.Error())
+		}
+
+		result[key] = typed
+	}
+
+	return result, nil
+}
+
+// settingValue returns the value converted to the type of the setting, after checking it
+// against the restrictions of the setting.
+func settingValue(setting models.AppChartSetting, value string) (interface{}, error) {
+	switch setting.Type {
+	case "", SettingString:
+		if len(setting.Enum) == 0 {
+			return value, nil
+		}
+		for _, allowed := range setting.Enum {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of %v", setting.Enum)
+	case SettingBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("expected a boolean")
+		}
+		return b, nil
+	case SettingInteger:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("expected an integer")
+		}
+		if err := checkRange(setting, float64(i)); err != nil {
+			return nil, err
+		}
+		return i, nil
+	case SettingNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("expected a number")
+		}
+		if err := checkRange(setting, f); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("unknown setting type '%s'", setting.Type)
+}
+
+// checkRange checks the number against the minimum and maximum of the setting, if any.
+func checkRange(setting models.AppChartSetting, value float64) error {
+	if setting.Minimum != "" {
+		min, err := strconv.ParseFloat(setting.Minimum, 64)
+		if err != nil {
+			return fmt.Errorf("bad minimum '%s' in app chart", setting.Minimum)
+		}
+		if value < min {
+			return fmt.Errorf("expected at least %s", setting.Minimum)
+		}
+	}
+	if setting.Maximum != "" {
+		max, err := strconv.ParseFloat(setting.Maximum, 64)
+		if err != nil {
+			return fmt.Errorf("bad maximum '%s' in app chart", setting.Maximum)
+		}
+		if value > max {
+			return fmt.Errorf("expected at most %s", setting.Maximum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package appchart_test
+
+import (
+	"github.com/epinio/epinio/internal/appchart"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Values", func() {
+	chart := &models.AppChart{
+		Meta: models.MetaLite{Name: "custom"},
+		Settings: map[string]models.AppChartSetting{
+			"tier":     {Enum: []string{"small", "large"}},
+			"debug":    {Type: "bool"},
+			"replicas": {Type: "integer", Minimum: "1", Maximum: "5"},
+			"ratio":    {Type: "number", Maximum: "1"},
+			"label":    {Type: "string"},
+		},
+	}
+
+	It("converts the values to the declared types", func() {
+		values, err := appchart.Values(chart, map[string]string{
+			"tier":     "large",
+			"debug":    "true",
+			"replicas": "3",
+			"ratio":    "0.5",
+			"label":    "blue",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(Equal(map[string]interface{}{
+			"tier":     "large",
+			"debug":    true,
+			"replicas": int64(3),
+			"ratio":    0.5,
+			"label":    "blue",
+		}))
+	})
+
+	It("accepts no values", func() {
+		values, err := appchart.Values(&models.AppChart{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(BeEmpty())
+	})
+
+	It("rejects values for undeclared settings", func() {
+		_, err := appchart.Values(chart, map[string]string{"color": "red"})
+		Expect(err).To(MatchError("app chart 'custom' has no setting 'color'"))
+	})
+
+	It("rejects values not matching the settings", func() {
+		_, err := appchart.Values(chart, map[string]string{"tier": "medium"})
+		Expect(err).To(MatchError(ContainSubstring("expected one of [small large]")))
+
+		_, err = appchart.Values(chart, map[string]string{"debug": "maybe"})
+		Expect(err).To(MatchError(ContainSubstring("expected a boolean")))
+
+		_, err = appchart.Values(chart, map[string]string{"replicas": "2.5"})
+		Expect(err).To(MatchError(ContainSubstring("expected an integer")))
+
+		_, err = appchart.Values(chart, map[string]string{"replicas": "9"})
+		Expect(err).To(MatchError(ContainSubstring("expected at most 5")))
+
+		_, err = appchart.Values(chart, map[string]string{"replicas": "0"})
+		Expect(err).To(MatchError(ContainSubstring("expected at least 1")))
+
+		_, err = appchart.Values(chart, map[string]string{"ratio": "high"})
+		Expect(err).To(MatchError(ContainSubstring("expected a number")))
+	})
+})
@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/routes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// appPort is the port the app chart gives the applications in the PORT variable.
+const appPort = 8080
+
+// Adoption describes an existing workload of a namespace, i.e. a deployment, with the services
+// selecting its instances, and the ingresses routing to these services, as the application
+// replacing them. The warnings name the parts of the workload which are not carried over.
+type Adoption struct {
+	Deployment    string
+	Services      []string
+	Ingresses     []string
+	ImageURL      string
+	Configuration models.ApplicationUpdateRequest
+	Warnings      []string
+}
+
+// Resources returns the kinds and names of the adopted resources, e.g. `Deployment/web`.
+func (a Adoption) Resources() []string {
+	result := []string{"Deployment/" + a.Deployment}
+	for _, name := range a.Services {
+		result = append(result, "Service/"+name)
+	}
+	for _, name := range a.Ingresses {
+		result = append(result, "Ingress/"+name)
+	}
+	return result
+}
+
+// AdoptionCandidates returns the named deployment of the namespace, with the services and
+// ingresses of the namespace, for Adopt.
+func AdoptionCandidates(ctx context.Context, cluster *kubernetes.Cluster, namespace, deploymentName string) (*appsv1.Deployment, []corev1.Service, []networkingv1.Ingress, error) {
+	deployment, err := cluster.Kubectl.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	services, err := cluster.Kubectl.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "listing the services")
+	}
+
+	ingresses, err := cluster.Kubectl.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "listing the ingresses")
+	}
+
+	return deployment, services.Items, ingresses.Items, nil
+}
+
+// Adopt returns the adoption of the deployment, with the services selecting its instances,
+// and the ingresses routing to them. It fails for deployments of Epinio applications, and
+// for unknown containers.
+func Adopt(deployment appsv1.Deployment, services []corev1.Service, ingresses []networkingv1.Ingress, container string) (Adoption, error) {
+	if isEpinioWorkload(deployment) {
+		return Adoption{}, fmt.Errorf("deployment '%s' belongs to an Epinio application", deployment.Name)
+	}
+
+	adoption := Adoption{Deployment: deployment.Name, Warnings: []string{}}
+	pod := deployment.Spec.Template.Spec
+
+	main, err := adoptedContainer(pod.Containers, container)
+	if err != nil {
+		return Adoption{}, errors.Wrapf(err, "deployment '%s'", deployment.Name)
+	}
+	adoption.ImageURL = main.Image
+
+	for _, other := range pod.Containers {
+		if other.Name != main.Name {
+			adoption.Warnings = append(adoption.Warnings,
+				fmt.Sprintf("container '%s' is not adopted, add it as sidecar", other.Name))
+		}
+	}
+	if len(pod.ImagePullSecrets) > 0 {
+		adoption.Warnings = append(adoption.Warnings,
+			"image pull secrets are not adopted, set the pull secret of the application")
+	}
+	if len(pod.Volumes) > 0 {
+		adoption.Warnings = append(adoption.Warnings,
+			"volumes are not adopted, add them as volumes, or configurations, of the application")
+	}
+
+	instances := int32(1)
+	if deployment.Spec.Replicas != nil {
+		instances = *deployment.Spec.Replicas
+	}
+	adoption.Configuration.Instances = &instances
+
+	environment := models.EnvVariableMap{}
+	for _, variable := range main.Env {
+		if variable.ValueFrom != nil {
+			adoption.Warnings = append(adoption.Warnings,
+				fmt.Sprintf("variable '%s' is taken from another resource, and not adopted", variable.Name))
+			continue
+		}
+		environment[variable.Name] = variable.Value
+	}
+	if len(environment) > 0 {
+		adoption.Configuration.Environment = environment
+	}
+
+	if len(main.Command) > 0 || len(main.Args) > 0 {
+		adoption.Configuration.Entrypoint = &models.AppEntrypoint{
+			Command: main.Command,
+			Args:    main.Args,
+		}
+	}
+
+	for _, port := range main.Ports {
+		if port.ContainerPort != appPort {
+			adoption.Warnings = append(adoption.Warnings,
+				fmt.Sprintf("the container listens on port %d, applications are served on the port of the PORT variable, %d",
+					port.ContainerPort, appPort))
+			break
+		}
+	}
+
+	// The services selecting the instances, and the routes of the ingresses to them.
+	podLabels := labels.Set(deployment.Spec.Template.Labels)
+	selected := map[string]struct{}{}
+	for _, service := range services {
+		if len(service.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(service.Spec.Selector).Matches(podLabels) {
+			selected[service.Name] = struct{}{}
+			adoption.Services = append(adoption.Services, service.Name)
+		}
+	}
+	sort.Strings(adoption.Services)
+
+	routeStrs := []string{}
+	for _, ingress := range ingresses {
+		found, warnings := ingressRoutes(ingress, selected)
+		adoption.Warnings = append(adoption.Warnings, warnings...)
+		if len(found) == 0 {
+			continue
+		}
+		adoption.Ingresses = append(adoption.Ingresses, ingress.Name)
+		routeStrs = append(routeStrs, found...)
+	}
+	sort.Strings(adoption.Ingresses)
+	sort.Strings(routeStrs)
+	if len(routeStrs) > 0 {
+		adoption.Configuration.Routes = routeStrs
+	}
+
+	return adoption, nil
+}
+
+// ingressRoutes returns the routes of the ingress to the services, with warnings for the
+// rules which are not routes.
+func ingressRoutes(ingress networkingv1.Ingress, services map[string]struct{}) ([]string, []string) {
+	tlsSecrets := map[string]string{}
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			tlsSecrets[host] = tls.SecretName
+		}
+	}
+
+	result := []string{}
+	warnings := []string{}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			if _, ok := services[path.Backend.Service.Name]; !ok {
+				continue
+			}
+			if rule.Host == "" {
+				warnings = append(warnings,
+					fmt.Sprintf("ingress '%s' routes all hosts, which is not adopted", ingress.Name))
+				continue
+			}
+
+			route := routes.Route{
+				Domain:    rule.Host,
+				Path:      path.Path,
+				TLSSecret: tlsSecrets[rule.Host],
+			}
+			if route.Path == "" {
+				route.Path = "/"
+			}
+			result = append(result, route.String())
+		}
+	}
+	return result, warnings
+}
+
+// adoptedContainer returns the named container, or the first.
+func adoptedContainer(containers []corev1.Container, name string) (corev1.Container, error) {
+	if len(containers) == 0 {
+		return corev1.Container{}, errors.New("no containers")
+	}
+	if name == "" {
+		return containers[0], nil
+	}
+
+	names := []string{}
+	for _, container := range containers {
+		if container.Name == name {
+			return container, nil
+		}
+		names = append(names, container.Name)
+	}
+	return corev1.Container{}, fmt.Errorf("container '%s' not found, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// isEpinioWorkload returns true for the deployments of Epinio applications.
+func isEpinioWorkload(deployment appsv1.Deployment) bool {
+	deploymentLabels := deployment.Labels
+	return deploymentLabels["app.kubernetes.io/managed-by"] == "epinio" ||
+		(deploymentLabels["app.kubernetes.io/component"] == "application" &&
+			deploymentLabels["app.kubernetes.io/part-of"] == deployment.Namespace)
+}
+
+// RemoveAdopted deletes the adopted resources, once the application replacing them is
+// deployed. Resources which are gone already are ignored.
+func RemoveAdopted(ctx context.Context, cluster *kubernetes.Cluster, namespace string, adoption Adoption) error {
+	errorMessages := []string{}
+	ignoreMissing := func(err error) {
+		if err != nil && !apierrors.IsNotFound(err) {
+			errorMessages = append(errorMessages, err.Error())
+		}
+	}
+
+	for _, name := range adoption.Ingresses {
+		ignoreMissing(cluster.Kubectl.NetworkingV1().Ingresses(namespace).Delete(ctx, name, metav1.DeleteOptions{}))
+	}
+	for _, name := range adoption.Services {
+		ignoreMissing(cluster.Kubectl.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{}))
+	}
+	ignoreMissing(cluster.Kubectl.AppsV1().Deployments(namespace).Delete(ctx, adoption.Deployment, metav1.DeleteOptions{}))
+
+	if len(errorMessages) > 0 {
+		return fmt.Errorf("some adopted resources were not removed: [%s]", strings.Join(errorMessages, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Adopt", func() {
+	var deployment appsv1.Deployment
+	var services []corev1.Service
+	var ingresses []networkingv1.Ingress
+
+	ingressTo := func(name, host, path, service string) networkingv1.Ingress {
+		return networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path: path,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{Name: service},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		replicas := int32(3)
+		deployment = appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "workspace"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", "tier": "front"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:    "main",
+							Image:   "registry.example.com/web:1.2",
+							Command: []string{"/bin/web"},
+							Args:    []string{"--verbose"},
+							Env: []corev1.EnvVar{
+								{Name: "MODE", Value: "production"},
+								{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{}},
+							},
+							Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+						}},
+					},
+				},
+			},
+		}
+		services = []corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "db"},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "db"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "external"},
+			},
+		}
+		secured := ingressTo("web-tls", "web.example.com", "/api", "web")
+		secured.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"web.example.com"}, SecretName: "web-cert"}}
+		ingresses = []networkingv1.Ingress{
+			secured,
+			ingressTo("web", "www.example.com", "", "web"),
+			ingressTo("db", "db.example.com", "/", "db"),
+		}
+	})
+
+	It("takes the image and settings of the main container", func() {
+		adoption, err := application.Adopt(deployment, services, ingresses, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(adoption.ImageURL).To(Equal("registry.example.com/web:1.2"))
+		Expect(*adoption.Configuration.Instances).To(Equal(int32(3)))
+		Expect(adoption.Configuration.Environment).To(Equal(models.EnvVariableMap{"MODE": "production"}))
+		Expect(adoption.Configuration.Entrypoint).To(Equal(&models.AppEntrypoint{
+			Command: []string{"/bin/web"},
+			Args:    []string{"--verbose"},
+		}))
+		Expect(adoption.Warnings).To(ConsistOf(ContainSubstring("variable 'TOKEN'")))
+	})
+
+	It("finds the services of the instances, and the routes of their ingresses", func() {
+		adoption, err := application.Adopt(deployment, services, ingresses, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(adoption.Services).To(Equal([]string{"web"}))
+		Expect(adoption.Ingresses).To(Equal([]string{"web", "web-tls"}))
+		Expect(adoption.Configuration.Routes).To(Equal([]string{
+			"web.example.com/api?tls-secret=web-cert",
+			"www.example.com",
+		}))
+		Expect(adoption.Resources()).To(Equal([]string{
+			"Deployment/web", "Service/web", "Ingress/web", "Ingress/web-tls",
+		}))
+	})
+
+	It("adopts the named container, with warnings about the others", func() {
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+			corev1.Container{Name: "proxy", Image: "proxy:1", Ports: []corev1.ContainerPort{{ContainerPort: 9000}}})
+
+		adoption, err := application.Adopt(deployment, services, ingresses, "proxy")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(adoption.ImageURL).To(Equal("proxy:1"))
+		Expect(adoption.Configuration.Entrypoint).To(BeNil())
+		Expect(adoption.Warnings).To(ConsistOf(
+			ContainSubstring("container 'main' is not adopted"),
+			ContainSubstring("listens on port 9000"),
+		))
+	})
+
+	It("rejects unknown containers", func() {
+		_, err := application.Adopt(deployment, services, ingresses, "proxy")
+		Expect(err).To(MatchError(ContainSubstring("container 'proxy' not found, expected one of main")))
+	})
+
+	It("warns about ingresses for all hosts", func() {
+		ingresses = []networkingv1.Ingress{ingressTo("catch-all", "", "/", "web")}
+
+		adoption, err := application.Adopt(deployment, services, ingresses, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(adoption.Configuration.Routes).To(BeNil())
+		Expect(adoption.Ingresses).To(BeEmpty())
+		Expect(adoption.Warnings).To(ContainElement(ContainSubstring("ingress 'catch-all' routes all hosts")))
+	})
+
+	It("rejects the deployments of applications", func() {
+		deployment.Labels = map[string]string{
+			"app.kubernetes.io/component": "application",
+			"app.kubernetes.io/part-of":   "workspace",
+		}
+
+		_, err := application.Adopt(deployment, services, ingresses, "")
+		Expect(err).To(MatchError(ContainSubstring("belongs to an Epinio application")))
+	})
+})
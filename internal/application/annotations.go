@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/routes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	routeAnnotationsKey = "routeannotations"
+)
+
+// RouteAnnotations returns the ingress annotations of the application's routes.
+func RouteAnnotations(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (models.AppRouteAnnotations, error) {
+	annotationsSecret, err := routeAnnotationsLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := annotationsSecret.Data[routeAnnotationsKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var annotations models.AppRouteAnnotations
+	if err := json.Unmarshal(encoded, &annotations); err != nil {
+		return nil, errors.Wrap(err, "bad route annotations")
+	}
+
+	return annotations, nil
+}
+
+// RouteAnnotationsSet replaces the ingress annotations of the named application's routes.
+// When the function returns the annotations are saved.
+func RouteAnnotationsSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, annotations models.AppRouteAnnotations) error {
+	encoded, err := json.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		annotationsSecret, err := routeAnnotationsLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if annotationsSecret.Data == nil {
+			annotationsSecret.Data = make(map[string][]byte)
+		}
+
+		if len(annotations) == 0 {
+			delete(annotationsSecret.Data, routeAnnotationsKey)
+		} else {
+			annotationsSecret.Data[routeAnnotationsKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, annotationsSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateRouteAnnotations checks that the annotations are given for plain routes, i.e.
+// domain and path without options, and that they are allowed by the operator.
+func ValidateRouteAnnotations(annotations models.AppRouteAnnotations) error {
+	allowed := AllowedRouteAnnotations()
+
+	for route, routeAnnotations := range annotations {
+		r := routes.FromString(route)
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if r.String() != strings.TrimSuffix(route, "/") {
+			return errors.Errorf("bad route '%s' for annotations: expected domain and path only", route)
+		}
+
+		for key := range routeAnnotations {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return errors.Errorf("bad annotation '%s' of route '%s': %s", key, route, errs[0])
+			}
+			if !annotationAllowed(allowed, key) {
+				return errors.Errorf("annotation '%s' of route '%s' is not allowed", key, route)
+			}
+		}
+	}
+	return nil
+}
+
+// AllowedRouteAnnotations returns the ingress annotations users may set on their routes, as
+// configured by the operator. A trailing `*` allows all annotations with that prefix.
+func AllowedRouteAnnotations() []string {
+	return strings.FieldsFunc(viper.GetString("ingress-annotations-allowed"), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// annotationAllowed returns true if the annotation matches an entry of the allowlist.
+func annotationAllowed(allowed []string, key string) bool {
+	for _, entry := range allowed {
+		if strings.HasSuffix(entry, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+		} else if key == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// routeAnnotationsLoad locates and returns the kube secret storing the referenced
+// application's route annotations. If necessary it creates that secret.
+func routeAnnotationsLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeRouteAnnotationsSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "routeannotations")
+}
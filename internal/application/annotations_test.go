@@ -0,0 +1,63 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RouteAnnotations", func() {
+	BeforeEach(func() {
+		viper.Set("ingress-annotations-allowed",
+			"nginx.ingress.kubernetes.io/proxy-body-size, traefik.ingress.kubernetes.io/*")
+	})
+
+	AfterEach(func() {
+		viper.Set("ingress-annotations-allowed", "")
+	})
+
+	Describe("AllowedRouteAnnotations", func() {
+		It("splits the allowlist", func() {
+			Expect(application.AllowedRouteAnnotations()).To(Equal([]string{
+				"nginx.ingress.kubernetes.io/proxy-body-size",
+				"traefik.ingress.kubernetes.io/*",
+			}))
+		})
+	})
+
+	Describe("ValidateRouteAnnotations", func() {
+		It("accepts allowed annotations", func() {
+			Expect(application.ValidateRouteAnnotations(models.AppRouteAnnotations{
+				"example.com/api": {
+					"nginx.ingress.kubernetes.io/proxy-body-size":      "10m",
+					"traefik.ingress.kubernetes.io/router.middlewares": "default-sticky@kubernetescrd",
+				},
+			})).To(Succeed())
+		})
+
+		It("rejects annotations not in the allowlist", func() {
+			err := application.ValidateRouteAnnotations(models.AppRouteAnnotations{
+				"example.com": {"nginx.ingress.kubernetes.io/server-snippet": "return 200;"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("annotation 'nginx.ingress.kubernetes.io/server-snippet' of route 'example.com' is not allowed")))
+		})
+
+		It("rejects everything without allowlist", func() {
+			viper.Set("ingress-annotations-allowed", "")
+			err := application.ValidateRouteAnnotations(models.AppRouteAnnotations{
+				"example.com": {"nginx.ingress.kubernetes.io/proxy-body-size": "10m"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("is not allowed")))
+		})
+
+		It("rejects routes with options", func() {
+			err := application.ValidateRouteAnnotations(models.AppRouteAnnotations{
+				"example.com?rewrite=/": {"nginx.ingress.kubernetes.io/proxy-body-size": "10m"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("expected domain and path only")))
+		})
+	})
+})
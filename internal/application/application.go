@@ -15,6 +15,7 @@ import (
 	"github.com/epinio/epinio/internal/duration"
 	"github.com/epinio/epinio/internal/helm"
 	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/listing"
 	"github.com/epinio/epinio/internal/namespaces"
 	"github.com/epinio/epinio/internal/s3manager"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
@@ -189,47 +190,75 @@ func ListAppRefs(ctx context.Context, cluster *kubernetes.Cluster, namespace str
 // List returns a list of all available apps in the specified namespace. If no namespace is
 // specified (empty string) then apps across all namespaces are returned.
 func List(ctx context.Context, cluster *kubernetes.Cluster, namespace string) (models.AppList, error) {
+	apps, _, err := ListPage(ctx, cluster, namespace, listing.Options{})
+	return apps, err
+}
+
+// ListPage returns the page of the apps in the specified namespace, or across all namespaces
+// for the empty string, selected by the options, and the number of matching apps. Only the
+// apps of the page are looked up.
+func ListPage(ctx context.Context, cluster *kubernetes.Cluster, namespace string, options listing.Options) (models.AppList, int, error) {
 
 	// Verify namespace, if specified
 
 	if namespace != "" {
 		exists, err := namespaces.Exists(ctx, cluster, namespace)
 		if err != nil {
-			return models.AppList{}, err
+			return models.AppList{}, 0, err
 		}
 		if !exists {
-			return models.AppList{}, epinioerrors.NamespaceMissingError{Namespace: namespace}
+			return models.AppList{}, 0, epinioerrors.NamespaceMissingError{Namespace: namespace}
 		}
 	}
 
-	// Get references for all apps, deployed or not
+	// Get the resources of all apps, deployed or not, and select the page
+
+	client, err := cluster.ClientApp()
+	if err != nil {
+		return models.AppList{}, 0, err
+	}
+
+	list, err := client.Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return models.AppList{}, 0, err
+	}
+
+	items := make([]listing.Item, 0, len(list.Items))
+	for _, app := range list.Items {
+		items = append(items, listing.Item{
+			Name:      app.GetName(),
+			Namespace: app.GetNamespace(),
+			CreatedAt: app.GetCreationTimestamp().Time,
+			Labels:    app.GetLabels(),
+		})
+	}
 
-	appRefs, err := ListAppRefs(ctx, cluster, namespace)
+	page, total, err := options.Select(items)
 	if err != nil {
-		return models.AppList{}, err
+		return models.AppList{}, 0, err
 	}
 
-	// Convert references to full application structures
+	// Convert the page to full application structures
 
 	result := models.AppList{}
 
-	for _, ref := range appRefs {
-		app, err := Lookup(ctx, cluster, ref.Namespace, ref.Name)
+	for _, i := range page {
+		app, err := Lookup(ctx, cluster, items[i].Namespace, items[i].Name)
 		if err != nil {
-			return result, err
+			return result, total, err
 		}
 		if app != nil {
 			result = append(result, *app)
 		}
 	}
 
-	return result, nil
+	return result, total, nil
 }
 
 // Delete removes the named application, its workload (if active), bindings (if any),
-// the stored application sources, and any staging jobs from when the application was
-// staged (if active). Waits for the application's deployment's pods to disappear
-// (if active).
+// the stored application sources and cached source chunks, and any staging jobs from
+// when the application was staged (if active). Waits for the application's
+// deployment's pods to disappear (if active).
 func Delete(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) error {
 	client, err := cluster.ClientApp()
 	if err != nil {
@@ -239,11 +268,18 @@ func Delete(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppR
 	log := requestctx.Logger(ctx)
 
 	// Ignore `not found` errors - App exists, without workload.
-	err = helm.Remove(cluster, log, appRef)
+	err = helm.Remove(ctx, cluster, log, appRef)
 	if err != nil && !strings.Contains(err.Error(), "release: not found") {
 		return err
 	}
 
+	// The image pull credentials are owned by the application resource, but
+	// attached to the service account of the namespace.
+	err = pullSecretDetach(ctx, cluster, appRef)
+	if err != nil {
+		return err
+	}
+
 	// Keep existing code to remove the CRD and everything it
 	// owns.  Only the workload resources needed their own removal
 	// to ensure that helm information stays consistent.
@@ -261,6 +297,12 @@ func Delete(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppR
 		return err
 	}
 
+	// delete the cached chunks of sources uploaded as delta
+	err = SourceChunksDelete(ctx, cluster, appRef)
+	if err != nil {
+		return err
+	}
+
 	// delete staging PVC (the one that holds the "source" and "cache" workspaces)
 	err = deleteStagePVC(ctx, cluster, appRef)
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -296,6 +338,18 @@ func AppChart(app *unstructured.Unstructured) (string, error) {
 	return chartName, nil
 }
 
+// BuilderImage returns the builder image used by the last staging, if one exists. It
+// returns an empty string otherwise. The information is pulled out of the app resource
+// itself, saved there by the staging endpoint.
+func BuilderImage(app *unstructured.Unstructured) (string, error) {
+	builderImage, _, err := unstructured.NestedString(app.UnstructuredContent(), "spec", "builderimage")
+	if err != nil {
+		return "", errors.New("builderimage should be string")
+	}
+
+	return builderImage, nil
+}
+
 // StageID returns the stage ID of the last attempt at staging, if one exists. It returns
 // an empty string otherwise. The information is pulled out of the app resource itself,
 // saved there by the staging endpoint. Note that success/failure of staging is immaterial
@@ -323,7 +377,8 @@ func ImageURL(app *unstructured.Unstructured) (string, error) {
 
 // Unstage removes staging resources. It deletes either all Jobs of the
 // named application, or all but stageIDCurrent. It also deletes the staged
-// objects from the S3 storage except for the current one.
+// objects from the S3 storage except for the current one. The logs of completed
+// jobs are archived first, if that did not happen already.
 func Unstage(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, stageIDCurrent string) error {
 	s3ConnectionDetails, err := s3manager.GetConnectionDetails(ctx, cluster,
 		helmchart.Namespace(), helmchart.S3ConnectionDetailsSecretName)
@@ -352,6 +407,10 @@ func Unstage(ctx context.Context, cluster *kubernetes.Cluster, appRef models.App
 			continue
 		}
 
+		if jobDone(job) {
+			archiveStagingLogs(ctx, cluster, appRef.Namespace, id)
+		}
+
 		err := cluster.DeleteJob(ctx, job.ObjectMeta.Namespace, job.ObjectMeta.Name)
 		if err != nil {
 			return err
@@ -383,8 +442,9 @@ func Unstage(ctx context.Context, cluster *kubernetes.Cluster, appRef models.App
 // the logging with the ctx cancelFunc. It's also the callers responsibility
 // to close the logChan when done.
 // When stageID is an empty string, no staging logs are returned. If it is set,
-// then only logs from that staging process are returned.
-func Logs(ctx context.Context, logChan chan tailer.ContainerLogLine, wg *sync.WaitGroup, cluster *kubernetes.Cluster, follow bool, app, stageID, namespace string) error {
+// then only logs from that staging process are returned. The parameters restrict
+// the returned lines further, see models.LogParameters.
+func Logs(ctx context.Context, logChan chan tailer.ContainerLogLine, wg *sync.WaitGroup, cluster *kubernetes.Cluster, follow bool, app, stageID, namespace string, params models.LogParameters) error {
 	logger := requestctx.Logger(ctx).WithName("logs-backend").V(2)
 	selector := labels.NewSelector()
 
@@ -411,19 +471,38 @@ func Logs(ctx context.Context, logChan chan tailer.ContainerLogLine, wg *sync.Wa
 		selector = selector.Add(*req)
 	}
 
+	podQuery := regexp.MustCompile(".*")
+	if params.Instance != "" {
+		podQuery = regexp.MustCompile("^" + regexp.QuoteMeta(params.Instance) + "$")
+	}
+
+	var include []*regexp.Regexp
+	if params.Filter != "" {
+		filter, err := regexp.Compile(params.Filter)
+		if err != nil {
+			return errors.Wrap(err, "bad filter")
+		}
+		include = []*regexp.Regexp{filter}
+	}
+
+	since := duration.LogHistory()
+	if params.Since > 0 {
+		since = params.Since
+	}
+
 	config := &tailer.Config{
 		ContainerQuery:        regexp.MustCompile(".*"),
 		ExcludeContainerQuery: regexp.MustCompile("linkerd-(proxy|init)"),
 		ContainerState:        "running",
 		Exclude:               nil,
-		Include:               nil,
+		Include:               include,
 		Timestamps:            false,
-		Since:                 duration.LogHistory(),
+		Since:                 since,
 		AllNamespaces:         true,
 		LabelSelector:         selector,
-		TailLines:             nil,
+		TailLines:             params.Tail,
 		Namespace:             "",
-		PodQuery:              regexp.MustCompile(".*"),
+		PodQuery:              podQuery,
 	}
 
 	if stageID != "" {
@@ -479,16 +558,96 @@ func fetch(ctx context.Context, cluster *kubernetes.Cluster, app *models.App) er
 		return errors.Wrap(err, "finding scaling")
 	}
 
+	rollout, err := Rollout(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding rollout")
+	}
+
+	tasks, err := Tasks(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding tasks")
+	}
+
+	processes, err := Processes(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding processes")
+	}
+
+	sidecars, err := Sidecars(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding sidecars")
+	}
+
+	migration, err := Migration(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding migration")
+	}
+
+	hooks, err := Hooks(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding hooks")
+	}
+
+	volumes, err := Volumes(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding volumes")
+	}
+
 	configurations, err := BoundConfigurationNames(ctx, cluster, app.Meta)
 	if err != nil {
 		return errors.Wrap(err, "finding configurations")
 	}
 
+	configurationPaths, err := BoundConfigurationPaths(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding configuration paths")
+	}
+
+	routeAnnotations, err := RouteAnnotations(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding route annotations")
+	}
+
+	autoSleep, err := AutoSleep(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding auto sleep")
+	}
+
+	ports, err := Ports(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding ports")
+	}
+
+	placement, err := Placement(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding placement")
+	}
+
+	termination, err := Termination(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding termination")
+	}
+
+	entrypoint, err := Entrypoint(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding entrypoint")
+	}
+
 	chartName, err := AppChart(applicationCR)
 	if err != nil {
 		return errors.Wrap(err, "finding app chart")
 	}
 
+	chartValues, err := ChartValues(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding chart values")
+	}
+
+	pullSecret, err := PullSecret(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding pull secret")
+	}
+
 	stageID, err := StageID(applicationCR)
 	if err != nil {
 		return errors.Wrap(err, "finding the stage id")
@@ -499,16 +658,45 @@ func fetch(ctx context.Context, cluster *kubernetes.Cluster, app *models.App) er
 		return errors.Wrap(err, "finding the image url")
 	}
 
+	staging, err := Staging(ctx, cluster, app.Meta)
+	if err != nil {
+		return errors.Wrap(err, "finding the staging settings")
+	}
+
+	staging.Builder, err = BuilderImage(applicationCR)
+	if err != nil {
+		return errors.Wrap(err, "finding the builder image")
+	}
+
 	app.Meta.CreatedAt = applicationCR.GetCreationTimestamp()
 
 	app.Configuration.Instances = &instances
+	app.Configuration.Rollout = rollout
+	app.Configuration.Tasks = tasks
+	app.Configuration.Processes = processes
+	app.Configuration.Sidecars = sidecars
+	app.Configuration.Migration = migration
+	app.Configuration.Hooks = hooks
+	app.Configuration.Volumes = volumes
 	app.Configuration.Configurations = configurations
+	app.Configuration.ConfigurationPaths = configurationPaths
 	app.Configuration.Environment = environment
 	app.Configuration.Routes = desiredRoutes
+	app.Configuration.RouteAnnotations = routeAnnotations
+	if autoSleep > 0 {
+		app.Configuration.AutoSleep = &autoSleep
+	}
+	app.Configuration.Ports = ports
+	app.Configuration.Placement = placement
+	app.Configuration.Termination = termination
+	app.Configuration.Entrypoint = entrypoint
 	app.Configuration.AppChart = chartName
+	app.Configuration.ChartValues = chartValues
+	app.Configuration.PullSecret = pullSecret
 	app.Origin = origin
 	app.StageID = stageID
 	app.ImageURL = imageURL
+	app.Staging = staging
 
 	// Check if app is active, and if yes, fill the associated parts.
 	// May have to straighten the workload structure a bit further.
@@ -525,6 +713,7 @@ func fetch(ctx context.Context, cluster *kubernetes.Cluster, app *models.App) er
 //- If there is an active staging job, app is: ApplicationStaging
 //- If there is no active staging job and no workload, app is: ApplicationCreated
 //- If there is no active staging job and a workload, app is: ApplicationRunning
+//- If the workload is put to sleep, app is: ApplicationSleeping
 func calculateStatus(ctx context.Context, cluster *kubernetes.Cluster, app *models.App) error {
 	if app.Status == models.ApplicationError {
 		return nil
@@ -542,6 +731,15 @@ func calculateStatus(ctx context.Context, cluster *kubernetes.Cluster, app *mode
 		return nil
 	}
 
+	sleeping, err := Sleeping(ctx, cluster, app.Meta)
+	if err != nil {
+		return err
+	}
+	if sleeping {
+		app.Status = models.ApplicationSleeping
+		return nil
+	}
+
 	app.Status = models.ApplicationRunning
 
 	return nil
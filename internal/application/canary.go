@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	canaryKey = "canary"
+
+	// CanaryWindow is the time in minutes over which the error rate of a canary is
+	// measured, and CanaryMinRequests the number of requests it has to get in that time
+	// for the rate to be judged.
+	CanaryWindow      = 5
+	CanaryMinRequests = 20
+
+	// Requests to the ingresses of an application routed to its canary by the ingress
+	// controller, optionally restricted to the server errors. See prometheusRequestsQuery
+	// for the namespace labels.
+	prometheusCanaryRequestsQuery = `sum(increase(nginx_ingress_controller_requests{exported_namespace="%[1]s",ingress=~"%[2]s",canary!=""%[4]s}[%[3]dm]))` +
+		` or sum(increase(nginx_ingress_controller_requests{namespace="%[1]s",ingress=~"%[2]s",canary!=""%[4]s}[%[3]dm]))`
+	prometheusServerErrors = `,status=~"5.."`
+)
+
+// Canary returns the canary deployment of the application, or nil, if there is none.
+func Canary(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppCanary, error) {
+	canarySecret, err := cluster.GetSecret(ctx, appRef.Namespace, appRef.MakeCanarySecretName())
+	if err != nil {
+		// Applications without canary have no canary secret either. Reading is not
+		// reason enough to create it.
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "error getting secret %s", appRef.MakeCanarySecretName())
+	}
+
+	encoded, ok := canarySecret.Data[canaryKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var canary models.AppCanary
+	if err := json.Unmarshal(encoded, &canary); err != nil {
+		return nil, errors.Wrap(err, "bad canary")
+	}
+
+	return &canary, nil
+}
+
+// CanarySet saves the canary deployment of the named application. A nil canary removes
+// the saved deployment. When the function returns the change is saved.
+func CanarySet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, canary *models.AppCanary) error {
+	var encoded []byte
+	if canary != nil {
+		var err error
+		encoded, err = json.Marshal(canary)
+		if err != nil {
+			return err
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if encoded == nil {
+			// Nothing to remove when there is no canary secret.
+			_, err := cluster.GetSecret(ctx, appRef.Namespace, appRef.MakeCanarySecretName())
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+		}
+
+		canarySecret, err := canaryLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if canarySecret.Data == nil {
+			canarySecret.Data = make(map[string][]byte)
+		}
+
+		if encoded == nil {
+			delete(canarySecret.Data, canaryKey)
+		} else {
+			canarySecret.Data[canaryKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, canarySecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateErrorThreshold checks that the error threshold of a canary is a percentage, and that
+// the error rate of the canary is known, i.e. that a prometheus server is configured. Zero
+// disables the automatic rollback.
+func ValidateErrorThreshold(threshold int) error {
+	if threshold < 0 || threshold > 100 {
+		return errors.New("error threshold param should be integer between 0 and 100")
+	}
+	if threshold > 0 && viper.GetString("prometheus-url") == "" {
+		return errors.New("error threshold requires a prometheus server recording the ingress traffic")
+	}
+	return nil
+}
+
+// CanaryFailing returns true if the application has a canary with error threshold, whose
+// error rate in the last CanaryWindow minutes reached that threshold. It returns the rate, in
+// percent, as well.
+func CanaryFailing(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (bool, float64, error) {
+	prometheusURL := viper.GetString("prometheus-url")
+	if prometheusURL == "" {
+		return false, 0, nil
+	}
+
+	canary, err := Canary(ctx, cluster, appRef)
+	if err != nil || canary == nil || canary.ErrorThreshold <= 0 {
+		return false, 0, err
+	}
+
+	ingresses, err := ingressListForApp(ctx, cluster, appRef)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(ingresses.Items) == 0 {
+		// Without routes the canary gets no traffic to judge it by.
+		return false, 0, nil
+	}
+
+	names := []string{}
+	for _, ingress := range ingresses.Items {
+		names = append(names, regexp.QuoteMeta(ingress.Name))
+	}
+
+	requests, err := prometheusQuery(ctx, prometheusURL, CanaryRequestsQuery(appRef.Namespace, names, CanaryWindow, false), "")
+	if err != nil {
+		return false, 0, err
+	}
+	failed, err := prometheusQuery(ctx, prometheusURL, CanaryRequestsQuery(appRef.Namespace, names, CanaryWindow, true), "")
+	if err != nil {
+		return false, 0, err
+	}
+
+	failing, rate := CanaryThresholdReached(canary.ErrorThreshold, failed[""], requests[""])
+	return failing, rate, nil
+}
+
+// CanaryThresholdReached returns true if the error rate of the failed requests out of all
+// requests, in percent, reached the threshold, and returns the rate. Too few requests are
+// not judged.
+func CanaryThresholdReached(threshold int, failed, requests float64) (bool, float64) {
+	if requests < CanaryMinRequests {
+		return false, 0
+	}
+	rate := failed * 100 / requests
+	return threshold > 0 && rate >= float64(threshold), rate
+}
+
+// CanaryRequestsQuery returns the prometheus query for the number of requests to the
+// ingresses in the namespace routed to the canary in the last minutes, or of those failing
+// with a server error. The ingresses are regular expressions.
+func CanaryRequestsQuery(namespace string, ingresses []string, minutes int, serverErrors bool) string {
+	status := ""
+	if serverErrors {
+		status = prometheusServerErrors
+	}
+	return fmt.Sprintf(prometheusCanaryRequestsQuery, namespace, strings.Join(ingresses, "|"), minutes, status)
+}
+
+// canaryLoad locates and returns the kube secret storing the referenced application's canary
+// deployment. If necessary it creates that secret.
+func canaryLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeCanarySecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "canary")
+}
@@ -0,0 +1,72 @@
+package application
+
+import (
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Canary", func() {
+	Describe("CanaryRequestsQuery", func() {
+		It("sums the requests routed to the canary", func() {
+			query := CanaryRequestsQuery("workspace", []string{"r1", "r2"}, 5, false)
+			Expect(query).To(Equal(
+				`sum(increase(nginx_ingress_controller_requests{exported_namespace="workspace",ingress=~"r1|r2",canary!=""}[5m]))` +
+					` or sum(increase(nginx_ingress_controller_requests{namespace="workspace",ingress=~"r1|r2",canary!=""}[5m]))`))
+		})
+
+		It("sums the server errors of the canary", func() {
+			query := CanaryRequestsQuery("workspace", []string{"r1"}, 5, true)
+			Expect(query).To(Equal(
+				`sum(increase(nginx_ingress_controller_requests{exported_namespace="workspace",ingress=~"r1",canary!="",status=~"5.."}[5m]))` +
+					` or sum(increase(nginx_ingress_controller_requests{namespace="workspace",ingress=~"r1",canary!="",status=~"5.."}[5m]))`))
+		})
+	})
+
+	Describe("CanaryThresholdReached", func() {
+		It("fails canaries at, or above, their threshold", func() {
+			failing, rate := CanaryThresholdReached(10, 10, 100)
+			Expect(failing).To(BeTrue())
+			Expect(rate).To(Equal(float64(10)))
+
+			failing, _ = CanaryThresholdReached(10, 30, 100)
+			Expect(failing).To(BeTrue())
+		})
+
+		It("keeps canaries below their threshold", func() {
+			failing, rate := CanaryThresholdReached(10, 9, 100)
+			Expect(failing).To(BeFalse())
+			Expect(rate).To(Equal(float64(9)))
+		})
+
+		It("does not judge canaries with too few requests", func() {
+			failing, _ := CanaryThresholdReached(10, CanaryMinRequests-1, CanaryMinRequests-1)
+			Expect(failing).To(BeFalse())
+		})
+
+		It("never fails canaries without threshold", func() {
+			failing, _ := CanaryThresholdReached(0, 100, 100)
+			Expect(failing).To(BeFalse())
+		})
+	})
+
+	Describe("ValidateErrorThreshold", func() {
+		AfterEach(func() {
+			viper.Set("prometheus-url", "")
+		})
+
+		It("rejects thresholds which are not percentages", func() {
+			Expect(ValidateErrorThreshold(-1)).To(MatchError(ContainSubstring("between 0 and 100")))
+			Expect(ValidateErrorThreshold(101)).To(MatchError(ContainSubstring("between 0 and 100")))
+		})
+
+		It("requires a prometheus server", func() {
+			Expect(ValidateErrorThreshold(0)).To(Succeed())
+			Expect(ValidateErrorThreshold(10)).To(MatchError(ContainSubstring("prometheus")))
+
+			viper.Set("prometheus-url", "http://prometheus")
+			Expect(ValidateErrorThreshold(10)).To(Succeed())
+		})
+	})
+})
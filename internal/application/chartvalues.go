@@ -0,0 +1,101 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	chartValuesKey = "chartvalues"
+)
+
+// ChartValues returns the values the user passes into the app chart of the application.
+func ChartValues(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (map[string]string, error) {
+	valuesSecret, err := chartValuesLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := valuesSecret.Data[chartValuesKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(encoded, &values); err != nil {
+		return nil, errors.Wrap(err, "bad chart values")
+	}
+
+	return values, nil
+}
+
+// ChartValuesSet merges the changes into the values passed into the app chart of the named
+// application. An empty value removes its setting. When the function returns the values are
+// saved.
+func ChartValuesSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, changes map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		valuesSecret, err := chartValuesLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		values := map[string]string{}
+		if encoded, ok := valuesSecret.Data[chartValuesKey]; ok {
+			if err := json.Unmarshal(encoded, &values); err != nil {
+				return errors.Wrap(err, "bad chart values")
+			}
+		}
+		values = MergeChartValues(values, changes)
+
+		encoded, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+
+		if valuesSecret.Data == nil {
+			valuesSecret.Data = make(map[string][]byte)
+		}
+
+		if len(values) == 0 {
+			delete(valuesSecret.Data, chartValuesKey)
+		} else {
+			valuesSecret.Data[chartValuesKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, valuesSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// MergeChartValues returns the values with the changes applied. An empty value removes its
+// setting.
+func MergeChartValues(values, changes map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range values {
+		result[key] = value
+	}
+	for key, value := range changes {
+		if value == "" {
+			delete(result, key)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// chartValuesLoad locates and returns the kube secret storing the referenced application's
+// chart values. If necessary it creates that secret.
+func chartValuesLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeChartValuesSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "chartvalues")
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -199,19 +200,79 @@ func BoundConfigurationNames(ctx context.Context, cluster *kubernetes.Cluster, a
 
 // BoundConfigurationsSet replaces or adds the specified configuration names to the named application.
 // When the function returns the configuration set will be extended.
-// Adding a known configuration is a no-op.
+// Adding a known configuration is a no-op. Configurations staying bound keep their path.
 func BoundConfigurationsSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, configurationNames []string, replace bool) error {
 	return svcUpdate(ctx, cluster, appRef, func(svcSecret *v1.Secret) {
+		old := svcSecret.Data
 		// Replacement is adding to a clear structure
 		if replace {
 			svcSecret.Data = make(map[string][]byte)
 		}
 		for _, configurationName := range configurationNames {
-			svcSecret.Data[configurationName] = nil
+			svcSecret.Data[configurationName] = old[configurationName]
 		}
 	})
 }
 
+// BoundConfigurationPaths returns the directories the keys of the bound configurations are
+// projected into, for the configurations not using the default directory.
+func BoundConfigurationPaths(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (map[string]string, error) {
+	svcSecret, err := svcLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]string
+	for name, path := range svcSecret.Data {
+		if len(path) == 0 {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[name] = string(path)
+	}
+
+	return result, nil
+}
+
+// BoundConfigurationPathsSet merges the given directories for the projection of the bound
+// configurations into the existing ones. An empty path restores the default directory.
+// Configurations which are not bound are ignored.
+func BoundConfigurationPathsSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, paths map[string]string) error {
+	return svcUpdate(ctx, cluster, appRef, func(svcSecret *v1.Secret) {
+		for name, path := range paths {
+			if _, ok := svcSecret.Data[name]; !ok {
+				continue
+			}
+			if path == "" {
+				svcSecret.Data[name] = nil
+			} else {
+				svcSecret.Data[name] = []byte(path)
+			}
+		}
+	})
+}
+
+// ValidateConfigurationPaths checks that the paths are for configurations bound to the
+// application, and absolute, or empty for the default.
+func ValidateConfigurationPaths(paths map[string]string, configurationNames []string) error {
+	bound := map[string]struct{}{}
+	for _, name := range configurationNames {
+		bound[name] = struct{}{}
+	}
+
+	for name, path := range paths {
+		if _, ok := bound[name]; !ok {
+			return fmt.Errorf("configuration '%s' has a path, but is not bound to the application", name)
+		}
+		if path != "" && !filepath.IsAbs(path) {
+			return fmt.Errorf("configuration '%s' is projected to '%s', expected an absolute path", name, path)
+		}
+	}
+	return nil
+}
+
 // BoundConfigurationsUnset removes the specified configuration name from the named application.
 // When the function returns the configuration set will be shrunk.
 // Removing an unknown configuration is a no-op.
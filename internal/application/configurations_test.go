@@ -0,0 +1,29 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateConfigurationPaths", func() {
+	bound := []string{"tls", "gcp-account"}
+
+	It("accepts absolute paths, and defaults, for bound configurations", func() {
+		Expect(application.ValidateConfigurationPaths(map[string]string{
+			"tls":         "/etc/tls",
+			"gcp-account": "",
+		}, bound)).To(Succeed())
+	})
+
+	It("rejects configurations which are not bound", func() {
+		err := application.ValidateConfigurationPaths(map[string]string{"db": "/etc/db"}, bound)
+		Expect(err).To(MatchError(ContainSubstring("configuration 'db' has a path, but is not bound")))
+	})
+
+	It("rejects relative paths", func() {
+		err := application.ValidateConfigurationPaths(map[string]string{"tls": "etc/tls"}, bound)
+		Expect(err).To(MatchError(ContainSubstring("expected an absolute path")))
+	})
+})
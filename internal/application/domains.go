@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/helpers/randstr"
+	"github.com/epinio/epinio/internal/routes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	domainsKey = "domains"
+)
+
+// Domains returns the custom domains of the application, ordered by domain.
+func Domains(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppDomain, error) {
+	domainsSecret, err := domainsLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := domainsSecret.Data[domainsKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var domains []models.AppDomain
+	if err := json.Unmarshal(encoded, &domains); err != nil {
+		return nil, errors.Wrap(err, "bad domains")
+	}
+
+	return domains, nil
+}
+
+// DomainsSet replaces the custom domains of the named application. When the function
+// returns the domains are saved.
+func DomainsSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, domains []models.AppDomain) error {
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Domain < domains[j].Domain })
+
+	encoded, err := json.Marshal(domains)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		domainsSecret, err := domainsLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if domainsSecret.Data == nil {
+			domainsSecret.Data = make(map[string][]byte)
+		}
+
+		if len(domains) == 0 {
+			delete(domainsSecret.Data, domainsKey)
+		} else {
+			domainsSecret.Data[domainsKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, domainsSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// NewDomain returns the unverified custom domain, with a fresh token for verifying its
+// ownership.
+func NewDomain(domain string) (models.AppDomain, error) {
+	token, err := randstr.Hex16()
+	if err != nil {
+		return models.AppDomain{}, errors.Wrap(err, "failed to generate a token")
+	}
+
+	return models.AppDomain{Domain: domain, Token: token}, nil
+}
+
+// ValidateDomain checks that the custom domain is a fully qualified, and not a wildcard,
+// DNS name.
+func ValidateDomain(domain string) error {
+	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+		return errors.Errorf("bad domain '%s': %s", domain, errs[0])
+	}
+	if !strings.Contains(domain, ".") {
+		return errors.Errorf("bad domain '%s': expected a fully qualified domain name", domain)
+	}
+	return nil
+}
+
+// DomainVerify returns true if the TXT record of the custom domain holds the domain's token,
+// proving that the user controls the domain. A missing record is not an error.
+func DomainVerify(ctx context.Context, domain models.AppDomain) (bool, error) {
+	records, err := net.DefaultResolver.LookupTXT(ctx, models.DomainChallengePrefix+domain.Domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "looking up the TXT record of domain '%s'", domain.Domain)
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) == domain.Token {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DomainStates returns the custom domains of the application, with the TXT records verifying
+// them, and the state of their certificates. A certificate is ready when the secret named by
+// the TLS section of the domain's ingress holds it.
+func DomainStates(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (models.AppDomainList, error) {
+	domains, err := Domains(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.AppDomainList{}
+	if len(domains) == 0 {
+		return result, nil
+	}
+
+	ingressList, err := ingressListForApp(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map the domains to the TLS secrets of their ingresses.
+	tlsSecrets := map[string]string{}
+	for _, ingress := range ingressList.Items {
+		route, err := routes.FromIngress(ingress)
+		if err != nil {
+			return nil, err
+		}
+		for _, tls := range ingress.Spec.TLS {
+			tlsSecrets[route.Domain] = tls.SecretName
+		}
+	}
+
+	noIssuer := viper.GetString("tls-issuer") == ""
+
+	for _, domain := range domains {
+		status := models.AppDomainStatus{
+			AppDomain:   domain,
+			Record:      models.DomainChallengePrefix + domain.Domain,
+			Certificate: models.DomainCertificatePending,
+		}
+
+		if noIssuer {
+			status.Certificate = models.DomainCertificateNone
+		} else if secretName, ok := tlsSecrets[domain.Domain]; ok && secretName != "" {
+			secret, err := cluster.GetSecret(ctx, appRef.Namespace, secretName)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			if err == nil && len(secret.Data[v1.TLSCertKey]) > 0 {
+				status.Certificate = models.DomainCertificateReady
+			}
+		}
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// domainsLoad locates and returns the kube secret storing the referenced application's custom
+// domains. If necessary it creates that secret.
+func domainsLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeDomainsSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "domains")
+}
@@ -0,0 +1,42 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Domains", func() {
+	Describe("ValidateDomain", func() {
+		It("accepts fully qualified domains", func() {
+			Expect(application.ValidateDomain("example.com")).To(Succeed())
+			Expect(application.ValidateDomain("shop.example.co.uk")).To(Succeed())
+		})
+
+		It("rejects single labels", func() {
+			err := application.ValidateDomain("localhost")
+			Expect(err).To(MatchError(ContainSubstring("expected a fully qualified domain name")))
+		})
+
+		It("rejects wildcards", func() {
+			err := application.ValidateDomain("*.example.com")
+			Expect(err).To(MatchError(ContainSubstring("bad domain '*.example.com'")))
+		})
+
+		It("rejects bad characters", func() {
+			err := application.ValidateDomain("exa_mple.com")
+			Expect(err).To(MatchError(ContainSubstring("bad domain 'exa_mple.com'")))
+		})
+	})
+
+	Describe("NewDomain", func() {
+		It("returns an unverified domain with a token", func() {
+			domain, err := application.NewDomain("example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(domain.Domain).To(Equal("example.com"))
+			Expect(domain.Token).ToNot(BeEmpty())
+			Expect(domain.Verified).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	entrypointKey = "entrypoint"
+)
+
+// Entrypoint returns the start command override of the application, or nil, if it runs the
+// image's default.
+func Entrypoint(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppEntrypoint, error) {
+	entrypointSecret, err := entrypointLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := entrypointSecret.Data[entrypointKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var entrypoint models.AppEntrypoint
+	if err := json.Unmarshal(encoded, &entrypoint); err != nil {
+		return nil, errors.Wrap(err, "bad entrypoint")
+	}
+
+	return &entrypoint, nil
+}
+
+// EntrypointSet merges the given start command override into the one of the named
+// application. Nil parts are kept, empty parts restore the image's default. When the function
+// returns the override is saved.
+func EntrypointSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, entrypoint models.AppEntrypoint) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		entrypointSecret, err := entrypointLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		var current models.AppEntrypoint
+		if encoded, ok := entrypointSecret.Data[entrypointKey]; ok {
+			if err := json.Unmarshal(encoded, &current); err != nil {
+				return errors.Wrap(err, "bad entrypoint")
+			}
+		}
+
+		merged := MergeEntrypoint(current, entrypoint)
+
+		if entrypointSecret.Data == nil {
+			entrypointSecret.Data = make(map[string][]byte)
+		}
+
+		if len(merged.Command) == 0 && len(merged.Args) == 0 {
+			delete(entrypointSecret.Data, entrypointKey)
+		} else {
+			encoded, err := json.Marshal(merged)
+			if err != nil {
+				return err
+			}
+			entrypointSecret.Data[entrypointKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, entrypointSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// MergeEntrypoint returns the current start command override with the changes applied. Nil
+// parts of the changes keep the current parts, empty parts remove them.
+func MergeEntrypoint(current, changes models.AppEntrypoint) models.AppEntrypoint {
+	result := current
+	if changes.Command != nil {
+		result.Command = changes.Command
+	}
+	if changes.Args != nil {
+		result.Args = changes.Args
+	}
+	if len(result.Command) == 0 {
+		result.Command = nil
+	}
+	if len(result.Args) == 0 {
+		result.Args = nil
+	}
+	return result
+}
+
+// ValidateEntrypoint checks that the command of the start command override, if any, names a
+// program.
+func ValidateEntrypoint(entrypoint models.AppEntrypoint) error {
+	if len(entrypoint.Command) > 0 && entrypoint.Command[0] == "" {
+		return errors.New("entrypoint command has no program")
+	}
+	return nil
+}
+
+// entrypointLoad locates and returns the kube secret storing the referenced application's
+// start command override. If necessary it creates that secret.
+func entrypointLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeEntrypointSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "entrypoint")
+}
@@ -0,0 +1,47 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Entrypoint", func() {
+	current := models.AppEntrypoint{
+		Command: []string{"bundle", "exec"},
+		Args:    []string{"puma"},
+	}
+
+	Describe("MergeEntrypoint", func() {
+		It("keeps the parts without changes", func() {
+			merged := application.MergeEntrypoint(current, models.AppEntrypoint{Args: []string{"sidekiq"}})
+			Expect(merged).To(Equal(models.AppEntrypoint{
+				Command: []string{"bundle", "exec"},
+				Args:    []string{"sidekiq"},
+			}))
+		})
+
+		It("restores the image's default for empty parts", func() {
+			merged := application.MergeEntrypoint(current, models.AppEntrypoint{Command: []string{}})
+			Expect(merged).To(Equal(models.AppEntrypoint{Args: []string{"puma"}}))
+
+			merged = application.MergeEntrypoint(current, models.AppEntrypoint{Command: []string{}, Args: []string{}})
+			Expect(merged).To(Equal(models.AppEntrypoint{}))
+		})
+	})
+
+	Describe("ValidateEntrypoint", func() {
+		It("accepts commands, and the restoring of the defaults", func() {
+			Expect(application.ValidateEntrypoint(current)).To(Succeed())
+			Expect(application.ValidateEntrypoint(models.AppEntrypoint{Args: []string{""}})).To(Succeed())
+			Expect(application.ValidateEntrypoint(models.AppEntrypoint{Command: []string{}})).To(Succeed())
+		})
+
+		It("rejects commands without program", func() {
+			err := application.ValidateEntrypoint(models.AppEntrypoint{Command: []string{"", "exec"}})
+			Expect(err).To(MatchError(ContainSubstring("has no program")))
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Events returns the events of the application's resources, ordered by time: the kube events
+// of instances, deployments, replica sets, and jobs in the application namespace, the kube
+// events of the staging jobs and their pods in the epinio namespace, and the conditions of
+// the deployments.
+func Events(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppEvent, error) {
+	result := []models.AppEvent{}
+
+	// The workload is selected by name only, as the app chart may not set part-of. The
+	// epinio namespace is shared by the stagings of all namespaces.
+	workloadSelector := labels.Set(map[string]string{
+		"app.kubernetes.io/name": appRef.Name,
+	}).AsSelector().String()
+	stagingSelector := labels.Set(map[string]string{
+		"app.kubernetes.io/name":    appRef.Name,
+		"app.kubernetes.io/part-of": appRef.Namespace,
+	}).AsSelector().String()
+
+	objects, conditions, err := workloadObjects(ctx, cluster, appRef.Namespace, workloadSelector)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, conditions...)
+
+	events, err := objectEvents(ctx, cluster, appRef.Namespace, objects)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, events...)
+
+	stagingObjects, err := jobObjects(ctx, cluster, helmchart.Namespace(), stagingSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err = objectEvents(ctx, cluster, helmchart.Namespace(), stagingObjects)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, events...)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(&result[j].Time)
+	})
+
+	return result, nil
+}
+
+// workloadObjects returns the kind/name of the selected pods, deployments, replica sets, and
+// jobs in the namespace, and the conditions of the deployments, as events.
+func workloadObjects(ctx context.Context, cluster *kubernetes.Cluster, namespace, selector string) (map[string]struct{}, []models.AppEvent, error) {
+	options := metav1.ListOptions{LabelSelector: selector}
+
+	objects, err := jobObjects(ctx, cluster, namespace, selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deployments, err := cluster.Kubectl.AppsV1().Deployments(namespace).List(ctx, options)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "listing deployments")
+	}
+
+	conditions := []models.AppEvent{}
+	for _, deployment := range deployments.Items {
+		object := "Deployment/" + deployment.Name
+		objects[object] = struct{}{}
+
+		for _, condition := range deployment.Status.Conditions {
+			eventType := corev1.EventTypeNormal
+			if condition.Status != corev1.ConditionTrue {
+				eventType = corev1.EventTypeWarning
+			}
+			conditions = append(conditions, models.AppEvent{
+				ID:      fmt.Sprintf("%s/%s/%s", object, condition.Type, condition.Status),
+				Time:    condition.LastUpdateTime,
+				Type:    eventType,
+				Object:  object,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+				Count:   1,
+			})
+		}
+	}
+
+	replicaSets, err := cluster.Kubectl.AppsV1().ReplicaSets(namespace).List(ctx, options)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "listing replica sets")
+	}
+	for _, replicaSet := range replicaSets.Items {
+		objects["ReplicaSet/"+replicaSet.Name] = struct{}{}
+	}
+
+	return objects, conditions, nil
+}
+
+// jobObjects returns the kind/name of the selected pods and jobs in the namespace.
+func jobObjects(ctx context.Context, cluster *kubernetes.Cluster, namespace, selector string) (map[string]struct{}, error) {
+	objects := map[string]struct{}{}
+
+	pods, err := cluster.ListPods(ctx, namespace, selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing pods")
+	}
+	for _, pod := range pods.Items {
+		objects["Pod/"+pod.Name] = struct{}{}
+	}
+
+	jobs, err := cluster.ListJobs(ctx, namespace, selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing jobs")
+	}
+	for _, job := range jobs.Items {
+		objects["Job/"+job.Name] = struct{}{}
+	}
+
+	return objects, nil
+}
+
+// objectEvents returns the kube events in the namespace which are about the objects.
+func objectEvents(ctx context.Context, cluster *kubernetes.Cluster, namespace string, objects map[string]struct{}) ([]models.AppEvent, error) {
+	result := []models.AppEvent{}
+	if len(objects) == 0 {
+		return result, nil
+	}
+
+	events, err := cluster.Kubectl.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing events")
+	}
+
+	for _, event := range events.Items {
+		object := event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+		if _, ok := objects[object]; !ok {
+			continue
+		}
+
+		result = append(result, models.AppEvent{
+			ID:      string(event.UID),
+			Time:    eventTime(event),
+			Type:    event.Type,
+			Object:  object,
+			Reason:  event.Reason,
+			Message: event.Message,
+			Count:   event.Count,
+		})
+	}
+
+	return result, nil
+}
+
+// eventTime returns the time the event last happened. Events of the newer events API only
+// have an event time.
+func eventTime(event corev1.Event) metav1.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp
+	}
+	if !event.EventTime.IsZero() {
+		return metav1.NewTime(event.EventTime.Time)
+	}
+	if !event.FirstTimestamp.IsZero() {
+		return event.FirstTimestamp
+	}
+	return event.CreationTimestamp
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	hooksKey = "hooks"
+
+	// hookComponent is the prefix of the component label of the jobs running the hooks,
+	// followed by the hook name.
+	hookComponent = "hook-"
+
+	// hookNameMax is the maximum length of hook names. The component label and the container
+	// name made from them have to be valid dns labels.
+	hookNameMax = 40
+)
+
+// Hooks returns the deployment hooks of the application, in the order of their declaration.
+func Hooks(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppHook, error) {
+	hooksSecret, err := hooksLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := hooksSecret.Data[hooksKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var hooks []models.AppHook
+	if err := json.Unmarshal(encoded, &hooks); err != nil {
+		return nil, errors.Wrap(err, "bad hooks")
+	}
+
+	return hooks, nil
+}
+
+// HooksSet replaces the deployment hooks of the named application. When the function returns
+// the hooks are saved.
+func HooksSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, hooks []models.AppHook) error {
+	encoded, err := json.Marshal(hooks)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		hooksSecret, err := hooksLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if hooksSecret.Data == nil {
+			hooksSecret.Data = make(map[string][]byte)
+		}
+
+		if len(hooks) == 0 {
+			delete(hooksSecret.Data, hooksKey)
+		} else {
+			hooksSecret.Data[hooksKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, hooksSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateHooks checks that the hooks have unique names usable in kube resource names, a
+// known phase, a command, and a proper timeout.
+func ValidateHooks(hooks []models.AppHook) error {
+	seen := map[string]struct{}{}
+	for _, hook := range hooks {
+		if errs := validation.IsDNS1123Label(hook.Name); len(errs) > 0 {
+			return errors.Errorf("bad hook name '%s': %s", hook.Name, errs[0])
+		}
+		if len(hook.Name) > hookNameMax {
+			return errors.Errorf("bad hook name '%s': must be no more than %d characters", hook.Name, hookNameMax)
+		}
+		if _, ok := seen[hook.Name]; ok {
+			return errors.Errorf("duplicate hook name '%s'", hook.Name)
+		}
+		seen[hook.Name] = struct{}{}
+
+		switch hook.Phase {
+		case models.HookPreDeploy, models.HookPostDeploy, models.HookPostRollback:
+		default:
+			return errors.Errorf("bad phase '%s' for hook '%s', expected one of pre-deploy, post-deploy, or post-rollback", hook.Phase, hook.Name)
+		}
+		if len(hook.Command) == 0 {
+			return errors.Errorf("hook '%s' has no command", hook.Name)
+		}
+		if hook.Timeout < 0 {
+			return errors.Errorf("timeout of hook '%s' should be >= 0", hook.Name)
+		}
+	}
+	return nil
+}
+
+// HooksRun runs the hooks of the given phase to completion, one after the other, in jobs
+// using the given image, environment and bound configurations. The run stops at the first
+// failed hook. It returns the results of the hooks run, and whether they all succeeded.
+func HooksRun(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef,
+	imageURL string, hooks []models.AppHook, phase string,
+	environment models.EnvVariableMap, configurations []string) ([]models.AppHookResult, bool, error) {
+
+	results := []models.AppHookResult{}
+	for _, hook := range hooks {
+		if hook.Phase != phase {
+			continue
+		}
+
+		ok, logs, err := runCommandJob(ctx, cluster, appRef, hookComponent+hook.Name, imageURL,
+			hook.Command, hook.Timeout, environment, configurations)
+		if err != nil {
+			return results, false, errors.Wrapf(err, "running hook '%s'", hook.Name)
+		}
+
+		result := models.AppHookResult{
+			Name:   hook.Name,
+			Phase:  hook.Phase,
+			Result: models.HookSucceeded,
+			Logs:   logs,
+		}
+		if !ok {
+			result.Result = models.HookFailed
+			return append(results, result), false, nil
+		}
+
+		results = append(results, result)
+	}
+
+	return results, true, nil
+}
+
+// hooksLoad locates and returns the kube secret storing the referenced application's
+// deployment hooks. If necessary it creates that secret.
+func hooksLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeHooksSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "hooks")
+}
@@ -0,0 +1,62 @@
+package application_test
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateHooks", func() {
+	It("accepts hooks of all phases", func() {
+		Expect(application.ValidateHooks([]models.AppHook{
+			{Name: "check", Phase: "pre-deploy", Command: []string{"bin/check"}},
+			{Name: "warmup", Phase: "post-deploy", Command: []string{"bin/warmup"}, Timeout: 60},
+			{Name: "notify", Phase: "post-rollback", Command: []string{"bin/notify", "rollback"}},
+		})).To(Succeed())
+	})
+
+	It("accepts the removal of all hooks", func() {
+		Expect(application.ValidateHooks([]models.AppHook{})).To(Succeed())
+	})
+
+	It("rejects bad and duplicate names", func() {
+		err := application.ValidateHooks([]models.AppHook{
+			{Name: "Check", Phase: "pre-deploy", Command: []string{"bin/check"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("bad hook name 'Check'")))
+
+		err = application.ValidateHooks([]models.AppHook{
+			{Name: strings.Repeat("a", 41), Phase: "pre-deploy", Command: []string{"bin/check"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("must be no more than 40 characters")))
+
+		err = application.ValidateHooks([]models.AppHook{
+			{Name: "check", Phase: "pre-deploy", Command: []string{"bin/check"}},
+			{Name: "check", Phase: "post-deploy", Command: []string{"bin/check"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("duplicate hook name 'check'")))
+	})
+
+	It("rejects unknown phases", func() {
+		err := application.ValidateHooks([]models.AppHook{
+			{Name: "check", Phase: "pre-stage", Command: []string{"bin/check"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("bad phase 'pre-stage' for hook 'check'")))
+	})
+
+	It("rejects hooks without command, or with negative timeout", func() {
+		err := application.ValidateHooks([]models.AppHook{
+			{Name: "check", Phase: "pre-deploy"},
+		})
+		Expect(err).To(MatchError(ContainSubstring("hook 'check' has no command")))
+
+		err = application.ValidateHooks([]models.AppHook{
+			{Name: "check", Phase: "pre-deploy", Command: []string{"bin/check"}, Timeout: -1},
+		})
+		Expect(err).To(MatchError(ContainSubstring("timeout of hook 'check' should be >= 0")))
+	})
+})
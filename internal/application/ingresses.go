@@ -2,17 +2,21 @@ package application
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/routes"
 	apierror "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // DesiredRoutes lists all desired routes for the given application
@@ -39,6 +43,53 @@ func DesiredRoutes(ctx context.Context, cluster *kubernetes.Cluster, appRef mode
 	return desiredRoutes, nil
 }
 
+// RoutesSet replaces the desired routes stored on the Application Custom Resource of the
+// referenced application. It does not deploy them.
+func RoutesSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, desiredRoutes []string) error {
+	client, err := cluster.ClientApp()
+	if err != nil {
+		return err
+	}
+
+	quoted := []string{}
+	for _, d := range desiredRoutes {
+		quoted = append(quoted, fmt.Sprintf("%q", d))
+	}
+
+	patch := fmt.Sprintf(`[{
+		"op": "replace",
+		"path": "/spec/routes",
+		"value": [%s] }]`,
+		strings.Join(quoted, ","))
+
+	_, err = client.Namespace(appRef.Namespace).Patch(ctx, appRef.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// ValidateRouteSecrets checks that the TLS secrets named by the routes exist in the namespace,
+// and hold certificates.
+func ValidateRouteSecrets(ctx context.Context, cluster *kubernetes.Cluster, namespace string, desiredRoutes []string) error {
+	for _, desired := range desiredRoutes {
+		route := routes.FromString(desired)
+		if route.TLSSecret == "" {
+			continue
+		}
+
+		secret, err := cluster.GetSecret(ctx, namespace, route.TLSSecret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return errors.Errorf("tls secret '%s' of route '%s' not found", route.TLSSecret, desired)
+			}
+			return err
+		}
+		if secret.Type != v1.SecretTypeTLS {
+			return errors.Errorf("secret '%s' of route '%s' has type '%s', expected '%s'",
+				route.TLSSecret, desired, secret.Type, v1.SecretTypeTLS)
+		}
+	}
+	return nil
+}
+
 // ListRoutes lists all (currently active) routes for the given application
 // The list is constructed from the actual Ingresses and not from the stored
 // information on the Application Custom Resource.
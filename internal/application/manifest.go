@@ -0,0 +1,247 @@
+package application
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// ManifestChanges returns the update turning the current configuration of an application into
+// the desired one, of a manifest, and the names of the changed settings, sorted. Unlike an
+// update request the manifest is complete, the settings it does not have are reset to their
+// defaults, i.e. one instance, no environment, no bindings, no tasks, and so on. The routes of
+// the manifest have to be resolved, i.e. not be empty.
+//
+// Volumes are merged by name, volumes missing from the manifest are kept, with their data.
+//
+// A pull secret with password is always a change, the saved password is not known.
+func ManifestChanges(current, desired models.ApplicationUpdateRequest) (models.ApplicationUpdateRequest, []string) {
+	changes := models.ApplicationUpdateRequest{}
+	changed := []string{}
+
+	instances, currentInstances := int32(1), int32(1)
+	if desired.Instances != nil {
+		instances = *desired.Instances
+	}
+	if current.Instances != nil {
+		currentInstances = *current.Instances
+	}
+	if instances != currentInstances {
+		changes.Instances = &instances
+		changed = append(changed, "instances")
+	}
+
+	if !sameList(current.Environment, desired.Environment) {
+		changes.Environment = models.EnvVariableMap{}
+		for name, value := range desired.Environment {
+			changes.Environment[name] = value
+		}
+		changed = append(changed, "environment")
+	}
+
+	if !sameSet(current.Configurations, desired.Configurations) {
+		changes.Configurations = append([]string{}, desired.Configurations...)
+		changed = append(changed, "configurations")
+	}
+
+	if !sameSet(current.Routes, desired.Routes) {
+		changes.Routes = desired.Routes
+		changed = append(changed, "routes")
+	}
+
+	if desired.AppChart != "" && desired.AppChart != current.AppChart {
+		changes.AppChart = desired.AppChart
+		changed = append(changed, "appchart")
+	}
+
+	if !samePointee(current.Rollout, desired.Rollout) {
+		rollout := models.AppRollout{}
+		if desired.Rollout != nil {
+			rollout = *desired.Rollout
+		}
+		changes.Rollout = &rollout
+		changed = append(changed, "rollout")
+	}
+
+	if !sameList(current.Tasks, desired.Tasks) {
+		changes.Tasks = append([]models.AppTask{}, desired.Tasks...)
+		changed = append(changed, "tasks")
+	}
+
+	processes := map[string]int32{}
+	for name, instances := range desired.Processes {
+		if current.Processes[name] != instances {
+			processes[name] = instances
+		}
+	}
+	for name, instances := range current.Processes {
+		if _, ok := desired.Processes[name]; !ok && instances != 0 {
+			processes[name] = 0
+		}
+	}
+	if len(processes) > 0 {
+		changes.Processes = processes
+		changed = append(changed, "processes")
+	}
+
+	if !sameList(current.Sidecars, desired.Sidecars) {
+		changes.Sidecars = append([]models.AppSidecar{}, desired.Sidecars...)
+		changed = append(changed, "sidecars")
+	}
+
+	if !samePointee(current.Migration, desired.Migration) {
+		migration := models.AppMigration{}
+		if desired.Migration != nil {
+			migration = *desired.Migration
+		}
+		changes.Migration = &migration
+		changed = append(changed, "migration")
+	}
+
+	if !sameList(current.Hooks, desired.Hooks) {
+		changes.Hooks = append([]models.AppHook{}, desired.Hooks...)
+		changed = append(changed, "hooks")
+	}
+
+	currentVolumes := map[string]models.AppVolume{}
+	for _, volume := range current.Volumes {
+		currentVolumes[volume.Name] = volume
+	}
+	for _, volume := range desired.Volumes {
+		if known, ok := currentVolumes[volume.Name]; !ok || !reflect.DeepEqual(known, volume) {
+			changes.Volumes = append(changes.Volumes, volume)
+		}
+	}
+	if len(changes.Volumes) > 0 {
+		changed = append(changed, "volumes")
+	}
+
+	if paths := mapChanges(current.ConfigurationPaths, desired.ConfigurationPaths); len(paths) > 0 {
+		changes.ConfigurationPaths = paths
+		changed = append(changed, "configurationpaths")
+	}
+
+	if !sameList(current.RouteAnnotations, desired.RouteAnnotations) {
+		changes.RouteAnnotations = models.AppRouteAnnotations{}
+		for route, annotations := range desired.RouteAnnotations {
+			changes.RouteAnnotations[route] = annotations
+		}
+		changed = append(changed, "routeannotations")
+	}
+
+	if !samePointee(current.AutoSleep, desired.AutoSleep) {
+		autoSleep := int32(0)
+		if desired.AutoSleep != nil {
+			autoSleep = *desired.AutoSleep
+		}
+		changes.AutoSleep = &autoSleep
+		changed = append(changed, "autosleep")
+	}
+
+	if !sameList(current.Ports, desired.Ports) {
+		changes.Ports = append([]models.AppPort{}, desired.Ports...)
+		changed = append(changed, "ports")
+	}
+
+	if !samePointee(current.Placement, desired.Placement) {
+		placement := models.AppPlacement{}
+		if desired.Placement != nil {
+			placement = *desired.Placement
+		}
+		changes.Placement = &placement
+		changed = append(changed, "placement")
+	}
+
+	if !samePointee(current.Termination, desired.Termination) {
+		termination := models.AppTermination{}
+		if desired.Termination != nil {
+			termination = *desired.Termination
+		}
+		changes.Termination = &termination
+		changed = append(changed, "termination")
+	}
+
+	if !samePointee(current.Entrypoint, desired.Entrypoint) {
+		entrypoint := models.AppEntrypoint{}
+		if desired.Entrypoint != nil {
+			entrypoint = *desired.Entrypoint
+		}
+		changes.Entrypoint = &entrypoint
+		changed = append(changed, "entrypoint")
+	}
+
+	if values := mapChanges(current.ChartValues, desired.ChartValues); len(values) > 0 {
+		changes.ChartValues = values
+		changed = append(changed, "chartvalues")
+	}
+
+	pullSecret, currentPullSecret := models.AppPullSecret{}, models.AppPullSecret{}
+	if desired.PullSecret != nil {
+		pullSecret = *desired.PullSecret
+	}
+	if current.PullSecret != nil {
+		currentPullSecret = *current.PullSecret
+	}
+	if pullSecret.Password != "" || pullSecret.Registry != currentPullSecret.Registry || pullSecret.Username != currentPullSecret.Username {
+		changes.PullSecret = &pullSecret
+		changed = append(changed, "pullsecret")
+	}
+
+	sort.Strings(changed)
+	return changes, changed
+}
+
+// samePointee returns true if the pointers point to the same value. Nil points to the zero
+// value.
+func samePointee(a, b interface{}) bool {
+	return reflect.DeepEqual(pointee(a), pointee(b))
+}
+
+// pointee returns the value the pointer points to, or the zero value of its type for nil.
+func pointee(pointer interface{}) interface{} {
+	value := reflect.ValueOf(pointer)
+	if value.IsNil() {
+		return reflect.Zero(value.Type().Elem()).Interface()
+	}
+	return value.Elem().Interface()
+}
+
+// sameSet returns true if the lists have the same elements, in any order.
+func sameSet(a, b []string) bool {
+	as := map[string]bool{}
+	for _, element := range a {
+		as[element] = true
+	}
+	bs := map[string]bool{}
+	for _, element := range b {
+		bs[element] = true
+	}
+	return reflect.DeepEqual(as, bs)
+}
+
+// sameList returns true if the lists, or maps, have the same elements. Nil and empty are
+// the same.
+func sameList(a, b interface{}) bool {
+	if reflect.ValueOf(a).Len() == 0 && reflect.ValueOf(b).Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// mapChanges returns the entries of the desired map which differ from the current one, and
+// the empty value for the entries it does not have, as expected by the merging settings.
+func mapChanges(current, desired map[string]string) map[string]string {
+	changes := map[string]string{}
+	for key, value := range desired {
+		if current[key] != value {
+			changes[key] = value
+		}
+	}
+	for key, value := range current {
+		if _, ok := desired[key]; !ok && value != "" {
+			changes[key] = ""
+		}
+	}
+	return changes
+}
@@ -0,0 +1,119 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ManifestChanges", func() {
+	var current models.ApplicationUpdateRequest
+
+	instances := func(n int32) *int32 { return &n }
+
+	BeforeEach(func() {
+		current = models.ApplicationUpdateRequest{
+			Instances:      instances(2),
+			Configurations: []string{"db", "cache"},
+			Environment:    models.EnvVariableMap{"MODE": "production"},
+			Routes:         []string{"app.example.com"},
+			AppChart:       "standard",
+			Tasks:          []models.AppTask{{Name: "report", Schedule: "@daily", Command: []string{"report"}}},
+			Processes:      map[string]int32{"worker": 1},
+			Volumes:        []models.AppVolume{{Name: "data", Path: "/data", Size: "1Gi"}},
+			ChartValues:    map[string]string{"tuning": "speed"},
+		}
+	})
+
+	It("changes nothing for the current configuration", func() {
+		changes, changed := application.ManifestChanges(current, current)
+		Expect(changed).To(BeEmpty())
+		Expect(changes).To(Equal(models.ApplicationUpdateRequest{}))
+	})
+
+	It("ignores the order of bindings and routes", func() {
+		desired := current
+		desired.Configurations = []string{"cache", "db"}
+		_, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(BeEmpty())
+	})
+
+	It("reports the changed settings, sorted", func() {
+		desired := current
+		desired.Instances = instances(3)
+		desired.Environment = models.EnvVariableMap{"MODE": "staging"}
+		desired.Routes = []string{"app.example.org"}
+
+		changes, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(Equal([]string{"environment", "instances", "routes"}))
+		Expect(*changes.Instances).To(Equal(int32(3)))
+		Expect(changes.Environment).To(Equal(models.EnvVariableMap{"MODE": "staging"}))
+		Expect(changes.Routes).To(Equal([]string{"app.example.org"}))
+		Expect(changes.Configurations).To(BeNil())
+		Expect(changes.Tasks).To(BeNil())
+	})
+
+	It("resets the settings missing from the manifest", func() {
+		desired := models.ApplicationUpdateRequest{
+			Routes:   []string{"app.example.com"},
+			AppChart: "standard",
+		}
+
+		changes, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(Equal([]string{"chartvalues", "configurations", "environment", "instances", "processes", "tasks"}))
+		Expect(*changes.Instances).To(Equal(int32(1)))
+		Expect(changes.Environment).To(BeEmpty())
+		Expect(changes.Environment).ToNot(BeNil())
+		Expect(changes.Configurations).To(BeEmpty())
+		Expect(changes.Configurations).ToNot(BeNil())
+		Expect(changes.Tasks).To(BeEmpty())
+		Expect(changes.Tasks).ToNot(BeNil())
+		Expect(changes.Processes).To(Equal(map[string]int32{"worker": 0}))
+		Expect(changes.ChartValues).To(Equal(map[string]string{"tuning": ""}))
+	})
+
+	It("keeps the app chart without one in the manifest", func() {
+		desired := current
+		desired.AppChart = ""
+		_, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(BeEmpty())
+	})
+
+	It("changes only the changed volumes, keeping the others", func() {
+		desired := current
+		desired.Volumes = []models.AppVolume{
+			{Name: "data", Path: "/data", Size: "1Gi"},
+			{Name: "cache", Path: "/cache", Size: "100Mi"},
+		}
+		changes, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(Equal([]string{"volumes"}))
+		Expect(changes.Volumes).To(Equal([]models.AppVolume{{Name: "cache", Path: "/cache", Size: "100Mi"}}))
+
+		desired.Volumes = nil
+		_, changed = application.ManifestChanges(current, desired)
+		Expect(changed).To(BeEmpty())
+	})
+
+	It("removes a migration missing from the manifest", func() {
+		current.Migration = &models.AppMigration{Command: []string{"migrate"}}
+		desired := current
+		desired.Migration = nil
+
+		changes, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(Equal([]string{"migration"}))
+		Expect(changes.Migration).To(Equal(&models.AppMigration{}))
+	})
+
+	It("always changes a pull secret with password", func() {
+		current.PullSecret = &models.AppPullSecret{Registry: "registry.example.com", Username: "ci"}
+		desired := current
+		_, changed := application.ManifestChanges(current, desired)
+		Expect(changed).To(BeEmpty())
+
+		desired.PullSecret = &models.AppPullSecret{Registry: "registry.example.com", Username: "ci", Password: "secret"}
+		_, changed = application.ManifestChanges(current, desired)
+		Expect(changed).To(Equal([]string{"pullsecret"}))
+	})
+})
@@ -0,0 +1,271 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/helpers/randstr"
+	"github.com/epinio/epinio/internal/duration"
+	"github.com/epinio/epinio/internal/names"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	migrationKey       = "migration"
+	migrationComponent = "migration"
+	commandLogLines    = 50
+)
+
+// Migration returns the pre-deploy migration of the application, or nil, if there is none.
+func Migration(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppMigration, error) {
+	migrationSecret, err := migrationLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := migrationSecret.Data[migrationKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var migration models.AppMigration
+	if err := json.Unmarshal(encoded, &migration); err != nil {
+		return nil, errors.Wrap(err, "bad migration")
+	}
+
+	return &migration, nil
+}
+
+// MigrationSet replaces the pre-deploy migration of the named application. A migration
+// without command removes it. When the function returns the migration is saved.
+func MigrationSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, migration models.AppMigration) error {
+	encoded, err := json.Marshal(migration)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		migrationSecret, err := migrationLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if migrationSecret.Data == nil {
+			migrationSecret.Data = make(map[string][]byte)
+		}
+
+		if len(migration.Command) == 0 {
+			delete(migrationSecret.Data, migrationKey)
+		} else {
+			migrationSecret.Data[migrationKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, migrationSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateMigration checks the timeout of the migration.
+func ValidateMigration(migration models.AppMigration) error {
+	if migration.Timeout < 0 {
+		return errors.New("migration timeout should be >= 0")
+	}
+	return nil
+}
+
+// MigrationRun runs the migration of the application to completion, in a job using the
+// given image, environment and bound configurations. The command is passed to the image's
+// entrypoint, i.e. the buildpack launcher for images built with buildpacks. The jobs of
+// previous migrations are removed. A failed migration is reported with the tail of its logs.
+func MigrationRun(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef,
+	imageURL string, migration models.AppMigration,
+	environment models.EnvVariableMap, configurations []string) error {
+
+	ok, logs, err := runCommandJob(ctx, cluster, appRef, migrationComponent, imageURL,
+		migration.Command, migration.Timeout, environment, configurations)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("migration failed:\n%s", logs)
+	}
+
+	return nil
+}
+
+// runCommandJob runs the command to completion, in a job of the given component, using the
+// image, environment and bound configurations of the application. The timeout is in
+// seconds, zero uses the deployment timeout. The jobs of previous runs of the component are
+// removed. It returns whether the command succeeded, and the tail of its logs.
+func runCommandJob(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef,
+	component, imageURL string, command []string, timeoutSeconds int64,
+	environment models.EnvVariableMap, configurations []string) (bool, string, error) {
+
+	selector := fmt.Sprintf("app.kubernetes.io/component=%s,app.kubernetes.io/name=%s,app.kubernetes.io/part-of=%s",
+		component, appRef.Name, appRef.Namespace)
+
+	previous, err := cluster.ListJobs(ctx, appRef.Namespace, selector)
+	if err != nil {
+		return false, "", errors.Wrapf(err, "listing the previous %s jobs", component)
+	}
+	for _, job := range previous.Items {
+		if err := cluster.DeleteJob(ctx, appRef.Namespace, job.Name); err != nil {
+			return false, "", errors.Wrapf(err, "removing a previous %s job", component)
+		}
+	}
+
+	id, err := randstr.Hex16()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to generate a uid")
+	}
+
+	timeout := duration.ToDeployment()
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	job := commandJob(appRef, component, names.GenerateResourceName(appRef.Name, component, id),
+		imageURL, command, environment, configurations, timeout)
+
+	// The job is not admitted to a restricted namespace unless it complies.
+	podSecurity, err := namespaces.PodSecurity(ctx, cluster, appRef.Namespace)
+	if err != nil {
+		return false, "", errors.Wrap(err, "getting the pod security level of the namespace")
+	}
+	if podSecurity == namespaces.PodSecurityRestricted {
+		namespaces.RestrictPodSpec(&job.Spec.Template.Spec)
+	}
+
+	if err := cluster.CreateJob(ctx, appRef.Namespace, job); err != nil {
+		return false, "", errors.Wrapf(err, "creating the %s job", component)
+	}
+
+	// The job deadline ends a hanging command, the wait includes a grace period for
+	// kubernetes to notice.
+	err = cluster.WaitForJobDone(ctx, appRef.Namespace, job.Name, timeout+time.Minute)
+	if err != nil {
+		return false, "", errors.Wrapf(err, "waiting for the %s", component)
+	}
+
+	failed, err := cluster.IsJobFailed(ctx, job.Name, appRef.Namespace)
+	if err != nil {
+		return false, "", errors.Wrapf(err, "checking the %s result", component)
+	}
+
+	return !failed, commandJobLogs(ctx, cluster, appRef.Namespace, job.Name, component), nil
+}
+
+// commandJob returns the job running the command of the given component of the application.
+func commandJob(appRef models.AppRef, component, jobName, imageURL string, command []string,
+	environment models.EnvVariableMap, configurations []string, timeout time.Duration) *batchv1.Job {
+
+	labels := map[string]string{
+		"app.kubernetes.io/name":       appRef.Name,
+		"app.kubernetes.io/part-of":    appRef.Namespace,
+		"app.kubernetes.io/managed-by": "epinio",
+		"app.kubernetes.io/component":  component,
+	}
+
+	env := []v1.EnvVar{}
+	for _, ev := range environment.List() {
+		env = append(env, v1.EnvVar{Name: ev.Name, Value: ev.Value})
+	}
+
+	// Configurations are mounted as done by the app chart.
+	volumes := []v1.Volume{}
+	mounts := []v1.VolumeMount{}
+	for _, configuration := range configurations {
+		volumes = append(volumes, v1.Volume{
+			Name: configuration,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: configuration,
+				},
+			},
+		})
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      configuration,
+			ReadOnly:  true,
+			MountPath: "/configurations/" + configuration,
+		})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   jobName,
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32(0),
+			ActiveDeadlineSeconds: pointer.Int64(int64(timeout.Seconds())),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					ServiceAccountName: appRef.Namespace,
+					Containers: []v1.Container{
+						{
+							Name:         component,
+							Image:        imageURL,
+							Args:         command,
+							Env:          env,
+							VolumeMounts: mounts,
+						},
+					},
+					RestartPolicy: v1.RestartPolicyNever,
+					Volumes:       volumes,
+				},
+			},
+		},
+	}
+}
+
+// commandJobLogs returns the tail of the logs of the named job, for reporting. Problems
+// retrieving the logs are reported in their place.
+func commandJobLogs(ctx context.Context, cluster *kubernetes.Cluster, namespace, jobName, container string) string {
+	pods, err := cluster.ListPods(ctx, namespace, "job-name="+jobName)
+	if err != nil {
+		return fmt.Sprintf("(no logs: %s)", err)
+	}
+	if len(pods.Items) == 0 {
+		return "(no logs: no pod)"
+	}
+
+	stream, err := cluster.Kubectl.CoreV1().Pods(namespace).GetLogs(pods.Items[0].Name, &v1.PodLogOptions{
+		Container: container,
+		TailLines: pointer.Int64(commandLogLines),
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Sprintf("(no logs: %s)", err)
+	}
+	defer stream.Close()
+
+	var logs bytes.Buffer
+	if _, err := io.Copy(&logs, stream); err != nil {
+		return fmt.Sprintf("(no logs: %s)", err)
+	}
+
+	return logs.String()
+}
+
+// migrationLoad locates and returns the kube secret storing the referenced application's
+// pre-deploy migration. If necessary it creates that secret.
+func migrationLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeMigrationSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "migration")
+}
@@ -0,0 +1,29 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateMigration", func() {
+	It("accepts a migration with default timeout", func() {
+		Expect(application.ValidateMigration(models.AppMigration{
+			Command: []string{"rake", "db:migrate"},
+		})).To(Succeed())
+	})
+
+	It("accepts the removal of the migration", func() {
+		Expect(application.ValidateMigration(models.AppMigration{})).To(Succeed())
+	})
+
+	It("rejects negative timeouts", func() {
+		err := application.ValidateMigration(models.AppMigration{
+			Command: []string{"rake", "db:migrate"},
+			Timeout: -5,
+		})
+		Expect(err).To(MatchError(ContainSubstring("migration timeout should be >= 0")))
+	})
+})
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	placementKey = "placement"
+)
+
+// Placement returns the node placement constraints of the application, nil if it has none.
+func Placement(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppPlacement, error) {
+	placementSecret, err := placementLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := placementSecret.Data[placementKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var placement models.AppPlacement
+	if err := json.Unmarshal(encoded, &placement); err != nil {
+		return nil, errors.Wrap(err, "bad placement")
+	}
+
+	return &placement, nil
+}
+
+// PlacementSet replaces the node placement constraints of the named application. An empty
+// placement removes them. When the function returns the placement is saved.
+func PlacementSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, placement models.AppPlacement) error {
+	encoded, err := json.Marshal(placement)
+	if err != nil {
+		return err
+	}
+
+	empty := len(placement.NodeSelector) == 0 &&
+		len(placement.Tolerations) == 0 &&
+		len(placement.TopologySpread) == 0
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		placementSecret, err := placementLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if placementSecret.Data == nil {
+			placementSecret.Data = make(map[string][]byte)
+		}
+
+		if empty {
+			delete(placementSecret.Data, placementKey)
+		} else {
+			placementSecret.Data[placementKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, placementSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidatePlacement checks that the node selector consists of proper labels, that the
+// tolerations have known operators and effects, and that the topology spread constraints have
+// proper topology keys, skews, and known actions.
+func ValidatePlacement(placement models.AppPlacement) error {
+	for key, value := range placement.NodeSelector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.Errorf("bad node selector label '%s': %s", key, errs[0])
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.Errorf("bad value '%s' of node selector label '%s': %s", value, key, errs[0])
+		}
+	}
+
+	for _, toleration := range placement.Tolerations {
+		if toleration.Key != "" {
+			if errs := validation.IsQualifiedName(toleration.Key); len(errs) > 0 {
+				return errors.Errorf("bad toleration key '%s': %s", toleration.Key, errs[0])
+			}
+		}
+
+		switch v1.TolerationOperator(toleration.Operator) {
+		case "", v1.TolerationOpEqual:
+			if toleration.Key == "" {
+				return errors.New("toleration without key, expected operator Exists")
+			}
+		case v1.TolerationOpExists:
+			if toleration.Value != "" {
+				return errors.Errorf("toleration '%s' with operator Exists, and value '%s'", toleration.Key, toleration.Value)
+			}
+		default:
+			return errors.Errorf("bad operator '%s' for toleration '%s', expected Equal, or Exists",
+				toleration.Operator, toleration.Key)
+		}
+
+		switch v1.TaintEffect(toleration.Effect) {
+		case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return errors.Errorf("bad effect '%s' for toleration '%s', expected NoSchedule, PreferNoSchedule, or NoExecute",
+				toleration.Effect, toleration.Key)
+		}
+
+		if toleration.TolerationSeconds != nil && v1.TaintEffect(toleration.Effect) != v1.TaintEffectNoExecute {
+			return errors.Errorf("toleration '%s' has seconds, which require effect NoExecute", toleration.Key)
+		}
+	}
+
+	seen := map[string]struct{}{}
+	for _, spread := range placement.TopologySpread {
+		if errs := validation.IsQualifiedName(spread.TopologyKey); len(errs) > 0 {
+			return errors.Errorf("bad topology key '%s': %s", spread.TopologyKey, errs[0])
+		}
+		if _, ok := seen[spread.TopologyKey]; ok {
+			return errors.Errorf("duplicate topology key '%s'", spread.TopologyKey)
+		}
+		seen[spread.TopologyKey] = struct{}{}
+
+		if spread.MaxSkew < 0 {
+			return errors.Errorf("bad max skew %d for topology key '%s', expected at least 1",
+				spread.MaxSkew, spread.TopologyKey)
+		}
+
+		switch v1.UnsatisfiableConstraintAction(spread.WhenUnsatisfiable) {
+		case "", v1.DoNotSchedule, v1.ScheduleAnyway:
+		default:
+			return errors.Errorf("bad action '%s' for topology key '%s', expected DoNotSchedule, or ScheduleAnyway",
+				spread.WhenUnsatisfiable, spread.TopologyKey)
+		}
+	}
+
+	return nil
+}
+
+// placementLoad locates and returns the kube secret storing the referenced application's node
+// placement constraints. If necessary it creates that secret.
+func placementLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakePlacementSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "placement")
+}
@@ -0,0 +1,79 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Placement", func() {
+	Describe("ValidatePlacement", func() {
+		It("accepts proper placements", func() {
+			seconds := int64(300)
+			Expect(application.ValidatePlacement(models.AppPlacement{
+				NodeSelector: map[string]string{"nvidia.com/gpu.present": "true"},
+				Tolerations: []models.AppToleration{
+					{Key: "nvidia.com/gpu", Operator: "Exists", Effect: "NoSchedule"},
+					{Key: "compliance", Value: "pci", Effect: "NoExecute", TolerationSeconds: &seconds},
+				},
+				TopologySpread: []models.AppTopologySpread{
+					{TopologyKey: "topology.kubernetes.io/zone", MaxSkew: 2, WhenUnsatisfiable: "ScheduleAnyway"},
+					{TopologyKey: "kubernetes.io/hostname"},
+				},
+			})).To(Succeed())
+		})
+
+		It("rejects bad node selector labels", func() {
+			err := application.ValidatePlacement(models.AppPlacement{
+				NodeSelector: map[string]string{"gpu": "a b"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("bad value 'a b' of node selector label 'gpu'")))
+		})
+
+		It("rejects unknown toleration operators and effects", func() {
+			err := application.ValidatePlacement(models.AppPlacement{
+				Tolerations: []models.AppToleration{{Key: "gpu", Operator: "In"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("bad operator 'In'")))
+
+			err = application.ValidatePlacement(models.AppPlacement{
+				Tolerations: []models.AppToleration{{Key: "gpu", Value: "yes", Effect: "NoRun"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("bad effect 'NoRun'")))
+		})
+
+		It("rejects tolerations with values and operator Exists", func() {
+			err := application.ValidatePlacement(models.AppPlacement{
+				Tolerations: []models.AppToleration{{Key: "gpu", Operator: "Exists", Value: "yes"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("with operator Exists")))
+		})
+
+		It("rejects toleration seconds without effect NoExecute", func() {
+			seconds := int64(60)
+			err := application.ValidatePlacement(models.AppPlacement{
+				Tolerations: []models.AppToleration{{Key: "gpu", Value: "yes", TolerationSeconds: &seconds}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("require effect NoExecute")))
+		})
+
+		It("rejects duplicate topology keys, and unknown actions", func() {
+			err := application.ValidatePlacement(models.AppPlacement{
+				TopologySpread: []models.AppTopologySpread{
+					{TopologyKey: "kubernetes.io/hostname"},
+					{TopologyKey: "kubernetes.io/hostname"},
+				},
+			})
+			Expect(err).To(MatchError(ContainSubstring("duplicate topology key")))
+
+			err = application.ValidatePlacement(models.AppPlacement{
+				TopologySpread: []models.AppTopologySpread{
+					{TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: "Wait"},
+				},
+			})
+			Expect(err).To(MatchError(ContainSubstring("bad action 'Wait'")))
+		})
+	})
+})
@@ -0,0 +1,300 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	portsKey = "ports"
+)
+
+// portExposures are the kinds of exposure of raw ports, each with a service of its own.
+var portExposures = []string{models.PortExposeNodePort, models.PortExposeLoadBalancer}
+
+// Ports returns the raw ports exposed by the application, ordered by port.
+func Ports(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppPort, error) {
+	portsSecret, err := portsLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := portsSecret.Data[portsKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var ports []models.AppPort
+	if err := json.Unmarshal(encoded, &ports); err != nil {
+		return nil, errors.Wrap(err, "bad ports")
+	}
+
+	return ports, nil
+}
+
+// PortsSet replaces the raw ports exposed by the named application. When the function
+// returns the ports are saved. They are exposed by the next deployment, see
+// PortServicesSync.
+func PortsSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, ports []models.AppPort) error {
+	ports = NormalizePorts(ports)
+
+	encoded, err := json.Marshal(ports)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		portsSecret, err := portsLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if portsSecret.Data == nil {
+			portsSecret.Data = make(map[string][]byte)
+		}
+
+		if len(ports) == 0 {
+			delete(portsSecret.Data, portsKey)
+		} else {
+			portsSecret.Data[portsKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, portsSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// NormalizePorts returns the ports ordered by port, with the defaults of protocol and
+// exposure filled in, and both in their canonical case.
+func NormalizePorts(ports []models.AppPort) []models.AppPort {
+	result := []models.AppPort{}
+	for _, port := range ports {
+		port.Protocol = strings.ToUpper(port.Protocol)
+		if port.Protocol == "" {
+			port.Protocol = string(v1.ProtocolTCP)
+		}
+		switch strings.ToLower(port.Expose) {
+		case "", strings.ToLower(models.PortExposeNodePort):
+			port.Expose = models.PortExposeNodePort
+		case strings.ToLower(models.PortExposeLoadBalancer):
+			port.Expose = models.PortExposeLoadBalancer
+		}
+		result = append(result, port)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Port != result[j].Port {
+			return result[i].Port < result[j].Port
+		}
+		return result[i].Protocol < result[j].Protocol
+	})
+	return result
+}
+
+// ValidatePorts checks that the ports are proper port numbers, unique per protocol, with known
+// protocols and exposures, and that fixed node ports are only requested for node port
+// exposure.
+func ValidatePorts(ports []models.AppPort) error {
+	seen := map[string]struct{}{}
+	for _, port := range ports {
+		if port.Port < 1 || port.Port > 65535 {
+			return errors.Errorf("bad port %d, expected 1 to 65535", port.Port)
+		}
+
+		protocol := strings.ToUpper(port.Protocol)
+		switch protocol {
+		case "":
+			protocol = string(v1.ProtocolTCP)
+		case string(v1.ProtocolTCP), string(v1.ProtocolUDP):
+		default:
+			return errors.Errorf("bad protocol '%s' for port %d, expected TCP, or UDP", port.Protocol, port.Port)
+		}
+
+		key := fmt.Sprintf("%d/%s", port.Port, protocol)
+		if _, ok := seen[key]; ok {
+			return errors.Errorf("duplicate port %s", key)
+		}
+		seen[key] = struct{}{}
+
+		switch strings.ToLower(port.Expose) {
+		case "", strings.ToLower(models.PortExposeNodePort):
+		case strings.ToLower(models.PortExposeLoadBalancer):
+			if port.NodePort != 0 {
+				return errors.Errorf("port %s is exposed by a load balancer, and cannot have a node port", key)
+			}
+		default:
+			return errors.Errorf("bad exposure '%s' for port %s, expected %s, or %s",
+				port.Expose, key, models.PortExposeNodePort, models.PortExposeLoadBalancer)
+		}
+
+		if port.NodePort < 0 || port.NodePort > 65535 {
+			return errors.Errorf("bad node port %d for port %s", port.NodePort, key)
+		}
+	}
+	return nil
+}
+
+// PortServicesSync creates, updates, and removes the services exposing the raw ports of the
+// application, one per kind of exposure. The services are owned by the application, and
+// removed with it.
+func PortServicesSync(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, ports []models.AppPort) error {
+	app, err := Get(ctx, cluster, appRef)
+	if err != nil {
+		return errors.Wrap(err, "error getting application resource")
+	}
+
+	services := cluster.Kubectl.CoreV1().Services(appRef.Namespace)
+
+	for _, expose := range portExposures {
+		serviceName := appRef.MakePortsServiceName(expose)
+
+		servicePorts := []v1.ServicePort{}
+		for _, port := range NormalizePorts(ports) {
+			if port.Expose != expose {
+				continue
+			}
+			servicePorts = append(servicePorts, v1.ServicePort{
+				Name:       fmt.Sprintf("%s-%d", strings.ToLower(port.Protocol), port.Port),
+				Protocol:   v1.Protocol(port.Protocol),
+				Port:       port.Port,
+				TargetPort: intstr.FromInt(int(port.Port)),
+				NodePort:   port.NodePort,
+			})
+		}
+
+		service, err := services.Get(ctx, serviceName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		found := err == nil
+
+		if len(servicePorts) == 0 {
+			if found {
+				err := services.Delete(ctx, serviceName, metav1.DeleteOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return errors.Wrapf(err, "removing the %s service", expose)
+				}
+			}
+			continue
+		}
+
+		if !found {
+			_, err = services.Create(ctx, &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceName,
+					Namespace: appRef.Namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       appRef.Name,
+						"app.kubernetes.io/part-of":    appRef.Namespace,
+						"app.kubernetes.io/managed-by": "epinio",
+						"app.kubernetes.io/component":  "application",
+						EpinioApplicationAreaLabel:     "ports",
+					},
+					OwnerReferences: []metav1.OwnerReference{makeOwnerReference(app)},
+				},
+				Spec: v1.ServiceSpec{
+					Type:     v1.ServiceType(expose),
+					Selector: portsSelector(appRef),
+					Ports:    servicePorts,
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return errors.Wrapf(err, "creating the %s service", expose)
+			}
+			continue
+		}
+
+		// Keep the node ports assigned by the cluster to unchanged ports.
+		for i, servicePort := range servicePorts {
+			if servicePort.NodePort != 0 {
+				continue
+			}
+			for _, current := range service.Spec.Ports {
+				if current.Port == servicePort.Port && current.Protocol == servicePort.Protocol {
+					servicePorts[i].NodePort = current.NodePort
+				}
+			}
+		}
+
+		service.Spec.Ports = servicePorts
+		_, err = services.Update(ctx, service, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "updating the %s service", expose)
+		}
+	}
+
+	return nil
+}
+
+// ExposedPorts returns the raw ports of the application as exposed by its services, with the
+// assigned node ports, and load balancer addresses.
+func ExposedPorts(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppExposedPort, error) {
+	services := cluster.Kubectl.CoreV1().Services(appRef.Namespace)
+
+	result := []models.AppExposedPort{}
+	for _, expose := range portExposures {
+		service, err := services.Get(ctx, appRef.MakePortsServiceName(expose), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		address := ""
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			address = ingress.IP
+			if address == "" {
+				address = ingress.Hostname
+			}
+			if address != "" {
+				break
+			}
+		}
+
+		for _, port := range service.Spec.Ports {
+			result = append(result, models.AppExposedPort{
+				AppPort: models.AppPort{
+					Port:     port.Port,
+					Protocol: string(port.Protocol),
+					Expose:   expose,
+					NodePort: port.NodePort,
+				},
+				Address: address,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Port < result[j].Port })
+
+	return result, nil
+}
+
+// portsSelector returns the labels of the application's instances.
+func portsSelector(appRef models.AppRef) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/component": "application",
+		"app.kubernetes.io/name":      appRef.Name,
+		"app.kubernetes.io/part-of":   appRef.Namespace,
+	}
+}
+
+// portsLoad locates and returns the kube secret storing the referenced application's raw
+// ports. If necessary it creates that secret.
+func portsLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakePortsSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "ports")
+}
@@ -0,0 +1,58 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ports", func() {
+	Describe("ValidatePorts", func() {
+		It("accepts proper ports", func() {
+			Expect(application.ValidatePorts([]models.AppPort{
+				{Port: 5432},
+				{Port: 53, Protocol: "udp", Expose: "loadbalancer"},
+				{Port: 53, Protocol: "TCP", Expose: "NodePort", NodePort: 30053},
+			})).To(Succeed())
+		})
+
+		It("rejects bad port numbers", func() {
+			err := application.ValidatePorts([]models.AppPort{{Port: 70000}})
+			Expect(err).To(MatchError(ContainSubstring("bad port 70000")))
+		})
+
+		It("rejects unknown protocols", func() {
+			err := application.ValidatePorts([]models.AppPort{{Port: 80, Protocol: "sctp"}})
+			Expect(err).To(MatchError(ContainSubstring("bad protocol 'sctp'")))
+		})
+
+		It("rejects duplicate ports", func() {
+			err := application.ValidatePorts([]models.AppPort{{Port: 80}, {Port: 80, Protocol: "tcp"}})
+			Expect(err).To(MatchError(ContainSubstring("duplicate port 80/TCP")))
+		})
+
+		It("rejects unknown exposures", func() {
+			err := application.ValidatePorts([]models.AppPort{{Port: 80, Expose: "ingress"}})
+			Expect(err).To(MatchError(ContainSubstring("bad exposure 'ingress'")))
+		})
+
+		It("rejects node ports of load balancers", func() {
+			err := application.ValidatePorts([]models.AppPort{{Port: 80, Expose: "LoadBalancer", NodePort: 30080}})
+			Expect(err).To(MatchError(ContainSubstring("cannot have a node port")))
+		})
+	})
+
+	Describe("NormalizePorts", func() {
+		It("fills in the defaults, and orders by port", func() {
+			Expect(application.NormalizePorts([]models.AppPort{
+				{Port: 6379, Expose: "loadbalancer"},
+				{Port: 53, Protocol: "udp"},
+			})).To(Equal([]models.AppPort{
+				{Port: 53, Protocol: "UDP", Expose: models.PortExposeNodePort},
+				{Port: 6379, Protocol: "TCP", Expose: models.PortExposeLoadBalancer},
+			}))
+		})
+	})
+})
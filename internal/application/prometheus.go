@@ -0,0 +1,125 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	prometheusTimeout = 10 * time.Second
+
+	// Containers of the pods, without the pause container, and without the
+	// pod-level aggregate.
+	prometheusContainers = `namespace="%s",pod=~"%s",container!="",container!="POD"`
+
+	prometheusCPUQuery    = `sum by (pod) (rate(container_cpu_usage_seconds_total{%s}[5m]))`
+	prometheusMemoryQuery = `sum by (pod) (container_memory_working_set_bytes{%s})`
+)
+
+// prometheusResponse is the part of the response of the prometheus query API used for
+// instant vector queries.
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// populatePrometheusMetrics adds the resource usage of the pods in the namespace, as recorded
+// by the prometheus server at the given url, i.e. the cadvisor metrics of the kubelets.
+func populatePrometheusMetrics(ctx context.Context, baseURL, namespace string, podInfos map[string]*models.PodInfo) error {
+	if len(podInfos) == 0 {
+		return nil
+	}
+
+	pods := []string{}
+	for name := range podInfos {
+		pods = append(pods, name)
+	}
+	selector := fmt.Sprintf(prometheusContainers, namespace, strings.Join(pods, "|"))
+
+	cpu, err := prometheusQuery(ctx, baseURL, fmt.Sprintf(prometheusCPUQuery, selector), "pod")
+	if err != nil {
+		return errors.Wrap(err, "querying prometheus for cpu usage")
+	}
+	memory, err := prometheusQuery(ctx, baseURL, fmt.Sprintf(prometheusMemoryQuery, selector), "pod")
+	if err != nil {
+		return errors.Wrap(err, "querying prometheus for memory usage")
+	}
+
+	for name, podInfo := range podInfos {
+		// cpu * 1000 -> milliCPUs (rounded)
+		podInfo.MilliCPUs = int64(math.Round(cpu[name] * 1000))
+		podInfo.MemoryBytes = int64(memory[name])
+	}
+
+	return nil
+}
+
+// prometheusQuery runs the instant query against the prometheus server at the given url, and
+// returns the values of the result, by the value of the label. The value of results without
+// the label is keyed by the empty string.
+func prometheusQuery(ctx context.Context, baseURL, query, label string) (map[string]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, prometheusTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded prometheusResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, errors.Wrapf(err, "bad response, status %d", response.StatusCode)
+	}
+	if decoded.Status != "success" {
+		return nil, errors.Errorf("query failed: %s", decoded.Error)
+	}
+
+	result := map[string]float64{}
+	for _, sample := range decoded.Data.Result {
+		// Instant vector samples are pairs of timestamp and value. The value is a string.
+		if len(sample.Value) != 2 {
+			continue
+		}
+		value, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad value for %s '%s'", label, sample.Metric[label])
+		}
+		result[sample.Metric[label]] = number
+	}
+
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prometheus metrics", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query().Get("query")
+			switch {
+			case strings.Contains(query, "container_cpu_usage_seconds_total"):
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+					{"metric":{"pod":"app-1"},"value":[1660000000,"0.2504"]}]}}`))
+			case strings.Contains(query, "container_memory_working_set_bytes"):
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+					{"metric":{"pod":"app-1"},"value":[1660000000,"134217728"]}]}}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"status":"error","error":"bad query"}`))
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("populates the usage of the pods", func() {
+		podInfos := map[string]*models.PodInfo{
+			"app-1": {Name: "app-1"},
+			"app-2": {Name: "app-2"},
+		}
+
+		err := populatePrometheusMetrics(context.Background(), server.URL, "workspace", podInfos)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(podInfos["app-1"].MilliCPUs).To(Equal(int64(250)))
+		Expect(podInfos["app-1"].MemoryBytes).To(Equal(int64(134217728)))
+		Expect(podInfos["app-2"].MilliCPUs).To(Equal(int64(0)))
+	})
+
+	It("reports failed queries", func() {
+		_, err := prometheusQuery(context.Background(), server.URL, "up", "pod")
+		Expect(err).To(MatchError(ContainSubstring("query failed: bad query")))
+	})
+})
@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	pullSecretArea = "pullsecret"
+)
+
+// dockerConfig is the content of a kube secret of type `kubernetes.io/dockerconfigjson`.
+type dockerConfig struct {
+	Auths map[string]dockerAuth `json:"auths"`
+}
+
+type dockerAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// PullSecret returns the registry and username of the image pull credentials of the
+// application, without the password. The result is nil if the application has none.
+func PullSecret(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppPullSecret, error) {
+	secret, err := cluster.GetSecret(ctx, appRef.Namespace, appRef.MakePullSecretName())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var config dockerConfig
+	if err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err != nil {
+		return nil, errors.Wrap(err, "bad pull secret")
+	}
+
+	for registry, auth := range config.Auths {
+		return &models.AppPullSecret{
+			Registry: registry,
+			Username: auth.Username,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// PullSecretSet saves the image pull credentials of the named application, and attaches them
+// to the service account of the namespace, which the application's instances run as. A
+// secret without registry removes the credentials, and detaches them. A secret without
+// password keeps the saved credentials.
+func PullSecretSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, pullSecret models.AppPullSecret) error {
+	secretName := appRef.MakePullSecretName()
+
+	if pullSecret.Registry == "" {
+		err := cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return serviceAccountPullSecret(ctx, cluster, appRef.Namespace, secretName, false)
+	}
+
+	if pullSecret.Password != "" {
+		encoded, err := json.Marshal(dockerConfig{
+			Auths: map[string]dockerAuth{
+				pullSecret.Registry: {
+					Username: pullSecret.Username,
+					Password: pullSecret.Password,
+					Auth: base64.StdEncoding.EncodeToString(
+						[]byte(pullSecret.Username + ":" + pullSecret.Password)),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			secret, err := pullSecretLoad(ctx, cluster, appRef)
+			if err != nil {
+				return err
+			}
+
+			secret.Data = map[string][]byte{
+				v1.DockerConfigJsonKey: encoded,
+			}
+
+			_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+				ctx, secret, metav1.UpdateOptions{})
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return serviceAccountPullSecret(ctx, cluster, appRef.Namespace, secretName, true)
+}
+
+// ValidatePullSecret checks that the image pull credentials name a registry host, and a user.
+// A pull secret without registry is valid, it removes the credentials.
+func ValidatePullSecret(pullSecret models.AppPullSecret) error {
+	if pullSecret.Registry == "" {
+		return nil
+	}
+
+	address, err := url.Parse("//" + pullSecret.Registry)
+	if err != nil || address.Host != pullSecret.Registry {
+		return errors.Errorf("bad pull secret registry '%s', expected a host, and optional port", pullSecret.Registry)
+	}
+	if pullSecret.Username == "" {
+		return errors.Errorf("pull secret for registry '%s' has no username", pullSecret.Registry)
+	}
+
+	return nil
+}
+
+// pullSecretDetach removes the image pull credentials of the referenced application from the
+// service account of the namespace. The secret itself is owned by the application resource.
+func pullSecretDetach(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) error {
+	return serviceAccountPullSecret(ctx, cluster, appRef.Namespace, appRef.MakePullSecretName(), false)
+}
+
+// serviceAccountPullSecret adds the named secret to, or removes it from, the image pull
+// secrets of the service account of the namespace.
+func serviceAccountPullSecret(ctx context.Context, cluster *kubernetes.Cluster, namespace, secretName string, attach bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		accounts := cluster.Kubectl.CoreV1().ServiceAccounts(namespace)
+
+		account, err := accounts.Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) && !attach {
+				return nil
+			}
+			return errors.Wrap(err, "getting the namespace service account")
+		}
+
+		secrets := []v1.LocalObjectReference{}
+		found := false
+		for _, secret := range account.ImagePullSecrets {
+			if secret.Name == secretName {
+				found = true
+				if !attach {
+					continue
+				}
+			}
+			secrets = append(secrets, secret)
+		}
+		if found == attach {
+			return nil
+		}
+		if attach {
+			secrets = append(secrets, v1.LocalObjectReference{Name: secretName})
+		}
+
+		account.ImagePullSecrets = secrets
+		_, err = accounts.Update(ctx, account, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// pullSecretLoad locates and returns the kube secret holding the referenced application's
+// image pull credentials. If necessary it creates that secret, with the type required for
+// image pull secrets.
+func pullSecretLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakePullSecretName()
+
+	secret, err := cluster.GetSecret(ctx, appRef.Namespace, secretName)
+	if err == nil {
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "error getting secret %s", secretName)
+	}
+
+	app, err := Get(ctx, cluster, appRef)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting application resource")
+	}
+
+	created := makeSecret(appRef, pullSecretArea)
+	created.ObjectMeta.Name = secretName
+	created.ObjectMeta.OwnerReferences = []metav1.OwnerReference{makeOwnerReference(app)}
+	created.Type = v1.SecretTypeDockerConfigJson
+	created.Data = map[string][]byte{
+		v1.DockerConfigJsonKey: []byte(`{"auths":{}}`),
+	}
+
+	err = cluster.CreateSecret(ctx, appRef.Namespace, created)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating secret %s", secretName)
+	}
+
+	return &created, nil
+}
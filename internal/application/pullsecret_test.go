@@ -0,0 +1,43 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidatePullSecret", func() {
+	It("accepts registry hosts, with and without port", func() {
+		Expect(application.ValidatePullSecret(models.AppPullSecret{
+			Registry: "ghcr.io",
+			Username: "deployer",
+			Password: "secret",
+		})).To(Succeed())
+		Expect(application.ValidatePullSecret(models.AppPullSecret{
+			Registry: "registry.example.com:5000",
+			Username: "deployer",
+		})).To(Succeed())
+	})
+
+	It("accepts the removal of the credentials", func() {
+		Expect(application.ValidatePullSecret(models.AppPullSecret{})).To(Succeed())
+	})
+
+	It("rejects registry urls", func() {
+		err := application.ValidatePullSecret(models.AppPullSecret{
+			Registry: "https://ghcr.io/org",
+			Username: "deployer",
+		})
+		Expect(err).To(MatchError(ContainSubstring("bad pull secret registry")))
+	})
+
+	It("rejects credentials without user", func() {
+		err := application.ValidatePullSecret(models.AppPullSecret{
+			Registry: "ghcr.io",
+			Password: "secret",
+		})
+		Expect(err).To(MatchError(ContainSubstring("has no username")))
+	})
+})
@@ -0,0 +1,172 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/metrics"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/go-logr/logr"
+	"github.com/spf13/viper"
+	apibatchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	stagingQueueInterval = 5 * time.Second
+	stagingJobSelector   = "app.kubernetes.io/component=staging,app.kubernetes.io/managed-by=epinio"
+	stagingJobNamespace  = "app.kubernetes.io/part-of"
+)
+
+// StagingQueuePlan is the result of planning the staging queue. It names the queued jobs
+// which may run now, and provides the positions of the jobs remaining in the queue,
+// keyed by stage id. Positions start at 1. Running counts the jobs running, including
+// the admitted ones.
+type StagingQueuePlan struct {
+	Admit    []string
+	Position map[string]int
+	Running  int
+}
+
+// StagingConcurrency returns the global limit for the number of staging jobs running at the
+// same time. Zero means no limit.
+func StagingConcurrency() int {
+	return viper.GetInt("staging-concurrency")
+}
+
+// StagingQueued returns true if staging jobs of the namespace have to be queued, i.e.
+// created suspended, for admission by `AdmitStaging`.
+func StagingQueued(settings models.NamespaceSettings) bool {
+	return StagingConcurrency() > 0 || settings.StagingConcurrency > 0
+}
+
+// PlanStagingQueue determines which of the queued (suspended) staging jobs may run, given
+// the global limit and the limits per namespace. Jobs are admitted in order of creation.
+// Zero limits are no limits.
+func PlanStagingQueue(jobs []apibatchv1.Job, globalLimit int, namespaceLimit map[string]int) StagingQueuePlan {
+	plan := StagingQueuePlan{
+		Position: map[string]int{},
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].CreationTimestamp.Equal(&jobs[j].CreationTimestamp) {
+			return jobs[i].Name < jobs[j].Name
+		}
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+
+	running := 0
+	runningIn := map[string]int{}
+	queued := []apibatchv1.Job{}
+
+	for _, job := range jobs {
+		if jobDone(job) {
+			continue
+		}
+		if job.Spec.Suspend != nil && *job.Spec.Suspend {
+			queued = append(queued, job)
+			continue
+		}
+		running++
+		runningIn[job.Labels[stagingJobNamespace]]++
+	}
+
+	for _, job := range queued {
+		namespace := job.Labels[stagingJobNamespace]
+		limit := namespaceLimit[namespace]
+
+		if (globalLimit == 0 || running < globalLimit) && (limit == 0 || runningIn[namespace] < limit) {
+			plan.Admit = append(plan.Admit, job.Name)
+			running++
+			runningIn[namespace]++
+			continue
+		}
+
+		plan.Position[job.Labels[models.EpinioStageIDLabel]] = len(plan.Position) + 1
+	}
+	plan.Running = running
+
+	return plan
+}
+
+// AdmitStaging runs the queued staging jobs for which there is room now. It returns the
+// resulting queue positions.
+func AdmitStaging(ctx context.Context, cluster *kubernetes.Cluster) (map[string]int, error) {
+	jobList, err := cluster.ListJobs(ctx, helmchart.Namespace(), stagingJobSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the limits of all namespaces having queued jobs
+	namespaceLimit := map[string]int{}
+	for _, job := range jobList.Items {
+		namespace := job.Labels[stagingJobNamespace]
+		if _, ok := namespaceLimit[namespace]; ok || job.Spec.Suspend == nil || !*job.Spec.Suspend {
+			continue
+		}
+		space, err := namespaces.Get(ctx, cluster, namespace)
+		if err != nil {
+			return nil, err
+		}
+		namespaceLimit[namespace] = 0
+		if space != nil {
+			namespaceLimit[namespace] = space.Settings.StagingConcurrency
+		}
+	}
+
+	plan := PlanStagingQueue(jobList.Items, StagingConcurrency(), namespaceLimit)
+	metrics.SetStaging(len(plan.Position), plan.Running)
+
+	for _, name := range plan.Admit {
+		_, err := cluster.Kubectl.BatchV1().Jobs(helmchart.Namespace()).Patch(ctx, name,
+			types.MergePatchType, []byte(`{"spec":{"suspend":false}}`), metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.Position, nil
+}
+
+// StagingQueueLoop periodically admits queued staging jobs, until the context is done.
+// Admission also happens whenever a staging job is created, this loop takes care of the
+// jobs waiting for others to complete.
+func StagingQueueLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(stagingQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "staging queue: no cluster")
+			continue
+		}
+
+		if _, err := AdmitStaging(ctx, cluster); err != nil {
+			logger.Error(err, "staging queue: admission failed")
+		}
+	}
+}
+
+// jobDone returns true if the job is complete or failed.
+func jobDone(job apibatchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status == v1.ConditionTrue &&
+			(condition.Type == apibatchv1.JobComplete || condition.Type == apibatchv1.JobFailed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package application_test
+
+import (
+	"time"
+
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("PlanStagingQueue", func() {
+	start := time.Now()
+
+	job := func(id, namespace string, age int, suspended bool) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "stage-" + id,
+				CreationTimestamp: metav1.NewTime(start.Add(time.Duration(age) * time.Second)),
+				Labels: map[string]string{
+					"app.kubernetes.io/part-of": namespace,
+					models.EpinioStageIDLabel:   id,
+				},
+			},
+			Spec: batchv1.JobSpec{
+				Suspend: pointer.Bool(suspended),
+			},
+		}
+	}
+
+	done := func(j batchv1.Job) batchv1.Job {
+		j.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: v1.ConditionTrue},
+		}
+		return j
+	}
+
+	It("admits everything without limits", func() {
+		plan := application.PlanStagingQueue([]batchv1.Job{
+			job("a", "one", 1, true),
+			job("b", "one", 2, true),
+		}, 0, map[string]int{})
+
+		Expect(plan.Admit).To(Equal([]string{"stage-a", "stage-b"}))
+		Expect(plan.Position).To(BeEmpty())
+	})
+
+	It("admits in order of creation, up to the global limit", func() {
+		plan := application.PlanStagingQueue([]batchv1.Job{
+			job("c", "two", 3, true),
+			job("a", "one", 1, false),
+			job("b", "one", 2, true),
+			done(job("z", "one", 0, false)),
+		}, 2, map[string]int{})
+
+		Expect(plan.Admit).To(Equal([]string{"stage-b"}))
+		Expect(plan.Position).To(Equal(map[string]int{"c": 1}))
+		Expect(plan.Running).To(Equal(2))
+	})
+
+	It("respects the limits of the namespaces", func() {
+		plan := application.PlanStagingQueue([]batchv1.Job{
+			job("a", "one", 1, false),
+			job("b", "one", 2, true),
+			job("c", "two", 3, true),
+			job("d", "one", 4, true),
+		}, 0, map[string]int{"one": 1})
+
+		Expect(plan.Admit).To(Equal([]string{"stage-c"}))
+		Expect(plan.Position).To(Equal(map[string]int{"b": 1, "d": 2}))
+	})
+})
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// areaSecretNames maps the areas of the secrets holding the resources of an application to
+// the functions computing their names.
+var areaSecretNames = map[string]func(appRef models.AppRef) string{
+	"canary":           func(appRef models.AppRef) string { return appRef.MakeCanarySecretName() },
+	"chartvalues":      func(appRef models.AppRef) string { return appRef.MakeChartValuesSecretName() },
+	"configuration":    func(appRef models.AppRef) string { return appRef.MakeConfigurationSecretName() },
+	"domains":          func(appRef models.AppRef) string { return appRef.MakeDomainsSecretName() },
+	"entrypoint":       func(appRef models.AppRef) string { return appRef.MakeEntrypointSecretName() },
+	"environment":      func(appRef models.AppRef) string { return appRef.MakeEnvSecretName() },
+	"hooks":            func(appRef models.AppRef) string { return appRef.MakeHooksSecretName() },
+	"migration":        func(appRef models.AppRef) string { return appRef.MakeMigrationSecretName() },
+	"placement":        func(appRef models.AppRef) string { return appRef.MakePlacementSecretName() },
+	"ports":            func(appRef models.AppRef) string { return appRef.MakePortsSecretName() },
+	"pullsecret":       func(appRef models.AppRef) string { return appRef.MakePullSecretName() },
+	"revisions":        func(appRef models.AppRef) string { return appRef.MakeRevisionsSecretName() },
+	"routeannotations": func(appRef models.AppRef) string { return appRef.MakeRouteAnnotationsSecretName() },
+	"scaling":          func(appRef models.AppRef) string { return appRef.MakeScaleSecretName() },
+	"sidecars":         func(appRef models.AppRef) string { return appRef.MakeSidecarsSecretName() },
+	"staging":          func(appRef models.AppRef) string { return appRef.MakeStagingSecretName() },
+	"tasks":            func(appRef models.AppRef) string { return appRef.MakeTasksSecretName() },
+	"termination":      func(appRef models.AppRef) string { return appRef.MakeTerminationSecretName() },
+	"volumes":          func(appRef models.AppRef) string { return appRef.MakeVolumesSecretName() },
+}
+
+// Rename creates the application resource of the new name, with the spec of the referenced
+// application and the given routes, and copies the secrets holding the resources of the
+// application, i.e. environment, bindings, scaling, etc., to it. The referenced application
+// is not changed, see Delete for its removal. On failure the new application is removed
+// again.
+func Rename(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, newName string, routes []string) error {
+	client, err := cluster.ClientApp()
+	if err != nil {
+		return err
+	}
+
+	app, err := Get(ctx, cluster, appRef)
+	if err != nil {
+		return errors.Wrap(err, "error getting application resource")
+	}
+
+	spec, _, err := unstructured.NestedMap(app.Object, "spec")
+	if err != nil {
+		return errors.Wrap(err, "bad application resource")
+	}
+
+	renamed := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	renamed.SetAPIVersion(app.GetAPIVersion())
+	renamed.SetKind(app.GetKind())
+	renamed.SetName(newName)
+	if err := unstructured.SetNestedMap(renamed.Object, spec, "spec"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedStringSlice(renamed.Object, routes, "spec", "routes"); err != nil {
+		return err
+	}
+
+	renamed, err = client.Namespace(appRef.Namespace).Create(ctx, renamed, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error creating the renamed application resource")
+	}
+
+	err = copySecrets(ctx, cluster, appRef, renamed)
+	if err != nil {
+		// The application resource owns the secrets copied so far.
+		derr := client.Namespace(appRef.Namespace).Delete(ctx, newName, metav1.DeleteOptions{})
+		if derr != nil {
+			return errors.Wrapf(err, "error removing the renamed application resource: %s", derr.Error())
+		}
+		return err
+	}
+
+	// The copied image pull credentials have to be attached, like the original.
+	pullSecret, err := PullSecret(ctx, cluster, appRef)
+	if err != nil {
+		return err
+	}
+	if pullSecret != nil {
+		newRef := models.NewAppRef(newName, appRef.Namespace)
+		err = serviceAccountPullSecret(ctx, cluster, appRef.Namespace, newRef.MakePullSecretName(), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copySecrets copies the secrets holding the resources of the referenced application to the
+// application resource of the new name, which owns the copies.
+func copySecrets(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, renamed *unstructured.Unstructured) error {
+	newRef := models.NewAppRef(renamed.GetName(), appRef.Namespace)
+
+	selector := "app.kubernetes.io/name=" + appRef.Name
+	selector += ",app.kubernetes.io/part-of=" + appRef.Namespace
+	selector += ",app.kubernetes.io/component=application"
+	selector += ",app.kubernetes.io/managed-by=epinio"
+
+	secrets, err := cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).List(ctx,
+		metav1.ListOptions{
+			LabelSelector: selector,
+		})
+	if err != nil {
+		return errors.Wrap(err, "error listing the application secrets")
+	}
+
+	for _, secret := range secrets.Items {
+		area := secret.ObjectMeta.Labels[EpinioApplicationAreaLabel]
+		secretName, ok := areaSecretNames[area]
+		if !ok {
+			return errors.Errorf("unknown area '%s' of application secret %s", area, secret.ObjectMeta.Name)
+		}
+
+		copied := makeSecret(newRef, area)
+		copied.ObjectMeta.Name = secretName(newRef)
+		copied.ObjectMeta.OwnerReferences = []metav1.OwnerReference{makeOwnerReference(renamed)}
+		copied.Type = secret.Type
+		copied.Data = map[string][]byte{}
+		for key, value := range secret.Data {
+			copied.Data[key] = value
+		}
+
+		err := cluster.CreateSecret(ctx, appRef.Namespace, copied)
+		if err != nil {
+			return errors.Wrapf(err, "error creating secret %s", copied.ObjectMeta.Name)
+		}
+	}
+
+	return nil
+}
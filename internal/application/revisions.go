@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// RevisionsKept is the number of deployment revisions remembered per application. Older
+// revisions are dropped as new ones are recorded.
+const RevisionsKept = 10
+
+// Revisions returns the deployment revisions recorded for the application, ordered from
+// oldest to newest.
+func Revisions(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (models.AppRevisionList, error) {
+	revSecret, err := revisionsLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisionsDecode(revSecret)
+}
+
+// RevisionAdd records a new deployment revision for the application, and returns the
+// number assigned to it. Only the last RevisionsKept revisions are kept.
+func RevisionAdd(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, revision models.AppRevision) (int, error) {
+	number := 0
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		revSecret, err := revisionsLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		revisions, err := revisionsDecode(revSecret)
+		if err != nil {
+			return err
+		}
+
+		number = 1
+		if len(revisions) > 0 {
+			number = revisions[len(revisions)-1].Number + 1
+		}
+
+		revision.Number = number
+		revision.EnvHash = environmentHash(revision.Environment)
+		if revision.CreatedAt.IsZero() {
+			revision.CreatedAt = metav1.Now()
+		}
+
+		encoded, err := json.Marshal(revision)
+		if err != nil {
+			return err
+		}
+
+		if revSecret.Data == nil {
+			revSecret.Data = make(map[string][]byte)
+		}
+		revSecret.Data[strconv.Itoa(number)] = encoded
+
+		// Drop the oldest revisions beyond the limit. The new revision is not in
+		// `revisions`, hence the `+ 1`.
+		for len(revisions)+1 > RevisionsKept {
+			delete(revSecret.Data, strconv.Itoa(revisions[0].Number))
+			revisions = revisions[1:]
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, revSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+
+	return number, err
+}
+
+// environmentHash returns a checksum of the environment, enabling the quick comparison of
+// the environments of different revisions without showing the values.
+func environmentHash(environment models.EnvVariableMap) string {
+	hash := sha256.New()
+	for _, ev := range environment.List() {
+		fmt.Fprintf(hash, "%s=%s\n", ev.Name, ev.Value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+// revisionsDecode converts the contents of the revisions secret into a list of revisions,
+// ordered by their numbers.
+func revisionsDecode(revSecret *v1.Secret) (models.AppRevisionList, error) {
+	result := models.AppRevisionList{}
+
+	for key, value := range revSecret.Data {
+		var revision models.AppRevision
+		if err := json.Unmarshal(value, &revision); err != nil {
+			return nil, errors.Wrapf(err, "bad revision %s", key)
+		}
+		result = append(result, revision)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Number < result[j].Number
+	})
+
+	return result, nil
+}
+
+// revisionsLoad locates and returns the kube secret storing the referenced application's
+// deployment revisions. If necessary it creates that secret.
+func revisionsLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeRevisionsSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "revisions")
+}
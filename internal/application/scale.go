@@ -2,18 +2,24 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"math"
 	"strconv"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/util/retry"
 )
 
 const (
-	instanceKey = "desired"
+	instanceKey       = "desired"
+	maxUnavailableKey = "maxunavailable"
+	maxSurgeKey       = "maxsurge"
+	processesKey      = "processes"
 )
 
 // Scaling returns the number of desired instances set by a user for the application
@@ -44,6 +50,101 @@ func ScalingSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.
 	})
 }
 
+// Rollout returns the rolling update parameters set by a user for the application, or nil,
+// if there are none.
+func Rollout(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppRollout, error) {
+	scaleSecret, err := scaleLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := models.AppRollout{
+		MaxUnavailable: string(scaleSecret.Data[maxUnavailableKey]),
+		MaxSurge:       string(scaleSecret.Data[maxSurgeKey]),
+	}
+
+	if rollout.MaxUnavailable == "" && rollout.MaxSurge == "" {
+		return nil, nil
+	}
+
+	return &rollout, nil
+}
+
+// RolloutSet sets the rolling update parameters for the named application. Empty
+// parameters are removed. When the function returns the parameters are saved.
+func RolloutSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, rollout models.AppRollout) error {
+	return scaleUpdate(ctx, cluster, appRef, func(scaleSecret *v1.Secret) {
+		for key, value := range map[string]string{
+			maxUnavailableKey: rollout.MaxUnavailable,
+			maxSurgeKey:       rollout.MaxSurge,
+		} {
+			if value == "" {
+				delete(scaleSecret.Data, key)
+			} else {
+				scaleSecret.Data[key] = []byte(value)
+			}
+		}
+	})
+}
+
+// Processes returns the additional process types of the application, mapped to their
+// desired number of instances. The result is nil if the application has none.
+func Processes(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (map[string]int32, error) {
+	scaleSecret, err := scaleLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := scaleSecret.Data[processesKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var processes map[string]int32
+	if err := json.Unmarshal(encoded, &processes); err != nil {
+		return nil, errors.Wrap(err, "bad processes")
+	}
+
+	return processes, nil
+}
+
+// ProcessesSet merges the given process types and their desired instances into the ones
+// already known for the named application. When the function returns the process types
+// are saved.
+func ProcessesSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, processes map[string]int32) error {
+	return scaleUpdate(ctx, cluster, appRef, func(scaleSecret *v1.Secret) {
+		current := map[string]int32{}
+		if encoded, ok := scaleSecret.Data[processesKey]; ok {
+			// Bad saved data is simply replaced.
+			_ = json.Unmarshal(encoded, &current)
+		}
+		for name, instances := range processes {
+			current[name] = instances
+		}
+
+		// Encoding a map of numbers cannot fail.
+		encoded, _ := json.Marshal(current)
+		scaleSecret.Data[processesKey] = encoded
+	})
+}
+
+// ValidateProcesses checks that the process types have names usable in kube resource
+// names, are not the main `web` process, and ask for a sensible number of instances.
+func ValidateProcesses(processes map[string]int32) error {
+	for name, instances := range processes {
+		if name == models.ProcessWeb {
+			return errors.Errorf("process type '%s' is scaled by the application instances", name)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return errors.Errorf("bad process type '%s': %s", name, errs[0])
+		}
+		if instances < 0 {
+			return errors.Errorf("instances of process type '%s' should be >= 0", name)
+		}
+	}
+	return nil
+}
+
 // scaleUpdate is a helper for the public functions. It encapsulates the read/modify/write cycle
 // necessary to update the application's kube resource holding the application's number of desired
 // instances
@@ -0,0 +1,32 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateProcesses", func() {
+	It("accepts proper process types", func() {
+		Expect(application.ValidateProcesses(map[string]int32{
+			"worker":    2,
+			"scheduler": 0,
+		})).To(Succeed())
+	})
+
+	It("rejects the web process", func() {
+		err := application.ValidateProcesses(map[string]int32{"web": 2})
+		Expect(err).To(MatchError(ContainSubstring("process type 'web' is scaled by the application instances")))
+	})
+
+	It("rejects bad names", func() {
+		err := application.ValidateProcesses(map[string]int32{"Back_Ground": 1})
+		Expect(err).To(MatchError(ContainSubstring("bad process type 'Back_Ground'")))
+	})
+
+	It("rejects negative instances", func() {
+		err := application.ValidateProcesses(map[string]int32{"worker": -1})
+		Expect(err).To(MatchError(ContainSubstring("instances of process type 'worker' should be >= 0")))
+	})
+})
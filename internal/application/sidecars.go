@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	sidecarsKey = "sidecars"
+)
+
+// Sidecars returns the sidecar containers of the application, in the order they were given.
+func Sidecars(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppSidecar, error) {
+	sidecarsSecret, err := sidecarsLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := sidecarsSecret.Data[sidecarsKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var sidecars []models.AppSidecar
+	if err := json.Unmarshal(encoded, &sidecars); err != nil {
+		return nil, errors.Wrap(err, "bad sidecars")
+	}
+
+	return sidecars, nil
+}
+
+// SidecarsSet replaces the sidecar containers of the named application. When the function
+// returns the sidecars are saved.
+func SidecarsSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, sidecars []models.AppSidecar) error {
+	encoded, err := json.Marshal(sidecars)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sidecarsSecret, err := sidecarsLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if sidecarsSecret.Data == nil {
+			sidecarsSecret.Data = make(map[string][]byte)
+		}
+
+		if len(sidecars) == 0 {
+			delete(sidecarsSecret.Data, sidecarsKey)
+		} else {
+			sidecarsSecret.Data[sidecarsKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, sidecarsSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateSidecars checks that the sidecars have unique names usable as container names, an
+// image, and mount only the given configurations, at absolute paths.
+func ValidateSidecars(sidecars []models.AppSidecar, configurations []string) error {
+	bound := map[string]struct{}{}
+	for _, configuration := range configurations {
+		bound[configuration] = struct{}{}
+	}
+
+	seen := map[string]struct{}{}
+	for _, sidecar := range sidecars {
+		if errs := validation.IsDNS1123Label(sidecar.Name); len(errs) > 0 {
+			return errors.Errorf("bad sidecar name '%s': %s", sidecar.Name, errs[0])
+		}
+		if _, ok := seen[sidecar.Name]; ok {
+			return errors.Errorf("duplicate sidecar name '%s'", sidecar.Name)
+		}
+		seen[sidecar.Name] = struct{}{}
+
+		if sidecar.Image == "" {
+			return errors.Errorf("sidecar '%s' has no image", sidecar.Name)
+		}
+
+		for _, mount := range sidecar.Mounts {
+			if _, ok := bound[mount.Configuration]; !ok {
+				return errors.Errorf("sidecar '%s' mounts configuration '%s', which is not bound to the application",
+					sidecar.Name, mount.Configuration)
+			}
+			if !path.IsAbs(mount.Path) {
+				return errors.Errorf("sidecar '%s' mounts configuration '%s' at '%s', expected an absolute path",
+					sidecar.Name, mount.Configuration, mount.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// sidecarsLoad locates and returns the kube secret storing the referenced application's sidecar
+// containers. If necessary it creates that secret.
+func sidecarsLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeSidecarsSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "sidecars")
+}
@@ -0,0 +1,59 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateSidecars", func() {
+	sidecar := func(name, image string, mounts ...models.AppSidecarMount) models.AppSidecar {
+		return models.AppSidecar{Name: name, Image: image, Mounts: mounts}
+	}
+	mount := func(configuration, path string) models.AppSidecarMount {
+		return models.AppSidecarMount{Configuration: configuration, Path: path}
+	}
+	bound := []string{"db-credentials"}
+
+	It("accepts proper sidecars", func() {
+		Expect(application.ValidateSidecars([]models.AppSidecar{
+			sidecar("sql-proxy", "gcr.io/cloudsql-docker/gce-proxy:1.33",
+				mount("db-credentials", "/secrets/db")),
+			sidecar("shipper", "fluent/fluent-bit"),
+		}, bound)).To(Succeed())
+	})
+
+	It("rejects bad names", func() {
+		err := application.ValidateSidecars([]models.AppSidecar{sidecar("SQL_Proxy", "proxy")}, bound)
+		Expect(err).To(MatchError(ContainSubstring("bad sidecar name 'SQL_Proxy'")))
+	})
+
+	It("rejects duplicate names", func() {
+		err := application.ValidateSidecars([]models.AppSidecar{
+			sidecar("proxy", "proxy:1"),
+			sidecar("proxy", "proxy:2"),
+		}, bound)
+		Expect(err).To(MatchError(ContainSubstring("duplicate sidecar name 'proxy'")))
+	})
+
+	It("rejects missing images", func() {
+		err := application.ValidateSidecars([]models.AppSidecar{sidecar("proxy", "")}, bound)
+		Expect(err).To(MatchError(ContainSubstring("sidecar 'proxy' has no image")))
+	})
+
+	It("rejects mounts of unbound configurations", func() {
+		err := application.ValidateSidecars([]models.AppSidecar{
+			sidecar("proxy", "proxy", mount("other", "/secrets")),
+		}, bound)
+		Expect(err).To(MatchError(ContainSubstring("which is not bound to the application")))
+	})
+
+	It("rejects relative mount paths", func() {
+		err := application.ValidateSidecars([]models.AppSidecar{
+			sidecar("proxy", "proxy", mount("db-credentials", "secrets")),
+		}, bound)
+		Expect(err).To(MatchError(ContainSubstring("expected an absolute path")))
+	})
+})
@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	sleepingKey  = "sleeping"
+	autoSleepKey = "autosleep"
+	awakeKey     = "awake"
+
+	// ActivatorService is the name of the service in the namespaces of sleeping
+	// applications, which forwards the requests for them to the activator of the epinio
+	// server, to wake them.
+	ActivatorService = "epinio-activator"
+
+	// Requests to the ingresses of an application within the idle time. The namespace
+	// label is `exported_namespace` when the metrics are scraped from the ingress
+	// controller's namespace, and `namespace` otherwise.
+	prometheusRequestsQuery = `sum(increase(nginx_ingress_controller_requests{exported_namespace="%[1]s",ingress=~"%[2]s"}[%[3]dm]))` +
+		` or sum(increase(nginx_ingress_controller_requests{namespace="%[1]s",ingress=~"%[2]s"}[%[3]dm]))`
+)
+
+// Sleeping returns true if the application is put to sleep, i.e. scaled to zero.
+func Sleeping(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (bool, error) {
+	scaleSecret, err := scaleLoad(ctx, cluster, appRef)
+	if err != nil {
+		return false, err
+	}
+
+	return string(scaleSecret.Data[sleepingKey]) == "true", nil
+}
+
+// SleepingSet puts the named application to sleep, or wakes it. Waking records the time, for
+// the idle time of the auto sleep to start from. It does not deploy the change.
+func SleepingSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, sleeping bool) error {
+	return scaleUpdate(ctx, cluster, appRef, func(scaleSecret *v1.Secret) {
+		if sleeping {
+			scaleSecret.Data[sleepingKey] = []byte("true")
+			return
+		}
+		delete(scaleSecret.Data, sleepingKey)
+		scaleSecret.Data[awakeKey] = []byte(time.Now().UTC().Format(time.RFC3339))
+	})
+}
+
+// AutoSleep returns the idle time in minutes after which the application is put to sleep.
+// Zero means that the application does not sleep automatically.
+func AutoSleep(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (int32, error) {
+	scaleSecret, err := scaleLoad(ctx, cluster, appRef)
+	if err != nil {
+		return 0, err
+	}
+
+	encoded, ok := scaleSecret.Data[autoSleepKey]
+	if !ok {
+		return 0, nil
+	}
+
+	minutes, err := strconv.ParseInt(string(encoded), 10, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "bad auto sleep")
+	}
+
+	return int32(minutes), nil
+}
+
+// AutoSleepSet sets the idle time in minutes after which the named application is put to
+// sleep. Zero disables the auto sleep.
+func AutoSleepSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, minutes int32) error {
+	return scaleUpdate(ctx, cluster, appRef, func(scaleSecret *v1.Secret) {
+		if minutes == 0 {
+			delete(scaleSecret.Data, autoSleepKey)
+			return
+		}
+		scaleSecret.Data[autoSleepKey] = []byte(strconv.Itoa(int(minutes)))
+	})
+}
+
+// ValidateAutoSleep checks that the idle time of the auto sleep is not negative, and that the
+// traffic of the applications is known, i.e. that a prometheus server is configured.
+func ValidateAutoSleep(minutes int32) error {
+	if minutes < 0 {
+		return errors.New("auto sleep should be >= 0 minutes")
+	}
+	if minutes > 0 && viper.GetString("prometheus-url") == "" {
+		return errors.New("auto sleep requires a prometheus server recording the ingress traffic")
+	}
+	return nil
+}
+
+// ActivatorEnabled returns true if requests for sleeping applications wake them.
+func ActivatorEnabled() bool {
+	return viper.GetString("activator-host") != "" && viper.GetInt("activator-port") > 0
+}
+
+// AppIdle returns true if the application is to be put to sleep, i.e. if it is awake and
+// deployed with auto sleep, got no requests through its routes for the idle time, and was not
+// woken within it.
+func AppIdle(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (bool, error) {
+	prometheusURL := viper.GetString("prometheus-url")
+	if prometheusURL == "" {
+		return false, nil
+	}
+
+	scaleSecret, err := scaleLoad(ctx, cluster, appRef)
+	if err != nil {
+		return false, err
+	}
+
+	minutes, err := strconv.Atoi(string(scaleSecret.Data[autoSleepKey]))
+	if err != nil || minutes <= 0 || string(scaleSecret.Data[sleepingKey]) == "true" {
+		return false, nil
+	}
+
+	if awake, err := time.Parse(time.RFC3339, string(scaleSecret.Data[awakeKey])); err == nil &&
+		time.Since(awake) < time.Duration(minutes)*time.Minute {
+		return false, nil
+	}
+
+	ingresses, err := ingressListForApp(ctx, cluster, appRef)
+	if err != nil {
+		return false, err
+	}
+	if len(ingresses.Items) == 0 {
+		// Not deployed, or without routes, i.e. without traffic to wake it.
+		return false, nil
+	}
+
+	names := []string{}
+	for _, ingress := range ingresses.Items {
+		names = append(names, regexp.QuoteMeta(ingress.Name))
+	}
+
+	requests, err := prometheusQuery(ctx, prometheusURL, RequestsQuery(appRef.Namespace, names, minutes), "")
+	if err != nil {
+		return false, err
+	}
+
+	// Without samples the ingresses got no requests.
+	return requests[""] == 0, nil
+}
+
+// RequestsQuery returns the prometheus query for the number of requests to the ingresses in
+// the namespace, in the last minutes. The ingresses are regular expressions.
+func RequestsQuery(namespace string, ingresses []string, minutes int) string {
+	return fmt.Sprintf(prometheusRequestsQuery, namespace, strings.Join(ingresses, "|"), minutes)
+}
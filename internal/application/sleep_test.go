@@ -0,0 +1,55 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auto sleep", func() {
+	Describe("RequestsQuery", func() {
+		It("sums the requests to the ingresses in the idle time", func() {
+			query := RequestsQuery("workspace", []string{"r1", "r2"}, 15)
+			Expect(query).To(Equal(
+				`sum(increase(nginx_ingress_controller_requests{exported_namespace="workspace",ingress=~"r1|r2"}[15m]))` +
+					` or sum(increase(nginx_ingress_controller_requests{namespace="workspace",ingress=~"r1|r2"}[15m]))`))
+		})
+	})
+
+	Describe("ValidateAutoSleep", func() {
+		AfterEach(func() {
+			viper.Set("prometheus-url", "")
+		})
+
+		It("rejects negative minutes", func() {
+			Expect(ValidateAutoSleep(-1)).To(MatchError(ContainSubstring(">= 0")))
+		})
+
+		It("requires a prometheus server", func() {
+			Expect(ValidateAutoSleep(0)).To(Succeed())
+			Expect(ValidateAutoSleep(10)).To(MatchError(ContainSubstring("prometheus")))
+
+			viper.Set("prometheus-url", "http://prometheus")
+			Expect(ValidateAutoSleep(10)).To(Succeed())
+		})
+	})
+
+	Describe("requests", func() {
+		It("takes the sum without labels", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+					{"metric":{},"value":[1660000000,"42"]}]}}`))
+			}))
+			defer server.Close()
+
+			requests, err := prometheusQuery(context.Background(), server.URL, RequestsQuery("workspace", []string{"r1"}, 5), "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests[""]).To(Equal(float64(42)))
+		})
+	})
+})
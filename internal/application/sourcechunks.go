@@ -0,0 +1,289 @@
+package application
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/s3manager"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+// sourceChunksPrefix is the common prefix of all cached chunks of app sources in the blob
+// store. The chunks are cached per application, as knowledge of a chunk's presence must not
+// leak to other users.
+const sourceChunksPrefix = "source-chunks/"
+
+var chunkHashRE = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// SourceChunksMissing returns the hashes of the chunks of app sources which are not cached for
+// the application, i.e. which have to be uploaded, for the assembly of sources uploaded as
+// delta.
+func SourceChunksMissing(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, hashes []string) ([]string, error) {
+	for _, hash := range hashes {
+		if err := ValidateChunkHash(hash); err != nil {
+			return nil, err
+		}
+	}
+
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := sourceChunks(ctx, manager, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	seen := map[string]struct{}{}
+	for _, hash := range hashes {
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+
+		if _, ok := cached[hash]; !ok {
+			missing = append(missing, hash)
+		}
+	}
+
+	return missing, nil
+}
+
+// SourceChunkStore caches the chunk of app sources for the application. The chunk is
+// rejected, and discarded, if its contents do not match the hash.
+func SourceChunkStore(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, hash string, data io.Reader, size int64) error {
+	if err := ValidateChunkHash(hash); err != nil {
+		return err
+	}
+
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	name := sourceChunkName(appRef, hash)
+	hasher := sha256.New()
+
+	err = manager.StoreObjectStream(ctx, name, io.TeeReader(data, hasher), size,
+		"application/octet-stream", map[string]string{
+			"Namespace": appRef.Namespace,
+			"App":       appRef.Name,
+		})
+	if err != nil {
+		return errors.Wrap(err, "storing the chunk")
+	}
+
+	if hex.EncodeToString(hasher.Sum(nil)) != hash {
+		if err := manager.DeleteObject(ctx, name); err != nil {
+			return errors.Wrap(err, "discarding the bad chunk")
+		}
+		return errors.Errorf("contents of chunk '%s' do not match its hash", hash)
+	}
+
+	return nil
+}
+
+// SourceDeltaAssemble stores the tarball of the app sources described by the files, with the
+// contents of the regular files assembled from the cached chunks. It returns the blobUID of
+// the tarball. Afterwards the cached chunks not used by the files are removed, i.e. the
+// cache holds the chunks of the last upload.
+func SourceDeltaAssemble(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, files []models.SourceFile, metadata map[string]string) (string, error) {
+	if err := ValidateSourceFiles(files); err != nil {
+		return "", err
+	}
+
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+
+	cached, err := sourceChunks(ctx, manager, appRef)
+	if err != nil {
+		return "", err
+	}
+
+	used := map[string]struct{}{}
+	for _, file := range files {
+		for _, hash := range file.Chunks {
+			if _, ok := cached[hash]; !ok {
+				return "", errors.Errorf("chunk '%s' of '%s' is missing", hash, file.Path)
+			}
+			used[hash] = struct{}{}
+		}
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(writeSourceTar(ctx, manager, appRef, writer, files))
+	}()
+
+	blobUID, err := manager.UploadStream(ctx, reader, -1, metadata)
+	_ = reader.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "assembling the application sources blob")
+	}
+
+	for hash := range cached {
+		if _, ok := used[hash]; ok {
+			continue
+		}
+		if err := manager.DeleteObject(ctx, sourceChunkName(appRef, hash)); err != nil {
+			return "", errors.Wrap(err, "removing unused chunk")
+		}
+	}
+
+	return blobUID, nil
+}
+
+// SourceChunksDelete removes all cached chunks of app sources of the application.
+func SourceChunksDelete(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) error {
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	cached, err := sourceChunks(ctx, manager, appRef)
+	if err != nil {
+		return err
+	}
+
+	for hash := range cached {
+		if err := manager.DeleteObject(ctx, sourceChunkName(appRef, hash)); err != nil {
+			return errors.Wrap(err, "removing chunk")
+		}
+	}
+
+	return nil
+}
+
+// ValidateChunkHash checks that the hash of a chunk is a hex-encoded sha256 hash.
+func ValidateChunkHash(hash string) error {
+	if !chunkHashRE.MatchString(hash) {
+		return errors.Errorf("bad chunk hash '%s'", hash)
+	}
+	return nil
+}
+
+// ValidateSourceFiles checks that the files of app sources uploaded as delta are unique, of
+// known type, and within the sources.
+func ValidateSourceFiles(files []models.SourceFile) error {
+	seen := map[string]struct{}{}
+	for _, file := range files {
+		clean := path.Clean(file.Path)
+		if file.Path == "" || clean != file.Path || path.IsAbs(clean) ||
+			clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return errors.Errorf("bad path '%s'", file.Path)
+		}
+		if _, ok := seen[clean]; ok {
+			return errors.Errorf("duplicate path '%s'", file.Path)
+		}
+		seen[clean] = struct{}{}
+
+		mode := os.FileMode(file.Mode)
+		switch {
+		case mode.IsDir(), mode&os.ModeSymlink != 0:
+			if len(file.Chunks) > 0 || file.Size != 0 {
+				return errors.Errorf("bad contents of '%s': not a regular file", file.Path)
+			}
+		case mode.IsRegular():
+			if file.Size < 0 {
+				return errors.Errorf("bad size of '%s'", file.Path)
+			}
+		default:
+			return errors.Errorf("bad type of '%s'", file.Path)
+		}
+
+		for _, hash := range file.Chunks {
+			if err := ValidateChunkHash(hash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SourceFilesSize returns the total size of the contents of the files, i.e. of the sources
+// they are assembled into.
+func SourceFilesSize(files []models.SourceFile) int64 {
+	var size int64
+	for _, file := range files {
+		size += file.Size
+	}
+	return size
+}
+
+// writeSourceTar writes the tarball of the app sources described by the files to the writer.
+// The names and headers match the tarballs created by the client, see helpers.Tar.
+func writeSourceTar(ctx context.Context, manager *s3manager.Manager, appRef models.AppRef, out io.Writer, files []models.SourceFile) error {
+	tw := tar.NewWriter(out)
+
+	for _, file := range files {
+		mode := os.FileMode(file.Mode)
+		header := &tar.Header{
+			Name: file.Path,
+			Mode: int64(mode.Perm()),
+			Size: file.Size,
+		}
+
+		switch {
+		case mode.IsDir():
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+		case mode&os.ModeSymlink != 0:
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = file.Link
+		default:
+			header.Typeflag = tar.TypeReg
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "writing header of '%s'", file.Path)
+		}
+
+		for _, hash := range file.Chunks {
+			chunk, err := manager.OpenObject(ctx, sourceChunkName(appRef, hash))
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(tw, chunk)
+			chunk.Close()
+			if err != nil {
+				return errors.Wrapf(err, "writing contents of '%s'", file.Path)
+			}
+		}
+	}
+
+	return tw.Close()
+}
+
+// sourceChunks returns the hashes of the chunks cached for the application.
+func sourceChunks(ctx context.Context, manager *s3manager.Manager, appRef models.AppRef) (map[string]struct{}, error) {
+	prefix := sourceChunkName(appRef, "")
+	names, err := manager.ObjectNames(ctx, prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the cached chunks")
+	}
+
+	result := map[string]struct{}{}
+	for _, name := range names {
+		result[strings.TrimPrefix(name, prefix)] = struct{}{}
+	}
+
+	return result, nil
+}
+
+// sourceChunkName returns the name of the chunk with the hash in the blob store.
+func sourceChunkName(appRef models.AppRef, hash string) string {
+	return sourceChunksPrefix + appRef.Namespace + "/" + appRef.Name + "/" + hash
+}
@@ -0,0 +1,73 @@
+package application_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SourceChunks", func() {
+	hash := strings.Repeat("ab", 32)
+
+	Describe("ValidateChunkHash", func() {
+		It("accepts hex-encoded sha256 hashes", func() {
+			Expect(application.ValidateChunkHash(hash)).To(Succeed())
+		})
+
+		It("rejects anything else", func() {
+			Expect(application.ValidateChunkHash("../" + hash)).ToNot(Succeed())
+			Expect(application.ValidateChunkHash(strings.ToUpper(hash))).ToNot(Succeed())
+			Expect(application.ValidateChunkHash(hash[1:])).ToNot(Succeed())
+		})
+	})
+
+	Describe("ValidateSourceFiles", func() {
+		It("accepts directories, regular files, and symbolic links", func() {
+			Expect(application.ValidateSourceFiles([]models.SourceFile{
+				{Path: "src", Mode: uint32(os.ModeDir | 0755)},
+				{Path: "src/main.go", Mode: 0644, Size: 12, Chunks: []string{hash}},
+				{Path: "src/empty", Mode: 0644},
+				{Path: "current", Mode: uint32(os.ModeSymlink | 0777), Link: "src"},
+			})).To(Succeed())
+		})
+
+		It("rejects paths outside of the sources", func() {
+			for _, path := range []string{"", ".", "..", "../etc/passwd", "/etc/passwd", "src/../../x", "src/"} {
+				err := application.ValidateSourceFiles([]models.SourceFile{{Path: path, Mode: 0644}})
+				Expect(err).To(HaveOccurred(), path)
+			}
+		})
+
+		It("rejects duplicate paths", func() {
+			err := application.ValidateSourceFiles([]models.SourceFile{
+				{Path: "main.go", Mode: 0644},
+				{Path: "main.go", Mode: 0644},
+			})
+			Expect(err).To(MatchError(ContainSubstring("duplicate path")))
+		})
+
+		It("rejects contents of directories, and special files", func() {
+			err := application.ValidateSourceFiles([]models.SourceFile{
+				{Path: "src", Mode: uint32(os.ModeDir | 0755), Size: 1, Chunks: []string{hash}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("not a regular file")))
+
+			err = application.ValidateSourceFiles([]models.SourceFile{
+				{Path: "socket", Mode: uint32(os.ModeSocket | 0755)},
+			})
+			Expect(err).To(MatchError(ContainSubstring("bad type")))
+		})
+
+		It("rejects bad chunk hashes", func() {
+			err := application.ValidateSourceFiles([]models.SourceFile{
+				{Path: "main.go", Mode: 0644, Size: 1, Chunks: []string{"nope"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("bad chunk hash")))
+		})
+	})
+})
@@ -0,0 +1,197 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/helpers/kubernetes/tailer"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/s3manager"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// stagingLogsPrefix is the common prefix of all archived staging logs in the blob store.
+const stagingLogsPrefix = "staging-logs/"
+
+// StagingLogsRetention returns the time archived staging logs are kept. Zero means forever.
+func StagingLogsRetention() time.Duration {
+	return viper.GetDuration("staging-logs-retention")
+}
+
+// StagingLogsArchive collects the logs of the completed staging job identified by
+// namespace and stage id, and stores them in the blob store, for retrieval after the
+// job is gone. Archived logs older than the retention are removed.
+func StagingLogsArchive(ctx context.Context, cluster *kubernetes.Cluster, namespace, stageID string) error {
+	lines := []tailer.ContainerLogLine{}
+	logChan := make(chan tailer.ContainerLogLine)
+	collected := make(chan struct{})
+
+	go func() {
+		for line := range logChan {
+			lines = append(lines, line)
+		}
+		close(collected)
+	}()
+
+	var wg sync.WaitGroup
+	err := Logs(ctx, logChan, &wg, cluster, false, "", stageID, namespace, models.LogParameters{})
+	wg.Wait()
+	close(logChan)
+	<-collected
+	if err != nil {
+		return errors.Wrap(err, "fetching the staging logs")
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	err = manager.StoreObject(ctx, stagingLogsName(namespace, stageID), data.Bytes(),
+		"application/x-ndjson", map[string]string{
+			"Namespace": namespace,
+			"Stage":     stageID,
+		})
+	if err != nil {
+		return errors.Wrap(err, "storing the staging logs")
+	}
+
+	if retention := StagingLogsRetention(); retention > 0 {
+		err := manager.DeleteObjectsBefore(ctx, stagingLogsPrefix, time.Now().Add(-retention))
+		if err != nil {
+			return errors.Wrap(err, "removing expired staging logs")
+		}
+	}
+
+	return nil
+}
+
+// StagingLogsArchived returns the archived logs of the staging job identified by namespace
+// and stage id. The result is nil if there are no such logs.
+func StagingLogsArchived(ctx context.Context, cluster *kubernetes.Cluster, namespace, stageID string) ([]tailer.ContainerLogLine, error) {
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := manager.FetchObject(ctx, stagingLogsName(namespace, stageID))
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	lines := []tailer.ContainerLogLine{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line tailer.ContainerLogLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, errors.Wrap(err, "bad archived staging log")
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// FilterLogLines returns the archived log lines restricted by the parameters, like Logs does
+// for the logs of running containers. The lines carry no time, so the duration is ignored.
+func FilterLogLines(lines []tailer.ContainerLogLine, params models.LogParameters) ([]tailer.ContainerLogLine, error) {
+	var filter *regexp.Regexp
+	if params.Filter != "" {
+		var err error
+		filter, err = regexp.Compile(params.Filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad filter")
+		}
+	}
+
+	result := []tailer.ContainerLogLine{}
+	for _, line := range lines {
+		if params.Instance != "" && line.PodName != params.Instance {
+			continue
+		}
+		if filter != nil && !filter.MatchString(line.Message) {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	if params.Tail == nil {
+		return result, nil
+	}
+
+	// Keep the last lines of each container, in the order of the archive.
+	counts := map[string]int64{}
+	keep := make([]bool, len(result))
+	for i := len(result) - 1; i >= 0; i-- {
+		container := result[i].PodName + "/" + result[i].ContainerName
+		if counts[container] < *params.Tail {
+			counts[container]++
+			keep[i] = true
+		}
+	}
+
+	tail := []tailer.ContainerLogLine{}
+	for i, line := range result {
+		if keep[i] {
+			tail = append(tail, line)
+		}
+	}
+	return tail, nil
+}
+
+// stagingLogsName returns the name of the blob holding the logs of the staging job
+// identified by namespace and stage id.
+func stagingLogsName(namespace, stageID string) string {
+	return path.Join(stagingLogsPrefix, namespace, stageID)
+}
+
+// blobStoreManager returns a manager for the blob store holding the archived logs, and the
+// cached chunks of app sources.
+func blobStoreManager(ctx context.Context, cluster *kubernetes.Cluster) (*s3manager.Manager, error) {
+	connectionDetails, err := s3manager.GetConnectionDetails(ctx, cluster,
+		helmchart.Namespace(), helmchart.S3ConnectionDetailsSecretName)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching the S3 connection details from the Kubernetes secret")
+	}
+
+	manager, err := s3manager.New(connectionDetails)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating an S3 manager")
+	}
+
+	return manager, nil
+}
+
+// archiveStagingLogs archives the logs of the staging job identified by namespace and stage
+// id, if they are not archived already. Failures are only logged, as they must not prevent
+// the removal of the job.
+func archiveStagingLogs(ctx context.Context, cluster *kubernetes.Cluster, namespace, stageID string) {
+	log := requestctx.Logger(ctx).WithName("staging-logs")
+
+	archived, err := StagingLogsArchived(ctx, cluster, namespace, stageID)
+	if err == nil && archived == nil {
+		err = StagingLogsArchive(ctx, cluster, namespace, stageID)
+	}
+	if err != nil {
+		log.Error(err, "archiving staging logs", "namespace", namespace, "stage", stageID)
+	}
+}
@@ -0,0 +1,51 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/helpers/kubernetes/tailer"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FilterLogLines", func() {
+	lines := []tailer.ContainerLogLine{
+		{PodName: "p1", ContainerName: "c", Message: "step 1 done"},
+		{PodName: "p2", ContainerName: "c", Message: "step 1 failed"},
+		{PodName: "p1", ContainerName: "c", Message: "step 2 done"},
+		{PodName: "p1", ContainerName: "c", Message: "step 3 failed"},
+	}
+
+	messages := func(lines []tailer.ContainerLogLine) []string {
+		result := []string{}
+		for _, line := range lines {
+			result = append(result, line.Message)
+		}
+		return result
+	}
+
+	It("keeps all lines without restrictions", func() {
+		filtered, err := application.FilterLogLines(lines, models.LogParameters{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal(lines))
+	})
+
+	It("keeps the lines of the instance, matching the filter", func() {
+		filtered, err := application.FilterLogLines(lines, models.LogParameters{Instance: "p1", Filter: "done$"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages(filtered)).To(Equal([]string{"step 1 done", "step 2 done"}))
+	})
+
+	It("keeps the last lines of each container", func() {
+		tail := int64(1)
+		filtered, err := application.FilterLogLines(lines, models.LogParameters{Tail: &tail})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages(filtered)).To(Equal([]string{"step 1 failed", "step 3 failed"}))
+	})
+
+	It("rejects bad filters", func() {
+		_, err := application.FilterLogLines(lines, models.LogParameters{Filter: "("})
+		Expect(err).To(MatchError(ContainSubstring("bad filter")))
+	})
+})
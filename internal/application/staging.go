@@ -0,0 +1,221 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	stagingKey = "staging"
+)
+
+// Staging returns the staging settings recorded for the application by its last staging.
+// Note that the builder image is recorded in the application resource instead.
+func Staging(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (models.ApplicationStage, error) {
+	var stage models.ApplicationStage
+
+	stagingSecret, err := stagingLoad(ctx, cluster, appRef)
+	if err != nil {
+		return stage, err
+	}
+
+	encoded, ok := stagingSecret.Data[stagingKey]
+	if !ok {
+		return stage, nil
+	}
+
+	if err := json.Unmarshal(encoded, &stage); err != nil {
+		return stage, errors.Wrap(err, "bad staging settings")
+	}
+
+	return stage, nil
+}
+
+// StagingSet records the staging settings of the named application.
+// When the function returns the settings are saved.
+func StagingSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, stage models.ApplicationStage) error {
+	encoded, err := json.Marshal(stage)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stagingSecret, err := stagingLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if stagingSecret.Data == nil {
+			stagingSecret.Data = make(map[string][]byte)
+		}
+		stagingSecret.Data[stagingKey] = encoded
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, stagingSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateBuilder checks that the builder image is a proper image reference, and that the
+// buildpacks are proper buildpack ids, or image references. An empty builder is ok.
+func ValidateBuilder(builderImage string, buildpacks []string) error {
+	if builderImage != "" {
+		if _, err := reference.ParseNormalizedNamed(builderImage); err != nil {
+			return errors.Wrapf(err, "bad builder image '%s'", builderImage)
+		}
+	}
+
+	for _, buildpack := range buildpacks {
+		if buildpack == "" || strings.ContainsAny(buildpack, ", \t\n") {
+			return errors.Errorf("bad buildpack '%s'", buildpack)
+		}
+		// Strip the scheme of image-based buildpacks, and the version of buildpack ids
+		ref := strings.TrimPrefix(buildpack, "docker://")
+		if _, err := reference.ParseNormalizedNamed(strings.SplitN(ref, "@", 2)[0]); err != nil {
+			return errors.Wrapf(err, "bad buildpack '%s'", buildpack)
+		}
+	}
+
+	return nil
+}
+
+// ValidateBuildEnvironment checks that the names of the build-time variables are proper
+// environment variable names.
+func ValidateBuildEnvironment(environment models.EnvVariableMap) error {
+	for name := range environment {
+		if errs := validation.IsEnvVarName(name); len(errs) > 0 {
+			return errors.Errorf("bad build environment variable '%s': %s", name, errs[0])
+		}
+	}
+	return nil
+}
+
+// ValidateStagingResources checks that the staging resources are proper kubernetes
+// quantities, that no request exceeds its limit, and that the timeout is not negative.
+func ValidateStagingResources(resources models.StagingResources) error {
+	_, err := StagingResourceRequirements(resources)
+	if err != nil {
+		return err
+	}
+	if resources.Timeout < 0 {
+		return errors.Errorf("bad staging timeout '%d', it cannot be negative", resources.Timeout)
+	}
+	return nil
+}
+
+// StagingResourcesMerge returns the staging resources of a namespace, with the gaps filled
+// from the global resources.
+func StagingResourcesMerge(space, global models.StagingResources) models.StagingResources {
+	if space.CPURequest == "" {
+		space.CPURequest = global.CPURequest
+	}
+	if space.CPULimit == "" {
+		space.CPULimit = global.CPULimit
+	}
+	if space.MemoryRequest == "" {
+		space.MemoryRequest = global.MemoryRequest
+	}
+	if space.MemoryLimit == "" {
+		space.MemoryLimit = global.MemoryLimit
+	}
+	if space.Timeout == 0 {
+		space.Timeout = global.Timeout
+	}
+	return space
+}
+
+// StagingResourceRequirements converts the staging resources into the resource requirements
+// of the staging job's containers.
+func StagingResourceRequirements(resources models.StagingResources) (v1.ResourceRequirements, error) {
+	requirements := v1.ResourceRequirements{}
+
+	quantities := []struct {
+		value string
+		name  v1.ResourceName
+		list  *v1.ResourceList
+	}{
+		{resources.CPURequest, v1.ResourceCPU, &requirements.Requests},
+		{resources.MemoryRequest, v1.ResourceMemory, &requirements.Requests},
+		{resources.CPULimit, v1.ResourceCPU, &requirements.Limits},
+		{resources.MemoryLimit, v1.ResourceMemory, &requirements.Limits},
+	}
+
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return requirements, errors.Wrapf(err, "bad staging %s '%s'", q.name, q.value)
+		}
+		if *q.list == nil {
+			*q.list = v1.ResourceList{}
+		}
+		(*q.list)[q.name] = quantity
+	}
+
+	for name, request := range requirements.Requests {
+		if limit, ok := requirements.Limits[name]; ok && request.Cmp(limit) > 0 {
+			return requirements, errors.Errorf("staging %s request '%s' exceeds the limit '%s'",
+				name, request.String(), limit.String())
+		}
+	}
+
+	return requirements, nil
+}
+
+// StagingPlacement decodes the node selector (a JSON object of labels) and the tolerations (a
+// JSON list of models.AppToleration) of the staging configuration into the scheduling
+// constraints of the staging jobs. These are independent of the placement of the
+// applications, to send the builds to dedicated nodes, e.g. an autoscaled pool of spot
+// instances. Empty values leave the jobs unconstrained.
+func StagingPlacement(nodeSelector, tolerations string) (map[string]string, []v1.Toleration, error) {
+	placement := models.AppPlacement{}
+
+	if nodeSelector != "" {
+		if err := json.Unmarshal([]byte(nodeSelector), &placement.NodeSelector); err != nil {
+			return nil, nil, errors.Wrap(err, "bad staging node selector")
+		}
+	}
+	if tolerations != "" {
+		if err := json.Unmarshal([]byte(tolerations), &placement.Tolerations); err != nil {
+			return nil, nil, errors.Wrap(err, "bad staging tolerations")
+		}
+	}
+
+	if err := ValidatePlacement(placement); err != nil {
+		return nil, nil, errors.Wrap(err, "bad staging placement")
+	}
+
+	var ts []v1.Toleration
+	for _, toleration := range placement.Tolerations {
+		ts = append(ts, v1.Toleration{
+			Key:               toleration.Key,
+			Operator:          v1.TolerationOperator(toleration.Operator),
+			Value:             toleration.Value,
+			Effect:            v1.TaintEffect(toleration.Effect),
+			TolerationSeconds: toleration.TolerationSeconds,
+		})
+	}
+
+	return placement.NodeSelector, ts, nil
+}
+
+// stagingLoad locates and returns the kube secret storing the referenced application's staging
+// settings. If necessary it creates that secret.
+func stagingLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeStagingSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "staging")
+}
@@ -0,0 +1,105 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Staging resources", func() {
+	global := models.StagingResources{
+		CPURequest:  "500m",
+		MemoryLimit: "2Gi",
+		Timeout:     600,
+	}
+
+	It("fills the gaps of the namespace from the global settings", func() {
+		merged := application.StagingResourcesMerge(models.StagingResources{
+			CPURequest: "1",
+			CPULimit:   "2",
+		}, global)
+
+		Expect(merged).To(Equal(models.StagingResources{
+			CPURequest:  "1",
+			CPULimit:    "2",
+			MemoryLimit: "2Gi",
+			Timeout:     600,
+		}))
+	})
+
+	It("converts the resources into requirements", func() {
+		requirements, err := application.StagingResourceRequirements(global)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requirements.Requests.Cpu().String()).To(Equal("500m"))
+		Expect(requirements.Limits.Memory().String()).To(Equal("2Gi"))
+		Expect(requirements.Limits).ToNot(HaveKey(v1.ResourceCPU))
+	})
+
+	It("rejects bad quantities", func() {
+		err := application.ValidateStagingResources(models.StagingResources{MemoryLimit: "lots"})
+		Expect(err).To(MatchError(ContainSubstring("bad staging memory 'lots'")))
+	})
+
+	It("rejects requests exceeding their limit", func() {
+		err := application.ValidateStagingResources(models.StagingResources{
+			CPURequest: "2",
+			CPULimit:   "1",
+		})
+		Expect(err).To(MatchError(ContainSubstring("exceeds the limit")))
+	})
+
+	It("rejects a negative timeout", func() {
+		err := application.ValidateStagingResources(models.StagingResources{Timeout: -1})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Staging placement", func() {
+	It("decodes the node selector and tolerations", func() {
+		nodeSelector, tolerations, err := application.StagingPlacement(
+			`{"node-pool":"build"}`,
+			`[{"key":"spot","operator":"Exists","effect":"NoSchedule"}]`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeSelector).To(Equal(map[string]string{"node-pool": "build"}))
+		Expect(tolerations).To(Equal([]v1.Toleration{{
+			Key:      "spot",
+			Operator: v1.TolerationOpExists,
+			Effect:   v1.TaintEffectNoSchedule,
+		}}))
+	})
+
+	It("leaves the jobs unconstrained without settings", func() {
+		nodeSelector, tolerations, err := application.StagingPlacement("", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeSelector).To(BeEmpty())
+		Expect(tolerations).To(BeEmpty())
+	})
+
+	It("rejects bad json", func() {
+		_, _, err := application.StagingPlacement(`node-pool=build`, "")
+		Expect(err).To(MatchError(ContainSubstring("bad staging node selector")))
+	})
+
+	It("rejects bad tolerations", func() {
+		_, _, err := application.StagingPlacement("", `[{"key":"spot","operator":"Maybe"}]`)
+		Expect(err).To(MatchError(ContainSubstring("bad operator 'Maybe'")))
+	})
+})
+
+var _ = Describe("Build environment", func() {
+	It("accepts proper variable names", func() {
+		err := application.ValidateBuildEnvironment(models.EnvVariableMap{
+			"BP_JVM_VERSION": "17",
+			"NODE_ENV":       "production",
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("rejects bad variable names", func() {
+		err := application.ValidateBuildEnvironment(models.EnvVariableMap{"BP JVM": "17"})
+		Expect(err).To(MatchError(ContainSubstring("bad build environment variable 'BP JVM'")))
+	})
+})
@@ -0,0 +1,119 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/metrics"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/go-logr/logr"
+	apibatchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const stagingEventsInterval = 5 * time.Second
+
+// StagingEnd is the end of a staging job, and the time from its start to its end.
+type StagingEnd struct {
+	Event    models.PlatformEvent
+	Duration time.Duration
+}
+
+// StagingEnds returns the ends of the staging jobs which are done, and not reported yet, and
+// the jobs reported now. A nil `reported` reports nothing, the jobs done already are the ones
+// done before the server was watching.
+func StagingEnds(jobs []apibatchv1.Job, reported map[string]bool) ([]StagingEnd, map[string]bool) {
+	result := []StagingEnd{}
+	done := map[string]bool{}
+
+	for _, job := range jobs {
+		if !jobDone(job) {
+			continue
+		}
+		done[job.Name] = true
+		if reported == nil || reported[job.Name] {
+			continue
+		}
+
+		eventType := models.EventStagingSucceeded
+		if jobFailed(job) {
+			eventType = models.EventStagingFailed
+		}
+		result = append(result, StagingEnd{
+			Event: models.PlatformEvent{
+				Type:      eventType,
+				Namespace: job.Labels[stagingJobNamespace],
+				Name:      job.Labels["app.kubernetes.io/name"],
+				User:      job.Labels["app.kubernetes.io/created-by"],
+				Details:   map[string]string{"stage_id": job.Labels[models.EpinioStageIDLabel]},
+			},
+			Duration: jobDuration(job),
+		})
+	}
+
+	return result, done
+}
+
+// StagingEventsLoop publishes the ends of the staging jobs, and records their durations,
+// until the context is done.
+func StagingEventsLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(stagingEventsInterval)
+	defer ticker.Stop()
+
+	var reported map[string]bool
+	for {
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "staging events: no cluster")
+		} else if jobList, err := cluster.ListJobs(ctx, helmchart.Namespace(), stagingJobSelector); err != nil {
+			logger.Error(err, "staging events: listing the staging jobs failed")
+		} else {
+			var ended []StagingEnd
+			ended, reported = StagingEnds(jobList.Items, reported)
+			for _, end := range ended {
+				events.Default.Publish(end.Event)
+				if end.Duration > 0 {
+					metrics.ObserveStaging(strings.TrimPrefix(end.Event.Type, "app.staging."), end.Duration)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// jobFailed returns true if the job failed.
+func jobFailed(job apibatchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status == v1.ConditionTrue && condition.Type == apibatchv1.JobFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// jobDuration returns the time from the start of the job to its end, zero if unknown.
+func jobDuration(job apibatchv1.Job) time.Duration {
+	if job.Status.StartTime == nil {
+		return 0
+	}
+	end := job.Status.CompletionTime
+	for _, condition := range job.Status.Conditions {
+		if condition.Status == v1.ConditionTrue && condition.Type == apibatchv1.JobFailed {
+			failedAt := condition.LastTransitionTime
+			end = &failedAt
+		}
+	}
+	if end == nil {
+		return 0
+	}
+	return end.Sub(job.Status.StartTime.Time)
+}
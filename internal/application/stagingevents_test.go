@@ -0,0 +1,88 @@
+package application_test
+
+import (
+	"time"
+
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("StagingEnds", func() {
+	job := func(id string, condition batchv1.JobConditionType) batchv1.Job {
+		j := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "stage-" + id,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":       "app-" + id,
+					"app.kubernetes.io/part-of":    "workspace",
+					"app.kubernetes.io/created-by": "alice",
+					models.EpinioStageIDLabel:      id,
+				},
+			},
+		}
+		if condition != "" {
+			j.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: v1.ConditionTrue}}
+		}
+		return j
+	}
+
+	It("reports nothing on the first look", func() {
+		ended, reported := application.StagingEnds([]batchv1.Job{
+			job("a", batchv1.JobComplete),
+			job("b", ""),
+		}, nil)
+		Expect(ended).To(BeEmpty())
+		Expect(reported).To(Equal(map[string]bool{"stage-a": true}))
+	})
+
+	It("reports the jobs ending, once", func() {
+		ended, reported := application.StagingEnds([]batchv1.Job{
+			job("a", batchv1.JobComplete),
+			job("b", batchv1.JobComplete),
+			job("c", batchv1.JobFailed),
+			job("d", ""),
+		}, map[string]bool{"stage-a": true})
+
+		Expect(ended).To(HaveLen(2))
+		Expect(ended[0].Event.Type).To(Equal(models.EventStagingSucceeded))
+		Expect(ended[0].Event.Namespace).To(Equal("workspace"))
+		Expect(ended[0].Event.Name).To(Equal("app-b"))
+		Expect(ended[0].Event.User).To(Equal("alice"))
+		Expect(ended[0].Event.Details).To(HaveKeyWithValue("stage_id", "b"))
+		Expect(ended[1].Event.Type).To(Equal(models.EventStagingFailed))
+		Expect(ended[1].Event.Name).To(Equal("app-c"))
+
+		ended, _ = application.StagingEnds([]batchv1.Job{
+			job("b", batchv1.JobComplete),
+			job("c", batchv1.JobFailed),
+		}, reported)
+		Expect(ended).To(BeEmpty())
+	})
+
+	It("measures the jobs from start to end", func() {
+		start := metav1.NewTime(time.Now().Add(-time.Minute))
+		end := metav1.NewTime(start.Add(42 * time.Second))
+
+		complete := job("a", batchv1.JobComplete)
+		complete.Status.StartTime = &start
+		complete.Status.CompletionTime = &end
+
+		failed := job("b", batchv1.JobFailed)
+		failed.Status.StartTime = &start
+		failed.Status.Conditions[0].LastTransitionTime = end
+
+		unstarted := job("c", batchv1.JobFailed)
+
+		ended, _ := application.StagingEnds([]batchv1.Job{complete, failed, unstarted}, map[string]bool{})
+		Expect(ended).To(HaveLen(3))
+		Expect(ended[0].Duration).To(Equal(42 * time.Second))
+		Expect(ended[1].Duration).To(Equal(42 * time.Second))
+		Expect(ended[2].Duration).To(BeZero())
+	})
+})
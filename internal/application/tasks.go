@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/helpers/randstr"
+	"github.com/epinio/epinio/internal/names"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	tasksKey = "tasks"
+)
+
+// Tasks returns the scheduled tasks of the application, ordered by name.
+func Tasks(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppTask, error) {
+	tasksSecret, err := tasksLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := tasksSecret.Data[tasksKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var tasks []models.AppTask
+	if err := json.Unmarshal(encoded, &tasks); err != nil {
+		return nil, errors.Wrap(err, "bad tasks")
+	}
+
+	return tasks, nil
+}
+
+// TasksSet replaces the scheduled tasks of the named application. When the function returns
+// the tasks are saved.
+func TasksSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, tasks []models.AppTask) error {
+	sorted := append([]models.AppTask{}, tasks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	encoded, err := json.Marshal(sorted)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tasksSecret, err := tasksLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if tasksSecret.Data == nil {
+			tasksSecret.Data = make(map[string][]byte)
+		}
+
+		if len(sorted) == 0 {
+			delete(tasksSecret.Data, tasksKey)
+		} else {
+			tasksSecret.Data[tasksKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, tasksSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// TaskStates returns the scheduled tasks of the application, together with their activity, as
+// recorded by the cron jobs the app chart created for them. Tasks without cron job, i.e.
+// of an application not deployed yet, are reported as inactive.
+func TaskStates(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (models.AppTaskList, error) {
+	tasks, err := Tasks(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobs, err := taskCronJobs(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	states := models.AppTaskList{}
+	for _, task := range tasks {
+		state := models.AppTaskStatus{AppTask: task}
+		if cronJob, ok := cronJobs[task.Name]; ok {
+			state.LastSchedule = cronJob.Status.LastScheduleTime
+			state.Active = len(cronJob.Status.Active)
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// TaskRun runs the named task of the application right now, outside of its schedule. It
+// returns the name of the job running the task.
+func TaskRun(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, taskName string) (string, error) {
+	cronJobs, err := taskCronJobs(ctx, cluster, appRef)
+	if err != nil {
+		return "", err
+	}
+
+	cronJob, ok := cronJobs[taskName]
+	if !ok {
+		return "", nil
+	}
+
+	id, err := randstr.Hex16()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate a uid")
+	}
+
+	labels := map[string]string{}
+	for key, value := range cronJob.Spec.JobTemplate.Labels {
+		labels[key] = value
+	}
+	labels[models.EpinioTaskLabel] = taskName
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        names.GenerateResourceName(cronJob.Name, "manual", id),
+			Namespace:   appRef.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(&cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+
+	created, err := cluster.Kubectl.BatchV1().Jobs(appRef.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "creating the task job")
+	}
+
+	return created.Name, nil
+}
+
+// ValidateTasks checks that the tasks have unique names usable in kube resource names, a
+// schedule, and a command.
+func ValidateTasks(tasks []models.AppTask) error {
+	seen := map[string]struct{}{}
+	for _, task := range tasks {
+		if errs := validation.IsDNS1123Label(task.Name); len(errs) > 0 {
+			return errors.Errorf("bad task name '%s': %s", task.Name, errs[0])
+		}
+		if _, ok := seen[task.Name]; ok {
+			return errors.Errorf("duplicate task name '%s'", task.Name)
+		}
+		seen[task.Name] = struct{}{}
+
+		if !validSchedule(task.Schedule) {
+			return errors.Errorf("bad schedule '%s' for task '%s', expected 5 cron fields, or a macro like @daily", task.Schedule, task.Name)
+		}
+		if len(task.Command) == 0 {
+			return errors.Errorf("task '%s' has no command", task.Name)
+		}
+	}
+	return nil
+}
+
+// validSchedule performs a basic check of the cron schedule. The full check is done by
+// kubernetes when the cron job is created.
+func validSchedule(schedule string) bool {
+	if strings.HasPrefix(schedule, "@") {
+		return len(schedule) > 1
+	}
+	return len(strings.Fields(schedule)) == 5
+}
+
+// taskCronJobs returns the cron jobs of the application's tasks, keyed by task name.
+func taskCronJobs(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (map[string]batchv1.CronJob, error) {
+	selector := fmt.Sprintf("app.kubernetes.io/name=%s,app.kubernetes.io/part-of=%s,%s",
+		appRef.Name, appRef.Namespace, models.EpinioTaskLabel)
+
+	cronJobList, err := cluster.Kubectl.BatchV1().CronJobs(appRef.Namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the task cron jobs")
+	}
+
+	cronJobs := map[string]batchv1.CronJob{}
+	for _, cronJob := range cronJobList.Items {
+		cronJobs[cronJob.Labels[models.EpinioTaskLabel]] = cronJob
+	}
+
+	return cronJobs, nil
+}
+
+// tasksLoad locates and returns the kube secret storing the referenced application's scheduled
+// tasks. If necessary it creates that secret.
+func tasksLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeTasksSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "tasks")
+}
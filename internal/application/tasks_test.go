@@ -0,0 +1,45 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateTasks", func() {
+	task := func(name, schedule string, command ...string) models.AppTask {
+		return models.AppTask{Name: name, Schedule: schedule, Command: command}
+	}
+
+	It("accepts proper tasks", func() {
+		Expect(application.ValidateTasks([]models.AppTask{
+			task("cleanup", "*/5 * * * *", "rake", "cleanup"),
+			task("report", "@daily", "./report.sh"),
+		})).To(Succeed())
+	})
+
+	It("rejects bad names", func() {
+		err := application.ValidateTasks([]models.AppTask{task("Clean_Up", "@daily", "true")})
+		Expect(err).To(MatchError(ContainSubstring("bad task name 'Clean_Up'")))
+	})
+
+	It("rejects duplicate names", func() {
+		err := application.ValidateTasks([]models.AppTask{
+			task("cleanup", "@daily", "true"),
+			task("cleanup", "@hourly", "true"),
+		})
+		Expect(err).To(MatchError(ContainSubstring("duplicate task name 'cleanup'")))
+	})
+
+	It("rejects bad schedules", func() {
+		err := application.ValidateTasks([]models.AppTask{task("cleanup", "* * *", "true")})
+		Expect(err).To(MatchError(ContainSubstring("bad schedule '* * *'")))
+	})
+
+	It("rejects missing commands", func() {
+		err := application.ValidateTasks([]models.AppTask{task("cleanup", "@daily")})
+		Expect(err).To(MatchError(ContainSubstring("has no command")))
+	})
+})
@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	terminationKey = "termination"
+
+	// maxGracePeriod is the longest grace period accepted for the shutdown of instances,
+	// one hour.
+	maxGracePeriod = 3600
+)
+
+// Termination returns the shutdown settings of the application, or nil, if it uses the
+// defaults.
+func Termination(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*models.AppTermination, error) {
+	terminationSecret, err := terminationLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := terminationSecret.Data[terminationKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var termination models.AppTermination
+	if err := json.Unmarshal(encoded, &termination); err != nil {
+		return nil, errors.Wrap(err, "bad termination")
+	}
+
+	return &termination, nil
+}
+
+// TerminationSet replaces the shutdown settings of the named application. Empty settings
+// restore the defaults. When the function returns the settings are saved.
+func TerminationSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, termination models.AppTermination) error {
+	encoded, err := json.Marshal(termination)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		terminationSecret, err := terminationLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		if terminationSecret.Data == nil {
+			terminationSecret.Data = make(map[string][]byte)
+		}
+
+		if termination.GracePeriod == 0 && len(termination.PreStop) == 0 {
+			delete(terminationSecret.Data, terminationKey)
+		} else {
+			terminationSecret.Data[terminationKey] = encoded
+		}
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, terminationSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// ValidateTermination checks the grace period of the shutdown, and that the pre-stop command,
+// if any, names a program.
+func ValidateTermination(termination models.AppTermination) error {
+	if termination.GracePeriod < 0 || termination.GracePeriod > maxGracePeriod {
+		return errors.Errorf("termination grace period should be 0 to %d seconds", maxGracePeriod)
+	}
+	if len(termination.PreStop) > 0 && termination.PreStop[0] == "" {
+		return errors.New("termination pre-stop command has no program")
+	}
+	return nil
+}
+
+// terminationLoad locates and returns the kube secret storing the referenced application's
+// shutdown settings. If necessary it creates that secret.
+func terminationLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeTerminationSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "termination")
+}
@@ -0,0 +1,35 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateTermination", func() {
+	It("accepts a grace period with pre-stop command", func() {
+		Expect(application.ValidateTermination(models.AppTermination{
+			GracePeriod: 120,
+			PreStop:     []string{"/bin/sh", "-c", "kill -TERM 1 && sleep 100"},
+		})).To(Succeed())
+	})
+
+	It("accepts the restoring of the defaults", func() {
+		Expect(application.ValidateTermination(models.AppTermination{})).To(Succeed())
+	})
+
+	It("rejects bad grace periods", func() {
+		err := application.ValidateTermination(models.AppTermination{GracePeriod: -1})
+		Expect(err).To(MatchError(ContainSubstring("grace period should be 0 to 3600 seconds")))
+
+		err = application.ValidateTermination(models.AppTermination{GracePeriod: 7200})
+		Expect(err).To(MatchError(ContainSubstring("grace period should be 0 to 3600 seconds")))
+	})
+
+	It("rejects pre-stop commands without program", func() {
+		err := application.ValidateTermination(models.AppTermination{PreStop: []string{""}})
+		Expect(err).To(MatchError(ContainSubstring("has no program")))
+	})
+})
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/go-logr/logr"
+)
+
+const (
+	// UploadExpiry is the time an upload of application sources in parts has to complete.
+	// Older uploads are discarded, with their parts, by UploadsSweepLoop.
+	UploadExpiry = 24 * time.Hour
+
+	uploadsSweepInterval = time.Hour
+)
+
+// UploadsSweepLoop periodically discards the uploads in parts which were neither completed,
+// nor aborted, within the UploadExpiry, until the context is done. Their parts would
+// otherwise stay in the S3 store.
+func UploadsSweepLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(uploadsSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "uploads sweep: no cluster")
+			continue
+		}
+
+		manager, err := blobStoreManager(ctx, cluster)
+		if err != nil {
+			logger.Error(err, "uploads sweep: no blob store")
+			continue
+		}
+
+		aborted, err := manager.AbortUploadsBefore(ctx, time.Now().Add(-UploadExpiry))
+		if err != nil {
+			logger.Error(err, "uploads sweep failed")
+		}
+		if aborted > 0 {
+			logger.Info("discarded expired uploads", "count", aborted)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	volumesKey = "volumes"
+)
+
+// Volumes returns the persistent volumes of the application, ordered by name.
+func Volumes(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) ([]models.AppVolume, error) {
+	volumesSecret, err := volumesLoad(ctx, cluster, appRef)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := volumesSecret.Data[volumesKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var volumes []models.AppVolume
+	if err := json.Unmarshal(encoded, &volumes); err != nil {
+		return nil, errors.Wrap(err, "bad volumes")
+	}
+
+	return volumes, nil
+}
+
+// VolumesSet merges the given volumes into the volumes of the named application, and
+// provisions the claims of new volumes. When the function returns the volumes are saved.
+func VolumesSet(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, volumes []models.AppVolume) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		volumesSecret, err := volumesLoad(ctx, cluster, appRef)
+		if err != nil {
+			return err
+		}
+
+		var current []models.AppVolume
+		if encoded, ok := volumesSecret.Data[volumesKey]; ok {
+			if err := json.Unmarshal(encoded, &current); err != nil {
+				return errors.Wrap(err, "bad volumes")
+			}
+		}
+
+		encoded, err := json.Marshal(MergeVolumes(current, volumes))
+		if err != nil {
+			return err
+		}
+
+		if volumesSecret.Data == nil {
+			volumesSecret.Data = make(map[string][]byte)
+		}
+		volumesSecret.Data[volumesKey] = encoded
+
+		_, err = cluster.Kubectl.CoreV1().Secrets(appRef.Namespace).Update(
+			ctx, volumesSecret, metav1.UpdateOptions{})
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return volumeClaimsEnsure(ctx, cluster, appRef, volumes)
+}
+
+// MergeVolumes returns the current volumes with the changes applied, ordered by name.
+// Changed volumes replace the current volumes of the same name.
+func MergeVolumes(current, changes []models.AppVolume) []models.AppVolume {
+	merged := map[string]models.AppVolume{}
+	for _, volume := range current {
+		merged[volume.Name] = volume
+	}
+	for _, volume := range changes {
+		merged[volume.Name] = volume
+	}
+
+	result := []models.AppVolume{}
+	for _, volume := range merged {
+		result = append(result, volume)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+// ValidateVolumes checks that the volumes have unique names usable in kube resource names,
+// absolute mount paths, proper sizes and access modes, and that volumes which cannot be
+// shared are not used by multiple instances.
+func ValidateVolumes(volumes []models.AppVolume, instances int32) error {
+	seen := map[string]struct{}{}
+	for _, volume := range volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			return errors.Errorf("bad volume name '%s': %s", volume.Name, errs[0])
+		}
+		if _, ok := seen[volume.Name]; ok {
+			return errors.Errorf("duplicate volume name '%s'", volume.Name)
+		}
+		seen[volume.Name] = struct{}{}
+
+		if !path.IsAbs(volume.Path) {
+			return errors.Errorf("volume '%s' is mounted at '%s', expected an absolute path", volume.Name, volume.Path)
+		}
+		if volume.Size != "" {
+			if _, err := resource.ParseQuantity(volume.Size); err != nil {
+				return errors.Errorf("bad size '%s' for volume '%s'", volume.Size, volume.Name)
+			}
+		}
+
+		switch v1.PersistentVolumeAccessMode(volume.AccessMode) {
+		case "", v1.ReadWriteOnce:
+			if instances > 1 {
+				return errors.Errorf("volume '%s' is ReadWriteOnce, and cannot be shared by %d instances, use access mode ReadWriteMany",
+					volume.Name, instances)
+			}
+		case v1.ReadWriteMany:
+		default:
+			return errors.Errorf("bad access mode '%s' for volume '%s', expected ReadWriteOnce, or ReadWriteMany",
+				volume.AccessMode, volume.Name)
+		}
+	}
+	return nil
+}
+
+// volumeClaimsEnsure creates the missing claims of the volumes. Existing claims are kept, as
+// long as they have the requested access mode.
+func volumeClaimsEnsure(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef, volumes []models.AppVolume) error {
+	app, err := Get(ctx, cluster, appRef)
+	if err != nil {
+		return errors.Wrapf(err, "error getting application resource")
+	}
+
+	claims := cluster.Kubectl.CoreV1().PersistentVolumeClaims(appRef.Namespace)
+
+	for _, volume := range volumes {
+		accessMode := v1.PersistentVolumeAccessMode(volume.AccessMode)
+		if accessMode == "" {
+			accessMode = v1.ReadWriteOnce
+		}
+
+		claimName := appRef.MakeVolumeClaimName(volume.Name)
+
+		claim, err := claims.Get(ctx, claimName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			if !hasAccessMode(claim, accessMode) {
+				return errors.Errorf("volume '%s' exists with access mode %v, unable to change it to %s",
+					volume.Name, claim.Spec.AccessModes, accessMode)
+			}
+			continue
+		}
+
+		size := volume.Size
+		if size == "" {
+			size = models.VolumeSizeDefault
+		}
+
+		// The claim is owned by the application, and removed with it.
+		_, err = claims.Create(ctx, &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      claimName,
+				Namespace: appRef.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":       appRef.Name,
+					"app.kubernetes.io/part-of":    appRef.Namespace,
+					"app.kubernetes.io/managed-by": "epinio",
+					"app.kubernetes.io/component":  "application",
+					EpinioApplicationAreaLabel:     "volume",
+				},
+				OwnerReferences: []metav1.OwnerReference{makeOwnerReference(app)},
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{accessMode},
+				Resources: v1.ResourceRequirements{
+					Requests: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceStorage: resource.MustParse(size),
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating the claim of volume '%s'", volume.Name)
+		}
+	}
+
+	return nil
+}
+
+// hasAccessMode returns true if the claim supports the access mode.
+func hasAccessMode(claim *v1.PersistentVolumeClaim, accessMode v1.PersistentVolumeAccessMode) bool {
+	for _, mode := range claim.Spec.AccessModes {
+		if mode == accessMode {
+			return true
+		}
+	}
+	return false
+}
+
+// volumesLoad locates and returns the kube secret storing the referenced application's
+// persistent volumes. If necessary it creates that secret.
+func volumesLoad(ctx context.Context, cluster *kubernetes.Cluster, appRef models.AppRef) (*v1.Secret, error) {
+	secretName := appRef.MakeVolumesSecretName()
+	return loadOrCreateSecret(ctx, cluster, appRef, secretName, "volumes")
+}
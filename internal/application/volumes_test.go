@@ -0,0 +1,69 @@
+package application_test
+
+import (
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Volumes", func() {
+	volume := func(name, path, size, accessMode string) models.AppVolume {
+		return models.AppVolume{Name: name, Path: path, Size: size, AccessMode: accessMode}
+	}
+
+	Describe("ValidateVolumes", func() {
+		It("accepts proper volumes", func() {
+			Expect(application.ValidateVolumes([]models.AppVolume{
+				volume("data", "/data", "5Gi", ""),
+				volume("uploads", "/app/uploads", "", "ReadWriteOnce"),
+			}, 1)).To(Succeed())
+		})
+
+		It("rejects bad names", func() {
+			err := application.ValidateVolumes([]models.AppVolume{volume("Data_1", "/data", "", "")}, 1)
+			Expect(err).To(MatchError(ContainSubstring("bad volume name 'Data_1'")))
+		})
+
+		It("rejects relative paths", func() {
+			err := application.ValidateVolumes([]models.AppVolume{volume("data", "data", "", "")}, 1)
+			Expect(err).To(MatchError(ContainSubstring("expected an absolute path")))
+		})
+
+		It("rejects bad sizes", func() {
+			err := application.ValidateVolumes([]models.AppVolume{volume("data", "/data", "lots", "")}, 1)
+			Expect(err).To(MatchError(ContainSubstring("bad size 'lots' for volume 'data'")))
+		})
+
+		It("rejects bad access modes", func() {
+			err := application.ValidateVolumes([]models.AppVolume{volume("data", "/data", "", "ReadOnlyMany")}, 1)
+			Expect(err).To(MatchError(ContainSubstring("bad access mode 'ReadOnlyMany'")))
+		})
+
+		It("rejects ReadWriteOnce volumes for multiple instances", func() {
+			err := application.ValidateVolumes([]models.AppVolume{volume("data", "/data", "", "")}, 3)
+			Expect(err).To(MatchError(ContainSubstring("cannot be shared by 3 instances")))
+		})
+
+		It("accepts ReadWriteMany volumes for multiple instances", func() {
+			Expect(application.ValidateVolumes([]models.AppVolume{
+				volume("data", "/data", "", "ReadWriteMany"),
+			}, 3)).To(Succeed())
+		})
+	})
+
+	Describe("MergeVolumes", func() {
+		It("replaces volumes by name, and keeps the others", func() {
+			merged := application.MergeVolumes(
+				[]models.AppVolume{volume("uploads", "/uploads", "", ""), volume("data", "/data", "1Gi", "")},
+				[]models.AppVolume{volume("data", "/var/data", "2Gi", ""), volume("cache", "/cache", "", "")},
+			)
+			Expect(merged).To(Equal([]models.AppVolume{
+				volume("cache", "/cache", "", ""),
+				volume("data", "/var/data", "2Gi", ""),
+				volume("uploads", "/uploads", "", ""),
+			}))
+		})
+	})
+})
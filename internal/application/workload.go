@@ -12,6 +12,7 @@ import (
 
 	"github.com/pkg/errors"
 	pkgerrors "github.com/pkg/errors"
+	"github.com/spf13/viper"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -154,32 +155,52 @@ func (a *Workload) PodNames(ctx context.Context) ([]string, error) {
 }
 
 // Replicas returns a slice of models.PodInfo. Each PodInfo matches a Pod belonging to
-// the application Deployment (workload).
-func (a *Workload) Replicas(ctx context.Context) (map[string]*models.PodInfo, error) {
+// the application Deployment (workload). It further returns the source of the resource
+// usage, which is empty when neither metrics server nor prometheus are available.
+func (a *Workload) Replicas(ctx context.Context) (map[string]*models.PodInfo, string, error) {
 	result := map[string]*models.PodInfo{}
 
 	deployment, err := a.Deployment(ctx)
 	if err != nil {
-		return result, err
+		return result, "", err
 	}
 	selector := labels.Set(deployment.Spec.Selector.MatchLabels).AsSelector().String()
 
 	pods, err := a.getPods(ctx, selector)
 	if err != nil {
-		return result, err
+		return result, "", err
 	}
-	podMetrics, err := a.getPodMetrics(ctx, selector)
+
+	result = a.generatePodInfo(pods)
+
+	source, err := a.populateMetrics(ctx, selector, result)
 	if err != nil {
-		return result, err
+		return result, "", err
 	}
 
-	result = a.generatePodInfo(pods)
+	return result, source, nil
+}
 
-	if err = a.populatePodMetrics(result, podMetrics); err != nil {
-		return result, err
+// populateMetrics adds the resource usage of the pods, from the metrics server, or, if that is
+// not available, from the prometheus server configured by the operator. Without either the
+// usage is left empty. The source of the usage is returned.
+func (a *Workload) populateMetrics(ctx context.Context, selector string, podInfos map[string]*models.PodInfo) (string, error) {
+	podMetrics, err := a.getPodMetrics(ctx, selector)
+	if err == nil {
+		return models.MetricsSourceServer, a.populatePodMetrics(podInfos, podMetrics)
 	}
 
-	return result, nil
+	prometheusURL := viper.GetString("prometheus-url")
+	if prometheusURL == "" {
+		return "", nil
+	}
+
+	err = populatePrometheusMetrics(ctx, prometheusURL, a.app.Namespace, podInfos)
+	if err != nil {
+		return "", err
+	}
+
+	return models.MetricsSourcePrometheus, nil
 }
 
 // Get returns the state of the app deployment encoded in the workload.
@@ -209,11 +230,24 @@ func (a *Workload) Get(ctx context.Context) (*models.AppDeployment, error) {
 		routes = []string{err.Error()}
 	}
 
-	replicas, err := a.Replicas(ctx)
+	ports, err := ExposedPorts(ctx, a.cluster, a.app)
+	if err != nil {
+		status = pkgerrors.Wrap(err, "failed to get exposed ports").Error()
+	}
+
+	replicas, metricsSource, err := a.Replicas(ctx)
 	if err != nil {
 		status = pkgerrors.Wrap(err, "failed to get replica details").Error()
 	}
 
+	var memoryBytes, milliCPUs int64
+	var restarts int32
+	for _, replica := range replicas {
+		memoryBytes += replica.MemoryBytes
+		milliCPUs += replica.MilliCPUs
+		restarts += replica.Restarts
+	}
+
 	return &models.AppDeployment{
 		Name:            deployment.Name,
 		Active:          true,
@@ -225,6 +259,11 @@ func (a *Workload) Get(ctx context.Context) (*models.AppDeployment, error) {
 		Routes:          routes,
 		DesiredReplicas: desiredReplicas,
 		ReadyReplicas:   readyReplicas,
+		MemoryBytes:     memoryBytes,
+		MilliCPUs:       milliCPUs,
+		Restarts:        restarts,
+		MetricsSource:   metricsSource,
+		Ports:           ports,
 	}, nil
 }
 
@@ -266,12 +305,35 @@ func (a *Workload) generatePodInfo(pods []corev1.Pod) map[string]*models.PodInfo
 			}
 		}
 
+		// The limits of the pod are only known when all its containers are limited.
+		memoryLimit := resource.NewQuantity(0, resource.BinarySI)
+		cpuLimit := resource.NewQuantity(0, resource.DecimalSI)
+		memoryLimited, cpuLimited := true, true
+		for _, container := range pod.Spec.Containers {
+			if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+				memoryLimit.Add(limit)
+			} else {
+				memoryLimited = false
+			}
+			if limit, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+				cpuLimit.Add(limit)
+			} else {
+				cpuLimited = false
+			}
+		}
+
 		result[pod.Name] = &models.PodInfo{
 			Name:      pod.Name,
 			Restarts:  restarts,
 			Ready:     podutils.IsPodReady(&pods[i]),
 			CreatedAt: pod.ObjectMeta.CreationTimestamp.Time.Format(time.RFC3339), // ISO 8601
 		}
+		if memoryLimited {
+			result[pod.Name].MemoryLimitBytes = memoryLimit.Value()
+		}
+		if cpuLimited {
+			result[pod.Name].MilliCPUsLimit = cpuLimit.MilliValue()
+		}
 	}
 
 	return result
@@ -0,0 +1,111 @@
+// Package audit records the requests of the API changing something, see models.AuditEntry. The
+// entries are written to the configured sink, and the recent ones are kept in memory, for the
+// admins to query.
+package audit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RecentSize is the number of recent entries kept in memory.
+const RecentSize = 1000
+
+// Default is the audit log of the server.
+var Default = NewLog(nil, RecentSize)
+
+// Log keeps the recent audit entries, and writes all entries to its sink.
+type Log struct {
+	sink Sink
+	size int
+
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+// NewLog returns an audit log writing to the sink, and keeping the last `size` entries. A nil
+// sink writes nowhere.
+func NewLog(sink Sink, size int) *Log {
+	return &Log{
+		sink:    sink,
+		size:    size,
+		entries: []models.AuditEntry{},
+	}
+}
+
+// Record keeps the entry, and writes it to the sink.
+func (l *Log) Record(ctx context.Context, entry models.AuditEntry) error {
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+	l.mu.Unlock()
+
+	if l.sink == nil {
+		return nil
+	}
+	return l.sink.Write(ctx, entry)
+}
+
+// Recent returns the recent entries of the user in the namespace, the newest first, at most
+// `limit` of them. Empty user and namespace match all entries, a limit of zero returns all.
+func (l *Log) Recent(user, namespace string, limit int) models.AuditEntryList {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := models.AuditEntryList{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		entry := l.entries[i]
+		if user != "" && entry.User != user {
+			continue
+		}
+		if namespace != "" && entry.Namespace != namespace {
+			continue
+		}
+		result = append(result, entry)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result
+}
+
+// Middleware records the requests changing something in the log, after they are handled. It
+// has to follow the authentication, for the user of the request.
+func Middleware(log *Log) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		ctx := c.Request.Context()
+		entry := models.AuditEntry{
+			Time:      time.Now(),
+			RequestID: requestctx.ID(ctx),
+			User:      requestctx.User(ctx).Username,
+			Verb:      c.Request.Method,
+			Resource:  c.Request.URL.Path,
+			Namespace: c.Param("namespace"),
+			Status:    c.Writer.Status(),
+			Result:    models.AuditResultSuccess,
+		}
+		if entry.Status >= http.StatusBadRequest {
+			entry.Result = models.AuditResultFailure
+		}
+
+		if err := log.Record(ctx, entry); err != nil {
+			requestctx.Logger(ctx).Error(err, "writing audit entry", "entry", entry)
+		}
+	}
+}
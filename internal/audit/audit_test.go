@@ -0,0 +1,144 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/epinio/epinio/internal/audit"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+
+	Describe("Log", func() {
+		It("returns the recent entries, the newest first", func() {
+			log := audit.NewLog(nil, 2)
+			for _, user := range []string{"jane", "john", "jim"} {
+				Expect(log.Record(context.Background(), models.AuditEntry{User: user})).To(Succeed())
+			}
+
+			entries := log.Recent("", "", 0)
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].User).To(Equal("jim"))
+			Expect(entries[1].User).To(Equal("john"))
+		})
+
+		It("filters the entries by user, and namespace", func() {
+			log := audit.NewLog(nil, 10)
+			Expect(log.Record(context.Background(), models.AuditEntry{User: "jane", Namespace: "workspace", Verb: "POST"})).To(Succeed())
+			Expect(log.Record(context.Background(), models.AuditEntry{User: "jane", Namespace: "staging"})).To(Succeed())
+			Expect(log.Record(context.Background(), models.AuditEntry{User: "john", Namespace: "workspace"})).To(Succeed())
+			Expect(log.Record(context.Background(), models.AuditEntry{User: "jane", Namespace: "workspace", Verb: "DELETE"})).To(Succeed())
+
+			entries := log.Recent("jane", "workspace", 0)
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Verb).To(Equal("DELETE"))
+
+			Expect(log.Recent("jane", "workspace", 1)).To(HaveLen(1))
+			Expect(log.Recent("", "workspace", 0)).To(HaveLen(3))
+		})
+	})
+
+	Describe("NewSink", func() {
+		It("rejects unknown sinks, and bad targets", func() {
+			_, err := audit.NewSink("syslog", "")
+			Expect(err).To(HaveOccurred())
+			_, err = audit.NewSink(audit.SinkFile, "")
+			Expect(err).To(HaveOccurred())
+			_, err = audit.NewSink(audit.SinkWebhook, "ftp://example.com")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns no sink for the empty kind", func() {
+			sink, err := audit.NewSink("", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sink).To(BeNil())
+		})
+
+		It("appends the entries to the file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "audit.log")
+			sink, err := audit.NewSink(audit.SinkFile, path)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sink.Write(context.Background(), models.AuditEntry{User: "jane"})).To(Succeed())
+			Expect(sink.Write(context.Background(), models.AuditEntry{User: "john"})).To(Succeed())
+
+			content, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var entry models.AuditEntry
+			Expect(json.Unmarshal([]byte(lines[1]), &entry)).To(Succeed())
+			Expect(entry.User).To(Equal("john"))
+		})
+
+		It("posts the entries to the webhook", func() {
+			received := make(chan models.AuditEntry, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var entry models.AuditEntry
+				_ = json.NewDecoder(r.Body).Decode(&entry)
+				received <- entry
+			}))
+			defer server.Close()
+
+			sink, err := audit.NewSink(audit.SinkWebhook, server.URL)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sink.Write(context.Background(), models.AuditEntry{User: "jane"})).To(Succeed())
+			Expect((<-received).User).To(Equal("jane"))
+		})
+	})
+
+	Describe("Middleware", func() {
+		var log *audit.Log
+		var router *gin.Engine
+
+		BeforeEach(func() {
+			gin.SetMode(gin.TestMode)
+			log = audit.NewLog(nil, 10)
+
+			router = gin.New()
+			router.Use(func(c *gin.Context) {
+				ctx := requestctx.WithUser(c.Request.Context(), auth.User{Username: "jane"})
+				c.Request = c.Request.WithContext(ctx)
+			}, audit.Middleware(log))
+			router.GET("/namespaces/:namespace", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.POST("/namespaces/:namespace/applications", func(c *gin.Context) { c.Status(http.StatusCreated) })
+			router.DELETE("/namespaces/:namespace", func(c *gin.Context) { c.Status(http.StatusForbidden) })
+		})
+
+		request := func(method, path string) {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+		}
+
+		It("records the requests changing something", func() {
+			request(http.MethodGet, "/namespaces/workspace")
+			request(http.MethodPost, "/namespaces/workspace/applications")
+			request(http.MethodDelete, "/namespaces/workspace")
+
+			entries := log.Recent("", "", 0)
+			Expect(entries).To(HaveLen(2))
+
+			Expect(entries[1].User).To(Equal("jane"))
+			Expect(entries[1].Verb).To(Equal(http.MethodPost))
+			Expect(entries[1].Resource).To(Equal("/namespaces/workspace/applications"))
+			Expect(entries[1].Namespace).To(Equal("workspace"))
+			Expect(entries[1].Status).To(Equal(http.StatusCreated))
+			Expect(entries[1].Result).To(Equal(models.AuditResultSuccess))
+
+			Expect(entries[0].Verb).To(Equal(http.MethodDelete))
+			Expect(entries[0].Result).To(Equal(models.AuditResultFailure))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of sinks
+const (
+	SinkEvents  = "events"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+)
+
+// webhookTimeout limits the time to post an entry to a webhook.
+const webhookTimeout = 10 * time.Second
+
+// Sink persists audit entries.
+type Sink interface {
+	Write(ctx context.Context, entry models.AuditEntry) error
+}
+
+// NewSink returns the sink of the kind. The target is the file to append the entries to, or
+// the URL of the webhook to post them to. The empty kind returns no sink.
+func NewSink(kind, target string) (Sink, error) {
+	switch kind {
+	case "":
+		return nil, nil
+	case SinkEvents:
+		return eventSink{}, nil
+	case SinkFile:
+		if target == "" {
+			return nil, errors.New("file audit sink without file")
+		}
+		return &fileSink{path: target}, nil
+	case SinkWebhook:
+		address, err := url.Parse(target)
+		if err != nil || address.Host == "" || (address.Scheme != "http" && address.Scheme != "https") {
+			return nil, errors.Errorf("webhook audit sink url `%s` is not an http(s) url", target)
+		}
+		return webhookSink{url: target, client: &http.Client{Timeout: webhookTimeout}}, nil
+	}
+	return nil, errors.Errorf("audit sink `%s` is not one of events, file, or webhook", kind)
+}
+
+// eventSink writes the entries as kube events of the epinio namespace.
+type eventSink struct{}
+
+func (s eventSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	namespace := helmchart.Namespace()
+	eventType := corev1.EventTypeNormal
+	if entry.Result != models.AuditResultSuccess {
+		eventType = corev1.EventTypeWarning
+	}
+
+	_, err = cluster.Kubectl.CoreV1().Events(namespace).Create(ctx, &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "epinio-audit-",
+			Labels: map[string]string{
+				"app.kubernetes.io/component": "audit",
+			},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       namespace,
+		},
+		Reason:         "Audit",
+		Type:           eventType,
+		Message:        fmt.Sprintf("%s %s %s: %d", entry.User, entry.Verb, entry.Resource, entry.Status),
+		FirstTimestamp: metav1.NewTime(entry.Time),
+		LastTimestamp:  metav1.NewTime(entry.Time),
+		Count:          1,
+		Source:         corev1.EventSource{Component: "epinio-server"},
+	}, metav1.CreateOptions{})
+
+	return errors.Wrap(err, "creating audit event")
+}
+
+// fileSink appends the entries to a file, as JSON lines.
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening audit file")
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return errors.Wrap(err, "writing audit file")
+}
+
+// webhookSink posts the entries to a webhook, as JSON.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s webhookSink) Write(ctx context.Context, entry models.AuditEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "posting audit entry")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Errorf("audit webhook returned '%s'", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// APITokenPrefix starts all API tokens, to tell them apart from other bearer tokens.
+const APITokenPrefix = "epn_"
+
+// Scopes of API tokens. Tokens with the read scope can only make requests which change
+// nothing.
+const (
+	APITokenScopeRead = "read"
+	APITokenScopeFull = "full"
+)
+
+var (
+	ErrAPITokenNotFound = errors.New("api token not found")
+	ErrAPITokenInvalid  = errors.New("api token invalid")
+)
+
+// APIToken is a token for the API, issued to a user, for use by scripts and pipelines instead
+// of the user's password. Only a hash of the secret part of the token is kept.
+type APIToken struct {
+	ID         string
+	Name       string
+	Username   string
+	Scope      string
+	Namespaces []string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+
+	hash string
+}
+
+// IsAPIToken returns true if the bearer token is an API token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// Expired returns true if the token is not valid anymore.
+func (t APIToken) Expired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// newAPITokenFromSecret creates an API token from its secret.
+func newAPITokenFromSecret(secret corev1.Secret) APIToken {
+	token := APIToken{
+		ID:         string(secret.Data["id"]),
+		Name:       string(secret.Data["name"]),
+		Username:   string(secret.Data["username"]),
+		Scope:      string(secret.Data["scope"]),
+		Namespaces: []string{},
+		CreatedAt:  secret.ObjectMeta.CreationTimestamp.Time,
+		hash:       string(secret.Data["hash"]),
+	}
+
+	// An unparseable expiry is the zero time, i.e. expired.
+	token.ExpiresAt, _ = time.Parse(time.RFC3339, string(secret.Data["expires"]))
+
+	for _, namespace := range strings.Split(string(secret.Data["namespaces"]), "\n") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			token.Namespaces = append(token.Namespaces, namespace)
+		}
+	}
+
+	return token
+}
+
+// CreateAPIToken issues a new API token to the user. It returns the token, and the secret
+// value to authenticate with, which is not kept, and cannot be retrieved later.
+func (s *AuthService) CreateAPIToken(ctx context.Context, username, name, scope string, namespaces []string, expiry time.Duration) (APIToken, string, error) {
+	if scope != APITokenScopeRead && scope != APITokenScopeFull {
+		return APIToken{}, "", errors.Errorf("bad scope '%s', expected '%s' or '%s'", scope, APITokenScopeRead, APITokenScopeFull)
+	}
+	if expiry <= 0 {
+		return APIToken{}, "", errors.New("the expiry of the token has to be positive")
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return APIToken{}, "", err
+	}
+	secretPart, err := randomHex(24)
+	if err != nil {
+		return APIToken{}, "", err
+	}
+
+	token := APIToken{
+		ID:         id,
+		Name:       name,
+		Username:   username,
+		Scope:      scope,
+		Namespaces: namespaces,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(expiry).Truncate(time.Second),
+		hash:       hashToken(secretPart),
+	}
+
+	_, err = s.SecretInterface.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: apiTokenSecretName(id),
+			Labels: map[string]string{
+				kubernetes.EpinioAPITokenLabelKey: kubernetes.EpinioAPITokenLabelValue,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			"id":         token.ID,
+			"name":       token.Name,
+			"username":   token.Username,
+			"scope":      token.Scope,
+			"namespaces": strings.Join(token.Namespaces, "\n"),
+			"expires":    token.ExpiresAt.UTC().Format(time.RFC3339),
+			"hash":       token.hash,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return APIToken{}, "", errors.Wrap(err, "error creating the api token secret")
+	}
+
+	return token, fmt.Sprintf("%s%s_%s", APITokenPrefix, id, secretPart), nil
+}
+
+// GetAPITokens returns the API tokens of the user, sorted by creation time, and id.
+func (s *AuthService) GetAPITokens(ctx context.Context, username string) ([]APIToken, error) {
+	secretSelector := labels.Set(map[string]string{
+		kubernetes.EpinioAPITokenLabelKey: kubernetes.EpinioAPITokenLabelValue,
+	}).AsSelector().String()
+
+	secretList, err := s.SecretInterface.List(ctx, metav1.ListOptions{
+		LabelSelector: secretSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the list of the api token secrets")
+	}
+
+	tokens := []APIToken{}
+	for _, secret := range secretList.Items {
+		token := newAPITokenFromSecret(secret)
+		if token.Username == username {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+
+	return tokens, nil
+}
+
+// GetAPIToken returns the API token of the user with the id. It returns ErrAPITokenNotFound
+// for tokens of other users.
+func (s *AuthService) GetAPIToken(ctx context.Context, username, id string) (APIToken, error) {
+	token, err := s.getAPIToken(ctx, id)
+	if err != nil {
+		return APIToken{}, err
+	}
+	if token.Username != username {
+		return APIToken{}, ErrAPITokenNotFound
+	}
+	return token, nil
+}
+
+// DeleteAPIToken revokes the API token of the user. It returns ErrAPITokenNotFound for
+// tokens of other users.
+func (s *AuthService) DeleteAPIToken(ctx context.Context, username, id string) error {
+	if _, err := s.GetAPIToken(ctx, username, id); err != nil {
+		return err
+	}
+
+	err := s.SecretInterface.Delete(ctx, apiTokenSecretName(id), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrAPITokenNotFound
+	}
+	return errors.Wrap(err, "error deleting the api token secret")
+}
+
+// ValidateAPIToken returns the API token the secret value belongs to. It returns
+// ErrAPITokenInvalid for unknown, and expired, tokens.
+func (s *AuthService) ValidateAPIToken(ctx context.Context, value string) (APIToken, error) {
+	pieces := strings.SplitN(strings.TrimPrefix(value, APITokenPrefix), "_", 2)
+	if !IsAPIToken(value) || len(pieces) != 2 {
+		return APIToken{}, ErrAPITokenInvalid
+	}
+
+	token, err := s.getAPIToken(ctx, pieces[0])
+	if err == ErrAPITokenNotFound {
+		return APIToken{}, ErrAPITokenInvalid
+	}
+	if err != nil {
+		return APIToken{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.hash), []byte(hashToken(pieces[1]))) != 1 {
+		return APIToken{}, ErrAPITokenInvalid
+	}
+	if token.Expired() {
+		return APIToken{}, ErrAPITokenInvalid
+	}
+
+	return token, nil
+}
+
+// getAPIToken returns the API token with the id.
+func (s *AuthService) getAPIToken(ctx context.Context, id string) (APIToken, error) {
+	secret, err := s.SecretInterface.Get(ctx, apiTokenSecretName(id), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return APIToken{}, ErrAPITokenNotFound
+	}
+	if err != nil {
+		return APIToken{}, errors.Wrap(err, "error getting the api token secret")
+	}
+	if secret.Labels[kubernetes.EpinioAPITokenLabelKey] != kubernetes.EpinioAPITokenLabelValue {
+		return APIToken{}, ErrAPITokenNotFound
+	}
+
+	return newAPITokenFromSecret(*secret), nil
+}
+
+func apiTokenSecretName(id string) string {
+	return "api-token-" + id
+}
+
+func hashToken(secretPart string) string {
+	sum := sha256.Sum256([]byte(secretPart))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", errors.Wrap(err, "error generating random token")
+	}
+	return hex.EncodeToString(buffer), nil
+}
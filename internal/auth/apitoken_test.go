@@ -0,0 +1,113 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("API tokens", func() {
+	var authService *auth.AuthService
+	var fake *authfakes.FakeSecretInterface
+	var stored *corev1.Secret
+
+	BeforeEach(func() {
+		fake = &authfakes.FakeSecretInterface{}
+		authService = &auth.AuthService{SecretInterface: fake}
+		stored = nil
+
+		// The fake keeps the one created secret, with its data as the API server does.
+		fake.CreateStub = func(ctx context.Context, secret *corev1.Secret, opts metav1.CreateOptions) (*corev1.Secret, error) {
+			stored = secret.DeepCopy()
+			stored.Data = map[string][]byte{}
+			for key, value := range secret.StringData {
+				stored.Data[key] = []byte(value)
+			}
+			return stored, nil
+		}
+		fake.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+			if stored == nil || stored.Name != name {
+				return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+			}
+			return stored, nil
+		}
+	})
+
+	It("validates the issued token", func() {
+		token, value, err := authService.CreateAPIToken(context.Background(), "jane", "ci", auth.APITokenScopeRead, []string{"workspace"}, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(auth.IsAPIToken(value)).To(BeTrue())
+		Expect(string(stored.Data["hash"])).ToNot(ContainSubstring(strings.Split(value, "_")[2]))
+
+		validated, err := authService.ValidateAPIToken(context.Background(), value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(validated.ID).To(Equal(token.ID))
+		Expect(validated.Username).To(Equal("jane"))
+		Expect(validated.Scope).To(Equal(auth.APITokenScopeRead))
+		Expect(validated.Namespaces).To(Equal([]string{"workspace"}))
+	})
+
+	It("rejects tokens with a wrong secret", func() {
+		_, value, err := authService.CreateAPIToken(context.Background(), "jane", "ci", auth.APITokenScopeFull, nil, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = authService.ValidateAPIToken(context.Background(), value+"x")
+		Expect(err).To(Equal(auth.ErrAPITokenInvalid))
+
+		_, err = authService.ValidateAPIToken(context.Background(), auth.APITokenPrefix+"unknown_secret")
+		Expect(err).To(Equal(auth.ErrAPITokenInvalid))
+	})
+
+	It("rejects expired tokens", func() {
+		_, value, err := authService.CreateAPIToken(context.Background(), "jane", "ci", auth.APITokenScopeFull, nil, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		stored.Data["expires"] = []byte(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+
+		_, err = authService.ValidateAPIToken(context.Background(), value)
+		Expect(err).To(Equal(auth.ErrAPITokenInvalid))
+	})
+
+	It("refuses bad scopes and expiries", func() {
+		_, _, err := authService.CreateAPIToken(context.Background(), "jane", "ci", "admin", nil, time.Hour)
+		Expect(err).To(HaveOccurred())
+
+		_, _, err = authService.CreateAPIToken(context.Background(), "jane", "ci", auth.APITokenScopeFull, nil, 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns the token of the user by id, and hides those of other users", func() {
+		token, _, err := authService.CreateAPIToken(context.Background(), "jane", "ci", auth.APITokenScopeRead, nil, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		found, err := authService.GetAPIToken(context.Background(), "jane", token.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found.Name).To(Equal("ci"))
+		Expect(found.Scope).To(Equal(auth.APITokenScopeRead))
+
+		_, err = authService.GetAPIToken(context.Background(), "john", token.ID)
+		Expect(err).To(Equal(auth.ErrAPITokenNotFound))
+		_, err = authService.GetAPIToken(context.Background(), "jane", "unknown")
+		Expect(err).To(Equal(auth.ErrAPITokenNotFound))
+	})
+
+	It("does not revoke the tokens of other users", func() {
+		token, _, err := authService.CreateAPIToken(context.Background(), "jane", "ci", auth.APITokenScopeFull, nil, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = authService.DeleteAPIToken(context.Background(), "john", token.ID)
+		Expect(err).To(Equal(auth.ErrAPITokenNotFound))
+		Expect(fake.DeleteCallCount()).To(Equal(0))
+
+		Expect(authService.DeleteAPIToken(context.Background(), "jane", token.ID)).To(Succeed())
+		Expect(fake.DeleteCallCount()).To(Equal(1))
+	})
+})
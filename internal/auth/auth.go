@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/names"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,7 +23,9 @@ import (
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserExists       = errors.New("user exists already")
+	ErrUserNotFederated = errors.New("user name taken by an Epinio user not marked as federated")
 )
 
 //counterfeiter:generate . SecretInterface
@@ -75,6 +79,75 @@ func (s *AuthService) GetUserByUsername(ctx context.Context, username string) (U
 	return User{}, ErrUserNotFound
 }
 
+// CreateUser creates the secret of a new user, with its password, role, namespaces, and
+// federation mark. It returns ErrUserExists if a user of the same name exists.
+func (s *AuthService) CreateUser(ctx context.Context, user User) error {
+	_, err := s.GetUserByUsername(ctx, user.Username)
+	if err == nil {
+		return ErrUserExists
+	}
+	if err != ErrUserNotFound {
+		return err
+	}
+
+	data := map[string]string{
+		"username": user.Username,
+		"password": user.Password,
+	}
+	if len(user.Namespaces) > 0 {
+		data["namespaces"] = strings.Join(user.namespaceEntries(), "\n")
+	}
+
+	annotations := map[string]string{}
+	if user.Federated {
+		annotations[FederatedAnnotation] = "true"
+	}
+
+	_, err = s.SecretInterface.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: names.GenerateResourceName("epinio-user", user.Username),
+			Labels: map[string]string{
+				kubernetes.EpinioAPISecretLabelKey:     kubernetes.EpinioAPISecretLabelValue,
+				kubernetes.EpinioAPISecretRoleLabelKey: user.Role,
+			},
+			Annotations: annotations,
+		},
+		Type:       "BasicAuth",
+		StringData: data,
+	}, metav1.CreateOptions{})
+
+	return errors.Wrap(err, fmt.Sprintf("error creating the user secret [%s]", user.Username))
+}
+
+// SetFederated marks the user as federated, i.e. as the same person as the user of the same
+// name at the LDAP server, or the OIDC issuer, or removes the mark. See ExternalUser.
+func (s *AuthService) SetFederated(ctx context.Context, username string, federated bool) error {
+	user, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	// note: Wrap (nil, ...) returns nil.
+	return errors.Wrap(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		userSecret, err := s.SecretInterface.Get(ctx, user.secretName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error getting the user secret [%s]", username))
+		}
+
+		if userSecret.Annotations == nil {
+			userSecret.Annotations = map[string]string{}
+		}
+		if federated {
+			userSecret.Annotations[FederatedAnnotation] = "true"
+		} else {
+			delete(userSecret.Annotations, FederatedAnnotation)
+		}
+
+		_, err = s.SecretInterface.Update(ctx, userSecret, metav1.UpdateOptions{})
+		return err
+	}), fmt.Sprintf("error setting the federation of user [%s]", username))
+}
+
 // GetUsersByAge returns the Epinio Users BasicAuth sorted from older to younger by CreationTime.
 func (s *AuthService) GetUsersByAge(ctx context.Context) ([]User, error) {
 	users, err := s.GetUsers(ctx)
@@ -98,6 +171,31 @@ func (s *AuthService) AddNamespaceToUser(ctx context.Context, username, namespac
 	return errors.Wrap(err, fmt.Sprintf("error updating user secret [%s]", username))
 }
 
+// SetNamespaceRole gives the user the role in the namespace, permanently. The role "none"
+// removes the namespace from the user.
+func (s *AuthService) SetNamespaceRole(ctx context.Context, username, namespace, role string) error {
+	return s.GrantNamespaceRole(ctx, username, namespace, role, time.Time{})
+}
+
+// GrantNamespaceRole gives the user the role in the namespace, until the expiry. The zero
+// expiry grants the role permanently. The role "none" removes the namespace from the user.
+func (s *AuthService) GrantNamespaceRole(ctx context.Context, username, namespace, role string, expiresAt time.Time) error {
+	user, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if role == "none" {
+		user.RemoveNamespace(namespace)
+	} else {
+		user.SetNamespaceRole(namespace, role)
+		user.SetNamespaceExpiry(namespace, expiresAt)
+	}
+
+	err = s.updateUserSecret(ctx, user)
+	return errors.Wrap(err, fmt.Sprintf("error updating user secret [%s]", username))
+}
+
 // RemoveNamespaceFromUsers will remove the specified namespace from all the users
 func (s *AuthService) RemoveNamespaceFromUsers(ctx context.Context, namespace string) error {
 	users, err := s.GetUsers(ctx)
@@ -150,13 +248,19 @@ func (s *AuthService) updateUserSecret(ctx context.Context, user User) error {
 			return errors.Wrap(err, fmt.Sprintf("error getting the user secret [%s]", user.Username))
 		}
 
+		userSecret.StringData = map[string]string{}
 		if len(user.Namespaces) > 0 {
-			userSecret.StringData = map[string]string{
-				"namespaces": strings.Join(user.Namespaces, "\n"),
-			}
+			userSecret.StringData["namespaces"] = strings.Join(user.namespaceEntries(), "\n")
+		} else if _, found := userSecret.Data["namespaces"]; found {
+			userSecret.StringData["namespaces"] = ""
+		}
+		if expiries := user.namespaceExpiryEntries(); len(expiries) > 0 {
+			userSecret.StringData["namespace-expiries"] = strings.Join(expiries, "\n")
+		} else if _, found := userSecret.Data["namespace-expiries"]; found {
+			userSecret.StringData["namespace-expiries"] = ""
 		}
 
 		_, err = s.SecretInterface.Update(ctx, userSecret, metav1.UpdateOptions{})
 		return err
-	}), fmt.Sprintf("error updating the user secret [%s]", user))
+	}), fmt.Sprintf("error updating the user secret [%s]", user.Username))
 }
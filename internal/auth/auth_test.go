@@ -197,6 +197,99 @@ var _ = Describe("Auth users", func() {
 			})
 		})
 	})
+
+	Describe("CreateUser", func() {
+		var fakeSecrets *authfakes.FakeSecretInterface
+
+		BeforeEach(func() {
+			fakeSecrets = &authfakes.FakeSecretInterface{}
+			authService = &auth.AuthService{SecretInterface: fakeSecrets}
+		})
+
+		It("creates the secret of the user, with its roles", func() {
+			fakeSecrets.ListReturns(&corev1.SecretList{}, nil)
+
+			err := authService.CreateUser(context.Background(), auth.User{
+				Username:       "jane",
+				Password:       "password",
+				Role:           "user",
+				Namespaces:     []string{"workspace", "production"},
+				NamespaceRoles: map[string]string{"production": auth.NamespaceRoleReader},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeSecrets.CreateCallCount()).To(Equal(1))
+			_, secret, _ := fakeSecrets.CreateArgsForCall(0)
+			Expect(string(secret.Type)).To(Equal("BasicAuth"))
+			Expect(secret.Labels[kubernetes.EpinioAPISecretLabelKey]).To(Equal(kubernetes.EpinioAPISecretLabelValue))
+			Expect(secret.Labels[kubernetes.EpinioAPISecretRoleLabelKey]).To(Equal("user"))
+			Expect(secret.StringData["username"]).To(Equal("jane"))
+			Expect(secret.StringData["password"]).To(Equal("password"))
+			Expect(secret.StringData["namespaces"]).To(Equal("workspace\nproduction:reader"))
+			Expect(secret.Annotations).ToNot(HaveKey(auth.FederatedAnnotation))
+		})
+
+		It("marks federated users", func() {
+			fakeSecrets.ListReturns(&corev1.SecretList{}, nil)
+
+			err := authService.CreateUser(context.Background(), auth.User{
+				Username:  "jane",
+				Password:  "password",
+				Role:      "user",
+				Federated: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, secret, _ := fakeSecrets.CreateArgsForCall(0)
+			Expect(secret.Annotations[auth.FederatedAnnotation]).To(Equal("true"))
+		})
+
+		It("rejects existing users", func() {
+			userSecrets := []corev1.Secret{newUserSecret("jane", "password", "user", "")}
+			fakeSecrets.ListReturns(&corev1.SecretList{Items: userSecrets}, nil)
+
+			err := authService.CreateUser(context.Background(), auth.User{Username: "jane", Password: "other", Role: "user"})
+			Expect(err).To(Equal(auth.ErrUserExists))
+			Expect(fakeSecrets.CreateCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("SetFederated", func() {
+		var secret corev1.Secret
+
+		BeforeEach(func() {
+			secret = newUserSecret("jane", "password", "user", "")
+			fakeSecrets := &authfakes.FakeSecretInterface{}
+			fakeSecrets.ListStub = func(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+				return &corev1.SecretList{Items: []corev1.Secret{secret}}, nil
+			}
+			fakeSecrets.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+				return secret.DeepCopy(), nil
+			}
+			fakeSecrets.UpdateStub = func(ctx context.Context, updated *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error) {
+				secret = *updated.DeepCopy()
+				return updated, nil
+			}
+			authService = &auth.AuthService{SecretInterface: fakeSecrets}
+		})
+
+		It("marks the user, and removes the mark", func() {
+			Expect(authService.SetFederated(context.Background(), "jane", true)).To(Succeed())
+			user, err := authService.GetUserByUsername(context.Background(), "jane")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.Federated).To(BeTrue())
+
+			Expect(authService.SetFederated(context.Background(), "jane", false)).To(Succeed())
+			user, err = authService.GetUserByUsername(context.Background(), "jane")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.Federated).To(BeFalse())
+		})
+
+		It("fails for unknown users", func() {
+			err := authService.SetFederated(context.Background(), "joe", true)
+			Expect(err).To(Equal(auth.ErrUserNotFound))
+		})
+	})
 })
 
 func newUserSecret(username, password, role, namespaces string) corev1.Secret {
@@ -36,3 +36,25 @@ func ExtendLocalTrust(certs string) {
 	// what this is a work around for.
 	http.DefaultTransport.(*http.Transport).ForceAttemptHTTP2 = false
 }
+
+// UseClientCertificate makes net/http's default transport, and the websocket's default
+// dialer, present the PEM encoded client certificate to servers asking for one, as the API
+// server does on its mutual TLS port.
+func UseClientCertificate(certPEM, keyPEM string) error {
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return err
+	}
+
+	config := http.DefaultTransport.(*http.Transport).TLSClientConfig
+	if config == nil {
+		config = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+		http.DefaultTransport.(*http.Transport).TLSClientConfig = config
+		websocket.DefaultDialer.TLSClientConfig = config
+	}
+	config.Certificates = []tls.Certificate{certificate}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ClientCA issues the client certificates of the machines enrolled for mutual TLS, and
+// verifies them.
+type ClientCA struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+	// PEM is the certificate, PEM encoded.
+	PEM []byte
+}
+
+// NewClientCA returns the CA of the PEM encoded certificate and key, usually the `tls.crt`
+// and `tls.key` of a secret.
+func NewClientCA(certPEM, keyPEM []byte) (*ClientCA, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad client CA")
+	}
+
+	certificate, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "bad client CA certificate")
+	}
+	if !certificate.IsCA {
+		return nil, errors.New("client CA certificate is not a CA")
+	}
+
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("client CA key cannot sign")
+	}
+
+	return &ClientCA{Certificate: certificate, Key: key, PEM: certPEM}, nil
+}
+
+// Pool returns the pool of the CA, for verifying client certificates.
+func (ca *ClientCA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+	return pool
+}
+
+// Issue signs the PEM encoded certificate request, and returns the PEM encoded client
+// certificate. The certificate is issued to the user, whatever the subject of the request,
+// and is valid for the ttl, at most as long as the CA.
+func (ca *ClientCA) Issue(csrPEM []byte, username string, ttl time.Duration) ([]byte, time.Time, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, time.Time{}, errors.New("certificate request is not PEM encoded")
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "bad certificate request")
+	}
+	if err := request.CheckSignature(); err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "bad certificate request signature")
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "error generating serial number")
+	}
+
+	now := time.Now()
+	expiry := now.Add(ttl).Truncate(time.Second)
+	if expiry.After(ca.Certificate.NotAfter) {
+		expiry = ca.Certificate.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: username},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     expiry,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, request.PublicKey, ca.Key)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "error issuing client certificate")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), expiry, nil
+}
+
+// ClientCertificateUser returns the user the verified client certificate of the connection
+// was issued to, the empty string if the connection has none.
+func ClientCertificateUser(state *tls.ConnectionState) string {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return state.VerifiedChains[0][0].Subject.CommonName
+}
+
+// NewClientCertificateRequest generates a key, and returns the PEM encoded certificate
+// request for it, and the key. The key does not leave the machine.
+func NewClientCertificateRequest(username string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error generating key")
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: username},
+	}, key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error creating certificate request")
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error encoding key")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
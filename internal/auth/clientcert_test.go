@@ -0,0 +1,96 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientCA", func() {
+	var ca *auth.ClientCA
+
+	newCA := func(isCA bool) ([]byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "epinio-client-ca"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(24 * time.Hour),
+			IsCA:                  isCA,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		}, &x509.Certificate{Subject: pkix.Name{CommonName: "epinio-client-ca"}}, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	BeforeEach(func() {
+		var err error
+		ca, err = auth.NewClientCA(newCA(true))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("refuses certificates which are not a CA", func() {
+		_, err := auth.NewClientCA(newCA(false))
+		Expect(err).To(MatchError("client CA certificate is not a CA"))
+	})
+
+	It("issues client certificates to the user", func() {
+		csr, key, err := auth.NewClientCertificateRequest("mallory")
+		Expect(err).ToNot(HaveOccurred())
+
+		certPEM, expiry, err := ca.Issue(csr, "jane", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expiry).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+		pair, err := tls.X509KeyPair(certPEM, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		certificate, err := x509.ParseCertificate(pair.Certificate[0])
+		Expect(err).ToNot(HaveOccurred())
+
+		chains, err := certificate.Verify(x509.VerifyOptions{
+			Roots:     ca.Pool(),
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(auth.ClientCertificateUser(&tls.ConnectionState{VerifiedChains: chains})).To(Equal("jane"))
+	})
+
+	It("limits the certificates to the lifetime of the CA", func() {
+		csr, _, err := auth.NewClientCertificateRequest("jane")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, expiry, err := ca.Issue(csr, "jane", 365*24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expiry).To(Equal(ca.Certificate.NotAfter))
+	})
+
+	It("refuses bad certificate requests", func() {
+		_, _, err := ca.Issue([]byte("not a request"), "jane", time.Hour)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("finds no user without verified client certificate", func() {
+		Expect(auth.ClientCertificateUser(nil)).To(Equal(""))
+		Expect(auth.ClientCertificateUser(&tls.ConnectionState{})).To(Equal(""))
+	})
+})
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// grantsCheckInterval is how often the grants loop looks for expired access.
+const grantsCheckInterval = time.Minute
+
+// RevokeExpiredGrants removes the users from the namespaces whose temporary access is over,
+// and returns the revoked grants, as `USER/NAMESPACE`.
+func (s *AuthService) RevokeExpiredGrants(ctx context.Context, now time.Time) ([]string, error) {
+	users, err := s.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := []string{}
+	for _, user := range users {
+		expired := user.ExpiredNamespaces(now)
+		if len(expired) == 0 {
+			continue
+		}
+		for _, namespace := range expired {
+			user.RemoveNamespace(namespace)
+		}
+		if err := s.updateUserSecret(ctx, user); err != nil {
+			return revoked, err
+		}
+		for _, namespace := range expired {
+			revoked = append(revoked, fmt.Sprintf("%s/%s", user.Username, namespace))
+		}
+	}
+
+	return revoked, nil
+}
+
+// GrantsLoop revokes the expired temporary access of the users every minute, until the
+// context is done.
+func GrantsLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(grantsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		authService, err := NewAuthServiceFromContext(ctx)
+		if err != nil {
+			logger.Error(err, "grants expiry: no cluster")
+			continue
+		}
+
+		revoked, err := authService.RevokeExpiredGrants(ctx, time.Now())
+		if err != nil {
+			logger.Error(err, "grants expiry failed")
+		}
+		for _, grant := range revoked {
+			logger.Info("revoked expired access", "grant", grant)
+		}
+	}
+}
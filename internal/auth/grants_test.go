@@ -0,0 +1,91 @@
+package auth_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Namespace access grants", func() {
+	var authService *auth.AuthService
+	var secret *corev1.Secret
+
+	BeforeEach(func() {
+		userSecret := newUserSecret("contractor", "password", "user", "workspace")
+		secret = &userSecret
+
+		fake := &authfakes.FakeSecretInterface{}
+		authService = &auth.AuthService{SecretInterface: fake}
+
+		fake.ListStub = func(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+			return &corev1.SecretList{Items: []corev1.Secret{*secret}}, nil
+		}
+		fake.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+			return secret.DeepCopy(), nil
+		}
+		fake.UpdateStub = func(ctx context.Context, updated *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error) {
+			secret = updated.DeepCopy()
+			for key, value := range updated.StringData {
+				secret.Data[key] = []byte(value)
+			}
+			secret.StringData = nil
+			return secret, nil
+		}
+	})
+
+	It("grants the role until the expiry", func() {
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		err := authService.GrantNamespaceRole(context.Background(), "contractor", "production", auth.NamespaceRoleDeveloper, expiresAt)
+		Expect(err).ToNot(HaveOccurred())
+
+		user, err := authService.GetUserByUsername(context.Background(), "contractor")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.NamespaceRole("production")).To(Equal(auth.NamespaceRoleDeveloper))
+		Expect(user.NamespaceExpiries["production"]).To(BeTemporally("==", expiresAt))
+		Expect(user.ExpiredNamespaces(time.Now())).To(BeEmpty())
+		Expect(user.ExpiredNamespaces(expiresAt)).To(ConsistOf("production"))
+	})
+
+	It("denies expired access before it is revoked", func() {
+		err := authService.GrantNamespaceRole(context.Background(), "contractor", "production", auth.NamespaceRoleReader, time.Now().Add(-time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+
+		user, err := authService.GetUserByUsername(context.Background(), "contractor")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.NamespaceRole("production")).To(BeEmpty())
+		Expect(user.NamespaceRole("workspace")).To(Equal(auth.NamespaceRoleAdmin))
+	})
+
+	It("makes the role permanent when set again without expiry", func() {
+		err := authService.GrantNamespaceRole(context.Background(), "contractor", "production", auth.NamespaceRoleReader, time.Now().Add(time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		err = authService.SetNamespaceRole(context.Background(), "contractor", "production", auth.NamespaceRoleReader)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(secret.Data["namespace-expiries"])).To(BeEmpty())
+	})
+
+	It("revokes the expired grants", func() {
+		err := authService.GrantNamespaceRole(context.Background(), "contractor", "production", auth.NamespaceRoleReader, time.Now().Add(time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+
+		revoked, err := authService.RevokeExpiredGrants(context.Background(), time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revoked).To(BeEmpty())
+
+		revoked, err = authService.RevokeExpiredGrants(context.Background(), time.Now().Add(2*time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revoked).To(ConsistOf("contractor/production"))
+
+		user, err := authService.GetUserByUsername(context.Background(), "contractor")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.Namespaces).To(ConsistOf("workspace"))
+		Expect(user.NamespaceExpiries).To(BeEmpty())
+	})
+})
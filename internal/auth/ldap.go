@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrLDAPInvalidCredentials = errors.New("invalid ldap credentials")
+)
+
+// ldapTimeout bounds the connection to the LDAP server, and each of its operations.
+const ldapTimeout = 10 * time.Second
+
+// LDAPIdentity is the user authenticated by the LDAP server.
+type LDAPIdentity struct {
+	Username string
+	DN       string
+	Groups   []string
+}
+
+// LDAPAuthenticator authenticates users with the password of their entry in an LDAP
+// directory, like Active Directory. The entry of the user is found with the service account,
+// or anonymously, and then bound to with the password of the user.
+//
+// The passwords are sent to the server, the connection has to be encrypted: either an
+// `ldaps://` url, or an `ldap://` url with StartTLS. Plain connections are refused, unless
+// AllowPlain.
+type LDAPAuthenticator struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	// StartTLS upgrades the connections to `ldap://` urls to TLS.
+	StartTLS bool
+	// CACert is the PEM encoded certificate of the CA of the server, for servers the system
+	// does not trust.
+	CACert string
+	// AllowPlain accepts `ldap://` urls without StartTLS, i.e. passwords sent in the clear.
+	AllowPlain bool
+	// UserBaseDN and UserFilter find the entry of the user. The `%s` of the filter is
+	// replaced by the name of the user.
+	UserBaseDN string
+	UserFilter string
+	// GroupBaseDN and GroupFilter find the groups of the user. The `%s` of the filter is
+	// replaced by the dn of the user. The groups are named by their GroupNameAttribute.
+	// Groups are not searched for without GroupBaseDN.
+	GroupBaseDN        string
+	GroupFilter        string
+	GroupNameAttribute string
+	// GroupRoles maps the groups of the users to the Epinio role they get.
+	GroupRoles map[string]string
+}
+
+// NewLDAPAuthenticator returns an authenticator of the users below the base dn of the
+// server. The users are found by their `uid`, and their groups by `member`, named by `cn`.
+func NewLDAPAuthenticator(url, userBaseDN string) *LDAPAuthenticator {
+	return &LDAPAuthenticator{
+		URL:                url,
+		UserBaseDN:         userBaseDN,
+		UserFilter:         "(uid=%s)",
+		GroupFilter:        "(member=%s)",
+		GroupNameAttribute: "cn",
+		GroupRoles:         map[string]string{},
+	}
+}
+
+// Authenticate checks the password of the user, and returns the user with their groups. It
+// returns ErrLDAPInvalidCredentials for unknown users, and bad passwords.
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, username, password string) (LDAPIdentity, error) {
+	identity := LDAPIdentity{Username: username}
+	if username == "" || password == "" {
+		return identity, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return identity, err
+	}
+	defer conn.Close()
+
+	// The client has no contexts, the connection is closed when the context is done,
+	// failing the operation in progress.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err := conn.Bind(a.BindDN, a.BindPassword); err != nil {
+		return identity, errors.Wrap(err, "binding with the ldap service account")
+	}
+
+	users, err := conn.Search(searchRequest(a.UserBaseDN, fmt.Sprintf(a.UserFilter, ldap.EscapeFilter(username)), "dn"))
+	if err != nil {
+		return identity, errors.Wrap(err, "searching the ldap user")
+	}
+	if len(users.Entries) != 1 {
+		return identity, ErrLDAPInvalidCredentials
+	}
+	identity.DN = users.Entries[0].DN
+
+	err = conn.Bind(identity.DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return identity, ErrLDAPInvalidCredentials
+	}
+	if err != nil {
+		return identity, errors.Wrap(err, "binding as the ldap user")
+	}
+
+	if a.GroupBaseDN == "" {
+		return identity, nil
+	}
+
+	// The groups may not be visible to the user, they are searched as the service account.
+	if err := conn.Bind(a.BindDN, a.BindPassword); err != nil {
+		return identity, errors.Wrap(err, "binding with the ldap service account")
+	}
+
+	groups, err := conn.Search(searchRequest(a.GroupBaseDN, fmt.Sprintf(a.GroupFilter, ldap.EscapeFilter(identity.DN)), a.GroupNameAttribute))
+	if err != nil {
+		return identity, errors.Wrap(err, "searching the ldap groups")
+	}
+	for _, group := range groups.Entries {
+		identity.Groups = append(identity.Groups, group.GetAttributeValues(a.GroupNameAttribute)...)
+	}
+
+	return identity, nil
+}
+
+// dial connects to the server, over TLS, or upgraded to it with StartTLS.
+func (a *LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	config, err := a.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := ldap.DialURL(a.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(config))
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to the ldap server")
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if a.StartTLS {
+		if err := conn.StartTLS(config); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "starting tls with the ldap server")
+		}
+	}
+
+	return conn, nil
+}
+
+// tlsConfig returns the configuration of the TLS connections to the server, trusting the CA
+// of the settings, if any, besides those of the system.
+func (a *LDAPAuthenticator) tlsConfig() (*tls.Config, error) {
+	address, err := url.Parse(a.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad ldap url")
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: address.Hostname(),
+	}
+	if a.CACert != "" {
+		rootCAs, _ := x509.SystemCertPool()
+		if rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM([]byte(a.CACert)) {
+			return nil, errors.New("ldap ca certificate is not a PEM encoded certificate")
+		}
+		config.RootCAs = rootCAs
+	}
+	return config, nil
+}
+
+// searchRequest returns the request for the attribute of the entries matching the filter,
+// below the base dn.
+func searchRequest(baseDN, filter, attribute string) *ldap.SearchRequest {
+	return ldap.NewSearchRequest(baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(ldapTimeout.Seconds()), false, filter, []string{attribute}, nil)
+}
+
+// Plain returns true if the connections to the server are not encrypted, i.e. for `ldap://`
+// urls without StartTLS.
+func (a *LDAPAuthenticator) Plain() bool {
+	return strings.HasPrefix(strings.ToLower(a.URL), "ldap://") && !a.StartTLS
+}
+
+// Role returns the role given to the groups, the empty string if none of them has one. The
+// admin role wins over any other.
+func (a *LDAPAuthenticator) Role(groups []string) string {
+	return groupRole(a.GroupRoles, groups)
+}
+
+// Validate checks the settings of the authenticator. Plain connections are refused, unless
+// explicitly allowed.
+func (a *LDAPAuthenticator) Validate() error {
+	address, err := url.Parse(a.URL)
+	if err != nil || (address.Scheme != "ldap" && address.Scheme != "ldaps") || address.Host == "" {
+		return errors.Errorf("ldap url `%s` is not an ldap:// or ldaps:// url", a.URL)
+	}
+	if address.Scheme == "ldaps" && a.StartTLS {
+		return errors.New("ldap StartTLS is for ldap:// urls, ldaps:// urls use TLS already")
+	}
+	if a.Plain() && !a.AllowPlain {
+		return errors.Errorf("ldap url `%s` sends the passwords in the clear, use ldaps://, or StartTLS", a.URL)
+	}
+	if _, err := a.tlsConfig(); err != nil {
+		return err
+	}
+	if a.UserBaseDN == "" {
+		return errors.New("ldap user base dn is not set")
+	}
+	if err := validateFilter(a.UserFilter); err != nil {
+		return err
+	}
+	if a.GroupBaseDN != "" {
+		if err := validateFilter(a.GroupFilter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFilter checks that the filter has exactly one `%s`.
+func validateFilter(filter string) error {
+	if strings.Count(filter, "%") != 1 || !strings.Contains(filter, "%s") {
+		return errors.Errorf("ldap filter `%s` has to contain `%%s` once", filter)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package auth_test
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/internal/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LDAPAuthenticator", func() {
+	var authenticator *auth.LDAPAuthenticator
+
+	BeforeEach(func() {
+		authenticator = auth.NewLDAPAuthenticator("ldaps://127.0.0.1:1", "ou=people,dc=example,dc=org")
+	})
+
+	Describe("Validate", func() {
+		It("accepts the defaults", func() {
+			Expect(authenticator.Validate()).To(Succeed())
+		})
+
+		It("refuses plain connections, unless allowed", func() {
+			authenticator.URL = "ldap://127.0.0.1:1"
+			Expect(authenticator.Plain()).To(BeTrue())
+			Expect(authenticator.Validate()).To(MatchError(ContainSubstring("sends the passwords in the clear")))
+
+			authenticator.AllowPlain = true
+			Expect(authenticator.Validate()).To(Succeed())
+
+			authenticator.AllowPlain = false
+			authenticator.StartTLS = true
+			Expect(authenticator.Plain()).To(BeFalse())
+			Expect(authenticator.Validate()).To(Succeed())
+		})
+
+		It("refuses StartTLS for ldaps urls", func() {
+			authenticator.StartTLS = true
+			Expect(authenticator.Validate()).To(MatchError(ContainSubstring("use TLS already")))
+		})
+
+		It("needs an ldap url", func() {
+			authenticator.URL = "https://127.0.0.1"
+			Expect(authenticator.Validate()).To(MatchError(ContainSubstring("is not an ldap:// or ldaps:// url")))
+		})
+
+		It("needs a PEM encoded ca certificate", func() {
+			authenticator.CACert = "not a certificate"
+			Expect(authenticator.Validate()).To(MatchError(ContainSubstring("not a PEM encoded certificate")))
+		})
+
+		It("needs the user base dn", func() {
+			authenticator.UserBaseDN = ""
+			Expect(authenticator.Validate()).To(MatchError("ldap user base dn is not set"))
+		})
+
+		It("needs a single placeholder in the filters", func() {
+			authenticator.UserFilter = "(uid=jane)"
+			Expect(authenticator.Validate()).ToNot(Succeed())
+
+			authenticator.UserFilter = "(&(uid=%s)(cn=%s))"
+			Expect(authenticator.Validate()).ToNot(Succeed())
+
+			authenticator.UserFilter = "(uid=%s)"
+			authenticator.GroupBaseDN = "ou=groups,dc=example,dc=org"
+			authenticator.GroupFilter = "(member=%d)"
+			Expect(authenticator.Validate()).ToNot(Succeed())
+		})
+	})
+
+	Describe("Role", func() {
+		It("maps the groups to the strongest role", func() {
+			authenticator.GroupRoles = map[string]string{"developers": "user", "operators": "admin"}
+
+			Expect(authenticator.Role([]string{"developers"})).To(Equal("user"))
+			Expect(authenticator.Role([]string{"developers", "operators"})).To(Equal("admin"))
+			Expect(authenticator.Role([]string{"guests"})).To(Equal(""))
+		})
+	})
+
+	Describe("Authenticate", func() {
+		It("refuses empty passwords without asking the server", func() {
+			_, err := authenticator.Authenticate(context.Background(), "jane", "")
+			Expect(err).To(Equal(auth.ErrLDAPInvalidCredentials))
+		})
+	})
+})
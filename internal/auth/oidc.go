@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OIDCKeysRefetchInterval is the minimum time between two fetches of the signing keys
+	// of an issuer. Tokens signed by a key still unknown after a fetch are rejected until
+	// it is over.
+	OIDCKeysRefetchInterval = 5 * time.Minute
+
+	// OIDCKeysRetryInterval is the time after a failed fetch of the signing keys before
+	// they are fetched again.
+	OIDCKeysRetryInterval = 10 * time.Second
+
+	// oidcFetchTimeout bounds a fetch of the signing keys, discovery included. The fetch
+	// is shared by the waiting requests, it does not end with the one which started it.
+	oidcFetchTimeout = 30 * time.Second
+)
+
+// oidcClient talks to the OIDC issuers. The timeout keeps a slow, or unresponsive, issuer from
+// blocking the requests waiting for its keys.
+var oidcClient = &http.Client{Timeout: 10 * time.Second}
+
+// OIDCProvider holds the endpoints of an OIDC issuer, as found by DiscoverOIDC.
+type OIDCProvider struct {
+	Issuer                      string `json:"issuer"`
+	JWKSURI                     string `json:"jwks_uri"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// DiscoverOIDC returns the endpoints of the issuer, from its openid configuration.
+func DiscoverOIDC(ctx context.Context, issuer string) (OIDCProvider, error) {
+	var provider OIDCProvider
+
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, wellKnown, &provider); err != nil {
+		return provider, errors.Wrapf(err, "discovering OIDC issuer '%s'", issuer)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return provider, errors.Errorf("OIDC issuer '%s' claims to be '%s'", issuer, provider.Issuer)
+	}
+
+	return provider, nil
+}
+
+// OIDCIdentity is the user identified by an ID token.
+type OIDCIdentity struct {
+	Username string
+	Groups   []string
+}
+
+// OIDCVerifier validates the ID tokens issued by an OIDC issuer for a client.
+type OIDCVerifier struct {
+	Issuer        string
+	ClientID      string
+	UsernameClaim string
+	GroupsClaim   string
+	// GroupRoles maps the groups of the users to the Epinio role they get.
+	GroupRoles map[string]string
+	// RefetchInterval is the minimum time between two fetches of the signing keys.
+	RefetchInterval time.Duration
+	// RetryInterval is the time after a failed fetch of the signing keys before the next.
+	RetryInterval time.Duration
+
+	mu        sync.Mutex
+	jwksURI   string
+	keys      map[string]interface{}
+	fetchedAt time.Time
+	failedAt  time.Time
+	fetching  chan struct{}
+}
+
+// NewOIDCVerifier returns a verifier for the ID tokens of the issuer, for the client. The
+// user is named by the `email` claim, and the groups are found in the `groups` claim.
+func NewOIDCVerifier(issuer, clientID string) *OIDCVerifier {
+	return &OIDCVerifier{
+		Issuer:          issuer,
+		ClientID:        clientID,
+		UsernameClaim:   "email",
+		GroupsClaim:     "groups",
+		GroupRoles:      map[string]string{},
+		RefetchInterval: OIDCKeysRefetchInterval,
+		RetryInterval:   OIDCKeysRetryInterval,
+	}
+}
+
+// ParseGroupRoles parses a comma separated list of `group=role` assignments.
+func ParseGroupRoles(assignments string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, assignment := range strings.Split(assignments, ",") {
+		assignment = strings.TrimSpace(assignment)
+		if assignment == "" {
+			continue
+		}
+		pieces := strings.SplitN(assignment, "=", 2)
+		if len(pieces) != 2 || pieces[0] == "" || pieces[1] == "" {
+			return nil, errors.Errorf("bad group role assignment '%s', expected GROUP=ROLE", assignment)
+		}
+		result[pieces[0]] = pieces[1]
+	}
+	return result, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of the ID token, and returns the
+// user it identifies.
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (OIDCIdentity, error) {
+	var identity OIDCIdentity
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		"RS256", "RS384", "RS512", "ES256", "ES384", "ES512",
+	}))
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return identity, errors.Wrap(err, "invalid ID token")
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if strings.TrimSuffix(issuer, "/") != strings.TrimSuffix(v.Issuer, "/") {
+		return identity, errors.New("ID token of another issuer")
+	}
+	if !claims.VerifyAudience(v.ClientID, true) {
+		return identity, errors.New("ID token for another client")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return identity, errors.New("ID token without expiry")
+	}
+
+	identity.Username, _ = claims[v.UsernameClaim].(string)
+	if identity.Username == "" {
+		return identity, errors.Errorf("ID token without '%s' claim", v.UsernameClaim)
+	}
+
+	switch groups := claims[v.GroupsClaim].(type) {
+	case string:
+		identity.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	}
+
+	return identity, nil
+}
+
+// Role returns the role given to the groups, the empty string if none of them has one. The
+// admin role wins over any other.
+func (v *OIDCVerifier) Role(groups []string) string {
+	return groupRole(v.GroupRoles, groups)
+}
+
+// groupRole returns the role the groupRoles give to the groups. The admin role wins over any
+// other.
+func groupRole(groupRoles map[string]string, groups []string) string {
+	role := ""
+	for _, group := range groups {
+		switch groupRole := groupRoles[group]; {
+		case groupRole == "admin":
+			return groupRole
+		case role == "":
+			role = groupRole
+		}
+	}
+	return role
+}
+
+// key returns the signing key with the id. The keys of the issuer are fetched again when the
+// key is not known, to follow key rotation, at most once per RefetchInterval after a
+// successful fetch, and once per RetryInterval after a failed one. Concurrent lookups of
+// unknown keys share a single fetch, made without holding the lock, and independent of the
+// request which started it.
+func (v *OIDCVerifier) key(ctx context.Context, kid string) (interface{}, error) {
+	v.mu.Lock()
+	for {
+		if key, ok := v.keys[kid]; ok {
+			v.mu.Unlock()
+			return key, nil
+		}
+		if v.fetching == nil {
+			break
+		}
+
+		// Wait for the fetch in progress, then look again.
+		fetching := v.fetching
+		v.mu.Unlock()
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		v.mu.Lock()
+		if _, ok := v.keys[kid]; !ok && v.fetching == nil {
+			v.mu.Unlock()
+			return nil, errors.Errorf("unknown signing key '%s'", kid)
+		}
+	}
+
+	if !v.fetchedAt.IsZero() && time.Since(v.fetchedAt) < v.RefetchInterval {
+		v.mu.Unlock()
+		return nil, errors.Errorf("unknown signing key '%s'", kid)
+	}
+	if !v.failedAt.IsZero() && time.Since(v.failedAt) < v.RetryInterval {
+		v.mu.Unlock()
+		return nil, errors.New("OIDC signing keys unavailable, try again later")
+	}
+
+	fetching := make(chan struct{})
+	v.fetching = fetching
+	jwksURI := v.jwksURI
+	v.mu.Unlock()
+
+	fetchCtx, cancel := context.WithTimeout(context.Background(), oidcFetchTimeout)
+	keys, jwksURI, err := v.fetchKeys(fetchCtx, jwksURI)
+	cancel()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fetching = nil
+	close(fetching)
+	if err != nil {
+		v.failedAt = time.Now()
+		return nil, err
+	}
+	v.fetchedAt = time.Now()
+	v.failedAt = time.Time{}
+	v.jwksURI = jwksURI
+	v.keys = keys
+
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown signing key '%s'", kid)
+	}
+	return key, nil
+}
+
+// fetchKeys returns the signing keys of the issuer, by id, and the url they were fetched from.
+// The url is discovered when not given.
+func (v *OIDCVerifier) fetchKeys(ctx context.Context, jwksURI string) (map[string]interface{}, string, error) {
+	if jwksURI == "" {
+		provider, err := DiscoverOIDC(ctx, v.Issuer)
+		if err != nil {
+			return nil, "", err
+		}
+		jwksURI = provider.JWKSURI
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, jwksURI, &jwks); err != nil {
+		return nil, "", errors.Wrap(err, "fetching OIDC signing keys")
+	}
+
+	keys := map[string]interface{}{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+		}
+	}
+
+	return keys, jwksURI, nil
+}
+
+// getJSON decodes the JSON document found at the url into the result.
+func getJSON(ctx context.Context, url string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := oidcClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("%s returned '%s'", url, response.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
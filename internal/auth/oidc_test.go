@@ -0,0 +1,220 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/golang-jwt/jwt/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OIDCVerifier", func() {
+	var issuer *httptest.Server
+	var key *rsa.PrivateKey
+	var kid string
+	var fetches int32
+	var failing int32
+	var verifier *auth.OIDCVerifier
+
+	signWith := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		Expect(err).ToNot(HaveOccurred())
+		return signed
+	}
+
+	sign := func(claims jwt.MapClaims) string {
+		return signWith("key-1", claims)
+	}
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    issuer.URL,
+			"aud":    "epinio",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"email":  "jane@example.com",
+			"groups": []string{"developers", "operators"},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		kid = "key-1"
+		atomic.StoreInt32(&fetches, 0)
+		atomic.StoreInt32(&failing, 0)
+
+		issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"issuer":   "http://" + r.Host,
+					"jwks_uri": "http://" + r.Host + "/keys",
+				})
+			case "/keys":
+				atomic.AddInt32(&fetches, 1)
+				if atomic.LoadInt32(&failing) != 0 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"keys": []map[string]string{{
+						"kid": kid,
+						"kty": "RSA",
+						"use": "sig",
+						"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+						"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+					}},
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(issuer.Close)
+
+		verifier = auth.NewOIDCVerifier(issuer.URL, "epinio")
+		verifier.GroupRoles = map[string]string{"developers": "user", "operators": "admin"}
+	})
+
+	It("returns the user identified by a valid token", func() {
+		identity, err := verifier.Verify(context.Background(), sign(claims()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identity.Username).To(Equal("jane@example.com"))
+		Expect(identity.Groups).To(Equal([]string{"developers", "operators"}))
+	})
+
+	It("rejects expired tokens", func() {
+		c := claims()
+		c["exp"] = time.Now().Add(-time.Hour).Unix()
+		_, err := verifier.Verify(context.Background(), sign(c))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects tokens for other clients", func() {
+		c := claims()
+		c["aud"] = "other"
+		_, err := verifier.Verify(context.Background(), sign(c))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects tokens of other issuers", func() {
+		c := claims()
+		c["iss"] = "https://elsewhere.example.com"
+		_, err := verifier.Verify(context.Background(), sign(c))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects tokens signed with other keys", func() {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims())
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString(other)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = verifier.Verify(context.Background(), signed)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does not fetch the keys again for unknown keys, until the refetch interval is over", func() {
+		_, err := verifier.Verify(context.Background(), sign(claims()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+
+		for i := 0; i < 5; i++ {
+			_, err = verifier.Verify(context.Background(), signWith("key-2", claims()))
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+	})
+
+	It("follows key rotation once the refetch interval is over", func() {
+		_, err := verifier.Verify(context.Background(), sign(claims()))
+		Expect(err).ToNot(HaveOccurred())
+
+		kid = "key-2"
+		verifier.RefetchInterval = 0
+
+		_, err = verifier.Verify(context.Background(), signWith("key-2", claims()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+	})
+
+	It("fetches the keys again after a failed fetch, once the retry interval is over", func() {
+		atomic.StoreInt32(&failing, 1)
+		_, err := verifier.Verify(context.Background(), sign(claims()))
+		Expect(err).To(HaveOccurred())
+
+		// Within the retry interval the keys are not fetched again.
+		_, err = verifier.Verify(context.Background(), sign(claims()))
+		Expect(err).To(HaveOccurred())
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+
+		atomic.StoreInt32(&failing, 0)
+		verifier.RetryInterval = 0
+
+		_, err = verifier.Verify(context.Background(), sign(claims()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+	})
+
+	It("fetches the keys independent of the request asking for them", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := verifier.Verify(ctx, sign(claims()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+	})
+
+	It("shares a single fetch between concurrent verifications", func() {
+		token := sign(claims())
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := verifier.Verify(context.Background(), token)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+	})
+
+	It("maps groups to roles, admin first", func() {
+		Expect(verifier.Role([]string{"developers"})).To(Equal("user"))
+		Expect(verifier.Role([]string{"developers", "operators"})).To(Equal("admin"))
+		Expect(verifier.Role([]string{"visitors"})).To(Equal(""))
+	})
+
+	It("parses group role assignments", func() {
+		roles, err := auth.ParseGroupRoles("developers=user, operators=admin")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(roles).To(Equal(map[string]string{"developers": "user", "operators": "admin"}))
+
+		_, err = auth.ParseGroupRoles("developers")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+)
+
+// MinPasswordLength is the minimum length of the passwords set by users.
+const MinPasswordLength = 8
+
+// ValidatePassword checks that the password is long enough.
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return errors.Errorf("password too short, it needs at least %d characters", MinPasswordLength)
+	}
+	return nil
+}
+
+// NewPassword returns a new random password.
+func NewPassword() (string, error) {
+	return randomHex(16)
+}
+
+// SetPassword replaces the password of the user. Unlike a rotation, the previous password
+// is not valid anymore.
+func (s *AuthService) SetPassword(ctx context.Context, username, password string) error {
+	user, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	// note: Wrap (nil, ...) returns nil.
+	return errors.Wrap(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		userSecret, err := s.SecretInterface.Get(ctx, user.secretName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error getting the user secret [%s]", username))
+		}
+
+		userSecret.StringData = map[string]string{
+			"password":          password,
+			"previous-password": "",
+			"previous-expires":  "",
+		}
+		if userSecret.Annotations == nil {
+			userSecret.Annotations = map[string]string{}
+		}
+		userSecret.Annotations[PasswordRotatedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+		_, err = s.SecretInterface.Update(ctx, userSecret, metav1.UpdateOptions{})
+		return err
+	}), fmt.Sprintf("error setting the password of user [%s]", username))
+}
+
+// DeleteUserSessions ends all sessions of the user, except the one with the id, if any.
+func (s *AuthService) DeleteUserSessions(ctx context.Context, username, except string) error {
+	secretSelector := labels.Set(map[string]string{
+		kubernetes.EpinioSessionLabelKey: kubernetes.EpinioSessionLabelValue,
+	}).AsSelector().String()
+
+	secretList, err := s.SecretInterface.List(ctx, metav1.ListOptions{
+		LabelSelector: secretSelector,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error getting the list of the session secrets")
+	}
+
+	for _, secret := range secretList.Items {
+		session := newSessionFromSecret(secret)
+		if session.Username != username || (except != "" && session.ID == except) {
+			continue
+		}
+		if err := s.DeleteSession(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
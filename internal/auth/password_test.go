@@ -0,0 +1,135 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Passwords", func() {
+	var authService *auth.AuthService
+	var fake *authfakes.FakeSecretInterface
+	var secret *corev1.Secret
+	var sessions []corev1.Secret
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "r-dev",
+				Labels: map[string]string{
+					kubernetes.EpinioAPISecretLabelKey: kubernetes.EpinioAPISecretLabelValue,
+				},
+			},
+			Type: corev1.SecretTypeBasicAuth,
+			Data: map[string][]byte{
+				"username": []byte("dev"),
+				"password": []byte("old-password"),
+			},
+		}
+		sessions = []corev1.Secret{
+			sessionSecret("s1", "dev"),
+			sessionSecret("s2", "other"),
+			sessionSecret("s3", "dev"),
+		}
+
+		fake = &authfakes.FakeSecretInterface{}
+		authService = &auth.AuthService{SecretInterface: fake}
+
+		fake.ListStub = func(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+			if strings.Contains(opts.LabelSelector, kubernetes.EpinioSessionLabelKey) {
+				return &corev1.SecretList{Items: sessions}, nil
+			}
+			return &corev1.SecretList{Items: []corev1.Secret{*secret}}, nil
+		}
+		fake.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+			return secret.DeepCopy(), nil
+		}
+		fake.UpdateStub = func(ctx context.Context, updated *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error) {
+			secret = updated.DeepCopy()
+			for key, value := range updated.StringData {
+				secret.Data[key] = []byte(value)
+			}
+			secret.StringData = nil
+			return secret, nil
+		}
+	})
+
+	It("rejects short passwords", func() {
+		Expect(auth.ValidatePassword("short")).To(HaveOccurred())
+		Expect(auth.ValidatePassword("long enough")).ToNot(HaveOccurred())
+	})
+
+	It("replaces the password, and the previous one of a rotation", func() {
+		_, err := authService.RotatePassword(context.Background(), "dev", 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = authService.SetPassword(context.Background(), "dev", "new-password")
+		Expect(err).ToNot(HaveOccurred())
+
+		user, err := authService.GetUserByUsername(context.Background(), "dev")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.Password).To(Equal("new-password"))
+		Expect(user.PreviousPassword).To(BeEmpty())
+	})
+
+	It("fails for unknown users", func() {
+		err := authService.SetPassword(context.Background(), "unknown", "new-password")
+		Expect(err).To(Equal(auth.ErrUserNotFound))
+	})
+
+	It("ends the sessions of the user only", func() {
+		err := authService.DeleteUserSessions(context.Background(), "dev", "")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fake.DeleteCallCount()).To(Equal(2))
+		_, first, _ := fake.DeleteArgsForCall(0)
+		_, second, _ := fake.DeleteArgsForCall(1)
+		Expect(first).To(ContainSubstring("s1"))
+		Expect(second).To(ContainSubstring("s3"))
+	})
+
+	It("keeps the session asked for", func() {
+		err := authService.DeleteUserSessions(context.Background(), "dev", "s3")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fake.DeleteCallCount()).To(Equal(1))
+		_, first, _ := fake.DeleteArgsForCall(0)
+		Expect(first).To(ContainSubstring("s1"))
+	})
+
+	It("makes the sessions issued before the change of the password out of date", func() {
+		before := time.Now().Add(-time.Minute)
+
+		err := authService.SetPassword(context.Background(), "dev", "new-password")
+		Expect(err).ToNot(HaveOccurred())
+
+		user, err := authService.GetUserByUsername(context.Background(), "dev")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.IsSessionCurrent(before)).To(BeFalse())
+		Expect(user.IsSessionCurrent(time.Unix(0, 0))).To(BeFalse())
+		Expect(user.IsSessionCurrent(time.Now())).To(BeTrue())
+	})
+})
+
+func sessionSecret(id, username string) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "epinio-session-" + id,
+			Labels: map[string]string{
+				kubernetes.EpinioSessionLabelKey: kubernetes.EpinioSessionLabelValue,
+			},
+		},
+		Data: map[string][]byte{
+			"id":       []byte(id),
+			"username": []byte(username),
+		},
+	}
+}
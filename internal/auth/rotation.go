@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// PasswordRotatedAtAnnotation holds the time the password of the user secret was last
+// rotated. Without it the password is as old as the secret.
+const PasswordRotatedAtAnnotation = "epinio.io/password-rotated-at"
+
+// rotationCheckInterval is how often the rotation loop looks for passwords to rotate.
+const rotationCheckInterval = 10 * time.Minute
+
+// IsPassword returns true if the password is the one of the user, or their previous one,
+// until the grace period of the last rotation is over.
+func (u User) IsPassword(password string, now time.Time) bool {
+	if subtle.ConstantTimeCompare([]byte(u.Password), []byte(password)) == 1 {
+		return true
+	}
+	return u.PreviousPassword != "" && now.Before(u.PreviousPasswordExpiresAt) &&
+		subtle.ConstantTimeCompare([]byte(u.PreviousPassword), []byte(password)) == 1
+}
+
+// RotatePassword gives the user a new random password, and returns it. The current password
+// stays valid for the grace period, so that the clients using it can switch over.
+func (s *AuthService) RotatePassword(ctx context.Context, username string, grace time.Duration) (string, error) {
+	user, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := NewPassword()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		userSecret, err := s.SecretInterface.Get(ctx, user.secretName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error getting the user secret [%s]", username))
+		}
+
+		userSecret.StringData = map[string]string{
+			"password":          password,
+			"previous-password": string(userSecret.Data["password"]),
+			"previous-expires":  now.Add(grace).UTC().Format(time.RFC3339),
+		}
+		if userSecret.Annotations == nil {
+			userSecret.Annotations = map[string]string{}
+		}
+		userSecret.Annotations[PasswordRotatedAtAnnotation] = now.UTC().Format(time.RFC3339)
+
+		_, err = s.SecretInterface.Update(ctx, userSecret, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("error rotating the password of user [%s]", username))
+	}
+
+	return password, nil
+}
+
+// RotateExpiredPasswords rotates the passwords of the users which were not rotated for the
+// interval, and returns the names of these users.
+func (s *AuthService) RotateExpiredPasswords(ctx context.Context, interval, grace time.Duration) ([]string, error) {
+	users, err := s.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated := []string{}
+	for _, user := range users {
+		if time.Since(user.PasswordRotatedAt) < interval {
+			continue
+		}
+		if _, err := s.RotatePassword(ctx, user.Username, grace); err != nil {
+			return rotated, err
+		}
+		rotated = append(rotated, user.Username)
+	}
+
+	return rotated, nil
+}
+
+// RotationLoop rotates the passwords of the users every interval, until the context is
+// done. Previous passwords stay valid for the grace period.
+func RotationLoop(ctx context.Context, logger logr.Logger, interval, grace time.Duration) {
+	ticker := time.NewTicker(rotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		authService, err := NewAuthServiceFromContext(ctx)
+		if err != nil {
+			logger.Error(err, "credentials rotation: no cluster")
+			continue
+		}
+
+		rotated, err := authService.RotateExpiredPasswords(ctx, interval, grace)
+		if err != nil {
+			logger.Error(err, "credentials rotation failed")
+		}
+		for _, username := range rotated {
+			logger.Info("rotated password", "user", username)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package auth_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Credentials rotation", func() {
+	var authService *auth.AuthService
+	var secret *corev1.Secret
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "r-ci",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+				Labels: map[string]string{
+					kubernetes.EpinioAPISecretLabelKey: kubernetes.EpinioAPISecretLabelValue,
+				},
+			},
+			Type: corev1.SecretTypeBasicAuth,
+			Data: map[string][]byte{
+				"username": []byte("ci"),
+				"password": []byte("old-password"),
+			},
+		}
+
+		fake := &authfakes.FakeSecretInterface{}
+		authService = &auth.AuthService{SecretInterface: fake}
+
+		fake.ListStub = func(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+			return &corev1.SecretList{Items: []corev1.Secret{*secret}}, nil
+		}
+		fake.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+			return secret.DeepCopy(), nil
+		}
+		fake.UpdateStub = func(ctx context.Context, updated *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error) {
+			secret = updated.DeepCopy()
+			for key, value := range updated.StringData {
+				secret.Data[key] = []byte(value)
+			}
+			secret.StringData = nil
+			return secret, nil
+		}
+	})
+
+	It("keeps the previous password valid for the grace period", func() {
+		password, err := authService.RotatePassword(context.Background(), "ci", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(password).ToNot(BeEmpty())
+
+		user, err := authService.GetUserByUsername(context.Background(), "ci")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.Password).To(Equal(password))
+		Expect(user.PasswordRotatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+
+		Expect(user.IsPassword(password, time.Now())).To(BeTrue())
+		Expect(user.IsPassword("old-password", time.Now())).To(BeTrue())
+		Expect(user.IsPassword("old-password", time.Now().Add(2*time.Hour))).To(BeFalse())
+		Expect(user.IsPassword("other", time.Now())).To(BeFalse())
+	})
+
+	It("rotates the passwords older than the interval", func() {
+		rotated, err := authService.RotateExpiredPasswords(context.Background(), 72*time.Hour, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rotated).To(BeEmpty())
+
+		rotated, err = authService.RotateExpiredPasswords(context.Background(), 24*time.Hour, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rotated).To(ConsistOf("ci"))
+
+		rotated, err = authService.RotateExpiredPasswords(context.Background(), 24*time.Hour, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rotated).To(BeEmpty())
+	})
+})
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SessionIssuer is the issuer of the session tokens, to tell them apart from other bearer
+// tokens.
+const SessionIssuer = "epinio-session"
+
+// SessionRefreshPrefix starts all refresh tokens of sessions.
+const SessionRefreshPrefix = "epr_"
+
+// CookieSessionIssuedKey is the key of the cookie sessions holding the time they were issued
+// at, in unix seconds. See IsSessionCurrent.
+const CookieSessionIssuedKey = "issued"
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionInvalid  = errors.New("session invalid")
+)
+
+// Session is a login of a user with user and password. The session token is short-lived,
+// and is refreshed with the refresh token of the session, until the session expires. Then
+// the user has to log in again. Only a hash of the refresh token is kept.
+type Session struct {
+	ID               string
+	Username         string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	RefreshExpiresAt time.Time
+
+	refreshHash string
+}
+
+// IsSessionToken returns true if the bearer token is a session token. The token is not
+// validated.
+func IsSessionToken(token string) bool {
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return false
+	}
+	return claims.Issuer == SessionIssuer
+}
+
+// IsSessionCurrent returns false for the sessions issued before the last change of the
+// password of the user. These have to log in again.
+func (u User) IsSessionCurrent(issued time.Time) bool {
+	return !issued.Before(u.PasswordRotatedAt.Truncate(time.Second))
+}
+
+// sessionKey returns the key signing the session tokens, the one of the cookie sessions.
+func sessionKey() []byte {
+	return []byte(os.Getenv("SESSION_KEY"))
+}
+
+// newSessionFromSecret creates a session from its secret.
+func newSessionFromSecret(secret corev1.Secret) Session {
+	session := Session{
+		ID:          string(secret.Data["id"]),
+		Username:    string(secret.Data["username"]),
+		CreatedAt:   secret.ObjectMeta.CreationTimestamp.Time,
+		refreshHash: string(secret.Data["refresh-hash"]),
+	}
+
+	// An unparseable expiry is the zero time, i.e. expired.
+	session.RefreshExpiresAt, _ = time.Parse(time.RFC3339, string(secret.Data["refresh-expires"]))
+
+	return session
+}
+
+// CreateSession starts a new session of the user. The session token is valid for the ttl,
+// and can be refreshed until the refreshTTL is over. It returns the session, its token, and
+// its refresh token. Expired sessions of all users are removed.
+func (s *AuthService) CreateSession(ctx context.Context, username string, ttl, refreshTTL time.Duration) (Session, string, string, error) {
+	if ttl <= 0 || refreshTTL <= 0 {
+		return Session{}, "", "", errors.New("the lifetime of the session has to be positive")
+	}
+
+	if err := s.removeExpiredSessions(ctx); err != nil {
+		return Session{}, "", "", err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return Session{}, "", "", err
+	}
+	refreshPart, err := randomHex(24)
+	if err != nil {
+		return Session{}, "", "", err
+	}
+
+	session := Session{
+		ID:               id,
+		Username:         username,
+		CreatedAt:        time.Now(),
+		RefreshExpiresAt: time.Now().Add(refreshTTL).Truncate(time.Second),
+		refreshHash:      hashToken(refreshPart),
+	}
+
+	_, err = s.SecretInterface.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: sessionSecretName(id),
+			Labels: map[string]string{
+				kubernetes.EpinioSessionLabelKey: kubernetes.EpinioSessionLabelValue,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			"id":              session.ID,
+			"username":        session.Username,
+			"refresh-expires": session.RefreshExpiresAt.UTC().Format(time.RFC3339),
+			"refresh-hash":    session.refreshHash,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return Session{}, "", "", errors.Wrap(err, "error creating the session secret")
+	}
+
+	token, err := session.token(ttl)
+	if err != nil {
+		return Session{}, "", "", err
+	}
+
+	return session, token, sessionRefreshToken(id, refreshPart), nil
+}
+
+// ValidateSession returns the session of the token. It returns ErrSessionInvalid for bad,
+// and expired, tokens, and for tokens of ended sessions.
+func (s *AuthService) ValidateSession(ctx context.Context, token string) (Session, error) {
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return sessionKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+	if err != nil || claims.Issuer != SessionIssuer || claims.ExpiresAt == nil {
+		return Session{}, ErrSessionInvalid
+	}
+
+	session, err := s.getSession(ctx, claims.ID)
+	if err == ErrSessionNotFound {
+		return Session{}, ErrSessionInvalid
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	if session.Username != claims.Subject {
+		return Session{}, ErrSessionInvalid
+	}
+
+	session.ExpiresAt = claims.ExpiresAt.Time
+	return session, nil
+}
+
+// RefreshSession returns a new token, and refresh token, for the session of the refresh token.
+// The old refresh token cannot be used again. It returns ErrSessionInvalid for bad refresh
+// tokens, and expired sessions.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string, ttl time.Duration) (Session, string, string, error) {
+	pieces := strings.SplitN(strings.TrimPrefix(refreshToken, SessionRefreshPrefix), "_", 2)
+	if !strings.HasPrefix(refreshToken, SessionRefreshPrefix) || len(pieces) != 2 {
+		return Session{}, "", "", ErrSessionInvalid
+	}
+
+	secret, err := s.SecretInterface.Get(ctx, sessionSecretName(pieces[0]), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Session{}, "", "", ErrSessionInvalid
+	}
+	if err != nil {
+		return Session{}, "", "", errors.Wrap(err, "error getting the session secret")
+	}
+	if secret.Labels[kubernetes.EpinioSessionLabelKey] != kubernetes.EpinioSessionLabelValue {
+		return Session{}, "", "", ErrSessionInvalid
+	}
+
+	session := newSessionFromSecret(*secret)
+	if subtle.ConstantTimeCompare([]byte(session.refreshHash), []byte(hashToken(pieces[1]))) != 1 {
+		return Session{}, "", "", ErrSessionInvalid
+	}
+	if time.Now().After(session.RefreshExpiresAt) {
+		return Session{}, "", "", ErrSessionInvalid
+	}
+
+	refreshPart, err := randomHex(24)
+	if err != nil {
+		return Session{}, "", "", err
+	}
+	session.refreshHash = hashToken(refreshPart)
+
+	// The update fails for concurrent refreshes with the same refresh token, and only the
+	// first one gets a new token.
+	secret.StringData = map[string]string{"refresh-hash": session.refreshHash}
+	if _, err := s.SecretInterface.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return Session{}, "", "", ErrSessionInvalid
+		}
+		return Session{}, "", "", errors.Wrap(err, "error updating the session secret")
+	}
+
+	token, err := session.token(ttl)
+	if err != nil {
+		return Session{}, "", "", err
+	}
+
+	return session, token, sessionRefreshToken(session.ID, refreshPart), nil
+}
+
+// DeleteSession ends the session. Its tokens cannot be used anymore.
+func (s *AuthService) DeleteSession(ctx context.Context, id string) error {
+	err := s.SecretInterface.Delete(ctx, sessionSecretName(id), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrSessionNotFound
+	}
+	return errors.Wrap(err, "error deleting the session secret")
+}
+
+// getSession returns the session with the id.
+func (s *AuthService) getSession(ctx context.Context, id string) (Session, error) {
+	secret, err := s.SecretInterface.Get(ctx, sessionSecretName(id), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Session{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return Session{}, errors.Wrap(err, "error getting the session secret")
+	}
+	if secret.Labels[kubernetes.EpinioSessionLabelKey] != kubernetes.EpinioSessionLabelValue {
+		return Session{}, ErrSessionNotFound
+	}
+
+	return newSessionFromSecret(*secret), nil
+}
+
+// removeExpiredSessions removes the sessions which cannot be refreshed anymore.
+func (s *AuthService) removeExpiredSessions(ctx context.Context) error {
+	secretSelector := labels.Set(map[string]string{
+		kubernetes.EpinioSessionLabelKey: kubernetes.EpinioSessionLabelValue,
+	}).AsSelector().String()
+
+	secretList, err := s.SecretInterface.List(ctx, metav1.ListOptions{
+		LabelSelector: secretSelector,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error getting the list of the session secrets")
+	}
+
+	for _, secret := range secretList.Items {
+		session := newSessionFromSecret(secret)
+		if time.Now().Before(session.RefreshExpiresAt) {
+			continue
+		}
+		err := s.SecretInterface.Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "error deleting the expired session secret")
+		}
+	}
+
+	return nil
+}
+
+// token returns a new session token, valid for the ttl, but not beyond the expiry of the
+// session.
+func (session *Session) token(ttl time.Duration) (string, error) {
+	session.ExpiresAt = time.Now().Add(ttl).Truncate(time.Second)
+	if session.ExpiresAt.After(session.RefreshExpiresAt) {
+		session.ExpiresAt = session.RefreshExpiresAt
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    SessionIssuer,
+		Subject:   session.Username,
+		ID:        session.ID,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+	})
+
+	signed, err := token.SignedString(sessionKey())
+	return signed, errors.Wrap(err, "error signing the session token")
+}
+
+func sessionSecretName(id string) string {
+	return "session-" + id
+}
+
+func sessionRefreshToken(id, refreshPart string) string {
+	return fmt.Sprintf("%s%s_%s", SessionRefreshPrefix, id, refreshPart)
+}
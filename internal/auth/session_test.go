@@ -0,0 +1,151 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Sessions", func() {
+	var authService *auth.AuthService
+	var fake *authfakes.FakeSecretInterface
+	var stored map[string]*corev1.Secret
+
+	// keep stores the secret with its data as the API server does.
+	keep := func(secret *corev1.Secret) (*corev1.Secret, error) {
+		kept := secret.DeepCopy()
+		if kept.Data == nil {
+			kept.Data = map[string][]byte{}
+		}
+		for key, value := range secret.StringData {
+			kept.Data[key] = []byte(value)
+		}
+		kept.StringData = nil
+		stored[kept.Name] = kept
+		return kept, nil
+	}
+
+	BeforeEach(func() {
+		os.Setenv("SESSION_KEY", "test-session-key")
+
+		fake = &authfakes.FakeSecretInterface{}
+		authService = &auth.AuthService{SecretInterface: fake}
+		stored = map[string]*corev1.Secret{}
+
+		fake.CreateStub = func(ctx context.Context, secret *corev1.Secret, opts metav1.CreateOptions) (*corev1.Secret, error) {
+			return keep(secret)
+		}
+		fake.UpdateStub = func(ctx context.Context, secret *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error) {
+			return keep(secret)
+		}
+		fake.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+			secret, ok := stored[name]
+			if !ok {
+				return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+			}
+			return secret.DeepCopy(), nil
+		}
+		fake.ListStub = func(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+			list := &corev1.SecretList{}
+			for _, secret := range stored {
+				list.Items = append(list.Items, *secret)
+			}
+			return list, nil
+		}
+		fake.DeleteStub = func(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+			if _, ok := stored[name]; !ok {
+				return apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+			}
+			delete(stored, name)
+			return nil
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("SESSION_KEY")
+	})
+
+	It("validates the token of the session", func() {
+		session, token, _, err := authService.CreateSession(context.Background(), "jane", time.Hour, 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(auth.IsSessionToken(token)).To(BeTrue())
+		Expect(auth.IsAPIToken(token)).To(BeFalse())
+
+		validated, err := authService.ValidateSession(context.Background(), token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(validated.ID).To(Equal(session.ID))
+		Expect(validated.Username).To(Equal("jane"))
+	})
+
+	It("rejects tokens signed with another key", func() {
+		_, token, _, err := authService.CreateSession(context.Background(), "jane", time.Hour, 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("SESSION_KEY", "other-session-key")
+		_, err = authService.ValidateSession(context.Background(), token)
+		Expect(err).To(Equal(auth.ErrSessionInvalid))
+	})
+
+	It("rejects the tokens of ended sessions", func() {
+		session, token, _, err := authService.CreateSession(context.Background(), "jane", time.Hour, 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(authService.DeleteSession(context.Background(), session.ID)).To(Succeed())
+
+		_, err = authService.ValidateSession(context.Background(), token)
+		Expect(err).To(Equal(auth.ErrSessionInvalid))
+	})
+
+	It("limits the token to the lifetime of the session", func() {
+		session, _, _, err := authService.CreateSession(context.Background(), "jane", time.Hour, time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(session.ExpiresAt).To(Equal(session.RefreshExpiresAt))
+	})
+
+	It("refreshes the session once per refresh token", func() {
+		session, _, refreshToken, err := authService.CreateSession(context.Background(), "jane", time.Hour, 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		refreshed, token, newRefreshToken, err := authService.RefreshSession(context.Background(), refreshToken, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(refreshed.ID).To(Equal(session.ID))
+		Expect(refreshed.RefreshExpiresAt).To(BeTemporally("==", session.RefreshExpiresAt))
+		Expect(newRefreshToken).ToNot(Equal(refreshToken))
+
+		_, err = authService.ValidateSession(context.Background(), token)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, _, err = authService.RefreshSession(context.Background(), refreshToken, time.Hour)
+		Expect(err).To(Equal(auth.ErrSessionInvalid))
+
+		_, _, _, err = authService.RefreshSession(context.Background(), newRefreshToken, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("refuses to refresh expired sessions, and removes them", func() {
+		session, _, refreshToken, err := authService.CreateSession(context.Background(), "jane", time.Hour, 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, secret := range stored {
+			secret.Data["refresh-expires"] = []byte(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+		}
+
+		_, _, _, err = authService.RefreshSession(context.Background(), refreshToken, time.Hour)
+		Expect(err).To(Equal(auth.ErrSessionInvalid))
+
+		other, _, _, err := authService.CreateSession(context.Background(), "john", time.Hour, 24*time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(HaveLen(1))
+		Expect(stored).To(HaveKey("session-" + other.ID))
+		Expect(stored).ToNot(HaveKey("session-" + session.ID))
+	})
+})
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+)
+
+var (
+	ErrTeamNotFound = errors.New("team not found")
+)
+
+// Team is a group of users sharing access to namespaces. The members are Epinio users, and
+// the users authenticated by the OIDC issuer with one of the groups of the team.
+type Team struct {
+	Name           string
+	Members        []string
+	Groups         []string
+	Namespaces     []string
+	NamespaceRoles map[string]string
+}
+
+// newTeamFromSecret creates a team from its secret.
+func newTeamFromSecret(secret corev1.Secret) Team {
+	// The namespaces are stored like the ones of users.
+	access := User{Namespaces: []string{}, NamespaceRoles: map[string]string{}}
+	access.parseNamespaceEntries(string(secret.Data["namespaces"]))
+
+	return Team{
+		Name:           string(secret.Data["name"]),
+		Members:        splitLines(string(secret.Data["members"])),
+		Groups:         splitLines(string(secret.Data["groups"])),
+		Namespaces:     access.Namespaces,
+		NamespaceRoles: access.NamespaceRoles,
+	}
+}
+
+// Includes returns true if the user is a member of the team, directly or by one of their
+// groups.
+func (t Team) Includes(username string, groups []string) bool {
+	for _, member := range t.Members {
+		if member == username {
+			return true
+		}
+	}
+	for _, group := range groups {
+		for _, teamGroup := range t.Groups {
+			if group == teamGroup {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// access returns the namespaces of the team as user, for the handling of namespace roles.
+func (t Team) access() User {
+	return User{Namespaces: t.Namespaces, NamespaceRoles: t.NamespaceRoles}
+}
+
+// WithTeams returns the user with the access of the teams they are a member of. Of the roles
+// the user has in a namespace, directly or by their teams, the strongest wins.
+func WithTeams(user User, teams []Team, groups []string) User {
+	namespaces := append([]string{}, user.Namespaces...)
+	roles := map[string]string{}
+	for namespace, role := range user.NamespaceRoles {
+		roles[namespace] = role
+	}
+	merged := User{Namespaces: namespaces, NamespaceRoles: roles}
+
+	for _, team := range teams {
+		if !team.Includes(user.Username, groups) {
+			continue
+		}
+		access := team.access()
+		for _, namespace := range team.Namespaces {
+			role := access.NamespaceRole(namespace)
+			if namespaceRoleRank(role) > namespaceRoleRank(merged.NamespaceRole(namespace)) {
+				merged.SetNamespaceRole(namespace, role)
+			}
+		}
+	}
+
+	user.Namespaces = merged.Namespaces
+	user.NamespaceRoles = merged.NamespaceRoles
+	return user
+}
+
+// namespaceRoleRank orders the namespace roles by the access they give.
+func namespaceRoleRank(role string) int {
+	switch role {
+	case NamespaceRoleReader:
+		return 1
+	case NamespaceRoleDeveloper:
+		return 2
+	case NamespaceRoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// GetTeams returns all the teams, sorted by name.
+func (s *AuthService) GetTeams(ctx context.Context) ([]Team, error) {
+	secretSelector := labels.Set(map[string]string{
+		kubernetes.EpinioTeamLabelKey: kubernetes.EpinioTeamLabelValue,
+	}).AsSelector().String()
+
+	secretList, err := s.SecretInterface.List(ctx, metav1.ListOptions{
+		LabelSelector: secretSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting the list of the team secrets")
+	}
+
+	teams := []Team{}
+	for _, secret := range secretList.Items {
+		teams = append(teams, newTeamFromSecret(secret))
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+
+	return teams, nil
+}
+
+// GetTeam returns the team with the name, or ErrTeamNotFound.
+func (s *AuthService) GetTeam(ctx context.Context, name string) (Team, error) {
+	secret, err := s.SecretInterface.Get(ctx, teamSecretName(name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Team{}, ErrTeamNotFound
+	}
+	if err != nil {
+		return Team{}, errors.Wrap(err, fmt.Sprintf("error getting the team secret [%s]", name))
+	}
+	if secret.Labels[kubernetes.EpinioTeamLabelKey] != kubernetes.EpinioTeamLabelValue {
+		return Team{}, ErrTeamNotFound
+	}
+
+	return newTeamFromSecret(*secret), nil
+}
+
+// SetTeamMembers creates the team, or replaces the members and groups of the existing team.
+func (s *AuthService) SetTeamMembers(ctx context.Context, name string, members, groups []string) error {
+	return s.updateTeam(ctx, name, true, func(team *Team) {
+		team.Members = members
+		team.Groups = groups
+	})
+}
+
+// SetTeamNamespaceRole gives the team the role in the namespace. The role "none" removes the
+// namespace from the team.
+func (s *AuthService) SetTeamNamespaceRole(ctx context.Context, name, namespace, role string) error {
+	return s.updateTeam(ctx, name, false, func(team *Team) {
+		access := team.access()
+		if role == "none" {
+			access.RemoveNamespace(namespace)
+		} else {
+			access.SetNamespaceRole(namespace, role)
+		}
+		team.Namespaces = access.Namespaces
+		team.NamespaceRoles = access.NamespaceRoles
+	})
+}
+
+// DeleteTeam removes the team. Its members lose the access given by it.
+func (s *AuthService) DeleteTeam(ctx context.Context, name string) error {
+	err := s.SecretInterface.Delete(ctx, teamSecretName(name), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrTeamNotFound
+	}
+	return errors.Wrap(err, fmt.Sprintf("error deleting the team secret [%s]", name))
+}
+
+// RemoveNamespaceFromTeams will remove the specified namespace from all the teams
+func (s *AuthService) RemoveNamespaceFromTeams(ctx context.Context, namespace string) error {
+	teams, err := s.GetTeams(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting teams")
+	}
+
+	errorMessages := []string{}
+	for _, team := range teams {
+		access := team.access()
+		if !access.RemoveNamespace(namespace) {
+			continue
+		}
+
+		err := s.updateTeam(ctx, team.Name, false, func(team *Team) {
+			access := team.access()
+			access.RemoveNamespace(namespace)
+			team.Namespaces = access.Namespaces
+			team.NamespaceRoles = access.NamespaceRoles
+		})
+		if err != nil {
+			errorMessages = append(errorMessages, err.Error())
+		}
+	}
+
+	if len(errorMessages) > 0 {
+		return fmt.Errorf("some error occurred while cleaning teams: [%s]", strings.Join(errorMessages, ", "))
+	}
+	return nil
+}
+
+// updateTeam applies the change to the team, and saves it. Unknown teams are created if
+// asked for, else ErrTeamNotFound is returned.
+func (s *AuthService) updateTeam(ctx context.Context, name string, create bool, change func(*Team)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.SecretInterface.Get(ctx, teamSecretName(name), metav1.GetOptions{})
+		isNew := apierrors.IsNotFound(err)
+		if isNew {
+			if !create {
+				return ErrTeamNotFound
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: teamSecretName(name),
+					Labels: map[string]string{
+						kubernetes.EpinioTeamLabelKey: kubernetes.EpinioTeamLabelValue,
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		} else if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error getting the team secret [%s]", name))
+		}
+
+		team := newTeamFromSecret(*secret)
+		team.Name = name
+		change(&team)
+
+		secret.StringData = map[string]string{
+			"name":       team.Name,
+			"members":    strings.Join(team.Members, "\n"),
+			"groups":     strings.Join(team.Groups, "\n"),
+			"namespaces": strings.Join(team.access().namespaceEntries(), "\n"),
+		}
+
+		if isNew {
+			_, err = s.SecretInterface.Create(ctx, secret, metav1.CreateOptions{})
+		} else {
+			_, err = s.SecretInterface.Update(ctx, secret, metav1.UpdateOptions{})
+		}
+		return err
+	})
+}
+
+func teamSecretName(name string) string {
+	return "team-" + name
+}
+
+func splitLines(value string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
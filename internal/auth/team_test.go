@@ -0,0 +1,165 @@
+package auth_test
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Teams", func() {
+
+	Describe("WithTeams", func() {
+		teams := []auth.Team{
+			{
+				Name:           "backend",
+				Members:        []string{"jane"},
+				Namespaces:     []string{"staging", "production"},
+				NamespaceRoles: map[string]string{"production": auth.NamespaceRoleReader},
+			},
+			{
+				Name:           "ops",
+				Groups:         []string{"operators"},
+				Namespaces:     []string{"production"},
+				NamespaceRoles: map[string]string{},
+			},
+		}
+
+		It("gives the members the namespaces of their teams", func() {
+			user := auth.User{Username: "jane", Role: "user", Namespaces: []string{"workspace"}}
+
+			user = auth.WithTeams(user, teams, nil)
+			Expect(user.Namespaces).To(Equal([]string{"workspace", "staging", "production"}))
+			Expect(user.NamespaceRole("workspace")).To(Equal(auth.NamespaceRoleAdmin))
+			Expect(user.NamespaceRole("staging")).To(Equal(auth.NamespaceRoleAdmin))
+			Expect(user.NamespaceRole("production")).To(Equal(auth.NamespaceRoleReader))
+		})
+
+		It("gives the users of the groups of the teams their namespaces", func() {
+			user := auth.User{Username: "john", Role: "user", Namespaces: []string{}}
+
+			user = auth.WithTeams(user, teams, []string{"operators"})
+			Expect(user.Namespaces).To(Equal([]string{"production"}))
+			Expect(user.NamespaceRole("production")).To(Equal(auth.NamespaceRoleAdmin))
+		})
+
+		It("keeps the strongest role of the user", func() {
+			user := auth.User{
+				Username:       "jane",
+				Role:           "user",
+				Namespaces:     []string{"staging", "production"},
+				NamespaceRoles: map[string]string{"staging": auth.NamespaceRoleReader, "production": auth.NamespaceRoleDeveloper},
+			}
+
+			user = auth.WithTeams(user, teams, nil)
+			Expect(user.NamespaceRole("staging")).To(Equal(auth.NamespaceRoleAdmin))
+			Expect(user.NamespaceRole("production")).To(Equal(auth.NamespaceRoleDeveloper))
+		})
+
+		It("gives nothing to users of no team", func() {
+			user := auth.User{Username: "john", Role: "user", Namespaces: []string{"workspace"}}
+
+			user = auth.WithTeams(user, teams, []string{"developers"})
+			Expect(user.Namespaces).To(Equal([]string{"workspace"}))
+		})
+	})
+
+	Describe("storage", func() {
+		var authService *auth.AuthService
+		var fake *authfakes.FakeSecretInterface
+		var stored *corev1.Secret
+
+		// keep stores the secret with its data as the API server does.
+		keep := func(secret *corev1.Secret) (*corev1.Secret, error) {
+			stored = secret.DeepCopy()
+			stored.Data = map[string][]byte{}
+			for key, value := range secret.StringData {
+				stored.Data[key] = []byte(value)
+			}
+			stored.StringData = nil
+			return stored, nil
+		}
+
+		BeforeEach(func() {
+			fake = &authfakes.FakeSecretInterface{}
+			authService = &auth.AuthService{SecretInterface: fake}
+			stored = nil
+
+			fake.CreateStub = func(ctx context.Context, secret *corev1.Secret, opts metav1.CreateOptions) (*corev1.Secret, error) {
+				return keep(secret)
+			}
+			fake.UpdateStub = func(ctx context.Context, secret *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error) {
+				return keep(secret)
+			}
+			fake.GetStub = func(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error) {
+				if stored == nil || stored.Name != name {
+					return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+				}
+				return stored.DeepCopy(), nil
+			}
+			fake.ListStub = func(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+				list := &corev1.SecretList{}
+				if stored != nil {
+					list.Items = append(list.Items, *stored)
+				}
+				return list, nil
+			}
+		})
+
+		It("creates the team, and stores its roles", func() {
+			err := authService.SetTeamMembers(context.Background(), "backend", []string{"jane", "john"}, []string{"developers"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.CreateCallCount()).To(Equal(1))
+
+			err = authService.SetTeamNamespaceRole(context.Background(), "backend", "staging", auth.NamespaceRoleDeveloper)
+			Expect(err).ToNot(HaveOccurred())
+			err = authService.SetTeamNamespaceRole(context.Background(), "backend", "workspace", auth.NamespaceRoleAdmin)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.UpdateCallCount()).To(Equal(2))
+
+			teams, err := authService.GetTeams(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(teams).To(HaveLen(1))
+			Expect(teams[0].Name).To(Equal("backend"))
+			Expect(teams[0].Members).To(Equal([]string{"jane", "john"}))
+			Expect(teams[0].Groups).To(Equal([]string{"developers"}))
+			Expect(teams[0].Namespaces).To(Equal([]string{"staging", "workspace"}))
+			Expect(string(stored.Data["namespaces"])).To(Equal("staging:developer\nworkspace"))
+		})
+
+		It("returns the team by name, and reports unknown ones", func() {
+			err := authService.SetTeamMembers(context.Background(), "backend", []string{"jane"}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			team, err := authService.GetTeam(context.Background(), "backend")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(team.Name).To(Equal("backend"))
+			Expect(team.Members).To(Equal([]string{"jane"}))
+
+			_, err = authService.GetTeam(context.Background(), "frontend")
+			Expect(err).To(Equal(auth.ErrTeamNotFound))
+		})
+
+		It("fails to give roles to unknown teams", func() {
+			err := authService.SetTeamNamespaceRole(context.Background(), "backend", "staging", auth.NamespaceRoleDeveloper)
+			Expect(err).To(Equal(auth.ErrTeamNotFound))
+		})
+
+		It("removes deleted namespaces from the teams", func() {
+			err := authService.SetTeamMembers(context.Background(), "backend", []string{"jane"}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			err = authService.SetTeamNamespaceRole(context.Background(), "backend", "staging", auth.NamespaceRoleReader)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = authService.RemoveNamespaceFromTeams(context.Background(), "staging")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(stored.Data["namespaces"])).To(Equal(""))
+		})
+	})
+})
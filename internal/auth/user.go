@@ -12,6 +12,19 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+// Roles of users in a namespace. Readers can only make requests changing nothing, and cannot
+// get into the applications. Developers can do everything in the namespace, except changing,
+// or deleting, the namespace itself. Admins of the namespace can do everything in it.
+const (
+	NamespaceRoleReader    = "reader"
+	NamespaceRoleDeveloper = "developer"
+	NamespaceRoleAdmin     = "admin"
+)
+
+// FederatedAnnotation marks the user secrets of the Epinio users who are the same person as
+// the user of the same name at the LDAP server, or the OIDC issuer. See ExternalUser.
+const FederatedAnnotation = "epinio.io/federated"
+
 // User is a struct containing all the information of an Epinio User
 type User struct {
 	Username   string
@@ -19,10 +32,45 @@ type User struct {
 	CreatedAt  time.Time
 	Role       string
 	Namespaces []string
+	// NamespaceRoles holds the roles of the user in their namespaces. Namespaces without
+	// role are administered by the user.
+	NamespaceRoles map[string]string
+	// NamespaceExpiries holds the end of the temporary access of the user to namespaces.
+	// Namespaces without expiry are granted permanently.
+	NamespaceExpiries map[string]time.Time
+
+	// PreviousPassword is the password before the last rotation. It is valid until
+	// PreviousPasswordExpiresAt, see IsPassword.
+	PreviousPassword          string
+	PreviousPasswordExpiresAt time.Time
+	PasswordRotatedAt         time.Time
+
+	// Federated is true for the users who log in at the LDAP server, or the OIDC issuer,
+	// as well. See ExternalUser.
+	Federated bool
 
 	secretName string
 }
 
+// IsRole returns true for the known roles of users, i.e. admin, and user. The empty role is
+// the user role.
+func IsRole(role string) bool {
+	switch role {
+	case "", "user", "admin":
+		return true
+	}
+	return false
+}
+
+// IsNamespaceRole returns true for the known roles in a namespace.
+func IsNamespaceRole(role string) bool {
+	switch role {
+	case NamespaceRoleReader, NamespaceRoleDeveloper, NamespaceRoleAdmin:
+		return true
+	}
+	return false
+}
+
 // NewUserFromSecret create an Epinio User from a Secret
 func NewUserFromSecret(secret corev1.Secret) User {
 	user := User{
@@ -32,20 +80,163 @@ func NewUserFromSecret(secret corev1.Secret) User {
 		Role:       secret.Labels[kubernetes.EpinioAPISecretRoleLabelKey],
 		Namespaces: []string{},
 
+		NamespaceRoles:    map[string]string{},
+		NamespaceExpiries: map[string]time.Time{},
+
+		PreviousPassword:  string(secret.Data["previous-password"]),
+		PasswordRotatedAt: secret.ObjectMeta.CreationTimestamp.Time,
+		Federated:         secret.Annotations[FederatedAnnotation] == "true",
+
 		secretName: secret.GetName(),
 	}
 
+	// An unparseable expiry is the zero time, i.e. the previous password is not valid.
+	user.PreviousPasswordExpiresAt, _ = time.Parse(time.RFC3339, string(secret.Data["previous-expires"]))
+	if rotated, err := time.Parse(time.RFC3339, secret.Annotations[PasswordRotatedAtAnnotation]); err == nil {
+		user.PasswordRotatedAt = rotated
+	}
+
 	if ns, found := secret.Data["namespaces"]; found {
-		namespaces := strings.TrimSpace(string(ns))
-		for _, namespace := range strings.Split(namespaces, "\n") {
-			namespace = strings.TrimSpace(namespace)
-			if namespace != "" {
-				user.Namespaces = append(user.Namespaces, namespace)
+		user.parseNamespaceEntries(string(ns))
+	}
+	if expiries, found := secret.Data["namespace-expiries"]; found {
+		user.parseNamespaceExpiries(string(expiries))
+	}
+
+	return user
+}
+
+// ExternalUser returns the user identified by the LDAP server, or the OIDC issuer. That is the
+// Epinio user of the same name if it is marked as federated, and a new user with the "user"
+// role otherwise. A name match alone does not make the identity the Epinio user: without the
+// mark the identity is refused with ErrUserNotFederated.
+func ExternalUser(users []User, username string) (User, error) {
+	for _, user := range users {
+		if user.Username != username {
+			continue
+		}
+		if !user.Federated {
+			return User{}, ErrUserNotFederated
+		}
+		return user, nil
+	}
+	return User{Username: username, Role: "user", Namespaces: []string{}}, nil
+}
+
+// parseNamespaceEntries sets the namespaces of the user, with their roles, as stored in the
+// secret of the user. Each line is a namespace, optionally followed by the role of the user
+// in it, as `NAMESPACE:ROLE`.
+func (u *User) parseNamespaceEntries(entries string) {
+	for _, namespace := range strings.Split(strings.TrimSpace(entries), "\n") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" {
+			continue
+		}
+		if pieces := strings.SplitN(namespace, ":", 2); len(pieces) == 2 {
+			namespace = pieces[0]
+			u.NamespaceRoles[namespace] = pieces[1]
+		}
+		u.Namespaces = append(u.Namespaces, namespace)
+	}
+}
+
+// parseNamespaceExpiries sets the expiries of the temporary access of the user, as stored in
+// the secret of the user. Each line is a namespace followed by the time the access ends, as
+// `NAMESPACE RFC3339-TIME`. Unparseable times are in the past, i.e. the access is over.
+func (u *User) parseNamespaceExpiries(entries string) {
+	for _, entry := range strings.Split(strings.TrimSpace(entries), "\n") {
+		pieces := strings.Fields(entry)
+		if len(pieces) == 0 {
+			continue
+		}
+		expiresAt := time.Unix(0, 0)
+		if len(pieces) == 2 {
+			if parsed, err := time.Parse(time.RFC3339, pieces[1]); err == nil {
+				expiresAt = parsed
 			}
 		}
+		u.NamespaceExpiries[pieces[0]] = expiresAt
 	}
+}
 
-	return user
+// NamespaceRole returns the role of the user in the namespace, the empty string for
+// namespaces of others, and for expired access.
+func (u User) NamespaceRole(namespace string) string {
+	if expiresAt, ok := u.NamespaceExpiries[namespace]; ok && !time.Now().Before(expiresAt) {
+		return ""
+	}
+	for _, ns := range u.Namespaces {
+		if ns == namespace {
+			if role, ok := u.NamespaceRoles[namespace]; ok {
+				return role
+			}
+			return NamespaceRoleAdmin
+		}
+	}
+	return ""
+}
+
+// SetNamespaceRole gives the user the role in the namespace, adding the namespace to the
+// User's namespaces, if needed.
+func (u *User) SetNamespaceRole(namespace, role string) {
+	u.AddNamespace(namespace)
+	if u.NamespaceRoles == nil {
+		u.NamespaceRoles = map[string]string{}
+	}
+	if role == NamespaceRoleAdmin {
+		delete(u.NamespaceRoles, namespace)
+		return
+	}
+	u.NamespaceRoles[namespace] = role
+}
+
+// namespaceEntries returns the namespaces of the user, with their roles, as stored in the
+// secret of the user.
+func (u User) namespaceEntries() []string {
+	entries := []string{}
+	for _, namespace := range u.Namespaces {
+		if role, ok := u.NamespaceRoles[namespace]; ok && role != NamespaceRoleAdmin {
+			namespace = namespace + ":" + role
+		}
+		entries = append(entries, namespace)
+	}
+	return entries
+}
+
+// namespaceExpiryEntries returns the expiries of the temporary access of the user, as stored
+// in the secret of the user.
+func (u User) namespaceExpiryEntries() []string {
+	entries := []string{}
+	for _, namespace := range u.Namespaces {
+		if expiresAt, ok := u.NamespaceExpiries[namespace]; ok {
+			entries = append(entries, namespace+" "+expiresAt.UTC().Format(time.RFC3339))
+		}
+	}
+	return entries
+}
+
+// SetNamespaceExpiry makes the access of the user to the namespace end at the time. The zero
+// time makes it permanent.
+func (u *User) SetNamespaceExpiry(namespace string, expiresAt time.Time) {
+	if expiresAt.IsZero() {
+		delete(u.NamespaceExpiries, namespace)
+		return
+	}
+	if u.NamespaceExpiries == nil {
+		u.NamespaceExpiries = map[string]time.Time{}
+	}
+	u.NamespaceExpiries[namespace] = expiresAt
+}
+
+// ExpiredNamespaces returns the namespaces whose temporary access by the user is over.
+func (u User) ExpiredNamespaces(now time.Time) []string {
+	expired := []string{}
+	for _, namespace := range u.Namespaces {
+		if expiresAt, ok := u.NamespaceExpiries[namespace]; ok && !now.Before(expiresAt) {
+			expired = append(expired, namespace)
+		}
+	}
+	return expired
 }
 
 // AddNamespace adds the namespace to the User's namespaces, if not already exists
@@ -63,6 +254,12 @@ func (u *User) AddNamespace(namespace string) {
 	u.Namespaces = append(u.Namespaces, namespace)
 }
 
+// IsExternal returns true for users without Epinio user, i.e. users known only to the OIDC
+// issuer which authenticated them.
+func (u User) IsExternal() bool {
+	return u.secretName == ""
+}
+
 // RemoveNamespace removes a namespace from the User's namespaces.
 // It returns false if the namespace was not there
 func (u *User) RemoveNamespace(namespace string) bool {
@@ -78,6 +275,8 @@ func (u *User) RemoveNamespace(namespace string) bool {
 	}
 
 	u.Namespaces = updatedNamespaces
+	delete(u.NamespaceRoles, namespace)
+	delete(u.NamespaceExpiries, namespace)
 	return removed
 }
 
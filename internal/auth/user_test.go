@@ -0,0 +1,102 @@
+package auth_test
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/auth/authfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Namespace roles", func() {
+
+	It("reads the roles of the user from the secret", func() {
+		user := auth.NewUserFromSecret(newUserSecret("user1", "password", "user", "workspace\nstaging:developer\nproduction:reader"))
+
+		Expect(user.Namespaces).To(Equal([]string{"workspace", "staging", "production"}))
+		Expect(user.NamespaceRole("workspace")).To(Equal(auth.NamespaceRoleAdmin))
+		Expect(user.NamespaceRole("staging")).To(Equal(auth.NamespaceRoleDeveloper))
+		Expect(user.NamespaceRole("production")).To(Equal(auth.NamespaceRoleReader))
+		Expect(user.NamespaceRole("other")).To(Equal(""))
+	})
+
+	Describe("SetNamespaceRole", func() {
+		var authService *auth.AuthService
+		var fake *authfakes.FakeSecretInterface
+
+		BeforeEach(func() {
+			fake = &authfakes.FakeSecretInterface{}
+			authService = &auth.AuthService{SecretInterface: fake}
+
+			userSecret := newUserSecret("user1", "password", "user", "workspace\nstaging:developer")
+			fake.ListReturns(&corev1.SecretList{Items: []corev1.Secret{userSecret}}, nil)
+			fake.GetReturns(&userSecret, nil)
+		})
+
+		It("stores the role of a new namespace", func() {
+			err := authService.SetNamespaceRole(context.Background(), "user1", "production", auth.NamespaceRoleReader)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, secret, _ := fake.UpdateArgsForCall(0)
+			Expect(secret.StringData["namespaces"]).To(Equal("workspace\nstaging:developer\nproduction:reader"))
+		})
+
+		It("changes the role of a known namespace", func() {
+			err := authService.SetNamespaceRole(context.Background(), "user1", "staging", auth.NamespaceRoleAdmin)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, secret, _ := fake.UpdateArgsForCall(0)
+			Expect(secret.StringData["namespaces"]).To(Equal("workspace\nstaging"))
+		})
+
+		It("removes the namespace for role none", func() {
+			err := authService.SetNamespaceRole(context.Background(), "user1", "staging", "none")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, secret, _ := fake.UpdateArgsForCall(0)
+			Expect(secret.StringData["namespaces"]).To(Equal("workspace"))
+		})
+
+		It("fails for unknown users", func() {
+			err := authService.SetNamespaceRole(context.Background(), "user2", "staging", auth.NamespaceRoleReader)
+			Expect(err).To(Equal(auth.ErrUserNotFound))
+		})
+	})
+})
+
+var _ = Describe("ExternalUser", func() {
+	var users []auth.User
+
+	BeforeEach(func() {
+		federated := newUserSecret("jane", "password", "admin", "workspace")
+		federated.Annotations = map[string]string{auth.FederatedAnnotation: "true"}
+
+		users = []auth.User{
+			auth.NewUserFromSecret(federated),
+			auth.NewUserFromSecret(newUserSecret("admin", "password", "admin", "workspace")),
+		}
+	})
+
+	It("is the Epinio user of the same name, when marked as federated", func() {
+		user, err := auth.ExternalUser(users, "jane")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.Federated).To(BeTrue())
+		Expect(user.Role).To(Equal("admin"))
+		Expect(user.Namespaces).To(Equal([]string{"workspace"}))
+	})
+
+	It("refuses identities named like an Epinio user not marked as federated", func() {
+		_, err := auth.ExternalUser(users, "admin")
+		Expect(err).To(Equal(auth.ErrUserNotFederated))
+	})
+
+	It("is a new user, without namespaces, for other identities", func() {
+		user, err := auth.ExternalUser(users, "joe")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.Username).To(Equal("joe"))
+		Expect(user.Role).To(Equal("user"))
+		Expect(user.Namespaces).To(BeEmpty())
+	})
+})
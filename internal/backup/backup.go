@@ -0,0 +1,441 @@
+// Package backup captures the state of the platform, i.e. the namespaces with their apps,
+// configurations, and services, and the users, in an archive, and restores it, e.g. on a
+// fresh install after the loss of a cluster. The archives are kept in the blob store.
+//
+// An archive is a gzipped tar, holding the meta data of the backup, see models.Backup, and the
+// manifests of the resources of each of the Parts, as a YAML stream. The manifests are
+// sanitized like those of an export, see manifests.Sanitize, with the data of the secrets.
+// The images of the apps are not part of the backup, only their references, and the meta
+// data of the registry they are in.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/configurations"
+	"github.com/epinio/epinio/internal/manifests"
+	"github.com/epinio/epinio/internal/services"
+	"github.com/epinio/epinio/internal/version"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// metaFile is the file of the archive holding the meta data of the backup.
+const metaFile = "backup.json"
+
+// scope tells where the resources of a part are.
+type scope int
+
+const (
+	// scopeCluster resources are not namespaced.
+	scopeCluster scope = iota
+	// scopeSystem resources are in the namespace of Epinio.
+	scopeSystem
+	// scopeNamespace resources are in the namespaces of the backup.
+	scopeNamespace
+)
+
+// Part is a kind of resources of the backup.
+type Part struct {
+	Name     string
+	Resource schema.GroupVersionResource
+	scope    scope
+	selector string
+	// keep, if set, tells which of the listed resources belong to the part.
+	keep func(object unstructured.Unstructured, namespaces map[string]struct{}) bool
+}
+
+var (
+	namespacesResource      = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	serviceAccountsResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "serviceaccounts"}
+	secretsResource         = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+	helmChartsResource      = schema.GroupVersionResource{Group: "helm.cattle.io", Version: "v1", Resource: "helmcharts"}
+	appsResource            = schema.GroupVersionResource{Group: "application.epinio.io", Version: "v1", Resource: "apps"}
+)
+
+// Parts are the parts of a backup, in the order of their restore. The configurations created
+// by the services are left out, their releases create them again. The secrets holding the
+// settings of the apps follow the apps, as they are owned by them.
+var Parts = []Part{
+	{
+		Name:     "namespaces",
+		Resource: namespacesResource,
+		scope:    scopeCluster,
+		selector: kubernetes.EpinioNamespaceLabelKey + "=" + kubernetes.EpinioNamespaceLabelValue,
+	},
+	{
+		// The service accounts of the apps, named after their namespace.
+		Name:     "serviceaccounts",
+		Resource: serviceAccountsResource,
+		scope:    scopeNamespace,
+		keep: func(object unstructured.Unstructured, _ map[string]struct{}) bool {
+			return object.GetName() == object.GetNamespace()
+		},
+	},
+	{
+		Name:     "users",
+		Resource: secretsResource,
+		scope:    scopeSystem,
+		selector: kubernetes.EpinioAPISecretLabelKey + "=" + kubernetes.EpinioAPISecretLabelValue,
+	},
+	{
+		Name:     "configurations",
+		Resource: secretsResource,
+		scope:    scopeNamespace,
+		selector: configurations.ConfigurationLabelKey + "=true," + configurations.ConfigurationTypeLabelKey + "=custom",
+	},
+	{
+		Name:     "services",
+		Resource: helmChartsResource,
+		scope:    scopeSystem,
+		selector: services.ServiceNameLabelKey + "," + services.TargetNamespaceLabelKey,
+		keep: func(object unstructured.Unstructured, namespaces map[string]struct{}) bool {
+			_, ok := namespaces[object.GetLabels()[services.TargetNamespaceLabelKey]]
+			return ok
+		},
+	},
+	{
+		Name:     "apps",
+		Resource: appsResource,
+		scope:    scopeNamespace,
+	},
+	{
+		Name:     "appsettings",
+		Resource: secretsResource,
+		scope:    scopeNamespace,
+		selector: application.EpinioApplicationAreaLabel,
+	},
+}
+
+// Archive is a backup, i.e. its meta data, and the resources of its parts, by part name.
+type Archive struct {
+	Meta    models.Backup
+	Objects map[string][]unstructured.Unstructured
+}
+
+// Create captures the state of the platform. The resources of the Epinio installation are
+// those of the system namespace. The registry holds the meta data of the registry of the
+// installation, the images of the apps are added to it.
+func Create(ctx context.Context, client dynamic.Interface, systemNamespace, name string, registry models.BackupRegistry) (*Archive, error) {
+	archive := &Archive{
+		Meta: models.Backup{
+			Name:       name,
+			CreatedAt:  time.Now().UTC(),
+			Version:    version.Version,
+			Namespaces: []string{},
+			Counts:     map[string]int{},
+			Registry:   registry,
+		},
+		Objects: map[string][]unstructured.Unstructured{},
+	}
+
+	namespaces := map[string]struct{}{}
+	for _, part := range Parts {
+		objects, err := collect(ctx, client, systemNamespace, archive.Meta.Namespaces, namespaces, part)
+		if err != nil {
+			return nil, err
+		}
+
+		if part.Resource == namespacesResource {
+			for _, object := range objects {
+				archive.Meta.Namespaces = append(archive.Meta.Namespaces, object.GetName())
+				namespaces[object.GetName()] = struct{}{}
+			}
+		}
+
+		archive.Objects[part.Name] = objects
+		archive.Meta.Counts[part.Name] = len(objects)
+	}
+
+	images := map[string]struct{}{}
+	for _, app := range archive.Objects["apps"] {
+		if image, _, _ := unstructured.NestedString(app.Object, "spec", "imageurl"); image != "" {
+			images[image] = struct{}{}
+		}
+	}
+	archive.Meta.Registry.Images = []string{}
+	for image := range images {
+		archive.Meta.Registry.Images = append(archive.Meta.Registry.Images, image)
+	}
+	sort.Strings(archive.Meta.Registry.Images)
+
+	return archive, nil
+}
+
+// collect returns the sanitized resources of the part, sorted by namespace and name.
+func collect(ctx context.Context, client dynamic.Interface, systemNamespace string, namespaceNames []string, namespaces map[string]struct{}, part Part) ([]unstructured.Unstructured, error) {
+	options := metav1.ListOptions{LabelSelector: part.selector}
+
+	var lists []*unstructured.UnstructuredList
+	switch part.scope {
+	case scopeCluster:
+		list, err := client.Resource(part.Resource).List(ctx, options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the %s", part.Name)
+		}
+		lists = append(lists, list)
+	case scopeSystem:
+		list, err := client.Resource(part.Resource).Namespace(systemNamespace).List(ctx, options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the %s", part.Name)
+		}
+		lists = append(lists, list)
+	case scopeNamespace:
+		for _, namespace := range namespaceNames {
+			list, err := client.Resource(part.Resource).Namespace(namespace).List(ctx, options)
+			if err != nil {
+				return nil, errors.Wrapf(err, "listing the %s of namespace %s", part.Name, namespace)
+			}
+			lists = append(lists, list)
+		}
+	}
+
+	objects := []unstructured.Unstructured{}
+	for _, list := range lists {
+		for _, object := range list.Items {
+			if part.keep != nil && !part.keep(object, namespaces) {
+				continue
+			}
+			manifests.Sanitize(&object, true)
+			objects = append(objects, object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].GetNamespace() != objects[j].GetNamespace() {
+			return objects[i].GetNamespace() < objects[j].GetNamespace()
+		}
+		return objects[i].GetName() < objects[j].GetName()
+	})
+
+	return objects, nil
+}
+
+// Write writes the archive to the writer, as a gzipped tar.
+func (a *Archive) Write(w io.Writer) error {
+	zipped := gzip.NewWriter(w)
+	tarball := tar.NewWriter(zipped)
+
+	meta, err := json.MarshalIndent(a.Meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(tarball, metaFile, meta); err != nil {
+		return err
+	}
+
+	for _, part := range Parts {
+		var stream bytes.Buffer
+		for _, object := range a.Objects[part.Name] {
+			data, err := yaml.Marshal(object.Object)
+			if err != nil {
+				return errors.Wrapf(err, "encoding %s %s", part.Name, object.GetName())
+			}
+			fmt.Fprintf(&stream, "---\n%s", data)
+		}
+		if err := writeFile(tarball, part.Name+".yaml", stream.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := tarball.Close(); err != nil {
+		return err
+	}
+	return zipped.Close()
+}
+
+func writeFile(tarball *tar.Writer, name string, data []byte) error {
+	err := tarball.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", name)
+	}
+	_, err = tarball.Write(data)
+	return errors.Wrapf(err, "writing %s", name)
+}
+
+// Read reads an archive written by Write.
+func Read(r io.Reader) (*Archive, error) {
+	zipped, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad backup archive")
+	}
+	defer zipped.Close()
+
+	archive := &Archive{Objects: map[string][]unstructured.Unstructured{}}
+	hasMeta := false
+
+	tarball := tar.NewReader(zipped)
+	for {
+		header, err := tarball.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "bad backup archive")
+		}
+
+		data, err := io.ReadAll(tarball)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", header.Name)
+		}
+
+		if header.Name == metaFile {
+			if err := json.Unmarshal(data, &archive.Meta); err != nil {
+				return nil, errors.Wrap(err, "bad backup meta data")
+			}
+			hasMeta = true
+			continue
+		}
+
+		partName := strings.TrimSuffix(header.Name, ".yaml")
+		objects, err := decode(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad %s of backup", partName)
+		}
+		archive.Objects[partName] = objects
+	}
+
+	if !hasMeta {
+		return nil, errors.New("bad backup archive, no meta data")
+	}
+	return archive, nil
+}
+
+// decode returns the objects of the YAML stream.
+func decode(stream []byte) ([]unstructured.Unstructured, error) {
+	objects := []unstructured.Unstructured{}
+	for _, document := range strings.Split(string(stream), "---\n") {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, err
+		}
+		objects = append(objects, unstructured.Unstructured{Object: object})
+	}
+	return objects, nil
+}
+
+// Restore creates the resources of the archive, part by part, in the order of Parts. The
+// resources of the backed up installation are placed in the system namespace of this one.
+// Existing resources are left as they are, and reported as skipped, i.e. a failed restore
+// can be run again. The refs of the created apps are returned, for their deployment.
+func Restore(ctx context.Context, client dynamic.Interface, systemNamespace string, archive *Archive) (models.BackupRestoreResponse, []models.AppRef, error) {
+	result := models.BackupRestoreResponse{
+		Created:  map[string]int{},
+		Skipped:  []string{},
+		Warnings: []string{},
+	}
+	created := []models.AppRef{}
+
+	for _, part := range Parts {
+		for _, object := range archive.Objects[part.Name] {
+			object := object.DeepCopy()
+			if part.scope == scopeSystem {
+				object.SetNamespace(systemNamespace)
+			}
+
+			if part.Name == "appsettings" {
+				owner, err := appOwner(ctx, client, object)
+				if err != nil {
+					return result, created, err
+				}
+				if owner != nil {
+					object.SetOwnerReferences([]metav1.OwnerReference{*owner})
+				}
+			}
+
+			var err error
+			if part.scope == scopeCluster {
+				_, err = client.Resource(part.Resource).Create(ctx, object, metav1.CreateOptions{})
+			} else {
+				_, err = client.Resource(part.Resource).Namespace(object.GetNamespace()).Create(ctx, object, metav1.CreateOptions{})
+			}
+			if apierrors.IsAlreadyExists(err) {
+				result.Skipped = append(result.Skipped, resourceName(part, object))
+				continue
+			}
+			if err != nil {
+				return result, created, errors.Wrapf(err, "restoring %s", resourceName(part, object))
+			}
+
+			result.Created[part.Name]++
+			if part.Name == "apps" {
+				created = append(created, models.NewAppRef(object.GetName(), object.GetNamespace()))
+			}
+		}
+	}
+
+	return result, created, nil
+}
+
+// appOwner returns the reference to the app owning the secret of its settings, if the app
+// exists.
+func appOwner(ctx context.Context, client dynamic.Interface, secret *unstructured.Unstructured) (*metav1.OwnerReference, error) {
+	name := secret.GetLabels()["app.kubernetes.io/name"]
+	if name == "" {
+		return nil, nil
+	}
+
+	app, err := client.Resource(appsResource).Namespace(secret.GetNamespace()).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting app %s of namespace %s", name, secret.GetNamespace())
+	}
+
+	return &metav1.OwnerReference{
+		APIVersion: app.GetAPIVersion(),
+		Kind:       app.GetKind(),
+		Name:       app.GetName(),
+		UID:        app.GetUID(),
+	}, nil
+}
+
+// resourceName returns the name of the resource of the part, as `part/namespace/name`, or
+// `part/name` for resources without namespace.
+func resourceName(part Part, object *unstructured.Unstructured) string {
+	if object.GetNamespace() == "" {
+		return part.Name + "/" + object.GetName()
+	}
+	return part.Name + "/" + object.GetNamespace() + "/" + object.GetName()
+}
+
+// RegistryWarnings returns warnings about the images of the backup which are in the registry
+// of the backed up installation, if that is not the registry of this one, given by its url.
+// These images have to be copied to the registry of this installation.
+func RegistryWarnings(meta models.Backup, registryURL string) []string {
+	warnings := []string{}
+	if meta.Registry.URL == "" || meta.Registry.URL == registryURL {
+		return warnings
+	}
+	for _, image := range meta.Registry.Images {
+		if strings.HasPrefix(image, meta.Registry.URL+"/") {
+			warnings = append(warnings, fmt.Sprintf("image %s is in the registry of the backed up installation, %s", image, meta.Registry.URL))
+		}
+	}
+	return warnings
+}
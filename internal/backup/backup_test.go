@@ -0,0 +1,214 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/epinio/epinio/internal/backup"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// object returns the unstructured object of the kind, with the labels, and fields.
+func object(apiVersion, kind, namespace, name string, labels map[string]string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":            name,
+			"uid":             "0123",
+			"resourceVersion": "42",
+		},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	obj.SetLabels(labels)
+	for key, value := range fields {
+		obj.Object[key] = value
+	}
+	return obj
+}
+
+// newClient returns a fake client holding the objects.
+func newClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, part := range backup.Parts {
+		listKinds[part.Resource] = "List"
+	}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+}
+
+var (
+	namespaceLabels = map[string]string{"app.kubernetes.io/component": "epinio-namespace"}
+	userLabels      = map[string]string{"epinio.suse.org/api-user-credentials": "true"}
+	settingsLabels  = map[string]string{"epinio.suse.org/area": "environment", "app.kubernetes.io/name": "web"}
+)
+
+// platform returns the resources of an installation with the namespace workspace, holding the
+// app web, bound to the configuration db, and the service cache.
+func platform() []runtime.Object {
+	return []runtime.Object{
+		object("v1", "Namespace", "", "workspace", namespaceLabels, nil),
+		object("v1", "Namespace", "", "kube-system", nil, nil),
+		object("v1", "ServiceAccount", "workspace", "workspace", nil, nil),
+		object("v1", "ServiceAccount", "workspace", "default", nil, nil),
+		object("v1", "Secret", "epinio", "admin", userLabels, map[string]interface{}{
+			"data": map[string]interface{}{"password": "c2VjcmV0"},
+		}),
+		object("v1", "Secret", "epinio", "registry-creds", nil, nil),
+		object("v1", "Secret", "workspace", "db", map[string]string{
+			"epinio.suse.org/configuration":      "true",
+			"epinio.suse.org/configuration-type": "custom",
+		}, map[string]interface{}{
+			"data": map[string]interface{}{"url": "cG9zdGdyZXM="},
+		}),
+		object("v1", "Secret", "workspace", "cache-redis", map[string]string{
+			"epinio.suse.org/configuration":      "true",
+			"epinio.suse.org/configuration-type": "service",
+		}, nil),
+		object("helm.cattle.io/v1", "HelmChart", "epinio", "xcache", map[string]string{
+			"application.epinio.io/service-name":     "cache",
+			"application.epinio.io/target-namespace": "workspace",
+		}, nil),
+		object("helm.cattle.io/v1", "HelmChart", "epinio", "xother", map[string]string{
+			"application.epinio.io/service-name":     "other",
+			"application.epinio.io/target-namespace": "elsewhere",
+		}, nil),
+		object("application.epinio.io/v1", "App", "workspace", "web", nil, map[string]interface{}{
+			"spec":   map[string]interface{}{"imageurl": "registry.old/apps/web:1"},
+			"status": map[string]interface{}{"phase": "running"},
+		}),
+		object("v1", "Secret", "workspace", "web-env", settingsLabels, map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "web-env",
+				"namespace": "workspace",
+				"labels":    map[string]interface{}{"epinio.suse.org/area": "environment", "app.kubernetes.io/name": "web"},
+				"ownerReferences": []interface{}{map[string]interface{}{
+					"apiVersion": "application.epinio.io/v1", "kind": "App", "name": "web", "uid": "0123",
+				}},
+			},
+		}),
+	}
+}
+
+// names returns the names of the objects.
+func names(objects []unstructured.Unstructured) []string {
+	result := []string{}
+	for _, object := range objects {
+		result = append(result, object.GetName())
+	}
+	return result
+}
+
+var _ = Describe("Backup", func() {
+	ctx := context.Background()
+	registry := models.BackupRegistry{URL: "registry.old", Namespace: "apps"}
+
+	It("captures the epinio namespaces, their resources, and the users", func() {
+		archive, err := backup.Create(ctx, newClient(platform()...), "epinio", "nightly", registry)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(archive.Meta.Name).To(Equal("nightly"))
+		Expect(archive.Meta.Namespaces).To(Equal([]string{"workspace"}))
+		Expect(archive.Meta.Registry.URL).To(Equal("registry.old"))
+		Expect(archive.Meta.Registry.Images).To(Equal([]string{"registry.old/apps/web:1"}))
+		Expect(archive.Meta.Counts).To(Equal(map[string]int{
+			"namespaces": 1, "serviceaccounts": 1, "users": 1, "configurations": 1,
+			"services": 1, "apps": 1, "appsettings": 1,
+		}))
+
+		Expect(names(archive.Objects["serviceaccounts"])).To(Equal([]string{"workspace"}))
+		Expect(names(archive.Objects["configurations"])).To(Equal([]string{"db"}))
+		Expect(names(archive.Objects["services"])).To(Equal([]string{"xcache"}))
+
+		app := archive.Objects["apps"][0]
+		Expect(app.GetUID()).To(BeEmpty())
+		Expect(app.Object).ToNot(HaveKey("status"))
+		Expect(archive.Objects["appsettings"][0].GetOwnerReferences()).To(BeEmpty())
+
+		data, _, _ := unstructured.NestedString(archive.Objects["users"][0].Object, "data", "password")
+		Expect(data).To(Equal("c2VjcmV0"))
+	})
+
+	It("writes, and reads, the archive", func() {
+		archive, err := backup.Create(ctx, newClient(platform()...), "epinio", "nightly", registry)
+		Expect(err).ToNot(HaveOccurred())
+
+		var data bytes.Buffer
+		Expect(archive.Write(&data)).To(Succeed())
+
+		read, err := backup.Read(&data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read.Meta.Name).To(Equal("nightly"))
+		Expect(read.Meta.CreatedAt.Equal(archive.Meta.CreatedAt)).To(BeTrue())
+		Expect(read.Meta.Counts).To(Equal(archive.Meta.Counts))
+		for _, part := range backup.Parts {
+			Expect(read.Objects[part.Name]).To(Equal(archive.Objects[part.Name]), part.Name)
+		}
+	})
+
+	It("rejects archives without meta data", func() {
+		_, err := backup.Read(bytes.NewReader([]byte("not an archive")))
+		Expect(err).To(MatchError(ContainSubstring("bad backup archive")))
+	})
+
+	It("restores the resources, into the system namespace of the installation", func() {
+		archive, err := backup.Create(ctx, newClient(platform()...), "epinio", "nightly", registry)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := newClient(object("v1", "Secret", "epinio-system", "admin", userLabels, nil))
+		result, apps, err := backup.Restore(ctx, client, "epinio-system", archive)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.Created).To(Equal(map[string]int{
+			"namespaces": 1, "serviceaccounts": 1, "configurations": 1,
+			"services": 1, "apps": 1, "appsettings": 1,
+		}))
+		Expect(result.Skipped).To(Equal([]string{"users/epinio-system/admin"}))
+		Expect(apps).To(Equal([]models.AppRef{models.NewAppRef("web", "workspace")}))
+
+		gvr := schema.GroupVersionResource{Group: "helm.cattle.io", Version: "v1", Resource: "helmcharts"}
+		_, err = client.Resource(gvr).Namespace("epinio-system").Get(ctx, "xcache", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		gvr = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+		settings, err := client.Resource(gvr).Namespace("workspace").Get(ctx, "web-env", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.GetOwnerReferences()).To(HaveLen(1))
+		Expect(settings.GetOwnerReferences()[0].Name).To(Equal("web"))
+	})
+
+	It("skips everything when restored again", func() {
+		archive, err := backup.Create(ctx, newClient(platform()...), "epinio", "nightly", registry)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := newClient()
+		_, _, err = backup.Restore(ctx, client, "epinio", archive)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, apps, err := backup.Restore(ctx, client, "epinio", archive)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Created).To(BeEmpty())
+		Expect(result.Skipped).To(HaveLen(7))
+		Expect(apps).To(BeEmpty())
+	})
+
+	It("warns of the images in the registry of the backed up installation", func() {
+		meta := models.Backup{Registry: models.BackupRegistry{
+			URL:    "registry.old",
+			Images: []string{"registry.old/apps/web:1", "docker.io/library/nginx:1"},
+		}}
+
+		Expect(backup.RegistryWarnings(meta, "registry.old")).To(BeEmpty())
+		Expect(backup.RegistryWarnings(meta, "registry.new")).To(Equal([]string{
+			"image registry.old/apps/web:1 is in the registry of the backed up installation, registry.old",
+		}))
+	})
+})
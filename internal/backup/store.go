@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/s3manager"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+// backupsPrefix is the common prefix of all backups in the blob store. A backup is kept as
+// two objects, its archive, and its meta data, for listing the backups without reading their
+// archives.
+const backupsPrefix = "backups/"
+
+var (
+	ErrBackupNotFound = errors.New("backup not found")
+	ErrBackupExists   = errors.New("backup exists already")
+)
+
+// Store keeps the archive in the blob store, under the name of its backup. Existing backups
+// are not replaced.
+func Store(ctx context.Context, cluster *kubernetes.Cluster, archive *Archive) error {
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	existing, err := manager.FetchObject(ctx, metaName(archive.Meta.Name))
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrBackupExists
+	}
+
+	var data bytes.Buffer
+	if err := archive.Write(&data); err != nil {
+		return errors.Wrap(err, "writing the backup archive")
+	}
+	meta, err := json.Marshal(archive.Meta)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]string{"Backup": archive.Meta.Name}
+	err = manager.StoreObject(ctx, archiveName(archive.Meta.Name), data.Bytes(), "application/gzip", metadata)
+	if err != nil {
+		return errors.Wrap(err, "storing the backup archive")
+	}
+	// The meta data is stored last, a backup is listed only after its archive is complete.
+	err = manager.StoreObject(ctx, metaName(archive.Meta.Name), meta, "application/json", metadata)
+	if err != nil {
+		return errors.Wrap(err, "storing the backup meta data")
+	}
+
+	return nil
+}
+
+// List returns the meta data of the backups in the blob store, newest first.
+func List(ctx context.Context, cluster *kubernetes.Cluster) (models.BackupList, error) {
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := manager.ObjectNames(ctx, backupsPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.BackupList{}
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := manager.FetchObject(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+
+		var meta models.Backup
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, errors.Wrapf(err, "bad meta data of backup %s", name)
+		}
+		result = append(result, meta)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+
+	return result, nil
+}
+
+// Fetch returns the archive of the named backup, or ErrBackupNotFound.
+func Fetch(ctx context.Context, cluster *kubernetes.Cluster, name string) (*Archive, error) {
+	manager, err := blobStoreManager(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := manager.FetchObject(ctx, archiveName(name))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrBackupNotFound
+	}
+
+	return Read(bytes.NewReader(data))
+}
+
+func archiveName(name string) string {
+	return path.Join(backupsPrefix, name+".tar.gz")
+}
+
+func metaName(name string) string {
+	return path.Join(backupsPrefix, name+".json")
+}
+
+// blobStoreManager returns a manager for the blob store holding the backups.
+func blobStoreManager(ctx context.Context, cluster *kubernetes.Cluster) (*s3manager.Manager, error) {
+	connectionDetails, err := s3manager.GetConnectionDetails(ctx, cluster,
+		helmchart.Namespace(), helmchart.S3ConnectionDetailsSecretName)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching the S3 connection details from the Kubernetes secret")
+	}
+
+	manager, err := s3manager.New(connectionDetails)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating an S3 manager")
+	}
+
+	return manager, nil
+}
@@ -0,0 +1,59 @@
+package admincmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/pkg/errors"
+)
+
+// RotateCredentials gives the named users, or all users if none are named, new passwords.
+// The previous passwords stay valid for the grace period. The new passwords are in the user
+// secrets. The stored credentials are updated if their user was rotated. It does not use the
+// API server.
+func (a *Admin) RotateCredentials(ctx context.Context, usernames []string, grace time.Duration) error {
+	log := a.Log.WithName("RotateCredentials")
+	log.Info("start")
+	defer log.Info("return")
+
+	a.ui.Note().
+		WithStringValue("Grace period", grace.String()).
+		Msg("Rotating the credentials of the users")
+
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(usernames) == 0 {
+		users, err := authService.GetUsers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			usernames = append(usernames, user.Username)
+		}
+	}
+
+	validUntil := time.Now().Add(grace).Local().Format(time.RFC1123)
+	msg := a.ui.Success().WithTable("User", "Previous Password Valid Until")
+
+	for _, username := range usernames {
+		password, err := authService.RotatePassword(ctx, username, grace)
+		if err != nil {
+			return err
+		}
+		msg = msg.WithTableRow(username, validUntil)
+
+		if username == a.Settings.User {
+			a.Settings.Password = password
+			if err := a.Settings.Save(); err != nil {
+				return errors.Wrap(err, "failed to save configuration")
+			}
+		}
+	}
+
+	msg.Msg("Rotated")
+	return nil
+}
@@ -0,0 +1,238 @@
+package admincmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// backendProtocolAnnotation is the ingress annotation telling that the epinio server speaks
+// https itself, instead of leaving TLS to the ingress.
+const backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
+// Proxy forwards a local port to the epinio server in the cluster, bypassing its ingress, and
+// serves the API on the given address and port, until the context is done. Requests without
+// credentials are sent with the credentials of the settings. It does not use the API server
+// to find the epinio server, only the cluster.
+func (a *Admin) Proxy(ctx context.Context, namespace, address string, port int) error {
+	log := a.Log.WithName("Proxy")
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	a.ui.Note().
+		WithStringValue("Namespace", namespace).
+		Msg("Connecting to the epinio server")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	pod, targetPort, scheme, err := getEpinioServer(ctx, cluster, namespace)
+	if err != nil {
+		return err
+	}
+
+	details.Info("found server", "pod", pod, "port", targetPort, "scheme", scheme)
+
+	forwardURL := cluster.Kubectl.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+
+	transport, upgrader, err := spdy.RoundTripperFor(cluster.RestConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up the port forwarding")
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, forwardURL)
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"},
+		[]string{fmt.Sprintf("0:%d", targetPort)}, stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up the port forwarding")
+	}
+
+	forwardErr := make(chan error, 1)
+	go func() {
+		forwardErr <- forwarder.ForwardPorts()
+	}()
+	defer close(stopChan)
+
+	select {
+	case <-readyChan:
+	case err := <-forwardErr:
+		return errors.Wrap(err, "failed to forward to the epinio server")
+	case <-ctx.Done():
+		return nil
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		return errors.Wrap(err, "failed to forward to the epinio server")
+	}
+
+	target := &url.URL{
+		Scheme: scheme,
+		Host:   fmt.Sprintf("127.0.0.1:%d", ports[0].Local),
+	}
+	details.Info("forwarding", "target", target.String())
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, fmt.Sprintf("%d", port)))
+	if err != nil {
+		return errors.Wrap(err, "error creating listener")
+	}
+
+	proxy := &http.Server{
+		Handler:           a.proxyHandler(target),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- proxy.Serve(listener)
+	}()
+
+	a.ui.Success().
+		WithStringValue("API", "http://"+listener.Addr().String()).
+		WithStringValue("Server", namespace+"/"+pod).
+		Msg("Proxying the epinio API, stop with Ctrl-C")
+
+	select {
+	case <-ctx.Done():
+	case err := <-forwardErr:
+		_ = proxy.Close()
+		return errors.Wrap(err, "lost the connection to the epinio server")
+	case err := <-serveErr:
+		return errors.Wrap(err, "proxy failed")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return proxy.Shutdown(shutdownCtx)
+}
+
+// proxyHandler returns the handler passing the requests on to the target, adding the
+// credentials of the settings to requests which have none.
+func (a *Admin) proxyHandler(target *url.URL) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		if req.Header.Get("Authorization") == "" {
+			if a.Settings.Token != "" {
+				req.Header.Set("Authorization", "Bearer "+a.Settings.Token)
+			} else if a.Settings.User != "" {
+				req.SetBasicAuth(a.Settings.User, a.Settings.Password)
+			}
+		}
+	}
+	proxy.FlushInterval = 100 * time.Millisecond
+	if target.Scheme == "https" {
+		// The certificate of the server is for its public name, not the forwarded port.
+		proxy.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, // nolint:gosec // Local end of a port forwarding
+			},
+		}
+	}
+
+	return proxy
+}
+
+// getEpinioServer returns a running pod of the epinio server in the namespace, the port it
+// serves the API on, and the scheme it speaks. The server is found through the backend of
+// its ingress.
+func getEpinioServer(ctx context.Context, cluster *kubernetes.Cluster, namespace string) (string, int, string, error) {
+	ingresses, err := cluster.ListIngress(ctx, namespace, "app.kubernetes.io/name=epinio")
+	if err != nil {
+		return "", 0, "", errors.Wrap(err, "failed to list ingresses for epinio api server")
+	}
+	if len(ingresses.Items) != 1 {
+		return "", 0, "", errors.Errorf("expected one epinio api ingress, found %d", len(ingresses.Items))
+	}
+
+	ingress := ingresses.Items[0]
+	if len(ingress.Spec.Rules) < 1 ||
+		ingress.Spec.Rules[0].HTTP == nil ||
+		len(ingress.Spec.Rules[0].HTTP.Paths) < 1 ||
+		ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service == nil {
+		return "", 0, "", errors.New("epinio api ingress has no service backend")
+	}
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+
+	scheme := "http"
+	if strings.EqualFold(ingress.Annotations[backendProtocolAnnotation], "https") {
+		scheme = "https"
+	}
+
+	service, err := cluster.Kubectl.CoreV1().Services(namespace).Get(ctx, backend.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, "", errors.Wrapf(err, "failed to get the epinio api service %s", backend.Name)
+	}
+
+	var targetPort *intstr.IntOrString
+	for _, servicePort := range service.Spec.Ports {
+		if (backend.Port.Name != "" && servicePort.Name == backend.Port.Name) ||
+			(backend.Port.Number != 0 && servicePort.Port == backend.Port.Number) {
+			targetPort = &servicePort.TargetPort
+			if targetPort.IntValue() == 0 && targetPort.Type == intstr.Int {
+				// An unset target port is the service port.
+				port := intstr.FromInt(int(servicePort.Port))
+				targetPort = &port
+			}
+			break
+		}
+	}
+	if targetPort == nil {
+		return "", 0, "", errors.Errorf("epinio api service %s has no port for the ingress", backend.Name)
+	}
+
+	pods, err := cluster.Kubectl.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, "", errors.Wrap(err, "failed to list the epinio server pods")
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		if targetPort.Type == intstr.Int {
+			return pod.Name, targetPort.IntValue(), scheme, nil
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == targetPort.StrVal {
+					return pod.Name, int(containerPort.ContainerPort), scheme, nil
+				}
+			}
+		}
+	}
+
+	return "", 0, "", errors.New("no running epinio server found")
+}
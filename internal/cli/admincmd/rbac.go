@@ -0,0 +1,47 @@
+package admincmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/rbac"
+)
+
+// ExportRBAC mirrors the permissions of the users as service accounts, and role bindings, of
+// the current kube cluster, and shows them. It does not use the API server.
+func (a *Admin) ExportRBAC(ctx context.Context) error {
+	log := a.Log.WithName("ExportRBAC")
+	log.Info("start")
+	defer log.Info("return")
+
+	a.ui.Note().Msg("Exporting the permissions of the users as kube RBAC")
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	exports, err := rbac.ExportUsers(ctx, cluster, helmchart.Namespace())
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(exports, func(i, j int) bool { return exports[i].Username < exports[j].Username })
+
+	msg := a.ui.Success().WithTable("User", "Service Account", "Namespaces")
+	for _, export := range exports {
+		roles := []string{}
+		for namespace, role := range export.Roles {
+			roles = append(roles, namespace+": "+role)
+		}
+		sort.Strings(roles)
+		msg = msg.WithTableRow(export.Username, export.ServiceAccount, strings.Join(roles, "\n"))
+	}
+	msg.Msg("Exported. Check with `kubectl auth can-i --list -n NAMESPACE --as system:serviceaccount:" +
+		helmchart.Namespace() + ":SERVICE-ACCOUNT`")
+
+	return nil
+}
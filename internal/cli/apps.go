@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/epinio/epinio/internal/cli/usercmd"
 	"github.com/epinio/epinio/internal/manifest"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
@@ -22,6 +25,12 @@ func init() {
 	CmdAppList.Flags().Bool("all", false, "list all applications")
 	CmdAppLogs.Flags().Bool("follow", false, "follow the logs of the application")
 	CmdAppLogs.Flags().Bool("staging", false, "show the staging logs of the application")
+	CmdAppLogs.Flags().String("instance", "", "show only the logs of the named instance")
+	CmdAppLogs.Flags().Duration("since", 0, "show only the logs younger than the duration, e.g. 10m")
+	CmdAppLogs.Flags().Int64("tail", -1, "show only the last lines of each container, -1 to show all")
+	CmdAppLogs.Flags().String("filter", "", "show only the lines matching the regular expression, plain text matches as substring")
+	CmdAppEvents.Flags().Bool("follow", false, "follow the events of the application")
+	CmdAppExport.Flags().String("format", usercmd.ExportFormatEpinio, "format of the export: epinio (app chart and values), or helm (standalone chart)")
 	CmdAppExec.Flags().StringP("instance", "i", "", "The name of the instance to shell to")
 	CmdAppPortForward.Flags().StringSliceVar(&portForwardAddress, "address", []string{"localhost"}, "Addresses to listen on (comma separated). Only accepts IP addresses or localhost as a value. When localhost is supplied, kubectl will try to bind on both 127.0.0.1 and ::1 and will fail if neither of these addresses are available to bind.")
 	CmdAppPortForward.Flags().StringVarP(&portForwardInstance, "instance", "i", "", "The name of the instance to shell to")
@@ -34,25 +43,63 @@ func init() {
 	envOption(CmdAppUpdate)
 	instancesOption(CmdAppCreate)
 	instancesOption(CmdAppUpdate)
+	autoSleepOption(CmdAppCreate)
+	autoSleepOption(CmdAppUpdate)
+	portOption(CmdAppCreate)
+	portOption(CmdAppUpdate)
+	entrypointOption(CmdAppCreate)
+	entrypointOption(CmdAppUpdate)
+	processOption(CmdAppCreate)
+	processOption(CmdAppUpdate)
+	volumeOption(CmdAppCreate)
+	volumeOption(CmdAppUpdate)
+	chartValueOption(CmdAppCreate)
+	chartValueOption(CmdAppUpdate)
+	pullSecretOption(CmdAppCreate)
+	pullSecretOption(CmdAppUpdate)
 
 	CmdAppCreate.Flags().String("app-chart", "", "App chart to use for deployment")
 	CmdAppUpdate.Flags().String("app-chart", "", "App chart to use for deployment")
 
+	CmdAppCopy.Flags().String("to-namespace", "", "namespace to copy the application to")
+	CmdAppCopy.Flags().StringSliceP("bind", "b", []string{}, "configurations to bind instead of the application's, as `old=new`")
+	envOption(CmdAppCopy)
+	_ = CmdAppCopy.MarkFlagRequired("to-namespace")
+
+	CmdAppAdopt.Flags().String("name", "", "name of the application, defaults to the name of the deployment")
+	CmdAppAdopt.Flags().String("container", "", "container of the deployment to adopt, defaults to the first")
+	CmdAppAdopt.Flags().Bool("dry-run", false, "Show what the adoption takes over, without doing it")
+
 	CmdApp.AddCommand(CmdAppCreate)
-	CmdApp.AddCommand(CmdAppChart) // See chart.go for implementation
-	CmdApp.AddCommand(CmdAppEnv)   // See env.go for implementation
+	CmdApp.AddCommand(CmdAppChart)  // See chart.go for implementation
+	CmdApp.AddCommand(CmdAppEnv)    // See env.go for implementation
+	CmdApp.AddCommand(CmdAppCanary) // See canary.go for implementation
+	CmdApp.AddCommand(CmdAppTasks)  // See tasks.go for implementation
+	CmdApp.AddCommand(CmdAppDomain) // See domains.go for implementation
+	CmdApp.AddCommand(CmdAppRoute)  // See routes.go for implementation
+	CmdApp.AddCommand(CmdAppImage)  // See image.go for implementation
 	CmdApp.AddCommand(CmdAppList)
 	CmdApp.AddCommand(CmdAppLogs)
+	CmdApp.AddCommand(CmdAppEvents)
 	CmdApp.AddCommand(CmdAppExec)
 	CmdApp.AddCommand(CmdAppPortForward)
 
 	CmdApp.AddCommand(CmdAppManifest)
+	CmdApp.AddCommand(CmdAppApply)
+	CmdApp.AddCommand(CmdAppAdopt)
+	CmdApp.AddCommand(CmdAppCopy)
 	CmdApp.AddCommand(CmdAppShow)
 	CmdApp.AddCommand(CmdAppExport)
 	CmdApp.AddCommand(CmdAppUpdate)
 	CmdApp.AddCommand(CmdAppDelete)
 	CmdApp.AddCommand(CmdAppPush) // See push.go for implementation
 	CmdApp.AddCommand(CmdAppRestart)
+	CmdAppRestart.Flags().Bool("all", false, "restart all active applications of the namespace")
+	CmdApp.AddCommand(CmdAppRename)
+	CmdApp.AddCommand(CmdAppSleep)
+	CmdApp.AddCommand(CmdAppWake)
+	CmdApp.AddCommand(CmdAppRollback)
+	CmdApp.AddCommand(CmdAppRevisions)
 	CmdApp.AddCommand(CmdAppRestage)
 }
 
@@ -153,7 +200,12 @@ var CmdAppExport = &cobra.Command{
 			return errors.Wrap(err, "error initializing cli")
 		}
 
-		err = client.AppExport(args[0], args[1])
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --format")
+		}
+
+		err = client.AppExport(args[0], args[1], format)
 		// Note: errors.Wrap (nil, "...") == nil
 		return errors.Wrap(err, "error exporting app")
 	},
@@ -182,6 +234,11 @@ var CmdAppLogs = &cobra.Command{
 			return errors.Wrap(err, "error reading option --staging")
 		}
 
+		params, err := logParameters(cmd)
+		if err != nil {
+			return err
+		}
+
 		stageID, err := client.AppStageID(args[0])
 		if err != nil {
 			return errors.Wrap(err, "error checking app")
@@ -192,12 +249,72 @@ var CmdAppLogs = &cobra.Command{
 			stageID = ""
 		}
 
-		err = client.AppLogs(args[0], stageID, follow)
+		err = client.AppLogs(args[0], stageID, follow, params)
 		// Note: errors.Wrap (nil, "...") == nil
 		return errors.Wrap(err, "error streaming application logs")
 	},
 }
 
+// logParameters returns the restrictions of the logs found in the options of the command.
+func logParameters(cmd *cobra.Command) (models.LogParameters, error) {
+	params := models.LogParameters{}
+
+	var err error
+	params.Instance, err = cmd.Flags().GetString("instance")
+	if err != nil {
+		return params, errors.Wrap(err, "error reading option --instance")
+	}
+
+	params.Since, err = cmd.Flags().GetDuration("since")
+	if err != nil {
+		return params, errors.Wrap(err, "error reading option --since")
+	}
+	if params.Since < 0 {
+		return params, errors.New("bad --since, expected a non-negative duration")
+	}
+
+	tail, err := cmd.Flags().GetInt64("tail")
+	if err != nil {
+		return params, errors.Wrap(err, "error reading option --tail")
+	}
+	if tail >= 0 {
+		params.Tail = &tail
+	}
+
+	params.Filter, err = cmd.Flags().GetString("filter")
+	if err != nil {
+		return params, errors.Wrap(err, "error reading option --filter")
+	}
+
+	return params, nil
+}
+
+// CmdAppEvents implements the command: epinio apps events
+var CmdAppEvents = &cobra.Command{
+	Use:               "events NAME",
+	Short:             "Streams the kubernetes events of the application",
+	Long:              "Streams the kubernetes events of the instances, deployment, and staging jobs of the application, and the conditions of the deployment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		follow, err := cmd.Flags().GetBool("follow")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --follow")
+		}
+
+		err = client.AppEvents(args[0], follow)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error streaming application events")
+	},
+}
+
 // CmdAppExec implements the command: epinio apps exec
 var CmdAppExec = &cobra.Command{
 	Use:   "exec NAME",
@@ -290,9 +407,10 @@ var CmdAppUpdate = &cobra.Command{
 
 // CmdAppManifest implements the command: epinio apps manifest
 var CmdAppManifest = &cobra.Command{
-	Use:               "manifest NAME MANIFESTPATH",
+	Use:               "manifest NAME [MANIFESTPATH]",
 	Short:             "Save state of the named application as a manifest",
-	Args:              cobra.ExactArgs(2),
+	Long:              "Save the state of the named application as a manifest pushing it as it is, or print it",
+	Args:              cobra.RangeArgs(1, 2),
 	ValidArgsFunction: matchingAppsFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
@@ -303,16 +421,193 @@ var CmdAppManifest = &cobra.Command{
 			return errors.Wrap(err, "error initializing cli")
 		}
 
-		err = client.AppManifest(args[0], args[1])
+		manifestPath := ""
+		if len(args) > 1 {
+			manifestPath = args[1]
+		}
+
+		err = client.AppManifest(args[0], manifestPath)
 		// Note: errors.Wrap (nil, "...") == nil
 		return errors.Wrap(err, "error getting app manifest")
 	},
 }
 
+// CmdAppApply implements the command: epinio app apply
+var CmdAppApply = &cobra.Command{
+	Use:   "apply MANIFESTPATH",
+	Short: "Create, or change, the application of the manifest to match it",
+	Long: `Create the application of the manifest, or change the existing one to match it. The settings missing from the manifest are reset to their defaults.
+A container image of the manifest is deployed when it changed. Applying the same manifest again changes nothing. Sources are not applied, they are pushed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		m, err := manifest.Get(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error reading manifest")
+		}
+		if m.Self == "<<Defaults>>" {
+			return errors.Errorf("manifest `%s` not found", args[0])
+		}
+		if m.Name == "" {
+			return errors.New("manifest has no application name")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppApply(m)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error applying app manifest")
+	},
+}
+
+// CmdAppAdopt implements the command: epinio app adopt
+var CmdAppAdopt = &cobra.Command{
+	Use:   "adopt DEPLOYMENT",
+	Short: "Turn an existing deployment into an application",
+	Long: `Turn the named deployment of the targeted namespace into an application running its image, to manage it like pushed applications.
+The instances, environment, and command of the deployment, and the routes of the ingresses to its services, become the settings of the application. The deployment, services, and ingresses are removed once the application is deployed.
+The parts of the deployment which are not carried over, like volumes and additional containers, are reported. Use --dry-run to see them first.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --name")
+		}
+		container, err := cmd.Flags().GetString("container")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --container")
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --dry-run")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppAdopt(models.AppAdoptRequest{
+			Deployment: args[0],
+			Name:       name,
+			Container:  container,
+			DryRun:     dryRun,
+		})
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error adopting deployment")
+	},
+}
+
+// CmdAppCopy implements the command: epinio app copy
+var CmdAppCopy = &cobra.Command{
+	Use:   "copy NAME --to-namespace NAMESPACE",
+	Short: "Copy the named application to another namespace",
+	Long: `Deploy the image of the named application in another namespace, without rebuilding it, e.g. to promote it from staging to production.
+The configurations bound to the application are bound by the same name, unless translated with --bind old=new. The environment given with --env is merged over the application's. Routes are not copied, the copy gets the default route.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		toNamespace, err := cmd.Flags().GetString("to-namespace")
+		if err != nil {
+			return errors.Wrap(err, "failed to read option --to-namespace")
+		}
+
+		assignments, err := cmd.Flags().GetStringSlice("bind")
+		if err != nil {
+			return errors.Wrap(err, "failed to read option --bind")
+		}
+
+		binds := map[string]string{}
+		for _, assignment := range assignments {
+			pieces := strings.SplitN(assignment, "=", 2)
+			if len(pieces) < 2 || pieces[0] == "" || pieces[1] == "" {
+				cmd.SilenceUsage = false
+				return errors.New("Bad --bind assignment `" + assignment + "`, expected `old=new` as value")
+			}
+			binds[pieces[0]] = pieces[1]
+		}
+
+		m, err := manifest.UpdateEnvironment(models.ApplicationManifest{}, cmd)
+		if err != nil {
+			cmd.SilenceUsage = false
+			return err
+		}
+
+		err = client.AppCopy(args[0], toNamespace, binds, m.Configuration.Environment)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error copying app")
+	},
+}
+
 // CmdAppRestart implements the command: epinio app restart
 var CmdAppRestart = &cobra.Command{
-	Use:               "restart NAME",
-	Short:             "Restart the application",
+	Use:               "restart NAME... | --all",
+	Short:             "Restart the applications",
+	Long:              "Restart the named applications, or all active applications of the namespace, with --all. Several applications are restarted by the server in one call.",
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: matchingAppsListFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		names, all, err := batchTargets(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		if len(names) == 1 {
+			err = client.AppRestart(names[0])
+		} else {
+			err = client.RestartApps(names, all)
+		}
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error restarting app")
+	},
+}
+
+// CmdAppRename implements the command: epinio app rename
+var CmdAppRename = &cobra.Command{
+	Use:               "rename NAME NEWNAME",
+	Short:             "Rename the application",
+	Long:              "Rename the application, keeping its image, configuration, and bindings. A default route follows the new name, and an active application is redeployed under it.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppRename(args[0], args[1])
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error renaming app")
+	},
+}
+
+// CmdAppSleep implements the command: epinio app sleep
+var CmdAppSleep = &cobra.Command{
+	Use:               "sleep NAME",
+	Short:             "Put the application to sleep",
+	Long:              "Put the application to sleep, i.e. scale it to zero instances. Its desired instances are kept, for waking it.",
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: matchingAppsFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -323,9 +618,80 @@ var CmdAppRestart = &cobra.Command{
 			return errors.Wrap(err, "error initializing cli")
 		}
 
-		err = client.AppRestart(args[0])
+		err = client.AppSleep(args[0])
 		// Note: errors.Wrap (nil, "...") == nil
-		return errors.Wrap(err, "error restarting app")
+		return errors.Wrap(err, "error putting app to sleep")
+	},
+}
+
+// CmdAppWake implements the command: epinio app wake
+var CmdAppWake = &cobra.Command{
+	Use:               "wake NAME",
+	Short:             "Wake the sleeping application",
+	Long:              "Wake the sleeping application, i.e. scale it back to its desired instances.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppWake(args[0])
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error waking app")
+	},
+}
+
+// CmdAppRevisions implements the command: epinio app revisions
+var CmdAppRevisions = &cobra.Command{
+	Use:               "revisions NAME",
+	Short:             "List the deployment revisions of the application",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppRevisions(args[0])
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error listing app revisions")
+	},
+}
+
+// CmdAppRollback implements the command: epinio app rollback
+var CmdAppRollback = &cobra.Command{
+	Use:               "rollback NAME [REVISION]",
+	Short:             "Roll the application back to a previous revision",
+	Long:              "Roll the application back to the specified revision, or the one before the current, if not specified",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		revision := 0
+		if len(args) == 2 {
+			revision, err = strconv.Atoi(args[1])
+			if err != nil || revision < 1 {
+				cmd.SilenceUsage = false
+				return errors.Errorf("bad revision '%s', expected a positive integer", args[1])
+			}
+		}
+
+		err = client.AppRollback(args[0], revision)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error rolling back app")
 	},
 }
 
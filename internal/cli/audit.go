@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CmdAudit.Flags().String("user", "", "Only show the entries of the user")
+	CmdAudit.Flags().String("namespace", "", "Only show the entries of the namespace")
+	CmdAudit.Flags().Int("limit", 100, "Maximum number of entries to show, 0 for all")
+	_ = CmdAudit.RegisterFlagCompletionFunc("namespace", matchingNamespaceFinder)
+}
+
+// CmdAudit implements the command: epinio audit
+var CmdAudit = &cobra.Command{
+	Use:   "audit",
+	Short: "Shows the audit log",
+	Long:  "Shows the recent requests changing something, the newest first. Only admins can do this.",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --user")
+		}
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --namespace")
+		}
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --limit")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AuditList(user, namespace, limit)
+		if err != nil {
+			return errors.Wrap(err, "error listing audit entries")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdBackup implements the command: epinio backup
+var CmdBackup = &cobra.Command{
+	Use:     "backup",
+	Aliases: []string{"backups"},
+	Short:   "Epinio backups",
+	Long: `Back up the platform state, and restore it, e.g. on a fresh install after the loss of a cluster.
+
+A backup holds the namespaces with their apps, configurations, and services, and the users. It is kept in the blob store of Epinio. The images of the apps are not part of it, only their references, and the registry they are in. The data of the services is not part of it either, a restore provisions them anew.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdBackup.AddCommand(CmdBackupCreate)
+	CmdBackup.AddCommand(CmdBackupList)
+	CmdBackup.AddCommand(CmdBackupRestore)
+}
+
+// CmdBackupCreate implements the command: epinio backup create
+var CmdBackupCreate = &cobra.Command{
+	Use:   "create [NAME]",
+	Short: "Backs up the platform state",
+	Long:  "Backs up the platform state, in a backup of the name. Without name the backup is named after the time of its creation. Only admins can do this.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.BackupCreate(name)
+		if err != nil {
+			return errors.Wrap(err, "error creating backup")
+		}
+
+		return nil
+	},
+}
+
+// CmdBackupList implements the command: epinio backup list
+var CmdBackupList = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the backups",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.BackupList()
+		if err != nil {
+			return errors.Wrap(err, "error listing backups")
+		}
+
+		return nil
+	},
+}
+
+// CmdBackupRestore implements the command: epinio backup restore
+var CmdBackupRestore = &cobra.Command{
+	Use:   "restore NAME",
+	Short: "Restores the platform state from the backup",
+	Long:  "Creates the resources of the backup, in order, i.e. namespaces, users, configurations, services, and apps, and deploys the restored apps. Existing resources are left as they are, a failed restore can be run again. Only admins can do this.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.BackupRestore(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error restoring backup")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdAppCanary implements the command: epinio app canary
+var CmdAppCanary = &cobra.Command{
+	Use:   "canary",
+	Short: "Epinio application canary deployments",
+	Long:  `Manage the canary deployments of epinio applications. Create them with 'epinio push --strategy canary'`,
+}
+
+func init() {
+	CmdCanaryPromote.Flags().Int("weight", 0, "Percentage of route traffic for the canary. Without it, the canary becomes the stable version")
+
+	CmdAppCanary.AddCommand(CmdCanaryPromote)
+	CmdAppCanary.AddCommand(CmdCanaryAbort)
+}
+
+// CmdCanaryPromote implements the command: epinio app canary promote
+var CmdCanaryPromote = &cobra.Command{
+	Use:               "promote APPNAME",
+	Short:             "Promote application canary",
+	Long:              "Shift more traffic to the canary of the named application, or make it the stable version",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		weight, err := cmd.Flags().GetInt("weight")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --weight")
+		}
+		if weight < 0 || weight > 100 {
+			cmd.SilenceUsage = false
+			return errors.Errorf("bad weight '%d', expected a percentage", weight)
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppCanaryPromote(args[0], weight)
+		if err != nil {
+			return errors.Wrap(err, "error promoting app canary")
+		}
+
+		return nil
+	},
+}
+
+// CmdCanaryAbort implements the command: epinio app canary abort
+var CmdCanaryAbort = &cobra.Command{
+	Use:               "abort APPNAME",
+	Short:             "Abort application canary",
+	Long:              "Remove the canary of the named application, returning all traffic to the stable version",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppCanaryAbort(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error aborting app canary")
+		}
+
+		return nil
+	},
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
@@ -61,6 +62,19 @@ func matchingAppsFinder(cmd *cobra.Command, args []string, toComplete string) ([
 	return matches, cobra.ShellCompDirectiveNoFileComp
 }
 
+// matchingAppsListFinder returns a list of matching apps from the provided partial command,
+// for commands taking several apps. The apps already given are left out.
+func matchingAppsListFinder(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	app, err := usercmd.New()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	matches := app.AppsMatching(toComplete)
+
+	return excluding(matches, args), cobra.ShellCompDirectiveNoFileComp
+}
+
 // matchingNamespaceFinder returns a list of matching namespaces from the provided partial command
 func matchingNamespaceFinder(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) != 0 {
@@ -93,3 +107,102 @@ func matchingChartFinder(cmd *cobra.Command, args []string, toComplete string) (
 
 	return matches, cobra.ShellCompDirectiveNoFileComp
 }
+
+// matchingServiceFinder returns a list of matching services from the provided partial command
+func matchingServiceFinder(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	app, err := usercmd.New()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	matches := app.ServiceMatching(toComplete)
+
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// matchingServiceListFinder returns a list of matching services from the provided partial
+// command, for commands taking several services. The services already given are left out.
+func matchingServiceListFinder(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	app, err := usercmd.New()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	matches := app.ServiceMatching(toComplete)
+
+	return excluding(matches, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// excluding returns the names which are not in the excluded names.
+func excluding(names, excluded []string) []string {
+	result := []string{}
+	for _, name := range names {
+		found := false
+		for _, exclude := range excluded {
+			if name == exclude {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// batchTargets returns the names given to a batch command, e.g. delete, or that the command
+// is for all resources, as asked for with --all. One of the two is required.
+func batchTargets(cmd *cobra.Command, args []string) ([]string, bool, error) {
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error reading option --all")
+	}
+	if all && len(args) > 0 {
+		return nil, false, errors.New("names cannot be combined with --all")
+	}
+	if !all && len(args) == 0 {
+		return nil, false, errors.New("requires at least one name, or --all")
+	}
+	return args, all, nil
+}
+
+// matchingServiceAppFinder returns a list of matching services, or apps, from the provided
+// partial command, for commands taking a service, and an app
+func matchingServiceAppFinder(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	app, err := usercmd.New()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// #args == 0: service name, #args == 1: app name.
+	if len(args) == 1 {
+		return app.AppsMatching(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return app.ServiceMatching(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// matchingCatalogFinder returns a list of matching catalog services from the provided partial command
+func matchingCatalogFinder(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	app, err := usercmd.New()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	matches := app.CatalogMatching(toComplete)
+
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
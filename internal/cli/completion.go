@@ -11,7 +11,11 @@ import (
 var CmdCompletion = &cobra.Command{
 	Use:   "completion [bash|zsh|fish|powershell]",
 	Short: "Generate completion script for a shell",
-	Long: `To load completions:
+	Long: `Names of applications, namespaces, configurations, services, and charts are
+completed by asking the Epinio API. They are cached for a short time, and the
+cached names are used when the API does not answer quickly.
+
+To load completions:
 
 Bash:
 
@@ -12,6 +12,8 @@ import (
 
 func init() {
 	CmdConfigurationDelete.Flags().Bool("unbind", false, "Unbind from applications before deleting")
+	CmdConfigurationDelete.Flags().Bool("all", false, "delete all configurations of the namespace")
+	CmdConfigurationBind.Flags().String("mount-path", "", "Directory to project the configuration keys into, as files (default /configurations/NAME)")
 	CmdConfiguration.AddCommand(CmdConfigurationShow)
 	CmdConfiguration.AddCommand(CmdConfigurationCreate)
 	CmdConfiguration.AddCommand(CmdConfigurationUpdate)
@@ -93,16 +95,12 @@ var CmdConfigurationUpdate = &cobra.Command{
 
 // CmdConfigurationDelete implements the command: epinio configuration delete
 var CmdConfigurationDelete = &cobra.Command{
-	Use:   "delete NAME",
-	Short: "Delete a configuration",
-	Long:  `Delete configuration by name.`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "delete NAME... | --all",
+	Short: "Delete configurations",
+	Long:  `Delete configurations by name, or all configurations of the namespace.`,
+	Args:  cobra.ArbitraryArgs,
 	RunE:  ConfigurationDelete,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) != 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-
 		epinioClient, err := usercmd.New()
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
@@ -110,7 +108,7 @@ var CmdConfigurationDelete = &cobra.Command{
 
 		matches := epinioClient.ConfigurationMatching(context.Background(), toComplete)
 
-		return matches, cobra.ShellCompDirectiveNoFileComp
+		return excluding(matches, args), cobra.ShellCompDirectiveNoFileComp
 	},
 }
 
@@ -279,6 +277,11 @@ func ConfigurationUpdate(cmd *cobra.Command, args []string) error {
 
 // ConfigurationDelete is the backend of command: epinio configuration delete
 func ConfigurationDelete(cmd *cobra.Command, args []string) error {
+	names, all, err := batchTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+
 	cmd.SilenceUsage = true
 
 	unbind, err := cmd.Flags().GetBool("unbind")
@@ -291,7 +294,11 @@ func ConfigurationDelete(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "error initializing cli")
 	}
 
-	err = client.DeleteConfiguration(args[0], unbind)
+	if len(names) == 1 {
+		err = client.DeleteConfiguration(names[0], unbind)
+	} else {
+		err = client.DeleteConfigurations(names, unbind, all)
+	}
 	if err != nil {
 		return errors.Wrap(err, "error deleting configuration")
 	}
@@ -303,12 +310,17 @@ func ConfigurationDelete(cmd *cobra.Command, args []string) error {
 func ConfigurationBind(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
+	mountPath, err := cmd.Flags().GetString("mount-path")
+	if err != nil {
+		return errors.Wrap(err, "error reading option --mount-path")
+	}
+
 	client, err := usercmd.New()
 	if err != nil {
 		return errors.Wrap(err, "error initializing cli")
 	}
 
-	err = client.BindConfiguration(args[0], args[1])
+	err = client.BindConfiguration(args[0], args[1], mountPath)
 	if err != nil {
 		return errors.Wrap(err, "error binding configuration")
 	}
@@ -6,15 +6,23 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var ()
+func init() {
+	CmdAppDelete.Flags().Bool("all", false, "delete all applications of the namespace")
+}
 
 // CmdAppDelete implements the command: epinio app delete
 var CmdAppDelete = &cobra.Command{
-	Use:               "delete NAME",
-	Short:             "Deletes an application",
-	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: matchingAppsFinder,
+	Use:               "delete NAME... | --all",
+	Short:             "Deletes applications",
+	Long:              "Deletes the named applications, or all applications of the namespace, in a single call to the server",
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: matchingAppsListFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		names, all, err := batchTargets(cmd, args)
+		if err != nil {
+			return err
+		}
+
 		cmd.SilenceUsage = true
 
 		client, err := usercmd.New()
@@ -22,7 +30,11 @@ var CmdAppDelete = &cobra.Command{
 			return errors.Wrap(err, "error initializing cli")
 		}
 
-		err = client.Delete(cmd.Context(), args[0])
+		if len(names) == 1 {
+			err = client.Delete(cmd.Context(), names[0])
+		} else {
+			err = client.DeleteApps(names, all)
+		}
 		if err != nil {
 			return errors.Wrap(err, "error deleting app")
 		}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdAppDomain implements the command: epinio app domain
+var CmdAppDomain = &cobra.Command{
+	Use:   "domain",
+	Short: "Epinio application custom domains",
+	Long:  `Manage the custom domains of epinio applications. A domain is routed to the application once its ownership is verified, with a certificate from the configured issuer`,
+}
+
+func init() {
+	CmdAppDomain.AddCommand(CmdDomainAdd)
+	CmdAppDomain.AddCommand(CmdDomainList)
+	CmdAppDomain.AddCommand(CmdDomainRemove)
+}
+
+// CmdDomainAdd implements the command: epinio app domain add
+var CmdDomainAdd = &cobra.Command{
+	Use:               "add APPNAME DOMAIN",
+	Short:             "Add application domain",
+	Long:              "Add the custom domain to the named application. Run it again after creating the DNS record it reports, to verify the ownership of the domain",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppDomainAdd(args[0], args[1])
+		if err != nil {
+			return errors.Wrap(err, "error adding app domain")
+		}
+
+		return nil
+	},
+}
+
+// CmdDomainList implements the command: epinio app domain list
+var CmdDomainList = &cobra.Command{
+	Use:               "list APPNAME",
+	Short:             "List application domains",
+	Long:              "List the custom domains of the named application, with their verification and certificate states",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppDomains(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error listing app domains")
+		}
+
+		return nil
+	},
+}
+
+// CmdDomainRemove implements the command: epinio app domain remove
+var CmdDomainRemove = &cobra.Command{
+	Use:               "remove APPNAME DOMAIN",
+	Short:             "Remove application domain",
+	Long:              "Remove the custom domain, and its route, from the named application",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppDomainRemove(args[0], args[1])
+		if err != nil {
+			return errors.Wrap(err, "error removing app domain")
+		}
+
+		return nil
+	},
+}
@@ -20,6 +20,8 @@ func init() {
 	CmdAppEnv.AddCommand(CmdEnvSet)
 	CmdAppEnv.AddCommand(CmdEnvShow)
 	CmdAppEnv.AddCommand(CmdEnvUnset)
+	CmdAppEnv.AddCommand(CmdEnvLoad)
+	CmdAppEnv.AddCommand(CmdEnvExport)
 }
 
 // CmdEnvList implements the command: epinio app env list
@@ -155,3 +157,56 @@ var CmdEnvUnset = &cobra.Command{
 		return nil
 	},
 }
+
+// CmdEnvLoad implements the command: epinio app env load
+var CmdEnvLoad = &cobra.Command{
+	Use:               "load APPNAME FILE",
+	Short:             "Extend application environment from a file",
+	Long:              "Add or change the environment variables of the named application, as found in the .env FILE, all at once",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.EnvLoad(cmd.Context(), args[0], args[1])
+		if err != nil {
+			return errors.Wrap(err, "error loading into app environment")
+		}
+
+		return nil
+	},
+}
+
+// CmdEnvExport implements the command: epinio app env export
+var CmdEnvExport = &cobra.Command{
+	Use:               "export APPNAME [FILE]",
+	Short:             "Export application environment",
+	Long:              "Write the environment variables of the named application to the .env FILE, or print them",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		}
+
+		err = client.EnvExport(cmd.Context(), args[0], path)
+		if err != nil {
+			return errors.Wrap(err, "error exporting app environment")
+		}
+
+		return nil
+	},
+}
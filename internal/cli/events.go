@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CmdEvents.Flags().String("namespace", "", "Only show the events of the namespace")
+	CmdEvents.Flags().StringSlice("type", []string{}, "Only show the events of the type, e.g. app.deployed, or of all types starting with it, e.g. app (multiple)")
+	_ = CmdEvents.RegisterFlagCompletionFunc("namespace", matchingNamespaceFinder)
+}
+
+// CmdEvents implements the command: epinio events
+var CmdEvents = &cobra.Command{
+	Use:   "events",
+	Short: "Streams the platform events",
+	Long:  "Streams the changes of the apps, services, and namespaces of the user as they happen, e.g. stagings, deployments, and deletions, until interrupted.",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --namespace")
+		}
+		types, err := cmd.Flags().GetStringSlice("type")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --type")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.Events(namespace, types)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error streaming platform events")
+	},
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdAppImage implements the command: epinio app image
+var CmdAppImage = &cobra.Command{
+	Use:   "image",
+	Short: "Epinio application images",
+	Long:  `Access the images of epinio applications`,
+}
+
+func init() {
+	CmdAppImagePull.Flags().StringP("file", "f", "", "Tarball to save the image into. Defaults to APPNAME.tar")
+	CmdAppImagePull.Flags().Bool("docker", false, "Load the image into the local docker daemon, instead of saving it")
+
+	CmdAppImage.AddCommand(CmdAppImagePull)
+}
+
+// CmdAppImagePull implements the command: epinio app image pull
+var CmdAppImagePull = &cobra.Command{
+	Use:               "pull APPNAME [--file TARBALL | --docker]",
+	Short:             "Download the image of the application",
+	Long:              "Download the image the named application currently runs, through the API, as a tarball loadable with `docker load`, or directly into the local docker daemon",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --file")
+		}
+		docker, err := cmd.Flags().GetBool("docker")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --docker")
+		}
+		if docker && file != "" {
+			cmd.SilenceUsage = false
+			return errors.New("options --file and --docker exclude each other")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppImagePull(cmd.Context(), args[0], file, docker)
+		if err != nil {
+			return errors.Wrap(err, "error pulling app image")
+		}
+
+		return nil
+	},
+}
@@ -1 +1,164 @@
 package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func init() {
+	CmdLogin.Flags().Bool("oidc", false, "Log in with the single sign-on of the Epinio server")
+	CmdLogin.Flags().String("user", "", "User to log in as. Default is the user of the settings")
+	CmdLogin.Flags().Bool("client-certificate", false, "Enroll the machine for the mutual TLS port of the Epinio server")
+}
+
+// CmdLogin implements the command: epinio login
+var CmdLogin = &cobra.Command{
+	Use:   "login [--user USER] [--oidc] [--client-certificate]",
+	Short: "Log in to the Epinio server",
+	Long: `Log in to the Epinio server, with user and password, or with its single sign-on.
+
+With user and password, the password is asked for, and a session is started. The session token is saved in the settings and used instead of the password, which is removed from the settings. It is refreshed automatically until the session expires. Then the login has to be repeated.
+
+With single sign-on, the login is confirmed in a browser, which may run on another machine. The ID token is saved in the settings and used instead of user and password. It is refreshed automatically while the refresh token is valid.
+
+With --client-certificate, a client certificate is issued to the user after the login, for the mutual TLS port of the Epinio server. The key of the certificate is generated on, and does not leave, the machine. The settings are switched to the mutual TLS port, on which requests without the certificate are refused.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		oidc, err := cmd.Flags().GetBool("oidc")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --oidc")
+		}
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --user")
+		}
+		clientCertificate, err := cmd.Flags().GetBool("client-certificate")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --client-certificate")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		if oidc {
+			err = client.LoginOIDC(cmd.Context())
+			if err != nil {
+				return errors.Wrap(err, "error logging in")
+			}
+			return enroll(clientCertificate)
+		}
+
+		if user == "" {
+			user = client.Settings.User
+		}
+		if user == "" {
+			return errors.New("no user to log in as, use --user")
+		}
+
+		password, err := askPassword(cmd)
+		if err != nil {
+			return errors.Wrap(err, "error reading password")
+		}
+
+		err = client.Login(user, password)
+		if err != nil {
+			return errors.Wrap(err, "error logging in")
+		}
+
+		return enroll(clientCertificate)
+	},
+}
+
+// enroll gets a client certificate for the machine, if asked for. The client is created anew,
+// to authenticate with the token of the login.
+func enroll(clientCertificate bool) error {
+	if !clientCertificate {
+		return nil
+	}
+
+	client, err := usercmd.New()
+	if err != nil {
+		return errors.Wrap(err, "error initializing cli")
+	}
+
+	err = client.EnrollClientCertificate()
+	if err != nil {
+		return errors.Wrap(err, "error enrolling the machine")
+	}
+
+	return nil
+}
+
+// CmdLogout implements the command: epinio logout
+var CmdLogout = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out of the Epinio server",
+	Long:  "Log out of the Epinio server. The session is ended, and the tokens are removed from the settings.",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.Logout()
+		if err != nil {
+			return errors.Wrap(err, "error logging out")
+		}
+
+		return nil
+	},
+}
+
+// askPassword reads the password from the terminal, without echoing it, or from the first
+// line of the standard input, if that is not a terminal.
+func askPassword(cmd *cobra.Command) (string, error) {
+	passwords, err := askPasswords(cmd, "Password: ")
+	if err != nil {
+		return "", err
+	}
+	return passwords[0], nil
+}
+
+// askPasswords reads a password for each prompt, from the terminal, without echoing them,
+// or from the lines of the standard input, if that is not a terminal.
+func askPasswords(cmd *cobra.Command, prompts ...string) ([]string, error) {
+	passwords := []string{}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		reader := bufio.NewReader(os.Stdin)
+		for range prompts {
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" {
+				return nil, err
+			}
+			passwords = append(passwords, strings.TrimRight(line, "\r\n"))
+		}
+		return passwords, nil
+	}
+
+	for _, prompt := range prompts {
+		fmt.Fprint(cmd.OutOrStdout(), prompt)
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(cmd.OutOrStdout())
+		if err != nil {
+			return nil, err
+		}
+		passwords = append(passwords, string(password))
+	}
+	return passwords, nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CmdMigrate.Flags().String("to-context", "", "settings context of the installation to migrate to")
+	CmdMigrate.Flags().String("export", "", "file to export the state to")
+	CmdMigrate.Flags().String("from-file", "", "file with an exported state to migrate, instead of the installation in use")
+	CmdMigrate.Flags().String("image-registry", "", "registry, with namespace, to re-point the images to, defaults to the registry of the target")
+}
+
+// CmdMigrate implements the command: epinio migrate
+var CmdMigrate = &cobra.Command{
+	Use:   "migrate --to-context NAME | --export FILE",
+	Short: "Migrate the platform state to another installation",
+	Long: `Copy the namespaces, with their configurations and apps, and the users of the installation in use to the installation of another settings context, e.g. for the migration to a new cluster. The state can be exported to a file with --export, and migrated from such a file with --from-file.
+
+Resources existing on the target are skipped. The images of the apps in the registry of the installation in use are re-pointed to the registry of the target, or the one given with --image-registry, they have to be copied there. Routes of the main domain are moved to the main domain of the target. Services are not migrated, bindings to their configurations are dropped. Only admins can do this.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		var options usercmd.MigrateOptions
+		var err error
+
+		options.ToContext, err = cmd.Flags().GetString("to-context")
+		if err != nil {
+			return errors.Wrap(err, "failed to read option --to-context")
+		}
+		options.Export, err = cmd.Flags().GetString("export")
+		if err != nil {
+			return errors.Wrap(err, "failed to read option --export")
+		}
+		options.FromFile, err = cmd.Flags().GetString("from-file")
+		if err != nil {
+			return errors.Wrap(err, "failed to read option --from-file")
+		}
+		options.ImageRegistry, err = cmd.Flags().GetString("image-registry")
+		if err != nil {
+			return errors.Wrap(err, "failed to read option --image-registry")
+		}
+
+		if options.ToContext == "" && options.Export == "" {
+			cmd.SilenceUsage = false
+			return errors.New("either --to-context, or --export is required")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.Migrate(options)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error migrating the platform state")
+	},
+}
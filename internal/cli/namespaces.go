@@ -41,6 +41,27 @@ func init() {
 	CmdNamespace.AddCommand(CmdNamespaceList)
 	CmdNamespace.AddCommand(CmdNamespaceDelete)
 	CmdNamespace.AddCommand(CmdNamespaceShow)
+	CmdNamespace.AddCommand(CmdNamespaceExport)
+	CmdNamespace.AddCommand(CmdNamespaceUpdate)
+	CmdNamespace.AddCommand(CmdNamespacePodSecurity)
+	CmdNamespace.AddCommand(CmdNamespaceQuota)
+	CmdNamespace.AddCommand(CmdNamespaceUploadLimit)
+
+	CmdNamespaceExport.Flags().Bool("secrets", false, "Include the data of the secrets. Without only their keys are exported, with empty values")
+	CmdNamespaceCreate.Flags().String("quota", "", "Quota of the namespace, comma-separated, e.g. cpu=20,memory=64Gi,apps=50. Resources are cpu, memory, storage, and apps")
+
+	CmdNamespaceUpdate.Flags().String("builder-image", "", "Default Paketo builder image for the applications of the namespace")
+	CmdNamespaceUpdate.Flags().StringSlice("buildpack", []string{}, "Default buildpacks for the applications of the namespace, in order. Can be set multiple times")
+	CmdNamespaceUpdate.Flags().String("staging-cpu-request", "", "CPU requested by the staging jobs of the namespace, e.g. 500m")
+	CmdNamespaceUpdate.Flags().String("staging-cpu-limit", "", "CPU limit of the staging jobs of the namespace, e.g. 2")
+	CmdNamespaceUpdate.Flags().String("staging-memory-request", "", "Memory requested by the staging jobs of the namespace, e.g. 512Mi")
+	CmdNamespaceUpdate.Flags().String("staging-memory-limit", "", "Memory limit of the staging jobs of the namespace, e.g. 2Gi")
+	CmdNamespaceUpdate.Flags().Duration("staging-timeout", 0, "Time the staging jobs of the namespace may run before they are stopped, e.g. 15m")
+	CmdNamespaceUpdate.Flags().Int("staging-concurrency", 0, "Maximum number of staging jobs of the namespace running at the same time. More are queued. Zero is no limit")
+	CmdNamespaceUpdate.Flags().String("app-chart", "", "App chart used by the applications of the namespace which do not choose one")
+	CmdNamespaceUpdate.Flags().StringSlice("allowed-app-chart", []string{}, "App charts the applications of the namespace may use. Empty allows all")
+	CmdNamespaceUpdate.Flags().String("log-sink-type", "", "Type of the sink the application logs of the namespace are forwarded to, one of loki, syslog, or http")
+	CmdNamespaceUpdate.Flags().String("log-sink-url", "", "Address of the log sink, i.e. the Loki push endpoint, the udp:// or tcp:// address of the syslog server, or the http endpoint")
 }
 
 // CmdNamespaces implements the command: epinio namespace list
@@ -72,12 +93,21 @@ var CmdNamespaceCreate = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
+		value, err := cmd.Flags().GetString("quota")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --quota")
+		}
+		quota, err := usercmd.ParseQuota(value)
+		if err != nil {
+			return err
+		}
+
 		client, err := usercmd.New()
 		if err != nil {
 			return errors.Wrap(err, "error initializing cli")
 		}
 
-		err = client.CreateNamespace(args[0])
+		err = client.CreateNamespace(args[0], quota)
 		if err != nil {
 			return errors.Wrap(err, "error creating epinio-controlled namespace")
 		}
@@ -123,6 +153,201 @@ var CmdNamespaceDelete = &cobra.Command{
 	},
 }
 
+// CmdNamespaceExport implements the command: epinio namespace export
+var CmdNamespaceExport = &cobra.Command{
+	Use:               "export NAME [FILE]",
+	Short:             "Save the Kubernetes manifests of an epinio-controlled namespace",
+	Long:              "Save the Kubernetes manifests of the namespace and its applications, configurations, and services into the file, or print them",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: matchingNamespaceFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		secrets, err := cmd.Flags().GetBool("secrets")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --secrets")
+		}
+
+		file := ""
+		if len(args) > 1 {
+			file = args[1]
+		}
+
+		err = client.ExportNamespace(args[0], file, secrets)
+		if err != nil {
+			return errors.Wrap(err, "error exporting epinio-controlled namespace")
+		}
+
+		return nil
+	},
+}
+
+// CmdNamespaceUpdate implements the command: epinio namespace update
+var CmdNamespaceUpdate = &cobra.Command{
+	Use:               "update NAME",
+	Short:             "Changes the settings of an epinio-controlled namespace",
+	Long:              "Changes the application defaults of an epinio-controlled namespace. Empty values remove a default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingNamespaceFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		changes := usercmd.NamespaceChanges{}
+
+		if cmd.Flags().Changed("buildpack") {
+			value, err := cmd.Flags().GetStringSlice("buildpack")
+			if err != nil {
+				return errors.Wrap(err, "could not read option --buildpack")
+			}
+			changes.Buildpacks = &value
+		}
+
+		if cmd.Flags().Changed("allowed-app-chart") {
+			value, err := cmd.Flags().GetStringSlice("allowed-app-chart")
+			if err != nil {
+				return errors.Wrap(err, "could not read option --allowed-app-chart")
+			}
+			changes.AllowedAppCharts = &value
+		}
+
+		for option, setting := range map[string]**string{
+			"builder-image":          &changes.BuilderImage,
+			"staging-cpu-request":    &changes.StagingCPURequest,
+			"staging-cpu-limit":      &changes.StagingCPULimit,
+			"staging-memory-request": &changes.StagingMemoryRequest,
+			"staging-memory-limit":   &changes.StagingMemoryLimit,
+			"app-chart":              &changes.AppChart,
+			"log-sink-type":          &changes.LogSinkType,
+			"log-sink-url":           &changes.LogSinkURL,
+		} {
+			if !cmd.Flags().Changed(option) {
+				continue
+			}
+			value, err := cmd.Flags().GetString(option)
+			if err != nil {
+				return errors.Wrapf(err, "could not read option --%s", option)
+			}
+			*setting = &value
+		}
+
+		if cmd.Flags().Changed("staging-timeout") {
+			value, err := cmd.Flags().GetDuration("staging-timeout")
+			if err != nil {
+				return errors.Wrap(err, "could not read option --staging-timeout")
+			}
+			seconds := int64(value.Seconds())
+			changes.StagingTimeout = &seconds
+		}
+
+		if cmd.Flags().Changed("staging-concurrency") {
+			value, err := cmd.Flags().GetInt("staging-concurrency")
+			if err != nil {
+				return errors.Wrap(err, "could not read option --staging-concurrency")
+			}
+			changes.StagingConcurrency = &value
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.UpdateNamespace(args[0], changes)
+		if err != nil {
+			return errors.Wrap(err, "error updating epinio-controlled namespace")
+		}
+
+		return nil
+	},
+}
+
+// CmdNamespacePodSecurity implements the command: epinio namespace pod-security
+var CmdNamespacePodSecurity = &cobra.Command{
+	Use:               "pod-security NAME LEVEL",
+	Short:             "Changes the pod security level of an epinio-controlled namespace",
+	Long:              "Changes the Pod Security Standard enforced in an epinio-controlled namespace, one of privileged, baseline, or restricted. Relaxing it lets applications run which do not comply with the level the namespace was created with. Only admins can do this.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingNamespaceFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.SetNamespacePodSecurity(args[0], args[1])
+		if err != nil {
+			return errors.Wrap(err, "error changing the pod security level")
+		}
+
+		return nil
+	},
+}
+
+// CmdNamespaceQuota implements the command: epinio namespace quota
+var CmdNamespaceQuota = &cobra.Command{
+	Use:               "quota NAME QUOTA",
+	Short:             "Changes the quota of an epinio-controlled namespace",
+	Long:              "Replaces the quota of an epinio-controlled namespace, given comma-separated, e.g. cpu=20,memory=64Gi,apps=50. Resources are cpu, memory, storage, and apps. `none` removes the quota. Only admins can do this.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingNamespaceFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		quota, err := usercmd.ParseQuota(args[1])
+		if err != nil {
+			return err
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.SetNamespaceQuota(args[0], quota)
+		if err != nil {
+			return errors.Wrap(err, "error changing the quota")
+		}
+
+		return nil
+	},
+}
+
+// CmdNamespaceUploadLimit implements the command: epinio namespace upload-limit
+var CmdNamespaceUploadLimit = &cobra.Command{
+	Use:               "upload-limit NAME LIMIT",
+	Short:             "Changes the upload limit of an epinio-controlled namespace",
+	Long:              "Changes the size limit of the application sources pushed to an epinio-controlled namespace, e.g. 2Gi. `0` is no limit, `default` restores the default limit of the server. Only admins can do this.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingNamespaceFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		limit := args[1]
+		if limit == "default" {
+			limit = ""
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.SetNamespaceUploadLimit(args[0], limit)
+		if err != nil {
+			return errors.Wrap(err, "error changing the upload limit")
+		}
+
+		return nil
+	},
+}
+
 // CmdNamespaceShow implements the command: epinio namespace show
 var CmdNamespaceShow = &cobra.Command{
 	Use:               "show NAME",
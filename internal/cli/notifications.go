@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdNotification implements the command: epinio notification
+var CmdNotification = &cobra.Command{
+	Use:     "notification",
+	Aliases: []string{"notifications"},
+	Short:   "Epinio notification targets",
+	Long: `Manage the notification targets of the targeted namespace, e.g. chat channels, receiving messages about the outcomes of pushes, and of the provisioning of services.
+
+The type of a target is one of slack, teams, or generic. The url is the incoming webhook of the Slack or Teams channel, or the endpoint receiving the generic messages as JSON posts, with the text of the message, and the event.
+A template replaces the default message. It is a Go template of the event, e.g. "{{.Name}} deployed by {{.User}}", with the fields Type, Namespace, Name, User, and Details.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdNotification.AddCommand(CmdNotificationSet)
+	CmdNotification.AddCommand(CmdNotificationList)
+	CmdNotification.AddCommand(CmdNotificationDelete)
+
+	CmdNotificationSet.Flags().StringSlice("event", []string{}, "Type of the events to notify, e.g. app.deployed, or app for all the events of apps. Can be set multiple times. Leave empty for the outcomes of pushes and service provisioning")
+	CmdNotificationSet.Flags().String("template", "", "Go template of the message, replacing the default one")
+}
+
+// CmdNotificationSet implements the command: epinio notification set
+var CmdNotificationSet = &cobra.Command{
+	Use:   "set NAME TYPE URL [--event TYPE]... [--template TEMPLATE]",
+	Short: "Creates the notification target, or replaces its settings",
+	Long:  "Creates the notification target of the targeted namespace, or replaces its type, url, event types, and template. Only namespace admins can do this.",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		events, err := cmd.Flags().GetStringSlice("event")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --event")
+		}
+		template, err := cmd.Flags().GetString("template")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --template")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.NotificationSet(args[0], models.NotificationTargetRequest{
+			Type:     args[1],
+			URL:      args[2],
+			Events:   events,
+			Template: template,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error setting notification target")
+		}
+
+		return nil
+	},
+}
+
+// CmdNotificationList implements the command: epinio notification list
+var CmdNotificationList = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the notification targets of the targeted namespace",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.NotificationList()
+		if err != nil {
+			return errors.Wrap(err, "error listing notification targets")
+		}
+
+		return nil
+	},
+}
+
+// CmdNotificationDelete implements the command: epinio notification delete
+var CmdNotificationDelete = &cobra.Command{
+	Use:   "delete NAME",
+	Short: "Deletes the notification target",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.NotificationDelete(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error deleting notification target")
+		}
+
+		return nil
+	},
+}
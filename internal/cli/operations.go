@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdOperation implements the command: epinio operation
+var CmdOperation = &cobra.Command{
+	Use:     "operation",
+	Aliases: []string{"operations"},
+	Short:   "Epinio long-running operations",
+	Long: `Follow the long-running operations of the server, e.g. stagings, deployments, and the provisioning of services.
+
+The operations go on when the client disconnects. They are kept for an hour after they finished.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdOperation.AddCommand(CmdOperationShow)
+
+	CmdOperationShow.Flags().Bool("wait", false, "Wait for the operation to finish")
+}
+
+// CmdOperationShow implements the command: epinio operation show
+var CmdOperationShow = &cobra.Command{
+	Use:   "show ID [--wait]",
+	Short: "Shows the phase, progress, and error of the operation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		wait, err := cmd.Flags().GetBool("wait")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --wait")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.OperationShow(args[0], wait)
+		return errors.Wrap(err, "error showing operation")
+	},
+}
@@ -15,7 +15,7 @@ func instancesOption(cmd *cobra.Command) {
 }
 
 func routeOption(cmd *cobra.Command) {
-	cmd.Flags().StringSliceP("route", "r", []string{}, "Custom route to use for the application (a subdomain of the default domain will be used if this is not set). Can be set multiple times to use multiple routes with the same application.")
+	cmd.Flags().StringSliceP("route", "r", []string{}, "Custom route to use for the application (a subdomain of the default domain will be used if this is not set). Can be set multiple times to use multiple routes with the same application. Routes have the form DOMAIN[/PATH][?OPTIONS], where the domain can start with a wildcard, i.e. '*.'. Options, separated by '&', are rewrite=TARGET, replacing the path prefix in the requests reaching the application, tls-secret=NAME, using an existing TLS secret instead of a certificate from the cluster issuer, and protocol=grpc, for applications serving gRPC without TLS (h2c).")
 }
 
 // bindOption initializes the --bind/-b option for the provided command
@@ -62,6 +62,45 @@ func bindOption(cmd *cobra.Command) {
 		})
 }
 
+// portOption initializes the --port option for the provided command
+func portOption(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("port", []string{}, "raw ports to expose outside of the cluster, as `port[/protocol][:expose[:nodeport]]`, with protocol tcp (default) or udp, and expose nodeport (default) or loadbalancer. An empty value removes all ports")
+}
+
+// autoSleepOption initializes the --auto-sleep option for the provided command
+func autoSleepOption(cmd *cobra.Command) {
+	cmd.Flags().Int32("auto-sleep", 0, "minutes without requests after which the application is put to sleep, 0 to not sleep automatically")
+}
+
+// entrypointOption initializes the --command and --args options for the provided command
+func entrypointOption(cmd *cobra.Command) {
+	cmd.Flags().String("command", "", "command replacing the entrypoint of the image, split like a shell would, empty to restore the image's")
+	cmd.Flags().String("args", "", "arguments replacing the arguments of the image, split like a shell would, empty to restore the image's")
+}
+
+// chartValueOption initializes the --chart-value option for the provided command
+func chartValueOption(cmd *cobra.Command) {
+	cmd.Flags().StringArray("chart-value", []string{}, "values for the settings of the app chart, as `name=value`. An empty value removes the setting")
+}
+
+// pullSecretOption initializes the --pull-registry, --pull-username, and --pull-password
+// options for the provided command
+func pullSecretOption(cmd *cobra.Command) {
+	cmd.Flags().String("pull-registry", "", "registry host of the credentials for pulling a private application image, empty to remove the credentials")
+	cmd.Flags().String("pull-username", "", "user of the image pull credentials")
+	cmd.Flags().String("pull-password", "", "password of the image pull credentials, not needed to keep the saved one")
+}
+
+// processOption initializes the --process option for the provided command
+func processOption(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("process", []string{}, "instances of additional process types, as `name=instances`")
+}
+
+// volumeOption initializes the --bind-volume option for the provided command
+func volumeOption(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("bind-volume", []string{}, "persistent volumes to mount, as `name:/path[:size]`")
+}
+
 // envOption initializes the --env/-e option for the provided command
 func envOption(cmd *cobra.Command) {
 	cmd.Flags().StringSliceP("env", "e", []string{}, "environment variables to be used")
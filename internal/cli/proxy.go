@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/epinio/epinio/internal/cli/admincmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	flags := CmdProxy.Flags()
+	flags.StringP("namespace", "n", "epinio", "The namespace of the epinio server")
+	flags.String("address", "127.0.0.1", "The address to serve the API on")
+	flags.Int("port", 8080, "The port to serve the API on. Use 0 to auto-assign a random port")
+}
+
+// CmdProxy implements the command: epinio proxy
+var CmdProxy = &cobra.Command{
+	Use:   "proxy",
+	Short: "Serve the API of the in-cluster epinio server locally",
+	Long:  "Forward a local port to the epinio server of the current cluster, bypassing its ingress, and serve the API with the stored credentials on localhost. Useful when the ingress or its domain are not set up yet, or not reachable.",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --namespace")
+		}
+		address, err := cmd.Flags().GetString("address")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --address")
+		}
+		port, err := cmd.Flags().GetInt("port")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --port")
+		}
+
+		client, err := admincmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = client.Proxy(ctx, namespace, address, port)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error proxying the epinio api")
+	},
+}
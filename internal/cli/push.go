@@ -20,18 +20,36 @@ func init() {
 	CmdAppPush.Flags().StringP("name", "n", "", "Application name. (mandatory if no manifest is provided)")
 	CmdAppPush.Flags().StringP("path", "p", "", "Path to application sources.")
 	CmdAppPush.Flags().String("builder-image", "", "Paketo builder image to use for staging")
+	CmdAppPush.Flags().StringSlice("buildpack", []string{}, "Buildpack to use for staging, instead of those of the builder. Can be set multiple times, in order")
+	CmdAppPush.Flags().String("dockerfile", "", "Build the sources with the Dockerfile at the given path (relative to the sources) instead of buildpacks")
+	CmdAppPush.Flags().Lookup("dockerfile").NoOptDefVal = "Dockerfile"
+	CmdAppPush.Flags().String("build-cache", "", "Where to keep the build cache between stagings: volume (default), registry, or none")
+	CmdAppPush.Flags().StringSlice("build-env", []string{}, "Build-time environment variable (name=value) given to staging only, e.g. BP_JVM_VERSION=17. Can be set multiple times")
 	CmdAppPush.Flags().String("app-chart", "", "App chart to use for deployment")
+	CmdAppPush.Flags().String("strategy", "", "Deployment strategy. Use 'canary' to run the new version next to the current one")
+	CmdAppPush.Flags().Int("weight", models.CanaryWeightDefault, "Canary only: Percentage of route traffic for the new version")
+	CmdAppPush.Flags().Int("error-threshold", 0, "Canary only: Error rate (percent) at which the new version is rolled back automatically, needs a prometheus server recording the ingress traffic")
+	CmdAppPush.Flags().String("archive", "", "Tarball, or zip archive, of the application sources to upload as is, instead of a directory. Use - to read it from stdin")
+	CmdAppPush.Flags().Bool("dry-run", false, "Show the changes the push makes to the application, and whether it is rebuilt, without making them")
 
 	routeOption(CmdAppPush)
 	bindOption(CmdAppPush)
 	envOption(CmdAppPush)
 	instancesOption(CmdAppPush)
+	autoSleepOption(CmdAppPush)
+	portOption(CmdAppPush)
+	entrypointOption(CmdAppPush)
+	processOption(CmdAppPush)
+	volumeOption(CmdAppPush)
+	chartValueOption(CmdAppPush)
+	pullSecretOption(CmdAppPush)
 }
 
 // CmdAppPush implements the command: epinio app push
 var CmdAppPush = &cobra.Command{
 	Use:   "push [flags] [PATH_TO_APPLICATION_MANIFEST]",
 	Short: "Push an application declared in the specified manifest",
+	Long:  "Push an application declared in the specified manifest. Files and directories of local sources matched by the patterns of the `.epinioignore` file in the sources, in gitignore syntax, are not uploaded. With `--output json` or `--output yaml` the progress of the push is printed on stdout as a stream of events, one per line of JSON, or one per YAML document",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
@@ -85,6 +103,26 @@ var CmdAppPush = &cobra.Command{
 			return errors.New("Name required, not found in manifest nor options")
 		}
 
+		// An archive of the sources replaces any other origin.
+
+		archive, err := cmd.Flags().GetString("archive")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --archive")
+		}
+		if archive != "" {
+			if cmd.Flags().Changed("path") || cmd.Flags().Changed("git") || cmd.Flags().Changed("container-image-url") {
+				cmd.SilenceUsage = false
+				return errors.New("option --archive excludes --path, --git, and --container-image-url")
+			}
+			if archive != "-" {
+				archive, err = filepath.Abs(archive)
+				if err != nil {
+					return errors.Wrap(err, "archive not accessible")
+				}
+			}
+			m.Origin = models.ApplicationOrigin{Kind: models.OriginPath, Path: archive}
+		}
+
 		// Final completion: Without origin fall back to working directory
 
 		if m.Origin.Kind == models.OriginNone {
@@ -92,7 +130,7 @@ var CmdAppPush = &cobra.Command{
 			m.Origin.Path = wd
 		}
 
-		if m.Origin.Kind == models.OriginPath {
+		if m.Origin.Kind == models.OriginPath && archive != "-" {
 			if _, err := os.Stat(m.Origin.Path); err != nil {
 				// Path issue is user error. Show usage
 				cmd.SilenceUsage = false
@@ -100,8 +138,37 @@ var CmdAppPush = &cobra.Command{
 			}
 		}
 
+		strategy, err := cmd.Flags().GetString("strategy")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --strategy")
+		}
+		if strategy != "" && strategy != models.StrategyCanary {
+			cmd.SilenceUsage = false
+			return errors.Errorf("bad strategy '%s', expected '%s'", strategy, models.StrategyCanary)
+		}
+
+		weight, err := cmd.Flags().GetInt("weight")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --weight")
+		}
+
+		errorThreshold, err := cmd.Flags().GetInt("error-threshold")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --error-threshold")
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --dry-run")
+		}
+
 		params := usercmd.PushParams{
 			ApplicationManifest: m,
+			Strategy:            strategy,
+			Weight:              weight,
+			ErrorThreshold:      errorThreshold,
+			DryRun:              dryRun,
+			Archive:             archive,
 		}
 
 		err = client.Push(cmd.Context(), params)
@@ -13,6 +13,8 @@ import (
 	settings "github.com/epinio/epinio/internal/cli/settings"
 	"github.com/epinio/epinio/internal/duration"
 	"github.com/epinio/epinio/internal/version"
+	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,6 +34,12 @@ var rootCmd = &cobra.Command{
 	Long:          `epinio cli is the official command line interface for Epinio PaaS `,
 	Version:       version.Version,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("quiet") && viper.GetInt("verbose") > 0 {
+			return errors.New("--quiet and --verbose cannot be combined")
+		}
+		return termui.ValidateOutputFormat(viper.GetString("output"))
+	},
 }
 
 // Execute executes the root command.
@@ -60,6 +68,29 @@ func init() {
 	viper.BindPFlag("verbosity", pf.Lookup("verbosity"))
 	argToEnv["verbosity"] = "VERBOSITY"
 
+	pf.String("context", "", "Settings context to use, instead of the current one")
+	viper.BindPFlag("context", pf.Lookup("context"))
+	argToEnv["context"] = "EPINIO_CONTEXT"
+
+	pf.BoolP("quiet", "q", false, "Only print results and problems, no notes and progress")
+	viper.BindPFlag("quiet", pf.Lookup("quiet"))
+	argToEnv["quiet"] = "EPINIO_QUIET"
+
+	pf.CountP("verbose", "v", "Print more progress messages, same as raising --verbosity; repeatable")
+	viper.BindPFlag("verbose", pf.Lookup("verbose"))
+
+	pf.StringP("output", "o", termui.OutputTable, "Output format of listings and details: table, json, or yaml")
+	viper.BindPFlag("output", pf.Lookup("output"))
+	argToEnv["output"] = "EPINIO_OUTPUT"
+
+	pf.Int("retries", epinioapi.DefaultRetryPolicy.Attempts-1, "Number of retries of API requests failing for transient reasons, e.g. an unavailable ingress. Requests changing things are only retried if they did not reach the server")
+	viper.BindPFlag("retries", pf.Lookup("retries"))
+	argToEnv["retries"] = "EPINIO_RETRIES"
+
+	pf.Duration("retry-backoff", epinioapi.DefaultRetryPolicy.Backoff, "Time to wait before the first retry of an API request, doubled for each further retry")
+	viper.BindPFlag("retry-backoff", pf.Lookup("retry-backoff"))
+	argToEnv["retry-backoff"] = "EPINIO_RETRY_BACKOFF"
+
 	pf.BoolP("skip-ssl-verification", "", false, "Skip the verification of TLS certificates")
 	viper.BindPFlag("skip-ssl-verification", pf.Lookup("skip-ssl-verification"))
 	argToEnv["skip-ssl-verification"] = "SKIP_SSL_VERIFICATION"
@@ -73,10 +104,24 @@ func init() {
 	rootCmd.AddCommand(CmdCompletion)
 	rootCmd.AddCommand(CmdSettings)
 	rootCmd.AddCommand(CmdInfo)
+	rootCmd.AddCommand(CmdLogin)
+	rootCmd.AddCommand(CmdLogout)
+	rootCmd.AddCommand(CmdToken)
+	rootCmd.AddCommand(CmdUser)
+	rootCmd.AddCommand(CmdTeam)
+	rootCmd.AddCommand(CmdWebhook)
+	rootCmd.AddCommand(CmdBackup)
+	rootCmd.AddCommand(CmdMigrate)
+	rootCmd.AddCommand(CmdNotification)
+	rootCmd.AddCommand(CmdAudit)
+	rootCmd.AddCommand(CmdEvents)
+	rootCmd.AddCommand(CmdOperation)
 	rootCmd.AddCommand(CmdNamespace)
 	rootCmd.AddCommand(CmdAppPush) // shorthand access to `app push`.
 	rootCmd.AddCommand(CmdApp)
 	rootCmd.AddCommand(CmdTarget)
+	rootCmd.AddCommand(CmdTop)
+	rootCmd.AddCommand(CmdProxy)
 	rootCmd.AddCommand(CmdConfiguration)
 	rootCmd.AddCommand(CmdServer)
 	rootCmd.AddCommand(cmdVersion)
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdAppRoute implements the command: epinio app route
+var CmdAppRoute = &cobra.Command{
+	Use:   "route",
+	Short: "Epinio application routes",
+	Long:  `Manage the options of the routes of epinio applications`,
+}
+
+func init() {
+	CmdRouteUpdate.Flags().String("tls-secret", "", "Existing kube TLS secret in the namespace holding the certificate of the route. An empty name returns the route to the certificate of the cluster issuer")
+
+	CmdAppRoute.AddCommand(CmdRouteUpdate)
+}
+
+// CmdRouteUpdate implements the command: epinio app route update
+var CmdRouteUpdate = &cobra.Command{
+	Use:               "update APPNAME ROUTE",
+	Short:             "Update application route",
+	Long:              "Update the options of the route of the named application",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if !cmd.Flags().Changed("tls-secret") {
+			return errors.New("nothing to update, use --tls-secret")
+		}
+
+		tlsSecret, err := cmd.Flags().GetString("tls-secret")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --tls-secret")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppRouteUpdate(args[0], args[1], tlsSecret)
+		if err != nil {
+			return errors.Wrap(err, "error updating app route")
+		}
+
+		return nil
+	},
+}
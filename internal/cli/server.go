@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -9,14 +10,33 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/helpers/termui"
 	"github.com/epinio/epinio/helpers/tracelog"
+	"github.com/epinio/epinio/internal/admission"
+	"github.com/epinio/epinio/internal/api/v1/deploy"
+	"github.com/epinio/epinio/internal/application"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/admincmd"
 	"github.com/epinio/epinio/internal/cli/server"
+	"github.com/epinio/epinio/internal/cloudevents"
+	"github.com/epinio/epinio/internal/health"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/logsink"
+	"github.com/epinio/epinio/internal/metrics"
+	"github.com/epinio/epinio/internal/notifications"
+	"github.com/epinio/epinio/internal/rbac"
+	"github.com/epinio/epinio/internal/rpcserver"
+	"github.com/epinio/epinio/internal/tracing"
 	"github.com/epinio/epinio/internal/version"
+	"github.com/epinio/epinio/internal/webhooks"
 	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/dynamic"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -24,6 +44,10 @@ import (
 )
 
 func init() {
+	CmdServer.AddCommand(CmdServerRotateCredentials)
+	CmdServer.AddCommand(CmdServerExportRBAC)
+	CmdServerRotateCredentials.Flags().Duration("grace-period", 24*time.Hour, "Time the previous passwords stay valid")
+
 	flags := CmdServer.Flags()
 
 	flags.StringP("namespace", "n", "epinio", "(NAMESPACE) The namespace to use")
@@ -57,6 +81,254 @@ func init() {
 	flags.String("ingress-class-name", "", "(INGRESS_CLASS_NAME) Name of the ingress class to use for apps. Leave empty to add no ingressClassName to the ingress.")
 	viper.BindPFlag("ingress-class-name", flags.Lookup("ingress-class-name"))
 	viper.BindEnv("ingress-class-name", "INGRESS_CLASS_NAME")
+
+	flags.String("ingress-annotations-allowed", "", "(INGRESS_ANNOTATIONS_ALLOWED) Comma-separated ingress annotations users may set on their app routes. A trailing '*' allows all annotations with that prefix. Leave empty to allow none.")
+	viper.BindPFlag("ingress-annotations-allowed", flags.Lookup("ingress-annotations-allowed"))
+	viper.BindEnv("ingress-annotations-allowed", "INGRESS_ANNOTATIONS_ALLOWED")
+
+	flags.String("prometheus-url", "", "(PROMETHEUS_URL) Base URL of a prometheus server to take the resource usage of application instances from, when the metrics server is not available.")
+	viper.BindPFlag("prometheus-url", flags.Lookup("prometheus-url"))
+	viper.BindEnv("prometheus-url", "PROMETHEUS_URL")
+
+	flags.Int("staging-concurrency", 0, "(STAGING_CONCURRENCY) Maximum number of staging jobs running at the same time. More are queued. Leave empty for no limit.")
+	viper.BindPFlag("staging-concurrency", flags.Lookup("staging-concurrency"))
+	viper.BindEnv("staging-concurrency", "STAGING_CONCURRENCY")
+
+	flags.Duration("staging-logs-retention", 0, "(STAGING_LOGS_RETENTION) Time to keep the logs of completed stagings. Leave empty to keep them forever.")
+	viper.BindPFlag("staging-logs-retention", flags.Lookup("staging-logs-retention"))
+	viper.BindEnv("staging-logs-retention", "STAGING_LOGS_RETENTION")
+
+	flags.String("upload-limit", "1Gi", "(UPLOAD_LIMIT) Size limit of the application sources pushed to a namespace, e.g. 512Mi, unless admins set another for the namespace. 0 is no limit.")
+	viper.BindPFlag("upload-limit", flags.Lookup("upload-limit"))
+	viper.BindEnv("upload-limit", "UPLOAD_LIMIT")
+
+	flags.String("activator-host", "", "(ACTIVATOR_HOST) DNS name of the Epinio server as reachable from the application namespaces, for requests to sleeping applications to wake them.")
+	viper.BindPFlag("activator-host", flags.Lookup("activator-host"))
+	viper.BindEnv("activator-host", "ACTIVATOR_HOST")
+
+	flags.Int("activator-port", 0, "(ACTIVATOR_PORT) Port to listen on for requests to sleeping applications, to wake them. Leave empty to not wake applications on request.")
+	viper.BindPFlag("activator-port", flags.Lookup("activator-port"))
+	viper.BindEnv("activator-port", "ACTIVATOR_PORT")
+
+	flags.String("oidc-issuer", "", "(OIDC_ISSUER) URL of an OIDC issuer whose ID tokens are accepted by the API. Leave empty to disable single sign-on.")
+	viper.BindPFlag("oidc-issuer", flags.Lookup("oidc-issuer"))
+	viper.BindEnv("oidc-issuer", "OIDC_ISSUER")
+
+	flags.String("oidc-client-id", "", "(OIDC_CLIENT_ID) Client id of Epinio at the OIDC issuer. ID tokens have to be issued for it.")
+	viper.BindPFlag("oidc-client-id", flags.Lookup("oidc-client-id"))
+	viper.BindEnv("oidc-client-id", "OIDC_CLIENT_ID")
+
+	flags.String("oidc-username-claim", "email", "(OIDC_USERNAME_CLAIM) Claim of the ID tokens naming the user")
+	viper.BindPFlag("oidc-username-claim", flags.Lookup("oidc-username-claim"))
+	viper.BindEnv("oidc-username-claim", "OIDC_USERNAME_CLAIM")
+
+	flags.String("oidc-groups-claim", "groups", "(OIDC_GROUPS_CLAIM) Claim of the ID tokens listing the groups of the user")
+	viper.BindPFlag("oidc-groups-claim", flags.Lookup("oidc-groups-claim"))
+	viper.BindEnv("oidc-groups-claim", "OIDC_GROUPS_CLAIM")
+
+	flags.String("oidc-group-roles", "", "(OIDC_GROUP_ROLES) Comma-separated GROUP=ROLE assignments giving the users of the groups their Epinio role. Users of no listed group get the role of their Epinio user, if any, else 'user'.")
+	viper.BindPFlag("oidc-group-roles", flags.Lookup("oidc-group-roles"))
+	viper.BindEnv("oidc-group-roles", "OIDC_GROUP_ROLES")
+
+	flags.String("ldap-url", "", "(LDAP_URL) URL of an LDAP server, like Active Directory, to check the passwords of users with, `ldaps://`, or `ldap://` with StartTLS. Leave empty to disable LDAP.")
+	viper.BindPFlag("ldap-url", flags.Lookup("ldap-url"))
+	viper.BindEnv("ldap-url", "LDAP_URL")
+
+	flags.Bool("ldap-start-tls", false, "(LDAP_START_TLS) Upgrade the connections to an `ldap://` url to TLS with StartTLS")
+	viper.BindPFlag("ldap-start-tls", flags.Lookup("ldap-start-tls"))
+	viper.BindEnv("ldap-start-tls", "LDAP_START_TLS")
+
+	flags.String("ldap-ca-cert", "", "(LDAP_CA_CERT) PEM encoded certificate of the CA of the LDAP server, if the system does not trust it")
+	viper.BindPFlag("ldap-ca-cert", flags.Lookup("ldap-ca-cert"))
+	viper.BindEnv("ldap-ca-cert", "LDAP_CA_CERT")
+
+	flags.Bool("ldap-insecure", false, "(LDAP_INSECURE) Accept an `ldap://` url without StartTLS, sending the passwords of the users in the clear. Only for tests.")
+	viper.BindPFlag("ldap-insecure", flags.Lookup("ldap-insecure"))
+	viper.BindEnv("ldap-insecure", "LDAP_INSECURE")
+
+	flags.String("ldap-bind-dn", "", "(LDAP_BIND_DN) DN of the service account searching the users, and groups. Leave empty to search anonymously.")
+	viper.BindPFlag("ldap-bind-dn", flags.Lookup("ldap-bind-dn"))
+	viper.BindEnv("ldap-bind-dn", "LDAP_BIND_DN")
+
+	flags.String("ldap-bind-password", "", "(LDAP_BIND_PASSWORD) Password of the service account")
+	viper.BindPFlag("ldap-bind-password", flags.Lookup("ldap-bind-password"))
+	viper.BindEnv("ldap-bind-password", "LDAP_BIND_PASSWORD")
+
+	flags.String("ldap-user-base-dn", "", "(LDAP_USER_BASE_DN) DN the users are searched below")
+	viper.BindPFlag("ldap-user-base-dn", flags.Lookup("ldap-user-base-dn"))
+	viper.BindEnv("ldap-user-base-dn", "LDAP_USER_BASE_DN")
+
+	flags.String("ldap-user-filter", "(uid=%s)", "(LDAP_USER_FILTER) Filter finding the user, `%s` is replaced by the name of the user. Use `(sAMAccountName=%s)` for Active Directory.")
+	viper.BindPFlag("ldap-user-filter", flags.Lookup("ldap-user-filter"))
+	viper.BindEnv("ldap-user-filter", "LDAP_USER_FILTER")
+
+	flags.String("ldap-group-base-dn", "", "(LDAP_GROUP_BASE_DN) DN the groups of the users are searched below. Leave empty to not search groups.")
+	viper.BindPFlag("ldap-group-base-dn", flags.Lookup("ldap-group-base-dn"))
+	viper.BindEnv("ldap-group-base-dn", "LDAP_GROUP_BASE_DN")
+
+	flags.String("ldap-group-filter", "(member=%s)", "(LDAP_GROUP_FILTER) Filter finding the groups of the user, `%s` is replaced by the DN of the user")
+	viper.BindPFlag("ldap-group-filter", flags.Lookup("ldap-group-filter"))
+	viper.BindEnv("ldap-group-filter", "LDAP_GROUP_FILTER")
+
+	flags.String("ldap-group-name-attribute", "cn", "(LDAP_GROUP_NAME_ATTRIBUTE) Attribute naming the groups")
+	viper.BindPFlag("ldap-group-name-attribute", flags.Lookup("ldap-group-name-attribute"))
+	viper.BindEnv("ldap-group-name-attribute", "LDAP_GROUP_NAME_ATTRIBUTE")
+
+	flags.String("ldap-group-roles", "", "(LDAP_GROUP_ROLES) Comma-separated GROUP=ROLE assignments giving the users of the LDAP groups their Epinio role. Users of no listed group get the role of their Epinio user, if any, else 'user'.")
+	viper.BindPFlag("ldap-group-roles", flags.Lookup("ldap-group-roles"))
+	viper.BindEnv("ldap-group-roles", "LDAP_GROUP_ROLES")
+
+	flags.Duration("session-ttl", time.Hour, "(SESSION_TTL) Time a session token of the CLI is valid. The CLI refreshes it while the session lasts.")
+	viper.BindPFlag("session-ttl", flags.Lookup("session-ttl"))
+	viper.BindEnv("session-ttl", "SESSION_TTL")
+
+	flags.Duration("session-refresh-ttl", 7*24*time.Hour, "(SESSION_REFRESH_TTL) Time a session of the CLI lasts. Then the user has to log in again.")
+	viper.BindPFlag("session-refresh-ttl", flags.Lookup("session-refresh-ttl"))
+	viper.BindEnv("session-refresh-ttl", "SESSION_REFRESH_TTL")
+
+	flags.Bool("rbac-export", false, "(RBAC_EXPORT) Mirror the permissions of the users as service accounts, and role bindings, for auditing them with kube tools")
+	viper.BindPFlag("rbac-export", flags.Lookup("rbac-export"))
+	viper.BindEnv("rbac-export", "RBAC_EXPORT")
+
+	flags.Bool("swagger-ui", false, "(SWAGGER_UI) Serve the Swagger UI of the OpenAPI document of the API at /api/v1/docs")
+	viper.BindPFlag("swagger-ui", flags.Lookup("swagger-ui"))
+	viper.BindEnv("swagger-ui", "SWAGGER_UI")
+
+	flags.Float64("rate-limit", 0, "(RATE_LIMIT) API requests per second allowed to each user, and API token. Leave empty for no limit.")
+	viper.BindPFlag("rate-limit", flags.Lookup("rate-limit"))
+	viper.BindEnv("rate-limit", "RATE_LIMIT")
+
+	flags.Int("rate-limit-burst", 20, "(RATE_LIMIT_BURST) API requests each user, and API token, can make at once, above the rate limit")
+	viper.BindPFlag("rate-limit-burst", flags.Lookup("rate-limit-burst"))
+	viper.BindEnv("rate-limit-burst", "RATE_LIMIT_BURST")
+
+	flags.String("rate-limit-overrides", "", "(RATE_LIMIT_OVERRIDES) Comma-separated rate limits of single API routes, as ROUTE=RATE[:BURST], e.g. AppUpload=0.1:2. These routes are limited separately. A rate of 0 is no limit.")
+	viper.BindPFlag("rate-limit-overrides", flags.Lookup("rate-limit-overrides"))
+	viper.BindEnv("rate-limit-overrides", "RATE_LIMIT_OVERRIDES")
+
+	flags.Duration("idempotency-ttl", 24*time.Hour, "(IDEMPOTENCY_TTL) Time the responses of requests with an Idempotency-Key header are kept, for replaying them to retries, in the memory of each replica. 0 ignores the header.")
+	viper.BindPFlag("idempotency-ttl", flags.Lookup("idempotency-ttl"))
+	viper.BindEnv("idempotency-ttl", "IDEMPOTENCY_TTL")
+
+	flags.Duration("credentials-rotation-interval", 0, "(CREDENTIALS_ROTATION_INTERVAL) Time after which the passwords of the users are rotated. The new passwords are in the user secrets. Leave empty to not rotate them.")
+	viper.BindPFlag("credentials-rotation-interval", flags.Lookup("credentials-rotation-interval"))
+	viper.BindEnv("credentials-rotation-interval", "CREDENTIALS_ROTATION_INTERVAL")
+
+	flags.Duration("credentials-grace-period", 24*time.Hour, "(CREDENTIALS_GRACE_PERIOD) Time the previous password of a user stays valid after a rotation")
+	viper.BindPFlag("credentials-grace-period", flags.Lookup("credentials-grace-period"))
+	viper.BindEnv("credentials-grace-period", "CREDENTIALS_GRACE_PERIOD")
+
+	flags.String("audit-sink", "", "(AUDIT_SINK) Where to write the audit log of the API requests changing something [events,file,webhook]. Leave empty to only keep the recent entries in memory.")
+	viper.BindPFlag("audit-sink", flags.Lookup("audit-sink"))
+	viper.BindEnv("audit-sink", "AUDIT_SINK")
+
+	flags.String("audit-sink-target", "", "(AUDIT_SINK_TARGET) File to append the audit log to, for the file sink, or URL to post the entries to, for the webhook sink")
+	viper.BindPFlag("audit-sink-target", flags.Lookup("audit-sink-target"))
+	viper.BindEnv("audit-sink-target", "AUDIT_SINK_TARGET")
+
+	flags.Int("mtls-port", 0, "(MTLS_PORT) Port to serve the API on with mutual TLS, to enrolled machines only. Leave empty to not serve it.")
+	viper.BindPFlag("mtls-port", flags.Lookup("mtls-port"))
+	viper.BindEnv("mtls-port", "MTLS_PORT")
+
+	flags.Int("grpc-port", 0, "(GRPC_PORT) Port to serve the gRPC API on, over HTTP/2 without TLS, like the REST API. Leave empty to not serve it.")
+	viper.BindPFlag("grpc-port", flags.Lookup("grpc-port"))
+	viper.BindEnv("grpc-port", "GRPC_PORT")
+
+	flags.String("mtls-certificate-secret", "", "(MTLS_CERTIFICATE_SECRET) Secret for the TLS certificate of the mutual TLS port")
+	viper.BindPFlag("mtls-certificate-secret", flags.Lookup("mtls-certificate-secret"))
+	viper.BindEnv("mtls-certificate-secret", "MTLS_CERTIFICATE_SECRET")
+
+	flags.String("mtls-ca-secret", "", "(MTLS_CA_SECRET) Secret for the CA certificate, and key, issuing the client certificates of the enrolled machines. Leave empty to issue none.")
+	viper.BindPFlag("mtls-ca-secret", flags.Lookup("mtls-ca-secret"))
+	viper.BindEnv("mtls-ca-secret", "MTLS_CA_SECRET")
+
+	flags.Duration("mtls-client-certificate-ttl", 90*24*time.Hour, "(MTLS_CLIENT_CERTIFICATE_TTL) Time a client certificate is valid. Then the machine has to be enrolled again.")
+	viper.BindPFlag("mtls-client-certificate-ttl", flags.Lookup("mtls-client-certificate-ttl"))
+	viper.BindEnv("mtls-client-certificate-ttl", "MTLS_CLIENT_CERTIFICATE_TTL")
+
+	flags.String("mtls-url", "", "(MTLS_URL) URL of the mutual TLS port, as reachable by the CLI. Enrolled machines are switched to it.")
+	viper.BindPFlag("mtls-url", flags.Lookup("mtls-url"))
+	viper.BindEnv("mtls-url", "MTLS_URL")
+
+	flags.String("pod-security-level", "restricted", "(POD_SECURITY_LEVEL) Pod Security Standard enforced in the namespaces created by Epinio [privileged,baseline,restricted]. Admins can change it per namespace.")
+	viper.BindPFlag("pod-security-level", flags.Lookup("pod-security-level"))
+	viper.BindEnv("pod-security-level", "POD_SECURITY_LEVEL")
+
+	flags.Bool("network-isolation", false, "(NETWORK_ISOLATION) Deny the traffic between the namespaces created by Epinio with network policies. Traffic within a namespace, and from the allowed namespaces, stays possible.")
+	viper.BindPFlag("network-isolation", flags.Lookup("network-isolation"))
+	viper.BindEnv("network-isolation", "NETWORK_ISOLATION")
+
+	flags.String("network-allowed-namespaces", "kube-system,ingress-nginx,traefik,linkerd", "(NETWORK_ALLOWED_NAMESPACES) Comma-separated namespaces whose pods may reach the applications of isolated namespaces, like those of the ingress controller, and service mesh. Epinio's namespace is always allowed.")
+	viper.BindPFlag("network-allowed-namespaces", flags.Lookup("network-allowed-namespaces"))
+	viper.BindEnv("network-allowed-namespaces", "NETWORK_ALLOWED_NAMESPACES")
+
+	flags.Int("admission-port", 0, "(ADMISSION_PORT) Port to serve the webhook validating the Epinio resources on. Leave empty to not validate them.")
+	viper.BindPFlag("admission-port", flags.Lookup("admission-port"))
+	viper.BindEnv("admission-port", "ADMISSION_PORT")
+
+	flags.String("admission-certificate-secret", "", "(ADMISSION_CERTIFICATE_SECRET) Secret for the TLS certificate of the webhook. Its ca.crt, or else tls.crt, is given to the API server to verify it.")
+	viper.BindPFlag("admission-certificate-secret", flags.Lookup("admission-certificate-secret"))
+	viper.BindEnv("admission-certificate-secret", "ADMISSION_CERTIFICATE_SECRET")
+
+	flags.String("admission-service", "epinio-server", "(ADMISSION_SERVICE) Service of Epinio's namespace the API server reaches the webhook through")
+	viper.BindPFlag("admission-service", flags.Lookup("admission-service"))
+	viper.BindEnv("admission-service", "ADMISSION_SERVICE")
+
+	flags.String("notification-allowed-hosts", "", "(NOTIFICATION_ALLOWED_HOSTS) Comma-separated hosts, addresses, and networks in CIDR notation, the notification targets may reach over plain http, or at internal addresses, like those of the cluster. Others have to be https urls of public hosts. An http proxy in the environment has to be listed as well.")
+	viper.BindPFlag("notification-allowed-hosts", flags.Lookup("notification-allowed-hosts"))
+	viper.BindEnv("notification-allowed-hosts", "NOTIFICATION_ALLOWED_HOSTS")
+
+	flags.String("admission-exempt-users", "system:serviceaccount:epinio:epinio-server", "(ADMISSION_EXEMPT_USERS) Comma-separated kube users whose changes are not validated, usually just Epinio's service account")
+	viper.BindPFlag("admission-exempt-users", flags.Lookup("admission-exempt-users"))
+	viper.BindEnv("admission-exempt-users", "ADMISSION_EXEMPT_USERS")
+
+	flags.Int("metrics-port", 0, "(METRICS_PORT) Port to serve the Prometheus metrics of the server on, at /metrics. Leave empty to not collect them.")
+	viper.BindPFlag("metrics-port", flags.Lookup("metrics-port"))
+	viper.BindEnv("metrics-port", "METRICS_PORT")
+
+	flags.Bool("service-monitor", false, "(SERVICE_MONITOR) Create a service monitor making the Prometheus operator scrape the metrics. The metrics service needs a port named `metrics` for the metrics port.")
+	viper.BindPFlag("service-monitor", flags.Lookup("service-monitor"))
+	viper.BindEnv("service-monitor", "SERVICE_MONITOR")
+
+	flags.String("metrics-service", "epinio-server", "(METRICS_SERVICE) Service of Epinio's namespace the service monitor scrapes the metrics through")
+	viper.BindPFlag("metrics-service", flags.Lookup("metrics-service"))
+	viper.BindEnv("metrics-service", "METRICS_SERVICE")
+
+	flags.Bool("grafana-dashboards", false, "(GRAFANA_DASHBOARDS) Create config maps with Grafana dashboards of the server, the stagings, and the resource usage of the applications, for the dashboard sidecar of an installed kube-prometheus-stack")
+	viper.BindPFlag("grafana-dashboards", flags.Lookup("grafana-dashboards"))
+	viper.BindEnv("grafana-dashboards", "GRAFANA_DASHBOARDS")
+
+	flags.String("dashboards-namespace", "", "(DASHBOARDS_NAMESPACE) Namespace searched by the dashboard sidecar of Grafana, for the dashboards. Defaults to Epinio's namespace.")
+	viper.BindPFlag("dashboards-namespace", flags.Lookup("dashboards-namespace"))
+	viper.BindEnv("dashboards-namespace", "DASHBOARDS_NAMESPACE")
+
+	flags.String("otlp-endpoint", "", "(OTEL_EXPORTER_OTLP_ENDPOINT) Base URL of the OpenTelemetry collector to export the traces of the server to, over OTLP/HTTP, e.g. http://collector:4318. Leave empty to not trace.")
+	viper.BindPFlag("otlp-endpoint", flags.Lookup("otlp-endpoint"))
+	viper.BindEnv("otlp-endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
+
+	flags.String("otlp-headers", "", "(OTEL_EXPORTER_OTLP_HEADERS) Comma-separated headers `KEY=VALUE` of the requests to the OpenTelemetry collector, e.g. for authentication")
+	viper.BindPFlag("otlp-headers", flags.Lookup("otlp-headers"))
+	viper.BindEnv("otlp-headers", "OTEL_EXPORTER_OTLP_HEADERS")
+
+	flags.String("cloudevents-sink", "", "(CLOUDEVENTS_SINK, K_SINK) URL of a CloudEvents sink, e.g. a Knative broker, to emit the platform events to. Leave empty to not emit them.")
+	viper.BindPFlag("cloudevents-sink", flags.Lookup("cloudevents-sink"))
+	viper.BindEnv("cloudevents-sink", "CLOUDEVENTS_SINK", "K_SINK")
+
+	flags.String("cloudevents-source", "/epinio", "(CLOUDEVENTS_SOURCE) Source of the emitted CloudEvents, extended by the namespace of the event")
+	viper.BindPFlag("cloudevents-source", flags.Lookup("cloudevents-source"))
+	viper.BindEnv("cloudevents-source", "CLOUDEVENTS_SOURCE")
+
+	flags.String("api-v1-sunset", "", "(API_V1_SUNSET) Date, YYYY-MM-DD, after which the v1 endpoints deprecated by v2 may be removed, announced in their responses")
+	viper.BindPFlag("api-v1-sunset", flags.Lookup("api-v1-sunset"))
+	viper.BindEnv("api-v1-sunset", "API_V1_SUNSET")
+
+	flags.Duration("shutdown-delay", 5*time.Second, "(SHUTDOWN_DELAY) Time between failing the readiness probe and closing the listeners, on shutdown, for the endpoints of the server to drop it")
+	viper.BindPFlag("shutdown-delay", flags.Lookup("shutdown-delay"))
+	viper.BindEnv("shutdown-delay", "SHUTDOWN_DELAY")
+
+	flags.Duration("shutdown-timeout", 20*time.Second, "(SHUTDOWN_TIMEOUT) Time the requests in flight, e.g. uploads, have to finish on shutdown. The termination grace period of the pod has to cover it and the shutdown delay.")
+	viper.BindPFlag("shutdown-timeout", flags.Lookup("shutdown-timeout"))
+	viper.BindEnv("shutdown-timeout", "SHUTDOWN_TIMEOUT")
 }
 
 // CmdServer implements the command: epinio server
@@ -68,6 +340,16 @@ var CmdServer = &cobra.Command{
 		cmd.SilenceUsage = true
 		logger := tracelog.NewLogger().WithName("EpinioServer")
 
+		if viper.GetInt("metrics-port") > 0 {
+			metrics.RegisterClientMetrics()
+		}
+
+		stopTracing, err := startTracing(logger.WithName("Tracing"))
+		if err != nil {
+			return errors.Wrap(err, "error configuring tracing")
+		}
+		defer stopTracing()
+
 		handler, err := server.NewHandler(logger)
 		if err != nil {
 			return errors.Wrap(err, "error creating handler")
@@ -84,12 +366,340 @@ var CmdServer = &cobra.Command{
 		listeningPort := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
 		ui.Normal().Msg("listening on localhost on port " + listeningPort)
 
-		return startServerGracefully(listener, handler)
+		// Servers of the API, besides the main one, to drain on shutdown.
+		drained := []*http.Server{}
+
+		queueCtx, stopQueue := context.WithCancel(context.Background())
+		defer stopQueue()
+		go application.StagingQueueLoop(queueCtx, logger.WithName("StagingQueue"))
+		go application.StagingEventsLoop(queueCtx, logger.WithName("StagingEvents"))
+		go application.UploadsSweepLoop(queueCtx, logger.WithName("UploadsSweep"))
+		go deploy.AutoSleepLoop(queueCtx, logger.WithName("AutoSleep"))
+		go deploy.CanaryWatchLoop(queueCtx, logger.WithName("CanaryWatch"))
+		go logsink.ForwardLoop(queueCtx, logger.WithName("LogSink"))
+		go auth.GrantsLoop(queueCtx, logger.WithName("GrantsExpiry"))
+		go webhooks.DeliverLoop(queueCtx, logger.WithName("Webhooks"))
+		go notifications.NotifyLoop(queueCtx, logger.WithName("Notifications"))
+		if sink := viper.GetString("cloudevents-sink"); sink != "" {
+			emitter, err := cloudevents.NewEmitter(sink, viper.GetString("cloudevents-source"))
+			if err != nil {
+				return errors.Wrap(err, "error configuring the cloudevents sink")
+			}
+			go cloudevents.EmitLoop(queueCtx, logger.WithName("CloudEvents"), emitter)
+		}
+		if viper.GetBool("rbac-export") {
+			go rbac.ExportLoop(queueCtx, logger.WithName("RBACExport"), helmchart.Namespace())
+		}
+		if interval := viper.GetDuration("credentials-rotation-interval"); interval > 0 {
+			go auth.RotationLoop(queueCtx, logger.WithName("CredentialsRotation"),
+				interval, viper.GetDuration("credentials-grace-period"))
+		}
+
+		if activatorPort := viper.GetInt("activator-port"); activatorPort > 0 {
+			activatorListener, err := net.Listen("tcp", fmt.Sprintf(":%d", activatorPort))
+			if err != nil {
+				return errors.Wrap(err, "error creating activator listener")
+			}
+			activator := &http.Server{
+				Handler: deploy.NewActivator(logger.WithName("Activator")),
+			}
+			defer activator.Close()
+			go func() {
+				if err := activator.Serve(activatorListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error(err, "activator server failed")
+				}
+			}()
+		}
+
+		if mtlsPort := viper.GetInt("mtls-port"); mtlsPort > 0 {
+			config, err := mtlsConfig(cmd.Context())
+			if err != nil {
+				return errors.Wrap(err, "error configuring mutual TLS")
+			}
+			mtlsListener, err := tls.Listen("tcp", fmt.Sprintf(":%d", mtlsPort), config)
+			if err != nil {
+				return errors.Wrap(err, "error creating mutual TLS listener")
+			}
+			mtls := &http.Server{
+				Handler: handler,
+			}
+			defer mtls.Close()
+			drained = append(drained, mtls)
+			go func() {
+				if err := mtls.Serve(mtlsListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error(err, "mtls server failed")
+				}
+			}()
+		}
+
+		if grpcPort := viper.GetInt("grpc-port"); grpcPort > 0 {
+			grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+			if err != nil {
+				return errors.Wrap(err, "error creating gRPC listener")
+			}
+			// The gRPC API is a gateway to the REST API, making its calls to the main port.
+			gateway := rpcserver.NewServer("http://127.0.0.1:"+listeningPort, "ws://127.0.0.1:"+listeningPort)
+			grpcServer := &http.Server{
+				Handler: rpcserver.Handler(gateway),
+			}
+			defer grpcServer.Close()
+			drained = append(drained, grpcServer)
+			go func() {
+				if err := grpcServer.Serve(grpcListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error(err, "grpc server failed")
+				}
+			}()
+		}
+
+		if admissionPort := viper.GetInt("admission-port"); admissionPort > 0 {
+			webhook, config, err := admissionWebhook(cmd.Context(), logger.WithName("Admission"), admissionPort)
+			if err != nil {
+				return errors.Wrap(err, "error configuring the admission webhook")
+			}
+			admissionListener, err := tls.Listen("tcp", fmt.Sprintf(":%d", admissionPort), config)
+			if err != nil {
+				return errors.Wrap(err, "error creating admission webhook listener")
+			}
+			admission := &http.Server{
+				Handler: webhook,
+			}
+			defer admission.Close()
+			go func() {
+				if err := admission.Serve(admissionListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error(err, "admission server failed")
+				}
+			}()
+		}
+
+		if metricsPort := viper.GetInt("metrics-port"); metricsPort > 0 {
+			metricsListener, err := net.Listen("tcp", fmt.Sprintf(":%d", metricsPort))
+			if err != nil {
+				return errors.Wrap(err, "error creating metrics listener")
+			}
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			metricsServer := &http.Server{
+				Handler: mux,
+			}
+			defer metricsServer.Close()
+			go func() {
+				if err := metricsServer.Serve(metricsListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error(err, "metrics server failed")
+				}
+			}()
+
+			if viper.GetBool("service-monitor") {
+				if err := registerServiceMonitor(cmd.Context()); err != nil {
+					return errors.Wrap(err, "error registering the service monitor")
+				}
+			}
+		}
+
+		if viper.GetBool("grafana-dashboards") {
+			if err := registerDashboards(cmd.Context()); err != nil {
+				return errors.Wrap(err, "error registering the grafana dashboards")
+			}
+		}
+
+		return startServerGracefully(listener, handler, drained...)
 	},
 }
 
-// startServerGracefully will start the server and will wait for a graceful shutdown
-func startServerGracefully(listener net.Listener, handler http.Handler) error {
+// startTracing exports the traces of the server to the configured collector, if any. The
+// returned function stops the export, after sending the last spans.
+func startTracing(logger logr.Logger) (func(), error) {
+	endpoint := viper.GetString("otlp-endpoint")
+	if endpoint == "" {
+		return func() {}, nil
+	}
+
+	headers, err := tracing.ParseHeaders(viper.GetString("otlp-headers"))
+	if err != nil {
+		return nil, err
+	}
+
+	shutdown, err := tracing.Setup(context.Background(), logger, endpoint, "epinio-server", headers)
+	if err != nil {
+		return nil, err
+	}
+	kubernetes.WrapTransport = tracing.Transport
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.Error(err, "exporting the last spans failed")
+		}
+	}, nil
+}
+
+// registerServiceMonitor makes the Prometheus operator scrape the metrics of the server.
+func registerServiceMonitor(ctx context.Context) error {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(cluster.RestConfig)
+	if err != nil {
+		return err
+	}
+	return metrics.RegisterServiceMonitor(ctx, cluster.Kubectl, client, helmchart.Namespace(), viper.GetString("metrics-service"))
+}
+
+// registerDashboards makes the dashboard sidecar of Grafana load the dashboards of Epinio.
+func registerDashboards(ctx context.Context) error {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return err
+	}
+	namespace := viper.GetString("dashboards-namespace")
+	if namespace == "" {
+		namespace = helmchart.Namespace()
+	}
+	return metrics.RegisterDashboards(ctx, cluster.Kubectl, namespace)
+}
+
+// admissionWebhook returns the handler, and TLS configuration, of the webhook validating the
+// Epinio resources, and registers the webhook with the API server.
+func admissionWebhook(ctx context.Context, logger logr.Logger, port int) (http.Handler, *tls.Config, error) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := cluster.GetSecret(ctx, helmchart.Namespace(), viper.GetString("admission-certificate-secret"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting the certificate of the admission webhook")
+	}
+	certificate, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "bad certificate of the admission webhook")
+	}
+	caBundle := secret.Data["ca.crt"]
+	if len(caBundle) == 0 {
+		caBundle = secret.Data["tls.crt"]
+	}
+
+	client, err := dynamic.NewForConfig(cluster.RestConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exemptUsers := []string{}
+	for _, user := range strings.Split(viper.GetString("admission-exempt-users"), ",") {
+		if user = strings.TrimSpace(user); user != "" {
+			exemptUsers = append(exemptUsers, user)
+		}
+	}
+
+	err = admission.Register(ctx, cluster, helmchart.Namespace(),
+		viper.GetString("admission-service"), int32(port), caBundle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validator := &admission.Validator{
+		Client:      client,
+		Namespace:   helmchart.Namespace(),
+		ExemptUsers: exemptUsers,
+	}
+
+	return admission.NewHandler(logger, validator), &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}, nil
+}
+
+// CmdServerRotateCredentials implements the command: epinio server rotate-credentials
+var CmdServerRotateCredentials = &cobra.Command{
+	Use:   "rotate-credentials [USERNAME...]",
+	Short: "Rotates the passwords of the API users",
+	Long:  "Gives the named users, or all users, new passwords, found in their secrets. The previous passwords stay valid for the grace period, so that running clients can switch over. Uses the current kube cluster, not the API server.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		grace, err := cmd.Flags().GetDuration("grace-period")
+		if err != nil {
+			return errors.Wrap(err, "could not read option --grace-period")
+		}
+
+		client, err := admincmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.RotateCredentials(cmd.Context(), args, grace)
+		if err != nil {
+			return errors.Wrap(err, "error rotating the credentials")
+		}
+
+		return nil
+	},
+}
+
+// CmdServerExportRBAC implements the command: epinio server export-rbac
+var CmdServerExportRBAC = &cobra.Command{
+	Use:   "export-rbac",
+	Short: "Mirrors the permissions of the users as kube RBAC",
+	Long:  "Creates a service account for every user, bound in each of their namespaces to the view, edit, or admin cluster role, matching their role there. Removes those of removed users. Uses the current kube cluster, not the API server. The server keeps them up to date with --rbac-export.",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := admincmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.ExportRBAC(cmd.Context())
+		if err != nil {
+			return errors.Wrap(err, "error exporting the permissions")
+		}
+
+		return nil
+	},
+}
+
+// mtlsConfig returns the TLS configuration of the mutual TLS port. Clients have to present a
+// certificate issued by the client CA.
+func mtlsConfig(ctx context.Context) (*tls.Config, error) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := cluster.GetSecret(ctx, helmchart.Namespace(), viper.GetString("mtls-certificate-secret"))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the certificate of the mutual TLS port")
+	}
+	certificate, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+	if err != nil {
+		return nil, errors.Wrap(err, "bad certificate of the mutual TLS port")
+	}
+
+	secret, err = cluster.GetSecret(ctx, helmchart.Namespace(), viper.GetString("mtls-ca-secret"))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the client CA")
+	}
+	ca, err := auth.NewClientCA(secret.Data["tls.crt"], secret.Data["tls.key"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.Pool(),
+	}, nil
+}
+
+// startServerGracefully serves the API until SIGINT or SIGTERM. Then the readiness probe
+// fails, and, after the shutdown delay, the listeners are closed and the requests in flight,
+// e.g. uploads, get the shutdown timeout to finish. Uploads in parts severed nevertheless are
+// resumed by the client, from the part which failed, against another server. Stagings run
+// as jobs, and are not affected. The other servers of the API are drained the same way.
+func startServerGracefully(listener net.Listener, handler http.Handler, others ...*http.Server) error {
 	srv := &http.Server{
 		Handler: handler,
 	}
@@ -116,10 +726,13 @@ func startServerGracefully(listener net.Listener, handler http.Handler) error {
 
 	log.Println("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	health.Drain()
+	time.Sleep(viper.GetDuration("shutdown-delay"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("shutdown-timeout"))
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := shutdownServers(ctx, append(others, srv)); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 		return err
 	}
@@ -127,3 +740,22 @@ func startServerGracefully(listener net.Listener, handler http.Handler) error {
 	log.Println("Server exiting")
 	return nil
 }
+
+// shutdownServers shuts the servers down, concurrently, waiting for their requests in flight
+// to finish, until the context is done.
+func shutdownServers(ctx context.Context, servers []*http.Server) error {
+	errs := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			errs <- srv.Shutdown(ctx)
+		}(srv)
+	}
+
+	var result error
+	for range servers {
+		if err := <-errs; err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
@@ -15,6 +15,15 @@ type IDKey struct{}
 // UserKey is the unique key to lookup the username from the request's context
 type UserKey struct{}
 
+// APITokenKey is the unique key to lookup the id of the API token used by the request
+type APITokenKey struct{}
+
+// APITokenScopeKey is the unique key to lookup the scope of the API token used by the request
+type APITokenScopeKey struct{}
+
+// SessionIDKey is the unique key to lookup the id of the session used by the request
+type SessionIDKey struct{}
+
 // LoggerKey is the unique key to lookup the logger from the request's context
 type LoggerKey struct{}
 
@@ -32,6 +41,49 @@ func User(ctx context.Context) auth.User {
 	return user
 }
 
+// WithAPIToken adds the id of the API token the request authenticated with to the context
+func WithAPIToken(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, APITokenKey{}, id)
+}
+
+// APIToken returns the id of the API token the request authenticated with, if any
+func APIToken(ctx context.Context) string {
+	id, ok := ctx.Value(APITokenKey{}).(string)
+	if !ok {
+		return ""
+	}
+	return id
+}
+
+// WithAPITokenScope adds the scope of the API token the request authenticated with to the
+// context
+func WithAPITokenScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, APITokenScopeKey{}, scope)
+}
+
+// APITokenScope returns the scope of the API token the request authenticated with, if any
+func APITokenScope(ctx context.Context) string {
+	scope, ok := ctx.Value(APITokenScopeKey{}).(string)
+	if !ok {
+		return ""
+	}
+	return scope
+}
+
+// WithSessionID adds the id of the session the request authenticated with to the context
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, SessionIDKey{}, id)
+}
+
+// SessionID returns the id of the session the request authenticated with, if any
+func SessionID(ctx context.Context) string {
+	id, ok := ctx.Value(SessionIDKey{}).(string)
+	if !ok {
+		return ""
+	}
+	return id
+}
+
 // WithID adds the request ID to the context
 func WithID(ctx context.Context, val string) context.Context {
 	return context.WithValue(ctx, IDKey{}, val)
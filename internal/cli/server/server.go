@@ -13,8 +13,16 @@ import (
 	"github.com/epinio/epinio/helpers/authtoken"
 	apiv1 "github.com/epinio/epinio/internal/api/v1"
 	"github.com/epinio/epinio/internal/api/v1/response"
+	apiv2 "github.com/epinio/epinio/internal/api/v2"
+	"github.com/epinio/epinio/internal/audit"
 	"github.com/epinio/epinio/internal/auth"
 	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/health"
+	"github.com/epinio/epinio/internal/idempotency"
+	"github.com/epinio/epinio/internal/metrics"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/internal/ratelimit"
+	"github.com/epinio/epinio/internal/tracing"
 	apierrors "github.com/epinio/epinio/pkg/api/core/v1/errors"
 
 	"github.com/alron/ginlogr"
@@ -28,6 +36,17 @@ import (
 	"github.com/spf13/viper"
 )
 
+// oidcVerifier validates the ID tokens of the OIDC issuer, nil when single sign-on is not
+// configured.
+var oidcVerifier *auth.OIDCVerifier
+
+// ldapAuthenticator checks the passwords of users with the LDAP server, nil when LDAP is not
+// configured.
+var ldapAuthenticator *auth.LDAPAuthenticator
+
+// ldapAuthenticatedKey marks the requests of users authenticated with LDAP.
+const ldapAuthenticatedKey = "ldap-authenticated"
+
 // NewHandler creates and setup the gin router
 func NewHandler(logger logr.Logger) (*gin.Engine, error) {
 	// Support colors on Windows also
@@ -39,7 +58,11 @@ func NewHandler(logger logr.Logger) (*gin.Engine, error) {
 	// | Path              | Notes      | Logging
 	// | ---               | ---        | ----
 	// | <Root>/...        | API        | Via "<Root>" Group
+	// | /api/v2/...       | API v2     | Via "/api/v2" Group
+	// | /api/versions     | Discovery  | Yes
 	// | /ready            | L/R Probes |
+	// | /healthz          | L Probe    |
+	// | /readyz           | R Probe    |
 	// | /namespaces/target/:namespace | ditto      | ditto
 
 	router := gin.New()
@@ -74,6 +97,71 @@ func NewHandler(logger logr.Logger) (*gin.Engine, error) {
 		return nil, errors.New("SESSION_KEY environment variable not defined")
 	}
 
+	oidcVerifier = nil
+	if issuer := viper.GetString("oidc-issuer"); issuer != "" {
+		groupRoles, err := auth.ParseGroupRoles(viper.GetString("oidc-group-roles"))
+		if err != nil {
+			return nil, err
+		}
+		oidcVerifier = auth.NewOIDCVerifier(issuer, viper.GetString("oidc-client-id"))
+		oidcVerifier.UsernameClaim = viper.GetString("oidc-username-claim")
+		oidcVerifier.GroupsClaim = viper.GetString("oidc-groups-claim")
+		oidcVerifier.GroupRoles = groupRoles
+	}
+
+	ldapAuthenticator = nil
+	if url := viper.GetString("ldap-url"); url != "" {
+		groupRoles, err := auth.ParseGroupRoles(viper.GetString("ldap-group-roles"))
+		if err != nil {
+			return nil, err
+		}
+		ldapAuthenticator = auth.NewLDAPAuthenticator(url, viper.GetString("ldap-user-base-dn"))
+		ldapAuthenticator.BindDN = viper.GetString("ldap-bind-dn")
+		ldapAuthenticator.BindPassword = viper.GetString("ldap-bind-password")
+		ldapAuthenticator.StartTLS = viper.GetBool("ldap-start-tls")
+		ldapAuthenticator.CACert = viper.GetString("ldap-ca-cert")
+		ldapAuthenticator.AllowPlain = viper.GetBool("ldap-insecure")
+		ldapAuthenticator.UserFilter = viper.GetString("ldap-user-filter")
+		ldapAuthenticator.GroupBaseDN = viper.GetString("ldap-group-base-dn")
+		ldapAuthenticator.GroupFilter = viper.GetString("ldap-group-filter")
+		ldapAuthenticator.GroupNameAttribute = viper.GetString("ldap-group-name-attribute")
+		ldapAuthenticator.GroupRoles = groupRoles
+		if err := ldapAuthenticator.Validate(); err != nil {
+			return nil, err
+		}
+		if ldapAuthenticator.Plain() {
+			logger.Info("WARNING: the LDAP server is reached without TLS, the passwords of its users are sent in the clear", "url", url)
+		}
+	}
+
+	if level := viper.GetString("pod-security-level"); level != "" {
+		if err := namespaces.ValidatePodSecurity(level); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit := viper.GetString("upload-limit"); limit != "" {
+		if _, err := namespaces.ParseUploadLimit(limit); err != nil {
+			return nil, err
+		}
+	}
+
+	auditSink, err := audit.NewSink(viper.GetString("audit-sink"), viper.GetString("audit-sink-target"))
+	if err != nil {
+		return nil, err
+	}
+	audit.Default = audit.NewLog(auditSink, audit.RecentSize)
+
+	limiter, err := newRateLimiter()
+	if err != nil {
+		return nil, err
+	}
+
+	sunset, err := apiV1Sunset()
+	if err != nil {
+		return nil, err
+	}
+
 	store := cookie.NewStore([]byte(os.Getenv("SESSION_KEY")))
 	store.Options(sessions.Options{MaxAge: 60 * 60 * 24}) // expire in a day
 	gob.Register(auth.User{})
@@ -87,6 +175,10 @@ func NewHandler(logger logr.Logger) (*gin.Engine, error) {
 		c.JSON(http.StatusOK, gin.H{})
 	})
 
+	// Deep healthchecks, reporting the status of the dependencies of the server.
+	router.GET("/healthz", health.Handler(health.LivenessChecks))
+	router.GET("/readyz", health.Handler(health.ReadinessChecks))
+
 	// add common middlewares to all the routes
 	router.Use(
 		sessions.Sessions("epinio-session", store),
@@ -94,16 +186,41 @@ func NewHandler(logger logr.Logger) (*gin.Engine, error) {
 		ginRecoveryLogger,
 		initContextMiddleware(logger),
 	)
+	if viper.GetInt("metrics-port") > 0 {
+		router.Use(metrics.Middleware())
+	}
+	if tracing.Enabled() {
+		router.Use(tracing.Middleware())
+	}
+
+	// Register public api routes, without authentication
+	{
+		publicRoutesGroup := router.Group(apiv1.Root)
+		apiv1.Salt(publicRoutesGroup)
+		router.GET(apiv2.VersionsPath, apiv2.Versions(sunset))
+	}
 
 	// Register api routes
 	{
-		apiRoutesGroup := router.Group(apiv1.Root, authMiddleware, sessionMiddleware, apiv1.AuthorizationMiddleware)
+		middlewares := []gin.HandlerFunc{authMiddleware, clientCertificateMiddleware, sessionMiddleware}
+		if limiter != nil {
+			middlewares = append(middlewares, ratelimit.Middleware(limiter))
+		}
+		middlewares = append(middlewares, audit.Middleware(audit.Default), apiv1.AuthorizationMiddleware)
+		if ttl := viper.GetDuration("idempotency-ttl"); ttl > 0 {
+			middlewares = append(middlewares, idempotency.Middleware(idempotency.NewStore(ttl), apiv1.IdempotentRoutes))
+		}
+
+		apiRoutesGroup := router.Group(apiv1.Root, append([]gin.HandlerFunc{apiv2.DeprecationMiddleware(sunset)}, middlewares...)...)
 		apiv1.Lemon(apiRoutesGroup)
+
+		apiv2RoutesGroup := router.Group(apiv2.Root, middlewares...)
+		apiv2.Lemon(apiv2RoutesGroup)
 	}
 
 	// Register web socket routes
 	{
-		wapiRoutesGroup := router.Group(apiv1.WsRoot, tokenAuthMiddleware, apiv1.AuthorizationMiddleware)
+		wapiRoutesGroup := router.Group(apiv1.WsRoot, tokenAuthMiddleware, clientCertificateMiddleware, apiv1.AuthorizationMiddleware)
 		apiv1.Spice(wapiRoutesGroup)
 	}
 
@@ -117,6 +234,45 @@ func NewHandler(logger logr.Logger) (*gin.Engine, error) {
 	return router, nil
 }
 
+// newRateLimiter returns the limiter of the API requests per identity, nil without rate
+// limit. The overrides name the routes of the API.
+func newRateLimiter() (*ratelimit.Limiter, error) {
+	perSecond := viper.GetFloat64("rate-limit")
+	if perSecond <= 0 {
+		return nil, nil
+	}
+	burst := viper.GetInt("rate-limit-burst")
+
+	named, err := ratelimit.ParseOverrides(viper.GetString("rate-limit-overrides"), burst)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]ratelimit.Limit{}
+	for name, limit := range named {
+		route, ok := apiv1.Routes[name]
+		if !ok {
+			return nil, fmt.Errorf("bad rate limit override, unknown route '%s'", name)
+		}
+		overrides[route.Method+" "+apiv1.Root+route.Path] = limit
+	}
+
+	return ratelimit.New(ratelimit.Limit{Rate: perSecond, Burst: burst}, overrides), nil
+}
+
+// apiV1Sunset returns the date the deprecated v1 endpoints are removed, zero if not set.
+func apiV1Sunset() (time.Time, error) {
+	value := viper.GetString("api-v1-sunset")
+	if value == "" {
+		return time.Time{}, nil
+	}
+	sunset, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad api v1 sunset '%s', expected a date YYYY-MM-DD", value)
+	}
+	return sunset, nil
+}
+
 // initContextMiddleware initialize the Request Context injecting the logger and the requestID
 func initContextMiddleware(logger logr.Logger) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -132,7 +288,9 @@ func initContextMiddleware(logger logr.Logger) gin.HandlerFunc {
 }
 
 // authMiddleware authenticates the user either using the session or if one
-// doesn't exist, it authenticates with basic auth.
+// doesn't exist, it authenticates with basic auth. Requests can also carry an API
+// token, a session token, or, with single sign-on configured, an ID token of the
+// OIDC issuer, as bearer token.
 func authMiddleware(ctx *gin.Context) {
 	reqCtx := ctx.Request.Context()
 	logger := requestctx.Logger(reqCtx).WithName("AuthMiddleware")
@@ -152,6 +310,41 @@ func authMiddleware(ctx *gin.Context) {
 		return
 	}
 
+	// and the teams giving them access to more namespaces
+	teams, err := authService.GetTeams(ctx)
+	if err != nil {
+		response.Error(ctx, apierrors.InternalError(err))
+		ctx.Abort()
+		return
+	}
+
+	if bearer := ctx.Request.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		token := strings.TrimPrefix(bearer, "Bearer ")
+		switch {
+		case auth.IsAPIToken(token):
+			logger.V(1).Info("API token authentication")
+			apiTokenAuthentication(ctx, logger, authService, users, teams, token)
+			return
+		case auth.IsSessionToken(token):
+			logger.V(1).Info("Session token authentication")
+			sessionTokenAuthentication(ctx, logger, authService, users, teams, token)
+			return
+		case oidcVerifier != nil:
+			logger.V(1).Info("OIDC authentication")
+			oidcAuthentication(ctx, logger, users, teams, token)
+			return
+		}
+	}
+
+	// Users who are not Epinio users, or give another password, are checked with LDAP.
+	if ldapAuthenticator != nil && sessions.Default(ctx).Get("user") == nil {
+		if username, password, ok := ctx.Request.BasicAuth(); ok && !isAccount(users, username, password) {
+			logger.V(1).Info("LDAP authentication")
+			ldapAuthentication(ctx, logger, users, teams, username, password)
+			return
+		}
+	}
+
 	if len(users) == 0 {
 		response.Error(ctx, apierrors.NewAPIError("no user found", "", http.StatusUnauthorized))
 		ctx.Abort()
@@ -181,8 +374,9 @@ func authMiddleware(ctx *gin.Context) {
 			}
 		}
 
-		// Perform basic auth authentication
-		gin.BasicAuth(accounts)(ctx)
+		// Perform basic auth authentication. The previous password of a user stays valid
+		// for the grace period of its rotation.
+		basicAuthentication(ctx, users)
 	} else {
 		logger.V(1).Info("Session authentication")
 		var ok bool
@@ -199,18 +393,18 @@ func authMiddleware(ctx *gin.Context) {
 		_, found := accounts[user.Username]
 
 		if !found {
-			session.Clear()
-			session.Options(sessions.Options{MaxAge: -1})
+			expireSession(ctx, session, "User no longer exists. Session expired.")
+			return
+		}
 
-			if err := session.Save(); err != nil {
-				response.Error(ctx, apierrors.NewInternalError("Couldn't save the session"))
-				ctx.Abort()
+		// Sessions issued before the last change of the password are not valid anymore.
+		// Sessions without issue time predate the check, and are treated the same.
+		issued, _ := session.Get(auth.CookieSessionIssuedKey).(int64)
+		for _, current := range users {
+			if current.Username == username && !current.IsSessionCurrent(time.Unix(issued, 0)) {
+				expireSession(ctx, session, "Password changed. Session expired.")
 				return
 			}
-
-			response.Error(ctx, apierrors.NewAPIError("User no longer exists. Session expired.", "", http.StatusUnauthorized))
-			ctx.Abort()
-			return
 		}
 	}
 
@@ -219,7 +413,7 @@ func authMiddleware(ctx *gin.Context) {
 	for _, user := range users {
 		if user.Username == username {
 			newCtx := ctx.Request.Context()
-			newCtx = requestctx.WithUser(newCtx, user)
+			newCtx = requestctx.WithUser(newCtx, auth.WithTeams(user, teams, nil))
 			ctx.Request = ctx.Request.Clone(newCtx)
 
 			break
@@ -227,15 +421,237 @@ func authMiddleware(ctx *gin.Context) {
 	}
 }
 
+// expireSession removes the cookie session, and refuses the request with the message.
+func expireSession(ctx *gin.Context, session sessions.Session, message string) {
+	session.Clear()
+	session.Options(sessions.Options{MaxAge: -1})
+
+	if err := session.Save(); err != nil {
+		response.Error(ctx, apierrors.NewInternalError("Couldn't save the session"))
+		ctx.Abort()
+		return
+	}
+
+	response.Error(ctx, apierrors.NewAPIError(message, "", http.StatusUnauthorized))
+	ctx.Abort()
+}
+
+// clientCertificateMiddleware refuses the requests coming in on the mutual TLS port with the
+// client certificate of another user. The certificate locks the access of the user to the
+// enrolled machine, it does not replace the authentication.
+func clientCertificateMiddleware(ctx *gin.Context) {
+	if ctx.Request.TLS == nil {
+		return
+	}
+
+	owner := auth.ClientCertificateUser(ctx.Request.TLS)
+	user := requestctx.User(ctx.Request.Context())
+	if owner == "" || owner != user.Username {
+		response.Error(ctx, apierrors.NewAPIError("client certificate issued to another user", "", http.StatusForbidden))
+		ctx.Abort()
+	}
+}
+
+// apiTokenAuthentication authenticates the user with the API token. The user keeps their role
+// and namespaces, including those of their teams, unless the token is limited to some namespaces. Then the user has access to
+// these namespaces only, as if they were a "user". Read-only tokens are refused for requests
+// changing anything.
+func apiTokenAuthentication(ctx *gin.Context, logger logr.Logger, authService *auth.AuthService, users []auth.User, teams []auth.Team, value string) {
+	token, err := authService.ValidateAPIToken(ctx.Request.Context(), value)
+	if err != nil {
+		if err != auth.ErrAPITokenInvalid {
+			response.Error(ctx, apierrors.InternalError(err))
+		} else {
+			response.Error(ctx, apierrors.NewAPIError("invalid token", "", http.StatusUnauthorized))
+		}
+		ctx.Abort()
+		return
+	}
+
+	var user auth.User
+	found := false
+	for _, u := range users {
+		if u.Username == token.Username {
+			user, found = u, true
+			break
+		}
+	}
+	if !found {
+		logger.V(2).Info("token of unknown user", "token", token.ID, "user", token.Username)
+		response.Error(ctx, apierrors.NewAPIError("invalid token", "", http.StatusUnauthorized))
+		ctx.Abort()
+		return
+	}
+	user = auth.WithTeams(user, teams, nil)
+
+	if len(token.Namespaces) > 0 {
+		namespaces := []string{}
+		roles := map[string]string{}
+		for _, namespace := range token.Namespaces {
+			role := user.NamespaceRole(namespace)
+			if user.Role == "admin" {
+				role = auth.NamespaceRoleAdmin
+			}
+			if role != "" {
+				namespaces = append(namespaces, namespace)
+				roles[namespace] = role
+			}
+		}
+		user.Role = "user"
+		user.Namespaces = namespaces
+		user.NamespaceRoles = roles
+	}
+
+	if token.Scope == auth.APITokenScopeRead && ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+		response.Error(ctx, apierrors.NewAPIError("token is read-only", "", http.StatusForbidden))
+		ctx.Abort()
+		return
+	}
+
+	newCtx := requestctx.WithUser(ctx.Request.Context(), user)
+	newCtx = requestctx.WithAPIToken(newCtx, token.ID)
+	newCtx = requestctx.WithAPITokenScope(newCtx, token.Scope)
+	ctx.Request = ctx.Request.Clone(newCtx)
+}
+
+// sessionTokenAuthentication authenticates the user with the token of their session. Users
+// removed since they logged in are refused.
+func sessionTokenAuthentication(ctx *gin.Context, logger logr.Logger, authService *auth.AuthService, users []auth.User, teams []auth.Team, token string) {
+	session, err := authService.ValidateSession(ctx.Request.Context(), token)
+	if err != nil {
+		if err != auth.ErrSessionInvalid {
+			response.Error(ctx, apierrors.InternalError(err))
+		} else {
+			response.Error(ctx, apierrors.NewAPIError("session expired, please log in again", "", http.StatusUnauthorized))
+		}
+		ctx.Abort()
+		return
+	}
+
+	for _, user := range users {
+		if user.Username == session.Username {
+			newCtx := requestctx.WithUser(ctx.Request.Context(), auth.WithTeams(user, teams, nil))
+			newCtx = requestctx.WithSessionID(newCtx, session.ID)
+			ctx.Request = ctx.Request.Clone(newCtx)
+			return
+		}
+	}
+
+	logger.V(2).Info("session of unknown user", "session", session.ID, "user", session.Username)
+	response.Error(ctx, apierrors.NewAPIError("User no longer exists. Session expired.", "", http.StatusUnauthorized))
+	ctx.Abort()
+}
+
+// isAccount returns true if the user and password are the ones of an Epinio user.
+func isAccount(users []auth.User, username, password string) bool {
+	for _, user := range users {
+		if user.Username == username {
+			return user.IsPassword(password, time.Now())
+		}
+	}
+	return false
+}
+
+// basicAuthentication authenticates the user with their password, and refuses the request
+// otherwise, like gin.BasicAuth.
+func basicAuthentication(ctx *gin.Context, users []auth.User) {
+	username, password, ok := ctx.Request.BasicAuth()
+	if !ok || !isAccount(users, username, password) {
+		ctx.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	ctx.Set(gin.AuthUserKey, username)
+}
+
+// ldapAuthentication authenticates the user with their password at the LDAP server. The user
+// gets the role of the federated Epinio user of the same name, if any, overridden by the role
+// of their LDAP groups. Users with neither get the "user" role. The namespaces are those of
+// the Epinio user, and of the teams the user, or one of their groups, is a member of. Users
+// named like an Epinio user which is not federated are refused, see auth.ExternalUser.
+func ldapAuthentication(ctx *gin.Context, logger logr.Logger, users []auth.User, teams []auth.Team, username, password string) {
+	identity, err := ldapAuthenticator.Authenticate(ctx.Request.Context(), username, password)
+	if err == auth.ErrLDAPInvalidCredentials {
+		ctx.Header("WWW-Authenticate", "Basic realm=\"Authorization Required\"")
+		response.Error(ctx, apierrors.NewAPIError("invalid credentials", "", http.StatusUnauthorized))
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		logger.Error(err, "ldap authentication failed", "user", username)
+		response.Error(ctx, apierrors.InternalError(err))
+		ctx.Abort()
+		return
+	}
+
+	user, err := auth.ExternalUser(users, identity.Username)
+	if err != nil {
+		logger.V(2).Info("external user rejected", "user", identity.Username, "error", err.Error())
+		response.Error(ctx, apierrors.NewAPIError("user name taken by an Epinio user", "", http.StatusForbidden))
+		ctx.Abort()
+		return
+	}
+	if role := ldapAuthenticator.Role(identity.Groups); role != "" {
+		user.Role = role
+	}
+	user = auth.WithTeams(user, teams, identity.Groups)
+
+	newCtx := requestctx.WithUser(ctx.Request.Context(), user)
+	ctx.Request = ctx.Request.Clone(newCtx)
+	ctx.Set(ldapAuthenticatedKey, true)
+}
+
+// oidcAuthentication authenticates the user with the ID token. The user gets the role of the
+// federated Epinio user of the same name, if any, overridden by the role of their groups.
+// Users with neither get the "user" role. The namespaces are those of the Epinio user, and of
+// the teams the user, or one of their groups, is a member of. Users named like an Epinio user
+// which is not federated are refused, see auth.ExternalUser.
+func oidcAuthentication(ctx *gin.Context, logger logr.Logger, users []auth.User, teams []auth.Team, token string) {
+	identity, err := oidcVerifier.Verify(ctx.Request.Context(), token)
+	if err != nil {
+		// detailed log message, not too specific message for the client
+		logger.V(2).Info("token rejected", "error", err.Error())
+		response.Error(ctx, apierrors.NewAPIError("invalid token", "", http.StatusUnauthorized))
+		ctx.Abort()
+		return
+	}
+
+	user, err := auth.ExternalUser(users, identity.Username)
+	if err != nil {
+		logger.V(2).Info("external user rejected", "user", identity.Username, "error", err.Error())
+		response.Error(ctx, apierrors.NewAPIError("user name taken by an Epinio user", "", http.StatusForbidden))
+		ctx.Abort()
+		return
+	}
+	if role := oidcVerifier.Role(identity.Groups); role != "" {
+		user.Role = role
+	}
+	user = auth.WithTeams(user, teams, identity.Groups)
+
+	newCtx := requestctx.WithUser(ctx.Request.Context(), user)
+	ctx.Request = ctx.Request.Clone(newCtx)
+}
+
 // sessionMiddleware creates a new session for a logged in user.
 // This middleware is not called when authentication fails. That's because
 // the authMiddleware calls "ctx.Abort()" in that case.
 // We only set the user in session upon successful authentication
 // (either basic auth or cookie based).
 func sessionMiddleware(ctx *gin.Context) {
+	// Bearer tokens are sent with every request, a session is not needed.
+	if strings.HasPrefix(ctx.Request.Header.Get("Authorization"), "Bearer ") {
+		return
+	}
+
 	session := sessions.Default(ctx)
 	requestContext := ctx.Request.Context()
 
+	// Users authenticated with LDAP are checked at every request, a session would skip the
+	// check of their password, and groups.
+	if ctx.GetBool(ldapAuthenticatedKey) {
+		return
+	}
+
 	user := requestctx.User(requestContext)
 	if user.Username == "" { // This can't be, authentication has succeeded.
 		response.Error(ctx, apierrors.NewInternalError("Couldn't set user in session after successful authentication. This can't happen."))
@@ -247,8 +663,10 @@ func sessionMiddleware(ctx *gin.Context) {
 
 		// remove the Password from the user saved in session (just in case)
 		user.Password = ""
+		user.PreviousPassword = ""
 
 		session.Set("user", user)
+		session.Set(auth.CookieSessionIssuedKey, time.Now().Unix())
 		session.Options(sessions.Options{
 			MaxAge:   172800, // Expire session every 2 days
 			Secure:   true,
@@ -309,11 +727,34 @@ func tokenAuthMiddleware(ctx *gin.Context) {
 
 	for _, user := range users {
 		if user.Username == claims.Username {
+			// The access of the user may be different for the request which got the token.
+			if claims.Role != "" {
+				user.Role = claims.Role
+				user.Namespaces = claims.Namespaces
+				user.NamespaceRoles = claims.NamespaceRoles
+			}
+
 			newCtx := ctx.Request.Context()
 			newCtx = requestctx.WithUser(newCtx, user)
+			newCtx = requestctx.WithAPITokenScope(newCtx, claims.Scope)
 			ctx.Request = ctx.Request.Clone(newCtx)
 
-			break
+			return
 		}
 	}
+
+	// Users authenticated by the OIDC issuer, or LDAP, need no Epinio user, their access is
+	// in the token.
+	if (oidcVerifier != nil || ldapAuthenticator != nil) && claims.Role != "" {
+		user := auth.User{
+			Username:       claims.Username,
+			Role:           claims.Role,
+			Namespaces:     claims.Namespaces,
+			NamespaceRoles: claims.NamespaceRoles,
+		}
+
+		newCtx := requestctx.WithUser(ctx.Request.Context(), user)
+		newCtx = requestctx.WithAPITokenScope(newCtx, claims.Scope)
+		ctx.Request = ctx.Request.Clone(newCtx)
+	}
 }
@@ -20,6 +20,8 @@ var CmdServices = &cobra.Command{
 
 func init() {
 	CmdServiceDelete.Flags().Bool("unbind", false, "Unbind from applications before deleting")
+	CmdServiceDelete.Flags().Bool("all", false, "delete all services of the namespace")
+	CmdServiceCreate.Flags().Bool("wait", false, "Wait for the service to be provisioned")
 	CmdServices.AddCommand(CmdServiceCatalog)
 	CmdServices.AddCommand(CmdServiceCreate)
 	CmdServices.AddCommand(CmdServiceBindCreate)
@@ -30,9 +32,10 @@ func init() {
 }
 
 var CmdServiceCatalog = &cobra.Command{
-	Use:   "catalog [NAME]",
-	Short: "Lists all available Epinio catalog services, or show the details of the specified one",
-	Args:  cobra.MaximumNArgs(1),
+	Use:               "catalog [NAME]",
+	Short:             "Lists all available Epinio catalog services, or show the details of the specified one",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: matchingCatalogFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
@@ -57,9 +60,10 @@ var CmdServiceCatalog = &cobra.Command{
 }
 
 var CmdServiceCreate = &cobra.Command{
-	Use:   "create CATALOGSERVICENAME SERVICENAME",
-	Short: "Create a service SERVICENAME of an Epinio catalog service CATALOGSERVICENAME",
-	Args:  cobra.ExactArgs(2),
+	Use:               "create CATALOGSERVICENAME SERVICENAME",
+	Short:             "Create a service SERVICENAME of an Epinio catalog service CATALOGSERVICENAME",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingCatalogFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
@@ -68,18 +72,24 @@ var CmdServiceCreate = &cobra.Command{
 			return errors.Wrap(err, "error initializing cli")
 		}
 
+		wait, err := cmd.Flags().GetBool("wait")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --wait")
+		}
+
 		catalogServiceName := args[0]
 		serviceName := args[1]
 
-		err = client.ServiceCreate(catalogServiceName, serviceName)
+		err = client.ServiceCreate(catalogServiceName, serviceName, wait)
 		return errors.Wrap(err, "error creating service")
 	},
 }
 
 var CmdServiceShow = &cobra.Command{
-	Use:   "show SERVICENAME",
-	Short: "Show details of a service SERVICENAME",
-	Args:  cobra.ExactArgs(1),
+	Use:               "show SERVICENAME",
+	Short:             "Show details of a service SERVICENAME",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingServiceFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
@@ -96,10 +106,16 @@ var CmdServiceShow = &cobra.Command{
 }
 
 var CmdServiceDelete = &cobra.Command{
-	Use:   "delete SERVICENAME",
-	Short: "Delete service SERVICENAME",
-	Args:  cobra.ExactArgs(1),
+	Use:               "delete SERVICENAME... | --all",
+	Short:             "Delete services SERVICENAME..., or all services",
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: matchingServiceListFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		names, all, err := batchTargets(cmd, args)
+		if err != nil {
+			return err
+		}
+
 		cmd.SilenceUsage = true
 
 		unbind, err := cmd.Flags().GetBool("unbind")
@@ -112,16 +128,19 @@ var CmdServiceDelete = &cobra.Command{
 			return errors.Wrap(err, "error initializing cli")
 		}
 
-		serviceName := args[0]
-
-		err = client.ServiceDelete(serviceName, unbind)
+		if len(names) == 1 {
+			err = client.ServiceDelete(names[0], unbind)
+		} else {
+			err = client.ServicesDelete(names, unbind, all)
+		}
 		return errors.Wrap(err, "error deleting service")
 	},
 }
 var CmdServiceBindCreate = &cobra.Command{
-	Use:   "bind SERVICENAME APPNAME",
-	Short: "Bind a service SERVICENAME to an Epinio app APPNAME",
-	Args:  cobra.ExactArgs(2),
+	Use:               "bind SERVICENAME APPNAME",
+	Short:             "Bind a service SERVICENAME to an Epinio app APPNAME",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingServiceAppFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
@@ -139,9 +158,10 @@ var CmdServiceBindCreate = &cobra.Command{
 }
 
 var CmdServiceUnbind = &cobra.Command{
-	Use:   "unbind SERVICENAME APPNAME",
-	Short: "Unbinds a service SERVICENAME from an Epinio app APPNAME",
-	Args:  cobra.ExactArgs(2),
+	Use:               "unbind SERVICENAME APPNAME",
+	Short:             "Unbinds a service SERVICENAME from an Epinio app APPNAME",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingServiceAppFinder,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
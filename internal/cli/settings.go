@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/epinio/epinio/helpers/termui"
@@ -40,6 +41,10 @@ func init() {
 	CmdSettings.AddCommand(CmdSettingsUpdate)
 	CmdSettings.AddCommand(CmdSettingsShow)
 	CmdSettings.AddCommand(CmdSettingsColors)
+	CmdSettings.AddCommand(CmdSettingsContexts)
+	CmdSettings.AddCommand(CmdSettingsUseContext)
+	CmdSettings.AddCommand(CmdSettingsSaveContext)
+	CmdSettings.AddCommand(CmdSettingsDeleteContext)
 }
 
 // CmdSettingsColors implements the command: epinio settings colors
@@ -102,6 +107,11 @@ var CmdSettingsShow = &cobra.Command{
 
 		ui.Note().WithStringValue("Settings", theSettings.Location).Msg("Show Settings")
 
+		tokenInfo := color.CyanString("None")
+		if theSettings.Token != "" {
+			tokenInfo = color.BlueString("Present")
+		}
+
 		certInfo := color.CyanString("None defined")
 		if theSettings.Certs != "" {
 			certInfo = color.BlueString("Present")
@@ -110,10 +120,12 @@ var CmdSettingsShow = &cobra.Command{
 		ui.Success().
 			WithTable("Key", "Value").
 			WithTableRow("Colorized Output", color.MagentaString("%t", theSettings.Colors)).
+			WithTableRow("Context", color.CyanString(theSettings.Context)).
 			WithTableRow("Current Namespace", color.CyanString(theSettings.Namespace)).
 			WithTableRow("Default App Chart", color.CyanString(theSettings.AppChart)).
 			WithTableRow("API User Name", color.BlueString(theSettings.User)).
 			WithTableRow("API Password", color.BlueString(theSettings.Password)).
+			WithTableRow("API Token", tokenInfo).
 			WithTableRow("API Url", color.BlueString(theSettings.API)).
 			WithTableRow("WSS Url", color.BlueString(theSettings.WSS)).
 			WithTableRow("Certificates", certInfo).
@@ -123,6 +135,138 @@ var CmdSettingsShow = &cobra.Command{
 	},
 }
 
+// CmdSettingsContexts implements the command: epinio settings contexts
+var CmdSettingsContexts = &cobra.Command{
+	Use:   "contexts",
+	Short: "List the settings contexts",
+	Long:  "List the named settings contexts, marking the one in use",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		ui := termui.NewUI()
+
+		theSettings, err := settings.Load()
+		if err != nil {
+			return errors.Wrap(err, "failed to load settings")
+		}
+
+		ui.Note().WithStringValue("Settings", theSettings.Location).Msg("Listing contexts")
+
+		names := []string{}
+		for name := range theSettings.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		msg := ui.Success().WithTable("", "Name", "API Url", "Namespace")
+		for _, name := range names {
+			context := theSettings.Contexts[name]
+			marker := ""
+			if name == theSettings.Context {
+				marker = color.GreenString("*")
+			}
+			msg = msg.WithTableRow(marker, color.CyanString(name),
+				color.BlueString(context.API), context.Namespace)
+		}
+		msg.Msg("Ok")
+
+		return nil
+	},
+}
+
+// CmdSettingsUseContext implements the command: epinio settings use-context
+var CmdSettingsUseContext = &cobra.Command{
+	Use:   "use-context NAME",
+	Short: "Switch to a settings context",
+	Long:  "Make the named settings context the current one, used when no --context is given",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		ui := termui.NewUI()
+
+		theSettings, err := settings.Load()
+		if err != nil {
+			return errors.Wrap(err, "failed to load settings")
+		}
+
+		ui.Note().WithStringValue("Settings", theSettings.Location).Msg("Switch Context")
+
+		if err := theSettings.UseContext(args[0]); err != nil {
+			return err
+		}
+		if err := theSettings.Save(); err != nil {
+			return err
+		}
+
+		ui.Success().
+			WithStringValue("Context", theSettings.Context).
+			WithStringValue("API Url", theSettings.API).
+			Msg("Ok")
+		return nil
+	},
+}
+
+// CmdSettingsSaveContext implements the command: epinio settings save-context
+var CmdSettingsSaveContext = &cobra.Command{
+	Use:   "save-context NAME",
+	Short: "Save the settings as context",
+	Long:  "Save the settings in use as the named context, and make it the current one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		ui := termui.NewUI()
+
+		theSettings, err := settings.Load()
+		if err != nil {
+			return errors.Wrap(err, "failed to load settings")
+		}
+
+		ui.Note().WithStringValue("Settings", theSettings.Location).Msg("Save Context")
+
+		if err := theSettings.SaveContext(args[0]); err != nil {
+			return err
+		}
+		if err := theSettings.Save(); err != nil {
+			return err
+		}
+
+		ui.Success().WithStringValue("Context", theSettings.Context).Msg("Ok")
+		return nil
+	},
+}
+
+// CmdSettingsDeleteContext implements the command: epinio settings delete-context
+var CmdSettingsDeleteContext = &cobra.Command{
+	Use:   "delete-context NAME",
+	Short: "Delete a settings context",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		ui := termui.NewUI()
+
+		theSettings, err := settings.Load()
+		if err != nil {
+			return errors.Wrap(err, "failed to load settings")
+		}
+
+		ui.Note().WithStringValue("Settings", theSettings.Location).Msg("Delete Context")
+
+		if err := theSettings.DeleteContext(args[0]); err != nil {
+			return err
+		}
+		if err := theSettings.Save(); err != nil {
+			return err
+		}
+
+		ui.Success().WithStringValue("Context", args[0]).Msg("Deleted")
+		return nil
+	},
+}
+
 // CmdSettingsUpdate implements the command: epinio settings update
 var CmdSettingsUpdate = &cobra.Command{
 	Use:   "update",
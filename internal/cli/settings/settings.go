@@ -33,12 +33,65 @@ type Settings struct {
 	Colors    bool   `mapstructure:"colors"`
 	AppChart  string `mapstructure:"appchart"` // Current default app chart (name)
 
+	// ID token and refresh token of the OIDC issuer, set by `epinio login --oidc`, or an
+	// API token, usually from EPINIO_TOKEN. The token is used instead of user and password.
+	Token        string `mapstructure:"token"`
+	RefreshToken string `mapstructure:"refresh-token"`
+
+	// Client certificate, and its key, PEM encoded, set by `epinio login --client-certificate`.
+	// They are presented to the API on its mutual TLS port.
+	ClientCertificate string `mapstructure:"client-certificate"`
+	ClientKey         string `mapstructure:"client-key"`
+
+	// Named sets of the settings above, for working with several Epinio installations.
+	// The current context is used by default, the --context option overrides it. Without
+	// context the settings above are used as is.
+	CurrentContext string             `mapstructure:"current-context"`
+	Contexts       map[string]Context `mapstructure:"contexts"`
+
+	Context  string // Context in use, empty for none
 	Location string // Origin of data, file which was loaded
 
 	v   *viper.Viper
 	log logr.Logger
 }
 
+// Context holds the settings specific to one Epinio installation.
+type Context struct {
+	Namespace string `mapstructure:"namespace"`
+	User      string `mapstructure:"user"`
+	Password  string `mapstructure:"pass"`
+	API       string `mapstructure:"api"`
+	WSS       string `mapstructure:"wss"`
+	Certs     string `mapstructure:"certs"`
+	AppChart  string `mapstructure:"appchart"`
+
+	Token        string `mapstructure:"token"`
+	RefreshToken string `mapstructure:"refresh-token"`
+
+	ClientCertificate string `mapstructure:"client-certificate"`
+	ClientKey         string `mapstructure:"client-key"`
+}
+
+// values returns the context as a map, for saving.
+func (c Context) values() map[string]interface{} {
+	return map[string]interface{}{
+		"namespace": c.Namespace,
+		"user":      c.User,
+		"pass":      c.Password,
+		"api":       c.API,
+		"wss":       c.WSS,
+		"certs":     c.Certs,
+		"appchart":  c.AppChart,
+
+		"token":         c.Token,
+		"refresh-token": c.RefreshToken,
+
+		"client-certificate": c.ClientCertificate,
+		"client-key":         c.ClientKey,
+	}
+}
+
 // DefaultLocation returns the standard location for the settings file
 func DefaultLocation() string {
 	return defaultSettingsFilePath
@@ -72,6 +125,10 @@ func LoadFrom(file string) (*Settings, error) {
 	v.SetDefault("api", "")
 	v.SetDefault("wss", "")
 	v.SetDefault("certs", "")
+	v.SetDefault("token", "")
+	v.SetDefault("refresh-token", "")
+	v.SetDefault("client-certificate", "")
+	v.SetDefault("client-key", "")
 	v.SetDefault("colors", true)
 
 	settingsExists, err := fileExists(file)
@@ -94,10 +151,30 @@ func LoadFrom(file string) (*Settings, error) {
 	cfg.v = v
 	cfg.Location = file
 
+	// Viper keys, and thus context names, are case-insensitive.
+	cfg.Context = strings.ToLower(viper.GetString("context"))
+	if cfg.Context == "" {
+		cfg.Context = cfg.CurrentContext
+	}
+	if cfg.Context != "" {
+		// An unknown context starts out empty, and is created when saved, i.e. by login.
+		context, ok := cfg.Contexts[cfg.Context]
+		if !ok {
+			context = Context{Namespace: "workspace"}
+		}
+		cfg.apply(context)
+	}
+
 	if cfg.Certs != "" {
 		auth.ExtendLocalTrust(cfg.Certs)
 	}
 
+	if cfg.ClientCertificate != "" {
+		if err := auth.UseClientCertificate(cfg.ClientCertificate, cfg.ClientKey); err != nil {
+			return nil, errors.Wrap(err, "bad client certificate")
+		}
+	}
+
 	if viper.GetBool("skip-ssl-verification") {
 		// Note: This has to work regardless of if `ExtendLocalTrust` was invoked or not.
 		// I.e. the `TLSClientConfig` of default http transport and default dialer may or
@@ -131,21 +208,51 @@ func LoadFrom(file string) (*Settings, error) {
 // Generates a string representation of the settings (for debugging)
 func (c *Settings) String() string {
 	return fmt.Sprintf(
-		"namespace=(%s), user=(%s), pass=(%s), api=(%s), wss=(%s), color=(%v), appchart=(%v), @(%s)",
-		c.Namespace, c.User, c.Password, c.API, c.WSS, c.Colors, c.AppChart, c.Location)
+		"namespace=(%s), user=(%s), pass=(%s), api=(%s), wss=(%s), color=(%v), appchart=(%v), context=(%s), @(%s)",
+		c.Namespace, c.User, c.Password, c.API, c.WSS, c.Colors, c.AppChart, c.Context, c.Location)
 }
 
-// Save saves the Epinio settings
+// Save saves the Epinio settings. With a context in use its settings are saved, and the
+// settings outside of the contexts are left as they are.
 func (c *Settings) Save() error {
-	c.v.Set("namespace", c.Namespace)
-	c.v.Set("appchart", c.AppChart)
-	c.v.Set("user", c.User)
-	c.v.Set("pass", c.Password)
-	c.v.Set("api", c.API)
-	c.v.Set("wss", c.WSS)
-	c.v.Set("certs", c.Certs)
+	if c.Context != "" {
+		if c.Contexts == nil {
+			c.Contexts = map[string]Context{}
+		}
+		c.Contexts[c.Context] = c.current()
+	} else {
+		c.v.Set("namespace", c.Namespace)
+		c.v.Set("appchart", c.AppChart)
+		c.v.Set("user", c.User)
+		c.v.Set("pass", c.Password)
+		c.v.Set("api", c.API)
+		c.v.Set("wss", c.WSS)
+		c.v.Set("certs", c.Certs)
+		c.v.Set("token", c.Token)
+		c.v.Set("refresh-token", c.RefreshToken)
+		c.v.Set("client-certificate", c.ClientCertificate)
+		c.v.Set("client-key", c.ClientKey)
+	}
 	c.v.Set("colors", c.Colors)
 
+	c.v.Set("current-context", c.CurrentContext)
+
+	// Setting the contexts in the loaded viper would merge them with the contexts read from
+	// the file, keeping deleted ones. The settings are written through a fresh viper instead.
+	out := viper.New()
+	out.SetConfigType("yaml")
+	out.SetConfigFile(c.v.ConfigFileUsed())
+	for key, value := range c.v.AllSettings() {
+		if key != "contexts" {
+			out.Set(key, value)
+		}
+	}
+	contexts := map[string]interface{}{}
+	for name, context := range c.Contexts {
+		contexts[name] = context.values()
+	}
+	out.Set("contexts", contexts)
+
 	c.log.Info("Saving", "to", c.v.ConfigFileUsed())
 
 	err := os.MkdirAll(filepath.Dir(c.v.ConfigFileUsed()), 0700)
@@ -153,7 +260,7 @@ func (c *Settings) Save() error {
 		return errors.Wrapf(err, "failed to create settings dir '%s'", filepath.Dir(c.v.ConfigFileUsed()))
 	}
 
-	err = c.v.WriteConfig()
+	err = out.WriteConfig()
 	if err != nil {
 		return errors.Wrapf(err, "failed to write settings file '%s'", c.v.ConfigFileUsed())
 	}
@@ -166,10 +273,117 @@ func (c *Settings) Save() error {
 	if c.Certs != "" {
 		auth.ExtendLocalTrust(c.Certs)
 	}
+	if c.ClientCertificate != "" {
+		if err := auth.UseClientCertificate(c.ClientCertificate, c.ClientKey); err != nil {
+			return errors.Wrap(err, "bad client certificate")
+		}
+	}
+
+	return nil
+}
+
+// UseContext makes the named context the current one, i.e. the one used by default. The
+// settings are not saved.
+func (c *Settings) UseContext(name string) error {
+	name = strings.ToLower(name)
+	context, ok := c.Contexts[name]
+	if !ok {
+		return errors.Errorf("unknown context '%s'", name)
+	}
+
+	c.CurrentContext = name
+	c.Context = name
+	c.apply(context)
+
+	return nil
+}
+
+// SaveContext copies the settings in use into the named context, replacing it, and makes it
+// the current one. The settings are not saved.
+func (c *Settings) SaveContext(name string) error {
+	name = strings.ToLower(name)
+	if name == "" {
+		return errors.New("context name cannot be empty")
+	}
+	if c.Contexts == nil {
+		c.Contexts = map[string]Context{}
+	}
+
+	c.Contexts[name] = c.current()
+	c.CurrentContext = name
+	c.Context = name
 
 	return nil
 }
 
+// DeleteContext removes the named context. Deleting the current context falls back to the
+// settings outside of the contexts. The settings are not saved.
+func (c *Settings) DeleteContext(name string) error {
+	name = strings.ToLower(name)
+	if _, ok := c.Contexts[name]; !ok {
+		return errors.Errorf("unknown context '%s'", name)
+	}
+
+	delete(c.Contexts, name)
+	if c.CurrentContext == name {
+		c.CurrentContext = ""
+	}
+	if c.Context == name {
+		c.Context = ""
+		c.apply(Context{
+			Namespace: c.v.GetString("namespace"),
+			User:      c.v.GetString("user"),
+			Password:  c.v.GetString("pass"),
+			API:       c.v.GetString("api"),
+			WSS:       c.v.GetString("wss"),
+			Certs:     c.v.GetString("certs"),
+			AppChart:  c.v.GetString("appchart"),
+
+			Token:        c.v.GetString("token"),
+			RefreshToken: c.v.GetString("refresh-token"),
+
+			ClientCertificate: c.v.GetString("client-certificate"),
+			ClientKey:         c.v.GetString("client-key"),
+		})
+	}
+
+	return nil
+}
+
+// current returns the settings in use, as context.
+func (c *Settings) current() Context {
+	return Context{
+		Namespace: c.Namespace,
+		User:      c.User,
+		Password:  c.Password,
+		API:       c.API,
+		WSS:       c.WSS,
+		Certs:     c.Certs,
+		AppChart:  c.AppChart,
+
+		Token:        c.Token,
+		RefreshToken: c.RefreshToken,
+
+		ClientCertificate: c.ClientCertificate,
+		ClientKey:         c.ClientKey,
+	}
+}
+
+// apply makes the settings of the context the ones in use.
+func (c *Settings) apply(context Context) {
+	c.Namespace = context.Namespace
+	c.User = context.User
+	c.Password = context.Password
+	c.API = context.API
+	c.WSS = context.WSS
+	c.Certs = context.Certs
+	c.AppChart = context.AppChart
+	c.Token = context.Token
+	c.RefreshToken = context.RefreshToken
+	c.ClientCertificate = context.ClientCertificate
+	c.ClientKey = context.ClientKey
+}
+
 func location() string {
 	return viper.GetString("settings-file")
 }
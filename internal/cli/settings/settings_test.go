@@ -0,0 +1,89 @@
+package settings_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/epinio/epinio/internal/cli/settings"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("Settings contexts", func() {
+	var file string
+
+	BeforeEach(func() {
+		dir, err := os.MkdirTemp("", "epinio-settings")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		file = filepath.Join(dir, "settings.yaml")
+		Expect(os.WriteFile(file, []byte(`
+namespace: workspace
+api: https://epinio.dev.example.com
+user: dev-user
+pass: dev-pass
+`), 0600)).To(Succeed())
+
+		viper.Set("context", "")
+		DeferCleanup(viper.Set, "context", "")
+	})
+
+	It("uses the settings outside of the contexts without context", func() {
+		s, err := settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Context).To(BeEmpty())
+		Expect(s.API).To(Equal("https://epinio.dev.example.com"))
+	})
+
+	It("saves, switches, and deletes contexts", func() {
+		s, err := settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.SaveContext("dev")).To(Succeed())
+		Expect(s.Save()).To(Succeed())
+
+		// A new context, created by saving it, e.g. on login.
+		viper.Set("context", "Prod")
+		s, err = settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Context).To(Equal("prod"))
+		Expect(s.API).To(BeEmpty())
+		Expect(s.Namespace).To(Equal("workspace"))
+		s.API = "https://epinio.prod.example.com"
+		s.User = "prod-user"
+		Expect(s.Save()).To(Succeed())
+
+		// The current context is still dev.
+		viper.Set("context", "")
+		s, err = settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Context).To(Equal("dev"))
+		Expect(s.API).To(Equal("https://epinio.dev.example.com"))
+		Expect(s.Contexts).To(HaveLen(2))
+
+		Expect(s.UseContext("prod")).To(Succeed())
+		Expect(s.API).To(Equal("https://epinio.prod.example.com"))
+		Expect(s.Save()).To(Succeed())
+
+		s, err = settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Context).To(Equal("prod"))
+		Expect(s.User).To(Equal("prod-user"))
+
+		Expect(s.DeleteContext("dev")).To(Succeed())
+		Expect(s.Save()).To(Succeed())
+
+		s, err = settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Contexts).To(HaveLen(1))
+		Expect(s.Contexts).To(HaveKey("prod"))
+	})
+
+	It("rejects unknown contexts", func() {
+		s, err := settings.LoadFrom(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.UseContext("staging")).To(MatchError("unknown context 'staging'"))
+		Expect(s.DeleteContext("staging")).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdAppTasks implements the command: epinio app tasks
+var CmdAppTasks = &cobra.Command{
+	Use:   "tasks",
+	Short: "Epinio application scheduled tasks",
+	Long:  `Show and run the scheduled tasks of epinio applications. Declare them in the 'tasks' section of the application manifest`,
+}
+
+func init() {
+	CmdAppTasks.AddCommand(CmdTasksList)
+	CmdAppTasks.AddCommand(CmdTasksRun)
+}
+
+// CmdTasksList implements the command: epinio app tasks list
+var CmdTasksList = &cobra.Command{
+	Use:               "list APPNAME",
+	Short:             "List application tasks",
+	Long:              "List the scheduled tasks of the named application, with their last run, and the number of active runs",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppTasks(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error listing app tasks")
+		}
+
+		return nil
+	},
+}
+
+// CmdTasksRun implements the command: epinio app tasks run
+var CmdTasksRun = &cobra.Command{
+	Use:               "run APPNAME TASK",
+	Short:             "Run application task",
+	Long:              "Run the named task of the named application now, outside of its schedule",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: matchingAppsFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.AppTaskRun(args[0], args[1])
+		if err != nil {
+			return errors.Wrap(err, "error running app task")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdTeam implements the command: epinio team
+var CmdTeam = &cobra.Command{
+	Use:     "team",
+	Aliases: []string{"teams"},
+	Short:   "Epinio teams",
+	Long: `Manage the Epinio teams. Teams give their members shared access to namespaces.
+
+The members of a team are Epinio users, and the users of the OIDC groups of the team.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+// CmdTeamRole implements the command: epinio team role
+var CmdTeamRole = &cobra.Command{
+	Use:           "role",
+	Short:         "Roles of the teams in namespaces",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdTeam.AddCommand(CmdTeamSet)
+	CmdTeam.AddCommand(CmdTeamList)
+	CmdTeam.AddCommand(CmdTeamDelete)
+	CmdTeam.AddCommand(CmdTeamRole)
+	CmdTeamRole.AddCommand(CmdTeamRoleSet)
+
+	CmdTeamSet.Flags().StringSlice("member", []string{}, "Epinio user in the team. Can be set multiple times")
+	CmdTeamSet.Flags().StringSlice("group", []string{}, "OIDC group whose users are in the team. Can be set multiple times")
+
+	CmdTeamRoleSet.Flags().String("namespace", "", "Namespace to give the team the role in")
+	_ = CmdTeamRoleSet.MarkFlagRequired("namespace")
+	_ = CmdTeamRoleSet.RegisterFlagCompletionFunc("namespace", matchingNamespaceFinder)
+}
+
+// CmdTeamSet implements the command: epinio team set
+var CmdTeamSet = &cobra.Command{
+	Use:   "set NAME [--member USER]... [--group GROUP]...",
+	Short: "Creates the team, or replaces its members",
+	Long:  "Creates the team, or replaces its members and groups. Only admins can do this.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		members, err := cmd.Flags().GetStringSlice("member")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --member")
+		}
+		groups, err := cmd.Flags().GetStringSlice("group")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --group")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TeamSet(args[0], members, groups)
+		if err != nil {
+			return errors.Wrap(err, "error setting team")
+		}
+
+		return nil
+	},
+}
+
+// CmdTeamList implements the command: epinio team list
+var CmdTeamList = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the teams",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TeamList()
+		if err != nil {
+			return errors.Wrap(err, "error listing teams")
+		}
+
+		return nil
+	},
+}
+
+// CmdTeamDelete implements the command: epinio team delete
+var CmdTeamDelete = &cobra.Command{
+	Use:   "delete NAME",
+	Short: "Deletes the team",
+	Long:  "Deletes the team. Its members lose the access given by it.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TeamDelete(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error deleting team")
+		}
+
+		return nil
+	},
+}
+
+// CmdTeamRoleSet implements the command: epinio team role set
+var CmdTeamRoleSet = &cobra.Command{
+	Use:   "set TEAM ROLE --namespace NAMESPACE",
+	Short: "Gives the team a role in the namespace",
+	Long: `Gives the team a role in the namespace. Only admins can do this.
+
+The members of the team get the role in the namespace, unless they have a stronger one already. The roles are those of the users. The role "none" removes the team from the namespace.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --namespace")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TeamRoleSet(args[0], args[1], namespace)
+		if err != nil {
+			return errors.Wrap(err, "error setting role")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdToken implements the command: epinio token
+var CmdToken = &cobra.Command{
+	Use:     "token",
+	Aliases: []string{"tokens"},
+	Short:   "API tokens",
+	Long: `Manage API tokens. Scripts and pipelines use them instead of the password of the user.
+
+Use the token by setting it as EPINIO_TOKEN in the environment of the CLI.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdToken.AddCommand(CmdTokenCreate)
+	CmdToken.AddCommand(CmdTokenList)
+	CmdToken.AddCommand(CmdTokenRevoke)
+
+	CmdTokenCreate.Flags().String("scope", "full", "Scope of the token, 'read' for requests changing nothing, or 'full'")
+	CmdTokenCreate.Flags().StringSlice("namespace", []string{}, "Namespace the token gives access to. Can be set multiple times. Default is all namespaces of the user")
+	CmdTokenCreate.Flags().Duration("expires-in", 30*24*time.Hour, "Time until the token expires")
+}
+
+// CmdTokenCreate implements the command: epinio token create
+var CmdTokenCreate = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Creates an API token",
+	Long:  "Creates an API token of the user. The token is shown only once.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		scope, err := cmd.Flags().GetString("scope")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --scope")
+		}
+		namespaces, err := cmd.Flags().GetStringSlice("namespace")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --namespace")
+		}
+		expiry, err := cmd.Flags().GetDuration("expires-in")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --expires-in")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TokenCreate(args[0], scope, namespaces, expiry)
+		if err != nil {
+			return errors.Wrap(err, "error creating API token")
+		}
+
+		return nil
+	},
+}
+
+// CmdTokenList implements the command: epinio token list
+var CmdTokenList = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the API tokens of the user",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TokenList()
+		if err != nil {
+			return errors.Wrap(err, "error listing API tokens")
+		}
+
+		return nil
+	},
+}
+
+// CmdTokenRevoke implements the command: epinio token revoke
+var CmdTokenRevoke = &cobra.Command{
+	Use:   "revoke ID",
+	Short: "Revokes an API token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.TokenRevoke(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error revoking API token")
+		}
+
+		return nil
+	},
+}
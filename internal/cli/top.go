@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CmdTop.Flags().Bool("all", false, "show the applications of all namespaces")
+	CmdTop.Flags().String("sort", usercmd.TopSortName, "sort order: name, cpu, memory, or restarts")
+	CmdTop.RegisterFlagCompletionFunc("sort",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{
+				usercmd.TopSortName,
+				usercmd.TopSortCPU,
+				usercmd.TopSortMemory,
+				usercmd.TopSortRestarts,
+			}, cobra.ShellCompDirectiveNoFileComp
+		})
+}
+
+// CmdTop implements the command: epinio top
+var CmdTop = &cobra.Command{
+	Use:               "top [namespace] [--all] [--sort ORDER]",
+	Short:             "Show the resource usage of applications",
+	Long:              "Show the instances, memory and cpu usage, and restarts of the applications in the targeted or named namespace, or in all namespaces",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: matchingNamespaceFinder,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --all")
+		}
+		sortBy, err := cmd.Flags().GetString("sort")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --sort")
+		}
+
+		namespace := ""
+		if len(args) > 0 {
+			if all {
+				return errors.New("a namespace cannot be combined with --all")
+			}
+			namespace = args[0]
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.Top(namespace, all, sortBy)
+		// Note: errors.Wrap (nil, "...") == nil
+		return errors.Wrap(err, "error showing the resource usage")
+	},
+}
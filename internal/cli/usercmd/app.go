@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,8 +19,11 @@ import (
 	"github.com/epinio/epinio/helpers/bytes"
 	"github.com/epinio/epinio/helpers/kubernetes/tailer"
 	"github.com/epinio/epinio/internal/cli/logprinter"
+	"github.com/epinio/epinio/internal/helmexport"
+	"github.com/epinio/epinio/internal/routes"
 	"github.com/epinio/epinio/pkg/api/core/v1/client"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"k8s.io/apimachinery/pkg/util/validation"
 	kubectlterm "k8s.io/kubectl/pkg/util/term"
 )
 
@@ -56,35 +60,111 @@ func (c *EpinioClient) AppCreate(appName string, appConfig models.ApplicationUpd
 	return nil
 }
 
-// AppsMatching returns all Epinio apps having the specified prefix in their name.
-func (c *EpinioClient) AppsMatching(prefix string) []string {
-	log := c.Log.WithName("AppsMatching").WithValues("PrefixToMatch", prefix)
+// AppApply creates the app of the manifest, or changes the existing one to match it
+func (c *EpinioClient) AppApply(m models.ApplicationManifest) error {
+	log := c.Log.WithName("AppApply").WithValues("Namespace", c.Settings.Namespace, "Application", m.Name)
 	log.Info("start")
 	defer log.Info("return")
-	details := log.V(1) // NOTE: Increment of level, not absolute.
 
-	result := []string{}
+	request := models.ApplicationApplyRequest{
+		Name:          m.Name,
+		Configuration: m.Configuration,
+	}
+	switch m.Origin.Kind {
+	case models.OriginContainer:
+		request.Origin = m.Origin
+	case models.OriginGit:
+		return errors.New("only container images can be applied, push the sources of git repositories")
+	}
 
-	// Ask for all apps. Filtering is local.
-	// TODO: Create new endpoint (compare `EnvMatch`) and move filtering to the server.
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", m.Name).
+		WithStringValue("Image", request.Origin.Container).
+		Msg("Apply application manifest")
 
-	apps, err := c.API.Apps(c.Settings.Namespace)
+	resp, err := c.API.AppApply(request, c.Settings.Namespace)
 	if err != nil {
-		return result
+		return err
 	}
 
-	for _, app := range apps {
-		details.Info("Found", "Name", app.Meta.Name)
+	if c.ui.Machine() {
+		return c.ui.Data(resp)
+	}
 
-		if strings.HasPrefix(app.Meta.Name, prefix) {
-			details.Info("Matched", "Name", app.Meta.Name)
-			result = append(result, app.Meta.Name)
-		}
+	changes := strings.Join(resp.Changes, ", ")
+	if resp.Created {
+		changes = "created"
+	} else if changes == "" {
+		changes = "none"
 	}
 
-	sort.Strings(result)
+	msg := c.ui.Success().
+		WithStringValue("Changes", changes).
+		WithBoolValue("Deployed", resp.Deployed)
+	if len(resp.Routes) > 0 {
+		msg = msg.WithStringValue("Routes", strings.Join(resp.Routes, ", "))
+	}
+	msg.Msg("Manifest applied")
 
-	return result
+	return nil
+}
+
+// AppAdopt turns an existing deployment of the targeted namespace into an app
+func (c *EpinioClient) AppAdopt(request models.AppAdoptRequest) error {
+	log := c.Log.WithName("AppAdopt").WithValues("Namespace", c.Settings.Namespace, "Deployment", request.Deployment)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Deployment", request.Deployment).
+		WithBoolValue("Dry run", request.DryRun).
+		Msg("Adopt deployment")
+
+	resp, err := c.API.AppAdopt(request, c.Settings.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(resp)
+	}
+
+	for _, warning := range resp.Warnings {
+		c.ui.Exclamation().Msg(warning)
+	}
+
+	msg := c.ui.Success().
+		WithStringValue("Application", resp.Name).
+		WithStringValue("Image", resp.ImageURL).
+		WithStringValue("Resources", strings.Join(resp.Adopted, ", "))
+	if len(resp.Routes) > 0 {
+		msg = msg.WithStringValue("Routes", strings.Join(resp.Routes, ", "))
+	}
+	if request.DryRun {
+		msg.Msg("Deployment can be adopted")
+		return nil
+	}
+	msg.Msg("Deployment adopted")
+
+	return nil
+}
+
+// AppsMatching returns all Epinio apps having the specified prefix in their name.
+func (c *EpinioClient) AppsMatching(prefix string) []string {
+	return c.matching("apps", c.Settings.Namespace, prefix, func() ([]string, error) {
+		apps, err := c.API.Apps(c.Settings.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{}
+		for _, app := range apps {
+			names = append(names, app.Meta.Name)
+		}
+		return names, nil
+	})
 }
 
 // Apps gets all Epinio apps in the targeted namespace, or all apps in all namespaces
@@ -123,8 +203,12 @@ func (c *EpinioClient) Apps(all bool) error {
 
 	sort.Sort(apps)
 
+	if c.ui.Machine() {
+		return c.ui.Data(apps)
+	}
+
 	if all {
-		msg = c.ui.Success().WithTable("Namespace", "Name", "Created", "Status", "Routes", "Configurations", "Status Details")
+		msg = c.ui.Success().WithTable("Namespace", "Name", "Created", "Status", "Memory", "MilliCPUs", "Restarts", "Routes", "Configurations", "Status Details")
 
 		for _, app := range apps {
 			created := fmt.Sprintf("%v", app.Meta.CreatedAt)
@@ -136,17 +220,24 @@ func (c *EpinioClient) Apps(all bool) error {
 					created,
 					"n/a",
 					"n/a",
+					"n/a",
+					"n/a",
+					"n/a",
 					strings.Join(app.Configuration.Configurations, ", "),
 					app.StatusMessage,
 				)
 			} else {
 				sort.Strings(app.Workload.Routes)
 				sort.Strings(app.Configuration.Configurations)
+				memory, milliCPUs, restarts := appUsage(app.Workload)
 				msg = msg.WithTableRow(
 					app.Meta.Namespace,
 					app.Meta.Name,
 					created,
 					app.Workload.Status,
+					memory,
+					milliCPUs,
+					restarts,
 					strings.Join(app.Workload.Routes, ", "),
 					strings.Join(app.Configuration.Configurations, ", "),
 					app.StatusMessage,
@@ -154,7 +245,7 @@ func (c *EpinioClient) Apps(all bool) error {
 			}
 		}
 	} else {
-		msg = c.ui.Success().WithTable("Name", "Created", "Status", "Routes", "Configurations", "Status Details")
+		msg = c.ui.Success().WithTable("Name", "Created", "Status", "Memory", "MilliCPUs", "Restarts", "Routes", "Configurations", "Status Details")
 
 		for _, app := range apps {
 			created := fmt.Sprintf("%v", app.Meta.CreatedAt)
@@ -165,16 +256,23 @@ func (c *EpinioClient) Apps(all bool) error {
 					created,
 					"n/a",
 					"n/a",
+					"n/a",
+					"n/a",
+					"n/a",
 					strings.Join(app.Configuration.Configurations, ", "),
 					app.StatusMessage,
 				)
 			} else {
 				sort.Strings(app.Workload.Routes)
 				sort.Strings(app.Configuration.Configurations)
+				memory, milliCPUs, restarts := appUsage(app.Workload)
 				msg = msg.WithTableRow(
 					app.Meta.Name,
 					created,
 					app.Workload.Status,
+					memory,
+					milliCPUs,
+					restarts,
 					strings.Join(app.Workload.Routes, ", "),
 					strings.Join(app.Configuration.Configurations, ", "),
 					app.StatusMessage,
@@ -188,135 +286,982 @@ func (c *EpinioClient) Apps(all bool) error {
 	return nil
 }
 
-// AppShow displays the information of the named app, in the targeted namespace
-func (c *EpinioClient) AppShow(appName string) error {
-	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+// AppShow displays the information of the named app, in the targeted namespace
+func (c *EpinioClient) AppShow(appName string) error {
+	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Show application details")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	details.Info("show application")
+
+	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	details.Info("show application domains")
+
+	domains, err := c.API.AppDomains(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(struct {
+			models.App
+			Domains models.AppDomainList `json:"domains"`
+		}{app, domains})
+	}
+
+	if err := c.printAppDetails(app, domains); err != nil {
+		return err
+	}
+
+	return c.printReplicaDetails(app)
+}
+
+// Formats of the exported applications
+const (
+	// ExportFormatEpinio exports the app chart, and the values of the deployed release.
+	ExportFormatEpinio = "epinio"
+	// ExportFormatHelm exports a standalone chart, deployable without Epinio.
+	ExportFormatHelm = "helm"
+)
+
+// AppExport saves the named app, in the targeted namespace, to the directory, in the format.
+func (c *EpinioClient) AppExport(appName string, directory string, format string) error {
+	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	if format != ExportFormatEpinio && format != ExportFormatHelm {
+		return fmt.Errorf("bad format '%s', expected one of %s, %s", format, ExportFormatEpinio, ExportFormatHelm)
+	}
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		WithStringValue("Target Directory", directory).
+		WithStringValue("Format", format).
+		Msg("Export application")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	details.Info("export application")
+
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create export directory '%s'", directory)
+	}
+
+	if format == ExportFormatHelm {
+		return c.appExportHelm(appName, directory)
+	}
+
+	err = c.API.AppGetPart(c.Settings.Namespace, appName, "values", filepath.Join(directory, "values.yaml"))
+	if err != nil {
+		return err
+	}
+
+	err = c.API.AppGetPart(c.Settings.Namespace, appName, "chart", filepath.Join(directory, "app-chart.tar.gz"))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// appExportHelm saves the named app as a standalone chart into the directory.
+func (c *EpinioClient) appExportHelm(appName string, directory string) error {
+	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	files, err := helmexport.Files(app)
+	if err != nil {
+		return err
+	}
+
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		target := filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return errors.Wrapf(err, "failed to create directory of '%s'", target)
+		}
+		if err := ioutil.WriteFile(target, files[path], 0600); err != nil {
+			return errors.Wrapf(err, "failed to write '%s'", target)
+		}
+	}
+
+	msg := c.ui.Success().WithTable("File")
+	for _, path := range paths {
+		msg = msg.WithTableRow(path)
+	}
+	if len(app.Configuration.Configurations) > 0 {
+		msg = msg.WithStringValue("Secrets to provide", strings.Join(app.Configuration.Configurations, ", "))
+	}
+	msg.Msg("Exported the application as a Helm chart. Install it with `helm install " + appName + " " + directory + "`")
+
+	return nil
+}
+
+// AppManifest saves the information of the named app, in the targeted namespace, into a manifest file.
+// Without a file the manifest is printed, and nothing else.
+func (c *EpinioClient) AppManifest(appName, manifestPath string) error {
+	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	if manifestPath != "" {
+		c.ui.Note().
+			WithStringValue("Namespace", c.Settings.Namespace).
+			WithStringValue("Application", appName).
+			WithStringValue("Destination", manifestPath).
+			Msg("Save application details to manifest")
+	}
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	details.Info("show application")
+
+	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	yaml, err := yaml.Marshal(AppToManifest(app))
+	if err != nil {
+		return err
+	}
+
+	if manifestPath == "" {
+		fmt.Fprint(os.Stdout, string(yaml))
+		return nil
+	}
+
+	err = ioutil.WriteFile(manifestPath, yaml, 0600)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Saved")
+
+	return nil
+}
+
+// AppToManifest returns the manifest which pushes the application as it is, i.e. with its
+// configuration, origin, and staging settings.
+func AppToManifest(app models.App) models.ApplicationManifest {
+	m := models.ApplicationManifest{}
+	m.Name = app.Meta.Name
+	m.Configuration = app.Configuration
+	m.Origin = app.Origin
+	m.Staging = app.Staging
+	return m
+}
+
+// AppCopy deploys the image of the named app, in the targeted namespace, as an app of the same
+// name in the other namespace, i.e. promotes it without rebuilding. The bound configurations
+// are translated by the binds, which map the configurations of the app to the ones of the
+// other namespace. Configurations not mapped are bound by the same name. The environment is
+// merged over the app's. Routes are not copied, the copy gets the default route of the other
+// namespace. An existing app in the other namespace is updated.
+func (c *EpinioClient) AppCopy(appName, toNamespace string, binds map[string]string, env models.EnvVariableMap) error {
+	log := c.Log.WithName("AppCopy").WithValues("Namespace", c.Settings.Namespace, "Application", appName, "To", toNamespace)
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	msg := c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		WithStringValue("Target Namespace", toNamespace)
+	for _, from := range sortedBinds(binds) {
+		msg = msg.WithStringValue(fmt.Sprintf("Configuration '%s'", from), binds[from])
+	}
+	for _, ev := range env.List() {
+		msg = msg.WithStringValue(fmt.Sprintf("Environment '%s'", ev.Name), ev.Value)
+	}
+	msg.Msg("Copying application")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	if toNamespace == c.Settings.Namespace {
+		return errors.New("the target namespace is the namespace of the application")
+	}
+
+	details.Info("show application")
+
+	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+	if app.ImageURL == "" {
+		return errors.Errorf("application '%s' has no image to copy, it was not staged yet", appName)
+	}
+
+	configuration, err := CopyConfiguration(app.Configuration, binds, env)
+	if err != nil {
+		return err
+	}
+
+	appRef := models.NewAppRef(appName, toNamespace)
+
+	details.Info("create application")
+
+	_, err = c.API.AppCreate(models.ApplicationCreateRequest{
+		Name:          appName,
+		Configuration: configuration,
+	}, toNamespace)
+	if err != nil {
+		rerr, ok := err.(interface{ StatusCode() int })
+		if !ok || rerr.StatusCode() != http.StatusConflict {
+			return err
+		}
+
+		c.ui.Normal().Msg("Application exists, updating ...")
+
+		_, err = c.API.AppUpdate(configuration, toNamespace, appName)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.ui.Normal().Msg("Deploying application ...")
+
+	deployResponse, err := c.API.AppDeploy(models.DeployRequest{
+		App:      appRef,
+		ImageURL: app.ImageURL,
+		Origin: models.ApplicationOrigin{
+			Kind:      models.OriginContainer,
+			Container: app.ImageURL,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	details.Info("wait for application resources")
+	c.ui.ProgressNote().KeeplineUnder(1).Msg("Creating application resources")
+
+	_, err = c.API.AppRunning(appRef)
+	if err != nil {
+		return errors.Wrap(err, "waiting for app failed")
+	}
+
+	msg = c.ui.Success().
+		WithStringValue("Name", appName).
+		WithStringValue("Namespace", toNamespace).
+		WithStringValue("Image", app.ImageURL).
+		WithStringValue("Routes", "")
+
+	routes := []string{}
+	for _, d := range deployResponse.Routes {
+		routes = append(routes, fmt.Sprintf("https://%s", d))
+	}
+	sort.Strings(routes)
+	for i, r := range routes {
+		msg = msg.WithStringValue(strconv.Itoa(i+1), r)
+	}
+	msg.Msg("App is online.")
+
+	return nil
+}
+
+// CopyConfiguration returns the configuration of an app copied to another namespace. The bound
+// configurations, their paths, and the mounts of the sidecars are translated by the binds.
+// Binds for configurations not bound to the app are rejected. The environment is merged over
+// the app's. Routes, and their annotations, are specific to the namespace of the app, and
+// dropped. Fixed node ports are cluster-wide, and left to the cluster to choose.
+func CopyConfiguration(conf models.ApplicationUpdateRequest, binds map[string]string, env models.EnvVariableMap) (models.ApplicationUpdateRequest, error) {
+	translate := func(name string) string {
+		if to, ok := binds[name]; ok {
+			return to
+		}
+		return name
+	}
+
+	bound := map[string]struct{}{}
+	for _, name := range conf.Configurations {
+		bound[name] = struct{}{}
+	}
+	for _, from := range sortedBinds(binds) {
+		if _, ok := bound[from]; !ok {
+			return conf, errors.Errorf("configuration '%s' is not bound to the application", from)
+		}
+	}
+
+	result := conf
+	result.Routes = []string{}
+	result.RouteAnnotations = nil
+
+	if conf.Ports != nil {
+		result.Ports = []models.AppPort{}
+		for _, port := range conf.Ports {
+			port.NodePort = 0
+			result.Ports = append(result.Ports, port)
+		}
+	}
+
+	result.Configurations = []string{}
+	for _, name := range conf.Configurations {
+		result.Configurations = append(result.Configurations, translate(name))
+	}
+
+	if conf.ConfigurationPaths != nil {
+		result.ConfigurationPaths = map[string]string{}
+		for name, path := range conf.ConfigurationPaths {
+			result.ConfigurationPaths[translate(name)] = path
+		}
+	}
+
+	if conf.Sidecars != nil {
+		result.Sidecars = []models.AppSidecar{}
+		for _, sidecar := range conf.Sidecars {
+			mounts := []models.AppSidecarMount{}
+			for _, mount := range sidecar.Mounts {
+				mount.Configuration = translate(mount.Configuration)
+				mounts = append(mounts, mount)
+			}
+			if sidecar.Mounts == nil {
+				mounts = nil
+			}
+			sidecar.Mounts = mounts
+			result.Sidecars = append(result.Sidecars, sidecar)
+		}
+	}
+
+	result.Environment = models.EnvVariableMap{}
+	for name, value := range conf.Environment {
+		result.Environment[name] = value
+	}
+	for name, value := range env {
+		result.Environment[name] = value
+	}
+
+	return result, nil
+}
+
+// sortedBinds returns the configurations translated by the binds, in order.
+func sortedBinds(binds map[string]string) []string {
+	result := []string{}
+	for name := range binds {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AppRestart restarts an application
+func (c *EpinioClient) AppRestart(appName string) error {
+	log := c.Log.WithName("AppRestart").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Restarting application")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("restarting application")
+
+	return c.API.AppRestart(c.Settings.Namespace, appName)
+}
+
+// RestartApps restarts the named applications, or all active ones, of the namespace, in one
+// call, and shows the outcome for each.
+func (c *EpinioClient) RestartApps(appnames []string, all bool) error {
+	log := c.Log.WithName("RestartApps").WithValues("Applications", appnames, "All", all)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().WithStringValue("Namespace", c.Settings.Namespace)
+	if all {
+		msg.Msg("Restarting all applications...")
+	} else {
+		msg.WithStringValue("Names", strings.Join(appnames, ", ")).Msg("Restarting applications...")
+	}
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	s := c.ui.Progressf("Restarting in %s", c.Settings.Namespace)
+	defer s.Stop()
+
+	response, err := c.API.AppBatchRestart(c.Settings.Namespace, models.BatchRestartRequest{
+		Names: appnames,
+		All:   all,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Stop()
+
+	if c.ui.Machine() {
+		if err := c.ui.Data(response.Results); err != nil {
+			return err
+		}
+	} else {
+		msg := c.ui.Success().WithTable("Application", "Result", "Details")
+		for _, result := range response.Results {
+			if result.Error == "" {
+				msg = msg.WithTableRow(result.Name, "restarted", "")
+				continue
+			}
+			msg = msg.WithTableRow(result.Name, "failed", result.Error)
+		}
+		msg.Msg("Restarts done.")
+	}
+
+	failed := 0
+	for _, result := range response.Results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d restarts failed", failed, len(response.Results))
+	}
+
+	return nil
+}
+
+// AppSleep puts an application to sleep, i.e. scales it to zero instances
+func (c *EpinioClient) AppSleep(appName string) error {
+	log := c.Log.WithName("AppSleep").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Putting application to sleep")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("putting application to sleep")
+
+	if err := c.API.AppSleep(c.Settings.Namespace, appName); err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Application sleeping")
+
+	return nil
+}
+
+// AppWake wakes a sleeping application, i.e. scales it back to its desired instances
+func (c *EpinioClient) AppWake(appName string) error {
+	log := c.Log.WithName("AppWake").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Waking application")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("waking application")
+
+	if err := c.API.AppWake(c.Settings.Namespace, appName); err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Application awake")
+
+	return nil
+}
+
+// AppRollback rolls an application back to a previous revision. A zero revision selects the
+// revision before the current one.
+func (c *EpinioClient) AppRollback(appName string, revision int) error {
+	log := c.Log.WithName("AppRollback").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName)
+
+	if revision > 0 {
+		msg = msg.WithStringValue("Revision", strconv.Itoa(revision))
+	} else {
+		msg = msg.WithStringValue("Revision", "previous")
+	}
+
+	msg.Msg("Rolling back application")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("rolling back application")
+
+	if err := c.API.AppRollback(c.Settings.Namespace, appName, revision); err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Application rolled back")
+
+	return nil
+}
+
+// AppRename renames the named app, in the targeted namespace.
+func (c *EpinioClient) AppRename(appName, newName string) error {
+	log := c.Log.WithName("AppRename").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		WithStringValue("New Name", newName).
+		Msg("Renaming application")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	errorMsgs := validation.IsDNS1123Subdomain(newName)
+	if len(errorMsgs) > 0 {
+		return fmt.Errorf("%s: %s", "app name incorrect", strings.Join(errorMsgs, "\n"))
+	}
+
+	log.V(1).Info("renaming application")
+
+	if err := c.API.AppRename(c.Settings.Namespace, appName, newName); err != nil {
+		return err
+	}
+
+	c.ui.Success().
+		WithStringValue("Name", newName).
+		Msg("Application renamed")
+
+	return nil
+}
+
+// AppCanaryPromote shifts traffic to the canary of the named app, in the targeted namespace.
+// A zero weight makes the canary the stable version of the app.
+func (c *EpinioClient) AppCanaryPromote(appName string, weight int) error {
+	log := c.Log.WithName("AppCanaryPromote").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName)
+
+	if weight > 0 && weight < 100 {
+		msg = msg.WithStringValue("Weight", fmt.Sprintf("%d%%", weight))
+	} else {
+		msg = msg.WithStringValue("Weight", "100% (stable)")
+	}
+
+	msg.Msg("Promoting application canary")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("promoting canary")
+
+	if err := c.API.AppCanaryPromote(c.Settings.Namespace, appName, weight); err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Application canary promoted")
+
+	return nil
+}
+
+// AppCanaryAbort removes the canary of the named app, in the targeted namespace
+func (c *EpinioClient) AppCanaryAbort(appName string) error {
+	log := c.Log.WithName("AppCanaryAbort").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Aborting application canary")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("aborting canary")
+
+	if err := c.API.AppCanaryAbort(c.Settings.Namespace, appName); err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Application canary aborted")
+
+	return nil
+}
+
+// AppRevisions lists the recorded deployment revisions of the named app, in the targeted namespace
+func (c *EpinioClient) AppRevisions(appName string) error {
+	log := c.Log.WithName("AppRevisions").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Listing application revisions")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	log.V(1).Info("list revisions")
+
+	revisions, err := c.API.AppRevisions(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	msg := c.ui.Success().WithTable("Revision", "Created", "User", "Result", "Origin", "Image", "Environment", "Configurations", "Hooks")
+
+	for _, revision := range revisions {
+		hooks := []string{}
+		for _, hook := range revision.Hooks {
+			hooks = append(hooks, fmt.Sprintf("%s: %s", hook.Name, hook.Result))
+		}
+
+		msg = msg.WithTableRow(
+			strconv.Itoa(revision.Number),
+			fmt.Sprintf("%v", revision.CreatedAt),
+			revision.Username,
+			revision.Result,
+			revision.Origin.String(),
+			revision.ImageURL,
+			revision.EnvHash,
+			strings.Join(revision.Configurations, ", "),
+			strings.Join(hooks, ", "),
+		)
+	}
+
+	msg.Msg("Revisions:")
+
+	return nil
+}
+
+// AppTasks lists the scheduled tasks of the named app, in the targeted namespace
+func (c *EpinioClient) AppTasks(appName string) error {
+	log := c.Log.WithName("AppTasks").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
 	log.Info("start")
 	defer log.Info("return")
-	details := log.V(1) // NOTE: Increment of level, not absolute.
 
 	c.ui.Note().
 		WithStringValue("Namespace", c.Settings.Namespace).
 		WithStringValue("Application", appName).
-		Msg("Show application details")
+		Msg("Listing application tasks")
 
 	if err := c.TargetOk(); err != nil {
 		return err
 	}
 
-	details.Info("show application")
+	log.V(1).Info("list tasks")
 
-	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	tasks, err := c.API.AppTasks(c.Settings.Namespace, appName)
 	if err != nil {
 		return err
 	}
 
-	if err := c.printAppDetails(app); err != nil {
-		return err
+	if len(tasks) == 0 {
+		c.ui.Exclamation().Msg("Application has no tasks")
+		return nil
 	}
 
-	return c.printReplicaDetails(app)
+	msg := c.ui.Success().WithTable("Name", "Schedule", "Command", "Last Run", "Active")
+
+	for _, task := range tasks {
+		lastRun := ""
+		if task.LastSchedule != nil {
+			lastRun = fmt.Sprintf("%v", *task.LastSchedule)
+		}
+		msg = msg.WithTableRow(
+			task.Name,
+			task.Schedule,
+			strings.Join(task.Command, " "),
+			lastRun,
+			strconv.Itoa(task.Active),
+		)
+	}
+
+	msg.Msg("Tasks:")
+
+	return nil
 }
 
-// AppExport saves the named app, in the targeted namespace, to the directory.
-func (c *EpinioClient) AppExport(appName string, directory string) error {
-	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+// AppTaskRun runs the named task of the named app now, in the targeted namespace
+func (c *EpinioClient) AppTaskRun(appName, taskName string) error {
+	log := c.Log.WithName("AppTaskRun").WithValues("Namespace", c.Settings.Namespace, "Application", appName, "Task", taskName)
 	log.Info("start")
 	defer log.Info("return")
-	details := log.V(1) // NOTE: Increment of level, not absolute.
 
 	c.ui.Note().
 		WithStringValue("Namespace", c.Settings.Namespace).
 		WithStringValue("Application", appName).
-		WithStringValue("Target Directory", directory).
-		Msg("Export application")
+		WithStringValue("Task", taskName).
+		Msg("Running application task")
 
 	if err := c.TargetOk(); err != nil {
 		return err
 	}
 
-	details.Info("export application")
+	log.V(1).Info("run task")
 
-	err := os.MkdirAll(directory, 0700)
+	resp, err := c.API.AppTaskRun(c.Settings.Namespace, appName, taskName)
 	if err != nil {
-		return errors.Wrapf(err, "failed to create export directory '%s'", directory)
+		return err
 	}
 
-	err = c.API.AppGetPart(c.Settings.Namespace, appName, "values", filepath.Join(directory, "values.yaml"))
-	if err != nil {
+	c.ui.Success().WithStringValue("Job", resp.Job).Msg("Application task started")
+
+	return nil
+}
+
+// AppDomains lists the custom domains of the named app, in the targeted namespace
+func (c *EpinioClient) AppDomains(appName string) error {
+	log := c.Log.WithName("AppDomains").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Listing application domains")
+
+	if err := c.TargetOk(); err != nil {
 		return err
 	}
 
-	err = c.API.AppGetPart(c.Settings.Namespace, appName, "chart", filepath.Join(directory, "app-chart.tar.gz"))
+	log.V(1).Info("list domains")
+
+	domains, err := c.API.AppDomains(c.Settings.Namespace, appName)
 	if err != nil {
 		return err
 	}
 
+	if len(domains) == 0 {
+		c.ui.Exclamation().Msg("Application has no custom domains")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("Domain", "Verified", "Certificate")
+
+	for _, domain := range domains {
+		msg = msg.WithTableRow(
+			domain.Domain,
+			strconv.FormatBool(domain.Verified),
+			domainCertificate(domain),
+		)
+	}
+
+	msg.Msg("Domains:")
+
 	return nil
 }
 
-// AppManifest saves the information of the named app, in the targeted namespace, into a manifest file
-func (c *EpinioClient) AppManifest(appName, manifestPath string) error {
-	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+// AppDomainAdd adds the custom domain to the named app, in the targeted namespace. An
+// unverified domain is reported with the TXT record to create for verifying its ownership.
+func (c *EpinioClient) AppDomainAdd(appName, domain string) error {
+	log := c.Log.WithName("AppDomainAdd").WithValues("Namespace", c.Settings.Namespace, "Application", appName, "Domain", domain)
 	log.Info("start")
 	defer log.Info("return")
-	details := log.V(1) // NOTE: Increment of level, not absolute.
 
 	c.ui.Note().
 		WithStringValue("Namespace", c.Settings.Namespace).
 		WithStringValue("Application", appName).
-		WithStringValue("Destination", manifestPath).
-		Msg("Save application details to manifest")
+		WithStringValue("Domain", domain).
+		Msg("Adding application domain")
 
 	if err := c.TargetOk(); err != nil {
 		return err
 	}
 
-	details.Info("show application")
+	log.V(1).Info("add domain")
 
-	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	status, err := c.API.AppDomainAdd(c.Settings.Namespace, appName, domain)
 	if err != nil {
 		return err
 	}
 
-	m := models.ApplicationManifest{}
-	m.Name = appName
-	m.Configuration = app.Configuration
-	m.Origin = app.Origin
+	if !status.Verified {
+		c.ui.Exclamation().
+			WithTable("Type", "Name", "Value").
+			WithTableRow("TXT", status.Record, status.Token).
+			Msg("Domain ownership not verified. Create this DNS record, then run the command again:")
+		return nil
+	}
 
-	yaml, err := yaml.Marshal(m)
-	if err != nil {
+	c.ui.Success().
+		WithStringValue("Domain", status.Domain).
+		WithStringValue("Certificate", status.Certificate).
+		Msg("Application domain added")
+
+	return nil
+}
+
+// AppDomainRemove removes the custom domain from the named app, in the targeted namespace
+func (c *EpinioClient) AppDomainRemove(appName, domain string) error {
+	log := c.Log.WithName("AppDomainRemove").WithValues("Namespace", c.Settings.Namespace, "Application", appName, "Domain", domain)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		WithStringValue("Domain", domain).
+		Msg("Removing application domain")
+
+	if err := c.TargetOk(); err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(manifestPath, yaml, 0600)
-	if err != nil {
+	log.V(1).Info("remove domain")
+
+	if err := c.API.AppDomainRemove(c.Settings.Namespace, appName, domain); err != nil {
 		return err
 	}
 
-	c.ui.Success().Msg("Saved")
+	c.ui.Success().Msg("Application domain removed")
 
 	return nil
 }
 
-// AppRestart restarts an application
-func (c *EpinioClient) AppRestart(appName string) error {
-	log := c.Log.WithName("AppRestart").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+// sortedRouteKeys returns the routes of the annotations, sorted.
+func sortedRouteKeys(annotations models.AppRouteAnnotations) []string {
+	result := []string{}
+	for route := range annotations {
+		result = append(result, route)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sortedAnnotationKeys returns the keys of the annotations, sorted.
+func sortedAnnotationKeys(annotations map[string]string) []string {
+	result := []string{}
+	for key := range annotations {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// domainCertificate returns the certificate state of the custom domain, for display. The
+// certificate of an unverified domain is not requested.
+func domainCertificate(domain models.AppDomainStatus) string {
+	if !domain.Verified {
+		return "-"
+	}
+	return domain.Certificate
+}
+
+// AppRouteUpdate changes the TLS secret of the named route of the named app, in the targeted
+// namespace. An empty secret returns the route to the certificate of the cluster issuer.
+func (c *EpinioClient) AppRouteUpdate(appName, route, tlsSecret string) error {
+	log := c.Log.WithName("AppRouteUpdate").WithValues("Namespace", c.Settings.Namespace, "Application", appName, "Route", route)
 	log.Info("start")
 	defer log.Info("return")
 
 	c.ui.Note().
 		WithStringValue("Namespace", c.Settings.Namespace).
 		WithStringValue("Application", appName).
-		Msg("Restarting application")
+		WithStringValue("Route", route).
+		WithStringValue("TLS Secret", tlsSecret).
+		Msg("Updating application route")
 
 	if err := c.TargetOk(); err != nil {
 		return err
 	}
 
-	log.V(1).Info("restarting application")
+	log.V(1).Info("show application")
 
-	return c.API.AppRestart(c.Settings.Namespace, appName)
+	app, err := c.API.AppShow(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	desiredRoutes, err := routeWithTLSSecret(app.Configuration.Routes, route, tlsSecret)
+	if err != nil {
+		return err
+	}
+
+	log.V(1).Info("update routes")
+
+	_, err = c.API.AppUpdate(models.ApplicationUpdateRequest{Routes: desiredRoutes},
+		c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Application route updated")
+
+	return nil
+}
+
+// routeWithTLSSecret returns the routes with the TLS secret of the route, matched by domain
+// and path, changed.
+func routeWithTLSSecret(desiredRoutes []string, route, tlsSecret string) ([]string, error) {
+	wanted := routes.FromString(route)
+
+	result := []string{}
+	found := false
+	for _, desired := range desiredRoutes {
+		r := routes.FromString(desired)
+		if r.Domain == wanted.Domain && r.Path == wanted.Path {
+			r.TLSSecret = tlsSecret
+			desired = r.String()
+			found = true
+		}
+		result = append(result, desired)
+	}
+
+	if !found {
+		return nil, errors.Errorf("application has no route '%s'", route)
+	}
+	return result, nil
 }
 
 // AppStageID returns the last stage id of the named app, in the targeted namespace
@@ -373,8 +1318,9 @@ func (c *EpinioClient) AppUpdate(appName string, appConfig models.ApplicationUpd
 // AppLogs streams the logs of all the application instances, in the targeted namespace
 // If stageID is an empty string, runtime application logs are streamed. If stageID
 // is set, then the matching staging logs are streamed.
+// The parameters restrict the streamed lines, see models.LogParameters.
 // The printLogs func will print the logs from the channel until the channel will be closed.
-func (c *EpinioClient) AppLogs(appName, stageID string, follow bool) error {
+func (c *EpinioClient) AppLogs(appName, stageID string, follow bool, params models.LogParameters) error {
 	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
 	log.Info("start")
 	defer log.Info("return")
@@ -401,7 +1347,7 @@ func (c *EpinioClient) AppLogs(appName, stageID string, follow bool) error {
 		}, c.ui.ProgressNote().Compact())
 	}
 
-	err := c.API.AppLogs(c.Settings.Namespace, appName, stageID, follow, callback)
+	err := c.API.AppLogs(c.Settings.Namespace, appName, stageID, follow, params, callback)
 	if err != nil {
 		c.ui.Problem().Msg(fmt.Sprintf("failed to tail logs: %s", err.Error()))
 		return err
@@ -410,6 +1356,48 @@ func (c *EpinioClient) AppLogs(appName, stageID string, follow bool) error {
 	return nil
 }
 
+// AppEvents streams the kubernetes events of the named application. When following, new
+// events are printed as they happen, until interrupted.
+func (c *EpinioClient) AppEvents(appName string, follow bool) error {
+	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		Msg("Streaming application events")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	details.Info("application events")
+
+	callback := func(event models.AppEvent) {
+		c.ui.ProgressNote().Compact().Msg(formatAppEvent(event))
+	}
+
+	err := c.API.AppEvents(c.Settings.Namespace, appName, follow, callback)
+	if err != nil {
+		c.ui.Problem().Msg(fmt.Sprintf("failed to stream events: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// formatAppEvent renders the event as a single line.
+func formatAppEvent(event models.AppEvent) string {
+	line := fmt.Sprintf("%s %-7s %s %s: %s",
+		event.Time.Format(time.RFC3339), event.Type, event.Object, event.Reason, event.Message)
+	if event.Count > 1 {
+		line += fmt.Sprintf(" (x%d)", event.Count)
+	}
+	return line
+}
+
 func (c *EpinioClient) AppExec(ctx context.Context, appName, instance string) error {
 	log := c.Log.WithName("Apps").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
 	log.Info("start")
@@ -503,7 +1491,109 @@ func (c *EpinioClient) Delete(ctx context.Context, appname string) error {
 	return nil
 }
 
-func (c *EpinioClient) printAppDetails(app models.App) error {
+// DeleteApps removes the named applications, or all applications of the targeted namespace,
+// in a single call to the server. It reports the outcome per application, and fails if any
+// of the deletions failed.
+func (c *EpinioClient) DeleteApps(appnames []string, all bool) error {
+	log := c.Log.WithName("DeleteApps").WithValues("Applications", appnames, "All", all)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().WithStringValue("Namespace", c.Settings.Namespace)
+	if all {
+		msg.Msg("Deleting all applications...")
+	} else {
+		msg.WithStringValue("Names", strings.Join(appnames, ", ")).Msg("Deleting applications...")
+	}
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	s := c.ui.Progressf("Deleting in %s", c.Settings.Namespace)
+	defer s.Stop()
+
+	response, err := c.API.AppBatchDelete(c.Settings.Namespace, models.BatchDeleteRequest{
+		Names: appnames,
+		All:   all,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Stop()
+	return c.batchDeleteResults("Application", response.Results)
+}
+
+// batchDeleteResults shows the results of a batch deletion, and returns an error if any of
+// the deletions failed.
+func (c *EpinioClient) batchDeleteResults(kind string, results []models.BatchDeleteResult) error {
+	if c.ui.Machine() {
+		if err := c.ui.Data(results); err != nil {
+			return err
+		}
+	} else {
+		msg := c.ui.Success().WithTable(kind, "Result", "Details")
+		for _, result := range results {
+			details := []string{}
+			if len(result.UnboundConfigurations) > 0 {
+				details = append(details, "unbound configurations: "+strings.Join(result.UnboundConfigurations, ", "))
+			}
+			if result.Error == "" {
+				if len(result.BoundApps) > 0 {
+					details = append(details, "unbound applications: "+strings.Join(result.BoundApps, ", "))
+				}
+				msg = msg.WithTableRow(result.Name, "deleted", strings.Join(details, "; "))
+				continue
+			}
+			details = append(details, result.Error)
+			msg = msg.WithTableRow(result.Name, "failed", strings.Join(details, "; "))
+		}
+		msg.Msg("Deletions done.")
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d deletions failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// tolerationString returns the toleration in the style of a taint, i.e. `key=value:effect`.
+func tolerationString(toleration models.AppToleration) string {
+	result := toleration.Key
+	if toleration.Operator == "Exists" {
+		if result == "" {
+			result = "*"
+		}
+	} else {
+		result += "=" + toleration.Value
+	}
+	if toleration.Effect != "" {
+		result += ":" + toleration.Effect
+	}
+	return result
+}
+
+// exposedPortAddress returns where the exposed port is reached, i.e. the node port, or the
+// address of the load balancer.
+func exposedPortAddress(port models.AppExposedPort) string {
+	if port.Expose == models.PortExposeLoadBalancer {
+		if port.Address == "" {
+			return "load balancer pending"
+		}
+		return fmt.Sprintf("%s:%d", port.Address, port.Port)
+	}
+	return fmt.Sprintf("node port %d", port.NodePort)
+}
+
+func (c *EpinioClient) printAppDetails(app models.App, domains models.AppDomainList) error {
 	msg := c.ui.Success().WithTable("Key", "Value").
 		WithTableRow("Origin", app.Origin.String()).
 		WithTableRow("Created", fmt.Sprintf("%v", app.Meta.CreatedAt))
@@ -515,7 +1605,11 @@ func (c *EpinioClient) printAppDetails(app models.App) error {
 		if err != nil {
 			return err
 		}
-		msg = msg.WithTableRow("Status", app.Workload.Status).
+		status := app.Workload.Status
+		if app.Status == models.ApplicationSleeping {
+			status = "sleeping"
+		}
+		msg = msg.WithTableRow("Status", status).
 			WithTableRow("Username", app.Workload.Username).
 			WithTableRow("Running StageId", app.Workload.StageID).
 			WithTableRow("Last StageId", app.StageID).
@@ -528,6 +1622,11 @@ func (c *EpinioClient) printAppDetails(app models.App) error {
 				msg = msg.WithTableRow("", r)
 			}
 		}
+
+		for _, port := range app.Workload.Ports {
+			msg = msg.WithTableRow(fmt.Sprintf("Exposed Port %d/%s", port.Port, port.Protocol),
+				exposedPortAddress(port))
+		}
 	} else {
 		if app.StageID == "" {
 			msg = msg.WithTableRow("Status", "not deployed")
@@ -544,11 +1643,135 @@ func (c *EpinioClient) printAppDetails(app models.App) error {
 		}
 	}
 
+	for _, route := range sortedRouteKeys(app.Configuration.RouteAnnotations) {
+		annotations := app.Configuration.RouteAnnotations[route]
+		for _, key := range sortedAnnotationKeys(annotations) {
+			msg = msg.WithTableRow(fmt.Sprintf("Annotation '%s'", route),
+				fmt.Sprintf("%s: %s", key, annotations[key]))
+		}
+	}
+
+	for _, domain := range domains {
+		state := "not verified"
+		if domain.Verified {
+			state = "certificate " + domain.Certificate
+		}
+		msg = msg.WithTableRow(fmt.Sprintf("Domain '%s'", domain.Domain), state)
+	}
+
+	if app.Staging.Dockerfile != "" {
+		msg = msg.WithTableRow("Dockerfile", app.Staging.Dockerfile)
+	} else if app.Staging.Builder != "" {
+		msg = msg.WithTableRow("Builder Image", app.Staging.Builder)
+		if len(app.Staging.Buildpacks) > 0 {
+			msg = msg.WithTableRow("Buildpacks", strings.Join(app.Staging.Buildpacks, ", "))
+		}
+	}
+	if app.Staging.Cache != "" {
+		msg = msg.WithTableRow("Build Cache", app.Staging.Cache)
+	}
+	for _, ev := range app.Staging.Environment.List() {
+		msg = msg.WithTableRow(fmt.Sprintf("Build Env '%s'", ev.Name), ev.Value)
+	}
+
+	msg = msg.
+		WithTableRow("App Chart", app.Configuration.AppChart)
+
+	for _, key := range sortedAnnotationKeys(app.Configuration.ChartValues) {
+		msg = msg.WithTableRow(fmt.Sprintf("Chart Value '%s'", key), app.Configuration.ChartValues[key])
+	}
+
+	msg = msg.
+		WithTableRow("Desired Instances", fmt.Sprintf("%d", *app.Configuration.Instances))
+
+	for _, name := range sortedProcessTypes(app.Configuration.Processes) {
+		msg = msg.WithTableRow(fmt.Sprintf("Process '%s'", name),
+			fmt.Sprintf("%d", app.Configuration.Processes[name]))
+	}
+
+	for _, port := range app.Configuration.Ports {
+		msg = msg.WithTableRow(fmt.Sprintf("Port %d/%s", port.Port, port.Protocol), port.Expose)
+	}
+
+	if app.Configuration.AutoSleep != nil {
+		msg = msg.WithTableRow("Auto Sleep", fmt.Sprintf("%dm idle", *app.Configuration.AutoSleep))
+	}
+
+	if app.Configuration.Rollout != nil {
+		msg = msg.
+			WithTableRow("Max Unavailable", app.Configuration.Rollout.MaxUnavailable).
+			WithTableRow("Max Surge", app.Configuration.Rollout.MaxSurge)
+	}
+
+	for _, volume := range app.Configuration.Volumes {
+		size := volume.Size
+		if size == "" {
+			size = models.VolumeSizeDefault
+		}
+		accessMode := volume.AccessMode
+		if accessMode == "" {
+			accessMode = "ReadWriteOnce"
+		}
+		msg = msg.WithTableRow(fmt.Sprintf("Volume '%s'", volume.Name),
+			fmt.Sprintf("%s (%s, %s)", volume.Path, size, accessMode))
+	}
+
+	if app.Configuration.Migration != nil {
+		msg = msg.WithTableRow("Migration", strings.Join(app.Configuration.Migration.Command, " "))
+	}
+
+	for _, hook := range app.Configuration.Hooks {
+		msg = msg.WithTableRow(fmt.Sprintf("Hook '%s' (%s)", hook.Name, hook.Phase), strings.Join(hook.Command, " "))
+	}
+
+	if pullSecret := app.Configuration.PullSecret; pullSecret != nil {
+		msg = msg.WithTableRow("Pull Secret", fmt.Sprintf("%s@%s", pullSecret.Username, pullSecret.Registry))
+	}
+
+	if entrypoint := app.Configuration.Entrypoint; entrypoint != nil {
+		if len(entrypoint.Command) > 0 {
+			msg = msg.WithTableRow("Command", strings.Join(entrypoint.Command, " "))
+		}
+		if len(entrypoint.Args) > 0 {
+			msg = msg.WithTableRow("Args", strings.Join(entrypoint.Args, " "))
+		}
+	}
+
+	if termination := app.Configuration.Termination; termination != nil {
+		if termination.GracePeriod > 0 {
+			msg = msg.WithTableRow("Termination Grace Period", fmt.Sprintf("%ds", termination.GracePeriod))
+		}
+		if len(termination.PreStop) > 0 {
+			msg = msg.WithTableRow("Pre-Stop", strings.Join(termination.PreStop, " "))
+		}
+	}
+
+	for _, sidecar := range app.Configuration.Sidecars {
+		msg = msg.WithTableRow(fmt.Sprintf("Sidecar '%s'", sidecar.Name), sidecar.Image)
+	}
+
+	if placement := app.Configuration.Placement; placement != nil {
+		for _, key := range sortedAnnotationKeys(placement.NodeSelector) {
+			msg = msg.WithTableRow("Node Selector", fmt.Sprintf("%s=%s", key, placement.NodeSelector[key]))
+		}
+		for _, toleration := range placement.Tolerations {
+			msg = msg.WithTableRow("Toleration", tolerationString(toleration))
+		}
+		for _, spread := range placement.TopologySpread {
+			msg = msg.WithTableRow("Topology Spread", spread.TopologyKey)
+		}
+	}
+
 	msg = msg.
-		WithTableRow("App Chart", app.Configuration.AppChart).
-		WithTableRow("Desired Instances", fmt.Sprintf("%d", *app.Configuration.Instances)).
-		WithTableRow("Bound Configurations", strings.Join(app.Configuration.Configurations, ", ")).
-		WithTableRow("Environment", "")
+		WithTableRow("Bound Configurations", strings.Join(app.Configuration.Configurations, ", "))
+
+	for _, name := range app.Configuration.Configurations {
+		if path, ok := app.Configuration.ConfigurationPaths[name]; ok {
+			msg = msg.WithTableRow(fmt.Sprintf("  - %s", name), path)
+		}
+	}
+
+	msg = msg.WithTableRow("Environment", "")
 
 	if len(app.Configuration.Environment) > 0 {
 		for _, ev := range app.Configuration.Environment.List() {
@@ -573,21 +1796,47 @@ func (c *EpinioClient) printReplicaDetails(app models.App) error {
 			if err != nil {
 				return err
 			}
+			memory, milliCPUs := "n/a", "n/a"
+			if app.Workload.MetricsSource != "" {
+				memory = withLimit(bytes.ByteCountIEC(r.MemoryBytes), r.MemoryLimitBytes, bytes.ByteCountIEC(r.MemoryLimitBytes))
+				milliCPUs = withLimit(strconv.Itoa(int(r.MilliCPUs)), r.MilliCPUsLimit, strconv.Itoa(int(r.MilliCPUsLimit)))
+			}
 			msg = msg.WithTableRow(
 				r.Name,
 				strconv.FormatBool(r.Ready),
-				bytes.ByteCountIEC(r.MemoryBytes),
-				strconv.Itoa(int(r.MilliCPUs)),
+				memory,
+				milliCPUs,
 				strconv.Itoa(int(r.Restarts)),
 				time.Since(createdAt).Round(time.Second).String(),
 			)
 		}
 		msg.Msg("Instances: ")
+
+		if app.Workload.MetricsSource == "" {
+			c.ui.Exclamation().Msg("No resource usage available, neither metrics server nor prometheus found")
+		}
 	}
 
 	return nil
 }
 
+// withLimit returns the usage, followed by the limit, if there is one.
+func withLimit(usage string, limit int64, limitStr string) string {
+	if limit == 0 {
+		return usage
+	}
+	return usage + " / " + limitStr
+}
+
+// appUsage returns the memory, cpu, and restarts of the application, for display in lists.
+func appUsage(workload *models.AppDeployment) (string, string, string) {
+	restarts := strconv.Itoa(int(workload.Restarts))
+	if workload.MetricsSource == "" {
+		return "n/a", "n/a", restarts
+	}
+	return bytes.ByteCountIEC(workload.MemoryBytes), strconv.Itoa(int(workload.MilliCPUs)), restarts
+}
+
 // AppRestage restage an application
 func (c *EpinioClient) AppRestage(appName string) error {
 	log := c.Log.WithName("AppRestage").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
@@ -626,5 +1875,5 @@ func (c *EpinioClient) AppRestage(appName string) error {
 	stageID := stageResponse.Stage.ID
 	log.V(1).Info("start tailing logs", "StageID", stageID)
 
-	return c.stageLogs(log.V(1), app.Meta, stageID)
+	return c.stageLogs(log.V(1), app.Meta, stageID, stageResponse.OperationID)
 }
@@ -1,9 +1,13 @@
 package usercmd_test
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/epinio/epinio/helpers/kubernetes/tailer"
 	"github.com/epinio/epinio/internal/cli/settings"
 	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/internal/manifest"
 	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	. "github.com/onsi/ginkgo/v2"
@@ -29,7 +33,7 @@ var _ = Describe("Client Apps unit tests", Label("wip"), func() {
 					return &models.StageResponse{Stage: models.NewStage("ID")}, nil
 				}
 
-				mockClient.mockAppLogs = func(namespace, appName, stageID string, follow bool, callback func(tailer.ContainerLogLine)) error {
+				mockClient.mockAppLogs = func(namespace, appName, stageID string, follow bool, params models.LogParameters, callback func(tailer.ContainerLogLine)) error {
 					return nil
 				}
 
@@ -47,6 +51,56 @@ var _ = Describe("Client Apps unit tests", Label("wip"), func() {
 			})
 		})
 
+		When("the server tracks the staging as operation", func() {
+			var mockClient *mockAPIClient
+
+			BeforeEach(func() {
+				mockClient = &mockAPIClient{}
+
+				mockClient.mockAppShow = func(namespace, appName string) (models.App, error) {
+					return *models.NewApp(appName, namespace), nil
+				}
+
+				mockClient.mockAppStage = func(req models.StageRequest) (*models.StageResponse, error) {
+					return &models.StageResponse{Stage: models.NewStage("ID"), OperationID: "OP"}, nil
+				}
+
+				mockClient.mockAppLogs = func(namespace, appName, stageID string, follow bool, params models.LogParameters, callback func(tailer.ContainerLogLine)) error {
+					return nil
+				}
+
+				mockClient.mockStagingComplete = func(namespace, id string) (models.Response, error) {
+					panic("called StagingComplete!")
+				}
+			})
+
+			It("waits for the operation", func() {
+				mockClient.mockOperationShow = func(id string) (models.Operation, error) {
+					Expect(id).To(Equal("OP"))
+					return models.Operation{ID: id, Phase: models.OperationSucceeded}, nil
+				}
+
+				epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = epinioClient.AppRestage("appname")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("fails with the error of the failed operation", func() {
+				mockClient.mockOperationShow = func(id string) (models.Operation, error) {
+					return models.Operation{ID: id, Phase: models.OperationFailed,
+						Error: "Failed to stage", ErrorDetails: "stage-id = ID"}, nil
+				}
+
+				epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = epinioClient.AppRestage("appname")
+				Expect(err).To(MatchError(ContainSubstring("Failed to stage: stage-id = ID")))
+			})
+		})
+
 		When("restaging a container-based app", func() {
 			var mockClient *mockAPIClient
 
@@ -73,24 +127,381 @@ var _ = Describe("Client Apps unit tests", Label("wip"), func() {
 			})
 		})
 	})
+
+	Describe("AppManifest", func() {
+		var mockClient *mockAPIClient
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "epinio-manifest")
+			Expect(err).ToNot(HaveOccurred())
+
+			mockClient = &mockAPIClient{}
+			mockClient.mockAppShow = func(namespace, appName string) (models.App, error) {
+				instances := int32(2)
+				app := models.NewApp(appName, namespace)
+				app.Configuration = models.ApplicationUpdateRequest{
+					Instances:      &instances,
+					Configurations: []string{"db"},
+					Environment:    models.EnvVariableMap{"CREDO": "up"},
+					Routes:         []string{"app.example.com/api"},
+					AppChart:       "standard",
+					Processes:      map[string]int32{"worker": 1},
+				}
+				app.Origin = models.ApplicationOrigin{
+					Kind: models.OriginGit,
+					Git:  &models.GitRef{URL: "https://example.com/app.git", Revision: "main"},
+				}
+				app.Staging = models.ApplicationStage{Builder: "paketobuildpacks/builder:full"}
+				return *app, nil
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("saves a manifest which pushes the app as it is", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			path := filepath.Join(dir, "app.yml")
+			err = epinioClient.AppManifest("appname", path)
+			Expect(err).ToNot(HaveOccurred())
+
+			m, err := manifest.Get(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			app, _ := mockClient.mockAppShow("workspace", "appname")
+			expected := usercmd.AppToManifest(app)
+			expected.Self = path
+
+			Expect(m).To(Equal(expected))
+		})
+	})
+
+	Describe("DeleteApps", func() {
+		var mockClient *mockAPIClient
+		var requested models.BatchDeleteRequest
+
+		BeforeEach(func() {
+			mockClient = &mockAPIClient{}
+			mockClient.mockAppBatchDelete = func(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+				requested = req
+				results := []models.BatchDeleteResult{}
+				for _, name := range req.Names {
+					result := models.BatchDeleteResult{Name: name}
+					if name == "missing" {
+						result.Error = "application 'missing' does not exist"
+					}
+					results = append(results, result)
+				}
+				return models.BatchDeleteResponse{Results: results}, nil
+			}
+		})
+
+		It("deletes the named applications in one call", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.DeleteApps([]string{"a", "b"}, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requested).To(Equal(models.BatchDeleteRequest{Names: []string{"a", "b"}}))
+		})
+
+		It("fails when any deletion failed", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.DeleteApps([]string{"a", "missing"}, false)
+			Expect(err).To(MatchError("1 of 2 deletions failed"))
+		})
+	})
+
+	Describe("RestartApps", func() {
+		var mockClient *mockAPIClient
+		var requested models.BatchRestartRequest
+
+		BeforeEach(func() {
+			mockClient = &mockAPIClient{}
+			mockClient.mockAppBatchRestart = func(namespace string, req models.BatchRestartRequest) (models.BatchRestartResponse, error) {
+				requested = req
+				results := []models.BatchRestartResult{}
+				for _, name := range req.Names {
+					result := models.BatchRestartResult{Name: name}
+					if name == "inactive" {
+						result.Error = "No restart possible for an application without workload"
+					}
+					results = append(results, result)
+				}
+				return models.BatchRestartResponse{Results: results}, nil
+			}
+		})
+
+		It("restarts all applications in one call", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.RestartApps(nil, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requested).To(Equal(models.BatchRestartRequest{All: true}))
+		})
+
+		It("fails when any restart failed", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.RestartApps([]string{"a", "inactive", "b"}, false)
+			Expect(err).To(MatchError("1 of 3 restarts failed"))
+		})
+	})
+
+	Describe("AppCopy", func() {
+		var mockClient *mockAPIClient
+		var created models.ApplicationCreateRequest
+		var createdIn string
+		var deployed models.DeployRequest
+
+		BeforeEach(func() {
+			mockClient = &mockAPIClient{}
+			mockClient.mockAppShow = func(namespace, appName string) (models.App, error) {
+				app := models.NewApp(appName, namespace)
+				app.ImageURL = "registry.example.com/apps/appname:1"
+				app.Configuration = models.ApplicationUpdateRequest{
+					Configurations:     []string{"db", "cache"},
+					ConfigurationPaths: map[string]string{"db": "/db"},
+					Environment:        models.EnvVariableMap{"MODE": "staging", "CREDO": "up"},
+					Routes:             []string{"appname.staging.example.com"},
+					RouteAnnotations: models.AppRouteAnnotations{
+						"appname.staging.example.com": {"timeout": "30"},
+					},
+					Sidecars: []models.AppSidecar{{
+						Name:   "proxy",
+						Image:  "proxy:1",
+						Mounts: []models.AppSidecarMount{{Configuration: "db", Path: "/secrets"}},
+					}},
+				}
+				return *app, nil
+			}
+			mockClient.mockAppCreate = func(req models.ApplicationCreateRequest, namespace string) (models.Response, error) {
+				created = req
+				createdIn = namespace
+				return models.Response{}, nil
+			}
+			mockClient.mockAppDeploy = func(req models.DeployRequest) (*models.DeployResponse, error) {
+				deployed = req
+				return &models.DeployResponse{Routes: []string{"appname.prod.example.com"}}, nil
+			}
+		})
+
+		It("deploys the image in the other namespace, with translated bindings", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "staging"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.AppCopy("appname", "prod",
+				map[string]string{"db": "prod-db"},
+				models.EnvVariableMap{"MODE": "production"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(createdIn).To(Equal("prod"))
+			Expect(created.Name).To(Equal("appname"))
+			Expect(created.Configuration.Configurations).To(Equal([]string{"prod-db", "cache"}))
+			Expect(created.Configuration.ConfigurationPaths).To(Equal(map[string]string{"prod-db": "/db"}))
+			Expect(created.Configuration.Sidecars[0].Mounts[0].Configuration).To(Equal("prod-db"))
+			Expect(created.Configuration.Environment).To(Equal(models.EnvVariableMap{"MODE": "production", "CREDO": "up"}))
+			Expect(created.Configuration.Routes).To(BeEmpty())
+			Expect(created.Configuration.RouteAnnotations).To(BeNil())
+
+			Expect(deployed.App).To(Equal(models.NewAppRef("appname", "prod")))
+			Expect(deployed.ImageURL).To(Equal("registry.example.com/apps/appname:1"))
+			Expect(deployed.Origin.Kind).To(Equal(models.OriginContainer))
+			Expect(deployed.Stage.ID).To(BeEmpty())
+		})
+
+		It("rejects binds for configurations not bound to the app", func() {
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "staging"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.AppCopy("appname", "prod", map[string]string{"queue": "prod-queue"}, nil)
+			Expect(err).To(MatchError("configuration 'queue' is not bound to the application"))
+		})
+
+		It("rejects apps without image", func() {
+			mockClient.mockAppShow = func(namespace, appName string) (models.App, error) {
+				return *models.NewApp(appName, namespace), nil
+			}
+
+			epinioClient, err := usercmd.NewEpinioClient(&settings.Settings{Namespace: "staging"}, mockClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = epinioClient.AppCopy("appname", "prod", nil, nil)
+			Expect(err).To(MatchError(ContainSubstring("no image to copy")))
+		})
+	})
 })
 
 type mockAPIClient struct {
 	mockAppShow         func(namespace string, appName string) (models.App, error)
 	mockAppStage        func(req models.StageRequest) (*models.StageResponse, error)
-	mockAppLogs         func(namespace, appName, stageID string, follow bool, callback func(tailer.ContainerLogLine)) error
+	mockAppLogs         func(namespace, appName, stageID string, follow bool, params models.LogParameters, callback func(tailer.ContainerLogLine)) error
 	mockStagingComplete func(namespace string, id string) (models.Response, error)
+	mockAppCreate       func(req models.ApplicationCreateRequest, namespace string) (models.Response, error)
+	mockAppDeploy       func(req models.DeployRequest) (*models.DeployResponse, error)
+	mockOperationShow   func(id string) (models.Operation, error)
+	mockApps            func(namespace string) (models.AppList, error)
+	mockAppBatchDelete  func(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error)
+	mockAppBatchRestart func(namespace string, req models.BatchRestartRequest) (models.BatchRestartResponse, error)
+
+	mockConfigurations     func(namespace string) (models.ConfigurationResponseList, error)
+	mockConfigurationShow  func(namespace string, name string) (models.ConfigurationResponse, error)
+	mockServiceCatalog     func() (*models.ServiceCatalogResponse, error)
+	mockServiceCatalogShow func(serviceName string) (*models.ServiceCatalogShowResponse, error)
+	mockChartList          func() ([]models.AppChart, error)
 }
 
 func (m *mockAPIClient) AuthToken() (string, error) {
 	return "", nil
 }
 
+func (m *mockAPIClient) APITokens() (models.APITokenList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) APITokenCreate(req models.APITokenCreateRequest) (models.APITokenCreateResponse, error) {
+	return models.APITokenCreateResponse{}, nil
+}
+
+func (m *mockAPIClient) APITokenDelete(id string) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) AuditEntries(user, namespace string, limit int) (models.AuditEntryList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) Events(namespace string, types []string, callback func(models.PlatformEvent)) error {
+	return nil
+}
+
+func (m *mockAPIClient) SessionCreate(user, password string) (models.SessionResponse, error) {
+	return models.SessionResponse{}, nil
+}
+
+func (m *mockAPIClient) SessionRefresh(refreshToken string) (models.SessionResponse, error) {
+	return models.SessionResponse{}, nil
+}
+
+func (m *mockAPIClient) SessionDelete() (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) ClientCertificateCreate(req models.ClientCertificateRequest) (models.ClientCertificateResponse, error) {
+	return models.ClientCertificateResponse{}, nil
+}
+
+func (m *mockAPIClient) UserRoleSet(username string, req models.UserRoleRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) PasswordChange(req models.PasswordChangeRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) UserPasswordReset(username string, req models.PasswordResetRequest) (models.PasswordResetResponse, error) {
+	return models.PasswordResetResponse{}, nil
+}
+
+func (m *mockAPIClient) UserFederationSet(username string, req models.UserFederationRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) Teams() (models.TeamList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) TeamSet(name string, req models.TeamRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) TeamDelete(name string) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) TeamRoleSet(name string, req models.UserRoleRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) Webhooks() (models.WebhookList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) WebhookSet(name string, req models.WebhookRequest) (models.WebhookSetResponse, error) {
+	return models.WebhookSetResponse{}, nil
+}
+
+func (m *mockAPIClient) OperationShow(id string) (models.Operation, error) {
+	if m.mockOperationShow != nil {
+		return m.mockOperationShow(id)
+	}
+	return models.Operation{}, nil
+}
+
+func (m *mockAPIClient) WebhookDelete(name string) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) Backups() (models.BackupList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) BackupCreate(req models.BackupCreateRequest) (models.Backup, error) {
+	return models.Backup{}, nil
+}
+
+func (m *mockAPIClient) BackupRestore(name string) (models.BackupRestoreResponse, error) {
+	return models.BackupRestoreResponse{}, nil
+}
+
+func (m *mockAPIClient) StateExport() (models.PlatformState, error) {
+	return models.PlatformState{}, nil
+}
+
+func (m *mockAPIClient) StateUserImport(req models.UserImportRequest) (models.UserImportResponse, error) {
+	return models.UserImportResponse{}, nil
+}
+
+func (m *mockAPIClient) Notifications(namespace string) (models.NotificationTargetList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) NotificationSet(namespace, name string, req models.NotificationTargetRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) NotificationDelete(namespace, name string) (models.Response, error) {
+	return models.Response{}, nil
+}
+
 func (m *mockAPIClient) AppCreate(req models.ApplicationCreateRequest, namespace string) (models.Response, error) {
+	if m.mockAppCreate != nil {
+		return m.mockAppCreate(req, namespace)
+	}
 	return models.Response{}, nil
 }
 
+func (m *mockAPIClient) AppApply(req models.ApplicationApplyRequest, namespace string) (models.ApplicationApplyResponse, error) {
+	return models.ApplicationApplyResponse{}, nil
+}
+
+func (m *mockAPIClient) AppAdopt(req models.AppAdoptRequest, namespace string) (models.AppAdoptResponse, error) {
+	return models.AppAdoptResponse{}, nil
+}
+
 func (m *mockAPIClient) Apps(namespace string) (models.AppList, error) {
+	if m.mockApps != nil {
+		return m.mockApps(namespace)
+	}
 	return models.AppList{}, nil
 }
 
@@ -114,7 +525,18 @@ func (m *mockAPIClient) AppDelete(namespace string, name string) (models.Applica
 	return models.ApplicationDeleteResponse{}, nil
 }
 
-func (m *mockAPIClient) AppUpload(namespace string, name string, tarball string) (models.UploadResponse, error) {
+func (m *mockAPIClient) AppBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+	if m.mockAppBatchDelete != nil {
+		return m.mockAppBatchDelete(namespace, req)
+	}
+	return models.BatchDeleteResponse{}, nil
+}
+
+func (m *mockAPIClient) AppUpload(namespace string, name string, tarball string, progress epinioapi.UploadProgress) (models.UploadResponse, error) {
+	return models.UploadResponse{}, nil
+}
+
+func (m *mockAPIClient) AppUploadDelta(namespace, name, dir string, progress epinioapi.UploadProgress) (models.UploadResponse, error) {
 	return models.UploadResponse{}, nil
 }
 
@@ -127,17 +549,28 @@ func (m *mockAPIClient) AppStage(req models.StageRequest) (*models.StageResponse
 }
 
 func (m *mockAPIClient) AppDeploy(req models.DeployRequest) (*models.DeployResponse, error) {
+	if m.mockAppDeploy != nil {
+		return m.mockAppDeploy(req)
+	}
 	return nil, nil
 }
 
-func (m *mockAPIClient) AppLogs(namespace, appName, stageID string, follow bool, callback func(tailer.ContainerLogLine)) error {
-	return m.mockAppLogs(namespace, appName, stageID, follow, callback)
+func (m *mockAPIClient) AppDeployAsync(req models.DeployRequest) (*models.DeployResponse, error) {
+	return m.AppDeploy(req)
+}
+
+func (m *mockAPIClient) AppLogs(namespace, appName, stageID string, follow bool, params models.LogParameters, callback func(tailer.ContainerLogLine)) error {
+	return m.mockAppLogs(namespace, appName, stageID, follow, params, callback)
 }
 
 func (m *mockAPIClient) StagingComplete(namespace string, id string) (models.Response, error) {
 	return m.mockStagingComplete(namespace, id)
 }
 
+func (m *mockAPIClient) StagingQueue(namespace string, id string) (models.StagingQueueResponse, error) {
+	return models.StagingQueueResponse{}, nil
+}
+
 func (m *mockAPIClient) AppRunning(app models.AppRef) (models.Response, error) {
 	return models.Response{}, nil
 }
@@ -154,6 +587,65 @@ func (m *mockAPIClient) AppRestart(namespace string, appName string) error {
 	return nil
 }
 
+func (m *mockAPIClient) AppBatchRestart(namespace string, req models.BatchRestartRequest) (models.BatchRestartResponse, error) {
+	if m.mockAppBatchRestart != nil {
+		return m.mockAppBatchRestart(namespace, req)
+	}
+	return models.BatchRestartResponse{}, nil
+}
+
+func (m *mockAPIClient) AppRollback(namespace string, appName string, revision int) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppRename(namespace string, appName string, newName string) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppSleep(namespace string, appName string) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppWake(namespace string, appName string) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppRevisions(namespace string, appName string) (models.AppRevisionList, error) {
+	return models.AppRevisionList{}, nil
+}
+
+func (m *mockAPIClient) AppCanaryPromote(namespace string, appName string, weight int) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppTasks(namespace string, appName string) (models.AppTaskList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) AppTaskRun(namespace string, appName string, taskName string) (models.AppTaskRunResponse, error) {
+	return models.AppTaskRunResponse{}, nil
+}
+
+func (m *mockAPIClient) AppEvents(namespace, appName string, follow bool, callback func(models.AppEvent)) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppDomains(namespace string, appName string) (models.AppDomainList, error) {
+	return nil, nil
+}
+
+func (m *mockAPIClient) AppDomainAdd(namespace string, appName string, domain string) (models.AppDomainStatus, error) {
+	return models.AppDomainStatus{}, nil
+}
+
+func (m *mockAPIClient) AppDomainRemove(namespace string, appName string, domain string) error {
+	return nil
+}
+
+func (m *mockAPIClient) AppCanaryAbort(namespace string, appName string) error {
+	return nil
+}
+
 func (m *mockAPIClient) EnvList(namespace string, appName string) (models.EnvVariableMap, error) {
 	return models.EnvVariableMap{}, nil
 }
@@ -178,6 +670,10 @@ func (m *mockAPIClient) Info() (models.InfoResponse, error) {
 	return models.InfoResponse{}, nil
 }
 
+func (m *mockAPIClient) OIDCConfig() (models.OIDCConfigResponse, error) {
+	return models.OIDCConfigResponse{}, nil
+}
+
 func (m *mockAPIClient) NamespaceCreate(req models.NamespaceCreateRequest) (models.Response, error) {
 	return models.Response{}, nil
 }
@@ -186,10 +682,30 @@ func (m *mockAPIClient) NamespaceDelete(namespace string) (models.Response, erro
 	return models.Response{}, nil
 }
 
+func (m *mockAPIClient) NamespaceUpdate(namespace string, req models.NamespaceUpdateRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) NamespacePodSecuritySet(namespace string, req models.NamespacePodSecurityRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) NamespaceQuotaSet(namespace string, req models.NamespaceQuotaRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
+func (m *mockAPIClient) NamespaceUploadLimitSet(namespace string, req models.NamespaceUploadLimitRequest) (models.Response, error) {
+	return models.Response{}, nil
+}
+
 func (m *mockAPIClient) NamespaceShow(namespace string) (models.Namespace, error) {
 	return models.Namespace{}, nil
 }
 
+func (m *mockAPIClient) NamespaceExport(namespace string, secrets bool) ([]byte, error) {
+	return nil, nil
+}
+
 func (m *mockAPIClient) NamespacesMatch(prefix string) (models.NamespacesMatchResponse, error) {
 	return models.NamespacesMatchResponse{}, nil
 }
@@ -199,6 +715,9 @@ func (m *mockAPIClient) Namespaces() (models.NamespaceList, error) {
 }
 
 func (m *mockAPIClient) Configurations(namespace string) (models.ConfigurationResponseList, error) {
+	if m.mockConfigurations != nil {
+		return m.mockConfigurations(namespace)
+	}
 	return models.ConfigurationResponseList{}, nil
 }
 
@@ -218,6 +737,10 @@ func (m *mockAPIClient) ConfigurationDelete(req models.ConfigurationDeleteReques
 	return models.ConfigurationDeleteResponse{}, nil
 }
 
+func (m *mockAPIClient) ConfigurationBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+	return models.BatchDeleteResponse{}, nil
+}
+
 func (m *mockAPIClient) ConfigurationCreate(req models.ConfigurationCreateRequest, namespace string) (models.Response, error) {
 	return models.Response{}, nil
 }
@@ -227,6 +750,9 @@ func (m *mockAPIClient) ConfigurationUpdate(req models.ConfigurationUpdateReques
 }
 
 func (m *mockAPIClient) ConfigurationShow(namespace string, name string) (models.ConfigurationResponse, error) {
+	if m.mockConfigurationShow != nil {
+		return m.mockConfigurationShow(namespace, name)
+	}
 	return models.ConfigurationResponse{}, nil
 }
 
@@ -235,10 +761,16 @@ func (m *mockAPIClient) ConfigurationApps(namespace string) (models.Configuratio
 }
 
 func (m *mockAPIClient) ServiceCatalog() (*models.ServiceCatalogResponse, error) {
+	if m.mockServiceCatalog != nil {
+		return m.mockServiceCatalog()
+	}
 	return nil, nil
 }
 
 func (m *mockAPIClient) ServiceCatalogShow(serviceName string) (*models.ServiceCatalogShowResponse, error) {
+	if m.mockServiceCatalogShow != nil {
+		return m.mockServiceCatalogShow(serviceName)
+	}
 	return nil, nil
 }
 
@@ -250,8 +782,12 @@ func (m *mockAPIClient) ServiceDelete(req models.ServiceDeleteRequest, namespace
 	return models.ServiceDeleteResponse{}, nil
 }
 
-func (m *mockAPIClient) ServiceCreate(req *models.ServiceCreateRequest, namespace string) error {
-	return nil
+func (m *mockAPIClient) ServiceBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+	return models.BatchDeleteResponse{}, nil
+}
+
+func (m *mockAPIClient) ServiceCreate(req *models.ServiceCreateRequest, namespace string) (models.ServiceCreateResponse, error) {
+	return models.ServiceCreateResponse{}, nil
 }
 
 func (m *mockAPIClient) ServiceBind(req *models.ServiceBindRequest, namespace, releaseName string) error {
@@ -267,6 +803,9 @@ func (m *mockAPIClient) ServiceList(namespace string) (*models.ServiceListRespon
 }
 
 func (m *mockAPIClient) ChartList() ([]models.AppChart, error) {
+	if m.mockChartList != nil {
+		return m.mockChartList()
+	}
 	return []models.AppChart{}, nil
 }
 
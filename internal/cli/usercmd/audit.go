@@ -0,0 +1,53 @@
+package usercmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/fatih/color"
+)
+
+// AuditList lists the recent audit entries, of the user in the namespace
+func (c *EpinioClient) AuditList(user, namespace string, limit int) error {
+	log := c.Log.WithName("AuditList").WithValues("User", user, "Namespace", namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().Msg("Listing audit entries")
+
+	entries, err := c.API.AuditEntries(user, namespace, limit)
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(entries)
+	}
+
+	if len(entries) == 0 {
+		c.ui.Exclamation().Msg("No audit entries found")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("Time", "User", "Verb", "Resource", "Namespace", "Status")
+
+	for _, entry := range entries {
+		status := strconv.Itoa(entry.Status)
+		if entry.Result != models.AuditResultSuccess {
+			status = color.RedString(status)
+		}
+		msg = msg.WithTableRow(
+			entry.Time.Local().Format(time.RFC1123),
+			entry.User,
+			entry.Verb,
+			entry.Resource,
+			entry.Namespace,
+			status,
+		)
+	}
+
+	msg.Msg("Epinio audit log:")
+
+	return nil
+}
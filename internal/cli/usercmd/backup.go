@@ -0,0 +1,124 @@
+package usercmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// BackupCreate backs up the platform state, in a backup of the name, if any
+func (c *EpinioClient) BackupCreate(name string) error {
+	log := c.Log.WithName("BackupCreate").WithValues("Backup", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Name", name).
+		Msg("Backing up the platform state...")
+
+	backup, err := c.API.BackupCreate(models.BackupCreateRequest{Name: name})
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(backup)
+	}
+
+	c.ui.Success().
+		WithStringValue("Name", backup.Name).
+		WithStringValue("Namespaces", strings.Join(backup.Namespaces, ", ")).
+		WithStringValue("Resources", backupCounts(backup.Counts)).
+		WithStringValue("Images", strconv.Itoa(len(backup.Registry.Images))).
+		Msg("Backup created.")
+
+	return nil
+}
+
+// BackupList lists the backups
+func (c *EpinioClient) BackupList() error {
+	log := c.Log.WithName("BackupList")
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().Msg("Listing backups")
+
+	backups, err := c.API.Backups()
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(backups)
+	}
+
+	if len(backups) == 0 {
+		c.ui.Exclamation().Msg("No backups found")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("Name", "Created", "Version", "Namespaces", "Resources")
+
+	for _, backup := range backups {
+		msg = msg.WithTableRow(
+			backup.Name,
+			backup.CreatedAt.Local().Format(time.RFC1123),
+			backup.Version,
+			strings.Join(backup.Namespaces, ", "),
+			backupCounts(backup.Counts),
+		)
+	}
+
+	msg.Msg("Epinio backups:")
+
+	return nil
+}
+
+// BackupRestore restores the platform state from the backup
+func (c *EpinioClient) BackupRestore(name string) error {
+	log := c.Log.WithName("BackupRestore").WithValues("Backup", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Name", name).
+		Msg("Restoring the platform state...")
+
+	resp, err := c.API.BackupRestore(name)
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(resp)
+	}
+
+	for _, skipped := range resp.Skipped {
+		c.ui.Exclamation().Msg(fmt.Sprintf("Skipped %s, it exists already", skipped))
+	}
+	for _, warning := range resp.Warnings {
+		c.ui.Exclamation().Msg(warning)
+	}
+
+	c.ui.Success().
+		WithStringValue("Created", backupCounts(resp.Created)).
+		WithStringValue("Deployed", strings.Join(resp.Deployed, ", ")).
+		Msg("Backup restored.")
+
+	return nil
+}
+
+// backupCounts returns the counts of the resources of a backup as a single line, sorted by
+// part.
+func backupCounts(counts map[string]int) string {
+	parts := []string{}
+	for part, count := range counts {
+		parts = append(parts, fmt.Sprintf("%s: %d", part, count))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
@@ -3,7 +3,10 @@ package usercmd
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 )
@@ -69,10 +72,15 @@ func (c *EpinioClient) ChartList(ctx context.Context) error {
 	c.ui.Note().
 		Msg("Show Application Charts")
 
-	charts, err := c.API.ChartList()
+	charts, received, err := c.charts()
 	if err != nil {
 		return err
 	}
+	c.staleWarning(received)
+
+	if c.ui.Machine() {
+		return c.ui.Data(charts)
+	}
 
 	msg := c.ui.Success().WithTable("Default", "Name", "Created", "Description")
 
@@ -119,20 +127,53 @@ func (c *EpinioClient) ChartShow(ctx context.Context, name string) error {
 		WithTableRow("Helm Chart", chart.HelmChart).
 		Msg("Details:")
 
+	if len(chart.Settings) == 0 {
+		return nil
+	}
+
+	settingNames := []string{}
+	for name := range chart.Settings {
+		settingNames = append(settingNames, name)
+	}
+	sort.Strings(settingNames)
+
+	msg := c.ui.Success().WithTable("Setting", "Type", "Allowed Values")
+	for _, name := range settingNames {
+		setting := chart.Settings[name]
+		msg = msg.WithTableRow(name, setting.Type, settingRange(setting))
+	}
+	msg.Msg("Settings:")
+
 	return nil
 }
 
+// settingRange returns a description of the values allowed for the app chart setting.
+func settingRange(setting models.AppChartSetting) string {
+	switch {
+	case len(setting.Enum) > 0:
+		return strings.Join(setting.Enum, ", ")
+	case setting.Minimum != "" && setting.Maximum != "":
+		return fmt.Sprintf("%s to %s", setting.Minimum, setting.Maximum)
+	case setting.Minimum != "":
+		return fmt.Sprintf(">= %s", setting.Minimum)
+	case setting.Maximum != "":
+		return fmt.Sprintf("<= %s", setting.Maximum)
+	}
+	return ""
+}
+
 // ChartMatching retrieves all application charts in the cluster, for the given prefix
 func (c *EpinioClient) ChartMatching(prefix string) []string {
-	log := c.Log.WithName("ChartMatching")
-	log.Info("start")
-	defer log.Info("return")
-
-	resp, err := c.API.ChartMatch(prefix)
-	if err != nil {
-		log.Error(err, "calling chart match endpoint")
-		return []string{}
-	}
+	return c.matching("charts", "", prefix, func() ([]string, error) {
+		charts, _, err := c.charts()
+		if err != nil {
+			return nil, err
+		}
 
-	return resp.Names
+		names := []string{}
+		for _, chart := range charts {
+			names = append(names, chart.Meta.Name)
+		}
+		return names, nil
+	})
 }
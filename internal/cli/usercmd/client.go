@@ -2,6 +2,8 @@
 package usercmd
 
 import (
+	"context"
+
 	"github.com/epinio/epinio/helpers/kubernetes/tailer"
 	"github.com/epinio/epinio/helpers/termui"
 	"github.com/epinio/epinio/helpers/tracelog"
@@ -9,6 +11,7 @@ import (
 	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 	kubectlterm "k8s.io/kubectl/pkg/util/term"
 
 	"github.com/go-logr/logr"
@@ -25,23 +28,78 @@ type EpinioClient struct {
 
 type APIClient interface {
 	AuthToken() (string, error)
+	AuditEntries(user, namespace string, limit int) (models.AuditEntryList, error)
+	Events(namespace string, types []string, callback func(models.PlatformEvent)) error
+	// sessions
+	SessionCreate(user, password string) (models.SessionResponse, error)
+	SessionRefresh(refreshToken string) (models.SessionResponse, error)
+	SessionDelete() (models.Response, error)
+	ClientCertificateCreate(req models.ClientCertificateRequest) (models.ClientCertificateResponse, error)
+	// api tokens
+	APITokens() (models.APITokenList, error)
+	APITokenCreate(req models.APITokenCreateRequest) (models.APITokenCreateResponse, error)
+	APITokenDelete(id string) (models.Response, error)
+	// users
+	UserRoleSet(username string, req models.UserRoleRequest) (models.Response, error)
+	PasswordChange(req models.PasswordChangeRequest) (models.Response, error)
+	UserPasswordReset(username string, req models.PasswordResetRequest) (models.PasswordResetResponse, error)
+	UserFederationSet(username string, req models.UserFederationRequest) (models.Response, error)
+	// teams
+	Teams() (models.TeamList, error)
+	TeamSet(name string, req models.TeamRequest) (models.Response, error)
+	TeamDelete(name string) (models.Response, error)
+	TeamRoleSet(name string, req models.UserRoleRequest) (models.Response, error)
+	// webhooks
+	Webhooks() (models.WebhookList, error)
+	WebhookSet(name string, req models.WebhookRequest) (models.WebhookSetResponse, error)
+	WebhookDelete(name string) (models.Response, error)
+	Notifications(namespace string) (models.NotificationTargetList, error)
+	NotificationSet(namespace, name string, req models.NotificationTargetRequest) (models.Response, error)
+	NotificationDelete(namespace, name string) (models.Response, error)
+	OperationShow(id string) (models.Operation, error)
+	// backups
+	Backups() (models.BackupList, error)
+	BackupCreate(req models.BackupCreateRequest) (models.Backup, error)
+	BackupRestore(name string) (models.BackupRestoreResponse, error)
+	StateExport() (models.PlatformState, error)
+	StateUserImport(req models.UserImportRequest) (models.UserImportResponse, error)
 	// app
 	AppCreate(req models.ApplicationCreateRequest, namespace string) (models.Response, error)
+	AppApply(req models.ApplicationApplyRequest, namespace string) (models.ApplicationApplyResponse, error)
+	AppAdopt(req models.AppAdoptRequest, namespace string) (models.AppAdoptResponse, error)
 	Apps(namespace string) (models.AppList, error)
 	AllApps() (models.AppList, error)
 	AppShow(namespace string, appName string) (models.App, error)
 	AppUpdate(req models.ApplicationUpdateRequest, namespace string, appName string) (models.Response, error)
 	AppDelete(namespace string, name string) (models.ApplicationDeleteResponse, error)
-	AppUpload(namespace string, name string, tarball string) (models.UploadResponse, error)
+	AppBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error)
+	AppUpload(namespace string, name string, tarball string, progress epinioapi.UploadProgress) (models.UploadResponse, error)
+	AppUploadDelta(namespace, name, dir string, progress epinioapi.UploadProgress) (models.UploadResponse, error)
 	AppImportGit(app models.AppRef, gitRef models.GitRef) (*models.ImportGitResponse, error)
 	AppStage(req models.StageRequest) (*models.StageResponse, error)
 	AppDeploy(req models.DeployRequest) (*models.DeployResponse, error)
-	AppLogs(namespace, appName, stageID string, follow bool, callback func(tailer.ContainerLogLine)) error
+	AppDeployAsync(req models.DeployRequest) (*models.DeployResponse, error)
+	AppLogs(namespace, appName, stageID string, follow bool, params models.LogParameters, callback func(tailer.ContainerLogLine)) error
+	AppEvents(namespace, appName string, follow bool, callback func(models.AppEvent)) error
 	StagingComplete(namespace string, id string) (models.Response, error)
+	StagingQueue(namespace string, id string) (models.StagingQueueResponse, error)
 	AppRunning(app models.AppRef) (models.Response, error)
 	AppExec(namespace string, appName, instance string, tty kubectlterm.TTY) error
 	AppPortForward(namespace string, appName, instance string, opts *epinioapi.PortForwardOpts) error
 	AppRestart(namespace string, appName string) error
+	AppBatchRestart(namespace string, req models.BatchRestartRequest) (models.BatchRestartResponse, error)
+	AppRollback(namespace string, appName string, revision int) error
+	AppRename(namespace string, appName string, newName string) error
+	AppSleep(namespace string, appName string) error
+	AppWake(namespace string, appName string) error
+	AppRevisions(namespace string, appName string) (models.AppRevisionList, error)
+	AppCanaryPromote(namespace string, appName string, weight int) error
+	AppCanaryAbort(namespace string, appName string) error
+	AppTasks(namespace string, appName string) (models.AppTaskList, error)
+	AppTaskRun(namespace string, appName string, taskName string) (models.AppTaskRunResponse, error)
+	AppDomains(namespace string, appName string) (models.AppDomainList, error)
+	AppDomainAdd(namespace string, appName string, domain string) (models.AppDomainStatus, error)
+	AppDomainRemove(namespace string, appName string, domain string) error
 	AppGetPart(namespace, appName, part, destinationPath string) error
 	// env
 	EnvList(namespace string, appName string) (models.EnvVariableMap, error)
@@ -51,10 +109,16 @@ type APIClient interface {
 	EnvMatch(namespace string, appName string, prefix string) (models.EnvMatchResponse, error)
 	// info
 	Info() (models.InfoResponse, error)
+	OIDCConfig() (models.OIDCConfigResponse, error)
 	// namespaces
 	NamespaceCreate(req models.NamespaceCreateRequest) (models.Response, error)
 	NamespaceDelete(namespace string) (models.Response, error)
+	NamespaceUpdate(namespace string, req models.NamespaceUpdateRequest) (models.Response, error)
+	NamespacePodSecuritySet(namespace string, req models.NamespacePodSecurityRequest) (models.Response, error)
+	NamespaceQuotaSet(namespace string, req models.NamespaceQuotaRequest) (models.Response, error)
+	NamespaceUploadLimitSet(namespace string, req models.NamespaceUploadLimitRequest) (models.Response, error)
 	NamespaceShow(namespace string) (models.Namespace, error)
+	NamespaceExport(namespace string, secrets bool) ([]byte, error)
 	NamespacesMatch(prefix string) (models.NamespacesMatchResponse, error)
 	Namespaces() (models.NamespaceList, error)
 	// configurations
@@ -63,6 +127,7 @@ type APIClient interface {
 	ConfigurationBindingCreate(req models.BindRequest, namespace string, appName string) (models.BindResponse, error)
 	ConfigurationBindingDelete(namespace string, appName string, configurationName string) (models.Response, error)
 	ConfigurationDelete(req models.ConfigurationDeleteRequest, namespace string, name string, f epinioapi.ErrorFunc) (models.ConfigurationDeleteResponse, error)
+	ConfigurationBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error)
 	ConfigurationCreate(req models.ConfigurationCreateRequest, namespace string) (models.Response, error)
 	ConfigurationUpdate(req models.ConfigurationUpdateRequest, namespace, name string) (models.Response, error)
 	ConfigurationShow(namespace string, name string) (models.ConfigurationResponse, error)
@@ -72,10 +137,11 @@ type APIClient interface {
 	ServiceCatalogShow(serviceName string) (*models.ServiceCatalogShowResponse, error)
 
 	ServiceShow(req *models.ServiceShowRequest, namespace string) (*models.ServiceShowResponse, error)
-	ServiceCreate(req *models.ServiceCreateRequest, namespace string) error
+	ServiceCreate(req *models.ServiceCreateRequest, namespace string) (models.ServiceCreateResponse, error)
 	ServiceBind(req *models.ServiceBindRequest, namespace, name string) error
 	ServiceUnbind(req *models.ServiceUnbindRequest, namespace, name string) error
 	ServiceDelete(req models.ServiceDeleteRequest, namespace string, name string, f epinioapi.ErrorFunc) (models.ServiceDeleteResponse, error)
+	ServiceBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error)
 	ServiceList(namespace string) (*models.ServiceListResponse, error)
 
 	// application charts
@@ -90,11 +156,30 @@ func New() (*EpinioClient, error) {
 		return nil, errors.Wrap(err, "error loading settings")
 	}
 
-	apiClient := epinioapi.New(cfg.API, cfg.WSS, cfg.User, cfg.Password)
+	apiClient := newAPIClient(cfg)
+
+	if cfg.Token != "" {
+		if err := refreshToken(context.Background(), cfg, apiClient); err != nil {
+			termui.NewUI().Exclamation().Msg(err.Error())
+		}
+		apiClient.SetToken(cfg.Token)
+	}
 
 	return NewEpinioClient(cfg, apiClient)
 }
 
+// newAPIClient returns a client for the API of the settings, retrying as asked for by the
+// options.
+func newAPIClient(cfg *settings.Settings) *epinioapi.Client {
+	apiClient := epinioapi.New(cfg.API, cfg.WSS, cfg.User, cfg.Password)
+	apiClient.SetRetryPolicy(epinioapi.RetryPolicy{
+		Attempts:   viper.GetInt("retries") + 1,
+		Backoff:    viper.GetDuration("retry-backoff"),
+		MaxBackoff: epinioapi.DefaultRetryPolicy.MaxBackoff,
+	})
+	return apiClient
+}
+
 func NewEpinioClient(cfg *settings.Settings, apiClient APIClient) (*EpinioClient, error) {
 	logger := tracelog.NewLogger().WithName("EpinioClient").V(3)
 
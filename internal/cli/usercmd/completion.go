@@ -0,0 +1,133 @@
+package usercmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// completionTTL is the time the resource names fetched for shell completion are reused,
+	// instead of asking the API again.
+	completionTTL = 30 * time.Second
+
+	// completionTimeout is the time shell completion waits for the API, before falling back
+	// to the cached names, even if they are stale.
+	completionTimeout = 2 * time.Second
+
+	// completionRetention is the time stale names are kept, as fallback for an API not
+	// answering in time.
+	completionRetention = 24 * time.Hour
+)
+
+// completionEntry holds the names of the resources of a kind, as fetched at the given time.
+type completionEntry struct {
+	Names []string  `json:"names"`
+	Time  time.Time `json:"time"`
+}
+
+// completionCache maps the kinds of resources, per API and namespace, to their names.
+type completionCache map[string]completionEntry
+
+// matching returns the names of the resources of the kind, in the namespace, which have the
+// prefix. The names come from the completion cache when fresh, and from the list function
+// otherwise. A list function not returning within the completion timeout is abandoned, for
+// the possibly stale names of the cache. Errors are swallowed, as shell completion has no way
+// of reporting them.
+func (c *EpinioClient) matching(kind, namespace, prefix string, list func() ([]string, error)) []string {
+	log := c.Log.WithName("Matching").WithValues("Kind", kind, "Namespace", namespace, "PrefixToMatch", prefix)
+
+	key := strings.Join([]string{c.Settings.API, kind, namespace}, "|")
+	cache := loadCompletionCache()
+
+	entry, cached := cache[key]
+	if !cached || time.Since(entry.Time) > completionTTL {
+		fetched := make(chan []string, 1)
+		go func() {
+			names, err := list()
+			if err != nil {
+				log.Info("list failed", "error", err.Error())
+				fetched <- nil
+				return
+			}
+			fetched <- names
+		}()
+
+		select {
+		case names := <-fetched:
+			if names != nil {
+				entry = completionEntry{Names: names, Time: time.Now()}
+				cache[key] = entry
+				saveCompletionCache(cache)
+			}
+		case <-time.After(completionTimeout):
+			log.Info("list timed out, using cache")
+		}
+	}
+
+	result := []string{}
+	for _, name := range entry.Names {
+		if strings.HasPrefix(name, prefix) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+
+	log.Info("matches", "found", result)
+	return result
+}
+
+// cachePath returns the location of the named cache file, in the user's cache directory.
+func cachePath(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "epinio", name), nil
+}
+
+// loadCompletionCache returns the completion cache. A missing or broken cache file is an
+// empty cache.
+func loadCompletionCache() completionCache {
+	cache := completionCache{}
+
+	path, err := cachePath("completion.json")
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return completionCache{}
+	}
+
+	return cache
+}
+
+// saveCompletionCache writes the completion cache, dropping the entries past retention.
+// Failures are ignored, they only cost the next completion a trip to the API.
+func saveCompletionCache(cache completionCache) {
+	for key, entry := range cache {
+		if time.Since(entry.Time) > completionRetention {
+			delete(cache, key)
+		}
+	}
+
+	path, err := cachePath("completion.json")
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,68 @@
+package usercmd_test
+
+import (
+	"os"
+
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Completion", func() {
+	var mockClient *mockAPIClient
+	var calls int
+	var epinioClient *usercmd.EpinioClient
+
+	BeforeEach(func() {
+		cacheDir, err := os.MkdirTemp("", "epinio-completion")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, cacheDir)
+
+		oldCache, had := os.LookupEnv("XDG_CACHE_HOME")
+		Expect(os.Setenv("XDG_CACHE_HOME", cacheDir)).To(Succeed())
+		DeferCleanup(func() {
+			if had {
+				os.Setenv("XDG_CACHE_HOME", oldCache)
+			} else {
+				os.Unsetenv("XDG_CACHE_HOME")
+			}
+		})
+
+		calls = 0
+		mockClient = &mockAPIClient{}
+		mockClient.mockApps = func(namespace string) (models.AppList, error) {
+			calls++
+			return models.AppList{
+				*models.NewApp("beta", namespace),
+				*models.NewApp("alpha", namespace),
+				*models.NewApp("alpine", namespace),
+			}, nil
+		}
+
+		epinioClient, err = usercmd.NewEpinioClient(&settings.Settings{
+			API:       "https://epinio.example.com",
+			Namespace: "workspace",
+		}, mockClient)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns the sorted names matching the prefix", func() {
+		Expect(epinioClient.AppsMatching("al")).To(Equal([]string{"alpha", "alpine"}))
+		Expect(epinioClient.AppsMatching("")).To(Equal([]string{"alpha", "alpine", "beta"}))
+	})
+
+	It("reuses the cached names", func() {
+		Expect(epinioClient.AppsMatching("b")).To(Equal([]string{"beta"}))
+		Expect(epinioClient.AppsMatching("a")).To(HaveLen(2))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("caches per namespace", func() {
+		epinioClient.AppsMatching("")
+		epinioClient.Settings.Namespace = "other"
+		epinioClient.AppsMatching("")
+		Expect(calls).To(Equal(2))
+	})
+})
@@ -50,6 +50,10 @@ func (c *EpinioClient) Configurations(all bool) error {
 
 	sort.Sort(configurations)
 
+	if c.ui.Machine() {
+		return c.ui.Data(configurations)
+	}
+
 	details.Info("show configurations")
 
 	msg = c.ui.Success()
@@ -86,46 +90,37 @@ func (c *EpinioClient) Configurations(all bool) error {
 // ConfigurationMatching returns all Epinio configurations having the specified prefix
 // in their name.
 func (c *EpinioClient) ConfigurationMatching(ctx context.Context, prefix string) []string {
-	log := c.Log.WithName("ConfigurationMatching").WithValues("PrefixToMatch", prefix)
-	log.Info("start")
-	defer log.Info("return")
-	details := log.V(1) // NOTE: Increment of level, not absolute.
-
-	result := []string{}
-
-	// Ask for all configurations. Filtering is local.
-	// TODO: Create new endpoint (compare `EnvMatch`) and move filtering to the server.
-
-	response, err := c.API.Configurations(c.Settings.Namespace)
-	if err != nil {
-		return result
-	}
-
-	for _, s := range response {
-		configuration := s.Meta.Name
-		details.Info("Found", "Name", configuration)
-		if strings.HasPrefix(configuration, prefix) {
-			details.Info("Matched", "Name", configuration)
-			result = append(result, configuration)
+	return c.matching("configurations", c.Settings.Namespace, prefix, func() ([]string, error) {
+		response, err := c.API.Configurations(c.Settings.Namespace)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	return result
+		names := []string{}
+		for _, configuration := range response {
+			names = append(names, configuration.Meta.Name)
+		}
+		return names, nil
+	})
 }
 
 // BindConfiguration attaches a configuration specified by name to the named application,
-// both in the targeted namespace.
-func (c *EpinioClient) BindConfiguration(configurationName, appName string) error {
+// both in the targeted namespace. A non-empty mount path is the directory the configuration
+// keys are projected into.
+func (c *EpinioClient) BindConfiguration(configurationName, appName, mountPath string) error {
 	log := c.Log.WithName("Bind Configuration To Application").
 		WithValues("Name", configurationName, "Application", appName, "Namespace", c.Settings.Namespace)
 	log.Info("start")
 	defer log.Info("return")
 
-	c.ui.Note().
+	msg := c.ui.Note().
 		WithStringValue("Configuration", configurationName).
 		WithStringValue("Application", appName).
-		WithStringValue("Namespace", c.Settings.Namespace).
-		Msg("Bind Configuration")
+		WithStringValue("Namespace", c.Settings.Namespace)
+	if mountPath != "" {
+		msg = msg.WithStringValue("Mount Path", mountPath)
+	}
+	msg.Msg("Bind Configuration")
 
 	if err := c.TargetOk(); err != nil {
 		return err
@@ -133,6 +128,7 @@ func (c *EpinioClient) BindConfiguration(configurationName, appName string) erro
 
 	request := models.BindRequest{
 		Names: []string{configurationName},
+		Path:  mountPath,
 	}
 
 	br, err := c.API.ConfigurationBindingCreate(request, c.Settings.Namespace, appName)
@@ -140,7 +136,8 @@ func (c *EpinioClient) BindConfiguration(configurationName, appName string) erro
 		return err
 	}
 
-	if len(br.WasBound) > 0 {
+	// With a mount path an already bound configuration got its path updated.
+	if len(br.WasBound) > 0 && mountPath == "" {
 		c.ui.Success().
 			WithStringValue("Configuration", configurationName).
 			WithStringValue("Application", appName).
@@ -257,6 +254,38 @@ func (c *EpinioClient) DeleteConfiguration(name string, unbind bool) error {
 	return nil
 }
 
+// DeleteConfigurations deletes the named configurations, or all configurations of the targeted
+// namespace, in a single call to the server. It reports the outcome per configuration, and
+// fails if any of the deletions failed.
+func (c *EpinioClient) DeleteConfigurations(names []string, unbind, all bool) error {
+	log := c.Log.WithName("DeleteConfigurations").
+		WithValues("Names", names, "All", all, "Namespace", c.Settings.Namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().WithStringValue("Namespace", c.Settings.Namespace)
+	if all {
+		msg.Msg("Delete All Configurations")
+	} else {
+		msg.WithStringValue("Names", strings.Join(names, ", ")).Msg("Delete Configurations")
+	}
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	response, err := c.API.ConfigurationBatchDelete(c.Settings.Namespace, models.BatchDeleteRequest{
+		Names:  names,
+		All:    all,
+		Unbind: unbind,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.batchDeleteResults("Configuration", response.Results)
+}
+
 // UpdateConfiguration updates a configuration specified by name and information about removed keys and changed assignments.
 // TODO: Allow underscores in configuration names (right now they fail because of kubernetes naming rules for secrets)
 func (c *EpinioClient) UpdateConfiguration(name string, removedKeys []string, assignments map[string]string) error {
@@ -378,6 +407,10 @@ func (c *EpinioClient) ConfigurationDetails(name string) error {
 
 	sort.Strings(boundApps)
 
+	if c.ui.Machine() {
+		return c.ui.Data(resp)
+	}
+
 	c.ui.Note().
 		WithStringValue("Created", fmt.Sprintf("%v", resp.Meta.CreatedAt)).
 		WithStringValue("User", resp.Configuration.Username).
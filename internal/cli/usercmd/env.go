@@ -2,8 +2,15 @@ package usercmd
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // EnvList displays a table of all environment variables and their
@@ -67,6 +74,159 @@ func (c *EpinioClient) EnvSet(ctx context.Context, appName, envName, envValue st
 	return nil
 }
 
+// EnvLoad adds or modifies all the environment variables found in the specified .env file,
+// in the named application, at once. A workload is restarted only once.
+func (c *EpinioClient) EnvLoad(ctx context.Context, appName, path string) error {
+	log := c.Log.WithName("EnvLoad")
+	log.Info("start")
+	defer log.Info("return")
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read '%s'", path)
+	}
+
+	request, err := ParseEnvFile(string(content))
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse '%s'", path)
+	}
+
+	msg := c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName).
+		WithStringValue("File", path)
+	names := []string{}
+	for _, ev := range request.List() {
+		names = append(names, ev.Name)
+	}
+	msg = msg.WithStringValue("Variables", strings.Join(names, ", "))
+	msg.Msg("Extend or modify application environment")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	if len(request) == 0 {
+		c.ui.Exclamation().Msg("No variables found, nothing to change")
+		return nil
+	}
+
+	_, err = c.API.EnvSet(request, c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("OK")
+	return nil
+}
+
+// EnvExport writes all environment variables of the named application into the specified
+// file, in .env format, as read by EnvLoad. Without a file the variables are printed, and
+// nothing else.
+func (c *EpinioClient) EnvExport(ctx context.Context, appName, path string) error {
+	log := c.Log.WithName("EnvExport")
+	log.Info("start")
+	defer log.Info("return")
+
+	if path != "" {
+		c.ui.Note().
+			WithStringValue("Namespace", c.Settings.Namespace).
+			WithStringValue("Application", appName).
+			WithStringValue("File", path).
+			Msg("Export application environment")
+	}
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	eVariables, err := c.API.EnvList(c.Settings.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	content := FormatEnvFile(eVariables)
+
+	if path == "" {
+		fmt.Fprint(os.Stdout, content)
+		return nil
+	}
+
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write '%s'", path)
+	}
+
+	c.ui.Success().Msg("OK")
+	return nil
+}
+
+// ParseEnvFile parses the content of a .env file. Blank lines and lines starting with `#`
+// are ignored, as is an `export` prefix. Values can be single-quoted (literal), or
+// double-quoted, with the escapes supported by Go strings.
+func ParseEnvFile(content string) (models.EnvVariableMap, error) {
+	result := models.EnvVariableMap{}
+
+	for number, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		pieces := strings.SplitN(line, "=", 2)
+		name := strings.TrimSpace(pieces[0])
+		if len(pieces) < 2 || name == "" {
+			return nil, errors.Errorf("line %d: expected `name=value`", number+1)
+		}
+		if errs := validation.IsEnvVarName(name); len(errs) > 0 {
+			return nil, errors.Errorf("line %d: bad variable name '%s': %s", number+1, name, errs[0])
+		}
+
+		value, err := envFileValue(strings.TrimSpace(pieces[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", number+1)
+		}
+
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// FormatEnvFile renders the environment in .env format, ordered by name. Values which
+// would not be read back unchanged are double-quoted.
+func FormatEnvFile(environment models.EnvVariableMap) string {
+	var content strings.Builder
+	for _, ev := range environment.List() {
+		value := ev.Value
+		if strings.ContainsAny(value, " \t\n\r\"'#\\") || strings.TrimSpace(value) != value {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&content, "%s=%s\n", ev.Name, value)
+	}
+	return content.String()
+}
+
+// envFileValue returns the value of a .env assignment, with quotes removed. Inline comments
+// are removed from unquoted values.
+func envFileValue(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", errors.Errorf("bad quoted value %s", value)
+		}
+		return unquoted, nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
+
 // EnvShow shows the value of the specified environment variable in
 // the named application.
 func (c *EpinioClient) EnvShow(ctx context.Context, appName, envName string) error {
@@ -0,0 +1,66 @@
+package usercmd_test
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Env files", func() {
+
+	Describe("ParseEnvFile", func() {
+		It("parses assignments, skipping comments and blank lines", func() {
+			env, err := usercmd.ParseEnvFile(`# database
+DB_HOST=db.example.com
+export DB_PORT = 5432
+
+GREETING="hello world\n"
+LITERAL='a "quoted" $value'
+LEVEL=debug # verbose
+EMPTY=
+`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(env).To(Equal(models.EnvVariableMap{
+				"DB_HOST":  "db.example.com",
+				"DB_PORT":  "5432",
+				"GREETING": "hello world\n",
+				"LITERAL":  `a "quoted" $value`,
+				"LEVEL":    "debug",
+				"EMPTY":    "",
+			}))
+		})
+
+		It("rejects lines without assignment", func() {
+			_, err := usercmd.ParseEnvFile("DB_HOST=db\nDB_PORT\n")
+			Expect(err).To(MatchError(ContainSubstring("line 2: expected `name=value`")))
+		})
+
+		It("rejects bad variable names", func() {
+			_, err := usercmd.ParseEnvFile("DB_HOST=db\n1DB PORT=5432\n")
+			Expect(err).To(MatchError(ContainSubstring("line 2: bad variable name '1DB PORT'")))
+		})
+
+		It("rejects bad quoting", func() {
+			_, err := usercmd.ParseEnvFile(`GREETING="hello`)
+			Expect(err).To(MatchError(ContainSubstring("line 1: bad quoted value")))
+		})
+	})
+
+	Describe("FormatEnvFile", func() {
+		It("renders values which read back unchanged", func() {
+			env := models.EnvVariableMap{
+				"PLAIN":  "value",
+				"SPACES": "hello world",
+				"QUOTES": `say "hi"`,
+				"HASH":   "a#b",
+			}
+			content := usercmd.FormatEnvFile(env)
+			Expect(content).To(Equal("HASH=\"a#b\"\nPLAIN=value\nQUOTES=\"say \\\"hi\\\"\"\nSPACES=\"hello world\"\n"))
+
+			parsed, err := usercmd.ParseEnvFile(content)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(Equal(env))
+		})
+	})
+})
@@ -0,0 +1,66 @@
+package usercmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Events streams the platform events of the namespace, or of all namespaces of the user, as
+// they happen, until interrupted. Types restrict the events to these types, or to types
+// starting with them.
+func (c *EpinioClient) Events(namespace string, types []string) error {
+	log := c.Log.WithName("Events").WithValues("Namespace", namespace, "Types", types)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note()
+	if namespace != "" {
+		msg = msg.WithStringValue("Namespace", namespace)
+	}
+	if len(types) > 0 {
+		msg = msg.WithStringValue("Types", strings.Join(types, ", "))
+	}
+	msg.Msg("Streaming platform events")
+
+	callback := func(event models.PlatformEvent) {
+		if c.ui.Machine() {
+			if err := c.ui.Data(event); err != nil {
+				log.Error(err, "printing event")
+			}
+			return
+		}
+		c.ui.ProgressNote().Compact().Msg(formatPlatformEvent(event))
+	}
+
+	err := c.API.Events(namespace, types, callback)
+	if err != nil {
+		c.ui.Problem().Msg(fmt.Sprintf("failed to stream events: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// formatPlatformEvent renders the event as a single line.
+func formatPlatformEvent(event models.PlatformEvent) string {
+	line := fmt.Sprintf("%s %-21s %s/%s",
+		event.Time.Local().Format(time.RFC3339), event.Type, event.Namespace, event.Name)
+	if event.User != "" {
+		line += " by " + event.User
+	}
+
+	keys := []string{}
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		line += fmt.Sprintf(" %s=%s", key, event.Details[key])
+	}
+
+	return line
+}
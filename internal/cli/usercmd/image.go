@@ -0,0 +1,70 @@
+package usercmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// AppImagePull downloads the image the named application currently runs, into the tarball,
+// or, if asked for, into the local docker daemon. Without tarball the image is saved as
+// APPNAME.tar in the working directory.
+func (c *EpinioClient) AppImagePull(ctx context.Context, appName, file string, docker bool) error {
+	log := c.Log.WithName("AppImagePull").WithValues("Namespace", c.Settings.Namespace, "Application", appName)
+	log.Info("start")
+	defer log.Info("return")
+
+	if file == "" {
+		file = appName + ".tar"
+	}
+
+	msg := c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Application", appName)
+	if docker {
+		msg = msg.WithStringValue("Target", "docker")
+	} else {
+		msg = msg.WithStringValue("Target", file)
+	}
+	msg.Msg("Pulling application image")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	if docker {
+		tmpDir, err := os.MkdirTemp("", "epinio-image")
+		if err != nil {
+			return errors.Wrap(err, "failed to create a temporary directory")
+		}
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+		file = filepath.Join(tmpDir, appName+".tar")
+	}
+
+	c.ui.ProgressNote().Msg("Downloading the image ...")
+
+	err := c.API.AppGetPart(c.Settings.Namespace, appName, "image", file)
+	if err != nil {
+		return err
+	}
+
+	if !docker {
+		c.ui.Success().WithStringValue("File", file).Msg("Image saved, load it with `docker load -i FILE`")
+		return nil
+	}
+
+	c.ui.ProgressNote().Msg("Loading the image into docker ...")
+
+	out, err := exec.CommandContext(ctx, "docker", "load", "-i", file).CombinedOutput() // nolint:gosec // file is ours
+	if err != nil {
+		return errors.Wrapf(err, "docker load failed: %s", string(out))
+	}
+
+	c.ui.Success().Msg(string(out))
+	return nil
+}
@@ -2,6 +2,7 @@ package usercmd
 
 import (
 	"github.com/epinio/epinio/internal/version"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
 )
 
 // Info displays information about environment
@@ -15,6 +16,13 @@ func (c *EpinioClient) Info() error {
 		return err
 	}
 
+	if c.ui.Machine() {
+		return c.ui.Data(struct {
+			models.InfoResponse
+			ClientVersion string `json:"client_version"`
+		}{v, version.Version})
+	}
+
 	c.ui.Success().
 		WithStringValue("Platform", v.Platform).
 		WithStringValue("Kubernetes Version", v.KubeVersion).
@@ -0,0 +1,319 @@
+package usercmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+// oidcScopes are the scopes asked for at login. The refresh token comes with offline_access.
+const oidcScopes = "openid profile email groups offline_access"
+
+// tokenRefreshMargin is how long before its expiry the ID token is refreshed.
+const tokenRefreshMargin = time.Minute
+
+// deviceAuthorization is the answer of the issuer to the start of a device login.
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// tokenResponse is the answer of the token endpoint of the issuer.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	RefreshToken     string `json:"refresh_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// LoginOIDC logs the user in at the OIDC issuer whose ID tokens the API accepts. It uses the
+// device flow: the user confirms the login in a browser, which may run on another machine.
+// The ID token, and refresh token, are saved in the settings.
+func (c *EpinioClient) LoginOIDC(ctx context.Context) error {
+	log := c.Log.WithName("LoginOIDC")
+	log.Info("start")
+	defer log.Info("return")
+
+	config, err := c.API.OIDCConfig()
+	if err != nil {
+		return errors.Wrap(err, "getting the single sign-on configuration of the API")
+	}
+
+	provider, err := auth.DiscoverOIDC(ctx, config.Issuer)
+	if err != nil {
+		return err
+	}
+	if provider.DeviceAuthorizationEndpoint == "" {
+		return errors.Errorf("OIDC issuer '%s' does not support device login", config.Issuer)
+	}
+
+	var device deviceAuthorization
+	err = postForm(ctx, provider.DeviceAuthorizationEndpoint, url.Values{
+		"client_id": {config.ClientID},
+		"scope":     {oidcScopes},
+	}, &device)
+	if err != nil {
+		return errors.Wrap(err, "starting the login")
+	}
+
+	verification := device.VerificationURIComplete
+	if verification == "" {
+		verification = device.VerificationURI
+	}
+	c.ui.Note().
+		WithStringValue("Open", verification).
+		WithStringValue("Code", device.UserCode).
+		Msg("Confirm the login in a browser")
+
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(device.ExpiresIn) * time.Second)
+
+	for {
+		if device.ExpiresIn > 0 && time.Now().After(deadline) {
+			return errors.New("login not confirmed in time")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var token tokenResponse
+		err = postForm(ctx, provider.TokenEndpoint, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {device.DeviceCode},
+			"client_id":   {config.ClientID},
+		}, &token)
+		if err != nil {
+			return errors.Wrap(err, "getting the token")
+		}
+
+		switch token.Error {
+		case "":
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		default:
+			return errors.Errorf("login failed: %s %s", token.Error, token.ErrorDescription)
+		}
+
+		if token.IDToken == "" {
+			return errors.New("OIDC issuer returned no ID token")
+		}
+
+		c.Settings.Token = token.IDToken
+		c.Settings.RefreshToken = token.RefreshToken
+		if err := c.Settings.Save(); err != nil {
+			return errors.Wrap(err, "saving the token")
+		}
+
+		c.ui.Success().Msg("Logged in")
+		return nil
+	}
+}
+
+// Login starts a session of the user. The user, session token, and refresh token are saved
+// in the settings, and the password is removed from them.
+func (c *EpinioClient) Login(user, password string) error {
+	log := c.Log.WithName("Login").WithValues("User", user)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("User", user).
+		Msg("Logging in...")
+
+	session, err := c.API.SessionCreate(user, password)
+	if err != nil {
+		return err
+	}
+
+	c.Settings.User = user
+	c.Settings.Token = session.Token
+	c.Settings.RefreshToken = session.RefreshToken
+	c.Settings.Password = ""
+	if err := c.Settings.Save(); err != nil {
+		return errors.Wrap(err, "saving the token")
+	}
+
+	c.ui.Success().
+		WithStringValue("Session expires", session.RefreshExpiresAt.Local().Format(time.RFC1123)).
+		Msg("Logged in")
+	return nil
+}
+
+// Logout ends the session of the settings, if any, and removes its tokens from the settings.
+func (c *EpinioClient) Logout() error {
+	log := c.Log.WithName("Logout")
+	log.Info("start")
+	defer log.Info("return")
+
+	if c.Settings.Token == "" {
+		c.ui.Exclamation().Msg("Not logged in")
+		return nil
+	}
+
+	if auth.IsSessionToken(c.Settings.Token) {
+		if _, err := c.API.SessionDelete(); err != nil {
+			return err
+		}
+	}
+
+	c.Settings.Token = ""
+	c.Settings.RefreshToken = ""
+	if err := c.Settings.Save(); err != nil {
+		return errors.Wrap(err, "saving the settings")
+	}
+
+	c.ui.Success().Msg("Logged out")
+	return nil
+}
+
+// EnrollClientCertificate gets a client certificate for the machine, for the mutual TLS port of
+// the API. The key is generated here, and only the certificate request is sent. Certificate,
+// key, and the URLs of the port are saved in the settings.
+func (c *EpinioClient) EnrollClientCertificate() error {
+	log := c.Log.WithName("EnrollClientCertificate")
+	log.Info("start")
+	defer log.Info("return")
+
+	csr, key, err := auth.NewClientCertificateRequest(c.Settings.User)
+	if err != nil {
+		return err
+	}
+
+	certificate, err := c.API.ClientCertificateCreate(models.ClientCertificateRequest{CSR: string(csr)})
+	if err != nil {
+		return err
+	}
+
+	c.Settings.ClientCertificate = certificate.Certificate
+	c.Settings.ClientKey = string(key)
+	if certificate.URL != "" {
+		c.Settings.API = certificate.URL
+		c.Settings.WSS = certificate.WsURL
+	}
+	if err := c.Settings.Save(); err != nil {
+		return errors.Wrap(err, "saving the client certificate")
+	}
+
+	c.ui.Success().
+		WithStringValue("API", c.Settings.API).
+		WithStringValue("Certificate expires", certificate.ExpiresAt.Local().Format(time.RFC1123)).
+		Msg("Machine enrolled")
+	return nil
+}
+
+// refreshToken replaces the token of the settings with a new one, shortly before it expires,
+// using the refresh token. Session tokens are refreshed by the API. For ID tokens, the issuer
+// and client are taken from the token.
+func refreshToken(ctx context.Context, cfg *settings.Settings, api APIClient) error {
+	// API tokens are not refreshed, they are valid until they expire, or are revoked.
+	if auth.IsAPIToken(cfg.Token) {
+		return nil
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(cfg.Token, claims); err != nil {
+		return errors.Wrap(err, "bad token, please log in again")
+	}
+	if claims.VerifyExpiresAt(time.Now().Add(tokenRefreshMargin).Unix(), true) {
+		return nil
+	}
+	if cfg.RefreshToken == "" {
+		return errors.New("the token expired, please log in again")
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if issuer == auth.SessionIssuer {
+		session, err := api.SessionRefresh(cfg.RefreshToken)
+		if err != nil {
+			return errors.Wrap(err, "refreshing the session token")
+		}
+
+		cfg.Token = session.Token
+		cfg.RefreshToken = session.RefreshToken
+		return cfg.Save()
+	}
+
+	clientID, _ := claims["azp"].(string)
+	if clientID == "" {
+		switch aud := claims["aud"].(type) {
+		case string:
+			clientID = aud
+		case []interface{}:
+			if len(aud) > 0 {
+				clientID, _ = aud[0].(string)
+			}
+		}
+	}
+
+	provider, err := auth.DiscoverOIDC(ctx, issuer)
+	if err != nil {
+		return err
+	}
+
+	var token tokenResponse
+	err = postForm(ctx, provider.TokenEndpoint, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {cfg.RefreshToken},
+		"client_id":     {clientID},
+	}, &token)
+	if err != nil {
+		return errors.Wrap(err, "refreshing the ID token")
+	}
+	if token.Error != "" {
+		return errors.Errorf("refreshing the ID token failed: %s %s, please log in again", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return errors.New("OIDC issuer returned no ID token")
+	}
+
+	cfg.Token = token.IDToken
+	if token.RefreshToken != "" {
+		cfg.RefreshToken = token.RefreshToken
+	}
+	return cfg.Save()
+}
+
+// postForm posts the form to the endpoint of the issuer, and decodes the JSON answer into the
+// result. Answers with status 400 carry errors of the OAuth2 protocol, and are decoded too.
+func postForm(ctx context.Context, endpoint string, form url.Values, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusBadRequest {
+		return errors.Errorf("%s returned '%s'", endpoint, response.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
@@ -0,0 +1,92 @@
+package usercmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoginOIDC", func() {
+	var issuer, api *httptest.Server
+	var polls int
+	var epinioClient *usercmd.EpinioClient
+	var cfg *settings.Settings
+
+	BeforeEach(func() {
+		polls = 0
+
+		issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"issuer":                        "http://" + r.Host,
+					"token_endpoint":                "http://" + r.Host + "/token",
+					"device_authorization_endpoint": "http://" + r.Host + "/device",
+				})
+			case "/device":
+				Expect(r.PostFormValue("client_id")).To(Equal("epinio"))
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"device_code":      "device-1",
+					"user_code":        "ABCD-EFGH",
+					"verification_uri": "http://" + r.Host + "/verify",
+					"expires_in":       60,
+					"interval":         1,
+				})
+			case "/token":
+				Expect(r.PostFormValue("device_code")).To(Equal("device-1"))
+				polls++
+				if polls == 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"id_token":      "id-token",
+					"refresh_token": "refresh-token",
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(issuer.Close)
+
+		api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":    issuer.URL,
+				"client_id": "epinio",
+			})
+		}))
+		DeferCleanup(api.Close)
+
+		dir, err := os.MkdirTemp("", "epinio-login")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		cfg, err = settings.LoadFrom(filepath.Join(dir, "settings.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		cfg.API = api.URL
+
+		epinioClient, err = usercmd.NewEpinioClient(cfg, epinioapi.New(api.URL, "", "", ""))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("saves the tokens once the login is confirmed", func() {
+		Expect(epinioClient.LoginOIDC(context.Background())).To(Succeed())
+		Expect(polls).To(Equal(2))
+
+		saved, err := settings.LoadFrom(cfg.Location)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Token).To(Equal("id-token"))
+		Expect(saved.RefreshToken).To(Equal("refresh-token"))
+	})
+})
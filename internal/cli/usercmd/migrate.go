@@ -0,0 +1,426 @@
+package usercmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// MigrateOptions selects the source of a migration, the state of the installation in use, or
+// an exported state read from FromFile, and its targets, the file Export to export the state
+// to, and the installation of the settings context ToContext. ImageRegistry overrides the
+// registry the images of the apps are re-pointed to, the registry of the target by default.
+type MigrateOptions struct {
+	ToContext     string
+	Export        string
+	FromFile      string
+	ImageRegistry string
+}
+
+// MigrationPlan is what a migration creates on the target installation, in order: the
+// namespaces, with their configurations and apps, and the users. Resources existing on the
+// target are skipped, and left as they are. Images maps the images of the apps to the images
+// they are re-pointed to.
+type MigrationPlan struct {
+	Namespaces []NamespaceMigration
+	Users      []models.UserState
+	Images     map[string]string
+	Skipped    []string
+	Warnings   []string
+}
+
+// NamespaceMigration is the part of a migration plan for a single namespace. Existing
+// namespaces are not created, only their missing configurations and apps.
+type NamespaceMigration struct {
+	Name           string
+	Create         bool
+	Configurations []models.ConfigurationCreateRequest
+	Apps           []models.ApplicationApplyRequest
+}
+
+// MigrationResult reports the outcome of a migration. The resources are named as
+// `namespace/name`, the skipped ones as `part/namespace/name`.
+type MigrationResult struct {
+	Namespaces     []string          `json:"namespaces"`
+	Configurations []string          `json:"configurations"`
+	Apps           []string          `json:"apps"`
+	Users          []string          `json:"users"`
+	Images         map[string]string `json:"images,omitempty"`
+	Skipped        []string          `json:"skipped,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+// Migrate copies the namespaces, with their custom configurations and apps, and the users
+// from the installation in use, or an exported state, to the installation of another settings
+// context, and/or exports the state to a file.
+func (c *EpinioClient) Migrate(options MigrateOptions) error {
+	log := c.Log.WithName("Migrate").WithValues("ToContext", options.ToContext)
+	log.Info("start")
+	defer log.Info("return")
+	details := log.V(1) // NOTE: Increment of level, not absolute.
+
+	msg := c.ui.Note()
+	if options.FromFile != "" {
+		msg = msg.WithStringValue("From File", options.FromFile)
+	} else {
+		msg = msg.WithStringValue("From", c.Settings.API)
+	}
+	if options.Export != "" {
+		msg = msg.WithStringValue("Export", options.Export)
+	}
+	if options.ToContext != "" {
+		msg = msg.WithStringValue("To Context", options.ToContext)
+	}
+	msg.Msg("Migrating the platform state...")
+
+	if options.ToContext == "" && options.Export == "" {
+		return errors.New("nothing to migrate to, neither target context, nor output file given")
+	}
+
+	details.Info("export source state")
+
+	var source models.PlatformState
+	var err error
+	if options.FromFile != "" {
+		source, err = readPlatformState(options.FromFile)
+	} else {
+		source, err = c.API.StateExport()
+	}
+	if err != nil {
+		return err
+	}
+
+	if options.Export != "" {
+		if err := writePlatformState(options.Export, source); err != nil {
+			return err
+		}
+		if options.ToContext == "" {
+			c.ui.Success().
+				WithStringValue("Namespaces", strconv.Itoa(len(source.Namespaces))).
+				WithStringValue("Users", strconv.Itoa(len(source.Users))).
+				Msg("State exported.")
+			return nil
+		}
+	}
+
+	target, err := c.contextClient(options.ToContext, options.FromFile == "")
+	if err != nil {
+		return err
+	}
+
+	details.Info("export target state")
+
+	targetState, err := target.StateExport()
+	if err != nil {
+		return errors.Wrap(err, "exporting the state of the target")
+	}
+
+	plan := PlanMigration(source, targetState, options.ImageRegistry)
+	result := MigrationResult{
+		Namespaces:     []string{},
+		Configurations: []string{},
+		Apps:           []string{},
+		Users:          []string{},
+		Images:         plan.Images,
+		Skipped:        plan.Skipped,
+		Warnings:       plan.Warnings,
+	}
+
+	for _, namespace := range plan.Namespaces {
+		details.Info("migrate namespace", "namespace", namespace.Name)
+
+		if namespace.Create {
+			_, err := target.NamespaceCreate(models.NamespaceCreateRequest{Name: namespace.Name})
+			if err != nil {
+				return errors.Wrapf(err, "creating namespace %s", namespace.Name)
+			}
+			result.Namespaces = append(result.Namespaces, namespace.Name)
+		}
+		for _, configuration := range namespace.Configurations {
+			_, err := target.ConfigurationCreate(configuration, namespace.Name)
+			if err != nil {
+				return errors.Wrapf(err, "creating configuration %s/%s", namespace.Name, configuration.Name)
+			}
+			result.Configurations = append(result.Configurations, namespace.Name+"/"+configuration.Name)
+		}
+		for _, app := range namespace.Apps {
+			_, err := target.AppApply(app, namespace.Name)
+			if err != nil {
+				return errors.Wrapf(err, "creating app %s/%s", namespace.Name, app.Name)
+			}
+			result.Apps = append(result.Apps, namespace.Name+"/"+app.Name)
+		}
+	}
+
+	if len(plan.Users) > 0 {
+		details.Info("migrate users")
+
+		resp, err := target.StateUserImport(models.UserImportRequest{Users: plan.Users})
+		if err != nil {
+			return errors.Wrap(err, "creating the users")
+		}
+		result.Users = resp.Created
+		for _, username := range resp.Skipped {
+			result.Skipped = append(result.Skipped, "users/"+username)
+		}
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(result)
+	}
+
+	for _, skipped := range result.Skipped {
+		c.ui.Exclamation().Msg(fmt.Sprintf("Skipped %s, it exists already", skipped))
+	}
+	for _, warning := range result.Warnings {
+		c.ui.Exclamation().Msg(warning)
+	}
+
+	if len(result.Images) > 0 {
+		msg := c.ui.Normal().WithTable("Image", "Target Image")
+		for _, image := range sortedBinds(result.Images) {
+			msg = msg.WithTableRow(image, result.Images[image])
+		}
+		msg.Msg("The images of the apps have to be copied to the registry of the target:")
+	}
+
+	c.ui.Success().
+		WithStringValue("Namespaces", strings.Join(result.Namespaces, ", ")).
+		WithStringValue("Configurations", strings.Join(result.Configurations, ", ")).
+		WithStringValue("Apps", strings.Join(result.Apps, ", ")).
+		WithStringValue("Users", strings.Join(result.Users, ", ")).
+		Msg("State migrated.")
+
+	return nil
+}
+
+// PlanMigration returns the plan of the migration of the source state to the installation of
+// the target state. The images of the source registry are re-pointed to the image registry,
+// the registry of the target by default, and the routes of the main domain of the source to
+// the main domain of the target. Bindings to configurations which are not migrated, i.e. those
+// of services, are dropped.
+func PlanMigration(source, target models.PlatformState, imageRegistry string) MigrationPlan {
+	if imageRegistry == "" {
+		imageRegistry = target.Registry
+	}
+
+	existing := map[string]bool{}
+	for _, namespace := range target.Namespaces {
+		existing["namespaces/"+namespace.Name] = true
+		for _, configuration := range namespace.Configurations {
+			existing["configurations/"+namespace.Name+"/"+configuration.Name] = true
+		}
+		for _, app := range namespace.Apps {
+			existing["apps/"+namespace.Name+"/"+app.Name] = true
+		}
+	}
+	for _, user := range target.Users {
+		existing["users/"+user.Username] = true
+	}
+
+	plan := MigrationPlan{
+		Namespaces: []NamespaceMigration{},
+		Users:      []models.UserState{},
+		Images:     map[string]string{},
+	}
+
+	for _, namespace := range source.Namespaces {
+		migration := NamespaceMigration{
+			Name:   namespace.Name,
+			Create: !existing["namespaces/"+namespace.Name],
+		}
+		if !migration.Create {
+			plan.Skipped = append(plan.Skipped, "namespaces/"+namespace.Name)
+		}
+
+		migrated := map[string]bool{}
+		for _, configuration := range namespace.Configurations {
+			migrated[configuration.Name] = true
+
+			key := "configurations/" + namespace.Name + "/" + configuration.Name
+			if existing[key] {
+				plan.Skipped = append(plan.Skipped, key)
+				continue
+			}
+			migration.Configurations = append(migration.Configurations, models.ConfigurationCreateRequest{
+				Name: configuration.Name,
+				Data: configuration.Data,
+			})
+		}
+
+		for _, app := range namespace.Apps {
+			key := "apps/" + namespace.Name + "/" + app.Name
+			if existing[key] {
+				plan.Skipped = append(plan.Skipped, key)
+				continue
+			}
+
+			request := models.ApplicationApplyRequest{
+				Name:          app.Name,
+				Configuration: app.Configuration,
+			}
+
+			request.Configuration.Configurations = []string{}
+			for _, configuration := range app.Configuration.Configurations {
+				if migrated[configuration] || existing["configurations/"+namespace.Name+"/"+configuration] {
+					request.Configuration.Configurations = append(request.Configuration.Configurations, configuration)
+					continue
+				}
+				plan.Warnings = append(plan.Warnings,
+					fmt.Sprintf("App %s/%s: binding to configuration %s dropped, it is not migrated", namespace.Name, app.Name, configuration))
+				if _, ok := request.Configuration.ConfigurationPaths[configuration]; ok {
+					paths := map[string]string{}
+					for name, path := range request.Configuration.ConfigurationPaths {
+						if name != configuration {
+							paths[name] = path
+						}
+					}
+					request.Configuration.ConfigurationPaths = paths
+				}
+			}
+
+			request.Configuration.Routes = []string{}
+			for _, route := range app.Configuration.Routes {
+				request.Configuration.Routes = append(request.Configuration.Routes,
+					RepointRoute(route, source.Domain, target.Domain))
+			}
+
+			if app.ImageURL != "" {
+				image := RepointImage(app.ImageURL, source.Registry, imageRegistry)
+				if image != app.ImageURL {
+					plan.Images[app.ImageURL] = image
+				}
+				request.Origin = models.ApplicationOrigin{
+					Kind:      models.OriginContainer,
+					Container: image,
+				}
+			} else {
+				plan.Warnings = append(plan.Warnings,
+					fmt.Sprintf("App %s/%s: created without workload, it has no image", namespace.Name, app.Name))
+			}
+
+			migration.Apps = append(migration.Apps, request)
+		}
+
+		plan.Namespaces = append(plan.Namespaces, migration)
+	}
+
+	for _, user := range source.Users {
+		if existing["users/"+user.Username] {
+			plan.Skipped = append(plan.Skipped, "users/"+user.Username)
+			continue
+		}
+		plan.Users = append(plan.Users, user)
+	}
+
+	return plan
+}
+
+// RepointImage returns the image moved from the registry to the other registry. Images of
+// other registries are returned as they are. The registries include their namespace.
+func RepointImage(image, fromRegistry, toRegistry string) string {
+	if fromRegistry == "" || toRegistry == "" || !strings.HasPrefix(image, fromRegistry+"/") {
+		return image
+	}
+	return toRegistry + "/" + strings.TrimPrefix(image, fromRegistry+"/")
+}
+
+// RepointRoute returns the route, i.e. domain and optional path, moved from the domain, or a
+// sub-domain of it, to the other domain. Routes of other domains are returned as they are.
+func RepointRoute(route, fromDomain, toDomain string) string {
+	if fromDomain == "" || toDomain == "" {
+		return route
+	}
+
+	host, path := route, ""
+	if index := strings.Index(route, "/"); index >= 0 {
+		host, path = route[:index], route[index:]
+	}
+
+	switch {
+	case host == fromDomain:
+		host = toDomain
+	case strings.HasSuffix(host, "."+fromDomain):
+		host = strings.TrimSuffix(host, fromDomain) + toDomain
+	default:
+		return route
+	}
+	return host + path
+}
+
+// contextClient returns a client for the API of the installation of the named settings
+// context. With the current check the context must not be the one in use. The client trusts
+// the certificates of both contexts.
+func (c *EpinioClient) contextClient(name string, check bool) (APIClient, error) {
+	cfg, err := settings.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading settings")
+	}
+	if err := cfg.UseContext(name); err != nil {
+		return nil, err
+	}
+	if check && cfg.API == c.Settings.API {
+		return nil, errors.Errorf("context '%s' is the installation in use", name)
+	}
+	// The client certificate is global, the one of the context in use is presented.
+	if cfg.ClientCertificate != "" {
+		return nil, errors.Errorf("context '%s' uses a client certificate, it cannot be a migration target", name)
+	}
+
+	if cfg.Certs != "" {
+		// ExtendLocalTrust replaces the TLS configuration of the context in use.
+		auth.ExtendLocalTrust(c.Settings.Certs + "\n" + cfg.Certs)
+		if c.Settings.ClientCertificate != "" {
+			if err := auth.UseClientCertificate(c.Settings.ClientCertificate, c.Settings.ClientKey); err != nil {
+				return nil, errors.Wrap(err, "bad client certificate")
+			}
+		}
+		if viper.GetBool("skip-ssl-verification") {
+			http.DefaultTransport.(*http.Transport).TLSClientConfig.InsecureSkipVerify = true // nolint:gosec // Controlled by user option
+		}
+	}
+
+	apiClient := newAPIClient(cfg)
+	// The token is not refreshed, that saves the settings, making the target context the
+	// current one. An expired token is rejected, asking for a login to the context.
+	if cfg.Token != "" {
+		apiClient.SetToken(cfg.Token)
+	}
+
+	return apiClient, nil
+}
+
+// readPlatformState reads an exported platform state from the file.
+func readPlatformState(path string) (models.PlatformState, error) {
+	var state models.PlatformState
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, errors.Wrap(err, "reading the exported state")
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, errors.Wrapf(err, "bad exported state in %s", path)
+	}
+
+	return state, nil
+}
+
+// writePlatformState writes the platform state to the file. The file holds the passwords of
+// the users and the data of the configurations, it is readable by its owner only.
+func writePlatformState(path string, state models.PlatformState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(os.WriteFile(path, data, 0600), "writing the exported state")
+}
@@ -0,0 +1,155 @@
+package usercmd_test
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration", func() {
+
+	Describe("RepointImage", func() {
+		It("moves images of the registry to the other registry", func() {
+			image := usercmd.RepointImage("registry.old.io/apps/workspace-app:abc", "registry.old.io/apps", "registry.new.io/epinio")
+			Expect(image).To(Equal("registry.new.io/epinio/workspace-app:abc"))
+		})
+
+		It("keeps images of other registries", func() {
+			image := usercmd.RepointImage("docker.io/library/nginx:latest", "registry.old.io/apps", "registry.new.io/epinio")
+			Expect(image).To(Equal("docker.io/library/nginx:latest"))
+
+			image = usercmd.RepointImage("registry.old.io/apps-other/app:abc", "registry.old.io/apps", "registry.new.io/epinio")
+			Expect(image).To(Equal("registry.old.io/apps-other/app:abc"))
+		})
+
+		It("keeps images without registry to move to", func() {
+			image := usercmd.RepointImage("registry.old.io/apps/app:abc", "registry.old.io/apps", "")
+			Expect(image).To(Equal("registry.old.io/apps/app:abc"))
+		})
+	})
+
+	Describe("RepointRoute", func() {
+		It("moves the routes of the domain, and its sub-domains, keeping their paths", func() {
+			Expect(usercmd.RepointRoute("old.example.com", "old.example.com", "new.example.org")).To(Equal("new.example.org"))
+			Expect(usercmd.RepointRoute("app.old.example.com", "old.example.com", "new.example.org")).To(Equal("app.new.example.org"))
+			Expect(usercmd.RepointRoute("app.old.example.com/api", "old.example.com", "new.example.org")).To(Equal("app.new.example.org/api"))
+		})
+
+		It("keeps the routes of other domains", func() {
+			Expect(usercmd.RepointRoute("www.example.com/shop", "old.example.com", "new.example.org")).To(Equal("www.example.com/shop"))
+			Expect(usercmd.RepointRoute("app.bold.example.com", "old.example.com", "new.example.org")).To(Equal("app.bold.example.com"))
+		})
+	})
+
+	Describe("PlanMigration", func() {
+		var source, target models.PlatformState
+
+		BeforeEach(func() {
+			source = models.PlatformState{
+				Registry: "registry.old.io/apps",
+				Domain:   "old.example.com",
+				Namespaces: []models.NamespaceState{
+					{
+						Name: "workspace",
+						Configurations: []models.ConfigurationState{
+							{Name: "credentials", Data: map[string]string{"user": "admin"}},
+						},
+						Apps: []models.AppState{
+							{
+								Name:     "shop",
+								ImageURL: "registry.old.io/apps/workspace-shop:abc",
+								Configuration: models.ApplicationUpdateRequest{
+									Configurations:     []string{"credentials", "xdb-postgres"},
+									ConfigurationPaths: map[string]string{"xdb-postgres": "/db"},
+									Routes:             []string{"shop.old.example.com", "shop.example.com"},
+								},
+							},
+							{Name: "draft"},
+						},
+					},
+					{Name: "production"},
+				},
+				Users: []models.UserState{
+					{Username: "admin", Password: "secret", Role: "admin"},
+					{Username: "jane", Password: "secret", Role: "user", Namespaces: map[string]string{"workspace": "developer"}},
+				},
+			}
+			target = models.PlatformState{
+				Registry: "registry.new.io/epinio",
+				Domain:   "new.example.org",
+				Namespaces: []models.NamespaceState{
+					{Name: "production"},
+				},
+				Users: []models.UserState{
+					{Username: "admin", Role: "admin"},
+				},
+			}
+		})
+
+		It("creates the missing resources, re-pointing the images, and routes", func() {
+			plan := usercmd.PlanMigration(source, target, "")
+
+			Expect(plan.Namespaces).To(HaveLen(2))
+			workspace := plan.Namespaces[0]
+			Expect(workspace.Name).To(Equal("workspace"))
+			Expect(workspace.Create).To(BeTrue())
+			Expect(workspace.Configurations).To(Equal([]models.ConfigurationCreateRequest{
+				{Name: "credentials", Data: map[string]string{"user": "admin"}},
+			}))
+
+			Expect(workspace.Apps).To(HaveLen(2))
+			shop := workspace.Apps[0]
+			Expect(shop.Name).To(Equal("shop"))
+			Expect(shop.Origin.Kind).To(Equal(models.OriginContainer))
+			Expect(shop.Origin.Container).To(Equal("registry.new.io/epinio/workspace-shop:abc"))
+			Expect(shop.Configuration.Routes).To(Equal([]string{"shop.new.example.org", "shop.example.com"}))
+			Expect(plan.Images).To(Equal(map[string]string{
+				"registry.old.io/apps/workspace-shop:abc": "registry.new.io/epinio/workspace-shop:abc",
+			}))
+			Expect(workspace.Apps[1].Origin.Container).To(BeEmpty())
+
+			Expect(plan.Namespaces[1].Name).To(Equal("production"))
+			Expect(plan.Namespaces[1].Create).To(BeFalse())
+
+			Expect(plan.Users).To(HaveLen(1))
+			Expect(plan.Users[0].Username).To(Equal("jane"))
+			Expect(plan.Skipped).To(Equal([]string{"namespaces/production", "users/admin"}))
+		})
+
+		It("drops the bindings to configurations which are not migrated", func() {
+			plan := usercmd.PlanMigration(source, target, "")
+
+			shop := plan.Namespaces[0].Apps[0]
+			Expect(shop.Configuration.Configurations).To(Equal([]string{"credentials"}))
+			Expect(shop.Configuration.ConfigurationPaths).To(BeEmpty())
+			Expect(plan.Warnings).To(ContainElement("App workspace/shop: binding to configuration xdb-postgres dropped, it is not migrated"))
+
+			// The source is left as it is.
+			Expect(source.Namespaces[0].Apps[0].Configuration.ConfigurationPaths).To(HaveKey("xdb-postgres"))
+		})
+
+		It("skips the configurations, and apps, existing on the target", func() {
+			target.Namespaces = append(target.Namespaces, models.NamespaceState{
+				Name:           "workspace",
+				Configurations: []models.ConfigurationState{{Name: "credentials"}},
+				Apps:           []models.AppState{{Name: "draft"}},
+			})
+
+			plan := usercmd.PlanMigration(source, target, "")
+
+			workspace := plan.Namespaces[0]
+			Expect(workspace.Create).To(BeFalse())
+			Expect(workspace.Configurations).To(BeEmpty())
+			Expect(workspace.Apps).To(HaveLen(1))
+			Expect(workspace.Apps[0].Configuration.Configurations).To(Equal([]string{"credentials"}))
+			Expect(plan.Skipped).To(ContainElements("namespaces/workspace", "configurations/workspace/credentials", "apps/workspace/draft"))
+		})
+
+		It("re-points the images to the given registry", func() {
+			plan := usercmd.PlanMigration(source, target, "mirror.example.com/epinio")
+
+			Expect(plan.Namespaces[0].Apps[0].Origin.Container).To(Equal("mirror.example.com/epinio/workspace-shop:abc"))
+		})
+	})
+})
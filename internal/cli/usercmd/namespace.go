@@ -2,7 +2,10 @@ package usercmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
@@ -11,8 +14,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
-// CreateNamespace creates a namespace
-func (c *EpinioClient) CreateNamespace(namespace string) error {
+// CreateNamespace creates a namespace, with the quota, if any
+func (c *EpinioClient) CreateNamespace(namespace string, quota models.NamespaceQuota) error {
 	log := c.Log.WithName("CreateNamespace").WithValues("Namespace", namespace)
 	log.Info("start")
 	defer log.Info("return")
@@ -26,7 +29,7 @@ func (c *EpinioClient) CreateNamespace(namespace string) error {
 		return fmt.Errorf("%s: %s", "namespace name incorrect", strings.Join(errorMsgs, "\n"))
 	}
 
-	_, err := c.API.NamespaceCreate(models.NamespaceCreateRequest{Name: namespace})
+	_, err := c.API.NamespaceCreate(models.NamespaceCreateRequest{Name: namespace, Quota: quota})
 	if err != nil {
 		return err
 	}
@@ -38,21 +41,18 @@ func (c *EpinioClient) CreateNamespace(namespace string) error {
 
 // NamespacesMatching returns all Epinio namespaces having the specified prefix in their name
 func (c *EpinioClient) NamespacesMatching(prefix string) []string {
-	log := c.Log.WithName("NamespaceMatching").WithValues("PrefixToMatch", prefix)
-	log.Info("start")
-	defer log.Info("return")
-
-	result := []string{}
-
-	resp, err := c.API.NamespacesMatch(prefix)
-	if err != nil {
-		return result
-	}
-
-	result = resp.Names
-
-	log.Info("matches", "found", result)
-	return result
+	return c.matching("namespaces", "", prefix, func() ([]string, error) {
+		namespaces, _, err := c.namespaces()
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{}
+		for _, namespace := range namespaces {
+			names = append(names, namespace.Meta.Name)
+		}
+		return names, nil
+	})
 }
 
 func (c *EpinioClient) Namespaces() error {
@@ -65,12 +65,18 @@ func (c *EpinioClient) Namespaces() error {
 
 	details.Info("list namespaces")
 
-	namespaces, err := c.API.Namespaces()
+	namespaces, received, err := c.namespaces()
 	if err != nil {
 		return err
 	}
+	c.staleWarning(received)
 
 	sort.Sort(namespaces)
+
+	if c.ui.Machine() {
+		return c.ui.Data(namespaces)
+	}
+
 	msg := c.ui.Success().WithTable("Name", "Created", "Applications", "Configurations")
 
 	for _, namespace := range namespaces {
@@ -146,6 +152,271 @@ func (c *EpinioClient) DeleteNamespace(namespace string) error {
 	return nil
 }
 
+// NamespaceChanges holds the changes to make to the settings of a namespace. Nil fields
+// leave the associated setting unchanged.
+type NamespaceChanges struct {
+	BuilderImage         *string
+	Buildpacks           *[]string
+	StagingCPURequest    *string
+	StagingCPULimit      *string
+	StagingMemoryRequest *string
+	StagingMemoryLimit   *string
+	StagingTimeout       *int64
+	StagingConcurrency   *int
+	AppChart             *string
+	AllowedAppCharts     *[]string
+	LogSinkType          *string
+	LogSinkURL           *string
+}
+
+// UpdateNamespace changes the settings of a Namespace.
+func (c *EpinioClient) UpdateNamespace(namespace string, changes NamespaceChanges) error {
+	log := c.Log.WithName("UpdateNamespace").WithValues("Namespace", namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().
+		WithStringValue("Name", namespace)
+	if changes.BuilderImage != nil {
+		msg = msg.WithStringValue("Builder Image", *changes.BuilderImage)
+	}
+	if changes.Buildpacks != nil {
+		msg = msg.WithStringValue("Buildpacks", strings.Join(*changes.Buildpacks, ", "))
+	}
+	if changes.StagingCPURequest != nil {
+		msg = msg.WithStringValue("Staging CPU Request", *changes.StagingCPURequest)
+	}
+	if changes.StagingCPULimit != nil {
+		msg = msg.WithStringValue("Staging CPU Limit", *changes.StagingCPULimit)
+	}
+	if changes.StagingMemoryRequest != nil {
+		msg = msg.WithStringValue("Staging Memory Request", *changes.StagingMemoryRequest)
+	}
+	if changes.StagingMemoryLimit != nil {
+		msg = msg.WithStringValue("Staging Memory Limit", *changes.StagingMemoryLimit)
+	}
+	if changes.StagingTimeout != nil {
+		msg = msg.WithStringValue("Staging Timeout", fmt.Sprintf("%ds", *changes.StagingTimeout))
+	}
+	if changes.StagingConcurrency != nil {
+		msg = msg.WithStringValue("Staging Concurrency", strconv.Itoa(*changes.StagingConcurrency))
+	}
+	if changes.AppChart != nil {
+		msg = msg.WithStringValue("App Chart", *changes.AppChart)
+	}
+	if changes.AllowedAppCharts != nil {
+		msg = msg.WithStringValue("Allowed App Charts", strings.Join(*changes.AllowedAppCharts, ", "))
+	}
+	if changes.LogSinkType != nil {
+		msg = msg.WithStringValue("Log Sink Type", *changes.LogSinkType)
+	}
+	if changes.LogSinkURL != nil {
+		msg = msg.WithStringValue("Log Sink URL", *changes.LogSinkURL)
+	}
+	msg.Msg("Updating namespace...")
+
+	space, err := c.API.NamespaceShow(namespace)
+	if err != nil {
+		return err
+	}
+
+	settings := space.Settings
+	if changes.BuilderImage != nil {
+		settings.BuilderImage = *changes.BuilderImage
+	}
+	if changes.Buildpacks != nil {
+		settings.Buildpacks = *changes.Buildpacks
+	}
+	if changes.StagingCPURequest != nil {
+		settings.Staging.CPURequest = *changes.StagingCPURequest
+	}
+	if changes.StagingCPULimit != nil {
+		settings.Staging.CPULimit = *changes.StagingCPULimit
+	}
+	if changes.StagingMemoryRequest != nil {
+		settings.Staging.MemoryRequest = *changes.StagingMemoryRequest
+	}
+	if changes.StagingMemoryLimit != nil {
+		settings.Staging.MemoryLimit = *changes.StagingMemoryLimit
+	}
+	if changes.StagingTimeout != nil {
+		settings.Staging.Timeout = *changes.StagingTimeout
+	}
+	if changes.StagingConcurrency != nil {
+		settings.StagingConcurrency = *changes.StagingConcurrency
+	}
+	if changes.AppChart != nil {
+		settings.AppChart = *changes.AppChart
+	}
+	if changes.AllowedAppCharts != nil {
+		settings.AllowedAppCharts = *changes.AllowedAppCharts
+	}
+	if changes.LogSinkType != nil || changes.LogSinkURL != nil {
+		sink := models.LogSink{}
+		if settings.LogSink != nil {
+			sink = *settings.LogSink
+		}
+		if changes.LogSinkType != nil {
+			sink.Type = *changes.LogSinkType
+		}
+		if changes.LogSinkURL != nil {
+			sink.URL = *changes.LogSinkURL
+		}
+		// An empty type removes the sink.
+		if sink.Type == "" {
+			settings.LogSink = nil
+		} else {
+			settings.LogSink = &sink
+		}
+	}
+
+	_, err = c.API.NamespaceUpdate(namespace, models.NamespaceUpdateRequest{Settings: settings})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Namespace updated.")
+
+	return nil
+}
+
+// SetNamespacePodSecurity changes the pod security level enforced in the namespace
+func (c *EpinioClient) SetNamespacePodSecurity(namespace, level string) error {
+	log := c.Log.WithName("SetNamespacePodSecurity").WithValues("Namespace", namespace, "Level", level)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Name", namespace).
+		WithStringValue("Level", level).
+		Msg("Changing the pod security level of the namespace...")
+
+	_, err := c.API.NamespacePodSecuritySet(namespace, models.NamespacePodSecurityRequest{Level: level})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Pod security level changed.")
+
+	return nil
+}
+
+// SetNamespaceQuota replaces the quota of the namespace. An empty quota removes it.
+func (c *EpinioClient) SetNamespaceQuota(namespace string, quota models.NamespaceQuota) error {
+	log := c.Log.WithName("SetNamespaceQuota").WithValues("Namespace", namespace, "Quota", quota)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Name", namespace).
+		WithStringValue("Quota", formatQuota(quota)).
+		Msg("Changing the quota of the namespace...")
+
+	_, err := c.API.NamespaceQuotaSet(namespace, models.NamespaceQuotaRequest{Quota: quota})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Quota changed.")
+
+	return nil
+}
+
+// SetNamespaceUploadLimit changes the size limit of the application sources uploaded to the
+// namespace. The empty string restores the default limit of the server.
+func (c *EpinioClient) SetNamespaceUploadLimit(namespace, limit string) error {
+	log := c.Log.WithName("SetNamespaceUploadLimit").WithValues("Namespace", namespace, "Limit", limit)
+	log.Info("start")
+	defer log.Info("return")
+
+	shown := limit
+	if shown == "" {
+		shown = "default"
+	}
+	c.ui.Note().
+		WithStringValue("Name", namespace).
+		WithStringValue("Limit", shown).
+		Msg("Changing the upload limit of the namespace...")
+
+	_, err := c.API.NamespaceUploadLimitSet(namespace, models.NamespaceUploadLimitRequest{Limit: limit})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Upload limit changed.")
+
+	return nil
+}
+
+// ParseQuota parses a quota given as comma-separated assignments, e.g.
+// `cpu=20,memory=64Gi,apps=50`. The empty string, and `none`, are no quota.
+func ParseQuota(value string) (models.NamespaceQuota, error) {
+	quota := models.NamespaceQuota{}
+
+	value = strings.TrimSpace(value)
+	if value == "" || value == "none" {
+		return quota, nil
+	}
+
+	for _, assignment := range strings.Split(value, ",") {
+		pieces := strings.SplitN(assignment, "=", 2)
+		key := strings.TrimSpace(pieces[0])
+		if len(pieces) < 2 || key == "" || strings.TrimSpace(pieces[1]) == "" {
+			return nil, errors.Errorf("bad quota '%s', expected `resource=limit`", assignment)
+		}
+		quota[key] = strings.TrimSpace(pieces[1])
+	}
+
+	return quota, nil
+}
+
+// formatQuota renders the quota for the user, sorted by resource.
+func formatQuota(quota models.NamespaceQuota) string {
+	if len(quota) == 0 {
+		return "none"
+	}
+	assignments := []string{}
+	for key, value := range quota {
+		assignments = append(assignments, key+"="+value)
+	}
+	sort.Strings(assignments)
+	return strings.Join(assignments, ",")
+}
+
+// ExportNamespace saves the manifests of the namespace and its resources into the file, or
+// prints them. The data of the secrets is only included if asked for.
+func (c *EpinioClient) ExportNamespace(namespace, file string, secrets bool) error {
+	log := c.Log.WithName("ExportNamespace").WithValues("Namespace", namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	if file != "" {
+		c.ui.Note().
+			WithStringValue("Name", namespace).
+			WithStringValue("Destination", file).
+			WithBoolValue("Secrets", secrets).
+			Msg("Exporting namespace...")
+	}
+
+	stream, err := c.API.NamespaceExport(namespace, secrets)
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		fmt.Fprint(os.Stdout, string(stream))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(file, stream, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write '%s'", file)
+	}
+
+	c.ui.Success().Msg("Saved")
+
+	return nil
+}
+
 // ShowNamepsace shows a Namespace
 func (c *EpinioClient) ShowNamespace(namespace string) error {
 	log := c.Log.WithName("ShowNamespace").WithValues("Namespace", namespace)
@@ -161,16 +432,59 @@ func (c *EpinioClient) ShowNamespace(namespace string) error {
 		return err
 	}
 
-	msg := c.ui.Success().WithTable("Key", "Value")
-
 	sort.Strings(space.Apps)
 	sort.Strings(space.Configurations)
 
+	if c.ui.Machine() {
+		return c.ui.Data(space)
+	}
+
+	msg := c.ui.Success().WithTable("Key", "Value")
+
 	msg = msg.
 		WithTableRow("Name", space.Meta.Name).
 		WithTableRow("Created", fmt.Sprintf("%v", space.Meta.CreatedAt)).
 		WithTableRow("Applications", strings.Join(space.Apps, "\n")).
-		WithTableRow("Configurations", strings.Join(space.Configurations, "\n"))
+		WithTableRow("Configurations", strings.Join(space.Configurations, "\n")).
+		WithTableRow("Builder Image", space.Settings.BuilderImage).
+		WithTableRow("Buildpacks", strings.Join(space.Settings.Buildpacks, "\n")).
+		WithTableRow("Staging CPU Request", space.Settings.Staging.CPURequest).
+		WithTableRow("Staging CPU Limit", space.Settings.Staging.CPULimit).
+		WithTableRow("Staging Memory Request", space.Settings.Staging.MemoryRequest).
+		WithTableRow("Staging Memory Limit", space.Settings.Staging.MemoryLimit)
+
+	if space.Settings.Staging.Timeout > 0 {
+		msg = msg.WithTableRow("Staging Timeout", fmt.Sprintf("%ds", space.Settings.Staging.Timeout))
+	} else {
+		msg = msg.WithTableRow("Staging Timeout", "")
+	}
+	if space.Settings.StagingConcurrency > 0 {
+		msg = msg.WithTableRow("Staging Concurrency", strconv.Itoa(space.Settings.StagingConcurrency))
+	} else {
+		msg = msg.WithTableRow("Staging Concurrency", "")
+	}
+	msg = msg.
+		WithTableRow("App Chart", space.Settings.AppChart).
+		WithTableRow("Allowed App Charts", strings.Join(space.Settings.AllowedAppCharts, "\n"))
+	if space.Settings.LogSink != nil {
+		msg = msg.WithTableRow("Log Sink", fmt.Sprintf("%s %s", space.Settings.LogSink.Type, space.Settings.LogSink.URL))
+	} else {
+		msg = msg.WithTableRow("Log Sink", "")
+	}
+	msg = msg.WithTableRow("Pod Security", space.PodSecurity)
+
+	usage := []string{}
+	for key, status := range space.Quota {
+		usage = append(usage, fmt.Sprintf("%s: %s/%s", key, status.Used, status.Limit))
+	}
+	sort.Strings(usage)
+	msg = msg.WithTableRow("Quota", strings.Join(usage, "\n"))
+
+	if space.UploadLimit != "" && space.UploadLimit != "0" {
+		msg = msg.WithTableRow("Upload Limit", space.UploadLimit)
+	} else {
+		msg = msg.WithTableRow("Upload Limit", "")
+	}
 
 	msg.Msg("Details:")
 
@@ -0,0 +1,31 @@
+package usercmd_test
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseQuota", func() {
+	It("parses comma-separated assignments", func() {
+		quota, err := usercmd.ParseQuota("cpu=20, memory=64Gi,apps=50")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(quota).To(Equal(models.NamespaceQuota{
+			"cpu":    "20",
+			"memory": "64Gi",
+			"apps":   "50",
+		}))
+	})
+
+	It("returns no quota for none", func() {
+		quota, err := usercmd.ParseQuota("none")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(quota).To(BeEmpty())
+	})
+
+	It("rejects assignments without value", func() {
+		_, err := usercmd.ParseQuota("cpu=20,memory")
+		Expect(err).To(MatchError(ContainSubstring("bad quota 'memory'")))
+	})
+})
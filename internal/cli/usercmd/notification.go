@@ -0,0 +1,113 @@
+package usercmd
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/internal/notifications"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// NotificationSet creates the notification target of the targeted namespace, or replaces its
+// settings
+func (c *EpinioClient) NotificationSet(name string, request models.NotificationTargetRequest) error {
+	log := c.Log.WithName("NotificationSet").WithValues("Namespace", c.Settings.Namespace, "Notification", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Notification", name).
+		WithStringValue("Type", request.Type).
+		WithStringValue("Events", notificationEvents(request.Events)).
+		Msg("Setting notification target...")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	_, err := c.API.NotificationSet(c.Settings.Namespace, name, request)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Notification target set.")
+
+	return nil
+}
+
+// NotificationList lists the notification targets of the targeted namespace
+func (c *EpinioClient) NotificationList() error {
+	log := c.Log.WithName("NotificationList").WithValues("Namespace", c.Settings.Namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		Msg("Listing notification targets")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	targets, err := c.API.Notifications(c.Settings.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(targets)
+	}
+
+	if len(targets) == 0 {
+		c.ui.Exclamation().Msg("No notification targets found")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("Name", "Type", "URL", "Events", "Template")
+
+	for _, target := range targets {
+		template := "default"
+		if target.Template != "" {
+			template = "custom"
+		}
+		msg = msg.WithTableRow(target.Name, target.Type, target.URL, notificationEvents(target.Events), template)
+	}
+
+	msg.Msg("Epinio notification targets:")
+
+	return nil
+}
+
+// NotificationDelete deletes the notification target of the targeted namespace
+func (c *EpinioClient) NotificationDelete(name string) error {
+	log := c.Log.WithName("NotificationDelete").WithValues("Namespace", c.Settings.Namespace, "Notification", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Namespace", c.Settings.Namespace).
+		WithStringValue("Notification", name).
+		Msg("Deleting notification target...")
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	_, err := c.API.NotificationDelete(c.Settings.Namespace, name)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Notification target deleted.")
+
+	return nil
+}
+
+// notificationEvents returns the event types of a notification target, for display. No types
+// stand for the default ones.
+func notificationEvents(events []string) string {
+	if len(events) == 0 {
+		return "default (" + strings.Join(notifications.DefaultEvents, ", ") + ")"
+	}
+	return strings.Join(events, ", ")
+}
@@ -0,0 +1,162 @@
+package usercmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// offlineTTL is the time the responses saved in the offline cache are used when the API is
+// unreachable. Older responses are dropped.
+const offlineTTL = 24 * time.Hour
+
+// offlineEntry holds a response of the API, as received at the given time.
+type offlineEntry struct {
+	Data json.RawMessage `json:"data"`
+	Time time.Time       `json:"time"`
+}
+
+// offlineCache maps the kinds of responses, per API, to the last response received.
+type offlineCache map[string]offlineEntry
+
+// offline runs the fetch function, which is expected to set the result, and saves the result
+// in the offline cache. When the API is unreachable the result is loaded from the cache
+// instead, if there, and the time it was received is returned. The time is zero for results
+// fresh from the API. Other errors are returned as they are.
+func (c *EpinioClient) offline(kind string, result interface{}, fetch func() error) (time.Time, error) {
+	log := c.Log.WithName("Offline").WithValues("Kind", kind)
+	key := strings.Join([]string{c.Settings.API, kind}, "|")
+
+	err := fetch()
+	if err == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			// Not cacheable, still a good result.
+			return time.Time{}, nil
+		}
+
+		cache := loadOfflineCache()
+		cache[key] = offlineEntry{Data: data, Time: time.Now()}
+		saveOfflineCache(cache)
+
+		return time.Time{}, nil
+	}
+
+	if !epinioapi.IsUnreachable(err) {
+		return time.Time{}, err
+	}
+
+	entry, ok := loadOfflineCache()[key]
+	if !ok || time.Since(entry.Time) > offlineTTL {
+		return time.Time{}, err
+	}
+	if jerr := json.Unmarshal(entry.Data, result); jerr != nil {
+		return time.Time{}, err
+	}
+
+	log.Info("api unreachable, using cache", "error", err.Error(), "time", entry.Time)
+	return entry.Time, nil
+}
+
+// catalog returns the service catalog, from the offline cache if the API is unreachable, see
+// offline.
+func (c *EpinioClient) catalog() (*models.ServiceCatalogResponse, time.Time, error) {
+	var catalog *models.ServiceCatalogResponse
+	received, err := c.offline("catalog", &catalog, func() (err error) {
+		catalog, err = c.API.ServiceCatalog()
+		return
+	})
+	return catalog, received, err
+}
+
+// catalogService returns the named service of the catalog, from the offline cache if the API
+// is unreachable, see offline.
+func (c *EpinioClient) catalogService(name string) (*models.ServiceCatalogShowResponse, time.Time, error) {
+	var service *models.ServiceCatalogShowResponse
+	received, err := c.offline("catalog/"+name, &service, func() (err error) {
+		service, err = c.API.ServiceCatalogShow(name)
+		return
+	})
+	return service, received, err
+}
+
+// charts returns the application charts, from the offline cache if the API is unreachable,
+// see offline.
+func (c *EpinioClient) charts() ([]models.AppChart, time.Time, error) {
+	var charts []models.AppChart
+	received, err := c.offline("charts", &charts, func() (err error) {
+		charts, err = c.API.ChartList()
+		return
+	})
+	return charts, received, err
+}
+
+// namespaces returns the namespaces, from the offline cache if the API is unreachable, see
+// offline.
+func (c *EpinioClient) namespaces() (models.NamespaceList, time.Time, error) {
+	var namespaces models.NamespaceList
+	received, err := c.offline("namespaces", &namespaces, func() (err error) {
+		namespaces, err = c.API.Namespaces()
+		return
+	})
+	return namespaces, received, err
+}
+
+// staleWarning tells the user that the shown data is from the offline cache, and how old it is,
+// if so.
+func (c *EpinioClient) staleWarning(received time.Time) {
+	if received.IsZero() {
+		return
+	}
+	c.ui.Exclamation().
+		Msgf("The API is unreachable. Showing cached data, received %s ago",
+			time.Since(received).Round(time.Second))
+}
+
+// loadOfflineCache returns the offline cache, without the entries past their TTL. A missing
+// or broken cache file is an empty cache.
+func loadOfflineCache() offlineCache {
+	cache := offlineCache{}
+
+	path, err := cachePath("offline.json")
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return offlineCache{}
+	}
+
+	for key, entry := range cache {
+		if time.Since(entry.Time) > offlineTTL {
+			delete(cache, key)
+		}
+	}
+
+	return cache
+}
+
+// saveOfflineCache writes the offline cache. Failures are ignored, they only lose the
+// fallback for an unreachable API.
+func saveOfflineCache(cache offlineCache) {
+	path, err := cachePath("offline.json")
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
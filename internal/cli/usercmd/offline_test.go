@@ -0,0 +1,70 @@
+package usercmd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Offline cache", func() {
+	var server *httptest.Server
+	var status int
+	var epinioClient *usercmd.EpinioClient
+
+	BeforeEach(func() {
+		cacheDir, err := os.MkdirTemp("", "epinio-offline")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, cacheDir)
+
+		oldCache, had := os.LookupEnv("XDG_CACHE_HOME")
+		Expect(os.Setenv("XDG_CACHE_HOME", cacheDir)).To(Succeed())
+		DeferCleanup(func() {
+			if had {
+				os.Setenv("XDG_CACHE_HOME", oldCache)
+			} else {
+				os.Unsetenv("XDG_CACHE_HOME")
+			}
+		})
+
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			if status == http.StatusOK {
+				_, _ = w.Write([]byte(`{"catalog_services":[{"name":"mysql-dev"}]}`))
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		apiClient := epinioapi.New(server.URL, "", "", "")
+		apiClient.SetRetryPolicy(epinioapi.RetryPolicy{Attempts: 1})
+
+		epinioClient, err = usercmd.NewEpinioClient(&settings.Settings{
+			API:       server.URL,
+			Namespace: "workspace",
+		}, apiClient)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("shows the cached catalog when the API is unreachable", func() {
+		Expect(epinioClient.ServiceCatalog()).To(Succeed())
+		server.Close()
+		Expect(epinioClient.ServiceCatalog()).To(Succeed())
+	})
+
+	It("fails without cached catalog", func() {
+		server.Close()
+		Expect(epinioClient.ServiceCatalog()).ToNot(Succeed())
+	})
+
+	It("does not hide errors of a reachable API", func() {
+		Expect(epinioClient.ServiceCatalog()).To(Succeed())
+		status = http.StatusInternalServerError
+		Expect(epinioClient.ServiceCatalog()).ToNot(Succeed())
+	})
+})
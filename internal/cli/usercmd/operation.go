@@ -0,0 +1,108 @@
+package usercmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+// operationPollInterval is the time between two queries for the state of a long-running
+// operation.
+const operationPollInterval = 2 * time.Second
+
+// OperationShow shows the long-running operation, e.g. a staging. With wait it waits for the
+// operation to finish first.
+func (c *EpinioClient) OperationShow(id string, wait bool) error {
+	log := c.Log.WithName("OperationShow").WithValues("Operation", id)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Operation", id).
+		Msg("Showing operation...")
+
+	var operation models.Operation
+	var err error
+	if wait {
+		operation, err = c.pollOperation(id)
+	} else {
+		operation, err = c.API.OperationShow(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(operation)
+	}
+
+	msg := c.ui.Success().WithTable("Key", "Value").
+		WithTableRow("Kind", operation.Kind).
+		WithTableRow("Namespace", operation.Namespace).
+		WithTableRow("Name", operation.Name).
+		WithTableRow("User", operation.User).
+		WithTableRow("Phase", string(operation.Phase)).
+		WithTableRow("Progress", operation.Progress).
+		WithTableRow("Error", operation.Error).
+		WithTableRow("Error Details", operation.ErrorDetails).
+		WithTableRow("Created", operation.CreatedAt.Format(time.RFC3339)).
+		WithTableRow("Updated", operation.UpdatedAt.Format(time.RFC3339))
+
+	keys := []string{}
+	for key := range operation.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		msg = msg.WithTableRow(key, operation.Details[key])
+	}
+
+	msg.Msg("Details:")
+
+	return nil
+}
+
+// waitForOperation blocks until the long-running operation is done, reporting the changes of
+// its progress. It fails for failed operations, with their error.
+func (c *EpinioClient) waitForOperation(id string) (models.Operation, error) {
+	operation, err := c.pollOperation(id)
+	if err != nil {
+		return operation, err
+	}
+	if operation.Phase == models.OperationFailed {
+		return operation, errors.New(operationError(operation))
+	}
+	return operation, nil
+}
+
+// pollOperation blocks until the long-running operation is done, reporting the changes of its
+// progress, and returns it.
+func (c *EpinioClient) pollOperation(id string) (models.Operation, error) {
+	progress := ""
+	for {
+		operation, err := c.API.OperationShow(id)
+		if err != nil {
+			return operation, errors.Wrap(err, "waiting for the operation failed")
+		}
+		if operation.Phase.Done() {
+			return operation, nil
+		}
+		if operation.Progress != progress && operation.Progress != "" {
+			c.ui.ProgressNote().Msg(fmt.Sprintf("%s %s: %s", operation.Kind, operation.Name, operation.Progress))
+		}
+		progress = operation.Progress
+
+		time.Sleep(operationPollInterval)
+	}
+}
+
+// operationError returns the message of the error of the failed operation.
+func operationError(operation models.Operation) string {
+	if operation.ErrorDetails == "" {
+		return operation.Error
+	}
+	return fmt.Sprintf("%s: %s", operation.Error, operation.ErrorDetails)
+}
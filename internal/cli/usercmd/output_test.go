@@ -0,0 +1,142 @@
+package usercmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("Machine-readable output", func() {
+	var mockClient *mockAPIClient
+	var epinioClient *usercmd.EpinioClient
+
+	// stdout returns what the function printed on stdout.
+	stdout := func(f func() error) string {
+		reader, writer, err := os.Pipe()
+		Expect(err).ToNot(HaveOccurred())
+
+		old := os.Stdout
+		os.Stdout = writer
+		ferr := f()
+		os.Stdout = old
+		Expect(writer.Close()).To(Succeed())
+		Expect(ferr).ToNot(HaveOccurred())
+
+		out, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		return string(out)
+	}
+
+	BeforeEach(func() {
+		cacheDir, err := os.MkdirTemp("", "epinio-output")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, cacheDir)
+
+		oldCache, had := os.LookupEnv("XDG_CACHE_HOME")
+		Expect(os.Setenv("XDG_CACHE_HOME", cacheDir)).To(Succeed())
+		DeferCleanup(func() {
+			if had {
+				os.Setenv("XDG_CACHE_HOME", oldCache)
+			} else {
+				os.Unsetenv("XDG_CACHE_HOME")
+			}
+		})
+
+		viper.Set("output", "json")
+		DeferCleanup(viper.Set, "output", "")
+
+		mockClient = &mockAPIClient{}
+		epinioClient, err = usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("prints the configurations", func() {
+		mockClient.mockConfigurations = func(namespace string) (models.ConfigurationResponseList, error) {
+			return models.ConfigurationResponseList{{
+				Meta:          models.ConfigurationRef{Meta: models.Meta{Name: "credentials", Namespace: namespace}},
+				Configuration: models.ConfigurationShowResponse{BoundApps: []string{"shop"}},
+			}}, nil
+		}
+
+		var configurations models.ConfigurationResponseList
+		Expect(json.Unmarshal([]byte(stdout(func() error {
+			return epinioClient.Configurations(false)
+		})), &configurations)).To(Succeed())
+
+		Expect(configurations).To(HaveLen(1))
+		Expect(configurations[0].Meta.Name).To(Equal("credentials"))
+		Expect(configurations[0].Configuration.BoundApps).To(Equal([]string{"shop"}))
+	})
+
+	It("prints the details of a configuration", func() {
+		mockClient.mockConfigurationShow = func(namespace, name string) (models.ConfigurationResponse, error) {
+			return models.ConfigurationResponse{
+				Meta:          models.ConfigurationRef{Meta: models.Meta{Name: name, Namespace: namespace}},
+				Configuration: models.ConfigurationShowResponse{Details: map[string]string{"user": "admin"}},
+			}, nil
+		}
+
+		var configuration models.ConfigurationResponse
+		Expect(json.Unmarshal([]byte(stdout(func() error {
+			return epinioClient.ConfigurationDetails("credentials")
+		})), &configuration)).To(Succeed())
+
+		Expect(configuration.Meta.Name).To(Equal("credentials"))
+		Expect(configuration.Configuration.Details).To(Equal(map[string]string{"user": "admin"}))
+	})
+
+	It("prints the service catalog", func() {
+		mockClient.mockServiceCatalog = func() (*models.ServiceCatalogResponse, error) {
+			return &models.ServiceCatalogResponse{CatalogServices: []*models.CatalogService{
+				{Meta: models.MetaLite{Name: "mysql-dev"}, AppVersion: "8.0"},
+			}}, nil
+		}
+
+		var services []models.CatalogService
+		Expect(json.Unmarshal([]byte(stdout(epinioClient.ServiceCatalog)), &services)).To(Succeed())
+
+		Expect(services).To(HaveLen(1))
+		Expect(services[0].Meta.Name).To(Equal("mysql-dev"))
+		Expect(services[0].AppVersion).To(Equal("8.0"))
+	})
+
+	It("prints a service of the catalog", func() {
+		mockClient.mockServiceCatalogShow = func(serviceName string) (*models.ServiceCatalogShowResponse, error) {
+			return &models.ServiceCatalogShowResponse{CatalogService: &models.CatalogService{
+				Meta:        models.MetaLite{Name: serviceName},
+				Description: "MySQL for development",
+			}}, nil
+		}
+
+		var service models.CatalogService
+		Expect(json.Unmarshal([]byte(stdout(func() error {
+			return epinioClient.ServiceCatalogShow("mysql-dev")
+		})), &service)).To(Succeed())
+
+		Expect(service.Meta.Name).To(Equal("mysql-dev"))
+		Expect(service.Description).To(Equal("MySQL for development"))
+	})
+
+	It("prints the application charts", func() {
+		mockClient.mockChartList = func() ([]models.AppChart, error) {
+			return []models.AppChart{{Meta: models.MetaLite{Name: "standard"}, HelmChart: "epinio-application"}}, nil
+		}
+
+		var charts []models.AppChart
+		Expect(json.Unmarshal([]byte(stdout(func() error {
+			return epinioClient.ChartList(context.Background())
+		})), &charts)).To(Succeed())
+
+		Expect(charts).To(HaveLen(1))
+		Expect(charts[0].Meta.Name).To(Equal("standard"))
+		Expect(charts[0].HelmChart).To(Equal("epinio-application"))
+	})
+})
@@ -3,25 +3,40 @@ package usercmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/epinio/epinio/helpers"
+	"github.com/epinio/epinio/helpers/bytes"
 	"github.com/epinio/epinio/helpers/kubernetes/tailer"
 	"github.com/epinio/epinio/internal/cli/logprinter"
 	"github.com/epinio/epinio/internal/duration"
+	"github.com/epinio/epinio/internal/manifest"
+	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 )
 
+// stagingQueuePollInterval is the time between two queries for the queue position of a
+// queued staging job.
+const stagingQueuePollInterval = 3 * time.Second
+
 type PushParams struct {
 	models.ApplicationManifest
+	Strategy       string // Deployment strategy. Empty, or models.StrategyCanary
+	Weight         int    // Canary only: Percentage of traffic for the new version
+	ErrorThreshold int    // Canary only: Error rate (percent) to abort the canary at
+	DryRun         bool   // Show the changes to the app, without making them
+	Archive        string // Path of a tarball of the sources to upload as is, "-" for stdin
 }
 
 // Push pushes an app
@@ -32,7 +47,17 @@ type PushParams struct {
 // * wait for staging to be done (complete or fail)
 // * deploy
 // * wait for app
-func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // nolint: gocyclo // Many ifs for view purposes
+// With machine-readable output the progress is printed as a stream of PushEvents.
+func (c *EpinioClient) Push(ctx context.Context, params PushParams) error {
+	err := c.push(ctx, params)
+	if err != nil {
+		c.pushEvent(params.Name, PushEvent{Event: PushEventError, Message: err.Error()})
+	}
+	return err
+}
+
+// push implements Push, see there.
+func (c *EpinioClient) push(ctx context.Context, params PushParams) error { // nolint: gocyclo // Many ifs for view purposes
 
 	// Use settings default if user did not specify --app-chart
 	if params.Configuration.AppChart == "" {
@@ -70,16 +95,69 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 		return err
 	}
 
+	// Without a choice of builder, local sources with a Dockerfile are built with it.
+	if params.Origin.Kind == models.OriginPath && params.Archive == "" &&
+		params.Staging.Builder == "" && len(params.Staging.Buildpacks) == 0 &&
+		params.Staging.Dockerfile == "" {
+		if _, err := os.Stat(filepath.Join(params.Origin.Path, "Dockerfile")); err == nil {
+			params.Staging.Dockerfile = "Dockerfile"
+		}
+	}
+
+	// Process types declared by the Procfile of local sources, and not configured otherwise,
+	// run with a single instance. For an existing app they keep their instances, see below.
+	detected := []string{}
+	if params.Origin.Kind == models.OriginPath && params.Archive == "" {
+		processTypes, err := manifest.ProcessTypes(params.Origin.Path)
+		if err != nil {
+			return err
+		}
+		for _, name := range processTypes {
+			if _, ok := params.Configuration.Processes[name]; ok {
+				continue
+			}
+			if params.Configuration.Processes == nil {
+				params.Configuration.Processes = map[string]int32{}
+			}
+			params.Configuration.Processes[name] = 1
+			detected = append(detected, name)
+		}
+	}
+
 	// Show builder, if relevant (i.e. path/git sources, not for container)
-	if params.Origin.Kind != models.OriginContainer &&
-		params.Staging.Builder != "" {
-		msg = msg.WithStringValue("Builder", params.Staging.Builder)
+	if params.Origin.Kind != models.OriginContainer {
+		if params.Staging.Builder != "" {
+			msg = msg.WithStringValue("Builder", params.Staging.Builder)
+		}
+		if len(params.Staging.Buildpacks) > 0 {
+			msg = msg.WithStringValue("Buildpacks", strings.Join(params.Staging.Buildpacks, ", "))
+		}
+		if params.Staging.Dockerfile != "" {
+			msg = msg.WithStringValue("Dockerfile", params.Staging.Dockerfile)
+		}
+		if params.Staging.Cache != "" {
+			msg = msg.WithStringValue("Build Cache", params.Staging.Cache)
+		}
+		if len(params.Staging.Environment) > 0 {
+			names := []string{}
+			for _, ev := range params.Staging.Environment.List() {
+				names = append(names, ev.Name)
+			}
+			msg = msg.WithStringValue("Build Environment", strings.Join(names, ", "))
+		}
 	}
 
 	if params.Configuration.Instances != nil {
 		msg = msg.WithStringValue("Instances",
 			strconv.Itoa(int(*params.Configuration.Instances)))
 	}
+	for _, name := range sortedProcessTypes(params.Configuration.Processes) {
+		msg = msg.WithStringValue(fmt.Sprintf("Process '%s'", name),
+			strconv.Itoa(int(params.Configuration.Processes[name])))
+	}
+	for _, volume := range params.Configuration.Volumes {
+		msg = msg.WithStringValue(fmt.Sprintf("Volume '%s'", volume.Name), volume.Path)
+	}
 	if len(params.Configuration.Configurations) > 0 {
 		msg = msg.WithStringValue("Configurations",
 			strings.Join(params.Configuration.Configurations, ", "))
@@ -92,6 +170,22 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 		}
 	}
 
+	if params.Strategy != "" {
+		msg = msg.WithStringValue("Strategy", params.Strategy)
+	}
+	if params.Strategy == models.StrategyCanary {
+		weight := params.Weight
+		if weight == 0 {
+			weight = models.CanaryWeightDefault
+		}
+		msg = msg.WithStringValue("Weight", fmt.Sprintf("%d%%", weight))
+	}
+
+	if params.DryRun {
+		msg.Msg("Comparing the given setup against the application")
+		return c.pushDryRun(appRef, params, detected)
+	}
+
 	msg.Msg("About to push an application with the given setup")
 
 	c.ui.Exclamation().
@@ -106,6 +200,7 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 
 	// AppCreate
 	c.ui.Normal().Msg("Create the application resource ...")
+	c.pushEvent(appRef.Name, PushEvent{Event: PushEventCreate, Phase: PushPhaseStarted})
 
 	request := models.ApplicationCreateRequest{
 		Name:          appRef.Name,
@@ -127,11 +222,23 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 
 		c.ui.Normal().Msg("Application exists, updating ...")
 		details.Info("app exists conflict")
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventUpdate, Phase: PushPhaseStarted})
+
+		update := params.Configuration
+		if len(detected) > 0 {
+			update, err = c.keepProcesses(appRef, update, detected)
+			if err != nil {
+				return err
+			}
+		}
 
-		_, err := c.API.AppUpdate(params.Configuration, appRef.Namespace, appRef.Name)
+		_, err := c.API.AppUpdate(update, appRef.Namespace, appRef.Name)
 		if err != nil {
 			return err
 		}
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventUpdate, Phase: PushPhaseFinished})
+	} else {
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventCreate, Phase: PushPhaseFinished})
 	}
 
 	// AppUpload / AppImportGit
@@ -140,26 +247,33 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 	case models.OriginNone:
 		return fmt.Errorf("%s", "No application origin")
 	case models.OriginPath:
-		c.ui.Normal().Msg("Collecting the application sources ...")
-
-		tmpDir, tarball, err := helpers.Tar(source)
-		defer func() {
-			if tmpDir != "" {
-				_ = os.RemoveAll(tmpDir)
+		if params.Archive != "" {
+			c.pushEvent(appRef.Name, PushEvent{Event: PushEventUpload, Phase: PushPhaseStarted})
+			upload, err := c.uploadArchive(appRef, params.Archive)
+			if err != nil {
+				return err
 			}
-		}()
-		if err != nil {
-			return err
+			log.V(3).Info("upload response", "response", upload)
+			c.pushEvent(appRef.Name, PushEvent{Event: PushEventUpload, Phase: PushPhaseFinished})
+
+			blobUID = upload.BlobUID
+			break
 		}
 
-		c.ui.Normal().Msg("Uploading application code ...")
+		c.ui.Normal().Msg("Uploading changed application code ...")
 
-		details.Info("upload code")
-		upload, err := c.API.AppUpload(appRef.Namespace, appRef.Name, tarball)
+		details.Info("upload code delta")
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventUpload, Phase: PushPhaseStarted})
+		upload, err := c.API.AppUploadDelta(appRef.Namespace, appRef.Name, source, c.uploadProgress(appRef.Name))
+		if errors.Is(err, epinioapi.ErrDeltaUnsupported) {
+			details.Info("upload code")
+			upload, err = c.uploadTarball(appRef, source)
+		}
 		if err != nil {
 			return err
 		}
 		log.V(3).Info("upload response", "response", upload)
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventUpload, Phase: PushPhaseFinished})
 
 		blobUID = upload.BlobUID
 
@@ -171,10 +285,12 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 			return errors.New("git origin is nil")
 		}
 
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventImport, Phase: PushPhaseStarted})
 		response, err := c.API.AppImportGit(appRef, *gitOrigin)
 		if err != nil {
 			return errors.Wrap(err, "importing git remote")
 		}
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventImport, Phase: PushPhaseFinished})
 
 		blobUID = response.BlobUID
 
@@ -192,6 +308,10 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 			App:          appRef,
 			BlobUID:      blobUID,
 			BuilderImage: params.Staging.Builder,
+			Buildpacks:   params.Staging.Buildpacks,
+			Dockerfile:   params.Staging.Dockerfile,
+			Cache:        params.Staging.Cache,
+			Environment:  params.Staging.Environment,
 		}
 		details.Info("staging code", "Blob", blobUID)
 		stageResponse, err = c.API.AppStage(req)
@@ -200,19 +320,33 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 		}
 		stageID = stageResponse.Stage.ID
 		log.V(3).Info("stage response", "response", stageResponse)
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventStage, Phase: PushPhaseStarted, StageID: stageID})
+
+		if stageResponse.QueuePosition > 0 {
+			err = c.waitForStagingQueue(appRef, stageID, stageResponse.QueuePosition)
+			if err != nil {
+				return err
+			}
+		}
 
 		details.Info("start tailing logs", "StageID", stageResponse.Stage.ID)
-		err = c.stageLogs(details, appRef, stageResponse.Stage.ID)
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventStage, Phase: PushPhaseRunning, StageID: stageID})
+		err = c.stageLogs(details, appRef, stageResponse.Stage.ID, stageResponse.OperationID)
 		if err != nil {
 			return err
 		}
+		c.pushEvent(appRef.Name, PushEvent{Event: PushEventStage, Phase: PushPhaseFinished, StageID: stageID})
 	}
 
 	// AppDeploy
 	c.ui.Normal().Msg("Deploying application ...")
+	c.pushEvent(appRef.Name, PushEvent{Event: PushEventDeploy, Phase: PushPhaseStarted})
 	deployRequest := models.DeployRequest{
-		App:    appRef,
-		Origin: params.Origin,
+		App:            appRef,
+		Origin:         params.Origin,
+		Strategy:       params.Strategy,
+		Weight:         params.Weight,
+		ErrorThreshold: params.ErrorThreshold,
 	}
 	// If container param is specified, then we just take it into ImageURL
 	// If not, we take the one from the staging response
@@ -223,7 +357,7 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 		deployRequest.Stage = models.StageRef{ID: stageID}
 	}
 
-	deployResponse, err := c.API.AppDeploy(deployRequest)
+	deployResponse, err := c.API.AppDeployAsync(deployRequest)
 	if err != nil {
 		return err
 	}
@@ -231,13 +365,23 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 	details.Info("wait for application resources")
 	c.ui.ProgressNote().KeeplineUnder(1).Msg("Creating application resources")
 
-	_, err = c.API.AppRunning(appRef)
-	if err != nil {
-		return errors.Wrap(err, "waiting for app failed")
+	deployedRoutes := deployResponse.Routes
+	if deployResponse.OperationID != "" {
+		operation, err := c.waitForOperation(deployResponse.OperationID)
+		if err != nil {
+			return errors.Wrap(err, "deploying app failed")
+		}
+		deployedRoutes = operationRoutes(operation)
+	} else {
+		_, err = c.API.AppRunning(appRef)
+		if err != nil {
+			return errors.Wrap(err, "waiting for app failed")
+		}
 	}
+	c.pushEvent(appRef.Name, PushEvent{Event: PushEventDeploy, Phase: PushPhaseFinished})
 
 	routes := []string{}
-	for _, d := range deployResponse.Routes {
+	for _, d := range deployedRoutes {
 		routes = append(routes, fmt.Sprintf("https://%s", d))
 	}
 
@@ -251,14 +395,85 @@ func (c *EpinioClient) Push(ctx context.Context, params PushParams) error { // n
 		sort.Strings(routes)
 		for i, r := range routes {
 			msg = msg.WithStringValue(strconv.Itoa(i+1), r)
+			c.pushEvent(appRef.Name, PushEvent{Event: PushEventRoute, Route: r})
 		}
 	}
 	msg.Msg("App is online.")
+	c.pushEvent(appRef.Name, PushEvent{Event: PushEventDone})
 
 	return nil
 }
 
-func (c *EpinioClient) stageLogs(logger logr.Logger, appRef models.AppRef, stageID string) error {
+// operationRoutes returns the routes of the app deployed by the operation.
+func operationRoutes(operation models.Operation) []string {
+	if operation.Details["routes"] == "" {
+		return []string{}
+	}
+	return strings.Split(operation.Details["routes"], ",")
+}
+
+// keepProcesses removes the detected process types already known to the existing app
+// from the update, so that they keep their instances.
+func (c *EpinioClient) keepProcesses(appRef models.AppRef, update models.ApplicationUpdateRequest, detected []string) (models.ApplicationUpdateRequest, error) {
+	app, err := c.API.AppShow(appRef.Namespace, appRef.Name)
+	if err != nil {
+		return update, err
+	}
+
+	processes := map[string]int32{}
+	for name, instances := range update.Processes {
+		processes[name] = instances
+	}
+	for _, name := range detected {
+		if _, ok := app.Configuration.Processes[name]; ok {
+			delete(processes, name)
+		}
+	}
+
+	update.Processes = processes
+	return update, nil
+}
+
+// sortedProcessTypes returns the names of the process types in order.
+func sortedProcessTypes(processes map[string]int32) []string {
+	result := []string{}
+	for name := range processes {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// waitForStagingQueue blocks until the queued staging job got a free slot, reporting the
+// changes of its position in the queue.
+func (c *EpinioClient) waitForStagingQueue(appRef models.AppRef, stageID string, position int) error {
+	c.ui.Note().
+		WithStringValue("Position", strconv.Itoa(position)).
+		Msg("Staging is queued, waiting for a free slot")
+	c.pushEvent(appRef.Name, PushEvent{Event: PushEventStage, Phase: PushPhaseQueued,
+		StageID: stageID, Position: position})
+
+	for position > 0 {
+		time.Sleep(stagingQueuePollInterval)
+
+		resp, err := c.API.StagingQueue(appRef.Namespace, stageID)
+		if err != nil {
+			return errors.Wrap(err, "waiting for the staging queue failed")
+		}
+		if resp.Position != position && resp.Position > 0 {
+			c.ui.ProgressNote().Msg(fmt.Sprintf("Queue position %d", resp.Position))
+			c.pushEvent(appRef.Name, PushEvent{Event: PushEventStage, Phase: PushPhaseQueued,
+				StageID: stageID, Position: resp.Position})
+		}
+		position = resp.Position
+	}
+
+	return nil
+}
+
+// stageLogs streams the logs of the staging until it is done, as per its operation, or, for
+// servers without operations, the staging complete endpoint.
+func (c *EpinioClient) stageLogs(logger logr.Logger, appRef models.AppRef, stageID, operationID string) error {
 	c.ui.Note().
 		WithStringValue("Namespace", c.Settings.Namespace).
 		WithStringValue("Application", appRef.Name).
@@ -275,15 +490,20 @@ func (c *EpinioClient) stageLogs(logger logr.Logger, appRef models.AppRef, stage
 			}, c.ui.ProgressNote().Compact())
 		}
 
-		err := c.API.AppLogs(c.Settings.Namespace, appRef.Name, stageID, true, callback)
+		err := c.API.AppLogs(c.Settings.Namespace, appRef.Name, stageID, true, models.LogParameters{}, callback)
 		if err != nil {
 			c.ui.Problem().Msg(fmt.Sprintf("failed to tail logs: %s", err.Error()))
 		}
 	}()
 
-	logger.Info("wait for job", "StageID", stageID)
+	logger.Info("wait for job", "StageID", stageID, "Operation", operationID)
 	c.ui.ProgressNote().Msg("Running staging")
 
+	if operationID != "" {
+		_, err := c.waitForOperation(operationID)
+		return errors.Wrap(err, "staging failed")
+	}
+
 	// blocking function that wait until the staging is done
 	_, err := c.API.StagingComplete(appRef.Namespace, stageID)
 	if err != nil {
@@ -292,3 +512,104 @@ func (c *EpinioClient) stageLogs(logger logr.Logger, appRef models.AppRef, stage
 
 	return err
 }
+
+// uploadTarball uploads the application sources in the directory as a whole, for servers not
+// supporting uploads as delta.
+func (c *EpinioClient) uploadTarball(appRef models.AppRef, source string) (models.UploadResponse, error) {
+	c.ui.Normal().Msg("Collecting the application sources ...")
+
+	tmpDir, tarball, err := helpers.Tar(source)
+	defer func() {
+		if tmpDir != "" {
+			_ = os.RemoveAll(tmpDir)
+		}
+	}()
+	if err != nil {
+		return models.UploadResponse{}, err
+	}
+
+	c.ui.Normal().Msg("Uploading application code ...")
+
+	return c.API.AppUpload(appRef.Namespace, appRef.Name, tarball, c.uploadProgress(appRef.Name))
+}
+
+// uploadArchive uploads the archive of the application sources as is, for the staging to
+// unpack. An archive read from stdin is saved into a temporary file first, as the upload
+// needs its size.
+func (c *EpinioClient) uploadArchive(appRef models.AppRef, archive string) (models.UploadResponse, error) {
+	if archive == "-" {
+		c.ui.Normal().Msg("Reading the application sources from stdin ...")
+
+		tmpFile, err := os.CreateTemp("", "epinio-archive")
+		if err != nil {
+			return models.UploadResponse{}, errors.Wrap(err, "can't create temporary file")
+		}
+		defer func() {
+			_ = os.Remove(tmpFile.Name())
+		}()
+
+		_, err = io.Copy(tmpFile, os.Stdin)
+		if cerr := tmpFile.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return models.UploadResponse{}, errors.Wrap(err, "can't read the archive from stdin")
+		}
+		archive = tmpFile.Name()
+	}
+
+	if err := checkArchive(archive); err != nil {
+		return models.UploadResponse{}, err
+	}
+
+	c.ui.Normal().Msg("Uploading application code archive ...")
+
+	return c.API.AppUpload(appRef.Namespace, appRef.Name, archive, c.uploadProgress(appRef.Name))
+}
+
+// checkArchive checks that the file is a tarball, possibly compressed, or a zip archive, i.e.
+// something the staging can unpack.
+func checkArchive(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "archive not accessible")
+	}
+	defer file.Close()
+
+	header := make([]byte, 262)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return errors.Wrap(err, "can't read the archive")
+	}
+	start := string(header[:n])
+
+	switch {
+	case strings.HasPrefix(start, "\x1f\x8b"): // gzip
+	case strings.HasPrefix(start, "PK\x03\x04"): // zip
+	case len(start) >= 262 && start[257:262] == "ustar": // tar
+	default:
+		return errors.New("the archive is not a tarball, compressed tarball, or zip archive")
+	}
+
+	return nil
+}
+
+// uploadProgress returns the progress callback of source uploads of the named application. It
+// reports every tenth of the upload.
+func (c *EpinioClient) uploadProgress(name string) epinioapi.UploadProgress {
+	reported := int64(0)
+	return func(sent, total int64) {
+		if total <= 0 {
+			return
+		}
+		tenths := sent * 10 / total
+		if tenths <= reported {
+			return
+		}
+		reported = tenths
+		c.ui.ProgressNote().Compact().Msg(fmt.Sprintf("%s / %s (%d%%)",
+			bytes.ByteCountIEC(sent), bytes.ByteCountIEC(total), sent*100/total))
+		c.pushEvent(name, PushEvent{Event: PushEventUpload, Phase: PushPhaseProgress,
+			Percent: sent * 100 / total, Sent: sent, Total: total})
+	}
+}
@@ -0,0 +1,271 @@
+package usercmd
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Kinds of changes for `PushChange.Change`.
+const (
+	PushAdded   = "added"
+	PushRemoved = "removed"
+	PushChanged = "changed"
+)
+
+// PushChange is a single change a push makes to the setup of an application.
+type PushChange struct {
+	Setting string `json:"setting"`
+	Change  string `json:"change"`
+	Current string `json:"current,omitempty"`
+	Pushed  string `json:"pushed,omitempty"`
+}
+
+// PushDiff describes what a push changes for an application, i.e. whether it creates the
+// application, the changes to its setup, and whether its sources are staged again, and why.
+type PushDiff struct {
+	Name          string       `json:"name"`
+	Namespace     string       `json:"namespace"`
+	Create        bool         `json:"create"`
+	Changes       []PushChange `json:"changes"`
+	Rebuild       bool         `json:"rebuild"`
+	RebuildReason string       `json:"rebuildReason,omitempty"`
+}
+
+// pushDryRun shows what the push would change for the application, without changing it.
+// Detected process types known to the application keep their instances, as for a real push.
+func (c *EpinioClient) pushDryRun(appRef models.AppRef, params PushParams, detected []string) error {
+	create := false
+	app, err := c.API.AppShow(appRef.Namespace, appRef.Name)
+	if err != nil {
+		rerr, ok := err.(interface{ StatusCode() int })
+		if !ok || rerr.StatusCode() != http.StatusNotFound {
+			return err
+		}
+		create = true
+		app = *models.NewApp(appRef.Name, appRef.Namespace)
+	}
+
+	if !create {
+		for _, name := range detected {
+			if _, ok := app.Configuration.Processes[name]; ok {
+				delete(params.Configuration.Processes, name)
+			}
+		}
+	}
+
+	diff := PushChanges(app, params)
+	diff.Create = create
+
+	if c.ui.Machine() {
+		return c.ui.Data(diff)
+	}
+
+	title := "Changes to the application"
+	if create {
+		title = "The application does not exist and is created"
+	}
+
+	if len(diff.Changes) == 0 {
+		c.ui.Normal().Msg("No changes to the setup of the application")
+	} else {
+		msg := c.ui.Success().WithTable("Setting", "Change", "Current", "Pushed")
+		for _, change := range diff.Changes {
+			msg = msg.WithTableRow(change.Setting, change.Change, change.Current, change.Pushed)
+		}
+		msg.Msg(title)
+	}
+
+	rebuild := "no"
+	if diff.Rebuild {
+		rebuild = "yes"
+	}
+	msg := c.ui.Note().WithStringValue("Rebuild", rebuild)
+	if diff.RebuildReason != "" {
+		msg = msg.WithStringValue("Reason", diff.RebuildReason)
+	}
+	msg.Msg("Dry run, nothing was changed")
+
+	return nil
+}
+
+// PushChanges compares the pushed manifest against the application, and returns the changes
+// the push makes. Settings not given by the push keep their current value, like for an
+// update. The environment, bound configurations and routes given by the push replace the
+// current ones.
+func PushChanges(app models.App, params PushParams) PushDiff {
+	current := app.Configuration
+	pushed := params.Configuration
+
+	diff := PushDiff{
+		Name:      app.Meta.Name,
+		Namespace: app.Meta.Namespace,
+		Changes:   []PushChange{},
+	}
+
+	if pushed.AppChart != "" && pushed.AppChart != current.AppChart {
+		diff.change("appchart", current.AppChart, pushed.AppChart)
+	}
+	if pushed.Instances != nil {
+		instances := ""
+		if current.Instances != nil {
+			instances = strconv.Itoa(int(*current.Instances))
+		}
+		if pushed := strconv.Itoa(int(*pushed.Instances)); pushed != instances {
+			diff.change("instances", instances, pushed)
+		}
+	}
+	if len(pushed.Environment) > 0 {
+		diff.compareMaps("environment", current.Environment, pushed.Environment, true)
+	}
+	if pushed.Configurations != nil {
+		diff.compareSets("configurations", current.Configurations, pushed.Configurations)
+	}
+	if len(pushed.Routes) > 0 {
+		diff.compareSets("routes", current.Routes, pushed.Routes)
+	}
+	if len(pushed.Processes) > 0 {
+		diff.compareMaps("processes", processStrings(current.Processes), processStrings(pushed.Processes), false)
+	}
+	if len(pushed.ChartValues) > 0 {
+		diff.compareMaps("chartvalues", current.ChartValues, pushed.ChartValues, false)
+	}
+
+	origin := params.Origin.String()
+	currentOrigin := ""
+	if app.Origin.Kind != models.OriginNone {
+		currentOrigin = app.Origin.String()
+	}
+	originChanged := params.Origin.Kind != app.Origin.Kind || origin != currentOrigin
+	if originChanged {
+		diff.change("origin", currentOrigin, origin)
+	}
+
+	if params.Origin.Kind != models.OriginContainer {
+		staging := diff.compareStaging(app.Staging, params.Staging)
+
+		diff.Rebuild = true
+		switch {
+		case app.StageID == "":
+			diff.RebuildReason = "the application was never staged"
+		case originChanged:
+			diff.RebuildReason = "the origin of the sources changed"
+		case staging:
+			diff.RebuildReason = "the staging settings changed"
+		case params.Origin.Kind == models.OriginPath:
+			diff.RebuildReason = "local sources are staged on every push"
+		default:
+			diff.RebuildReason = "sources are staged on every push"
+		}
+	}
+
+	return diff
+}
+
+// compareStaging adds the changes of the staging settings, and returns whether there are any.
+func (d *PushDiff) compareStaging(current, pushed models.ApplicationStage) bool {
+	count := len(d.Changes)
+
+	if pushed.Builder != "" && pushed.Builder != current.Builder {
+		d.change("staging.builder", current.Builder, pushed.Builder)
+	}
+	if currentPacks, pushedPacks := strings.Join(current.Buildpacks, ", "), strings.Join(pushed.Buildpacks, ", "); currentPacks != pushedPacks {
+		d.change("staging.buildpacks", currentPacks, pushedPacks)
+	}
+	if pushed.Dockerfile != current.Dockerfile {
+		d.change("staging.dockerfile", current.Dockerfile, pushed.Dockerfile)
+	}
+	if pushed.Cache != "" && pushed.Cache != current.Cache {
+		d.change("staging.cache", current.Cache, pushed.Cache)
+	}
+	d.compareMaps("staging.environment", current.Environment, pushed.Environment, true)
+
+	return len(d.Changes) > count
+}
+
+// change adds a change of the setting from the current to the pushed value. Empty values
+// are additions and removals.
+func (d *PushDiff) change(setting, current, pushed string) {
+	change := PushChanged
+	switch {
+	case current == "":
+		change = PushAdded
+	case pushed == "":
+		change = PushRemoved
+	}
+	d.Changes = append(d.Changes, PushChange{
+		Setting: setting,
+		Change:  change,
+		Current: current,
+		Pushed:  pushed,
+	})
+}
+
+// compareMaps adds the changes of the keys of the setting, in order. When the pushed map
+// replaces the current one, the keys it does not have are removed.
+func (d *PushDiff) compareMaps(setting string, current, pushed map[string]string, replace bool) {
+	keys := map[string]struct{}{}
+	for key := range pushed {
+		keys[key] = struct{}{}
+	}
+	if replace {
+		for key := range current {
+			keys[key] = struct{}{}
+		}
+	}
+
+	sorted := []string{}
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		if current[key] != pushed[key] {
+			d.change(fmt.Sprintf("%s.%s", setting, key), current[key], pushed[key])
+		}
+	}
+}
+
+// compareSets adds the elements of the setting which are added or removed by the push, in
+// order.
+func (d *PushDiff) compareSets(setting string, current, pushed []string) {
+	has := func(list []string, element string) bool {
+		for _, e := range list {
+			if e == element {
+				return true
+			}
+		}
+		return false
+	}
+
+	changes := []PushChange{}
+	for _, element := range current {
+		if !has(pushed, element) {
+			changes = append(changes, PushChange{Setting: setting, Change: PushRemoved, Current: element})
+		}
+	}
+	for _, element := range pushed {
+		if !has(current, element) {
+			changes = append(changes, PushChange{Setting: setting, Change: PushAdded, Pushed: element})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Current+changes[i].Pushed < changes[j].Current+changes[j].Pushed
+	})
+
+	d.Changes = append(d.Changes, changes...)
+}
+
+// processStrings returns the instances of the process types as strings, for comparison.
+func processStrings(processes map[string]int32) map[string]string {
+	result := map[string]string{}
+	for name, instances := range processes {
+		result[name] = strconv.Itoa(int(instances))
+	}
+	return result
+}
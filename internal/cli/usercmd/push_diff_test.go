@@ -0,0 +1,78 @@
+package usercmd_test
+
+import (
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PushChanges", func() {
+	var app models.App
+	var params usercmd.PushParams
+
+	BeforeEach(func() {
+		instances := int32(1)
+		app = *models.NewApp("appname", "workspace")
+		app.StageID = "stage-1"
+		app.Origin = models.ApplicationOrigin{Kind: models.OriginPath, Path: "/src"}
+		app.Configuration = models.ApplicationUpdateRequest{
+			Instances:      &instances,
+			Configurations: []string{"db"},
+			Environment:    models.EnvVariableMap{"MODE": "staging", "CREDO": "up"},
+			Routes:         []string{"appname.example.com"},
+		}
+
+		params = usercmd.PushParams{}
+		params.Name = "appname"
+		params.Origin = models.ApplicationOrigin{Kind: models.OriginPath, Path: "/src"}
+	})
+
+	It("has no changes for settings not given", func() {
+		diff := usercmd.PushChanges(app, params)
+		Expect(diff.Changes).To(BeEmpty())
+		Expect(diff.Rebuild).To(BeTrue())
+		Expect(diff.RebuildReason).To(Equal("local sources are staged on every push"))
+	})
+
+	It("lists the changed environment, bindings and routes", func() {
+		instances := int32(3)
+		params.Configuration = models.ApplicationUpdateRequest{
+			Instances:      &instances,
+			Configurations: []string{"cache"},
+			Environment:    models.EnvVariableMap{"MODE": "production", "DEBUG": "1"},
+			Routes:         []string{"appname.example.com", "www.example.com"},
+		}
+
+		diff := usercmd.PushChanges(app, params)
+		Expect(diff.Changes).To(Equal([]usercmd.PushChange{
+			{Setting: "instances", Change: usercmd.PushChanged, Current: "1", Pushed: "3"},
+			{Setting: "environment.CREDO", Change: usercmd.PushRemoved, Current: "up"},
+			{Setting: "environment.DEBUG", Change: usercmd.PushAdded, Pushed: "1"},
+			{Setting: "environment.MODE", Change: usercmd.PushChanged, Current: "staging", Pushed: "production"},
+			{Setting: "configurations", Change: usercmd.PushAdded, Pushed: "cache"},
+			{Setting: "configurations", Change: usercmd.PushRemoved, Current: "db"},
+			{Setting: "routes", Change: usercmd.PushAdded, Pushed: "www.example.com"},
+		}))
+	})
+
+	It("rebuilds for changed staging settings", func() {
+		params.Staging.Dockerfile = "Dockerfile"
+
+		diff := usercmd.PushChanges(app, params)
+		Expect(diff.Changes).To(Equal([]usercmd.PushChange{
+			{Setting: "staging.dockerfile", Change: usercmd.PushAdded, Pushed: "Dockerfile"},
+		}))
+		Expect(diff.RebuildReason).To(Equal("the staging settings changed"))
+	})
+
+	It("does not rebuild container images", func() {
+		params.Origin = models.ApplicationOrigin{Kind: models.OriginContainer, Container: "splatform/sample-app"}
+
+		diff := usercmd.PushChanges(app, params)
+		Expect(diff.Changes).To(Equal([]usercmd.PushChange{
+			{Setting: "origin", Change: usercmd.PushChanged, Current: "/src", Pushed: "splatform/sample-app"},
+		}))
+		Expect(diff.Rebuild).To(BeFalse())
+	})
+})
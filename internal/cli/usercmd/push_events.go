@@ -0,0 +1,63 @@
+package usercmd
+
+import (
+	"time"
+)
+
+// Kinds of push progress events, for `PushEvent.Event`.
+const (
+	PushEventCreate = "create"
+	PushEventUpdate = "update"
+	PushEventUpload = "upload"
+	PushEventImport = "import"
+	PushEventStage  = "stage"
+	PushEventDeploy = "deploy"
+	PushEventRoute  = "route"
+	PushEventDone   = "done"
+	PushEventError  = "error"
+)
+
+// Phases of the push steps, for `PushEvent.Phase`.
+const (
+	PushPhaseStarted  = "started"
+	PushPhaseProgress = "progress"
+	PushPhaseQueued   = "queued"
+	PushPhaseRunning  = "running"
+	PushPhaseFinished = "finished"
+)
+
+// PushEvent reports the progress of a push, for machine-readable output. Each step of the
+// push, i.e. creating or updating the application, uploading or importing the sources,
+// staging, and deploying, reports when it started and finished, with more events in
+// between for the upload and staging. The routes of the deployed application are reported
+// one per event, before the final event. A failed push ends with an error event instead.
+type PushEvent struct {
+	Event     string    `json:"event"`
+	Phase     string    `json:"phase,omitempty"`
+	Time      time.Time `json:"time"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Percent   int64     `json:"percent,omitempty"`
+	Sent      int64     `json:"sent,omitempty"`
+	Total     int64     `json:"total,omitempty"`
+	Position  int       `json:"position,omitempty"`
+	StageID   string    `json:"stageId,omitempty"`
+	Route     string    `json:"route,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// pushEvent prints the progress event of the push of the application, if machine-readable
+// output is asked for. Events failing to print are logged, not pushes failing.
+func (c *EpinioClient) pushEvent(name string, event PushEvent) {
+	if !c.ui.Machine() {
+		return
+	}
+
+	event.Time = time.Now()
+	event.Name = name
+	event.Namespace = c.Settings.Namespace
+
+	if err := c.ui.Event(event); err != nil {
+		c.Log.Info("push event failed", "error", err.Error())
+	}
+}
@@ -20,10 +20,15 @@ func (c *EpinioClient) ServiceCatalog() error {
 
 	c.ui.Note().Msg("Getting catalog...")
 
-	catalog, err := c.API.ServiceCatalog()
+	catalog, received, err := c.catalog()
 	if err != nil {
 		return errors.Wrap(err, "service catalog failed")
 	}
+	c.staleWarning(received)
+
+	if c.ui.Machine() {
+		return c.ui.Data(catalog.CatalogServices)
+	}
 
 	msg := c.ui.Success().WithTable("Name", "Created", "Version", "Description")
 
@@ -51,13 +56,18 @@ func (c *EpinioClient) ServiceCatalogShow(serviceName string) error {
 		WithStringValue("Service", serviceName).
 		Msg("Show service details")
 
-	catalogShowResponse, err := c.API.ServiceCatalogShow(serviceName)
+	catalogShowResponse, received, err := c.catalogService(serviceName)
 	if err != nil {
 		return err
 	}
+	c.staleWarning(received)
 
 	service := catalogShowResponse.CatalogService
 
+	if c.ui.Machine() {
+		return c.ui.Data(service)
+	}
+
 	c.ui.Success().WithTable("Key", "Value").
 		WithTableRow("Name", service.Meta.Name).
 		WithTableRow("Created", fmt.Sprintf("%v", service.Meta.CreatedAt)).
@@ -69,8 +79,8 @@ func (c *EpinioClient) ServiceCatalogShow(serviceName string) error {
 	return nil
 }
 
-// ServiceCreate creates a service
-func (c *EpinioClient) ServiceCreate(catalogServiceName, serviceName string) error {
+// ServiceCreate creates a service. With wait it waits for the service to be provisioned.
+func (c *EpinioClient) ServiceCreate(catalogServiceName, serviceName string, wait bool) error {
 	log := c.Log.WithName("ServiceCreate")
 	log.Info("start")
 	defer log.Info("return")
@@ -85,9 +95,25 @@ func (c *EpinioClient) ServiceCreate(catalogServiceName, serviceName string) err
 		Name:           serviceName,
 	}
 
-	err := c.API.ServiceCreate(request, c.Settings.Namespace)
-	// Note: errors.Wrap (nil, "...") == nil
-	return errors.Wrap(err, "service create failed")
+	resp, err := c.API.ServiceCreate(request, c.Settings.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "service create failed")
+	}
+
+	if wait && resp.OperationID != "" {
+		c.ui.ProgressNote().Msg("Waiting for the service to be provisioned")
+		if _, err := c.waitForOperation(resp.OperationID); err != nil {
+			return errors.Wrap(err, "service provisioning failed")
+		}
+	}
+
+	msg := c.ui.Success()
+	if !wait && resp.OperationID != "" {
+		msg = msg.WithStringValue("Operation", resp.OperationID)
+	}
+	msg.Msg("Service created.")
+
+	return nil
 }
 
 // ServiceShow describes a service instance
@@ -111,6 +137,10 @@ func (c *EpinioClient) ServiceShow(serviceName string) error {
 		return errors.New("Service not found")
 	}
 
+	if c.ui.Machine() {
+		return c.ui.Data(resp.Service)
+	}
+
 	c.ui.Success().WithTable("Key", "Value").
 		WithTableRow("Name", resp.Service.Meta.Name).
 		WithTableRow("Created", fmt.Sprintf("%v", resp.Service.Meta.CreatedAt)).
@@ -189,6 +219,37 @@ func (c *EpinioClient) ServiceDelete(name string, unbind bool) error {
 	return nil
 }
 
+// ServicesDelete deletes the named services, or all services of the targeted namespace, in a
+// single call to the server. It reports the outcome per service, and fails if any of the
+// deletions failed.
+func (c *EpinioClient) ServicesDelete(names []string, unbind, all bool) error {
+	log := c.Log.WithName("ServicesDelete")
+	log.Info("start")
+	defer log.Info("return")
+
+	msg := c.ui.Note().WithStringValue("Namespace", c.Settings.Namespace)
+	if all {
+		msg.Msg("Deleting All Services...")
+	} else {
+		msg.WithStringValue("Names", strings.Join(names, ", ")).Msg("Deleting Services...")
+	}
+
+	if err := c.TargetOk(); err != nil {
+		return err
+	}
+
+	response, err := c.API.ServiceBatchDelete(c.Settings.Namespace, models.BatchDeleteRequest{
+		Names:  names,
+		All:    all,
+		Unbind: unbind,
+	})
+	if err != nil {
+		return errors.Wrap(err, "service deletion failed")
+	}
+
+	return c.batchDeleteResults("Service", response.Results)
+}
+
 // ServiceBind binds a service to an application
 func (c *EpinioClient) ServiceBind(name, appName string) error {
 	log := c.Log.WithName("ServiceBind")
@@ -235,6 +296,10 @@ func (c *EpinioClient) ServiceList() error {
 		return errors.Wrap(err, "service list failed")
 	}
 
+	if c.ui.Machine() {
+		return c.ui.Data(resp.Services)
+	}
+
 	if len(resp.Services) == 0 {
 		c.ui.Normal().Msg("No services found")
 		return nil
@@ -251,3 +316,35 @@ func (c *EpinioClient) ServiceList() error {
 
 	return nil
 }
+
+// ServiceMatching returns all Epinio services having the specified prefix in their name.
+func (c *EpinioClient) ServiceMatching(prefix string) []string {
+	return c.matching("services", c.Settings.Namespace, prefix, func() ([]string, error) {
+		resp, err := c.API.ServiceList(c.Settings.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{}
+		for _, service := range resp.Services {
+			names = append(names, service.Meta.Name)
+		}
+		return names, nil
+	})
+}
+
+// CatalogMatching returns all catalog services having the specified prefix in their name.
+func (c *EpinioClient) CatalogMatching(prefix string) []string {
+	return c.matching("catalog", "", prefix, func() ([]string, error) {
+		resp, _, err := c.catalog()
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{}
+		for _, service := range resp.CatalogServices {
+			names = append(names, service.Meta.Name)
+		}
+		return names, nil
+	})
+}
@@ -0,0 +1,127 @@
+package usercmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// TeamSet creates the team, or replaces its members and groups
+func (c *EpinioClient) TeamSet(name string, members, groups []string) error {
+	log := c.Log.WithName("TeamSet").WithValues("Team", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Team", name).
+		WithStringValue("Members", strings.Join(members, ", ")).
+		WithStringValue("Groups", strings.Join(groups, ", ")).
+		Msg("Setting team...")
+
+	_, err := c.API.TeamSet(name, models.TeamRequest{
+		Members: members,
+		Groups:  groups,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Team set.")
+
+	return nil
+}
+
+// TeamList lists the teams
+func (c *EpinioClient) TeamList() error {
+	log := c.Log.WithName("TeamList")
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().Msg("Listing teams")
+
+	teams, err := c.API.Teams()
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(teams)
+	}
+
+	if len(teams) == 0 {
+		c.ui.Exclamation().Msg("No teams found")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("Name", "Members", "Groups", "Namespaces")
+
+	for _, team := range teams {
+		namespaces := []string{}
+		for _, namespace := range team.Namespaces {
+			role, ok := team.NamespaceRoles[namespace]
+			if !ok {
+				role = "admin"
+			}
+			namespaces = append(namespaces, fmt.Sprintf("%s (%s)", namespace, role))
+		}
+		sort.Strings(namespaces)
+
+		msg = msg.WithTableRow(
+			team.Name,
+			strings.Join(team.Members, ", "),
+			strings.Join(team.Groups, ", "),
+			strings.Join(namespaces, ", "),
+		)
+	}
+
+	msg.Msg("Epinio teams:")
+
+	return nil
+}
+
+// TeamDelete deletes the team
+func (c *EpinioClient) TeamDelete(name string) error {
+	log := c.Log.WithName("TeamDelete").WithValues("Team", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Team", name).
+		Msg("Deleting team...")
+
+	_, err := c.API.TeamDelete(name)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Team deleted.")
+
+	return nil
+}
+
+// TeamRoleSet gives the team a role in the namespace
+func (c *EpinioClient) TeamRoleSet(name, role, namespace string) error {
+	log := c.Log.WithName("TeamRoleSet").WithValues("Team", name, "Role", role, "Namespace", namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Team", name).
+		WithStringValue("Role", role).
+		WithStringValue("Namespace", namespace).
+		Msg("Setting role...")
+
+	_, err := c.API.TeamRoleSet(name, models.UserRoleRequest{
+		Namespace: namespace,
+		Role:      role,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Role set.")
+
+	return nil
+}
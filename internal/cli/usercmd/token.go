@@ -0,0 +1,107 @@
+package usercmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/fatih/color"
+)
+
+// TokenCreate issues a new API token to the user, and shows its secret value. The value is
+// shown only once.
+func (c *EpinioClient) TokenCreate(name, scope string, namespaces []string, expiry time.Duration) error {
+	log := c.Log.WithName("TokenCreate").WithValues("Name", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Name", name).
+		Msg("Creating API token...")
+
+	token, err := c.API.APITokenCreate(models.APITokenCreateRequest{
+		Name:       name,
+		Scope:      scope,
+		Namespaces: namespaces,
+		Expiry:     expiry.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(token)
+	}
+
+	c.ui.Success().
+		WithStringValue("ID", token.ID).
+		WithStringValue("Expires", token.ExpiresAt.Local().Format(time.RFC1123)).
+		WithStringValue("Token", token.Token).
+		Msg("API token created. Store the token now, it is not shown again.")
+
+	return nil
+}
+
+// TokenList lists the API tokens of the user
+func (c *EpinioClient) TokenList() error {
+	log := c.Log.WithName("TokenList")
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().Msg("Listing API tokens")
+
+	tokens, err := c.API.APITokens()
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(tokens)
+	}
+
+	if len(tokens) == 0 {
+		c.ui.Exclamation().Msg("No API tokens found")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("ID", "Name", "Scope", "Namespaces", "Created", "Expires")
+
+	for _, token := range tokens {
+		expires := token.ExpiresAt.Local().Format(time.RFC1123)
+		if time.Now().After(token.ExpiresAt) {
+			expires = color.RedString("expired")
+		}
+		msg = msg.WithTableRow(
+			token.ID,
+			token.Name,
+			token.Scope,
+			strings.Join(token.Namespaces, ", "),
+			token.CreatedAt.Local().Format(time.RFC1123),
+			expires,
+		)
+	}
+
+	msg.Msg("Epinio API tokens:")
+
+	return nil
+}
+
+// TokenRevoke revokes the API token with the id
+func (c *EpinioClient) TokenRevoke(id string) error {
+	log := c.Log.WithName("TokenRevoke").WithValues("ID", id)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("ID", id).
+		Msg("Revoking API token...")
+
+	_, err := c.API.APITokenDelete(id)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("API token revoked.")
+
+	return nil
+}
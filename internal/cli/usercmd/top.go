@@ -0,0 +1,173 @@
+package usercmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/epinio/epinio/helpers/bytes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+// Sort orders of `epinio top`.
+const (
+	TopSortName     = "name"
+	TopSortCPU      = "cpu"
+	TopSortMemory   = "memory"
+	TopSortRestarts = "restarts"
+)
+
+// AppUsage is the resource usage of an application, as shown by `epinio top`.
+type AppUsage struct {
+	Namespace        string `json:"namespace"`
+	Name             string `json:"name"`
+	Instances        int32  `json:"instances"`
+	DesiredInstances int32  `json:"desiredinstances"`
+	MemoryBytes      int64  `json:"memoryBytes"`
+	MilliCPUs        int64  `json:"millicpus"`
+	Restarts         int32  `json:"restarts"`
+	Metrics          bool   `json:"metrics"`
+}
+
+// Top shows the instances, the resource usage, and the restarts of the applications in the
+// namespace, or in all namespaces, sorted as asked for. Applications without workload are
+// included, with no instances.
+func (c *EpinioClient) Top(namespace string, all bool, sortBy string) error {
+	if namespace == "" {
+		namespace = c.Settings.Namespace
+	}
+
+	log := c.Log.WithName("Top").WithValues("Namespace", namespace)
+	log.Info("start")
+	defer log.Info("return")
+
+	if err := ValidateTopSort(sortBy); err != nil {
+		return err
+	}
+
+	var apps models.AppList
+	var err error
+	if all {
+		c.ui.Note().Msg("Showing the resource usage of all applications")
+		apps, err = c.API.AllApps()
+	} else {
+		c.ui.Note().
+			WithStringValue("Namespace", namespace).
+			Msg("Showing the resource usage of applications")
+		apps, err = c.API.Apps(namespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	usages := appUsages(apps, sortBy)
+
+	if c.ui.Machine() {
+		return c.ui.Data(usages)
+	}
+
+	msg := c.ui.Success()
+	if all {
+		msg = msg.WithTable("Namespace", "Name", "Instances", "Memory", "MilliCPUs", "Restarts")
+	} else {
+		msg = msg.WithTable("Name", "Instances", "Memory", "MilliCPUs", "Restarts")
+	}
+
+	var total AppUsage
+	total.Metrics = true
+	for _, usage := range usages {
+		row := []string{
+			usage.Name,
+			fmt.Sprintf("%d/%d", usage.Instances, usage.DesiredInstances),
+			"n/a",
+			"n/a",
+			strconv.Itoa(int(usage.Restarts)),
+		}
+		if usage.Metrics {
+			row[2] = bytes.ByteCountIEC(usage.MemoryBytes)
+			row[3] = strconv.Itoa(int(usage.MilliCPUs))
+		}
+		if all {
+			row = append([]string{usage.Namespace}, row...)
+		}
+		msg = msg.WithTableRow(row...)
+
+		total.Instances += usage.Instances
+		total.DesiredInstances += usage.DesiredInstances
+		total.MemoryBytes += usage.MemoryBytes
+		total.MilliCPUs += usage.MilliCPUs
+		total.Restarts += usage.Restarts
+		total.Metrics = total.Metrics && usage.Metrics
+	}
+
+	if len(usages) > 0 {
+		row := []string{
+			"Total",
+			fmt.Sprintf("%d/%d", total.Instances, total.DesiredInstances),
+			"n/a",
+			"n/a",
+			strconv.Itoa(int(total.Restarts)),
+		}
+		if total.Metrics {
+			row[2] = bytes.ByteCountIEC(total.MemoryBytes)
+			row[3] = strconv.Itoa(int(total.MilliCPUs))
+		}
+		if all {
+			row = append([]string{""}, row...)
+		}
+		msg = msg.WithTableRow(row...)
+	}
+
+	msg.Msg("Resource usage")
+
+	return nil
+}
+
+// ValidateTopSort checks that the sort order of `epinio top` is known.
+func ValidateTopSort(sortBy string) error {
+	switch sortBy {
+	case "", TopSortName, TopSortCPU, TopSortMemory, TopSortRestarts:
+		return nil
+	}
+	return errors.Errorf("bad sort order '%s', expected one of name, cpu, memory, or restarts", sortBy)
+}
+
+// appUsages returns the resource usage of the applications, sorted as asked for. Usage sorts
+// are descending, to show the heaviest users first.
+func appUsages(apps models.AppList, sortBy string) []AppUsage {
+	// Name order first, to break the ties of the usage orders.
+	sort.Sort(apps)
+
+	usages := []AppUsage{}
+	for _, app := range apps {
+		usage := AppUsage{
+			Namespace: app.Meta.Namespace,
+			Name:      app.Meta.Name,
+		}
+		if app.Workload != nil {
+			usage.Instances = app.Workload.ReadyReplicas
+			usage.DesiredInstances = app.Workload.DesiredReplicas
+			usage.MemoryBytes = app.Workload.MemoryBytes
+			usage.MilliCPUs = app.Workload.MilliCPUs
+			usage.Restarts = app.Workload.Restarts
+			usage.Metrics = app.Workload.MetricsSource != ""
+		}
+		usages = append(usages, usage)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		switch sortBy {
+		case TopSortCPU:
+			return a.MilliCPUs > b.MilliCPUs
+		case TopSortMemory:
+			return a.MemoryBytes > b.MemoryBytes
+		case TopSortRestarts:
+			return a.Restarts > b.Restarts
+		}
+		return false
+	})
+
+	return usages
+}
@@ -0,0 +1,53 @@
+package usercmd_test
+
+import (
+	"github.com/epinio/epinio/internal/cli/settings"
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Top", func() {
+	var mockClient *mockAPIClient
+	var listed []string
+	var epinioClient *usercmd.EpinioClient
+
+	BeforeEach(func() {
+		listed = []string{}
+		mockClient = &mockAPIClient{}
+		mockClient.mockApps = func(namespace string) (models.AppList, error) {
+			listed = append(listed, namespace)
+
+			busy := models.NewApp("busy", namespace)
+			busy.Workload = &models.AppDeployment{
+				DesiredReplicas: 2,
+				ReadyReplicas:   2,
+				MemoryBytes:     512 * 1024 * 1024,
+				MilliCPUs:       900,
+				MetricsSource:   "metrics-server",
+			}
+			return models.AppList{*busy, *models.NewApp("idle", namespace)}, nil
+		}
+
+		var err error
+		epinioClient, err = usercmd.NewEpinioClient(&settings.Settings{Namespace: "workspace"}, mockClient)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("shows the targeted namespace by default", func() {
+		Expect(epinioClient.Top("", false, usercmd.TopSortCPU)).To(Succeed())
+		Expect(listed).To(Equal([]string{"workspace"}))
+	})
+
+	It("shows the named namespace", func() {
+		Expect(epinioClient.Top("prod", false, usercmd.TopSortName)).To(Succeed())
+		Expect(listed).To(Equal([]string{"prod"}))
+	})
+
+	It("rejects unknown sort orders", func() {
+		err := epinioClient.Top("", false, "size")
+		Expect(err).To(MatchError("bad sort order 'size', expected one of name, cpu, memory, or restarts"))
+		Expect(listed).To(BeEmpty())
+	})
+})
@@ -0,0 +1,114 @@
+package usercmd
+
+import (
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+// UserRoleSet gives the user a role in the namespace. A non-empty expiry makes the role
+// temporary.
+func (c *EpinioClient) UserRoleSet(username, role, namespace, expires string) error {
+	log := c.Log.WithName("UserRoleSet").WithValues("User", username, "Role", role, "Namespace", namespace, "Expires", expires)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("User", username).
+		WithStringValue("Role", role).
+		WithStringValue("Namespace", namespace).
+		WithStringValue("Expires", expires).
+		Msg("Setting role...")
+
+	_, err := c.API.UserRoleSet(username, models.UserRoleRequest{
+		Namespace: namespace,
+		Role:      role,
+		Expires:   expires,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Role set.")
+
+	return nil
+}
+
+// UserFederationSet marks the user as the same person as the user of the same name at the LDAP
+// server, or the OIDC issuer, or removes the mark.
+func (c *EpinioClient) UserFederationSet(username string, federated bool) error {
+	log := c.Log.WithName("UserFederationSet").WithValues("User", username, "Federated", federated)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("User", username).
+		WithBoolValue("Federated", federated).
+		Msg("Setting federation...")
+
+	_, err := c.API.UserFederationSet(username, models.UserFederationRequest{
+		Federated: federated,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Federation set.")
+
+	return nil
+}
+
+// ChangePassword replaces the password of the user of the settings. A password stored in the
+// settings is updated.
+func (c *EpinioClient) ChangePassword(currentPassword, newPassword string) error {
+	log := c.Log.WithName("ChangePassword").WithValues("User", c.Settings.User)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("User", c.Settings.User).
+		Msg("Changing password...")
+
+	_, err := c.API.PasswordChange(models.PasswordChangeRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.Settings.Password != "" {
+		c.Settings.Password = newPassword
+		if err := c.Settings.Save(); err != nil {
+			return errors.Wrap(err, "failed to save the new password in the settings")
+		}
+	}
+
+	c.ui.Success().Msg("Password changed.")
+
+	return nil
+}
+
+// ResetPassword gives the user a new password, the requested one, or a random one, and shows
+// it. The sessions of the user end.
+func (c *EpinioClient) ResetPassword(username, password string) error {
+	log := c.Log.WithName("ResetPassword").WithValues("User", username)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("User", username).
+		Msg("Resetting password...")
+
+	resp, err := c.API.UserPasswordReset(username, models.PasswordResetRequest{
+		Password: password,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().
+		WithStringValue("Password", resp.Password).
+		Msg("Password reset. Hand the new password to the user.")
+
+	return nil
+}
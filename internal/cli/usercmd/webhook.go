@@ -0,0 +1,94 @@
+package usercmd
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// WebhookSet creates the webhook, or replaces its url, event types, and secret
+func (c *EpinioClient) WebhookSet(name, url, secret string, events []string) error {
+	log := c.Log.WithName("WebhookSet").WithValues("Webhook", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Webhook", name).
+		WithStringValue("URL", url).
+		WithStringValue("Events", strings.Join(events, ", ")).
+		Msg("Setting webhook...")
+
+	resp, err := c.API.WebhookSet(name, models.WebhookRequest{
+		URL:    url,
+		Secret: secret,
+		Events: events,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := c.ui.Success()
+	if secret == "" {
+		msg = msg.WithStringValue("Secret", resp.Secret)
+	}
+	msg.Msg("Webhook set. The events are signed with its secret.")
+
+	return nil
+}
+
+// WebhookList lists the webhooks
+func (c *EpinioClient) WebhookList() error {
+	log := c.Log.WithName("WebhookList")
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().Msg("Listing webhooks")
+
+	hooks, err := c.API.Webhooks()
+	if err != nil {
+		return err
+	}
+
+	if c.ui.Machine() {
+		return c.ui.Data(hooks)
+	}
+
+	if len(hooks) == 0 {
+		c.ui.Exclamation().Msg("No webhooks found")
+		return nil
+	}
+
+	msg := c.ui.Success().WithTable("Name", "URL", "Events")
+
+	for _, hook := range hooks {
+		events := strings.Join(hook.Events, ", ")
+		if events == "" {
+			events = "all"
+		}
+		msg = msg.WithTableRow(hook.Name, hook.URL, events)
+	}
+
+	msg.Msg("Epinio webhooks:")
+
+	return nil
+}
+
+// WebhookDelete deletes the webhook
+func (c *EpinioClient) WebhookDelete(name string) error {
+	log := c.Log.WithName("WebhookDelete").WithValues("Webhook", name)
+	log.Info("start")
+	defer log.Info("return")
+
+	c.ui.Note().
+		WithStringValue("Webhook", name).
+		Msg("Deleting webhook...")
+
+	_, err := c.API.WebhookDelete(name)
+	if err != nil {
+		return err
+	}
+
+	c.ui.Success().Msg("Webhook deleted.")
+
+	return nil
+}
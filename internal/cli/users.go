@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdUser implements the command: epinio user
+var CmdUser = &cobra.Command{
+	Use:           "user",
+	Aliases:       []string{"users"},
+	Short:         "Epinio users",
+	Long:          `Manage the Epinio users`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+// CmdUserRole implements the command: epinio user role
+var CmdUserRole = &cobra.Command{
+	Use:           "role",
+	Short:         "Roles of the users in namespaces",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdUser.AddCommand(CmdUserRole)
+	CmdUserRole.AddCommand(CmdUserRoleSet)
+	CmdUser.AddCommand(CmdUserPassword)
+	CmdUser.AddCommand(CmdUserPasswordReset)
+	CmdUser.AddCommand(CmdUserFederate)
+
+	CmdUserFederate.Flags().Bool("remove", false, "Remove the mark instead")
+
+	CmdUserPasswordReset.Flags().Bool("ask-password", false, "Ask for the new password instead of generating a random one")
+
+	CmdUserRoleSet.Flags().String("namespace", "", "Namespace to give the user the role in")
+	_ = CmdUserRoleSet.MarkFlagRequired("namespace")
+	_ = CmdUserRoleSet.RegisterFlagCompletionFunc("namespace", matchingNamespaceFinder)
+	CmdUserRoleSet.Flags().String("expires", "", "Revoke the role after this duration, e.g. 72h. Default is never")
+}
+
+// CmdUserRoleSet implements the command: epinio user role set
+var CmdUserRoleSet = &cobra.Command{
+	Use:   "set USER ROLE --namespace NAMESPACE [--expires DURATION]",
+	Short: "Gives the user a role in the namespace",
+	Long: `Gives the user a role in the namespace. Only admins can do this.
+
+Readers can only look at the namespace, without getting into its applications. Developers can do everything in the namespace, except changing, or deleting, the namespace itself. Admins of the namespace can do everything in it. The role "none" removes the user from the namespace.
+
+With --expires the role is temporary, e.g. for contractors, or access during an incident. The user is removed from the namespace when the duration is over.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --namespace")
+		}
+		expires, err := cmd.Flags().GetString("expires")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --expires")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.UserRoleSet(args[0], args[1], namespace, expires)
+		if err != nil {
+			return errors.Wrap(err, "error setting role")
+		}
+
+		return nil
+	},
+}
+
+// CmdUserPassword implements the command: epinio user password
+var CmdUserPassword = &cobra.Command{
+	Use:   "password",
+	Short: "Changes your password",
+	Long: `Changes the password of the user of the settings. The current password, and the new one, twice, are asked for. Without a terminal they are read from the first three lines of the standard input.
+
+Only Epinio users have a password to change. Other users, e.g. of LDAP, or single sign-on, change it there.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		passwords, err := askPasswords(cmd, "Current password: ", "New password: ", "Repeat new password: ")
+		if err != nil {
+			return errors.Wrap(err, "error reading password")
+		}
+		if passwords[1] != passwords[2] {
+			return errors.New("the new passwords differ")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.ChangePassword(passwords[0], passwords[1])
+		if err != nil {
+			return errors.Wrap(err, "error changing password")
+		}
+
+		return nil
+	},
+}
+
+// CmdUserPasswordReset implements the command: epinio user password-reset
+var CmdUserPasswordReset = &cobra.Command{
+	Use:   "password-reset USER [--ask-password]",
+	Short: "Resets the password of the user",
+	Long:  `Gives the user a new random password, and shows it. With --ask-password the new password is asked for instead. The sessions of the user end. Only admins can do this.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		ask, err := cmd.Flags().GetBool("ask-password")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --ask-password")
+		}
+
+		password := ""
+		if ask {
+			passwords, err := askPasswords(cmd, "New password: ", "Repeat new password: ")
+			if err != nil {
+				return errors.Wrap(err, "error reading password")
+			}
+			if passwords[0] != passwords[1] {
+				return errors.New("the new passwords differ")
+			}
+			password = passwords[0]
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.ResetPassword(args[0], password)
+		if err != nil {
+			return errors.Wrap(err, "error resetting password")
+		}
+
+		return nil
+	},
+}
+
+// CmdUserFederate implements the command: epinio user federate
+var CmdUserFederate = &cobra.Command{
+	Use:   "federate USER [--remove]",
+	Short: "Marks the user as federated",
+	Long: `Marks the Epinio user as the same person as the user of the same name at the LDAP server, or the OIDC issuer. They log in there with the roles of the Epinio user. Only admins can do this.
+
+Without the mark logins of the same name there are refused, for them not to take over the Epinio user. With --remove the mark is removed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		remove, err := cmd.Flags().GetBool("remove")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --remove")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.UserFederationSet(args[0], !remove)
+		if err != nil {
+			return errors.Wrap(err, "error setting federation")
+		}
+
+		return nil
+	},
+}
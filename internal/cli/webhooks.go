@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/epinio/epinio/internal/cli/usercmd"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CmdWebhook implements the command: epinio webhook
+var CmdWebhook = &cobra.Command{
+	Use:     "webhook",
+	Aliases: []string{"webhooks"},
+	Short:   "Epinio webhooks",
+	Long: `Manage the webhooks receiving the platform events, e.g. deployments, and staging failures.
+
+The events are posted as JSON, signed with the secret of the webhook. The header X-Epinio-Signature carries the hex encoded HMAC-SHA256 of the body, prefixed with "sha256=".`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Args:          cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Usage(); err != nil {
+			return err
+		}
+		return fmt.Errorf(`Unknown method "%s"`, args[0])
+	},
+}
+
+func init() {
+	CmdWebhook.AddCommand(CmdWebhookSet)
+	CmdWebhook.AddCommand(CmdWebhookList)
+	CmdWebhook.AddCommand(CmdWebhookDelete)
+
+	CmdWebhookSet.Flags().String("secret", "", "Secret signing the events. Leave empty to keep the existing one, or to generate one")
+	CmdWebhookSet.Flags().StringSlice("event", []string{}, "Type of the events to post, e.g. app.deployed, or app for all the events of apps. Can be set multiple times. Leave empty for all events")
+}
+
+// CmdWebhookSet implements the command: epinio webhook set
+var CmdWebhookSet = &cobra.Command{
+	Use:   "set NAME URL [--secret SECRET] [--event TYPE]...",
+	Short: "Creates the webhook, or replaces its settings",
+	Long:  "Creates the webhook, or replaces its url, event types, and secret. Only admins can do this.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		secret, err := cmd.Flags().GetString("secret")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --secret")
+		}
+		events, err := cmd.Flags().GetStringSlice("event")
+		if err != nil {
+			return errors.Wrap(err, "error reading option --event")
+		}
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.WebhookSet(args[0], args[1], secret, events)
+		if err != nil {
+			return errors.Wrap(err, "error setting webhook")
+		}
+
+		return nil
+	},
+}
+
+// CmdWebhookList implements the command: epinio webhook list
+var CmdWebhookList = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the webhooks",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.WebhookList()
+		if err != nil {
+			return errors.Wrap(err, "error listing webhooks")
+		}
+
+		return nil
+	},
+}
+
+// CmdWebhookDelete implements the command: epinio webhook delete
+var CmdWebhookDelete = &cobra.Command{
+	Use:   "delete NAME",
+	Short: "Deletes the webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		client, err := usercmd.New()
+		if err != nil {
+			return errors.Wrap(err, "error initializing cli")
+		}
+
+		err = client.WebhookDelete(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error deleting webhook")
+		}
+
+		return nil
+	},
+}
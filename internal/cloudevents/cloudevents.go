@@ -0,0 +1,207 @@
+// Package cloudevents emits the platform events, see models.PlatformEvent, to a CloudEvents
+// sink, e.g. a Knative broker, or a webhook event source of Argo Events, so that event-driven
+// platforms can build on Epinio. The events are posted in the structured content mode of the
+// HTTP binding of CloudEvents 1.0, with the platform event as data.
+//
+// The type of an event is the one of the platform event, prefixed with TypePrefix, e.g.
+// `io.epinio.app.deployed`. The source is the configured one, extended by the namespace of
+// the event, if any, and the subject is the name of the app, service, or namespace.
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SpecVersion is the version of the CloudEvents specification of the events.
+	SpecVersion = "1.0"
+	// ContentType is the content type of the structured mode.
+	ContentType = "application/cloudevents+json"
+	// TypePrefix is the reverse-DNS prefix of the types of the events.
+	TypePrefix = "io.epinio."
+)
+
+// Emission of the events: a request has emitTimeout to succeed, failed ones are retried
+// emitAttempts times in all, waiting retryDelay, doubled after each attempt.
+const (
+	emitTimeout  = 10 * time.Second
+	emitAttempts = 4
+	retryDelay   = 2 * time.Second
+)
+
+// Event is a CloudEvent, in the JSON format, carrying a platform event.
+type Event struct {
+	SpecVersion     string               `json:"specversion"`
+	ID              string               `json:"id"`
+	Source          string               `json:"source"`
+	Type            string               `json:"type"`
+	Subject         string               `json:"subject,omitempty"`
+	Time            time.Time            `json:"time"`
+	DataContentType string               `json:"datacontenttype"`
+	Data            models.PlatformEvent `json:"data"`
+}
+
+// Emitter posts the events to the sink.
+type Emitter struct {
+	Client *http.Client
+	Sink   string
+	Source string
+	// RetryDelay is the wait before the first retry of a failed emission.
+	RetryDelay time.Duration
+
+	// instance distinguishes the ids of the events of this server from those of its other
+	// replicas, and restarts, as the ids of the platform events are not.
+	instance string
+}
+
+// Validate checks the url of the sink, and the source of the events.
+func Validate(sink, source string) error {
+	address, err := url.Parse(sink)
+	if err != nil || address.Host == "" || (address.Scheme != "http" && address.Scheme != "https") {
+		return errors.Errorf("cloudevents sink `%s` is not an http(s) url", sink)
+	}
+	if source == "" {
+		return errors.New("cloudevents source is empty")
+	}
+	if _, err := url.Parse(source); err != nil {
+		return errors.Errorf("cloudevents source `%s` is not an uri reference", source)
+	}
+	return nil
+}
+
+// NewEmitter returns an emitter to the sink, for events of the source, with the default
+// timeout and retry delay.
+func NewEmitter(sink, source string) (*Emitter, error) {
+	if err := Validate(sink, source); err != nil {
+		return nil, err
+	}
+
+	instance := make([]byte, 8)
+	if _, err := rand.Read(instance); err != nil {
+		return nil, errors.Wrap(err, "generating the instance id")
+	}
+
+	return &Emitter{
+		Client:     &http.Client{Timeout: emitTimeout},
+		Sink:       sink,
+		Source:     source,
+		RetryDelay: retryDelay,
+		instance:   hex.EncodeToString(instance),
+	}, nil
+}
+
+// Event returns the CloudEvent of the platform event.
+func (e *Emitter) Event(event models.PlatformEvent) Event {
+	source := e.Source
+	if event.Namespace != "" {
+		source += "/namespaces/" + event.Namespace
+	}
+
+	id := strconv.FormatUint(event.ID, 10)
+	if e.instance != "" {
+		id = e.instance + "-" + id
+	}
+
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            TypePrefix + event.Type,
+		Subject:         event.Name,
+		Time:            event.Time.UTC(),
+		DataContentType: "application/json",
+		Data:            event,
+	}
+}
+
+// Emit posts the event to the sink, retrying failed attempts, until the context is done.
+func (e *Emitter) Emit(ctx context.Context, event models.PlatformEvent) error {
+	payload, err := json.Marshal(e.Event(event))
+	if err != nil {
+		return err
+	}
+
+	delay := e.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err = e.post(ctx, payload)
+		if err == nil || attempt == emitAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes a single attempt at emitting the payload.
+func (e *Emitter) post(ctx context.Context, payload []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Sink, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", ContentType)
+
+	response, err := e.Client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "posting event")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Errorf("cloudevents sink returned '%s'", response.Status)
+	}
+	return nil
+}
+
+// EmitLoop posts the events of the default bus to the sink of the emitter, in order, until
+// the context is done.
+func EmitLoop(ctx context.Context, logger logr.Logger, emitter *Emitter) {
+	emit := func(event models.PlatformEvent) {
+		if err := emitter.Emit(ctx, event); err != nil {
+			logger.Error(err, "cloudevents: emission failed", "event", event.ID, "type", event.Type)
+		}
+	}
+
+	var lastID uint64
+	for {
+		subscription, missed := events.Default.Subscribe(lastID, nil)
+		for _, event := range missed {
+			lastID = event.ID
+			emit(event)
+		}
+
+		// The bus closes the subscription when the loop falls behind, e.g. while the
+		// sink is down. The next one catches up from the recent events.
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				events.Default.Unsubscribe(subscription)
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					open = false
+					break
+				}
+				lastID = event.ID
+				emit(event)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cloudevents_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/epinio/epinio/internal/cloudevents"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Emitter", func() {
+	event := models.PlatformEvent{
+		ID:        7,
+		Time:      time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Type:      models.EventAppDeployed,
+		Namespace: "workspace",
+		Name:      "app",
+		Details:   map[string]string{"image": "registry/app:1"},
+	}
+
+	newEmitter := func(sink string) *cloudevents.Emitter {
+		emitter, err := cloudevents.NewEmitter(sink, "/epinio")
+		Expect(err).ToNot(HaveOccurred())
+		emitter.RetryDelay = time.Millisecond
+		return emitter
+	}
+
+	It("posts the event in the structured mode", func() {
+		var request *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		Expect(newEmitter(server.URL).Emit(context.Background(), event)).To(Succeed())
+
+		Expect(request.Method).To(Equal(http.MethodPost))
+		Expect(request.Header.Get("Content-Type")).To(Equal(cloudevents.ContentType))
+
+		var received cloudevents.Event
+		Expect(json.Unmarshal(body, &received)).To(Succeed())
+		Expect(received.SpecVersion).To(Equal("1.0"))
+		Expect(received.Type).To(Equal("io.epinio.app.deployed"))
+		Expect(received.Source).To(Equal("/epinio/namespaces/workspace"))
+		Expect(received.Subject).To(Equal("app"))
+		Expect(received.Time).To(BeTemporally("==", event.Time))
+		Expect(received.Data).To(Equal(event))
+		Expect(strings.HasSuffix(received.ID, "-7")).To(BeTrue())
+	})
+
+	It("distinguishes the ids of the events of different servers", func() {
+		Expect(newEmitter("http://sink").Event(event).ID).
+			ToNot(Equal(newEmitter("http://sink").Event(event).ID))
+	})
+
+	It("keeps the source of events without namespace", func() {
+		platform := models.PlatformEvent{ID: 1, Type: models.EventNamespaceCreated, Name: "workspace"}
+		Expect(newEmitter("http://sink").Event(platform).Source).To(Equal("/epinio"))
+	})
+
+	It("retries failed emissions, and gives up after the last attempt", func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := newEmitter(server.URL).Emit(context.Background(), event)
+		Expect(err).To(MatchError(ContainSubstring("503")))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(4)))
+	})
+
+	It("rejects sinks which are not http urls", func() {
+		_, err := cloudevents.NewEmitter("broker.local", "/epinio")
+		Expect(err).To(MatchError(ContainSubstring("not an http(s) url")))
+
+		_, err = cloudevents.NewEmitter("http://broker.local", "")
+		Expect(err).To(MatchError(ContainSubstring("source is empty")))
+	})
+})
@@ -15,6 +15,7 @@ import (
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	epinioerrors "github.com/epinio/epinio/internal/errors"
+	"github.com/epinio/epinio/internal/listing"
 	"github.com/epinio/epinio/internal/names"
 	"github.com/epinio/epinio/internal/namespaces"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
@@ -39,6 +40,7 @@ type Configuration struct {
 	Namespace  string
 	Username   string
 	CreatedAt  metav1.Time
+	Labels     map[string]string
 	kubeClient *kubernetes.Cluster
 }
 
@@ -100,6 +102,7 @@ func List(ctx context.Context, cluster *kubernetes.Cluster, namespace string) (C
 			Name:       name,
 			Namespace:  namespace,
 			Username:   username,
+			Labels:     s.ObjectMeta.Labels,
 			kubeClient: cluster,
 		})
 	}
@@ -107,6 +110,31 @@ func List(ctx context.Context, cluster *kubernetes.Cluster, namespace string) (C
 	return result, nil
 }
 
+// Page returns the page of the configurations selected by the options, and the number of
+// matching configurations.
+func (l ConfigurationList) Page(options listing.Options) (ConfigurationList, int, error) {
+	items := make([]listing.Item, 0, len(l))
+	for _, configuration := range l {
+		items = append(items, listing.Item{
+			Name:      configuration.Name,
+			Namespace: configuration.Namespace,
+			CreatedAt: configuration.CreatedAt.Time,
+			Labels:    configuration.Labels,
+		})
+	}
+
+	page, total, err := options.Select(items)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := ConfigurationList{}
+	for _, i := range page {
+		result = append(result, l[i])
+	}
+	return result, total, nil
+}
+
 // CreateConfiguration creates a new  configuration instance from namespace,
 // name, and a map of parameters.
 func CreateConfiguration(ctx context.Context, cluster *kubernetes.Cluster, name, namespace, username string,
@@ -0,0 +1,167 @@
+// Package events broadcasts the changes of the platform, see models.PlatformEvent, to the
+// clients subscribed to them, e.g. dashboards and bots, so that they do not have to poll. The
+// recent events are kept, for clients reconnecting after missing some.
+//
+// The events are those of the server they are subscribed to. With several replicas of the
+// server, the changes made through the others are not seen.
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// RecentSize is the number of recent events kept in memory.
+const RecentSize = 1000
+
+// subscriptionBuffer is the number of events a subscriber can fall behind. Subscribers falling
+// further behind are dropped.
+const subscriptionBuffer = 100
+
+// Default is the event bus of the server.
+var Default = NewBus(RecentSize)
+
+// Bus numbers the events published to it, keeps the last `size` of them, and sends them to
+// the subscribers.
+type Bus struct {
+	size int
+
+	mu          sync.Mutex
+	lastID      uint64
+	recent      []models.PlatformEvent
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives the events matching its filter, until it is closed.
+type Subscription struct {
+	filter func(models.PlatformEvent) bool
+	events chan models.PlatformEvent
+}
+
+// NewBus returns a bus keeping the last `size` events.
+func NewBus(size int) *Bus {
+	return &Bus{
+		size:        size,
+		recent:      []models.PlatformEvent{},
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish numbers the event, stamps it with the current time if it has none, and sends it
+// to the subscribers. It returns the published event.
+func (b *Bus) Publish(event models.PlatformEvent) models.PlatformEvent {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+
+	b.recent = append(b.recent, event)
+	if len(b.recent) > b.size {
+		b.recent = b.recent[len(b.recent)-b.size:]
+	}
+
+	for s := range b.subscribers {
+		if s.filter != nil && !s.filter(event) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			// Too far behind. Closing makes the client reconnect, and catch up from
+			// the recent events.
+			delete(b.subscribers, s)
+			close(s.events)
+		}
+	}
+
+	return event
+}
+
+// Subscribe returns a subscription to the events matching the filter, nil for all. It also
+// returns the recent matching events after the one with id `after`, which the subscription
+// will not receive. With `after` zero there are none.
+func (b *Bus) Subscribe(after uint64, filter func(models.PlatformEvent) bool) (*Subscription, []models.PlatformEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	missed := []models.PlatformEvent{}
+	if after > 0 {
+		for _, event := range b.recent {
+			if event.ID > after && (filter == nil || filter(event)) {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	s := &Subscription{
+		filter: filter,
+		events: make(chan models.PlatformEvent, subscriptionBuffer),
+	}
+	b.subscribers[s] = struct{}{}
+
+	return s, missed
+}
+
+// Unsubscribe closes the subscription, if the bus did not drop it already.
+func (b *Bus) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.events)
+	}
+}
+
+// Events returns the channel of the events of the subscription. It is closed when the
+// subscription is, or when the subscriber fell too far behind.
+func (s *Subscription) Events() <-chan models.PlatformEvent {
+	return s.events
+}
+
+// Publish publishes an event of the named app, service, or namespace to the default bus. The
+// user is the one of the request of the context, if any.
+func Publish(ctx context.Context, eventType, namespace, name string, details map[string]string) {
+	Default.Publish(models.PlatformEvent{
+		Type:      eventType,
+		Namespace: namespace,
+		Name:      name,
+		User:      requestctx.User(ctx).Username,
+		Details:   details,
+	})
+}
+
+// Filter returns the filter of the events the user may see, i.e. those of their namespaces,
+// or all for admins. A namespace restricts them to the events of that namespace. Types
+// restrict them to the events of these types, or of types starting with them, e.g. `app`
+// for all events of apps.
+func Filter(user auth.User, namespace string, types []string) func(models.PlatformEvent) bool {
+	return func(event models.PlatformEvent) bool {
+		if user.Role != "admin" && user.NamespaceRole(event.Namespace) == "" {
+			return false
+		}
+		if namespace != "" && event.Namespace != namespace {
+			return false
+		}
+		if len(types) == 0 {
+			return true
+		}
+		for _, t := range types {
+			if event.Type == t || strings.HasPrefix(event.Type, t+".") {
+				return true
+			}
+		}
+		return false
+	}
+}
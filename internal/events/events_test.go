@@ -0,0 +1,119 @@
+package events_test
+
+import (
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bus", func() {
+	var bus *events.Bus
+
+	BeforeEach(func() {
+		bus = events.NewBus(3)
+	})
+
+	event := func(eventType, namespace string) models.PlatformEvent {
+		return models.PlatformEvent{Type: eventType, Namespace: namespace, Name: "app"}
+	}
+
+	It("numbers, and stamps, the events", func() {
+		first := bus.Publish(event(models.EventAppCreated, "one"))
+		second := bus.Publish(event(models.EventAppDeleted, "one"))
+		Expect(first.ID).To(Equal(uint64(1)))
+		Expect(second.ID).To(Equal(uint64(2)))
+		Expect(first.Time.IsZero()).To(BeFalse())
+	})
+
+	It("sends the matching events to the subscribers", func() {
+		all, _ := bus.Subscribe(0, nil)
+		one, _ := bus.Subscribe(0, func(e models.PlatformEvent) bool { return e.Namespace == "one" })
+
+		bus.Publish(event(models.EventAppCreated, "one"))
+		bus.Publish(event(models.EventAppCreated, "two"))
+
+		Expect(all.Events()).To(HaveLen(2))
+		Expect(one.Events()).To(HaveLen(1))
+		Expect((<-one.Events()).Namespace).To(Equal("one"))
+	})
+
+	It("returns the recent events missed", func() {
+		for i := 0; i < 5; i++ {
+			bus.Publish(event(models.EventAppScaled, "one"))
+		}
+
+		_, missed := bus.Subscribe(0, nil)
+		Expect(missed).To(BeEmpty())
+
+		_, missed = bus.Subscribe(3, nil)
+		Expect(missed).To(HaveLen(2))
+		Expect(missed[0].ID).To(Equal(uint64(4)))
+
+		// Only the last 3 are kept
+		_, missed = bus.Subscribe(1, nil)
+		Expect(missed).To(HaveLen(3))
+	})
+
+	It("drops subscribers falling behind", func() {
+		s, _ := bus.Subscribe(0, nil)
+		for i := 0; i < 101; i++ {
+			bus.Publish(event(models.EventAppScaled, "one"))
+		}
+
+		count := 0
+		for range s.Events() {
+			count++
+		}
+		Expect(count).To(Equal(100))
+
+		// Unsubscribing a dropped subscription is fine
+		bus.Unsubscribe(s)
+	})
+
+	It("closes the subscriptions on unsubscribe", func() {
+		s, _ := bus.Subscribe(0, nil)
+		bus.Unsubscribe(s)
+		_, ok := <-s.Events()
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Filter", func() {
+	user := auth.User{
+		Username:   "alice",
+		Role:       "user",
+		Namespaces: []string{"one"},
+	}
+
+	event := func(eventType, namespace string) models.PlatformEvent {
+		return models.PlatformEvent{Type: eventType, Namespace: namespace}
+	}
+
+	It("passes the events of the namespaces of the user", func() {
+		filter := events.Filter(user, "", nil)
+		Expect(filter(event(models.EventAppCreated, "one"))).To(BeTrue())
+		Expect(filter(event(models.EventAppCreated, "two"))).To(BeFalse())
+	})
+
+	It("passes all events to admins", func() {
+		filter := events.Filter(auth.User{Username: "admin", Role: "admin"}, "", nil)
+		Expect(filter(event(models.EventNamespaceDeleted, "two"))).To(BeTrue())
+	})
+
+	It("restricts the events to the namespace", func() {
+		filter := events.Filter(auth.User{Role: "admin"}, "one", nil)
+		Expect(filter(event(models.EventAppCreated, "one"))).To(BeTrue())
+		Expect(filter(event(models.EventAppCreated, "two"))).To(BeFalse())
+	})
+
+	It("restricts the events to the types, and their prefixes", func() {
+		filter := events.Filter(user, "", []string{"app.staging", models.EventServiceDeleted})
+		Expect(filter(event(models.EventStagingFailed, "one"))).To(BeTrue())
+		Expect(filter(event(models.EventServiceDeleted, "one"))).To(BeTrue())
+		Expect(filter(event(models.EventAppDeployed, "one"))).To(BeFalse())
+		Expect(filter(event(models.EventServiceCreated, "one"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,243 @@
+// Package health checks the dependencies of the server, for the liveness and readiness
+// probes of its deployment. Each check reports its own status, so that a failing probe tells
+// which dependency is missing.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/admission"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/internal/registry"
+	"github.com/epinio/epinio/internal/s3manager"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+// checkTimeout is the time a check has to succeed. Probes have a timeout of their own, of a
+// few seconds, a hanging dependency must not hang the probe.
+const checkTimeout = 3 * time.Second
+
+// Statuses of the checks, and of the reports.
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// draining is set when the server is shutting down.
+var draining int32
+
+// Drain marks the server as shutting down. The readiness probe fails from then on, for the
+// server to get no new requests, while it finishes those in flight.
+func Drain() {
+	atomic.StoreInt32(&draining, 1)
+}
+
+// Draining returns true if the server is shutting down.
+func Draining() bool {
+	return atomic.LoadInt32(&draining) == 1
+}
+
+// Check is a check of a dependency.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// CheckStatus is the result of a check.
+type CheckStatus struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the result of the checks of a probe. Its status is ok if all the checks are.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckStatus `json:"checks"`
+}
+
+// Healthy returns true if all the checks of the report succeeded.
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Run runs the checks, concurrently, and reports their results.
+func Run(ctx context.Context, checks []Check) Report {
+	report := Report{Status: StatusOK, Checks: map[string]CheckStatus{}}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := runCheck(ctx, check)
+			status := CheckStatus{Status: StatusOK, Duration: time.Since(start).Round(time.Millisecond).String()}
+			if err != nil {
+				status.Status = StatusFailed
+				status.Error = err.Error()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks[check.Name] = status
+			if err != nil {
+				report.Status = StatusFailed
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// runCheck runs the check, until the context is done. A check not heeding the context is
+// left behind.
+func runCheck(ctx context.Context, check Check) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- check.Run(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "check did not finish")
+	}
+}
+
+// Handler serves the report of the checks, as JSON, with status 503 if a check failed. The
+// checks are made for the cluster, when it cannot be reached they all fail.
+func Handler(checks func(*kubernetes.Cluster) []Check) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var report Report
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			report = Report{
+				Status: StatusFailed,
+				Checks: map[string]CheckStatus{"kubernetes": {Status: StatusFailed, Error: err.Error()}},
+			}
+		} else {
+			report = Run(ctx, checks(cluster))
+		}
+
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
+
+// LivenessChecks returns the checks of the liveness probe, only the API server. The other
+// dependencies failing is no reason to restart the server, only to not send it requests.
+func LivenessChecks(cluster *kubernetes.Cluster) []Check {
+	return []Check{KubernetesCheck(cluster.Kubectl)}
+}
+
+// ReadinessChecks returns the checks of the readiness probe, all the dependencies, and the
+// server not shutting down.
+func ReadinessChecks(cluster *kubernetes.Cluster) []Check {
+	return []Check{
+		DrainCheck(),
+		KubernetesCheck(cluster.Kubectl),
+		SecretCheck("registry", cluster.Kubectl, helmchart.Namespace(), registry.CredentialsSecretName),
+		BlobStoreCheck(cluster),
+		CRDCheck(cluster.Kubectl, admission.AppResource.Resource, admission.AppChartResource.Resource, admission.ServiceResource.Resource),
+	}
+}
+
+// DrainCheck checks the server is not shutting down.
+func DrainCheck() Check {
+	return Check{
+		Name: "server",
+		Run: func(ctx context.Context) error {
+			if Draining() {
+				return errors.New("shutting down")
+			}
+			return nil
+		},
+	}
+}
+
+// KubernetesCheck checks the API server is reachable.
+func KubernetesCheck(kube kubeclient.Interface) Check {
+	return Check{
+		Name: "kubernetes",
+		Run: func(ctx context.Context) error {
+			_, err := kube.Discovery().ServerVersion()
+			return errors.Wrap(err, "getting the version of the API server")
+		},
+	}
+}
+
+// SecretCheck checks the secret is present.
+func SecretCheck(name string, kube kubeclient.Interface, namespace, secret string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			_, err := kube.CoreV1().Secrets(namespace).Get(ctx, secret, metav1.GetOptions{})
+			return errors.Wrapf(err, "getting secret %s/%s", namespace, secret)
+		},
+	}
+}
+
+// BlobStoreCheck checks the S3 storage of the application sources is reachable, and has its
+// bucket.
+func BlobStoreCheck(cluster *kubernetes.Cluster) Check {
+	return Check{
+		Name: "blobstore",
+		Run: func(ctx context.Context) error {
+			details, err := s3manager.GetConnectionDetails(ctx, cluster, helmchart.Namespace(), helmchart.S3ConnectionDetailsSecretName)
+			if err != nil {
+				return errors.Wrap(err, "fetching the S3 connection details")
+			}
+			manager, err := s3manager.New(details)
+			if err != nil {
+				return errors.Wrap(err, "creating an S3 manager")
+			}
+			return manager.Ping(ctx)
+		},
+	}
+}
+
+// CRDCheck checks the Epinio resources are served, i.e. their CRDs are installed.
+func CRDCheck(kube kubeclient.Interface, resources ...string) Check {
+	return Check{
+		Name: "crds",
+		Run: func(ctx context.Context) error {
+			groupVersion := admission.Group + "/" + admission.Version
+			list, err := kube.Discovery().ServerResourcesForGroupVersion(groupVersion)
+			if err != nil {
+				return errors.Wrapf(err, "listing the resources of %s", groupVersion)
+			}
+
+			served := map[string]bool{}
+			for _, resource := range list.APIResources {
+				served[resource.Name] = true
+			}
+			for _, resource := range resources {
+				if !served[resource] {
+					return errors.Errorf("resource %s.%s not installed", resource, admission.Group)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/epinio/epinio/internal/health"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Run", func() {
+	ok := health.Check{Name: "ok", Run: func(ctx context.Context) error { return nil }}
+	failing := health.Check{Name: "failing", Run: func(ctx context.Context) error { return errors.New("unreachable") }}
+	hanging := health.Check{Name: "hanging", Run: func(ctx context.Context) error {
+		time.Sleep(time.Minute)
+		return nil
+	}}
+
+	It("reports ok when all the checks are", func() {
+		report := health.Run(context.Background(), []health.Check{ok})
+		Expect(report.Healthy()).To(BeTrue())
+		Expect(report.Checks).To(HaveKeyWithValue("ok", HaveField("Status", health.StatusOK)))
+	})
+
+	It("reports each check, and fails when one does", func() {
+		report := health.Run(context.Background(), []health.Check{ok, failing})
+		Expect(report.Healthy()).To(BeFalse())
+		Expect(report.Status).To(Equal(health.StatusFailed))
+		Expect(report.Checks["ok"].Status).To(Equal(health.StatusOK))
+		Expect(report.Checks["failing"].Status).To(Equal(health.StatusFailed))
+		Expect(report.Checks["failing"].Error).To(Equal("unreachable"))
+	})
+
+	It("fails the checks which do not finish in time", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		report := health.Run(ctx, []health.Check{ok, hanging})
+		Expect(report.Healthy()).To(BeFalse())
+		Expect(report.Checks["ok"].Status).To(Equal(health.StatusOK))
+		Expect(report.Checks["hanging"].Error).To(ContainSubstring("did not finish"))
+	})
+})
+
+var _ = Describe("Checks", func() {
+	var kube *kubefake.Clientset
+
+	BeforeEach(func() {
+		kube = kubefake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-creds", Namespace: "epinio"},
+		})
+		kube.Resources = []*metav1.APIResourceList{{
+			GroupVersion: "application.epinio.io/v1",
+			APIResources: []metav1.APIResource{{Name: "apps"}, {Name: "appcharts"}},
+		}}
+	})
+
+	It("checks the API server", func() {
+		Expect(health.KubernetesCheck(kube).Run(context.Background())).To(Succeed())
+	})
+
+	It("checks the secret is present", func() {
+		Expect(health.SecretCheck("registry", kube, "epinio", "registry-creds").Run(context.Background())).To(Succeed())
+		Expect(health.SecretCheck("registry", kube, "epinio", "missing").Run(context.Background())).
+			To(MatchError(ContainSubstring("getting secret epinio/missing")))
+	})
+
+	It("checks the resources are served", func() {
+		Expect(health.CRDCheck(kube, "apps", "appcharts").Run(context.Background())).To(Succeed())
+		Expect(health.CRDCheck(kube, "apps", "services").Run(context.Background())).
+			To(MatchError("resource services.application.epinio.io not installed"))
+	})
+
+	It("fails without the resources of the group", func() {
+		kube.Resources = nil
+		Expect(health.CRDCheck(kube, "apps").Run(context.Background())).
+			To(MatchError(ContainSubstring("listing the resources of application.epinio.io/v1")))
+	})
+})
+
+var _ = Describe("DrainCheck", func() {
+	It("fails once the server is shutting down", func() {
+		Expect(health.DrainCheck().Run(context.Background())).To(Succeed())
+
+		health.Drain()
+		Expect(health.Draining()).To(BeTrue())
+		Expect(health.DrainCheck().Run(context.Background())).To(MatchError("shutting down"))
+	})
+})
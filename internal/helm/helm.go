@@ -5,7 +5,9 @@ package helm
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -15,29 +17,48 @@ import (
 	"github.com/epinio/epinio/internal/duration"
 	"github.com/epinio/epinio/internal/names"
 	"github.com/epinio/epinio/internal/routes"
+	"github.com/epinio/epinio/internal/tracing"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/go-logr/logr"
 	hc "github.com/mittwald/go-helm-client"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
 	"gopkg.in/yaml.v2"
 	helmrelease "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 )
 
+// backendProtocolAnnotation is the ingress annotation selecting the protocol spoken to the application.
+const backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
 type ChartParameters struct {
-	models.AppRef                        // Application: name & namespace
-	Context        context.Context       // Operation context
-	Cluster        *kubernetes.Cluster   // Cluster to talk to.
-	Chart          string                // Name of Chart CR to use for deployment
-	ImageURL       string                // Application Image
-	Username       string                // User causing the (re)deployment
-	Instances      int32                 // Number Of Desired Replicas
-	StageID        string                // Stage ID that produced ImageURL
-	Environment    models.EnvVariableMap // App Environment
-	Configurations []string              // Bound Configurations (list of names)
-	Routes         []string              // Desired application routes
-	Start          *int64                // Nano-epoch of deployment. Optional. Used to force a restart, even when nothing else has changed.
+	models.AppRef                                 // Application: name & namespace
+	Context            context.Context            // Operation context
+	Cluster            *kubernetes.Cluster        // Cluster to talk to.
+	Chart              string                     // Name of Chart CR to use for deployment
+	ImageURL           string                     // Application Image
+	Username           string                     // User causing the (re)deployment
+	Instances          int32                      // Number Of Desired Replicas
+	Rollout            *models.AppRollout         // Rolling update parameters. Optional.
+	Tasks              []models.AppTask           // Scheduled tasks. Optional.
+	Processes          map[string]int32           // Additional process types and their instances. Optional.
+	Sidecars           []models.AppSidecar        // Additional containers next to the main container. Optional.
+	Volumes            []models.AppVolume         // Persistent volumes mounted into the instances. Optional.
+	ConfigurationPaths map[string]string          // Bound configurations projected to a non-default directory. Optional.
+	RouteAnnotations   models.AppRouteAnnotations // Ingress annotations of the routes. Optional.
+	Placement          *models.AppPlacement       // Node placement constraints of the instances. Optional.
+	Termination        *models.AppTermination     // Shutdown settings of the instances. Optional.
+	Entrypoint         *models.AppEntrypoint      // Start command override of the main container. Optional.
+	ChartValues        map[string]string          // User values for the settings declared by the chart. Optional.
+	StageID            string                     // Stage ID that produced ImageURL
+	Environment        models.EnvVariableMap      // App Environment
+	Configurations     []string                   // Bound Configurations (list of names)
+	Routes             []string                   // Desired application routes
+	Start              *int64                     // Nano-epoch of deployment. Optional. Used to force a restart, even when nothing else has changed.
+	Canary             *models.AppCanary          // Canary deployment next to the stable version. Optional.
 }
 
 func Values(cluster *kubernetes.Cluster, logger logr.Logger, app models.AppRef) ([]byte, error) {
@@ -61,13 +82,22 @@ func Values(cluster *kubernetes.Cluster, logger logr.Logger, app models.AppRef)
 	return yaml, nil
 }
 
-func Remove(cluster *kubernetes.Cluster, logger logr.Logger, app models.AppRef) error {
+func Remove(ctx context.Context, cluster *kubernetes.Cluster, logger logr.Logger, app models.AppRef) error {
 	client, err := GetHelmClient(cluster.RestConfig, logger, app.Namespace)
 	if err != nil {
 		return err
 	}
 
-	return client.UninstallReleaseByName(names.ReleaseName(app.Name))
+	_, span := tracing.Start(ctx, "helm uninstall", tracing.KindInternal)
+	span.SetAttributes(
+		attribute.String("helm.release", names.ReleaseName(app.Name)),
+		attribute.String("helm.namespace", app.Namespace),
+	)
+
+	err = client.UninstallReleaseByName(names.ReleaseName(app.Name))
+	tracing.Finish(span, err)
+
+	return err
 }
 
 func Deploy(logger logr.Logger, parameters ChartParameters) error {
@@ -96,14 +126,32 @@ func Deploy(logger logr.Logger, parameters ChartParameters) error {
 			","))
 	}
 
+	// Annotations are keyed by domain and path, without the route options.
+	routeAnnotations := map[string]map[string]string{}
+	for route, annotations := range parameters.RouteAnnotations {
+		r := routes.FromString(route)
+		routeAnnotations[r.Domain+r.Path] = annotations
+	}
+
 	routesYaml := "~"
 	if len(parameters.Routes) > 0 {
 		rs := []string{}
 		for _, desired := range parameters.Routes {
 			r := routes.FromString(desired)
-			rs = append(rs, fmt.Sprintf(`{"id":"%s","domain":"%s","path":"%s"}`,
-				strings.ReplaceAll(r.String(), "/", "."),
-				r.Domain, r.Path))
+			annotations := map[string]string{}
+			for key, value := range routeAnnotations[r.Domain+r.Path] {
+				annotations[key] = value
+			}
+			if r.IsGRPC() {
+				// The ingress controller talks h2c to the application.
+				annotations[backendProtocolAnnotation] = "GRPC"
+			}
+			encoded, err := json.Marshal(annotations)
+			if err != nil {
+				return err
+			}
+			rs = append(rs, fmt.Sprintf(`{"id":"%s","domain":"%s","path":"%s","rewrite":"%s","secret":"%s","annotations":%s}`,
+				r.ID(), r.Domain, r.Path, r.Rewrite, r.TLSSecret, encoded))
 		}
 		routesYaml = fmt.Sprintf(`[%s]`, strings.Join(rs, `,`))
 	}
@@ -119,19 +167,163 @@ func Deploy(logger logr.Logger, parameters ChartParameters) error {
 		start = fmt.Sprintf(`start: "%d"`, *parameters.Start)
 	}
 
+	canary := "~"
+	if parameters.Canary != nil {
+		canary = fmt.Sprintf(`{"imageURL":"%s","stageID":"%s","weight":%d,"errorThreshold":%d}`,
+			parameters.Canary.ImageURL,
+			parameters.Canary.StageID,
+			parameters.Canary.Weight,
+			parameters.Canary.ErrorThreshold)
+	}
+
+	rollout := "~"
+	if parameters.Rollout != nil {
+		rs := []string{}
+		if parameters.Rollout.MaxUnavailable != "" {
+			rs = append(rs, fmt.Sprintf(`"maxUnavailable":%s`, rolloutValue(parameters.Rollout.MaxUnavailable)))
+		}
+		if parameters.Rollout.MaxSurge != "" {
+			rs = append(rs, fmt.Sprintf(`"maxSurge":%s`, rolloutValue(parameters.Rollout.MaxSurge)))
+		}
+		rollout = fmt.Sprintf(`{%s}`, strings.Join(rs, `,`))
+	}
+
+	tasks := `[]`
+	if len(parameters.Tasks) > 0 {
+		// JSON is YAML, and takes care of quoting the commands.
+		encoded, err := json.Marshal(parameters.Tasks)
+		if err != nil {
+			return errors.Wrap(err, "encoding tasks")
+		}
+		tasks = string(encoded)
+	}
+
+	processes := `[]`
+	if len(parameters.Processes) > 0 {
+		ps := []string{}
+		for _, name := range sortedProcesses(parameters.Processes) {
+			ps = append(ps, fmt.Sprintf(`{"name":"%s","replicaCount":%d}`,
+				name, parameters.Processes[name]))
+		}
+		processes = fmt.Sprintf(`[%s]`, strings.Join(ps, `,`))
+	}
+
+	sidecars := `[]`
+	if len(parameters.Sidecars) > 0 {
+		// JSON is YAML, and takes care of quoting the environment.
+		encoded, err := json.Marshal(parameters.Sidecars)
+		if err != nil {
+			return errors.Wrap(err, "encoding sidecars")
+		}
+		sidecars = string(encoded)
+	}
+
+	volumes := `[]`
+	if len(parameters.Volumes) > 0 {
+		vs := []string{}
+		for _, volume := range parameters.Volumes {
+			vs = append(vs, fmt.Sprintf(`{"name":"%s","path":"%s","claim":"%s"}`,
+				volume.Name, volume.Path, parameters.MakeVolumeClaimName(volume.Name)))
+		}
+		volumes = fmt.Sprintf(`[%s]`, strings.Join(vs, `,`))
+	}
+
+	configurationPaths := `{}`
+	if len(parameters.ConfigurationPaths) > 0 {
+		// JSON is YAML, and takes care of quoting the paths.
+		encoded, err := json.Marshal(parameters.ConfigurationPaths)
+		if err != nil {
+			return errors.Wrap(err, "encoding configuration paths")
+		}
+		configurationPaths = string(encoded)
+	}
+
+	nodeSelector, tolerations, topologySpread, err := placementValues(parameters.AppRef, parameters.Placement)
+	if err != nil {
+		return errors.Wrap(err, "encoding placement")
+	}
+
+	gracePeriod := "~"
+	lifecycle := "~"
+	if parameters.Termination != nil {
+		if parameters.Termination.GracePeriod > 0 {
+			gracePeriod = fmt.Sprintf("%d", parameters.Termination.GracePeriod)
+		}
+		if len(parameters.Termination.PreStop) > 0 {
+			// JSON is YAML, and takes care of quoting the command.
+			encoded, err := json.Marshal(corev1.Lifecycle{
+				PreStop: &corev1.LifecycleHandler{
+					Exec: &corev1.ExecAction{Command: parameters.Termination.PreStop},
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "encoding lifecycle")
+			}
+			lifecycle = string(encoded)
+		}
+	}
+
+	command, args := "~", "~"
+	if parameters.Entrypoint != nil {
+		// JSON is YAML, and takes care of quoting the command and arguments.
+		if len(parameters.Entrypoint.Command) > 0 {
+			encoded, err := json.Marshal(parameters.Entrypoint.Command)
+			if err != nil {
+				return errors.Wrap(err, "encoding command")
+			}
+			command = string(encoded)
+		}
+		if len(parameters.Entrypoint.Args) > 0 {
+			encoded, err := json.Marshal(parameters.Entrypoint.Args)
+			if err != nil {
+				return errors.Wrap(err, "encoding args")
+			}
+			args = string(encoded)
+		}
+	}
+
+	userConfig := `{}`
+	if len(parameters.ChartValues) > 0 {
+		values, err := appchart.Values(appChart, parameters.ChartValues)
+		if err != nil {
+			return errors.Wrap(err, "checking chart values")
+		}
+		// JSON is YAML, and takes care of quoting the values.
+		encoded, err := json.Marshal(values)
+		if err != nil {
+			return errors.Wrap(err, "encoding chart values")
+		}
+		userConfig = string(encoded)
+	}
+
 	yamlParameters := fmt.Sprintf(`
 epinio:
   appName: "%[9]s"
+  args: %[25]s
+  canary: %[12]s
+  command: %[24]s
   env: %[6]s
   imageURL: "%[3]s"
   ingress: %[10]s
+  lifecycle: %[23]s
+  nodeSelector: %[19]s
+  processes: %[15]s
   replicaCount: %[1]d
+  rollout: %[13]s
   routes: %[7]s
+  sidecars: %[16]s
   configurations: %[5]s
+  configurationPaths: %[18]s
   stageID: "%[2]s"
+  tasks: %[14]s
+  terminationGracePeriodSeconds: %[22]s
   tlsIssuer: "%[11]s"
+  tolerations: %[20]s
+  topologySpreadConstraints: %[21]s
   username: "%[4]s"
+  volumes: %[17]s
   %[8]s
+userConfig: %[26]s
 `, parameters.Instances,
 		parameters.StageID,
 		parameters.ImageURL,
@@ -143,6 +335,21 @@ epinio:
 		parameters.Name,
 		ingress,
 		viper.GetString("tls-issuer"),
+		canary,
+		rollout,
+		tasks,
+		processes,
+		sidecars,
+		volumes,
+		configurationPaths,
+		nodeSelector,
+		tolerations,
+		topologySpread,
+		gracePeriod,
+		lifecycle,
+		command,
+		args,
+		userConfig,
 	)
 
 	logger.Info("app helm setup", "parameters", yamlParameters)
@@ -158,10 +365,14 @@ epinio:
 	// See also part.go, fetchAppChart
 	if appChart.HelmRepo != "" {
 		name := names.GenerateResourceName("hr-" + base64.StdEncoding.EncodeToString([]byte(appChart.HelmRepo)))
-		if err := client.AddOrUpdateChartRepo(repo.Entry{
+		_, span := tracing.Start(parameters.Context, "helm repo update", tracing.KindInternal)
+		span.SetAttributes(attribute.String("helm.repo", appChart.HelmRepo))
+		err := client.AddOrUpdateChartRepo(repo.Entry{
 			Name: name,
 			URL:  appChart.HelmRepo,
-		}); err != nil {
+		})
+		tracing.Finish(span, err)
+		if err != nil {
 			return errors.Wrap(err, "creating the chart repository")
 		}
 
@@ -186,11 +397,36 @@ epinio:
 		ReuseValues: true,
 	}
 
-	if _, err := client.InstallOrUpgradeChart(context.Background(), &chartSpec); err != nil {
-		return err
+	_, span := tracing.Start(parameters.Context, "helm install-or-upgrade", tracing.KindInternal)
+	span.SetAttributes(
+		attribute.String("helm.release", chartSpec.ReleaseName),
+		attribute.String("helm.chart", chartSpec.ChartName),
+		attribute.String("helm.namespace", chartSpec.Namespace),
+	)
+
+	_, err = client.InstallOrUpgradeChart(context.Background(), &chartSpec)
+	tracing.Finish(span, err)
+
+	return err
+}
+
+// sortedProcesses returns the names of the process types in order, for stable helm values.
+func sortedProcesses(processes map[string]int32) []string {
+	result := []string{}
+	for name := range processes {
+		result = append(result, name)
 	}
+	sort.Strings(result)
+	return result
+}
 
-	return nil
+// rolloutValue converts a rolling update parameter into its YAML form. Percentages are
+// strings, absolute numbers are integers, as expected by a kube Deployment.
+func rolloutValue(value string) string {
+	if strings.HasSuffix(value, "%") {
+		return fmt.Sprintf(`"%s"`, value)
+	}
+	return value
 }
 
 func Status(ctx context.Context, logger logr.Logger, cluster *kubernetes.Cluster, namespace, releaseName string) (helmrelease.Status, error) {
@@ -199,8 +435,15 @@ func Status(ctx context.Context, logger logr.Logger, cluster *kubernetes.Cluster
 		return "", err
 	}
 
-	var r *helmrelease.Release
-	if r, err = client.GetRelease(releaseName); err != nil {
+	_, span := tracing.Start(ctx, "helm status", tracing.KindInternal)
+	span.SetAttributes(
+		attribute.String("helm.release", releaseName),
+		attribute.String("helm.namespace", namespace),
+	)
+
+	r, err := client.GetRelease(releaseName)
+	tracing.Finish(span, err)
+	if err != nil {
 		return "", err
 	}
 
@@ -228,3 +471,73 @@ func GetHelmClient(restConfig *rest.Config, logger logr.Logger, namespace string
 
 	return hc.NewClientFromRestConf(options)
 }
+
+// placementValues returns the node selector, tolerations, and topology spread constraints of
+// the placement, as the YAML of the kube resources. The constraints spread the instances of
+// the application.
+func placementValues(app models.AppRef, placement *models.AppPlacement) (string, string, string, error) {
+	nodeSelector, tolerations, topologySpread := `{}`, `[]`, `[]`
+	if placement == nil {
+		return nodeSelector, tolerations, topologySpread, nil
+	}
+
+	// JSON is YAML, and takes care of quoting.
+	if len(placement.NodeSelector) > 0 {
+		encoded, err := json.Marshal(placement.NodeSelector)
+		if err != nil {
+			return "", "", "", err
+		}
+		nodeSelector = string(encoded)
+	}
+
+	if len(placement.Tolerations) > 0 {
+		ts := []corev1.Toleration{}
+		for _, toleration := range placement.Tolerations {
+			ts = append(ts, corev1.Toleration{
+				Key:               toleration.Key,
+				Operator:          corev1.TolerationOperator(toleration.Operator),
+				Value:             toleration.Value,
+				Effect:            corev1.TaintEffect(toleration.Effect),
+				TolerationSeconds: toleration.TolerationSeconds,
+			})
+		}
+		encoded, err := json.Marshal(ts)
+		if err != nil {
+			return "", "", "", err
+		}
+		tolerations = string(encoded)
+	}
+
+	if len(placement.TopologySpread) > 0 {
+		cs := []corev1.TopologySpreadConstraint{}
+		for _, spread := range placement.TopologySpread {
+			maxSkew := spread.MaxSkew
+			if maxSkew == 0 {
+				maxSkew = 1
+			}
+			whenUnsatisfiable := corev1.UnsatisfiableConstraintAction(spread.WhenUnsatisfiable)
+			if whenUnsatisfiable == "" {
+				whenUnsatisfiable = corev1.DoNotSchedule
+			}
+			cs = append(cs, corev1.TopologySpreadConstraint{
+				MaxSkew:           maxSkew,
+				TopologyKey:       spread.TopologyKey,
+				WhenUnsatisfiable: whenUnsatisfiable,
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app.kubernetes.io/component": "application",
+						"app.kubernetes.io/name":      app.Name,
+						"app.kubernetes.io/part-of":   app.Namespace,
+					},
+				},
+			})
+		}
+		encoded, err := json.Marshal(cs)
+		if err != nil {
+			return "", "", "", err
+		}
+		topologySpread = string(encoded)
+	}
+
+	return nodeSelector, tolerations, topologySpread, nil
+}
@@ -0,0 +1,165 @@
+// Package helmexport converts an application into a standalone Helm chart. The chart deploys
+// the image of the application with its environment, bindings, routes, and scaling, on a
+// cluster without Epinio. The bindings become references to secrets holding the data of the
+// configurations, which have to exist in the namespace of the release. Additional process
+// types, sidecars, tasks, and volumes are not exported.
+package helmexport
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/epinio/epinio/internal/routes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Port is the port the applications listen on, given to them in the PORT variable, as done
+// by the app chart.
+const Port = 8080
+
+// ChartVersion is the version of the exported charts.
+const ChartVersion = "0.1.0"
+
+// Values are the values of the exported chart. The pull secrets are empty, the credentials of
+// private registries are not returned by the API.
+type Values struct {
+	Image        Image     `yaml:"image"`
+	ReplicaCount int32     `yaml:"replicaCount"`
+	Port         int32     `yaml:"port"`
+	Command      []string  `yaml:"command,omitempty"`
+	Args         []string  `yaml:"args,omitempty"`
+	Env          []Env     `yaml:"env"`
+	Bindings     []Binding `yaml:"bindings"`
+	Ingress      Ingress   `yaml:"ingress"`
+	PullSecrets  []string  `yaml:"imagePullSecrets"`
+}
+
+// Image is the image of the application.
+type Image struct {
+	Repository string `yaml:"repository"`
+	PullPolicy string `yaml:"pullPolicy"`
+}
+
+// Env is an environment variable of the application.
+type Env struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// Binding is a configuration bound to the application, mounted from the secret into
+// `/configurations/NAME`, as done by the app chart.
+type Binding struct {
+	Name       string `yaml:"name"`
+	SecretName string `yaml:"secretName"`
+}
+
+// Ingress holds the routes of the application. An empty class uses the default class of
+// the cluster.
+type Ingress struct {
+	Enabled   bool    `yaml:"enabled"`
+	ClassName string  `yaml:"className"`
+	Routes    []Route `yaml:"routes"`
+}
+
+// Route is a host and path routed to the application, with the secret of its certificate,
+// if any.
+type Route struct {
+	Host      string `yaml:"host"`
+	Path      string `yaml:"path"`
+	TLSSecret string `yaml:"tlsSecret,omitempty"`
+}
+
+// Files returns the files of the chart of the application, by their path relative to the
+// directory of the chart.
+func Files(app models.App) (map[string][]byte, error) {
+	if app.ImageURL == "" {
+		return nil, fmt.Errorf("application '%s' has no image to export, push it first", app.Meta.Name)
+	}
+
+	chart, err := yaml.Marshal(chartMetadata(app))
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding the chart metadata")
+	}
+	values, err := yaml.Marshal(ValuesOf(app))
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding the chart values")
+	}
+
+	files := map[string][]byte{
+		"Chart.yaml":  chart,
+		"values.yaml": values,
+	}
+	for name, template := range templates {
+		files[filepath.Join("templates", name)] = []byte(template)
+	}
+
+	return files, nil
+}
+
+// ValuesOf returns the values of the chart of the application.
+func ValuesOf(app models.App) Values {
+	config := app.Configuration
+
+	values := Values{
+		Image: Image{
+			Repository: app.ImageURL,
+			PullPolicy: "IfNotPresent",
+		},
+		ReplicaCount: 1,
+		Port:         Port,
+		Env:          []Env{},
+		Bindings:     []Binding{},
+		Ingress:      Ingress{Routes: []Route{}},
+		PullSecrets:  []string{},
+	}
+	if config.Instances != nil {
+		values.ReplicaCount = *config.Instances
+	}
+
+	for _, variable := range config.Environment.List() {
+		values.Env = append(values.Env, Env{Name: variable.Name, Value: variable.Value})
+	}
+
+	for _, configuration := range config.Configurations {
+		values.Bindings = append(values.Bindings, Binding{
+			Name:       configuration,
+			SecretName: configuration,
+		})
+	}
+
+	for _, routeStr := range config.Routes {
+		route := routes.FromString(routeStr)
+		values.Ingress.Routes = append(values.Ingress.Routes, Route{
+			Host:      route.Domain,
+			Path:      route.Path,
+			TLSSecret: route.TLSSecret,
+		})
+	}
+	values.Ingress.Enabled = len(values.Ingress.Routes) > 0
+
+	if config.Entrypoint != nil {
+		values.Command = config.Entrypoint.Command
+		values.Args = config.Entrypoint.Args
+	}
+
+	return values
+}
+
+// chartMetadata returns the contents of the Chart.yaml of the application.
+func chartMetadata(app models.App) yaml.MapSlice {
+	appVersion := app.StageID
+	if appVersion == "" {
+		appVersion = "latest"
+	}
+
+	return yaml.MapSlice{
+		{Key: "apiVersion", Value: "v2"},
+		{Key: "name", Value: app.Meta.Name},
+		{Key: "description", Value: fmt.Sprintf("Application %s, exported from namespace %s", app.Meta.Name, app.Meta.Namespace)},
+		{Key: "type", Value: "application"},
+		{Key: "version", Value: ChartVersion},
+		{Key: "appVersion", Value: appVersion},
+	}
+}
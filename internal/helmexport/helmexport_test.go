@@ -0,0 +1,99 @@
+package helmexport_test
+
+import (
+	"strings"
+
+	"github.com/epinio/epinio/internal/helmexport"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// render loads the exported chart of the app, and renders it for a release of its name.
+func render(app models.App) (*chart.Chart, map[string]string) {
+	files, err := helmexport.Files(app)
+	Expect(err).ToNot(HaveOccurred())
+
+	buffered := []*loader.BufferedFile{}
+	for name, data := range files {
+		buffered = append(buffered, &loader.BufferedFile{Name: name, Data: data})
+	}
+	loaded, err := loader.LoadFiles(buffered)
+	Expect(err).ToNot(HaveOccurred())
+
+	options := chartutil.ReleaseOptions{Name: app.Meta.Name, Namespace: "default", Revision: 1, IsInstall: true}
+	values, err := chartutil.ToRenderValues(loaded, nil, options, nil)
+	Expect(err).ToNot(HaveOccurred())
+	rendered, err := engine.Render(loaded, values)
+	Expect(err).ToNot(HaveOccurred())
+
+	return loaded, rendered
+}
+
+var _ = Describe("Helm export", func() {
+	var app models.App
+
+	BeforeEach(func() {
+		instances := int32(3)
+		app = models.App{
+			Meta:     models.AppRef{Meta: models.Meta{Name: "web", Namespace: "workspace"}},
+			ImageURL: "registry.example.com/apps/web:abc",
+			StageID:  "abc",
+			Configuration: models.ApplicationUpdateRequest{
+				Instances:      &instances,
+				Environment:    models.EnvVariableMap{"MODE": "production", "DEBUG": "0"},
+				Configurations: []string{"db"},
+				Routes:         []string{"web.example.com", "example.com/web?tls-secret=cert"},
+			},
+		}
+	})
+
+	It("captures the image, scaling, environment, bindings, and routes in the values", func() {
+		values := helmexport.ValuesOf(app)
+		Expect(values.Image.Repository).To(Equal("registry.example.com/apps/web:abc"))
+		Expect(values.ReplicaCount).To(Equal(int32(3)))
+		Expect(values.Env).To(Equal([]helmexport.Env{{Name: "DEBUG", Value: "0"}, {Name: "MODE", Value: "production"}}))
+		Expect(values.Bindings).To(Equal([]helmexport.Binding{{Name: "db", SecretName: "db"}}))
+		Expect(values.Ingress.Enabled).To(BeTrue())
+		Expect(values.Ingress.Routes).To(Equal([]helmexport.Route{
+			{Host: "web.example.com", Path: "/"},
+			{Host: "example.com", Path: "/web", TLSSecret: "cert"},
+		}))
+	})
+
+	It("refuses apps without image", func() {
+		app.ImageURL = ""
+		_, err := helmexport.Files(app)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("renders a chart deploying the app", func() {
+		chart, rendered := render(app)
+		Expect(chart.Metadata.Name).To(Equal("web"))
+		Expect(chart.Metadata.AppVersion).To(Equal("abc"))
+
+		deployment := rendered["web/templates/deployment.yaml"]
+		Expect(deployment).To(ContainSubstring("replicas: 3"))
+		Expect(deployment).To(ContainSubstring("image: registry.example.com/apps/web:abc"))
+		Expect(deployment).To(ContainSubstring("- name: MODE\n              value: \"production\""))
+		Expect(deployment).To(ContainSubstring("mountPath: /configurations/db"))
+		Expect(deployment).To(ContainSubstring("secretName: db"))
+
+		ingress := rendered["web/templates/ingress.yaml"]
+		Expect(ingress).To(ContainSubstring("host: \"web.example.com\""))
+		Expect(ingress).To(ContainSubstring("path: /web"))
+		Expect(ingress).To(ContainSubstring("secretName: cert"))
+
+		Expect(rendered["web/templates/service.yaml"]).To(ContainSubstring("port: 8080"))
+	})
+
+	It("renders no ingress for apps without routes", func() {
+		app.Configuration.Routes = nil
+		_, rendered := render(app)
+		Expect(strings.TrimSpace(rendered["web/templates/ingress.yaml"])).To(BeEmpty())
+	})
+})
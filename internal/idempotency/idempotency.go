@@ -0,0 +1,339 @@
+// Package idempotency makes retries of mutating API requests safe. A request carrying an
+// `Idempotency-Key` header is run once, its response is stored, and replayed for the
+// requests repeating the key, e.g. a client retrying a request whose response it did not get
+// because of the network. The keys are scoped to the identity making the request, and kept
+// for a while, in the memory of the server.
+//
+// The store is bounded, in entries per identity, in entries overall, and in the size of the
+// bodies, dropping the oldest completed entries first. Each replica of the server has its own
+// store, i.e. a retry reaching another replica than the first request runs again. Deployments
+// with several replicas need session affinity for the keys to hold.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierrors "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// Headers of the requests, and of the replayed responses.
+const (
+	KeyHeader      = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxKeyLength is the length limit of the keys.
+const maxKeyLength = 255
+
+// Default bounds of the stores.
+const (
+	DefaultMaxEntries            = 10000
+	DefaultMaxEntriesPerIdentity = 1000
+	DefaultMaxBodySize           = 64 * 1024
+)
+
+var (
+	// ErrInProgress is the error of a key whose first request is still running.
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrMismatch is the error of a key used before for a different request.
+	ErrMismatch = errors.New("the idempotency key was used for a different request")
+	// ErrTooMany is the error of a key which finds the store full of requests in progress.
+	ErrTooMany = errors.New("too many requests with idempotency keys in progress")
+	// ErrTooLarge is the error of a key whose first request, or its response, exceeded the
+	// body size limit. Its response is not stored, and not replayed.
+	ErrTooLarge = errors.New("the request with this idempotency key is too large to replay")
+)
+
+// Response is a stored response, replayed for the repeated requests.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store holds the responses of the requests with idempotency keys, for the TTL, within its
+// bounds.
+type Store struct {
+	TTL time.Duration
+
+	// MaxEntries, and MaxEntriesPerIdentity, bound the number of keys. MaxBodySize bounds
+	// the bodies of the requests, and of the stored responses.
+	MaxEntries            int
+	MaxEntriesPerIdentity int
+	MaxBodySize           int
+
+	mu         sync.Mutex
+	entries    map[entryKey]*entry
+	identities map[string]int
+	lastPrune  time.Time
+}
+
+type entryKey struct {
+	identity string
+	key      string
+}
+
+type entry struct {
+	fingerprint string
+	response    *Response
+	tooLarge    bool
+	created     time.Time
+}
+
+// NewStore returns a store keeping the responses for the TTL, with the default bounds.
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		TTL:                   ttl,
+		MaxEntries:            DefaultMaxEntries,
+		MaxEntriesPerIdentity: DefaultMaxEntriesPerIdentity,
+		MaxBodySize:           DefaultMaxBodySize,
+		entries:               map[entryKey]*entry{},
+		identities:            map[string]int{},
+	}
+}
+
+// Begin claims the key of the identity for the request with the fingerprint. It returns the
+// stored response if the key completed for the same request, and nil if the request has to
+// run, followed by Finish, or Abandon. It fails if the first request with the key is still
+// running, was a different request, or had a response too large to store. A full store drops
+// its oldest completed entry, of the identity if it has too many, for the new key, and fails
+// if all are in progress.
+func (s *Store) Begin(identity, key, fingerprint string, now time.Time) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	k := entryKey{identity: identity, key: key}
+	e, ok := s.entries[k]
+	if !ok {
+		if s.identities[identity] >= s.MaxEntriesPerIdentity && !s.evict(identity) {
+			return nil, ErrTooMany
+		}
+		if len(s.entries) >= s.MaxEntries && !s.evict("") {
+			return nil, ErrTooMany
+		}
+		s.entries[k] = &entry{fingerprint: fingerprint, created: now}
+		s.identities[identity]++
+		return nil, nil
+	}
+	if e.fingerprint != fingerprint {
+		return nil, ErrMismatch
+	}
+	if e.tooLarge {
+		return nil, ErrTooLarge
+	}
+	if e.response == nil {
+		return nil, ErrInProgress
+	}
+	return e.response, nil
+}
+
+// Finish stores the response of the request which claimed the key. A response whose body
+// exceeds the limit is not stored, the key only remembers that its request ran.
+func (s *Store) Finish(identity, key string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[entryKey{identity: identity, key: key}]
+	if !ok {
+		return
+	}
+	if len(response.Body) > s.MaxBodySize {
+		e.tooLarge = true
+		return
+	}
+	e.response = &response
+}
+
+// Abandon releases the key of a request which failed without effect, for the repetitions to
+// run again.
+func (s *Store) Abandon(identity, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(entryKey{identity: identity, key: key})
+}
+
+// prune removes the entries older than the TTL.
+func (s *Store) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < s.TTL/10 {
+		return
+	}
+	s.lastPrune = now
+
+	for k, e := range s.entries {
+		if now.Sub(e.created) > s.TTL {
+			s.remove(k)
+		}
+	}
+}
+
+// evict removes the oldest completed entry, of the identity, if not empty. It returns false
+// if there is none.
+func (s *Store) evict(identity string) bool {
+	var oldest *entryKey
+	var created time.Time
+	for k, e := range s.entries {
+		if identity != "" && k.identity != identity {
+			continue
+		}
+		if e.response == nil && !e.tooLarge {
+			continue
+		}
+		if oldest == nil || e.created.Before(created) {
+			k := k
+			oldest, created = &k, e.created
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	s.remove(*oldest)
+	return true
+}
+
+// remove deletes the entry, and counts it off its identity.
+func (s *Store) remove(k entryKey) {
+	if _, ok := s.entries[k]; !ok {
+		return
+	}
+	delete(s.entries, k)
+	s.identities[k.identity]--
+	if s.identities[k.identity] <= 0 {
+		delete(s.identities, k.identity)
+	}
+}
+
+// Middleware runs the requests to the routes, as `METHOD PATH` with the path of the route,
+// at most once per idempotency key, replaying the response of the first request for the
+// repeated ones. Responses with server errors are not stored, their requests can be retried.
+// Requests without key, and to other routes, are passed through. It has to run after the
+// authentication.
+func Middleware(store *Store, routes map[string]struct{}) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(KeyHeader)
+		if key == "" {
+			return
+		}
+		if _, ok := routes[c.Request.Method+" "+c.FullPath()]; !ok {
+			return
+		}
+		if len(key) > maxKeyLength {
+			response.Error(c, apierrors.NewBadRequest("idempotency key too long"))
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		identity := "user:" + requestctx.User(ctx).Username
+		if token := requestctx.APIToken(ctx); token != "" {
+			identity = "token:" + token
+		}
+
+		// The request is identified by its method, URL, and body. The bodies of these
+		// routes are small JSON documents, read again by the handlers.
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(store.MaxBodySize)+1))
+		if err != nil {
+			response.Error(c, apierrors.BadRequest(err, "reading the request"))
+			c.Abort()
+			return
+		}
+		if len(body) > store.MaxBodySize {
+			response.Error(c, apierrors.NewAPIError(ErrTooLarge.Error(), "", http.StatusRequestEntityTooLarge))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.RequestURI()+"\n"), body...))
+
+		stored, err := store.Begin(identity, key, hex.EncodeToString(sum[:]), time.Now())
+		switch {
+		case errors.Is(err, ErrInProgress):
+			c.Header("Retry-After", "1")
+			response.Error(c, apierrors.NewAPIError(err.Error(), "", http.StatusConflict))
+			c.Abort()
+			return
+		case errors.Is(err, ErrMismatch):
+			response.Error(c, apierrors.NewAPIError(err.Error(), "", http.StatusUnprocessableEntity))
+			c.Abort()
+			return
+		case errors.Is(err, ErrTooLarge):
+			response.Error(c, apierrors.NewAPIError(err.Error(), "", http.StatusConflict))
+			c.Abort()
+			return
+		case errors.Is(err, ErrTooMany):
+			c.Header("Retry-After", "1")
+			response.Error(c, apierrors.NewAPIError(err.Error(), "", http.StatusTooManyRequests))
+			c.Abort()
+			return
+		case stored != nil:
+			for name, values := range stored.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(ReplayedHeader, "true")
+			c.Data(stored.Status, stored.Header.Get("Content-Type"), stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer, limit: store.MaxBodySize}
+		c.Writer = recorder
+		defer func() {
+			if r := recover(); r != nil {
+				store.Abandon(identity, key)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if !recorder.Written() || status >= http.StatusInternalServerError {
+			store.Abandon(identity, key)
+			return
+		}
+		store.Finish(identity, key, Response{
+			Status: status,
+			Header: recorder.Header().Clone(),
+			Body:   recorder.body.Bytes(),
+		})
+	}
+}
+
+// recordingWriter keeps a copy of the body of the response it writes, up to a byte beyond the
+// limit, enough to tell that the body exceeds it.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+		w.body.Write(data)
+	}
+}
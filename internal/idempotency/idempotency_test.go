@@ -0,0 +1,221 @@
+package idempotency_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/idempotency"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotency", func() {
+
+	Describe("Store", func() {
+		var store *idempotency.Store
+		var now time.Time
+
+		BeforeEach(func() {
+			store = idempotency.NewStore(time.Hour)
+			now = time.Now()
+		})
+
+		It("runs the first request, and replays its response", func() {
+			stored, err := store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored).To(BeNil())
+
+			_, err = store.Begin("jane", "key", "request", now)
+			Expect(err).To(MatchError(idempotency.ErrInProgress))
+
+			store.Finish("jane", "key", idempotency.Response{Status: http.StatusCreated, Body: []byte("created")})
+
+			stored, err = store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored.Status).To(Equal(http.StatusCreated))
+			Expect(stored.Body).To(Equal([]byte("created")))
+		})
+
+		It("rejects keys reused for other requests", func() {
+			_, err := store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.Begin("jane", "key", "other request", now)
+			Expect(err).To(MatchError(idempotency.ErrMismatch))
+		})
+
+		It("runs the requests of abandoned keys again", func() {
+			_, err := store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			store.Abandon("jane", "key")
+
+			stored, err := store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored).To(BeNil())
+		})
+
+		It("forgets the keys after the TTL", func() {
+			_, err := store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			store.Finish("jane", "key", idempotency.Response{Status: http.StatusOK})
+
+			stored, err := store.Begin("jane", "key", "other request", now.Add(2*time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored).To(BeNil())
+		})
+
+		It("drops the oldest completed keys of identities with too many", func() {
+			store.MaxEntriesPerIdentity = 2
+			for i, key := range []string{"k1", "k2"} {
+				_, err := store.Begin("jane", key, "request", now.Add(time.Duration(i)*time.Second))
+				Expect(err).ToNot(HaveOccurred())
+				store.Finish("jane", key, idempotency.Response{Status: http.StatusOK})
+			}
+
+			_, err := store.Begin("jane", "k3", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+
+			stored, err := store.Begin("jane", "k1", "other request", now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored).To(BeNil())
+
+			stored, err = store.Begin("jane", "k2", "request", now)
+			Expect(err).To(MatchError(idempotency.ErrTooMany))
+			Expect(stored).To(BeNil())
+		})
+
+		It("rejects new keys when all stored ones are in progress", func() {
+			store.MaxEntries = 2
+			_, err := store.Begin("jane", "k1", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = store.Begin("joe", "k1", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.Begin("ann", "k1", "request", now)
+			Expect(err).To(MatchError(idempotency.ErrTooMany))
+
+			store.Finish("jane", "k1", idempotency.Response{Status: http.StatusOK})
+			_, err = store.Begin("ann", "k1", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("does not store large responses, nor replay them", func() {
+			store.MaxBodySize = 4
+			_, err := store.Begin("jane", "key", "request", now)
+			Expect(err).ToNot(HaveOccurred())
+			store.Finish("jane", "key", idempotency.Response{Status: http.StatusOK, Body: []byte("large")})
+
+			_, err = store.Begin("jane", "key", "request", now)
+			Expect(err).To(MatchError(idempotency.ErrTooLarge))
+		})
+	})
+
+	Describe("Middleware", func() {
+		var router *gin.Engine
+		var runs int
+		var status int
+
+		BeforeEach(func() {
+			gin.SetMode(gin.TestMode)
+			runs = 0
+			status = http.StatusCreated
+
+			router = gin.New()
+			router.Use(func(c *gin.Context) {
+				user := auth.User{Username: c.GetHeader("X-User")}
+				c.Request = c.Request.WithContext(requestctx.WithUser(c.Request.Context(), user))
+			})
+			router.Use(idempotency.Middleware(idempotency.NewStore(time.Hour), map[string]struct{}{
+				"POST /apps": {},
+				"POST /echo": {},
+			}))
+			handler := func(c *gin.Context) {
+				runs++
+				c.JSON(status, gin.H{"run": runs})
+			}
+			router.POST("/apps", handler)
+			router.POST("/other", handler)
+			router.POST("/echo", func(c *gin.Context) {
+				var body map[string]string
+				Expect(c.BindJSON(&body)).To(Succeed())
+				c.String(http.StatusOK, fmt.Sprintf("name=%s", body["name"]))
+			})
+		})
+
+		request := func(path, user, key, body string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("POST", path, strings.NewReader(body))
+			r.Header.Set("X-User", user)
+			if key != "" {
+				r.Header.Set(idempotency.KeyHeader, key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			return w
+		}
+
+		It("replays the response of the first request with the key", func() {
+			first := request("/apps", "jane", "k1", `{"name":"app"}`)
+			Expect(first.Code).To(Equal(http.StatusCreated))
+
+			again := request("/apps", "jane", "k1", `{"name":"app"}`)
+			Expect(again.Code).To(Equal(http.StatusCreated))
+			Expect(again.Body.String()).To(Equal(first.Body.String()))
+			Expect(again.Header().Get(idempotency.ReplayedHeader)).To(Equal("true"))
+			Expect(runs).To(Equal(1))
+		})
+
+		It("scopes the keys to the user", func() {
+			request("/apps", "jane", "k1", `{"name":"app"}`)
+			request("/apps", "joe", "k1", `{"name":"app"}`)
+			Expect(runs).To(Equal(2))
+		})
+
+		It("rejects keys reused for other requests", func() {
+			request("/apps", "jane", "k1", `{"name":"app"}`)
+			w := request("/apps", "jane", "k1", `{"name":"other"}`)
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(runs).To(Equal(1))
+		})
+
+		It("runs requests after server errors again", func() {
+			status = http.StatusInternalServerError
+			request("/apps", "jane", "k1", `{"name":"app"}`)
+
+			status = http.StatusCreated
+			w := request("/apps", "jane", "k1", `{"name":"app"}`)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(runs).To(Equal(2))
+		})
+
+		It("ignores requests without key, and to other routes", func() {
+			request("/apps", "jane", "", `{"name":"app"}`)
+			request("/apps", "jane", "", `{"name":"app"}`)
+			request("/other", "jane", "k1", `{"name":"app"}`)
+			request("/other", "jane", "k1", `{"name":"app"}`)
+			Expect(runs).To(Equal(4))
+		})
+
+		It("rejects overlong keys", func() {
+			w := request("/apps", "jane", strings.Repeat("k", 256), `{}`)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(runs).To(Equal(0))
+		})
+
+		It("rejects large requests", func() {
+			w := request("/apps", "jane", "k1", `{"name":"`+strings.Repeat("a", idempotency.DefaultMaxBodySize)+`"}`)
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(runs).To(Equal(0))
+		})
+
+		It("passes the body on to the handler", func() {
+			w := request("/echo", "jane", "k1", `{"name":"app"}`)
+			Expect(w.Body.String()).To(Equal("name=app"))
+		})
+	})
+})
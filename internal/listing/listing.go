@@ -0,0 +1,159 @@
+// Package listing selects pages of the lists returned by the API, filtered by name prefix, and
+// label selector, and sorted. Installations with thousands of apps can so be listed
+// piecewise, without looking up all apps for each request.
+package listing
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// TotalCountHeader is the header of the list responses holding the number of matching items,
+// over all pages.
+const TotalCountHeader = "X-Total-Count"
+
+// Sort orders. A leading "-" reverses them. The default is by namespace, then name. Ties are
+// broken by name, then namespace, making the order deterministic.
+const (
+	SortName    = "name"
+	SortCreated = "created"
+)
+
+// Options select a page of a list. A zero limit is no limit.
+type Options struct {
+	Limit    int
+	Offset   int
+	Prefix   string
+	Selector string
+	Sort     string
+
+	selector labels.Selector
+}
+
+// Item describes an element of a list, for filtering and sorting it.
+type Item struct {
+	Name      string
+	Namespace string
+	CreatedAt time.Time
+	Labels    map[string]string
+}
+
+// FromQuery returns the options of the query parameters `limit`, `offset`, `name` (the name
+// prefix), `labels` (the label selector), and `sort`.
+func FromQuery(query url.Values) (Options, error) {
+	options := Options{
+		Prefix:   query.Get("name"),
+		Selector: query.Get("labels"),
+		Sort:     query.Get("sort"),
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		options.Limit, err = strconv.Atoi(value)
+		if err != nil || options.Limit < 0 {
+			return options, errors.Errorf("bad limit '%s', expected a positive count", value)
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		options.Offset, err = strconv.Atoi(value)
+		if err != nil || options.Offset < 0 {
+			return options, errors.Errorf("bad offset '%s', expected a positive count", value)
+		}
+	}
+
+	switch strings.TrimPrefix(options.Sort, "-") {
+	case "", SortName, SortCreated:
+	default:
+		return options, errors.Errorf("bad sort '%s', expected one of name, created, optionally prefixed by -", options.Sort)
+	}
+
+	options.selector, err = labels.Parse(options.Selector)
+	if err != nil {
+		return options, errors.Wrapf(err, "bad label selector '%s'", options.Selector)
+	}
+
+	return options, nil
+}
+
+// Query returns the query parameters of the options, see FromQuery.
+func (o Options) Query() url.Values {
+	query := url.Values{}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Prefix != "" {
+		query.Set("name", o.Prefix)
+	}
+	if o.Selector != "" {
+		query.Set("labels", o.Selector)
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	return query
+}
+
+// Select filters the items by name prefix, and label selector, sorts them, and returns the
+// indices of the items of the page, and the number of matching items.
+func (o Options) Select(items []Item) ([]int, int, error) {
+	selector := o.selector
+	if selector == nil {
+		var err error
+		selector, err = labels.Parse(o.Selector)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "bad label selector '%s'", o.Selector)
+		}
+	}
+
+	matching := []int{}
+	for i, item := range items {
+		if !strings.HasPrefix(item.Name, o.Prefix) {
+			continue
+		}
+		if !selector.Matches(labels.Set(item.Labels)) {
+			continue
+		}
+		matching = append(matching, i)
+	}
+
+	descending := strings.HasPrefix(o.Sort, "-")
+	key := strings.TrimPrefix(o.Sort, "-")
+	sort.SliceStable(matching, func(i, j int) bool {
+		a, b := items[matching[i]], items[matching[j]]
+		if descending {
+			a, b = b, a
+		}
+		if key == SortCreated && !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if key == "" && a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		// Same names in different namespaces are ordered by namespace, for the order to
+		// not depend on the one of the cluster.
+		return a.Namespace < b.Namespace
+	})
+
+	total := len(matching)
+	if o.Offset >= total {
+		return []int{}, total, nil
+	}
+	matching = matching[o.Offset:]
+	if o.Limit > 0 && o.Limit < len(matching) {
+		matching = matching[:o.Limit]
+	}
+
+	return matching, total, nil
+}
@@ -0,0 +1,96 @@
+package listing_test
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/epinio/epinio/internal/listing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Listing", func() {
+	now := time.Now()
+	items := []listing.Item{
+		{Name: "web", Namespace: "b", CreatedAt: now, Labels: map[string]string{"team": "web"}},
+		{Name: "api", Namespace: "b", CreatedAt: now.Add(-time.Hour), Labels: map[string]string{"team": "core"}},
+		{Name: "web-admin", Namespace: "a", CreatedAt: now.Add(-2 * time.Hour), Labels: map[string]string{"team": "web"}},
+		{Name: "worker", Namespace: "a", CreatedAt: now.Add(time.Hour)},
+	}
+
+	selectNames := func(query string) ([]string, int) {
+		values, err := url.ParseQuery(query)
+		Expect(err).ToNot(HaveOccurred())
+		options, err := listing.FromQuery(values)
+		Expect(err).ToNot(HaveOccurred())
+
+		page, total, err := options.Select(items)
+		Expect(err).ToNot(HaveOccurred())
+
+		names := []string{}
+		for _, i := range page {
+			names = append(names, items[i].Name)
+		}
+		return names, total
+	}
+
+	It("sorts by namespace, then name, by default", func() {
+		names, total := selectNames("")
+		Expect(names).To(Equal([]string{"web-admin", "worker", "api", "web"}))
+		Expect(total).To(Equal(4))
+	})
+
+	It("pages the items", func() {
+		names, total := selectNames("limit=2&offset=1")
+		Expect(names).To(Equal([]string{"worker", "api"}))
+		Expect(total).To(Equal(4))
+
+		names, _ = selectNames("limit=2&offset=4")
+		Expect(names).To(BeEmpty())
+	})
+
+	It("filters by name prefix, and labels", func() {
+		names, total := selectNames("name=web")
+		Expect(names).To(Equal([]string{"web-admin", "web"}))
+		Expect(total).To(Equal(2))
+
+		names, _ = selectNames("labels=team%21%3Dweb")
+		Expect(names).To(Equal([]string{"worker", "api"}))
+	})
+
+	It("sorts by name, and creation, also reversed", func() {
+		names, _ := selectNames("sort=name")
+		Expect(names).To(Equal([]string{"api", "web", "web-admin", "worker"}))
+
+		names, _ = selectNames("sort=-created")
+		Expect(names).To(Equal([]string{"worker", "web", "api", "web-admin"}))
+	})
+
+	It("breaks ties by name, then namespace, whatever the order of the items", func() {
+		tied := []listing.Item{
+			{Name: "web", Namespace: "b", CreatedAt: now},
+			{Name: "api", Namespace: "b", CreatedAt: now},
+			{Name: "web", Namespace: "a", CreatedAt: now},
+		}
+		for _, sort := range []string{"name", "created"} {
+			page, _, err := listing.Options{Sort: sort}.Select(tied)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page).To(Equal([]int{1, 2, 0}))
+		}
+	})
+
+	It("rejects bad parameters", func() {
+		for _, query := range []string{"limit=-1", "offset=x", "sort=size", "labels=a%3D%3D%3Db"} {
+			values, _ := url.ParseQuery(query)
+			_, err := listing.FromQuery(values)
+			Expect(err).To(HaveOccurred(), query)
+		}
+	})
+
+	It("round-trips the options through the query", func() {
+		options := listing.Options{Limit: 10, Offset: 20, Prefix: "web", Selector: "team=web", Sort: "-name"}
+		parsed, err := listing.FromQuery(options.Query())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.Query()).To(Equal(options.Query()))
+	})
+})
@@ -0,0 +1,219 @@
+// Package logsink forwards the logs of the applications of a namespace to the external log
+// sink configured for the namespace, see models.LogSink.
+package logsink
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// forwardInterval is the time between two rounds of log forwarding.
+	forwardInterval = 10 * time.Second
+
+	// excludedContainers is the prefix of the names of the containers whose logs are not
+	// forwarded, i.e. the linkerd sidecars.
+	excludedContainers = "linkerd-"
+)
+
+// Validate checks the log sink settings of a namespace, see models.LogSink.
+func Validate(settings models.LogSink) error {
+	address, err := url.Parse(settings.URL)
+	if err != nil || address.Host == "" {
+		return errors.Errorf("log sink url `%s` is not valid", settings.URL)
+	}
+
+	switch settings.Type {
+	case models.LogSinkLoki, models.LogSinkHTTP:
+		if address.Scheme != "http" && address.Scheme != "https" {
+			return errors.Errorf("%s log sink url `%s` is not an http(s) url", settings.Type, settings.URL)
+		}
+	case models.LogSinkSyslog:
+		if address.Scheme != "udp" && address.Scheme != "tcp" {
+			return errors.Errorf("syslog log sink url `%s` is not an udp or tcp url", settings.URL)
+		}
+	default:
+		return errors.Errorf("log sink type `%s` is not one of loki, syslog, or http", settings.Type)
+	}
+
+	return nil
+}
+
+// forwarder remembers the time of the last entry forwarded per application container, to
+// forward every entry only once.
+type forwarder struct {
+	logger    logr.Logger
+	started   time.Time
+	positions map[string]time.Time
+}
+
+// ForwardLoop periodically forwards the new logs of the applications in the namespaces with
+// a log sink, until the context is done. The logs written before the start of the loop are
+// not forwarded.
+func ForwardLoop(ctx context.Context, logger logr.Logger) {
+	ticker := time.NewTicker(forwardInterval)
+	defer ticker.Stop()
+
+	f := &forwarder{
+		logger:    logger,
+		started:   time.Now(),
+		positions: map[string]time.Time{},
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "log forwarding: no cluster")
+			continue
+		}
+
+		f.forward(ctx, cluster)
+	}
+}
+
+// forward sends the new log entries of all namespaces with a log sink to their sinks.
+func (f *forwarder) forward(ctx context.Context, cluster *kubernetes.Cluster) {
+	spaces, err := namespaces.List(ctx, cluster)
+	if err != nil {
+		f.logger.Error(err, "log forwarding: listing namespaces failed")
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, space := range spaces {
+		if space.Settings.LogSink == nil {
+			continue
+		}
+
+		s, err := newSink(*space.Settings.LogSink)
+		if err != nil {
+			f.logger.Error(err, "log forwarding: bad log sink", "namespace", space.Name)
+			continue
+		}
+
+		if err := f.forwardNamespace(ctx, cluster, space.Name, s, seen); err != nil {
+			f.logger.Error(err, "log forwarding failed", "namespace", space.Name)
+		}
+	}
+
+	// Forget the containers which are gone.
+	for key := range f.positions {
+		if !seen[key] {
+			delete(f.positions, key)
+		}
+	}
+}
+
+// forwardNamespace sends the new log entries of the application instances of the namespace to
+// the sink. The keys of the containers handled are recorded in seen.
+func (f *forwarder) forwardNamespace(ctx context.Context, cluster *kubernetes.Cluster, namespace string, s sink, seen map[string]bool) error {
+	pods, err := cluster.Kubectl.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/component=application,app.kubernetes.io/part-of=" + namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			if strings.HasPrefix(container.Name, excludedContainers) {
+				continue
+			}
+
+			key := namespace + "/" + pod.Name + "/" + container.Name
+			seen[key] = true
+
+			position, ok := f.positions[key]
+			if !ok {
+				position = f.started
+			}
+
+			entries, err := containerEntries(ctx, cluster, pod, container.Name, position)
+			if err != nil {
+				return errors.Wrapf(err, "reading the logs of %s/%s", pod.Name, container.Name)
+			}
+			if len(entries) == 0 {
+				f.positions[key] = position
+				continue
+			}
+
+			if err := s.Send(ctx, entries); err != nil {
+				return err
+			}
+
+			f.positions[key] = entries[len(entries)-1].Time
+		}
+	}
+
+	return nil
+}
+
+// containerEntries returns the log entries of the container of the application instance
+// written after the given time.
+func containerEntries(ctx context.Context, cluster *kubernetes.Cluster, pod v1.Pod, container string, after time.Time) ([]Entry, error) {
+	since := metav1.NewTime(after)
+	stream, err := cluster.Kubectl.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container:  container,
+		Timestamps: true,
+		SinceTime:  &since,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		stamp, message, ok := ParseLine(scanner.Text())
+		// The since time of kubernetes has a resolution of seconds, skip the entries
+		// which were already forwarded.
+		if !ok || !stamp.After(after) {
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Time:      stamp,
+			Namespace: pod.Namespace,
+			App:       pod.Labels["app.kubernetes.io/name"],
+			Instance:  pod.Name,
+			Container: container,
+			Message:   message,
+		})
+	}
+
+	return entries, scanner.Err()
+}
+
+// ParseLine splits a log line read with timestamps into its time and message. The result is
+// false if the line has no timestamp.
+func ParseLine(line string) (time.Time, string, bool) {
+	stamp, message, found := strings.Cut(line, " ")
+	if !found {
+		stamp = line
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return t, strings.TrimRight(message, "\r\n\t "), true
+}
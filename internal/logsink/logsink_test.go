@@ -0,0 +1,71 @@
+package logsink_test
+
+import (
+	"time"
+
+	"github.com/epinio/epinio/internal/logsink"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	It("accepts the supported sinks", func() {
+		Expect(logsink.Validate(models.LogSink{Type: "loki", URL: "http://loki:3100/loki/api/v1/push"})).To(Succeed())
+		Expect(logsink.Validate(models.LogSink{Type: "syslog", URL: "udp://syslog:514"})).To(Succeed())
+		Expect(logsink.Validate(models.LogSink{Type: "http", URL: "https://logs.example.com/ingest"})).To(Succeed())
+	})
+
+	It("rejects unknown types", func() {
+		err := logsink.Validate(models.LogSink{Type: "fluentd", URL: "http://fluentd"})
+		Expect(err).To(MatchError(ContainSubstring("is not one of loki, syslog, or http")))
+	})
+
+	It("rejects urls not matching the type", func() {
+		err := logsink.Validate(models.LogSink{Type: "syslog", URL: "http://syslog:514"})
+		Expect(err).To(MatchError(ContainSubstring("is not an udp or tcp url")))
+
+		err = logsink.Validate(models.LogSink{Type: "loki", URL: "tcp://loki:3100"})
+		Expect(err).To(MatchError(ContainSubstring("is not an http(s) url")))
+	})
+
+	It("rejects urls without host", func() {
+		err := logsink.Validate(models.LogSink{Type: "http", URL: "/ingest"})
+		Expect(err).To(MatchError(ContainSubstring("is not valid")))
+	})
+})
+
+var _ = Describe("ParseLine", func() {
+	It("splits timestamp and message", func() {
+		stamp, message, ok := logsink.ParseLine("2022-06-01T10:00:00.123456789Z hello world\r")
+		Expect(ok).To(BeTrue())
+		Expect(stamp).To(Equal(time.Date(2022, 6, 1, 10, 0, 0, 123456789, time.UTC)))
+		Expect(message).To(Equal("hello world"))
+	})
+
+	It("rejects lines without timestamp", func() {
+		_, _, ok := logsink.ParseLine("hello world")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("SyslogMessage", func() {
+	It("formats the entry as RFC 5424 message", func() {
+		Expect(logsink.SyslogMessage(logsink.Entry{
+			Time:      time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC),
+			Namespace: "workspace",
+			App:       "sample",
+			Instance:  "sample-57d5c7c5d-x2v8k",
+			Container: "sample",
+			Message:   "hello",
+		})).To(Equal("<14>1 2022-06-01T10:00:00Z workspace sample sample-57d5c7c5d-x2v8k sample - hello\n"))
+	})
+
+	It("marks empty fields", func() {
+		Expect(logsink.SyslogMessage(logsink.Entry{
+			Time:    time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC),
+			Message: "hello",
+		})).To(Equal("<14>1 2022-06-01T10:00:00Z - - - - - hello\n"))
+	})
+})
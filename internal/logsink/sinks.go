@@ -0,0 +1,195 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	// sendTimeout is the time a sink is given to accept a batch of entries.
+	sendTimeout = 10 * time.Second
+
+	// syslogPriority is the priority of the syslog messages, facility user, severity info.
+	syslogPriority = 14
+)
+
+// Entry is a single log line of an application instance, as forwarded to a sink.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	App       string    `json:"app"`
+	Instance  string    `json:"instance"`
+	Container string    `json:"container"`
+	Message   string    `json:"message"`
+}
+
+// sink is the interface of the destinations of forwarded log entries.
+type sink interface {
+	Send(ctx context.Context, entries []Entry) error
+}
+
+// newSink returns the sink for the log sink settings of a namespace.
+func newSink(settings models.LogSink) (sink, error) {
+	if err := Validate(settings); err != nil {
+		return nil, err
+	}
+
+	switch settings.Type {
+	case models.LogSinkLoki:
+		return lokiSink{url: settings.URL}, nil
+	case models.LogSinkSyslog:
+		address, _ := url.Parse(settings.URL)
+		return syslogSink{network: address.Scheme, address: address.Host}, nil
+	default:
+		return httpSink{url: settings.URL}, nil
+	}
+}
+
+// lokiSink pushes the entries to a Loki server, as streams labeled with the namespace, app,
+// instance, and container of the entries.
+type lokiSink struct {
+	url string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// Send implements the sink interface
+func (s lokiSink) Send(ctx context.Context, entries []Entry) error {
+	push := lokiPush{Streams: []*lokiStream{}}
+	streams := map[string]*lokiStream{}
+
+	for _, entry := range entries {
+		key := entry.Namespace + "/" + entry.App + "/" + entry.Instance + "/" + entry.Container
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{
+				Stream: map[string]string{
+					"namespace": entry.Namespace,
+					"app":       entry.App,
+					"instance":  entry.Instance,
+					"container": entry.Container,
+				},
+				Values: [][2]string{},
+			}
+			streams[key] = stream
+			push.Streams = append(push.Streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(entry.Time.UnixNano(), 10),
+			entry.Message,
+		})
+	}
+
+	return postJSON(ctx, s.url, push)
+}
+
+// httpSink posts the entries to an endpoint, as a JSON array.
+type httpSink struct {
+	url string
+}
+
+// Send implements the sink interface
+func (s httpSink) Send(ctx context.Context, entries []Entry) error {
+	return postJSON(ctx, s.url, entries)
+}
+
+// syslogSink sends the entries to a syslog server, as RFC 5424 messages.
+type syslogSink struct {
+	network string
+	address string
+}
+
+// Send implements the sink interface
+func (s syslogSink) Send(ctx context.Context, entries []Entry) error {
+	dialer := net.Dialer{Timeout: sendTimeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return errors.Wrap(err, "connecting to the syslog server")
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if _, err := io.WriteString(conn, SyslogMessage(entry)); err != nil {
+			return errors.Wrap(err, "writing to the syslog server")
+		}
+	}
+
+	return nil
+}
+
+// SyslogMessage returns the entry as RFC 5424 message, terminated by a newline. The host is
+// the namespace, the app name the application, the process id the instance, and the message
+// id the container of the entry.
+func SyslogMessage(entry Entry) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s - %s\n",
+		syslogPriority,
+		entry.Time.UTC().Format(time.RFC3339Nano),
+		syslogField(entry.Namespace, 255),
+		syslogField(entry.App, 48),
+		syslogField(entry.Instance, 128),
+		syslogField(entry.Container, 32),
+		entry.Message)
+}
+
+// syslogField returns the value as header field of a syslog message, i.e. `-` for empty
+// values, and cut to the maximum length otherwise.
+func syslogField(value string, max int) string {
+	if value == "" {
+		return "-"
+	}
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+// postJSON posts the value as JSON to the endpoint, and checks that it was accepted.
+func postJSON(ctx context.Context, endpoint string, value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Errorf("log sink %s rejected the entries: %s", endpoint, response.Status)
+	}
+
+	return nil
+}
@@ -4,10 +4,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/epinio/epinio/helpers"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/google/shlex"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -67,17 +69,69 @@ func UpdateBASN(manifest models.ApplicationManifest, cmd *cobra.Command) (models
 	return manifest, nil
 }
 
-// UpdateBuilder updates the incoming manifest with information pulled from the --builder option
+// UpdateBuilder updates the incoming manifest with information pulled from the --builder-image,
+// --buildpack, --dockerfile, --build-cache, and --build-env options. Buildpacks and Dockerfile
+// are exclusive, and each replaces the manifest's choice of the other.
 func UpdateBuilder(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
 	builderImage, err := cmd.Flags().GetString("builder-image")
 	if err != nil {
 		return manifest, errors.Wrap(err, "could not read option --builder-image")
 	}
 
+	dockerfile, err := cmd.Flags().GetString("dockerfile")
+	if err != nil {
+		return manifest, errors.Wrap(err, "could not read option --dockerfile")
+	}
+
+	buildpacks, err := cmd.Flags().GetStringSlice("buildpack")
+	if err != nil {
+		return manifest, errors.Wrap(err, "could not read option --buildpack")
+	}
+
+	cache, err := cmd.Flags().GetString("build-cache")
+	if err != nil {
+		return manifest, errors.Wrap(err, "could not read option --build-cache")
+	}
+
+	buildEnvAssignments, err := cmd.Flags().GetStringSlice("build-env")
+	if err != nil {
+		return manifest, errors.Wrap(err, "could not read option --build-env")
+	}
+
+	buildEnvironment := models.EnvVariableMap{}
+	for _, assignment := range buildEnvAssignments {
+		pieces := strings.SplitN(assignment, "=", 2)
+		if len(pieces) < 2 {
+			return manifest, errors.New("Bad --build-env assignment `" + assignment + "`, expected `name=value` as value")
+		}
+		buildEnvironment[pieces[0]] = pieces[1]
+	}
+
+	if (builderImage != "" || len(buildpacks) > 0) && dockerfile != "" {
+		cmd.SilenceUsage = false
+		return manifest, errors.New("cannot use --dockerfile with --builder-image or --buildpack")
+	}
+
 	// B:uilder - Replace
 
 	if builderImage != "" {
 		manifest.Staging.Builder = builderImage
+		manifest.Staging.Dockerfile = ""
+	}
+	if len(buildpacks) > 0 {
+		manifest.Staging.Buildpacks = buildpacks
+		manifest.Staging.Dockerfile = ""
+	}
+	if dockerfile != "" {
+		manifest.Staging.Dockerfile = dockerfile
+		manifest.Staging.Builder = ""
+		manifest.Staging.Buildpacks = nil
+	}
+	if cache != "" {
+		manifest.Staging.Cache = cache
+	}
+	if len(buildEnvironment) > 0 {
+		manifest.Staging.Environment = buildEnvironment
 	}
 
 	return manifest, nil
@@ -199,7 +253,8 @@ func UpdateName(manifest models.ApplicationManifest, cmd *cobra.Command) (models
 }
 
 // UpdateICE updates the incoming manifest with information pulled from the
-// --bind, --env, and --instances options.
+// --bind, --env, --instances, --process, --bind-volume, --port, --auto-sleep, --command,
+// --args, --chart-value, and --pull-* options.
 // Option information replaces any existing information.
 func UpdateICE(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
 	var err error
@@ -223,6 +278,48 @@ func UpdateICE(manifest models.ApplicationManifest, cmd *cobra.Command) (models.
 		return manifest, err
 	}
 
+	// Processes - Retrieve from options
+	manifest, err = UpdateProcesses(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Volumes - Retrieve from options
+	manifest, err = UpdateVolumes(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Ports - Retrieve from options
+	manifest, err = UpdatePorts(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Auto sleep - Retrieve from options
+	manifest, err = UpdateAutoSleep(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Start command - Retrieve from options
+	manifest, err = UpdateEntrypoint(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Chart values - Retrieve from options
+	manifest, err = UpdateChartValues(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
+	// Pull secret - Retrieve from options
+	manifest, err = UpdatePullSecret(manifest, cmd)
+	if err != nil {
+		return manifest, err
+	}
+
 	return manifest, nil
 }
 
@@ -245,6 +342,62 @@ func UpdateInstances(manifest models.ApplicationManifest, cmd *cobra.Command) (m
 	return manifest, nil
 }
 
+// UpdateAutoSleep updates the incoming manifest with information pulled from the --auto-sleep option
+func UpdateAutoSleep(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	minutes, err := cmd.Flags().GetInt32("auto-sleep")
+	if err != nil {
+		return manifest, errors.Wrap(err, "failed to read option --auto-sleep")
+	}
+
+	if !cmd.Flags().Changed("auto-sleep") {
+		// Not set --> keep the manifest's auto sleep.
+		return manifest, nil
+	}
+	if minutes < 0 {
+		return manifest, errors.New("Bad --auto-sleep, expected a non-negative number of minutes")
+	}
+
+	manifest.Configuration.AutoSleep = &minutes
+
+	return manifest, nil
+}
+
+// UpdateEntrypoint updates the incoming manifest with information pulled from the --command and
+// --args options. Each option replaces its part of the manifest's start command. An empty
+// option restores the image's default for its part.
+func UpdateEntrypoint(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	for _, option := range []string{"command", "args"} {
+		if !cmd.Flags().Changed(option) {
+			// Not set --> keep the manifest's part.
+			continue
+		}
+
+		value, err := cmd.Flags().GetString(option)
+		if err != nil {
+			return manifest, errors.Wrapf(err, "failed to read option --%s", option)
+		}
+
+		words, err := shlex.Split(value)
+		if err != nil {
+			return manifest, errors.Wrapf(err, "Bad --%s", option)
+		}
+		if words == nil {
+			words = []string{}
+		}
+
+		if manifest.Configuration.Entrypoint == nil {
+			manifest.Configuration.Entrypoint = &models.AppEntrypoint{}
+		}
+		if option == "command" {
+			manifest.Configuration.Entrypoint.Command = words
+		} else {
+			manifest.Configuration.Entrypoint.Args = words
+		}
+	}
+
+	return manifest, nil
+}
+
 // UpdateConfigurations updates the incoming manifest with information pulled from the --bind option
 func UpdateConfigurations(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
 	boundConfigurations, err := cmd.Flags().GetStringSlice("bind")
@@ -287,6 +440,233 @@ func UpdateEnvironment(manifest models.ApplicationManifest, cmd *cobra.Command)
 	return manifest, nil
 }
 
+// UpdateProcesses updates the incoming manifest with information pulled from the --process option
+func UpdateProcesses(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	assignments, err := cmd.Flags().GetStringSlice("process")
+	if err != nil {
+		return manifest, errors.Wrap(err, "failed to read option --process")
+	}
+
+	processes := map[string]int32{}
+	for _, assignment := range assignments {
+		pieces := strings.SplitN(assignment, "=", 2)
+		if len(pieces) < 2 {
+			return manifest, errors.New("Bad --process assignment `" + assignment + "`, expected `name=instances` as value")
+		}
+		instances, err := strconv.ParseInt(pieces[1], 10, 32)
+		if err != nil || instances < 0 {
+			return manifest, errors.New("Bad --process assignment `" + assignment + "`, expected a non-negative number of instances")
+		}
+		processes[pieces[0]] = int32(instances)
+	}
+
+	// Processes - Merge
+
+	if len(processes) > 0 {
+		if manifest.Configuration.Processes == nil {
+			manifest.Configuration.Processes = map[string]int32{}
+		}
+		for name, instances := range processes {
+			manifest.Configuration.Processes[name] = instances
+		}
+	}
+
+	return manifest, nil
+}
+
+// UpdateChartValues updates the incoming manifest with information pulled from the --chart-value option
+func UpdateChartValues(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	assignments, err := cmd.Flags().GetStringArray("chart-value")
+	if err != nil {
+		return manifest, errors.Wrap(err, "failed to read option --chart-value")
+	}
+
+	// Chart values - Merge
+
+	for _, assignment := range assignments {
+		pieces := strings.SplitN(assignment, "=", 2)
+		if len(pieces) < 2 || pieces[0] == "" {
+			return manifest, errors.New("Bad --chart-value assignment `" + assignment + "`, expected `name=value` as value")
+		}
+		if manifest.Configuration.ChartValues == nil {
+			manifest.Configuration.ChartValues = map[string]string{}
+		}
+		manifest.Configuration.ChartValues[pieces[0]] = pieces[1]
+	}
+
+	return manifest, nil
+}
+
+// UpdatePullSecret updates the incoming manifest with information pulled from the
+// --pull-registry, --pull-username, and --pull-password options. An empty registry removes
+// the image pull credentials.
+func UpdatePullSecret(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	for _, option := range []string{"pull-registry", "pull-username", "pull-password"} {
+		if !cmd.Flags().Changed(option) {
+			// Not set --> keep the manifest's part.
+			continue
+		}
+
+		value, err := cmd.Flags().GetString(option)
+		if err != nil {
+			return manifest, errors.Wrapf(err, "failed to read option --%s", option)
+		}
+
+		if manifest.Configuration.PullSecret == nil {
+			manifest.Configuration.PullSecret = &models.AppPullSecret{}
+		}
+		switch option {
+		case "pull-registry":
+			manifest.Configuration.PullSecret.Registry = value
+		case "pull-username":
+			manifest.Configuration.PullSecret.Username = value
+		case "pull-password":
+			manifest.Configuration.PullSecret.Password = value
+		}
+	}
+
+	return manifest, nil
+}
+
+// UpdatePorts updates the incoming manifest with information pulled from the --port option
+func UpdatePorts(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	specs, err := cmd.Flags().GetStringSlice("port")
+	if err != nil {
+		return manifest, errors.Wrap(err, "failed to read option --port")
+	}
+
+	if !cmd.Flags().Changed("port") {
+		// Not set --> keep the manifest's ports.
+		return manifest, nil
+	}
+
+	// Ports - Replace. An empty option removes all ports.
+
+	ports := []models.AppPort{}
+	for _, spec := range specs {
+		port, err := ParsePort(spec)
+		if err != nil {
+			return manifest, err
+		}
+		ports = append(ports, port)
+	}
+
+	manifest.Configuration.Ports = ports
+
+	return manifest, nil
+}
+
+// ParsePort returns the port of a `port[/protocol][:expose[:nodeport]]` specification, e.g.
+// `5432`, `53/udp`, `6379:loadbalancer`, or `9000/tcp:nodeport:30900`.
+func ParsePort(spec string) (models.AppPort, error) {
+	bad := func(expected string) (models.AppPort, error) {
+		return models.AppPort{}, errors.New("Bad --port `" + spec + "`, expected " + expected)
+	}
+
+	pieces := strings.Split(spec, ":")
+	if len(pieces) > 3 {
+		return bad("`port[/protocol][:expose[:nodeport]]` as value")
+	}
+
+	portProtocol := strings.SplitN(pieces[0], "/", 2)
+	number, err := strconv.ParseInt(portProtocol[0], 10, 32)
+	if err != nil {
+		return bad("a port number")
+	}
+
+	port := models.AppPort{Port: int32(number)}
+	if len(portProtocol) == 2 {
+		port.Protocol = strings.ToUpper(portProtocol[1])
+	}
+	if len(pieces) > 1 {
+		port.Expose = pieces[1]
+	}
+	if len(pieces) > 2 {
+		nodePort, err := strconv.ParseInt(pieces[2], 10, 32)
+		if err != nil {
+			return bad("a node port number")
+		}
+		port.NodePort = int32(nodePort)
+	}
+
+	return port, nil
+}
+
+// UpdateVolumes updates the incoming manifest with information pulled from the --bind-volume option
+func UpdateVolumes(manifest models.ApplicationManifest, cmd *cobra.Command) (models.ApplicationManifest, error) {
+	specs, err := cmd.Flags().GetStringSlice("bind-volume")
+	if err != nil {
+		return manifest, errors.Wrap(err, "failed to read option --bind-volume")
+	}
+
+	// Volumes - Merge, by name
+
+	for _, spec := range specs {
+		pieces := strings.Split(spec, ":")
+		if len(pieces) < 2 || len(pieces) > 3 {
+			return manifest, errors.New("Bad --bind-volume `" + spec + "`, expected `name:/path[:size]` as value")
+		}
+
+		volume := models.AppVolume{
+			Name: pieces[0],
+			Path: pieces[1],
+		}
+		if len(pieces) == 3 {
+			volume.Size = pieces[2]
+		}
+
+		replaced := false
+		for i, known := range manifest.Configuration.Volumes {
+			if known.Name == volume.Name {
+				// Keep the access mode coming from the manifest file.
+				volume.AccessMode = known.AccessMode
+				manifest.Configuration.Volumes[i] = volume
+				replaced = true
+			}
+		}
+		if !replaced {
+			manifest.Configuration.Volumes = append(manifest.Configuration.Volumes, volume)
+		}
+	}
+
+	return manifest, nil
+}
+
+// ProcessTypes returns the names of the process types declared by the Procfile in the
+// specified directory, except for `web`. A missing Procfile declares nothing.
+func ProcessTypes(dir string) ([]string, error) {
+	procfile := filepath.Join(dir, "Procfile")
+
+	exists, err := fileExists(procfile)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(procfile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read Procfile '%s'", procfile)
+	}
+
+	result := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pieces := strings.SplitN(line, ":", 2)
+		if len(pieces) < 2 {
+			return nil, errors.Errorf("bad Procfile line `%s`, expected `name: command`", line)
+		}
+		name := strings.TrimSpace(pieces[0])
+		if name == models.ProcessWeb {
+			continue
+		}
+		result = append(result, name)
+	}
+
+	return helpers.UniqueStrings(result), nil
+}
+
 // Get reads the manifest at the spcified path into
 // memory. Note that a missing file is not an error. It simply maps to
 // an empty manifest.
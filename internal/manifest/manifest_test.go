@@ -142,4 +142,78 @@ configuration:
 			})
 		})
 	})
+
+	Describe("ProcessTypes", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "procfile")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		When("there is no Procfile", func() {
+			It("returns nothing", func() {
+				processTypes, err := manifest.ProcessTypes(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(processTypes).To(BeEmpty())
+			})
+		})
+
+		When("there is a Procfile", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(path.Join(dir, "Procfile"), []byte(`# processes
+web: bundle exec rails server
+worker: bundle exec sidekiq
+
+scheduler: bundle exec clockwork clock.rb
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the process types except web", func() {
+				processTypes, err := manifest.ProcessTypes(dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(processTypes).To(Equal([]string{"worker", "scheduler"}))
+			})
+		})
+
+		When("the Procfile is malformed", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(path.Join(dir, "Procfile"), []byte("worker\n"), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("fails with an error", func() {
+				_, err := manifest.ProcessTypes(dir)
+				Expect(err).To(MatchError(ContainSubstring("bad Procfile line `worker`")))
+			})
+		})
+	})
+
+	Describe("ParsePort", func() {
+		It("parses the port, protocol, exposure, and node port", func() {
+			Expect(manifest.ParsePort("5432")).To(Equal(models.AppPort{Port: 5432}))
+			Expect(manifest.ParsePort("53/udp")).To(Equal(models.AppPort{Port: 53, Protocol: "UDP"}))
+			Expect(manifest.ParsePort("6379:loadbalancer")).To(Equal(models.AppPort{Port: 6379, Expose: "loadbalancer"}))
+			Expect(manifest.ParsePort("9000/tcp:nodeport:30900")).To(Equal(models.AppPort{
+				Port: 9000, Protocol: "TCP", Expose: "nodeport", NodePort: 30900,
+			}))
+		})
+
+		It("rejects bad specifications", func() {
+			_, err := manifest.ParsePort("db")
+			Expect(err).To(MatchError(ContainSubstring("expected a port number")))
+
+			_, err = manifest.ParsePort("9000:nodeport:high")
+			Expect(err).To(MatchError(ContainSubstring("expected a node port number")))
+
+			_, err = manifest.ParsePort("9000:nodeport:30900:x")
+			Expect(err).To(MatchError(ContainSubstring("port[/protocol]")))
+		})
+	})
 })
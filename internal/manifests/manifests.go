@@ -0,0 +1,148 @@
+// Package manifests exports the Kubernetes resources of a namespace as plain manifests, e.g.
+// for audits, migrations, and disaster-recovery rehearsals. The manifests are stripped of the
+// fields set by the cluster, like the status, uids, and resource versions, so that they can
+// be applied to another cluster. The data of the secrets is left out, unless asked for.
+package manifests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// Resources are the kinds of resources exported, in the order of the export, which is an
+// order they can be applied in. The resources of the services, deployed by helm, are part of
+// these.
+var Resources = []schema.GroupVersionResource{
+	{Group: "", Version: "v1", Resource: "configmaps"},
+	{Group: "", Version: "v1", Resource: "secrets"},
+	{Group: "", Version: "v1", Resource: "serviceaccounts"},
+	{Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+	{Group: "application.epinio.io", Version: "v1", Resource: "apps"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+	{Group: "", Version: "v1", Resource: "services"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+}
+
+// namespaceResource is the resource of the namespace itself, exported first.
+var namespaceResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
+// skippedSecretTypes are the types of secrets managed by the cluster, and helm, which are not
+// exported.
+var skippedSecretTypes = map[string]struct{}{
+	"kubernetes.io/service-account-token": {},
+	"helm.sh/release.v1":                  {},
+}
+
+// skippedNames are resources created by the cluster in all namespaces, by resource.
+var skippedNames = map[string]map[string]struct{}{
+	"configmaps":      {"kube-root-ca.crt": {}},
+	"serviceaccounts": {"default": {}},
+}
+
+// Export returns the manifests of the namespace, and of its resources, as a YAML stream. With
+// secrets the data of the secrets is included, else only their keys, with empty values.
+func Export(ctx context.Context, client dynamic.Interface, namespace string, secrets bool) ([]byte, error) {
+	space, err := client.Resource(namespaceResource).Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting namespace %s", namespace)
+	}
+	objects := []unstructured.Unstructured{*space}
+
+	for _, resource := range Resources {
+		list, err := client.Resource(resource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the %s of namespace %s", resource.Resource, namespace)
+		}
+
+		items := list.Items
+		sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+
+		for _, item := range items {
+			if skipped(resource, item) {
+				continue
+			}
+			objects = append(objects, item)
+		}
+	}
+
+	var out bytes.Buffer
+	for i := range objects {
+		object := &objects[i]
+		Sanitize(object, secrets)
+
+		data, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding %s %s", object.GetKind(), object.GetName())
+		}
+		fmt.Fprintf(&out, "---\n%s", data)
+	}
+
+	return out.Bytes(), nil
+}
+
+// Sanitize removes the fields set by the cluster from the object, and, without secrets, the
+// values of the data of a secret.
+func Sanitize(object *unstructured.Unstructured, secrets bool) {
+	unstructured.RemoveNestedField(object.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences"} {
+		unstructured.RemoveNestedField(object.Object, "metadata", field)
+	}
+
+	annotations := object.GetAnnotations()
+	if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		object.SetAnnotations(annotations)
+	}
+
+	switch object.GetKind() {
+	case "Namespace":
+		unstructured.RemoveNestedField(object.Object, "spec", "finalizers")
+	case "Service":
+		// The addresses are assigned by the cluster.
+		unstructured.RemoveNestedField(object.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(object.Object, "spec", "clusterIPs")
+	case "PersistentVolumeClaim":
+		// The volume is the one bound in this cluster.
+		unstructured.RemoveNestedField(object.Object, "spec", "volumeName")
+	case "Secret":
+		if secrets {
+			break
+		}
+		for _, field := range []string{"data", "stringData"} {
+			data, found, err := unstructured.NestedMap(object.Object, field)
+			if err != nil || !found {
+				continue
+			}
+			for key := range data {
+				data[key] = ""
+			}
+			_ = unstructured.SetNestedMap(object.Object, data, field)
+		}
+	}
+}
+
+// skipped returns true for the resources managed by the cluster, and helm, which are not
+// exported.
+func skipped(resource schema.GroupVersionResource, object unstructured.Unstructured) bool {
+	if _, ok := skippedNames[resource.Resource][object.GetName()]; ok {
+		return true
+	}
+	if resource.Resource == "secrets" {
+		secretType, _, _ := unstructured.NestedString(object.Object, "type")
+		if _, ok := skippedSecretTypes[secretType]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package manifests_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/epinio/epinio/internal/manifests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// object returns the unstructured object of the kind, with the fields.
+func object(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":            name,
+			"uid":             "0123",
+			"resourceVersion": "42",
+			"managedFields":   []interface{}{map[string]interface{}{"manager": "helm"}},
+		},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	for key, value := range fields {
+		obj.Object[key] = value
+	}
+	return obj
+}
+
+var _ = Describe("Export", func() {
+	var client *dynamicfake.FakeDynamicClient
+
+	BeforeEach(func() {
+		listKinds := map[schema.GroupVersionResource]string{}
+		for _, resource := range manifests.Resources {
+			listKinds[resource] = "List"
+		}
+
+		client = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+			object("v1", "Namespace", "", "workspace", map[string]interface{}{
+				"status": map[string]interface{}{"phase": "Active"},
+			}),
+			object("v1", "Secret", "workspace", "db", map[string]interface{}{
+				"type": "Opaque",
+				"data": map[string]interface{}{"password": "c2VjcmV0"},
+			}),
+			object("v1", "Secret", "workspace", "sh.helm.release.v1.db.v1", map[string]interface{}{
+				"type": "helm.sh/release.v1",
+			}),
+			object("v1", "ConfigMap", "workspace", "kube-root-ca.crt", nil),
+			object("v1", "Service", "workspace", "web", map[string]interface{}{
+				"spec": map[string]interface{}{"clusterIP": "10.0.0.1", "ports": []interface{}{map[string]interface{}{"port": int64(8080)}}},
+			}),
+			object("apps/v1", "Deployment", "workspace", "web", map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"readyReplicas": int64(2)},
+			}),
+			object("apps/v1", "Deployment", "other", "api", nil),
+		)
+	})
+
+	// documents returns the objects of the exported stream.
+	documents := func(secrets bool) []map[string]interface{} {
+		stream, err := manifests.Export(context.Background(), client, "workspace", secrets)
+		Expect(err).ToNot(HaveOccurred())
+
+		result := []map[string]interface{}{}
+		for _, document := range strings.Split(string(stream), "---\n") {
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+			obj := map[string]interface{}{}
+			Expect(yaml.Unmarshal([]byte(document), &obj)).To(Succeed())
+			result = append(result, obj)
+		}
+		return result
+	}
+
+	It("exports the namespace, and its resources, in the order to apply them", func() {
+		kinds := []string{}
+		for _, document := range documents(false) {
+			kinds = append(kinds, document["kind"].(string)+"/"+document["metadata"].(map[string]interface{})["name"].(string))
+		}
+		Expect(kinds).To(Equal([]string{"Namespace/workspace", "Secret/db", "Deployment/web", "Service/web"}))
+	})
+
+	It("strips the fields set by the cluster", func() {
+		for _, document := range documents(false) {
+			Expect(document).ToNot(HaveKey("status"))
+			metadata := document["metadata"].(map[string]interface{})
+			Expect(metadata).ToNot(HaveKey("uid"))
+			Expect(metadata).ToNot(HaveKey("resourceVersion"))
+			Expect(metadata).ToNot(HaveKey("managedFields"))
+
+			if document["kind"] == "Service" {
+				spec := document["spec"].(map[string]interface{})
+				Expect(spec).ToNot(HaveKey("clusterIP"))
+				Expect(spec).To(HaveKey("ports"))
+			}
+		}
+	})
+
+	It("leaves out the data of the secrets, unless asked for", func() {
+		secret := documents(false)[1]
+		Expect(secret["data"]).To(Equal(map[string]interface{}{"password": ""}))
+
+		secret = documents(true)[1]
+		Expect(secret["data"]).To(Equal(map[string]interface{}{"password": "c2VjcmV0"}))
+	})
+})
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+// DashboardLabel is the label of the config maps the dashboard sidecar of Grafana, as deployed
+// by the kube-prometheus-stack, loads the dashboards from.
+const DashboardLabel = "grafana_dashboard"
+
+// query is a Prometheus query of a panel, with the legend of its series.
+type query struct {
+	expr   string
+	legend string
+}
+
+// panel is a time series panel of a dashboard, in the unit of Grafana.
+type panel struct {
+	title   string
+	unit    string
+	queries []query
+}
+
+// dashboard is a Grafana dashboard. The variables are queried from the label values of the
+// metrics, after the choice of the data source.
+type dashboard struct {
+	uid       string
+	title     string
+	variables []variable
+	panels    []panel
+}
+
+// variable is a template variable of a dashboard, with the query of its values.
+type variable struct {
+	name  string
+	query string
+	all   bool
+}
+
+// dashboards are the dashboards of the server, of the stagings, and of the resource usage of
+// the applications. The latter uses the cadvisor metrics of the kubelets, and the restarts of
+// kube-state-metrics, as scraped by the kube-prometheus-stack. The main container of the
+// instances of an application is named after it.
+var dashboards = []dashboard{
+	{
+		uid:   "epinio-server",
+		title: "Epinio / Server",
+		panels: []panel{
+			{title: "Requests", unit: "reqps", queries: []query{
+				{`sum by (route) (rate(epinio_http_requests_total[5m]))`, "{{route}}"},
+			}},
+			{title: "Server errors", unit: "reqps", queries: []query{
+				{`sum by (route, code) (rate(epinio_http_requests_total{code=~"5.."}[5m]))`, "{{route}} {{code}}"},
+			}},
+			{title: "Request duration, 95th percentile", unit: "s", queries: []query{
+				{`histogram_quantile(0.95, sum by (le, route) (rate(epinio_http_request_duration_seconds_bucket[5m])))`, "{{route}}"},
+			}},
+			{title: "Authentication failures", unit: "reqps", queries: []query{
+				{`sum by (route) (rate(epinio_auth_failures_total[5m]))`, "{{route}}"},
+			}},
+			{title: "Kube client requests", unit: "reqps", queries: []query{
+				{`sum by (method, code) (rate(epinio_kube_client_requests_total[5m]))`, "{{method}} {{code}}"},
+			}},
+			{title: "Kube client duration, 95th percentile", unit: "s", queries: []query{
+				{`histogram_quantile(0.95, sum by (le, verb) (rate(epinio_kube_client_request_duration_seconds_bucket[5m])))`, "{{verb}}"},
+			}},
+		},
+	},
+	{
+		uid:   "epinio-staging",
+		title: "Epinio / Staging",
+		panels: []panel{
+			{title: "Staging duration", unit: "s", queries: []query{
+				{`histogram_quantile(0.5, sum by (le) (rate(epinio_staging_duration_seconds_bucket[1h])))`, "median"},
+				{`histogram_quantile(0.95, sum by (le) (rate(epinio_staging_duration_seconds_bucket[1h])))`, "95th percentile"},
+			}},
+			{title: "Stagings per hour", unit: "short", queries: []query{
+				{`sum by (result) (increase(epinio_staging_duration_seconds_count[1h]))`, "{{result}}"},
+			}},
+			{title: "Staging jobs", unit: "short", queries: []query{
+				{`sum(epinio_staging_running)`, "running"},
+				{`sum(epinio_staging_queue_depth)`, "queued"},
+			}},
+		},
+	},
+	{
+		uid:   "epinio-applications",
+		title: "Epinio / Applications",
+		variables: []variable{
+			{name: "namespace", query: `label_values(container_cpu_usage_seconds_total, namespace)`},
+			{name: "app", query: `label_values(container_cpu_usage_seconds_total{namespace="$namespace",container!="",container!="POD"}, container)`, all: true},
+		},
+		panels: []panel{
+			{title: "CPU usage", unit: "cores", queries: []query{
+				{`sum by (container) (rate(container_cpu_usage_seconds_total{namespace="$namespace",container=~"$app",container!="",container!="POD"}[5m]))`, "{{container}}"},
+			}},
+			{title: "Memory usage", unit: "bytes", queries: []query{
+				{`sum by (container) (container_memory_working_set_bytes{namespace="$namespace",container=~"$app",container!="",container!="POD"})`, "{{container}}"},
+			}},
+			{title: "Instances", unit: "short", queries: []query{
+				{`count by (container) (container_memory_working_set_bytes{namespace="$namespace",container=~"$app",container!="",container!="POD"})`, "{{container}}"},
+			}},
+			{title: "Restarts", unit: "short", queries: []query{
+				{`sum by (container) (increase(kube_pod_container_status_restarts_total{namespace="$namespace",container=~"$app",container!="",container!="POD"}[1h]))`, "{{container}}"},
+			}},
+		},
+	},
+}
+
+// encode returns the dashboard in the JSON model of Grafana. The panels are laid out two per row.
+func (d dashboard) encode() ([]byte, error) {
+	datasource := map[string]interface{}{"type": "prometheus", "uid": "${datasource}"}
+
+	variables := []interface{}{
+		map[string]interface{}{
+			"name":  "datasource",
+			"label": "Data source",
+			"type":  "datasource",
+			"query": "prometheus",
+		},
+	}
+	for _, v := range d.variables {
+		variables = append(variables, map[string]interface{}{
+			"name":       v.name,
+			"type":       "query",
+			"datasource": datasource,
+			"query":      v.query,
+			"refresh":    2,
+			"sort":       1,
+			"multi":      v.all,
+			"includeAll": v.all,
+		})
+	}
+
+	panels := []interface{}{}
+	for i, p := range d.panels {
+		targets := []interface{}{}
+		for j, q := range p.queries {
+			targets = append(targets, map[string]interface{}{
+				"refId":        string(rune('A' + j)),
+				"datasource":   datasource,
+				"expr":         q.expr,
+				"legendFormat": q.legend,
+			})
+		}
+		panels = append(panels, map[string]interface{}{
+			"id":         i + 1,
+			"type":       "timeseries",
+			"title":      p.title,
+			"datasource": datasource,
+			"gridPos":    map[string]interface{}{"h": 8, "w": 12, "x": 12 * (i % 2), "y": 8 * (i / 2)},
+			"fieldConfig": map[string]interface{}{
+				"defaults": map[string]interface{}{"unit": p.unit},
+			},
+			"targets": targets,
+		})
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"uid":           d.uid,
+		"title":         d.title,
+		"tags":          []string{"epinio"},
+		"editable":      false,
+		"schemaVersion": 36,
+		"refresh":       "30s",
+		"time":          map[string]interface{}{"from": "now-6h", "to": "now"},
+		"templating":    map[string]interface{}{"list": variables},
+		"panels":        panels,
+	}, "", "  ")
+}
+
+// DashboardConfigMaps returns the config maps holding the dashboards, one each, for the
+// dashboard sidecar of Grafana, in the namespace.
+func DashboardConfigMaps(namespace string) ([]*corev1.ConfigMap, error) {
+	result := []*corev1.ConfigMap{}
+	for _, d := range dashboards {
+		data, err := d.encode()
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding dashboard %s", d.uid)
+		}
+
+		result = append(result, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      d.uid + "-dashboard",
+				Namespace: namespace,
+				Labels: map[string]string{
+					DashboardLabel:                 "1",
+					"app.kubernetes.io/managed-by": "epinio",
+				},
+			},
+			Data: map[string]string{d.uid + ".json": string(data)},
+		})
+	}
+	return result, nil
+}
+
+// RegisterDashboards creates, or updates, the config maps of the dashboards in the namespace,
+// for the dashboard sidecar of Grafana to load them.
+func RegisterDashboards(ctx context.Context, kube kubeclient.Interface, namespace string) error {
+	wanted, err := DashboardConfigMaps(namespace)
+	if err != nil {
+		return err
+	}
+	configMaps := kube.CoreV1().ConfigMaps(namespace)
+
+	for _, configMap := range wanted {
+		existing, err := configMaps.Get(ctx, configMap.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+				return errors.Wrapf(err, "creating the dashboard config map %s", configMap.Name)
+			}
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "getting the dashboard config map %s", configMap.Name)
+		}
+
+		existing.Labels = configMap.Labels
+		existing.Data = configMap.Data
+		if _, err := configMaps.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "updating the dashboard config map %s", configMap.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/internal/metrics"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Dashboards", func() {
+	It("holds one dashboard per config map, labeled for the Grafana sidecar", func() {
+		configMaps, err := metrics.DashboardConfigMaps("monitoring")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configMaps).To(HaveLen(3))
+
+		uids := []string{}
+		for _, configMap := range configMaps {
+			Expect(configMap.Namespace).To(Equal("monitoring"))
+			Expect(configMap.Labels).To(HaveKeyWithValue(metrics.DashboardLabel, "1"))
+			Expect(configMap.Data).To(HaveLen(1))
+
+			for _, data := range configMap.Data {
+				var model struct {
+					UID    string `json:"uid"`
+					Panels []struct {
+						Targets []struct {
+							Expr string `json:"expr"`
+						} `json:"targets"`
+					} `json:"panels"`
+				}
+				Expect(json.Unmarshal([]byte(data), &model)).To(Succeed())
+				Expect(model.Panels).ToNot(BeEmpty())
+				for _, panel := range model.Panels {
+					Expect(panel.Targets).ToNot(BeEmpty())
+				}
+				uids = append(uids, model.UID)
+			}
+		}
+		Expect(uids).To(ConsistOf("epinio-server", "epinio-staging", "epinio-applications"))
+	})
+
+	It("creates the config maps, and updates existing ones", func() {
+		existing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "epinio-server-dashboard", Namespace: "epinio"},
+			Data:       map[string]string{"old.json": "{}"},
+		}
+		kube := kubefake.NewSimpleClientset(existing)
+
+		err := metrics.RegisterDashboards(context.Background(), kube, "epinio")
+		Expect(err).ToNot(HaveOccurred())
+
+		list, err := kube.CoreV1().ConfigMaps("epinio").List(context.Background(), metav1.ListOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Items).To(HaveLen(3))
+
+		server, err := kube.CoreV1().ConfigMaps("epinio").Get(context.Background(), "epinio-server-dashboard", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.Labels).To(HaveKeyWithValue(metrics.DashboardLabel, "1"))
+		Expect(server.Data).To(HaveKey("epinio-server.json"))
+		Expect(server.Data).ToNot(HaveKey("old.json"))
+	})
+})
@@ -0,0 +1,150 @@
+// Package metrics collects the metrics of the server, for Prometheus: the requests of the API
+// by endpoint, the failed authentications, the stagings and their queue, and the requests of
+// the kube client. They are served by Handler, on the metrics port. RegisterDashboards ships
+// Grafana dashboards for them, and for the resource usage of the applications.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+const namespace = "epinio"
+
+// Registry holds the metrics of the server, and those of the Go runtime and process.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requests of the API, by method, route, and status code.",
+	}, []string{"method", "route", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to handle the requests of the API, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	authFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Requests refused as unauthorized, by route.",
+	}, []string{"route"})
+
+	stagingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "staging_duration_seconds",
+		Help:      "Time from the start to the end of the staging jobs, by result.",
+		Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"result"})
+
+	stagingQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "staging_queue_depth",
+		Help:      "Staging jobs waiting in the queue for a free slot.",
+	})
+
+	stagingRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "staging_running",
+		Help:      "Staging jobs running.",
+	})
+
+	kubeRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kube_client_request_duration_seconds",
+		Help:      "Time of the requests of the kube client, by verb and host.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"verb", "host"})
+
+	kubeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kube_client_requests_total",
+		Help:      "Requests of the kube client, by status code, method, and host.",
+	}, []string{"code", "method", "host"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests,
+		requestDuration,
+		authFailures,
+		stagingDuration,
+		stagingQueued,
+		stagingRunning,
+		kubeRequestDuration,
+		kubeRequests,
+	)
+}
+
+// Handler serves the metrics, in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts the requests, and their time, by route. The route is the one of gin,
+// e.g. `/api/v1/namespaces/:namespace`, keeping the number of series bounded. Requests
+// matching no route are counted as `unmatched`.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+
+		requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(status)).Inc()
+		requestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+		if status == http.StatusUnauthorized {
+			authFailures.WithLabelValues(route).Inc()
+		}
+	}
+}
+
+// ObserveStaging records the time a staging job took, by its result, e.g. succeeded.
+func ObserveStaging(result string, duration time.Duration) {
+	stagingDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// SetStaging records the number of queued, and of running, staging jobs.
+func SetStaging(queued, running int) {
+	stagingQueued.Set(float64(queued))
+	stagingRunning.Set(float64(running))
+}
+
+// RegisterClientMetrics makes the kube client record its requests.
+func RegisterClientMetrics() {
+	clientmetrics.Register(clientmetrics.RegisterOpts{
+		RequestLatency: kubeLatency{},
+		RequestResult:  kubeResult{},
+	})
+}
+
+type kubeLatency struct{}
+
+func (kubeLatency) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	kubeRequestDuration.WithLabelValues(verb, u.Host).Observe(latency.Seconds())
+}
+
+type kubeResult struct{}
+
+func (kubeResult) Increment(_ context.Context, code, method, host string) {
+	kubeRequests.WithLabelValues(code, method, host).Inc()
+}
@@ -0,0 +1,124 @@
+package metrics_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/epinio/epinio/internal/metrics"
+	"github.com/gin-gonic/gin"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// scrape returns the metrics as served.
+func scrape() string {
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(recorder.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return string(body)
+}
+
+var _ = Describe("Metrics", func() {
+	It("counts the requests by route, and the auth failures", func() {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(metrics.Middleware())
+		router.GET("/api/v1/namespaces/:namespace", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.GET("/api/v1/secret", func(c *gin.Context) {
+			c.Status(http.StatusUnauthorized)
+		})
+
+		for _, path := range []string{"/api/v1/namespaces/one", "/api/v1/namespaces/two", "/api/v1/secret", "/nowhere"} {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`epinio_http_requests_total{code="200",method="GET",route="/api/v1/namespaces/:namespace"} 2`))
+		Expect(body).To(ContainSubstring(`epinio_http_requests_total{code="404",method="GET",route="unmatched"} 1`))
+		Expect(body).To(ContainSubstring(`epinio_http_request_duration_seconds_count{method="GET",route="/api/v1/secret"} 1`))
+		Expect(body).To(ContainSubstring(`epinio_auth_failures_total{route="/api/v1/secret"} 1`))
+	})
+
+	It("records the stagings", func() {
+		metrics.ObserveStaging("succeeded", 42*time.Second)
+		metrics.SetStaging(3, 2)
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`epinio_staging_duration_seconds_sum{result="succeeded"} 42`))
+		Expect(body).To(ContainSubstring("epinio_staging_queue_depth 3"))
+		Expect(body).To(ContainSubstring("epinio_staging_running 2"))
+	})
+
+	It("serves the metrics of the runtime", func() {
+		Expect(scrape()).To(ContainSubstring("go_goroutines"))
+	})
+})
+
+var _ = Describe("RegisterServiceMonitor", func() {
+	var service *corev1.Service
+
+	BeforeEach(func() {
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "epinio-server",
+				Namespace: "epinio",
+				Labels:    map[string]string{"app.kubernetes.io/name": "epinio-server"},
+			},
+		}
+	})
+
+	newDynamic := func(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+		return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{metrics.ServiceMonitorResource: "ServiceMonitorList"}, objects...)
+	}
+
+	get := func(client *dynamicfake.FakeDynamicClient) *unstructured.Unstructured {
+		monitor, err := client.Resource(metrics.ServiceMonitorResource).Namespace("epinio").
+			Get(context.Background(), metrics.ServiceMonitorName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		return monitor
+	}
+
+	It("creates the service monitor, selecting the service by its labels", func() {
+		client := newDynamic()
+		err := metrics.RegisterServiceMonitor(context.Background(), kubefake.NewSimpleClientset(service), client, "epinio", "epinio-server")
+		Expect(err).ToNot(HaveOccurred())
+
+		monitor := get(client)
+		labels, _, _ := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+		Expect(labels).To(Equal(service.Labels))
+		endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+		Expect(endpoints).To(ConsistOf(HaveKeyWithValue("port", metrics.PortName)))
+	})
+
+	It("updates an existing service monitor", func() {
+		existing := metrics.ServiceMonitor("epinio", map[string]string{"old": "label"})
+		client := newDynamic(existing)
+
+		err := metrics.RegisterServiceMonitor(context.Background(), kubefake.NewSimpleClientset(service), client, "epinio", "epinio-server")
+		Expect(err).ToNot(HaveOccurred())
+
+		labels, _, _ := unstructured.NestedStringMap(get(client).Object, "spec", "selector", "matchLabels")
+		Expect(labels).To(Equal(service.Labels))
+	})
+
+	It("fails for a service without labels", func() {
+		service.Labels = nil
+		err := metrics.RegisterServiceMonitor(context.Background(), kubefake.NewSimpleClientset(service), newDynamic(), "epinio", "epinio-server")
+		Expect(err).To(MatchError(ContainSubstring("no labels")))
+	})
+})
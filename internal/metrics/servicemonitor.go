@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+// ServiceMonitorName is the name of the service monitor of the server.
+const ServiceMonitorName = "epinio-server"
+
+// PortName is the name the port of the service has to give the metrics port, for the
+// service monitor.
+const PortName = "metrics"
+
+// ServiceMonitorResource is the resource of the service monitors of the Prometheus operator.
+var ServiceMonitorResource = schema.GroupVersionResource{
+	Group:    "monitoring.coreos.com",
+	Version:  "v1",
+	Resource: "servicemonitors",
+}
+
+// ServiceMonitor returns the service monitor making Prometheus scrape the metrics port of the
+// services with the labels, in the namespace.
+func ServiceMonitor(namespace string, labels map[string]string) *unstructured.Unstructured {
+	matchLabels := map[string]interface{}{}
+	for key, value := range labels {
+		matchLabels[key] = value
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": ServiceMonitorResource.GroupVersion().String(),
+		"kind":       "ServiceMonitor",
+		"metadata": map[string]interface{}{
+			"name":      ServiceMonitorName,
+			"namespace": namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "epinio",
+			},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": matchLabels,
+			},
+			"namespaceSelector": map[string]interface{}{
+				"matchNames": []interface{}{namespace},
+			},
+			"endpoints": []interface{}{
+				map[string]interface{}{
+					"port": PortName,
+					"path": "/metrics",
+				},
+			},
+		},
+	}}
+}
+
+// RegisterServiceMonitor makes the Prometheus operator scrape the metrics of the server,
+// through the named service of Epinio's namespace. The service has to name the metrics port
+// `metrics`. The service monitor selects the service by its labels.
+func RegisterServiceMonitor(ctx context.Context, kube kubeclient.Interface, client dynamic.Interface, namespace, service string) error {
+	svc, err := kube.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the service %s", service)
+	}
+	if len(svc.Labels) == 0 {
+		return errors.Errorf("service %s has no labels to select it by", service)
+	}
+
+	monitor := ServiceMonitor(namespace, svc.Labels)
+	monitors := client.Resource(ServiceMonitorResource).Namespace(namespace)
+
+	existing, err := monitors.Get(ctx, ServiceMonitorName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = monitors.Create(ctx, monitor, metav1.CreateOptions{})
+		return errors.Wrap(err, "creating the service monitor")
+	}
+	if err != nil {
+		return errors.Wrap(err, "getting the service monitor")
+	}
+
+	existing.Object["spec"] = monitor.Object["spec"]
+	_, err = monitors.Update(ctx, existing, metav1.UpdateOptions{})
+	return errors.Wrap(err, "updating the service monitor")
+}
@@ -5,21 +5,35 @@ package namespaces
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/duration"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// SettingsAnnotationKey is the annotation of the kube namespace holding the settings of the
+// epinio-controlled namespace, as JSON
+const SettingsAnnotationKey = "epinio.suse.org/namespace-settings"
+
 // Namespace represents an epinio-controlled namespace in the system
 type Namespace struct {
-	Name      string
-	CreatedAt metav1.Time
+	Name        string
+	CreatedAt   metav1.Time
+	Settings    models.NamespaceSettings
+	PodSecurity string
+	// UploadLimit is the size limit of the uploads to the namespace, the default limit of
+	// the server if empty.
+	UploadLimit string
 }
 
+// List returns the epinio-controlled namespaces, sorted by name.
 func List(ctx context.Context, kubeClient *kubernetes.Cluster) ([]Namespace, error) {
 	listOptions := metav1.ListOptions{
 		LabelSelector: kubernetes.EpinioNamespaceLabelKey + "=" + kubernetes.EpinioNamespaceLabelValue,
@@ -32,11 +46,22 @@ func List(ctx context.Context, kubeClient *kubernetes.Cluster) ([]Namespace, err
 
 	result := []Namespace{}
 	for _, namespace := range namespaceList.Items {
+		var settings models.NamespaceSettings
+		if encoded, ok := namespace.ObjectMeta.Annotations[SettingsAnnotationKey]; ok {
+			if err := json.Unmarshal([]byte(encoded), &settings); err != nil {
+				return []Namespace{}, errors.Wrapf(err, "bad settings of namespace %s", namespace.ObjectMeta.Name)
+			}
+		}
+
 		result = append(result, Namespace{
-			Name:      namespace.ObjectMeta.Name,
-			CreatedAt: namespace.ObjectMeta.CreationTimestamp,
+			Name:        namespace.ObjectMeta.Name,
+			CreatedAt:   namespace.ObjectMeta.CreationTimestamp,
+			Settings:    settings,
+			PodSecurity: namespace.ObjectMeta.Labels[PodSecurityEnforceLabelKey],
+			UploadLimit: namespace.ObjectMeta.Annotations[UploadLimitAnnotationKey],
 		})
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
 
 	return result, nil
 }
@@ -72,18 +97,31 @@ func Get(ctx context.Context, kubeClient *kubernetes.Cluster, lookupNamespace st
 	return nil, nil
 }
 
+// CreateOptions holds the policies applied to a new epinio-controlled namespace.
+type CreateOptions struct {
+	// PodSecurity is the level the Pod Security admission enforces, none if empty.
+	PodSecurity string
+	// NetworkIsolation denies the traffic coming from other namespaces, except from the
+	// AllowedNamespaces.
+	NetworkIsolation  bool
+	AllowedNamespaces []string
+	// Quota limits the resources of the namespace, none if empty.
+	Quota models.NamespaceQuota
+}
+
 // Create generates a new epinio-controlled namespace, i.e. a kube
-// namespace plus a configuration account.
-func Create(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string) error {
+// namespace plus a configuration account, with the policies of the options.
+func Create(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string, options CreateOptions) error {
+	labels := podSecurityLabels(options.PodSecurity)
+	labels["kubed-sync"] = "registry-creds" // Instruct kubed to copy image pull secrets over.
+	labels[kubernetes.EpinioNamespaceLabelKey] = kubernetes.EpinioNamespaceLabelValue
+
 	if _, err := kubeClient.Kubectl.CoreV1().Namespaces().Create(
 		ctx,
 		&corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: namespace,
-				Labels: map[string]string{
-					"kubed-sync":                       "registry-creds", // Instruct kubed to copy image pull secrets over.
-					kubernetes.EpinioNamespaceLabelKey: kubernetes.EpinioNamespaceLabelValue,
-				},
+				Name:   namespace,
+				Labels: labels,
 				Annotations: map[string]string{
 					"linkerd.io/inject": "enabled",
 				},
@@ -101,6 +139,18 @@ func Create(ctx context.Context, kubeClient *kubernetes.Cluster, namespace strin
 		return errors.Wrap(err, "failed to create a configuration account for apps")
 	}
 
+	if options.NetworkIsolation {
+		if err := createNetworkPolicies(ctx, kubeClient, namespace, options.AllowedNamespaces); err != nil {
+			return errors.Wrap(err, "failed to isolate the namespace")
+		}
+	}
+
+	if len(options.Quota) > 0 {
+		if err := QuotaSet(ctx, kubeClient, namespace, options.Quota); err != nil {
+			return errors.Wrap(err, "failed to set the quota of the namespace")
+		}
+	}
+
 	if _, err := kubeClient.WaitForSecret(ctx, namespace, "registry-creds", duration.ToSecretCopied()); err != nil {
 		return errors.Wrap(err, "timed out while waiting for registry-creds secret to be copied to the new namespace")
 	}
@@ -108,6 +158,30 @@ func Create(ctx context.Context, kubeClient *kubernetes.Cluster, namespace strin
 	return nil
 }
 
+// SettingsSet replaces the settings of the named epinio-controlled namespace.
+func SettingsSet(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string, settings models.NamespaceSettings) error {
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				SettingsAnnotationKey: string(encoded),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = kubeClient.Kubectl.CoreV1().Namespaces().Patch(ctx, namespace,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+
+	return err
+}
+
 // Delete destroys an epinio-controlled namespace, i.e. the associated
 // kube namespace and configuration account.
 func Delete(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string) error {
@@ -0,0 +1,75 @@
+package namespaces
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/pkg/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Names of the network policies isolating an epinio-controlled namespace.
+const (
+	NetworkPolicyDefaultDeny = "epinio-default-deny"
+	NetworkPolicyAllow       = "epinio-allow"
+)
+
+// namespaceNameLabelKey is the label kubernetes gives all namespaces, holding their name.
+const namespaceNameLabelKey = "kubernetes.io/metadata.name"
+
+// createNetworkPolicies isolates the namespace from the other namespaces. All incoming
+// traffic is denied, except from the pods of the namespace itself, i.e. the applications and
+// the services bound to them, and from the pods of the allowed namespaces, like those of the
+// ingress controller and service mesh.
+func createNetworkPolicies(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string, allowedNamespaces []string) error {
+	policies := kubeClient.Kubectl.NetworkingV1().NetworkPolicies(namespace)
+
+	_, err := policies.Create(ctx, &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   NetworkPolicyDefaultDeny,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "epinio"},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "creating the default deny network policy")
+	}
+
+	peers := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{}},
+	}
+	if len(allowedNamespaces) > 0 {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      namespaceNameLabelKey,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   allowedNamespaces,
+				}},
+			},
+		})
+	}
+
+	_, err = policies.Create(ctx, &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   NetworkPolicyAllow,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "epinio"},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: peers},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "creating the allowing network policy")
+	}
+
+	return nil
+}
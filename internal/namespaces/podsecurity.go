@@ -0,0 +1,112 @@
+package namespaces
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+// Levels of the Pod Security Standards, enforced by the Pod Security admission of the cluster.
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+// Labels of the kube namespace telling the Pod Security admission the level to enforce, and
+// to audit and warn about.
+const (
+	PodSecurityEnforceLabelKey = "pod-security.kubernetes.io/enforce"
+	PodSecurityAuditLabelKey   = "pod-security.kubernetes.io/audit"
+	PodSecurityWarnLabelKey    = "pod-security.kubernetes.io/warn"
+)
+
+// ValidatePodSecurity checks that the level is one of the Pod Security Standards.
+func ValidatePodSecurity(level string) error {
+	switch level {
+	case PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted:
+		return nil
+	}
+	return errors.Errorf("bad pod security level '%s', expected one of %s, %s, or %s",
+		level, PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted)
+}
+
+// podSecurityLabels returns the labels enforcing the level, none for no level.
+func podSecurityLabels(level string) map[string]string {
+	if level == "" {
+		return map[string]string{}
+	}
+	return map[string]string{
+		PodSecurityEnforceLabelKey: level,
+		PodSecurityAuditLabelKey:   level,
+		PodSecurityWarnLabelKey:    level,
+	}
+}
+
+// PodSecuritySet makes the Pod Security admission enforce the level in the named
+// epinio-controlled namespace.
+func PodSecuritySet(ctx context.Context, kubeClient *kubernetes.Cluster, namespace, level string) error {
+	if err := ValidatePodSecurity(level); err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": podSecurityLabels(level),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = kubeClient.Kubectl.CoreV1().Namespaces().Patch(ctx, namespace,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+
+	return err
+}
+
+// PodSecurity returns the level enforced in the named namespace, the empty string for none.
+func PodSecurity(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string) (string, error) {
+	space, err := kubeClient.Kubectl.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return space.ObjectMeta.Labels[PodSecurityEnforceLabelKey], nil
+}
+
+// RestrictPodSpec makes the pod comply with the restricted level: its containers run as
+// non-root users, without privilege escalation, capabilities, or an unconfined seccomp
+// profile.
+func RestrictPodSpec(spec *corev1.PodSpec) {
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	spec.SecurityContext.RunAsNonRoot = pointer.Bool(true)
+	spec.SecurityContext.SeccompProfile = &corev1.SeccompProfile{
+		Type: corev1.SeccompProfileTypeRuntimeDefault,
+	}
+
+	for i := range spec.InitContainers {
+		RestrictContainer(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		RestrictContainer(&spec.Containers[i])
+	}
+}
+
+// RestrictContainer takes privilege escalation, and all capabilities, from the container.
+func RestrictContainer(container *corev1.Container) {
+	if container.SecurityContext == nil {
+		container.SecurityContext = &corev1.SecurityContext{}
+	}
+	container.SecurityContext.AllowPrivilegeEscalation = pointer.Bool(false)
+	container.SecurityContext.Capabilities = &corev1.Capabilities{
+		Drop: []corev1.Capability{"ALL"},
+	}
+}
@@ -0,0 +1,47 @@
+package namespaces_test
+
+import (
+	"github.com/epinio/epinio/internal/namespaces"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Pod security", func() {
+	Describe("ValidatePodSecurity", func() {
+		It("accepts the levels of the Pod Security Standards", func() {
+			Expect(namespaces.ValidatePodSecurity("privileged")).To(Succeed())
+			Expect(namespaces.ValidatePodSecurity("baseline")).To(Succeed())
+			Expect(namespaces.ValidatePodSecurity("restricted")).To(Succeed())
+		})
+
+		It("rejects other levels", func() {
+			Expect(namespaces.ValidatePodSecurity("")).ToNot(Succeed())
+			Expect(namespaces.ValidatePodSecurity("strict")).ToNot(Succeed())
+		})
+	})
+
+	Describe("RestrictPodSpec", func() {
+		It("makes all containers comply with the restricted level", func() {
+			spec := corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers: []corev1.Container{
+					{Name: "main"},
+					{Name: "sidecar", SecurityContext: &corev1.SecurityContext{RunAsUser: pointer.Int64(1000)}},
+				},
+			}
+
+			namespaces.RestrictPodSpec(&spec)
+
+			Expect(*spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(spec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+
+			for _, container := range append(spec.InitContainers, spec.Containers...) {
+				Expect(*container.SecurityContext.AllowPrivilegeEscalation).To(BeFalse(), container.Name)
+				Expect(container.SecurityContext.Capabilities.Drop).To(ConsistOf(corev1.Capability("ALL")), container.Name)
+			}
+			Expect(*spec.Containers[1].SecurityContext.RunAsUser).To(Equal(int64(1000)))
+		})
+	})
+})
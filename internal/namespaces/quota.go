@@ -0,0 +1,199 @@
+package namespaces
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Names of the resource quota, and limit range, of an epinio-controlled namespace.
+const (
+	QuotaName      = "epinio-quota"
+	LimitRangeName = "epinio-limits"
+)
+
+// quotaResources maps the keys of the namespace quotas to the resources limited by the
+// resource quota.
+var quotaResources = map[string]corev1.ResourceName{
+	"cpu":     corev1.ResourceRequestsCPU,
+	"memory":  corev1.ResourceRequestsMemory,
+	"storage": corev1.ResourceRequestsStorage,
+	"apps":    corev1.ResourceName("count/apps.application.epinio.io"),
+}
+
+// Requests given to the containers of a namespace with a cpu, or memory, quota which do not
+// ask for any. The quota refuses pods without requests.
+var (
+	defaultCPURequest    = resource.MustParse("100m")
+	defaultMemoryRequest = resource.MustParse("128Mi")
+)
+
+// ValidateQuota checks that the quota limits known resources, by proper quantities.
+func ValidateQuota(quota models.NamespaceQuota) error {
+	for key, value := range quota {
+		if _, ok := quotaResources[key]; !ok {
+			return errors.Errorf("bad quota '%s', expected one of %s", key, strings.Join(quotaKeys(), ", "))
+		}
+		if key == "apps" {
+			if count, err := strconv.Atoi(value); err != nil || count < 0 {
+				return errors.Errorf("bad quota apps=%s, expected a count", value)
+			}
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return errors.Wrapf(err, "bad quota %s=%s", key, value)
+		}
+		if quantity.Sign() < 0 {
+			return errors.Errorf("bad quota %s=%s, cannot be negative", key, value)
+		}
+	}
+	return nil
+}
+
+// QuotaSet replaces the quota of the named epinio-controlled namespace. An empty quota
+// removes it.
+func QuotaSet(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string, quota models.NamespaceQuota) error {
+	if err := ValidateQuota(quota); err != nil {
+		return err
+	}
+
+	if len(quota) == 0 {
+		err := kubeClient.Kubectl.CoreV1().ResourceQuotas(namespace).Delete(ctx, QuotaName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "removing the resource quota")
+		}
+		return removeLimitRange(ctx, kubeClient, namespace)
+	}
+
+	hard := corev1.ResourceList{}
+	for key, value := range quota {
+		hard[quotaResources[key]] = resource.MustParse(value)
+	}
+
+	resourceQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   QuotaName,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "epinio"},
+		},
+		Spec: corev1.ResourceQuotaSpec{Hard: hard},
+	}
+	if err := applyResourceQuota(ctx, kubeClient, namespace, resourceQuota); err != nil {
+		return errors.Wrap(err, "saving the resource quota")
+	}
+
+	_, cpu := quota["cpu"]
+	_, memory := quota["memory"]
+	if !cpu && !memory {
+		return removeLimitRange(ctx, kubeClient, namespace)
+	}
+
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   LimitRangeName,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "epinio"},
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{{
+				Type: corev1.LimitTypeContainer,
+				DefaultRequest: corev1.ResourceList{
+					corev1.ResourceCPU:    defaultCPURequest,
+					corev1.ResourceMemory: defaultMemoryRequest,
+				},
+			}},
+		},
+	}
+	if err := applyLimitRange(ctx, kubeClient, namespace, limitRange); err != nil {
+		return errors.Wrap(err, "saving the limit range")
+	}
+
+	return nil
+}
+
+// Quota returns the quota of the named namespace, with the current usage of the limited
+// resources. It is empty for namespaces without quota.
+func Quota(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string) (map[string]models.QuotaStatus, error) {
+	result := map[string]models.QuotaStatus{}
+
+	resourceQuota, err := kubeClient.Kubectl.CoreV1().ResourceQuotas(namespace).Get(ctx, QuotaName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for key, name := range quotaResources {
+		hard, ok := resourceQuota.Spec.Hard[name]
+		if !ok {
+			continue
+		}
+		used := resourceQuota.Status.Used[name]
+		result[key] = models.QuotaStatus{
+			Limit: hard.String(),
+			Used:  used.String(),
+		}
+	}
+
+	return result, nil
+}
+
+func applyResourceQuota(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string, quota *corev1.ResourceQuota) error {
+	quotas := kubeClient.Kubectl.CoreV1().ResourceQuotas(namespace)
+
+	existing, err := quotas.Get(ctx, quota.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = quotas.Create(ctx, quota, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	existing.Spec = quota.Spec
+	_, err = quotas.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func applyLimitRange(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string, limitRange *corev1.LimitRange) error {
+	limitRanges := kubeClient.Kubectl.CoreV1().LimitRanges(namespace)
+
+	existing, err := limitRanges.Get(ctx, limitRange.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = limitRanges.Create(ctx, limitRange, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	existing.Spec = limitRange.Spec
+	_, err = limitRanges.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func removeLimitRange(ctx context.Context, kubeClient *kubernetes.Cluster, namespace string) error {
+	err := kubeClient.Kubectl.CoreV1().LimitRanges(namespace).Delete(ctx, LimitRangeName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "removing the limit range")
+	}
+	return nil
+}
+
+func quotaKeys() []string {
+	keys := []string{}
+	for key := range quotaResources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,30 @@
+package namespaces_test
+
+import (
+	"github.com/epinio/epinio/internal/namespaces"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateQuota", func() {
+	It("accepts quantities of the known resources", func() {
+		Expect(namespaces.ValidateQuota(models.NamespaceQuota{
+			"cpu":     "20",
+			"memory":  "64Gi",
+			"storage": "100Gi",
+			"apps":    "50",
+		})).To(Succeed())
+	})
+
+	It("rejects unknown resources", func() {
+		err := namespaces.ValidateQuota(models.NamespaceQuota{"gpu": "1"})
+		Expect(err).To(MatchError(ContainSubstring("expected one of apps, cpu, memory, storage")))
+	})
+
+	It("rejects bad quantities", func() {
+		Expect(namespaces.ValidateQuota(models.NamespaceQuota{"memory": "lots"})).ToNot(Succeed())
+		Expect(namespaces.ValidateQuota(models.NamespaceQuota{"cpu": "-1"})).ToNot(Succeed())
+		Expect(namespaces.ValidateQuota(models.NamespaceQuota{"apps": "1.5"})).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,77 @@
+package namespaces
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// UploadLimitAnnotationKey is the annotation of the kube namespace holding the size limit of
+// the application sources uploaded to the epinio-controlled namespace, as a kubernetes
+// quantity. Without it the default limit of the server applies.
+const UploadLimitAnnotationKey = "epinio.suse.org/upload-limit"
+
+// ParseUploadLimit returns the size, in bytes, of the upload limit given as kubernetes
+// quantity, e.g. `512Mi`, or `2Gi`. Zero is no limit.
+func ParseUploadLimit(limit string) (int64, error) {
+	quantity, err := resource.ParseQuantity(limit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "bad upload limit '%s'", limit)
+	}
+	if quantity.Sign() < 0 {
+		return 0, errors.Errorf("bad upload limit '%s', cannot be negative", limit)
+	}
+	return quantity.Value(), nil
+}
+
+// UploadLimitSet replaces the upload limit of the named epinio-controlled namespace. The
+// empty string removes it, the default limit of the server applies again.
+func UploadLimitSet(ctx context.Context, kubeClient *kubernetes.Cluster, namespace, limit string) error {
+	var value interface{}
+	if limit != "" {
+		if _, err := ParseUploadLimit(limit); err != nil {
+			return err
+		}
+		value = limit
+	}
+
+	// A merge patch removes the annotation for the null value.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				UploadLimitAnnotationKey: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = kubeClient.Kubectl.CoreV1().Namespaces().Patch(ctx, namespace,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+
+	return err
+}
+
+// UploadLimit returns the size limit, in bytes, of the uploads to the named namespace, the
+// one of the namespace, or else the default. Zero is no limit.
+func UploadLimit(ctx context.Context, kubeClient *kubernetes.Cluster, namespace, defaultLimit string) (int64, error) {
+	space, err := kubeClient.Kubectl.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	limit, ok := space.ObjectMeta.Annotations[UploadLimitAnnotationKey]
+	if !ok {
+		limit = defaultLimit
+	}
+	if limit == "" {
+		return 0, nil
+	}
+	return ParseUploadLimit(limit)
+}
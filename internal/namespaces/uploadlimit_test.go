@@ -0,0 +1,26 @@
+package namespaces_test
+
+import (
+	"github.com/epinio/epinio/internal/namespaces"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseUploadLimit", func() {
+	It("returns the size of the quantity", func() {
+		Expect(namespaces.ParseUploadLimit("512Mi")).To(Equal(int64(512 * 1024 * 1024)))
+		Expect(namespaces.ParseUploadLimit("2G")).To(Equal(int64(2000 * 1000 * 1000)))
+	})
+
+	It("takes zero as no limit", func() {
+		Expect(namespaces.ParseUploadLimit("0")).To(Equal(int64(0)))
+	})
+
+	It("rejects bad quantities", func() {
+		_, err := namespaces.ParseUploadLimit("huge")
+		Expect(err).To(MatchError(ContainSubstring("bad upload limit 'huge'")))
+
+		_, err = namespaces.ParseUploadLimit("-1Gi")
+		Expect(err).To(MatchError(ContainSubstring("cannot be negative")))
+	})
+})
@@ -0,0 +1,145 @@
+package notifications
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// The targets are set by the users of the namespaces, and posted to from within the cluster.
+// To keep them from reaching the services of the cluster, and the metadata endpoints of the
+// cloud, through the server, they are limited to https, and to public addresses, except for
+// the hosts, and networks, the operator allows, see the `notification-allowed-hosts` flag.
+
+// internalSuffixes are the endings of names resolved to addresses of the cluster, or of the
+// local network.
+var internalSuffixes = []string{".local", ".internal", ".svc", ".localhost"}
+
+// sharedNetwork is the shared address space of carrier-grade NAT, RFC 6598, not covered by
+// net.IP.IsPrivate.
+var sharedNetwork = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// allowedHosts returns the hosts, and the networks, the targets may reach, whatever their
+// scheme, and addresses.
+func allowedHosts() (map[string]struct{}, []*net.IPNet) {
+	hosts := map[string]struct{}{}
+	networks := []*net.IPNet{}
+	for _, entry := range strings.Split(viper.GetString("notification-allowed-hosts"), ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		hosts[entry] = struct{}{}
+	}
+	return hosts, networks
+}
+
+// hostAllowed returns true if the operator allows the host, by name, or address.
+func hostAllowed(host string) bool {
+	hosts, networks := allowedHosts()
+	if _, ok := hosts[strings.ToLower(host)]; ok {
+		return true
+	}
+	return ipAllowed(net.ParseIP(host), networks)
+}
+
+func ipAllowed(ip net.IP, networks []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// internalIP returns true for the addresses which are not public: loopback, private,
+// link-local, shared, unspecified, and multicast ones.
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedNetwork.Contains(ip)
+}
+
+// internalHost returns true for the names of the cluster, and of the local network, i.e. those
+// without domain, or with an internal one, and for the internal addresses.
+func internalHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return internalIP(ip)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL checks that the url of a target is an https url of a public host, unless the
+// operator allows the host.
+func ValidateURL(address string) error {
+	parsed, err := url.Parse(address)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.Errorf("notification url `%s` is not an http(s) url", address)
+	}
+
+	host := parsed.Hostname()
+	if hostAllowed(host) {
+		return nil
+	}
+	if parsed.Scheme != "https" {
+		return errors.Errorf("notification url `%s` is not an https url", address)
+	}
+	if internalHost(host) {
+		return errors.Errorf("notification url `%s` is not of a public host", address)
+	}
+	return nil
+}
+
+// guardedDial connects to the address, refusing the internal addresses the name of the host
+// resolves to, unless the operator allows the host, or the address. Checking the resolved
+// addresses, at connection time, covers the names resolving to changing addresses as well.
+func guardedDial(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if hostAllowed(host) {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		guarded := *dialer
+		guarded.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			_, networks := allowedHosts()
+			if ip == nil || (internalIP(ip) && !ipAllowed(ip, networks)) {
+				return errors.Errorf("notification target address %s is not public", host)
+			}
+			return nil
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+}
@@ -0,0 +1,161 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// Delivery of the messages: a request has deliveryTimeout to succeed, failed ones are retried
+// deliveryAttempts times in all, waiting retryDelay, doubled after each attempt.
+const (
+	deliveryTimeout  = 10 * time.Second
+	deliveryAttempts = 3
+	retryDelay       = 2 * time.Second
+)
+
+// Sender posts the messages to the targets.
+type Sender struct {
+	Client *http.Client
+	// RetryDelay is the wait before the first retry of a failed delivery.
+	RetryDelay time.Duration
+}
+
+// NewSender returns a sender with the default timeout and retry delay. It connects to public
+// addresses only, unless the operator allows the host, and does not follow redirects.
+func NewSender() *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = guardedDial(&net.Dialer{Timeout: deliveryTimeout})
+
+	return &Sender{
+		Client: &http.Client{
+			Timeout:   deliveryTimeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		RetryDelay: retryDelay,
+	}
+}
+
+// Notify posts the message about the event to the target, retrying failed attempts, until the
+// context is done.
+func (s *Sender) Notify(ctx context.Context, target Target, event models.PlatformEvent) error {
+	payload, err := target.Payload(event)
+	if err != nil {
+		return err
+	}
+
+	delay := s.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err = s.post(ctx, target, payload)
+		if err == nil || attempt == deliveryAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes a single attempt at delivering the payload. The url is checked again, for the
+// targets stored before the operator changed the allowed hosts.
+func (s *Sender) post(ctx context.Context, target Target, payload []byte) error {
+	if err := ValidateURL(target.URL); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "posting notification")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Errorf("notification target returned '%s'", response.Status)
+	}
+	return nil
+}
+
+// NotifyLoop posts the messages about the events of the default bus to the matching targets
+// of their namespaces, until the context is done. The targets are read for each event,
+// changes apply to the next one.
+func NotifyLoop(ctx context.Context, logger logr.Logger) {
+	sender := NewSender()
+
+	var lastID uint64
+	for {
+		subscription, missed := events.Default.Subscribe(lastID, nil)
+		for _, event := range missed {
+			lastID = event.ID
+			dispatch(ctx, logger, sender, event)
+		}
+
+		// The bus closes the subscription when the loop falls behind. The next one
+		// catches up from the recent events.
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				events.Default.Unsubscribe(subscription)
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					open = false
+					break
+				}
+				lastID = event.ID
+				dispatch(ctx, logger, sender, event)
+			}
+		}
+	}
+}
+
+// dispatch posts the message about the event to the targets of its namespace it matches, in
+// the background. Events without namespace, and those of deleted namespaces, have none.
+func dispatch(ctx context.Context, logger logr.Logger, sender *Sender, event models.PlatformEvent) {
+	if event.Namespace == "" || event.Type == models.EventNamespaceDeleted {
+		return
+	}
+
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		logger.Error(err, "notifications: no cluster")
+		return
+	}
+	targets, err := NewStore(cluster, event.Namespace).List(ctx)
+	if err != nil {
+		logger.Error(err, "notifications: listing the targets failed", "namespace", event.Namespace)
+		return
+	}
+
+	for _, target := range targets {
+		if !target.Matches(event) {
+			continue
+		}
+		go func(target Target) {
+			if err := sender.Notify(ctx, target, event); err != nil {
+				logger.Error(err, "notifications: delivery failed", "namespace", target.Namespace,
+					"target", target.Name, "event", event.ID, "type", event.Type)
+			}
+		}(target)
+	}
+}
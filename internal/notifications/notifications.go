@@ -0,0 +1,280 @@
+// Package notifications posts messages about the events of a namespace, see
+// models.PlatformEvent, to the chat channels, and other receivers, configured for it, see
+// models.NotificationTarget. By default these are the outcomes of pushes, i.e. the failed
+// stagings and the deployments, and of the provisioning of services.
+//
+// The targets are stored as secrets of their namespace, as their urls carry the credentials
+// of the channels. They are removed with the namespace.
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+var (
+	ErrTargetNotFound = errors.New("notification target not found")
+)
+
+// DefaultEvents are the types of the events notified to the targets without types of their
+// own: the outcomes of pushes, and of the provisioning of services.
+var DefaultEvents = []string{
+	models.EventStagingFailed,
+	models.EventAppDeployed,
+	models.EventServiceProvisioned,
+	models.EventServiceFailed,
+}
+
+// defaultTemplates are the messages of the events, by type. The events of other types get
+// defaultTemplate.
+var defaultTemplates = map[string]string{
+	models.EventStagingFailed:      `Staging of app {{.Name}} in namespace {{.Namespace}} failed{{with .Details.stage_id}} (stage {{.}}){{end}}`,
+	models.EventStagingSucceeded:   `Staging of app {{.Name}} in namespace {{.Namespace}} succeeded{{with .Details.stage_id}} (stage {{.}}){{end}}`,
+	models.EventAppDeployed:        `App {{.Name}} in namespace {{.Namespace}} deployed{{with .Details.image}} with image {{.}}{{end}}{{with .User}} by {{.}}{{end}}`,
+	models.EventServiceProvisioned: `Service {{.Name}} in namespace {{.Namespace}} provisioned{{with .Details.catalog_service}} from {{.}}{{end}}`,
+	models.EventServiceFailed:      `Provisioning of service {{.Name}} in namespace {{.Namespace}} failed{{with .Details.error}}: {{.}}{{end}}`,
+}
+
+const defaultTemplate = `{{.Type}}: {{.Name}} in namespace {{.Namespace}}{{with .User}} by {{.}}{{end}}`
+
+// Target is a receiver of messages about the events of its namespace.
+type Target struct {
+	Name      string
+	Namespace string
+	Type      string
+	URL       string
+	Events    []string
+	Template  string
+}
+
+// newTargetFromSecret creates a target from its secret.
+func newTargetFromSecret(secret corev1.Secret) Target {
+	events := []string{}
+	for _, event := range strings.Split(string(secret.Data["events"]), "\n") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+
+	return Target{
+		Name:      string(secret.Data["name"]),
+		Namespace: secret.Namespace,
+		Type:      string(secret.Data["type"]),
+		URL:       string(secret.Data["url"]),
+		Events:    events,
+		Template:  string(secret.Data["template"]),
+	}
+}
+
+// Validate checks the type, url, and template of the target.
+func (t Target) Validate() error {
+	switch t.Type {
+	case models.NotificationSlack, models.NotificationTeams, models.NotificationGeneric:
+	default:
+		return errors.Errorf("notification type `%s` is not one of slack, teams, or generic", t.Type)
+	}
+
+	if err := ValidateURL(t.URL); err != nil {
+		return err
+	}
+
+	if t.Template != "" {
+		if _, err := template.New("message").Parse(t.Template); err != nil {
+			return errors.Wrap(err, "bad notification template")
+		}
+	}
+
+	return nil
+}
+
+// Matches returns true if the target receives the event.
+func (t Target) Matches(event models.PlatformEvent) bool {
+	if event.Namespace != t.Namespace {
+		return false
+	}
+
+	types := t.Events
+	if len(types) == 0 {
+		types = DefaultEvents
+	}
+	for _, eventType := range types {
+		if event.Type == eventType || strings.HasPrefix(event.Type, eventType+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Message returns the text of the message about the event, from the template of the target,
+// or the default one of the type of the event.
+func (t Target) Message(event models.PlatformEvent) (string, error) {
+	text := t.Template
+	if text == "" {
+		text = defaultTemplates[event.Type]
+	}
+	if text == "" {
+		text = defaultTemplate
+	}
+
+	tmpl, err := template.New("message").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing the notification template")
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, event); err != nil {
+		return "", errors.Wrap(err, "rendering the notification template")
+	}
+	return out.String(), nil
+}
+
+// Payload returns the body of the request posting the message about the event to the target.
+// Slack gets the text, Teams a message card, colored by the outcome of the event, and the
+// generic targets the text with the event.
+func (t Target) Payload(event models.PlatformEvent) ([]byte, error) {
+	message, err := t.Message(event)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Type {
+	case models.NotificationSlack:
+		return json.Marshal(map[string]interface{}{"text": message})
+	case models.NotificationTeams:
+		return json.Marshal(map[string]interface{}{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    message,
+			"text":       message,
+			"themeColor": themeColor(event),
+		})
+	default:
+		return json.Marshal(map[string]interface{}{"text": message, "event": event})
+	}
+}
+
+// themeColor returns red for failures, and green for everything else.
+func themeColor(event models.PlatformEvent) string {
+	if strings.HasSuffix(event.Type, ".failed") {
+		return "D70000"
+	}
+	return "2EB886"
+}
+
+// Store keeps the notification targets of a namespace in its secrets.
+type Store struct {
+	SecretInterface typedcorev1.SecretInterface
+	Namespace       string
+}
+
+// NewStore returns the store of the notification targets of the namespace.
+func NewStore(cluster *kubernetes.Cluster, namespace string) *Store {
+	return &Store{
+		SecretInterface: cluster.Kubectl.CoreV1().Secrets(namespace),
+		Namespace:       namespace,
+	}
+}
+
+// List returns the targets of the namespace, sorted by name.
+func (s *Store) List(ctx context.Context) ([]Target, error) {
+	selector := labels.Set(map[string]string{
+		kubernetes.EpinioNotificationLabelKey: kubernetes.EpinioNotificationLabelValue,
+	}).AsSelector().String()
+
+	secretList, err := s.SecretInterface.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the notification target secrets")
+	}
+
+	targets := []Target{}
+	for _, secret := range secretList.Items {
+		target := newTargetFromSecret(secret)
+		target.Namespace = s.Namespace
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+
+	return targets, nil
+}
+
+// Get returns the target with the name, or ErrTargetNotFound.
+func (s *Store) Get(ctx context.Context, name string) (Target, error) {
+	secret, err := s.SecretInterface.Get(ctx, targetSecretName(name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Target{}, ErrTargetNotFound
+	}
+	if err != nil {
+		return Target{}, errors.Wrap(err, fmt.Sprintf("error getting the notification target secret [%s]", name))
+	}
+	if secret.Labels[kubernetes.EpinioNotificationLabelKey] != kubernetes.EpinioNotificationLabelValue {
+		return Target{}, ErrTargetNotFound
+	}
+
+	target := newTargetFromSecret(*secret)
+	target.Namespace = s.Namespace
+	return target, nil
+}
+
+// Set creates the target, or replaces the existing one.
+func (s *Store) Set(ctx context.Context, target Target) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.SecretInterface.Get(ctx, targetSecretName(target.Name), metav1.GetOptions{})
+		isNew := apierrors.IsNotFound(err)
+		if isNew {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: targetSecretName(target.Name),
+					Labels: map[string]string{
+						kubernetes.EpinioNotificationLabelKey: kubernetes.EpinioNotificationLabelValue,
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		} else if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error getting the notification target secret [%s]", target.Name))
+		}
+
+		secret.Data = map[string][]byte{
+			"name":     []byte(target.Name),
+			"type":     []byte(target.Type),
+			"url":      []byte(target.URL),
+			"events":   []byte(strings.Join(target.Events, "\n")),
+			"template": []byte(target.Template),
+		}
+
+		if isNew {
+			_, err = s.SecretInterface.Create(ctx, secret, metav1.CreateOptions{})
+		} else {
+			_, err = s.SecretInterface.Update(ctx, secret, metav1.UpdateOptions{})
+		}
+		return err
+	})
+}
+
+// Delete removes the target.
+func (s *Store) Delete(ctx context.Context, name string) error {
+	err := s.SecretInterface.Delete(ctx, targetSecretName(name), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrTargetNotFound
+	}
+	return errors.Wrap(err, fmt.Sprintf("error deleting the notification target secret [%s]", name))
+}
+
+func targetSecretName(name string) string {
+	return "notification-" + name
+}
@@ -0,0 +1,236 @@
+package notifications_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/epinio/epinio/internal/notifications"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Target", func() {
+	deployed := models.PlatformEvent{
+		ID:        7,
+		Type:      models.EventAppDeployed,
+		Namespace: "workspace",
+		Name:      "app",
+		User:      "alice",
+		Details:   map[string]string{"image": "registry/app:1"},
+	}
+	failed := models.PlatformEvent{
+		ID:        8,
+		Type:      models.EventStagingFailed,
+		Namespace: "workspace",
+		Name:      "app",
+		Details:   map[string]string{"stage_id": "s1"},
+	}
+
+	slack := notifications.Target{Name: "chat", Namespace: "workspace", Type: models.NotificationSlack, URL: "https://hooks.slack.com/services/x"}
+
+	It("receives the outcomes of pushes, and of service provisioning, without types", func() {
+		Expect(slack.Matches(deployed)).To(BeTrue())
+		Expect(slack.Matches(failed)).To(BeTrue())
+		Expect(slack.Matches(models.PlatformEvent{Type: models.EventServiceFailed, Namespace: "workspace"})).To(BeTrue())
+		Expect(slack.Matches(models.PlatformEvent{Type: models.EventAppScaled, Namespace: "workspace"})).To(BeFalse())
+	})
+
+	It("receives the events of its types, of its namespace only", func() {
+		target := slack
+		target.Events = []string{"app.staging"}
+		Expect(target.Matches(failed)).To(BeTrue())
+		Expect(target.Matches(deployed)).To(BeFalse())
+
+		other := failed
+		other.Namespace = "other"
+		Expect(target.Matches(other)).To(BeFalse())
+	})
+
+	It("renders the default messages", func() {
+		Expect(slack.Message(deployed)).To(Equal("App app in namespace workspace deployed with image registry/app:1 by alice"))
+		Expect(slack.Message(failed)).To(Equal("Staging of app app in namespace workspace failed (stage s1)"))
+		Expect(slack.Message(models.PlatformEvent{Type: models.EventAppScaled, Namespace: "workspace", Name: "app"})).
+			To(Equal("app.scaled: app in namespace workspace"))
+	})
+
+	It("renders the template of the target", func() {
+		target := slack
+		target.Template = `{{.Name}} is out ({{.Details.image}}{{.Details.missing}})`
+		Expect(target.Message(deployed)).To(Equal("app is out (registry/app:1)"))
+	})
+
+	It("formats the payload for the type of the target", func() {
+		var payload map[string]interface{}
+
+		data, err := slack.Payload(deployed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(data, &payload)).To(Succeed())
+		Expect(payload).To(Equal(map[string]interface{}{"text": "App app in namespace workspace deployed with image registry/app:1 by alice"}))
+
+		teams := slack
+		teams.Type = models.NotificationTeams
+		data, err = teams.Payload(failed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(data, &payload)).To(Succeed())
+		Expect(payload).To(HaveKeyWithValue("@type", "MessageCard"))
+		Expect(payload).To(HaveKeyWithValue("themeColor", "D70000"))
+
+		generic := slack
+		generic.Type = models.NotificationGeneric
+		data, err = generic.Payload(deployed)
+		Expect(err).ToNot(HaveOccurred())
+		payload = map[string]interface{}{}
+		Expect(json.Unmarshal(data, &payload)).To(Succeed())
+		Expect(payload).To(HaveKey("text"))
+		Expect(payload["event"]).To(HaveKeyWithValue("type", models.EventAppDeployed))
+	})
+
+	It("validates the type, url, and template", func() {
+		Expect(slack.Validate()).To(Succeed())
+
+		target := slack
+		target.Type = "irc"
+		Expect(target.Validate()).To(MatchError(ContainSubstring("not one of slack, teams, or generic")))
+
+		target = slack
+		target.URL = "hooks.slack.com"
+		Expect(target.Validate()).To(MatchError(ContainSubstring("not an http(s) url")))
+
+		target = slack
+		target.Template = "{{.Name"
+		Expect(target.Validate()).To(MatchError(ContainSubstring("bad notification template")))
+	})
+
+	It("rejects plain http, and internal hosts", func() {
+		for _, address := range []string{
+			"http://hooks.slack.com/services/x",
+			"https://127.0.0.1/hook",
+			"https://10.0.0.1/hook",
+			"https://169.254.169.254/latest/meta-data",
+			"https://[::1]/hook",
+			"https://localhost/hook",
+			"https://epinio-server/hook",
+			"https://epinio-server.epinio.svc/hook",
+			"https://epinio-server.epinio.svc.cluster.local/hook",
+		} {
+			Expect(notifications.ValidateURL(address)).ToNot(Succeed(), address)
+		}
+	})
+
+	It("accepts the hosts, and networks, the operator allows", func() {
+		viper.Set("notification-allowed-hosts", "chat.epinio.svc, 10.0.0.0/8")
+		DeferCleanup(viper.Set, "notification-allowed-hosts", "")
+
+		Expect(notifications.ValidateURL("http://chat.epinio.svc/hook")).To(Succeed())
+		Expect(notifications.ValidateURL("https://10.1.2.3/hook")).To(Succeed())
+		Expect(notifications.ValidateURL("https://192.168.1.1/hook")).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("Store", func() {
+	var store *notifications.Store
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		store = &notifications.Store{
+			SecretInterface: kubefake.NewSimpleClientset().CoreV1().Secrets("workspace"),
+			Namespace:       "workspace",
+		}
+	})
+
+	It("sets, lists, gets, and deletes the targets", func() {
+		Expect(store.Set(ctx, notifications.Target{Name: "teams", Type: models.NotificationTeams, URL: "https://teams/1"})).To(Succeed())
+		Expect(store.Set(ctx, notifications.Target{Name: "chat", Type: models.NotificationSlack, URL: "https://slack/1", Events: []string{"app"}})).To(Succeed())
+		Expect(store.Set(ctx, notifications.Target{Name: "chat", Type: models.NotificationSlack, URL: "https://slack/2"})).To(Succeed())
+
+		targets, err := store.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(HaveLen(2))
+		Expect(targets[0].Name).To(Equal("chat"))
+		Expect(targets[0].URL).To(Equal("https://slack/2"))
+		Expect(targets[0].Events).To(BeEmpty())
+		Expect(targets[0].Namespace).To(Equal("workspace"))
+
+		target, err := store.Get(ctx, "teams")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(target.Type).To(Equal(models.NotificationTeams))
+
+		Expect(store.Delete(ctx, "teams")).To(Succeed())
+		_, err = store.Get(ctx, "teams")
+		Expect(err).To(MatchError(notifications.ErrTargetNotFound))
+		Expect(store.Delete(ctx, "teams")).To(MatchError(notifications.ErrTargetNotFound))
+	})
+})
+
+var _ = Describe("Sender", func() {
+	event := models.PlatformEvent{ID: 7, Type: models.EventAppDeployed, Namespace: "workspace", Name: "app"}
+
+	BeforeEach(func() {
+		viper.Set("notification-allowed-hosts", "127.0.0.1")
+		DeferCleanup(viper.Set, "notification-allowed-hosts", "")
+	})
+
+	It("posts the message", func() {
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		target := notifications.Target{Type: models.NotificationSlack, URL: server.URL}
+		Expect(notifications.NewSender().Notify(context.Background(), target, event)).To(Succeed())
+		Expect(string(body)).To(Equal(`{"text":"App app in namespace workspace deployed"}`))
+	})
+
+	It("retries failed deliveries, and gives up after the last attempt", func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		sender := notifications.NewSender()
+		sender.RetryDelay = time.Millisecond
+		target := notifications.Target{Type: models.NotificationSlack, URL: server.URL}
+		err := sender.Notify(context.Background(), target, event)
+		Expect(err).To(MatchError(ContainSubstring("500")))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+	})
+
+	It("does not connect to internal addresses of public names", func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}))
+		defer server.Close()
+		viper.Set("notification-allowed-hosts", "")
+
+		sender := notifications.NewSender()
+		sender.RetryDelay = time.Millisecond
+		_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		// The name is public, its address is not.
+		request, err := http.NewRequest(http.MethodPost, "https://hooks.example.com:"+port, nil)
+		Expect(err).ToNot(HaveOccurred())
+		transport := sender.Client.Transport.(*http.Transport)
+		dial := transport.DialContext
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dial(ctx, network, server.Listener.Addr().String())
+		}
+		_, err = sender.Client.Do(request)
+		Expect(err).To(MatchError(ContainSubstring("is not public")))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(0)))
+	})
+})
@@ -0,0 +1,189 @@
+// Package operations tracks the long-running operations of the server, e.g. stagings and the
+// provisioning of services, by an id. The work goes on in the background, independent of the
+// request starting it, and of the client disconnecting. Clients look the operation up by its
+// id, to follow its progress, and get its result.
+//
+// The operations are those of the server running them, kept in its memory. With several
+// replicas of the server, the operations of the others are not seen. Finished operations are
+// kept for the retention time.
+package operations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/helpers/randstr"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierrors "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Retention is the time finished operations are kept.
+const Retention = time.Hour
+
+// Default is the registry of the operations of the server.
+var Default = NewRegistry(Retention)
+
+// Work is the work of an operation. It reports its progress to the tracker, and returns the
+// errors it failed with, if any.
+type Work func(ctx context.Context, tracker *Tracker) apierrors.APIErrors
+
+// Registry keeps the operations, running, and finished within the retention time.
+type Registry struct {
+	retention time.Duration
+
+	mu         sync.Mutex
+	operations map[string]*models.Operation
+}
+
+// Tracker records the progress of an operation.
+type Tracker struct {
+	registry *Registry
+	id       string
+}
+
+// NewRegistry returns a registry keeping finished operations for the retention time.
+func NewRegistry(retention time.Duration) *Registry {
+	return &Registry{
+		retention:  retention,
+		operations: map[string]*models.Operation{},
+	}
+}
+
+// Start starts an operation of the kind, on the named app, or service, running the work in
+// the background. It returns the operation. The work gets the values of the context, e.g. the
+// user, but is not canceled with it.
+func (r *Registry) Start(ctx context.Context, kind, namespace, name string, work Work) (models.Operation, error) {
+	id, err := randstr.Hex16()
+	if err != nil {
+		return models.Operation{}, err
+	}
+
+	now := time.Now()
+	operation := &models.Operation{
+		ID:        id,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		User:      requestctx.User(ctx).Username,
+		Phase:     models.OperationPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	r.mu.Lock()
+	r.prune(now)
+	r.operations[id] = operation
+	started := *operation
+	r.mu.Unlock()
+
+	tracker := &Tracker{registry: r, id: id}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				tracker.finish(apierrors.NewInternalError("operation failed", fmt.Sprint(r)))
+			}
+		}()
+		tracker.finish(work(detached{ctx}, tracker))
+	}()
+
+	return started, nil
+}
+
+// Get returns the operation with the id, if it is known.
+func (r *Registry) Get(id string) (models.Operation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	operation, ok := r.operations[id]
+	if !ok {
+		return models.Operation{}, false
+	}
+	return copyOf(operation), true
+}
+
+// update changes the operation with the id, if it is still known.
+func (r *Registry) update(id string, change func(*models.Operation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if operation, ok := r.operations[id]; ok {
+		change(operation)
+		operation.UpdatedAt = time.Now()
+	}
+}
+
+// prune removes the operations finished longer than the retention time ago.
+func (r *Registry) prune(now time.Time) {
+	for id, operation := range r.operations {
+		if operation.Phase.Done() && now.Sub(operation.UpdatedAt) > r.retention {
+			delete(r.operations, id)
+		}
+	}
+}
+
+// Progress records the phase of the operation, and a description of its current step.
+func (t *Tracker) Progress(phase models.OperationPhase, progress string) {
+	t.registry.update(t.id, func(operation *models.Operation) {
+		operation.Phase = phase
+		operation.Progress = progress
+	})
+}
+
+// Detail records a detail of the result of the operation.
+func (t *Tracker) Detail(key, value string) {
+	t.registry.update(t.id, func(operation *models.Operation) {
+		if operation.Details == nil {
+			operation.Details = map[string]string{}
+		}
+		operation.Details[key] = value
+	})
+}
+
+// finish records the end of the operation, failed with the first of the errors, if any.
+func (t *Tracker) finish(errs apierrors.APIErrors) {
+	t.registry.update(t.id, func(operation *models.Operation) {
+		operation.Progress = ""
+		if errs == nil {
+			operation.Phase = models.OperationSucceeded
+			return
+		}
+
+		first := errs.Errors()[0]
+		operation.Phase = models.OperationFailed
+		operation.Error = first.Title
+		operation.ErrorDetails = first.Details
+	})
+}
+
+// Visible returns true if the user may see the operation, i.e. it is in one of their
+// namespaces, or they are an admin.
+func Visible(user auth.User, operation models.Operation) bool {
+	return user.Role == "admin" || user.NamespaceRole(operation.Namespace) != ""
+}
+
+// copyOf returns a copy of the operation, not sharing its details.
+func copyOf(operation *models.Operation) models.Operation {
+	result := *operation
+	if operation.Details != nil {
+		result.Details = map[string]string{}
+		for key, value := range operation.Details {
+			result.Details[key] = value
+		}
+	}
+	return result
+}
+
+// detached is a context with the values of its parent, but neither its deadline, nor its
+// cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (d detached) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (d detached) Done() <-chan struct{}             { return nil }
+func (d detached) Err() error                        { return nil }
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,139 @@
+package operations_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/epinio/epinio/internal/operations"
+	apierrors "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var registry *operations.Registry
+	var ctx context.Context
+
+	BeforeEach(func() {
+		registry = operations.NewRegistry(time.Hour)
+		ctx = requestctx.WithUser(context.Background(), auth.User{Username: "jane"})
+	})
+
+	phase := func(id string) func() models.OperationPhase {
+		return func() models.OperationPhase {
+			operation, _ := registry.Get(id)
+			return operation.Phase
+		}
+	}
+
+	It("runs the work in the background, and records its progress", func() {
+		step := make(chan struct{})
+		operation, err := registry.Start(ctx, models.OperationStage, "space", "app",
+			func(ctx context.Context, tracker *operations.Tracker) apierrors.APIErrors {
+				tracker.Progress(models.OperationRunning, "building")
+				tracker.Detail("stage_id", "42")
+				<-step
+				return nil
+			})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(operation.ID).ToNot(BeEmpty())
+		Expect(operation.User).To(Equal("jane"))
+
+		Eventually(phase(operation.ID)).Should(Equal(models.OperationRunning))
+		running, ok := registry.Get(operation.ID)
+		Expect(ok).To(BeTrue())
+		Expect(running.Progress).To(Equal("building"))
+		Expect(running.Details).To(HaveKeyWithValue("stage_id", "42"))
+
+		close(step)
+		Eventually(phase(operation.ID)).Should(Equal(models.OperationSucceeded))
+		done, _ := registry.Get(operation.ID)
+		Expect(done.Progress).To(BeEmpty())
+		Expect(done.Details).To(HaveKeyWithValue("stage_id", "42"))
+	})
+
+	It("records the error of failed operations", func() {
+		operation, err := registry.Start(ctx, models.OperationDeploy, "space", "app",
+			func(ctx context.Context, tracker *operations.Tracker) apierrors.APIErrors {
+				return apierrors.NewInternalError("Failed to stage", "stage-id = 42")
+			})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(phase(operation.ID)).Should(Equal(models.OperationFailed))
+		failed, _ := registry.Get(operation.ID)
+		Expect(failed.Error).To(Equal("Failed to stage"))
+		Expect(failed.ErrorDetails).To(Equal("stage-id = 42"))
+	})
+
+	It("records panics of the work as errors", func() {
+		operation, err := registry.Start(ctx, models.OperationDeploy, "space", "app",
+			func(ctx context.Context, tracker *operations.Tracker) apierrors.APIErrors {
+				panic("boom")
+			})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(phase(operation.ID)).Should(Equal(models.OperationFailed))
+		failed, _ := registry.Get(operation.ID)
+		Expect(failed.ErrorDetails).To(Equal("boom"))
+	})
+
+	It("goes on when the request is canceled", func() {
+		requestCtx, cancel := context.WithCancel(ctx)
+		started := make(chan struct{})
+		user := make(chan string, 1)
+
+		operation, err := registry.Start(requestCtx, models.OperationServiceCreate, "space", "db",
+			func(ctx context.Context, tracker *operations.Tracker) apierrors.APIErrors {
+				<-started
+				if ctx.Err() != nil {
+					return apierrors.NewInternalError("canceled")
+				}
+				user <- requestctx.User(ctx).Username
+				return nil
+			})
+		Expect(err).ToNot(HaveOccurred())
+
+		cancel()
+		close(started)
+
+		Eventually(phase(operation.ID)).Should(Equal(models.OperationSucceeded))
+		Expect(<-user).To(Equal("jane"))
+	})
+
+	It("forgets finished operations after the retention time", func() {
+		registry = operations.NewRegistry(time.Millisecond)
+		work := func(ctx context.Context, tracker *operations.Tracker) apierrors.APIErrors {
+			return nil
+		}
+
+		first, err := registry.Start(ctx, models.OperationStage, "space", "app", work)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(phase(first.ID)).Should(Equal(models.OperationSucceeded))
+
+		time.Sleep(10 * time.Millisecond)
+		_, err = registry.Start(ctx, models.OperationStage, "space", "app", work)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, ok := registry.Get(first.ID)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("does not know other ids", func() {
+		_, ok := registry.Get("unknown")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Visible", func() {
+	operation := models.Operation{Namespace: "space"}
+
+	It("shows the operations of the namespaces of the user", func() {
+		Expect(operations.Visible(auth.User{Role: "admin"}, operation)).To(BeTrue())
+		Expect(operations.Visible(auth.User{Role: "user", Namespaces: []string{"space"}}, operation)).To(BeTrue())
+		Expect(operations.Visible(auth.User{Role: "user", Namespaces: []string{"other"}}, operation)).To(BeFalse())
+	})
+})
@@ -0,0 +1,174 @@
+// Package ratelimit limits the rate of the API requests of each identity, i.e. user or API
+// token, so that a runaway client cannot starve the others.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	apierrors "github.com/epinio/epinio/pkg/api/core/v1/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// idleTimeout is how long the buckets of an identity are kept without requests.
+const idleTimeout = 10 * time.Minute
+
+// Limit is a rate of requests per second, with a burst of requests allowed at once. A rate
+// of zero is no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseOverrides parses the limits of endpoints, given as comma-separated assignments
+// `ENDPOINT=RATE[:BURST]`, e.g. `AppUpload=0.1:2,AppLogs=0`. The burst defaults to the
+// default burst.
+func ParseOverrides(spec string, defaultBurst int) (map[string]Limit, error) {
+	overrides := map[string]Limit{}
+
+	for _, assignment := range strings.Split(spec, ",") {
+		assignment = strings.TrimSpace(assignment)
+		if assignment == "" {
+			continue
+		}
+
+		pieces := strings.SplitN(assignment, "=", 2)
+		if len(pieces) < 2 || pieces[0] == "" {
+			return nil, errors.Errorf("bad rate limit '%s', expected ENDPOINT=RATE[:BURST]", assignment)
+		}
+
+		limit := Limit{Burst: defaultBurst}
+		value := pieces[1]
+		if i := strings.Index(value, ":"); i >= 0 {
+			burst, err := strconv.Atoi(value[i+1:])
+			if err != nil || burst < 1 {
+				return nil, errors.Errorf("bad rate limit '%s', burst is not a positive count", assignment)
+			}
+			limit.Burst = burst
+			value = value[:i]
+		}
+
+		var err error
+		limit.Rate, err = strconv.ParseFloat(value, 64)
+		if err != nil || limit.Rate < 0 || math.IsInf(limit.Rate, 0) {
+			return nil, errors.Errorf("bad rate limit '%s', rate is not a positive number", assignment)
+		}
+
+		overrides[pieces[0]] = limit
+	}
+
+	return overrides, nil
+}
+
+// Limiter holds a token bucket per identity, and per identity and overridden endpoint.
+type Limiter struct {
+	Default   Limit
+	Overrides map[string]Limit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	used    time.Time
+}
+
+// New returns a limiter applying the default limit to all endpoints, except the overridden
+// ones.
+func New(def Limit, overrides map[string]Limit) *Limiter {
+	return &Limiter{
+		Default:   def,
+		Overrides: overrides,
+		buckets:   map[string]*bucket{},
+	}
+}
+
+// Allow returns true if the identity may make a request to the endpoint now. Otherwise it
+// returns how long the identity has to wait.
+func (l *Limiter) Allow(identity, endpoint string, now time.Time) (bool, time.Duration) {
+	limit, overridden := l.Overrides[endpoint]
+	if !overridden {
+		limit = l.Default
+	}
+	if limit.Rate == 0 {
+		return true, 0
+	}
+
+	key := identity
+	if overridden {
+		key = identity + " " + endpoint
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), burst)}
+		l.buckets[key] = b
+	}
+	b.used = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// prune removes the buckets of the identities without requests for a while. They are full
+// again anyway.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < idleTimeout {
+		return
+	}
+	l.lastPrune = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.used) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Middleware refuses the requests of identities over their limit, with status 429. The
+// identity is the API token of the request, if any, else the user. The endpoint is the
+// method and route of the request, as `METHOD PATH`. It has to run after the authentication.
+func Middleware(limiter *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		identity := "user:" + requestctx.User(ctx).Username
+		if token := requestctx.APIToken(ctx); token != "" {
+			identity = "token:" + token
+		}
+
+		allowed, delay := limiter.Allow(identity, c.Request.Method+" "+c.FullPath(), time.Now())
+		if allowed {
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+		response.Error(c, apierrors.NewAPIError(
+			fmt.Sprintf("rate limit exceeded, retry in %s", delay.Round(time.Second)), "", http.StatusTooManyRequests))
+		c.Abort()
+	}
+}
@@ -0,0 +1,79 @@
+package ratelimit_test
+
+import (
+	"time"
+
+	"github.com/epinio/epinio/internal/ratelimit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate limiting", func() {
+
+	Describe("ParseOverrides", func() {
+		It("parses rates, with optional bursts", func() {
+			overrides, err := ratelimit.ParseOverrides("AppUpload=0.1:2, AppLogs=0", 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(Equal(map[string]ratelimit.Limit{
+				"AppUpload": {Rate: 0.1, Burst: 2},
+				"AppLogs":   {Rate: 0, Burst: 20},
+			}))
+		})
+
+		It("rejects bad assignments", func() {
+			for _, spec := range []string{"AppUpload", "AppUpload=fast", "AppUpload=-1", "AppUpload=1:0", "=1"} {
+				_, err := ratelimit.ParseOverrides(spec, 20)
+				Expect(err).To(HaveOccurred(), spec)
+			}
+		})
+	})
+
+	Describe("Limiter", func() {
+		var limiter *ratelimit.Limiter
+		var now time.Time
+
+		BeforeEach(func() {
+			limiter = ratelimit.New(ratelimit.Limit{Rate: 1, Burst: 2}, map[string]ratelimit.Limit{
+				"POST /api/v1/upload": {Rate: 0.5, Burst: 1},
+				"GET /api/v1/logs":    {Rate: 0},
+			})
+			now = time.Now()
+		})
+
+		It("allows the burst, then the rate", func() {
+			Expect(limiter.Allow("user:ci", "GET /api/v1/apps", now)).To(BeTrue())
+			Expect(limiter.Allow("user:ci", "GET /api/v1/info", now)).To(BeTrue())
+
+			allowed, delay := limiter.Allow("user:ci", "GET /api/v1/apps", now)
+			Expect(allowed).To(BeFalse())
+			Expect(delay).To(Equal(time.Second))
+
+			Expect(limiter.Allow("user:ci", "GET /api/v1/apps", now.Add(time.Second))).To(BeTrue())
+		})
+
+		It("limits each identity separately", func() {
+			limiter.Allow("user:ci", "GET /api/v1/apps", now)
+			limiter.Allow("user:ci", "GET /api/v1/apps", now)
+			allowed, _ := limiter.Allow("user:ci", "GET /api/v1/apps", now)
+			Expect(allowed).To(BeFalse())
+
+			Expect(limiter.Allow("user:jane", "GET /api/v1/apps", now)).To(BeTrue())
+			Expect(limiter.Allow("token:abc", "GET /api/v1/apps", now)).To(BeTrue())
+		})
+
+		It("limits overridden endpoints separately", func() {
+			Expect(limiter.Allow("user:ci", "POST /api/v1/upload", now)).To(BeTrue())
+			allowed, delay := limiter.Allow("user:ci", "POST /api/v1/upload", now)
+			Expect(allowed).To(BeFalse())
+			Expect(delay).To(Equal(2 * time.Second))
+
+			Expect(limiter.Allow("user:ci", "GET /api/v1/apps", now)).To(BeTrue())
+		})
+
+		It("does not limit endpoints with a rate of zero", func() {
+			for i := 0; i < 10; i++ {
+				Expect(limiter.Allow("user:ci", "GET /api/v1/logs", now)).To(BeTrue())
+			}
+		})
+	})
+})
@@ -0,0 +1,243 @@
+// Package rbac mirrors the permissions of the Epinio users as kube RBAC. Every user gets a
+// service account in Epinio's namespace, bound in each of their namespaces to the cluster
+// role matching their role there. Cluster admins can then audit the access with native
+// tools, e.g. `kubectl auth can-i --as system:serviceaccount:epinio:SA`, and extend it.
+package rbac
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/names"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+// Labels, and annotation, of the exported service accounts, and role bindings.
+const (
+	ExportLabelKey     = "epinio.io/rbac-export"
+	ExportLabelValue   = "true"
+	UsernameAnnotation = "epinio.io/username"
+)
+
+// exportInterval is how often the export loop mirrors the users.
+const exportInterval = time.Minute
+
+// ClusterRoles maps the roles of the users in a namespace to the cluster roles bound there.
+// These are the user-facing roles kube comes with. Epinio admins are admins of all Epinio
+// namespaces.
+var ClusterRoles = map[string]string{
+	auth.NamespaceRoleReader:    "view",
+	auth.NamespaceRoleDeveloper: "edit",
+	auth.NamespaceRoleAdmin:     "admin",
+}
+
+// Export is the service account of a user, and the roles it is bound to in namespaces.
+type Export struct {
+	Username       string
+	ServiceAccount string
+	// Roles maps namespaces to the role of the user there.
+	Roles map[string]string
+}
+
+// ServiceAccountName returns the name of the service account of the user.
+func ServiceAccountName(username string) string {
+	return names.GenerateResourceName("epinio-user", username)
+}
+
+// Exports returns the exports of the users, in the given Epinio namespaces. Admins get the
+// admin role in all of them.
+func Exports(users []auth.User, epinioNamespaces []string) []Export {
+	known := map[string]bool{}
+	for _, namespace := range epinioNamespaces {
+		known[namespace] = true
+	}
+
+	exports := []Export{}
+	for _, user := range users {
+		export := Export{
+			Username:       user.Username,
+			ServiceAccount: ServiceAccountName(user.Username),
+			Roles:          map[string]string{},
+		}
+		if user.Role == "admin" {
+			for _, namespace := range epinioNamespaces {
+				export.Roles[namespace] = auth.NamespaceRoleAdmin
+			}
+		} else {
+			for _, namespace := range user.Namespaces {
+				if known[namespace] {
+					export.Roles[namespace] = user.NamespaceRole(namespace)
+				}
+			}
+		}
+		exports = append(exports, export)
+	}
+
+	return exports
+}
+
+// Sync makes the service accounts, in the namespace, and role bindings, match the exports.
+// Those of removed users, and namespaces, are removed.
+func Sync(ctx context.Context, client kubeclient.Interface, namespace string, exports []Export) error {
+	selector := metav1.ListOptions{LabelSelector: ExportLabelKey + "=" + ExportLabelValue}
+
+	wantedAccounts := map[string]Export{}
+	wantedBindings := map[string]*rbacv1.RoleBinding{}
+	for _, export := range exports {
+		wantedAccounts[export.ServiceAccount] = export
+		for ns, role := range export.Roles {
+			binding := roleBinding(namespace, ns, export, role)
+			wantedBindings[ns+"/"+binding.Name] = binding
+		}
+	}
+
+	// Service accounts
+	accounts := client.CoreV1().ServiceAccounts(namespace)
+	existingAccounts, err := accounts.List(ctx, selector)
+	if err != nil {
+		return errors.Wrap(err, "listing the exported service accounts")
+	}
+	for _, account := range existingAccounts.Items {
+		if _, ok := wantedAccounts[account.Name]; ok {
+			delete(wantedAccounts, account.Name)
+			continue
+		}
+		err := accounts.Delete(ctx, account.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "removing service account %s", account.Name)
+		}
+	}
+	for name, export := range wantedAccounts {
+		_, err := accounts.Create(ctx, &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      exportLabels(),
+				Annotations: map[string]string{UsernameAnnotation: export.Username},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "creating service account %s", name)
+		}
+	}
+
+	// Role bindings, in all namespaces
+	existingBindings, err := client.RbacV1().RoleBindings("").List(ctx, selector)
+	if err != nil {
+		return errors.Wrap(err, "listing the exported role bindings")
+	}
+	for _, binding := range existingBindings.Items {
+		bindings := client.RbacV1().RoleBindings(binding.Namespace)
+		key := binding.Namespace + "/" + binding.Name
+
+		wanted, ok := wantedBindings[key]
+		if ok && wanted.RoleRef == binding.RoleRef {
+			delete(wantedBindings, key)
+			continue
+		}
+
+		// The role of a binding cannot be changed, it is replaced.
+		err := bindings.Delete(ctx, binding.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "removing role binding %s", key)
+		}
+	}
+	for key, binding := range wantedBindings {
+		_, err := client.RbacV1().RoleBindings(binding.Namespace).Create(ctx, binding, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "creating role binding %s", key)
+		}
+	}
+
+	return nil
+}
+
+// ExportUsers mirrors the users of the cluster, with the access given by their teams.
+func ExportUsers(ctx context.Context, cluster *kubernetes.Cluster, namespace string) ([]Export, error) {
+	authService, err := auth.NewAuthServiceFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := authService.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := authService.GetTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, user := range users {
+		users[i] = auth.WithTeams(user, teams, nil)
+	}
+
+	namespaceList, err := cluster.Kubectl.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
+		LabelSelector: kubernetes.EpinioNamespaceLabelKey + "=" + kubernetes.EpinioNamespaceLabelValue,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the namespaces")
+	}
+	epinioNamespaces := []string{}
+	for _, ns := range namespaceList.Items {
+		epinioNamespaces = append(epinioNamespaces, ns.Name)
+	}
+
+	exports := Exports(users, epinioNamespaces)
+	return exports, Sync(ctx, cluster.Kubectl, namespace, exports)
+}
+
+// ExportLoop mirrors the users every minute, until the context is done.
+func ExportLoop(ctx context.Context, logger logr.Logger, namespace string) {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		cluster, err := kubernetes.GetCluster(ctx)
+		if err != nil {
+			logger.Error(err, "rbac export: no cluster")
+		} else if _, err := ExportUsers(ctx, cluster, namespace); err != nil {
+			logger.Error(err, "rbac export failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func roleBinding(namespace, bindingNamespace string, export Export, role string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        export.ServiceAccount,
+			Namespace:   bindingNamespace,
+			Labels:      exportLabels(),
+			Annotations: map[string]string{UsernameAnnotation: export.Username},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     ClusterRoles[role],
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      export.ServiceAccount,
+			Namespace: namespace,
+		}},
+	}
+}
+
+func exportLabels() map[string]string {
+	return map[string]string{
+		ExportLabelKey:                 ExportLabelValue,
+		"app.kubernetes.io/managed-by": "epinio",
+	}
+}
@@ -0,0 +1,102 @@
+package rbac_test
+
+import (
+	"context"
+
+	"github.com/epinio/epinio/internal/auth"
+	"github.com/epinio/epinio/internal/rbac"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("RBAC export", func() {
+	users := []auth.User{
+		{Username: "admin", Role: "admin"},
+		{
+			Username:       "jane",
+			Role:           "user",
+			Namespaces:     []string{"workspace", "prod", "gone"},
+			NamespaceRoles: map[string]string{"prod": auth.NamespaceRoleReader},
+		},
+	}
+
+	Describe("Exports", func() {
+		It("maps the roles of the users in the Epinio namespaces", func() {
+			exports := rbac.Exports(users, []string{"workspace", "prod"})
+			Expect(exports).To(HaveLen(2))
+
+			Expect(exports[0].Username).To(Equal("admin"))
+			Expect(exports[0].Roles).To(Equal(map[string]string{
+				"workspace": auth.NamespaceRoleAdmin,
+				"prod":      auth.NamespaceRoleAdmin,
+			}))
+
+			Expect(exports[1].ServiceAccount).To(Equal(rbac.ServiceAccountName("jane")))
+			Expect(exports[1].Roles).To(Equal(map[string]string{
+				"workspace": auth.NamespaceRoleAdmin,
+				"prod":      auth.NamespaceRoleReader,
+			}))
+		})
+	})
+
+	Describe("Sync", func() {
+		var client *fake.Clientset
+		ctx := context.Background()
+
+		bindings := func() map[string]string {
+			list, err := client.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			result := map[string]string{}
+			for _, binding := range list.Items {
+				result[binding.Namespace+"/"+binding.Name] = binding.RoleRef.Name
+			}
+			return result
+		}
+
+		BeforeEach(func() {
+			client = fake.NewSimpleClientset()
+		})
+
+		It("binds the service accounts of the users to the cluster roles", func() {
+			Expect(rbac.Sync(ctx, client, "epinio", rbac.Exports(users, []string{"workspace", "prod"}))).To(Succeed())
+
+			accounts, err := client.CoreV1().ServiceAccounts("epinio").List(ctx, metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(accounts.Items).To(HaveLen(2))
+
+			jane := rbac.ServiceAccountName("jane")
+			Expect(bindings()).To(HaveKeyWithValue("workspace/"+jane, "admin"))
+			Expect(bindings()).To(HaveKeyWithValue("prod/"+jane, "view"))
+
+			binding, err := client.RbacV1().RoleBindings("prod").Get(ctx, jane, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(binding.Subjects).To(Equal([]rbacv1.Subject{{
+				Kind: rbacv1.ServiceAccountKind, Name: jane, Namespace: "epinio",
+			}}))
+		})
+
+		It("replaces changed roles, and removes those of removed users", func() {
+			Expect(rbac.Sync(ctx, client, "epinio", rbac.Exports(users, []string{"workspace", "prod"}))).To(Succeed())
+
+			changed := []auth.User{{
+				Username:       "jane",
+				Role:           "user",
+				Namespaces:     []string{"prod"},
+				NamespaceRoles: map[string]string{"prod": auth.NamespaceRoleDeveloper},
+			}}
+			Expect(rbac.Sync(ctx, client, "epinio", rbac.Exports(changed, []string{"workspace", "prod"}))).To(Succeed())
+
+			Expect(bindings()).To(Equal(map[string]string{
+				"prod/" + rbac.ServiceAccountName("jane"): "edit",
+			}))
+
+			accounts, err := client.CoreV1().ServiceAccounts("epinio").List(ctx, metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(accounts.Items).To(HaveLen(1))
+			Expect(accounts.Items[0].Annotations).To(HaveKeyWithValue(rbac.UsernameAnnotation, "jane"))
+		})
+	})
+})
@@ -0,0 +1,322 @@
+package registry
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"runtime"
+	"strings"
+
+	parser "github.com/novln/docker-parser"
+	"github.com/pkg/errors"
+)
+
+// Media types of the image manifests understood by Image.
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// descriptor references a blob, or manifest, of an image.
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+// manifest is the part of image manifests, and manifest lists, needed to fetch the image.
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    descriptor   `json:"config"`
+	Layers    []descriptor `json:"layers"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+// Image is an image in a registry, ready to be exported, see OpenImage.
+type Image struct {
+	URL      string
+	client   *http.Client
+	base     string
+	username string
+	password string
+	token    string
+	manifest manifest
+}
+
+// OpenImage locates the image in its registry, using the credentials of the connection
+// details for that registry, if any. The certificate authority, if any, is trusted for the
+// connection to the registry, on top of the system ones.
+func (d *ConnectionDetails) OpenImage(ctx context.Context, imageURL string, ca []byte) (*Image, error) {
+	ref, err := parser.Parse(imageURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad image reference '%s'", imageURL)
+	}
+
+	host := ref.Registry()
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(ca) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM(ca)
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	image := &Image{
+		URL:    imageURL,
+		client: &http.Client{Transport: transport},
+		base:   fmt.Sprintf("https://%s/v2/%s", host, ref.ShortName()),
+	}
+	for _, credentials := range d.RegistryCredentials {
+		if strings.SplitN(credentials.URL, "/", 2)[0] == ref.Registry() {
+			image.username = credentials.Username
+			image.password = credentials.Password
+			break
+		}
+	}
+
+	tag := ref.Tag()
+	if tag == "" {
+		tag = "latest"
+	}
+
+	image.manifest, err = image.fetchManifest(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Choose the image for the platform of the server, or the first, from manifest lists.
+	if image.manifest.MediaType == mediaTypeDockerManifestList || image.manifest.MediaType == mediaTypeOCIIndex {
+		if len(image.manifest.Manifests) == 0 {
+			return nil, errors.Errorf("image '%s' has no manifests", imageURL)
+		}
+		chosen := image.manifest.Manifests[0]
+		for _, m := range image.manifest.Manifests {
+			if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+				chosen = m
+				break
+			}
+		}
+		image.manifest, err = image.fetchManifest(ctx, chosen.Digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return image, nil
+}
+
+// Export writes the image to the writer, as a tarball loadable by `docker load`.
+func (i *Image) Export(ctx context.Context, w io.Writer) error {
+	archive := tar.NewWriter(w)
+
+	configFile := digestHex(i.manifest.Config.Digest) + ".json"
+	if err := i.exportBlob(ctx, archive, configFile, i.manifest.Config); err != nil {
+		return err
+	}
+
+	layerFiles := []string{}
+	for _, layer := range i.manifest.Layers {
+		layerFile := digestHex(layer.Digest) + "/layer.tar"
+		if err := i.exportBlob(ctx, archive, layerFile, layer); err != nil {
+			return err
+		}
+		layerFiles = append(layerFiles, layerFile)
+	}
+
+	index, err := json.Marshal([]struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}{{
+		Config:   configFile,
+		RepoTags: []string{i.URL},
+		Layers:   layerFiles,
+	}})
+	if err != nil {
+		return err
+	}
+	if err := archive.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0644, Size: int64(len(index))}); err != nil {
+		return err
+	}
+	if _, err := archive.Write(index); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// exportBlob writes the blob into the archive, under the given name.
+func (i *Image) exportBlob(ctx context.Context, archive *tar.Writer, name string, blob descriptor) error {
+	response, err := i.get(ctx, "/blobs/"+blob.Digest, "")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if err := archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: blob.Size}); err != nil {
+		return err
+	}
+	_, err = io.CopyN(archive, response.Body, blob.Size)
+	return errors.Wrapf(err, "copying blob %s", blob.Digest)
+}
+
+// fetchManifest returns the manifest of the given tag, or digest, of the image.
+func (i *Image) fetchManifest(ctx context.Context, reference string) (manifest, error) {
+	var result manifest
+
+	response, err := i.get(ctx, "/manifests/"+reference, strings.Join([]string{
+		mediaTypeDockerManifest, mediaTypeDockerManifestList,
+		mediaTypeOCIManifest, mediaTypeOCIIndex,
+	}, ", "))
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return result, errors.Wrap(err, "bad image manifest")
+	}
+	if result.MediaType == "" {
+		result.MediaType = response.Header.Get("Content-Type")
+	}
+
+	return result, nil
+}
+
+// get requests the path of the image from the registry. Registries asking for a bearer token
+// are given one, which is kept for the following requests.
+func (i *Image) get(ctx context.Context, path, accept string) (*http.Response, error) {
+	response, err := i.request(ctx, path, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized && i.token == "" {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, errors.Errorf("registry denied access to %s", i.URL)
+		}
+		if err := i.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+
+		response, err = i.request(ctx, path, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, errors.Errorf("registry returned '%s' for %s", response.Status, i.URL)
+	}
+
+	return response, nil
+}
+
+// request makes a single request for the path of the image, with the known credentials.
+func (i *Image) request(ctx context.Context, path, accept string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, i.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if i.token != "" {
+		request.Header.Set("Authorization", "Bearer "+i.token)
+	} else if i.username != "" {
+		request.SetBasicAuth(i.username, i.password)
+	}
+
+	response, err := i.client.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", i.URL)
+	}
+	return response, nil
+}
+
+// challengeParam matches the parameters of a bearer challenge.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate gets the bearer token asked for by the challenge of the registry, using the
+// known credentials.
+func (i *Image) authenticate(ctx context.Context, challenge string) error {
+	params := map[string]string{}
+	for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return errors.New("registry asked for a token without realm")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"], nil)
+	if err != nil {
+		return err
+	}
+	query := request.URL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	request.URL.RawQuery = query.Encode()
+	if i.username != "" {
+		request.SetBasicAuth(i.username, i.password)
+	}
+
+	response, err := i.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "getting a registry token")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("registry token request returned '%s'", response.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return errors.Wrap(err, "bad registry token")
+	}
+
+	i.token = token.Token
+	if i.token == "" {
+		i.token = token.AccessToken
+	}
+	if i.token == "" {
+		return errors.New("registry returned no token")
+	}
+
+	return nil
+}
+
+// digestHex returns the hex part of the digest, i.e. without the algorithm.
+func digestHex(digest string) string {
+	if pieces := strings.SplitN(digest, ":", 2); len(pieces) == 2 {
+		return pieces[1]
+	}
+	return digest
+}
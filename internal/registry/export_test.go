@@ -0,0 +1,112 @@
+package registry_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/epinio/epinio/internal/registry"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image", func() {
+	var server *httptest.Server
+	var ca []byte
+	var details *registry.ConnectionDetails
+
+	BeforeEach(func() {
+		blobs := map[string]string{
+			"sha256:c0ff1e": `{"architecture":"amd64"}`,
+			"sha256:1a7e51": "layer-one",
+		}
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "epinio" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch {
+			case r.URL.Path == "/v2/apps/sample/manifests/1":
+				w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+				_, _ = w.Write([]byte(`{
+					"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+					"config": {"digest": "sha256:c0ff1e", "size": 24},
+					"layers": [{"digest": "sha256:1a7e51", "size": 9}]
+				}`))
+			case strings.HasPrefix(r.URL.Path, "/v2/apps/sample/blobs/"):
+				blob, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/apps/sample/blobs/")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(blob))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		details = &registry.ConnectionDetails{
+			RegistryCredentials: []registry.RegistryCredentials{{
+				URL:      strings.TrimPrefix(server.URL, "https://"),
+				Username: "epinio",
+				Password: "secret",
+			}},
+		}
+	})
+
+	It("exports the image as docker archive", func() {
+		imageURL := strings.TrimPrefix(server.URL, "https://") + "/apps/sample:1"
+
+		image, err := details.OpenImage(context.Background(), imageURL, ca)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out bytes.Buffer
+		Expect(image.Export(context.Background(), &out)).To(Succeed())
+
+		files := map[string]string{}
+		archive := tar.NewReader(&out)
+		for {
+			header, err := archive.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			content, err := io.ReadAll(archive)
+			Expect(err).ToNot(HaveOccurred())
+			files[header.Name] = string(content)
+		}
+
+		Expect(files).To(HaveKeyWithValue("c0ff1e.json", `{"architecture":"amd64"}`))
+		Expect(files).To(HaveKeyWithValue("1a7e51/layer.tar", "layer-one"))
+
+		var index []struct {
+			Config   string
+			RepoTags []string
+			Layers   []string
+		}
+		Expect(json.Unmarshal([]byte(files["manifest.json"]), &index)).To(Succeed())
+		Expect(index).To(HaveLen(1))
+		Expect(index[0].Config).To(Equal("c0ff1e.json"))
+		Expect(index[0].RepoTags).To(Equal([]string{imageURL}))
+		Expect(index[0].Layers).To(Equal([]string{"1a7e51/layer.tar"}))
+	})
+
+	It("fails for unknown images", func() {
+		imageURL := strings.TrimPrefix(server.URL, "https://") + "/apps/other:1"
+
+		_, err := details.OpenImage(context.Background(), imageURL, ca)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -2,15 +2,51 @@ package routes
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+const (
+	// WildcardPrefix starts the domain of a route matching all subdomains of the rest of
+	// the domain, e.g. "*.tenant.example.com".
+	WildcardPrefix = "*."
+
+	// optionsSeparator separates the options of a route from its domain and path, e.g.
+	// "example.com/api?rewrite=/&tls-secret=corp-cert". Options are separated by "&".
+	optionsSeparator = "?"
+
+	// RewriteOption holds the rewrite target of a route. Requests under the path of such a
+	// route reach the application with the path prefix replaced by the target.
+	RewriteOption = "rewrite"
+
+	// TLSSecretOption names an existing kube TLS secret holding the certificate of the
+	// route, used instead of a certificate from the cluster issuer.
+	TLSSecretOption = "tls-secret"
+
+	// ProtocolOption selects the protocol spoken by the application behind the route, see
+	// ProtocolHTTP and ProtocolGRPC. Without it the route is plain HTTP.
+	ProtocolOption = "protocol"
+
+	// ProtocolHTTP is the protocol of plain HTTP applications, the default.
+	ProtocolHTTP = "http"
+
+	// ProtocolGRPC is the protocol of gRPC applications, served as HTTP/2 without TLS
+	// (h2c) behind the ingress.
+	ProtocolGRPC = "grpc"
+)
+
+// Route is a host and path prefix routed to an application. Multiple applications can
+// share a domain by using different paths.
 type Route struct {
-	Domain string
-	Path   string
+	Domain    string
+	Path      string
+	Rewrite   string
+	TLSSecret string
+	Protocol  string
 }
 
 // String returns the string representation of a Route object.
@@ -20,8 +56,100 @@ type Route struct {
 // also removes trailing "/". E.g.
 // Route{ Domain: "mydomain.org", Path: "/" }
 // becomes: "mydomain.org" (no trailing "/")
+// Rewrite target, TLS secret, and protocol are appended as options. E.g.
+// Route{ Domain: "mydomain.org", Path: "/api", Rewrite: "/", TLSSecret: "cert" }
+// becomes: "mydomain.org/api?rewrite=/&tls-secret=cert"
 func (r Route) String() string {
-	return strings.TrimSuffix(r.Domain+r.Path, "/")
+	result := strings.TrimSuffix(r.Domain+r.Path, "/")
+
+	options := []string{}
+	if r.Rewrite != "" {
+		options = append(options, RewriteOption+"="+r.Rewrite)
+	}
+	if r.TLSSecret != "" {
+		options = append(options, TLSSecretOption+"="+r.TLSSecret)
+	}
+	if r.Protocol != "" {
+		options = append(options, ProtocolOption+"="+r.Protocol)
+	}
+	if len(options) > 0 {
+		result += optionsSeparator + strings.Join(options, "&")
+	}
+	return result
+}
+
+// ID returns an identifier for the route, usable in the names of kube resources. The
+// wildcard of a domain becomes "wildcard", and the options are not part of it.
+// E.g.
+// Route{ Domain: "*.mydomain.org", Path: "/api" }
+// becomes: "wildcard.mydomain.org.api"
+func (r Route) ID() string {
+	domain := r.Domain
+	if strings.HasPrefix(domain, WildcardPrefix) {
+		domain = "wildcard." + strings.TrimPrefix(domain, WildcardPrefix)
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(domain+r.Path, "/"), "/", ".")
+}
+
+// IsGRPC returns true if the application behind the route speaks gRPC.
+func (r Route) IsGRPC() bool {
+	return r.Protocol == ProtocolGRPC
+}
+
+// IsWildcard returns true if the route matches all subdomains of its domain.
+func (r Route) IsWildcard() bool {
+	return strings.HasPrefix(r.Domain, WildcardPrefix)
+}
+
+// Validate checks that the domain of the route is a DNS name, with at most a leading
+// wildcard, that the path and rewrite target are absolute, that the TLS secret is a
+// proper kube name, and that the protocol is known.
+func (r Route) Validate() error {
+	domain := strings.TrimPrefix(r.Domain, WildcardPrefix)
+	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+		return fmt.Errorf("bad route '%s': bad domain: %s", r.String(), errs[0])
+	}
+	if r.IsWildcard() && !strings.Contains(domain, ".") {
+		return fmt.Errorf("bad route '%s': wildcard of a top level domain", r.String())
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		return fmt.Errorf("bad route '%s': path does not start with '/'", r.String())
+	}
+	if strings.ContainsAny(r.Path, "*?") {
+		return fmt.Errorf("bad route '%s': path is a prefix, and without wildcards", r.String())
+	}
+	if r.Rewrite != "" && !strings.HasPrefix(r.Rewrite, "/") {
+		return fmt.Errorf("bad route '%s': rewrite target does not start with '/'", r.String())
+	}
+	if r.TLSSecret != "" {
+		if errs := validation.IsDNS1123Subdomain(r.TLSSecret); len(errs) > 0 {
+			return fmt.Errorf("bad route '%s': bad tls secret: %s", r.String(), errs[0])
+		}
+	}
+	switch r.Protocol {
+	case "", ProtocolHTTP, ProtocolGRPC:
+	default:
+		return fmt.Errorf("bad route '%s': unknown protocol '%s', expected %s, or %s",
+			r.String(), r.Protocol, ProtocolHTTP, ProtocolGRPC)
+	}
+	return nil
+}
+
+// ValidateRoutes checks that the route strings are proper routes, without unknown
+// options. See Route.Validate.
+func ValidateRoutes(routeStrs []string) error {
+	for _, routeStr := range routeStrs {
+		_, options := splitOptions(routeStr)
+		for key := range options {
+			if key != RewriteOption && key != TLSSecretOption && key != ProtocolOption {
+				return fmt.Errorf("bad route '%s': unknown option '%s'", routeStr, key)
+			}
+		}
+		if err := FromString(routeStr).Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ToIngress  returns an Ingress resource for this route
@@ -49,9 +177,18 @@ func (r Route) ToIngress(ingressName string) networkingv1.Ingress {
 // E.g.
 // mydomain.org/api
 // becomes: Route{ Domain: "mydomain.org", Path: "/api" }
+// and
+// mydomain.org/api?rewrite=/v2&tls-secret=cert
+// becomes: Route{ Domain: "mydomain.org", Path: "/api", Rewrite: "/v2", TLSSecret: "cert" }
+// and
+// grpc.mydomain.org?protocol=grpc
+// becomes: Route{ Domain: "grpc.mydomain.org", Path: "/", Protocol: "grpc" }
+// Unknown options are ignored.
 func FromString(routeStr string) Route {
 	var domain, path string
 
+	routeStr, options := splitOptions(routeStr)
+
 	splitRoute := strings.SplitN(routeStr, "/", 2)
 	domain = splitRoute[0]
 	if len(splitRoute) > 1 {
@@ -59,7 +196,37 @@ func FromString(routeStr string) Route {
 	} else {
 		path = "/"
 	}
-	return Route{Domain: domain, Path: path}
+	return Route{
+		Domain:    domain,
+		Path:      path,
+		Rewrite:   options[RewriteOption],
+		TLSSecret: options[TLSSecretOption],
+		Protocol:  options[ProtocolOption],
+	}
+}
+
+// splitOptions splits the route string into domain and path, and the options.
+func splitOptions(routeStr string) (string, map[string]string) {
+	options := map[string]string{}
+
+	splitRoute := strings.SplitN(routeStr, optionsSeparator, 2)
+	if len(splitRoute) < 2 {
+		return routeStr, options
+	}
+
+	for _, option := range strings.Split(splitRoute[1], "&") {
+		if option == "" {
+			continue
+		}
+		keyValue := strings.SplitN(option, "=", 2)
+		if len(keyValue) < 2 {
+			options[keyValue[0]] = ""
+		} else {
+			options[keyValue[0]] = keyValue[1]
+		}
+	}
+
+	return splitRoute[0], options
 }
 
 // FromIngress returns a Route resource matching the given Ingress
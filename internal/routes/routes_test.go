@@ -33,6 +33,33 @@ var _ = Describe("Route", func() {
 				}))
 			})
 		})
+		When("a rewrite target is given", func() {
+			BeforeEach(func() {
+				routeStr = "*.mydomain.org/api?rewrite=/v2"
+			})
+
+			It("constructs a Route object with the rewrite target", func() {
+				Expect(FromString(routeStr)).To(Equal(Route{
+					Domain:  "*.mydomain.org",
+					Path:    "/api",
+					Rewrite: "/v2",
+				}))
+			})
+		})
+		When("multiple options are given", func() {
+			BeforeEach(func() {
+				routeStr = "mydomain.org?tls-secret=corp-cert&rewrite=/v2"
+			})
+
+			It("constructs a Route object with all options", func() {
+				Expect(FromString(routeStr)).To(Equal(Route{
+					Domain:    "mydomain.org",
+					Path:      "/",
+					Rewrite:   "/v2",
+					TLSSecret: "corp-cert",
+				}))
+			})
+		})
 	})
 
 	Describe("FromIngress", func() {
@@ -136,6 +163,31 @@ var _ = Describe("Route", func() {
 				Expect(route.String()).To(Equal("somedomain.org"))
 			})
 		})
+		When("there is a rewrite target", func() {
+			BeforeEach(func() {
+				route.Rewrite = "/"
+			})
+			It("appends it as option", func() {
+				Expect(route.String()).To(Equal("somedomain.org/somepath?rewrite=/"))
+			})
+		})
+		When("there is a tls secret", func() {
+			BeforeEach(func() {
+				route.Rewrite = "/"
+				route.TLSSecret = "corp-cert"
+			})
+			It("appends it after the rewrite target", func() {
+				Expect(route.String()).To(Equal("somedomain.org/somepath?rewrite=/&tls-secret=corp-cert"))
+			})
+		})
+		When("there is a protocol", func() {
+			BeforeEach(func() {
+				route.Protocol = "grpc"
+			})
+			It("appends it as option", func() {
+				Expect(route.String()).To(Equal("somedomain.org/somepath?protocol=grpc"))
+			})
+		})
 		When("the path is \"/\"", func() {
 			BeforeEach(func() {
 				route.Path = "/"
@@ -145,4 +197,59 @@ var _ = Describe("Route", func() {
 			})
 		})
 	})
+	Describe("ID", func() {
+		It("turns the wildcard into a name", func() {
+			route := Route{Domain: "*.somedomain.org", Path: "/api", Rewrite: "/"}
+			Expect(route.ID()).To(Equal("wildcard.somedomain.org.api"))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("accepts wildcard domains, paths and rewrites", func() {
+			Expect(FromString("*.tenant.example.com/api?rewrite=/").Validate()).To(Succeed())
+			Expect(FromString("example.com").Validate()).To(Succeed())
+		})
+
+		It("rejects wildcards inside the domain", func() {
+			err := FromString("app.*.example.com").Validate()
+			Expect(err).To(MatchError(ContainSubstring("bad domain")))
+		})
+
+		It("rejects wildcards of top level domains", func() {
+			err := FromString("*.com").Validate()
+			Expect(err).To(MatchError(ContainSubstring("wildcard of a top level domain")))
+		})
+
+		It("rejects wildcards in the path", func() {
+			err := FromString("example.com/api/*").Validate()
+			Expect(err).To(MatchError(ContainSubstring("path is a prefix")))
+		})
+
+		It("rejects bad tls secret names", func() {
+			err := FromString("example.com?tls-secret=Corp_Cert").Validate()
+			Expect(err).To(MatchError(ContainSubstring("bad tls secret")))
+		})
+
+		It("rejects unknown protocols", func() {
+			err := FromString("example.com?protocol=ftp").Validate()
+			Expect(err).To(MatchError(ContainSubstring("unknown protocol 'ftp'")))
+		})
+
+		It("rejects relative rewrite targets", func() {
+			err := FromString("example.com/api?rewrite=v2").Validate()
+			Expect(err).To(MatchError(ContainSubstring("rewrite target does not start with '/'")))
+		})
+	})
+
+	Describe("ValidateRoutes", func() {
+		It("accepts known options", func() {
+			Expect(ValidateRoutes([]string{"example.com/api?rewrite=/&tls-secret=cert"})).To(Succeed())
+			Expect(ValidateRoutes([]string{"grpc.example.com?protocol=grpc"})).To(Succeed())
+		})
+
+		It("rejects unknown options", func() {
+			err := ValidateRoutes([]string{"example.com?timeout=5"})
+			Expect(err).To(MatchError(ContainSubstring("unknown option 'timeout'")))
+		})
+	})
 })
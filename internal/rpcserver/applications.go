@@ -0,0 +1,161 @@
+package rpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/epinio/epinio/pkg/api/core/v1/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// List returns the applications of the namespace.
+func (a *applications) List(ctx context.Context, in *rpc.ListApplicationsRequest) (*rpc.ListApplicationsResponse, error) {
+	client, err := a.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := client.Apps(in.Namespace)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result := &rpc.ListApplicationsResponse{Applications: []*rpc.Application{}}
+	for _, app := range apps {
+		result.Applications = append(result.Applications, toApplication(app))
+	}
+	return result, nil
+}
+
+// Get returns the application.
+func (a *applications) Get(ctx context.Context, in *rpc.GetApplicationRequest) (*rpc.Application, error) {
+	client, err := a.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := client.AppShow(in.Namespace, in.Name)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toApplication(app), nil
+}
+
+// Create creates the application, and returns it.
+func (a *applications) Create(ctx context.Context, in *rpc.CreateApplicationRequest) (*rpc.Application, error) {
+	if in.Instances < 0 {
+		return nil, status.Error(codes.InvalidArgument, "instances must not be negative")
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := models.ApplicationUpdateRequest{
+		Routes:         in.Routes,
+		Configurations: in.Configurations,
+		Environment:    in.Environment,
+	}
+	if in.Instances > 0 {
+		configuration.Instances = &in.Instances
+	}
+
+	_, err = client.AppCreate(models.ApplicationCreateRequest{
+		Name:          in.Name,
+		Configuration: configuration,
+	}, in.Namespace)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	app, err := client.AppShow(in.Namespace, in.Name)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toApplication(app), nil
+}
+
+// Scale sets the instances of the application, and returns it.
+func (a *applications) Scale(ctx context.Context, in *rpc.ScaleApplicationRequest) (*rpc.Application, error) {
+	if in.Instances < 0 {
+		return nil, status.Error(codes.InvalidArgument, "instances must not be negative")
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.AppUpdate(models.ApplicationUpdateRequest{Instances: &in.Instances}, in.Namespace, in.Name)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	app, err := client.AppShow(in.Namespace, in.Name)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toApplication(app), nil
+}
+
+// Restart restarts the application.
+func (a *applications) Restart(ctx context.Context, in *rpc.RestartApplicationRequest) (*rpc.RestartApplicationResponse, error) {
+	client, err := a.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.AppRestart(in.Namespace, in.Name); err != nil {
+		return nil, statusError(err)
+	}
+	return &rpc.RestartApplicationResponse{}, nil
+}
+
+// Delete deletes the application, and returns the configurations it was bound to.
+func (a *applications) Delete(ctx context.Context, in *rpc.DeleteApplicationRequest) (*rpc.DeleteApplicationResponse, error) {
+	client, err := a.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.AppDelete(in.Namespace, in.Name)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &rpc.DeleteApplicationResponse{UnboundConfigurations: response.UnboundConfigurations}, nil
+}
+
+// toApplication returns the gRPC message of the application.
+func toApplication(app models.App) *rpc.Application {
+	result := &rpc.Application{
+		Namespace:      app.Meta.Namespace,
+		Name:           app.Meta.Name,
+		Status:         string(app.Status),
+		StatusMessage:  app.StatusMessage,
+		Routes:         app.Configuration.Routes,
+		Image:          app.ImageURL,
+		StageId:        app.StageID,
+		Configurations: app.Configuration.Configurations,
+		Environment:    app.Configuration.Environment,
+	}
+	if !app.Meta.CreatedAt.IsZero() {
+		result.CreatedAt = app.Meta.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if app.Configuration.Instances != nil {
+		result.DesiredInstances = *app.Configuration.Instances
+	}
+
+	if app.Workload != nil {
+		result.DesiredInstances = app.Workload.DesiredReplicas
+		result.ReadyInstances = app.Workload.ReadyReplicas
+		result.Username = app.Workload.Username
+		if len(app.Workload.Routes) > 0 {
+			result.Routes = app.Workload.Routes
+		}
+	}
+
+	return result
+}
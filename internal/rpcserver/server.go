@@ -0,0 +1,119 @@
+// Package rpcserver serves the gRPC API, see the rpc package for its definitions. It is a
+// gateway to the REST API: each call is made as requests to the API server, with the
+// credentials of the caller. Both APIs authenticate, authorize, and audit the same, and the
+// REST API stays the one implementation of the operations.
+package rpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	epinioapi "github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/rpc"
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns the gRPC server of the API, making its calls to the REST API at the
+// URLs, the plain one and the websocket one.
+func NewServer(apiURL, wsURL string) *grpc.Server {
+	server := grpc.NewServer()
+	rpc.RegisterApplicationsServer(server, &applications{apiURL: apiURL, wsURL: wsURL})
+	return server
+}
+
+// Handler returns the handler of the gRPC server, serving HTTP/2 without TLS, i.e. h2c. Like
+// for the REST API, TLS is terminated in front of the server.
+func Handler(server *grpc.Server) http.Handler {
+	return h2c.NewHandler(server, &http2.Server{})
+}
+
+// applications serves the Applications service.
+type applications struct {
+	rpc.UnimplementedApplicationsServer
+
+	apiURL string
+	wsURL  string
+}
+
+// client returns the client of the REST API making the requests of the call, with the
+// credentials of the `authorization` metadata of the call.
+func (a *applications) client(ctx context.Context) (*epinioapi.Client, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "no authorization metadata")
+	}
+	authorization := values[0]
+
+	if token := strings.TrimPrefix(authorization, "Bearer "); token != authorization {
+		client := epinioapi.New(a.apiURL, a.wsURL, "", "")
+		client.SetToken(token)
+		return client, nil
+	}
+
+	if credentials := strings.TrimPrefix(authorization, "Basic "); credentials != authorization {
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "bad basic authorization")
+		}
+		userPassword := strings.SplitN(string(decoded), ":", 2)
+		if len(userPassword) != 2 {
+			return nil, status.Error(codes.Unauthenticated, "bad basic authorization")
+		}
+		return epinioapi.New(a.apiURL, a.wsURL, userPassword[0], userPassword[1]), nil
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "authorization is neither basic nor bearer")
+}
+
+// statusError returns the gRPC status of the error of a request to the REST API, with the
+// code matching the HTTP status of its response.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var response interface{ StatusCode() int }
+	if !errors.As(err, &response) {
+		if epinioapi.IsUnreachable(err) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code := codes.Internal
+	switch response.StatusCode() {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		code = codes.FailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		code = codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
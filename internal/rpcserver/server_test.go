@@ -0,0 +1,149 @@
+package rpcserver_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/epinio/epinio/internal/rpcserver"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/epinio/epinio/pkg/api/core/v1/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Applications", func() {
+	var api *httptest.Server
+	var server *grpc.Server
+	var conn *grpc.ClientConn
+	var client rpc.ApplicationsClient
+	var requests []*http.Request
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:password"))
+
+	BeforeEach(func() {
+		requests = []*http.Request{}
+		api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/versions" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			requests = append(requests, r)
+
+			switch {
+			case r.URL.Path == "/api/v1/namespaces/workspace/applications/missing":
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[{"status":404,"title":"application 'missing' does not exist"}]}`))
+			case r.URL.Path == "/api/v1/namespaces/other/applications":
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors":[{"status":403,"title":"user unauthorized"}]}`))
+			case r.Method == "GET" && r.URL.Path == "/api/v1/namespaces/workspace/applications":
+				instances := int32(2)
+				apps := models.AppList{{
+					Meta:          models.NewAppRef("app", "workspace"),
+					Configuration: models.ApplicationUpdateRequest{Instances: &instances, Routes: []string{"app.example.com"}},
+					Status:        models.ApplicationRunning,
+				}}
+				_ = json.NewEncoder(w).Encode(apps)
+			case r.Method == "GET" && r.URL.Path == "/api/v1/namespaces/workspace/applications/app":
+				_ = json.NewEncoder(w).Encode(models.App{
+					Meta: models.NewAppRef("app", "workspace"),
+					Workload: &models.AppDeployment{
+						DesiredReplicas: 3,
+						ReadyReplicas:   1,
+						Username:        "admin",
+					},
+				})
+			case r.Method == "POST" && r.URL.Path == "/api/v1/namespaces/workspace/applications/app/restart":
+				_, _ = w.Write([]byte(`{"status":"ok"}`))
+			default:
+				w.WriteHeader(http.StatusNotImplemented)
+			}
+		}))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		server = rpcserver.NewServer(api.URL, "")
+		go func() { _ = server.Serve(listener) }()
+
+		conn, err = grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).ToNot(HaveOccurred())
+		client = rpc.NewApplicationsClient(conn)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		server.Stop()
+		api.Close()
+	})
+
+	authorized := func(authorization string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", authorization)
+	}
+
+	It("lists the applications with the credentials of the caller", func() {
+		response, err := client.List(authorized(basic), &rpc.ListApplicationsRequest{Namespace: "workspace"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Applications).To(HaveLen(1))
+		Expect(response.Applications[0].Name).To(Equal("app"))
+		Expect(response.Applications[0].Status).To(Equal("running"))
+		Expect(response.Applications[0].DesiredInstances).To(Equal(int32(2)))
+		Expect(response.Applications[0].Routes).To(Equal([]string{"app.example.com"}))
+
+		Expect(requests).To(HaveLen(1))
+		user, password, ok := requests[0].BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(user).To(Equal("admin"))
+		Expect(password).To(Equal("password"))
+	})
+
+	It("forwards bearer tokens", func() {
+		app, err := client.Get(authorized("Bearer token"), &rpc.GetApplicationRequest{Namespace: "workspace", Name: "app"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(app.DesiredInstances).To(Equal(int32(3)))
+		Expect(app.ReadyInstances).To(Equal(int32(1)))
+		Expect(app.Username).To(Equal("admin"))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("restarts the application", func() {
+		_, err := client.Restart(authorized(basic), &rpc.RestartApplicationRequest{Namespace: "workspace", Name: "app"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal("POST"))
+	})
+
+	It("maps the HTTP status of errors to codes", func() {
+		_, err := client.Get(authorized(basic), &rpc.GetApplicationRequest{Namespace: "workspace", Name: "missing"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+
+		_, err = client.List(authorized(basic), &rpc.ListApplicationsRequest{Namespace: "other"})
+		Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+	})
+
+	It("rejects calls without credentials", func() {
+		_, err := client.List(context.Background(), &rpc.ListApplicationsRequest{Namespace: "workspace"})
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+		_, err = client.List(authorized("Digest whatever"), &rpc.ListApplicationsRequest{Namespace: "workspace"})
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("rejects negative instances", func() {
+		_, err := client.Scale(authorized(basic), &rpc.ScaleApplicationRequest{Namespace: "workspace", Name: "app", Instances: -1})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		Expect(requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,361 @@
+package rpcserver
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/epinio/epinio/helpers/kubernetes/tailer"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/epinio/epinio/pkg/api/core/v1/rpc"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// Steps, and phases, of a push, for the events of Push. See usercmd.PushEvent, they are the
+// same as those of `epinio push --output json`.
+const (
+	pushEventCreate = "create"
+	pushEventUpload = "upload"
+	pushEventStage  = "stage"
+	pushEventDeploy = "deploy"
+	pushEventRoute  = "route"
+	pushEventDone   = "done"
+
+	pushPhaseStarted  = "started"
+	pushPhaseQueued   = "queued"
+	pushPhaseRunning  = "running"
+	pushPhaseFinished = "finished"
+)
+
+// Push creates the application, if it does not exist, uploads, and stages its sources, if
+// any, and deploys it, like `epinio push`, reporting the progress to the stream.
+func (a *applications) Push(stream rpc.Applications_PushServer) error {
+	ctx := stream.Context()
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "no push request")
+	}
+	if err != nil {
+		return err
+	}
+	if first.Namespace == "" || first.Name == "" {
+		return status.Error(codes.InvalidArgument, "the first push request has to name the application")
+	}
+	appRef := models.NewAppRef(first.Name, first.Namespace)
+
+	// The sources are collected into a tarball, for the upload.
+	tarball := ""
+	if first.Image == "" {
+		file, err := os.CreateTemp("", "epinio-push")
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		tarball = file.Name()
+		defer os.Remove(tarball)
+
+		err = receiveSources(stream, first, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The logs of the staging are sent while the steps go on.
+	var mutex sync.Mutex
+	send := func(event *rpc.PushEvent) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return stream.Send(event)
+	}
+
+	_, err = client.AppCreate(models.ApplicationCreateRequest{Name: appRef.Name}, appRef.Namespace)
+	if err == nil {
+		if err := send(&rpc.PushEvent{Event: pushEventCreate, Phase: pushPhaseFinished}); err != nil {
+			return err
+		}
+	} else if status.Code(statusError(err)) != codes.AlreadyExists {
+		return statusError(err)
+	}
+
+	deployRequest := models.DeployRequest{
+		App:      appRef,
+		ImageURL: first.Image,
+		Origin:   models.ApplicationOrigin{Kind: models.OriginContainer, Container: first.Image},
+	}
+
+	if tarball != "" {
+		if err := send(&rpc.PushEvent{Event: pushEventUpload, Phase: pushPhaseStarted}); err != nil {
+			return err
+		}
+		upload, err := client.AppUpload(appRef.Namespace, appRef.Name, tarball, nil)
+		if err != nil {
+			return statusError(err)
+		}
+		if err := send(&rpc.PushEvent{Event: pushEventUpload, Phase: pushPhaseFinished}); err != nil {
+			return err
+		}
+
+		stage, err := client.AppStage(models.StageRequest{
+			App:          appRef,
+			BlobUID:      upload.BlobUID,
+			BuilderImage: first.BuilderImage,
+		})
+		if err != nil {
+			return statusError(err)
+		}
+		stageID := stage.Stage.ID
+
+		if err := send(&rpc.PushEvent{Event: pushEventStage, Phase: pushPhaseStarted, StageId: stageID}); err != nil {
+			return err
+		}
+		if stage.QueuePosition > 0 {
+			event := &rpc.PushEvent{Event: pushEventStage, Phase: pushPhaseQueued, StageId: stageID, Position: int32(stage.QueuePosition)}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+
+		logsCtx, stopLogs := context.WithCancel(ctx)
+		var logs sync.WaitGroup
+		logs.Add(1)
+		go func() {
+			defer logs.Done()
+			_ = client.AppLogsContext(logsCtx, appRef.Namespace, appRef.Name, stageID, true, models.LogParameters{},
+				func(line tailer.ContainerLogLine) {
+					_ = send(&rpc.PushEvent{Event: pushEventStage, Phase: pushPhaseRunning, StageId: stageID, Message: line.Message})
+				})
+		}()
+
+		_, err = client.StagingComplete(appRef.Namespace, stageID)
+		stopLogs()
+		logs.Wait()
+		if err != nil {
+			return statusError(err)
+		}
+		if err := send(&rpc.PushEvent{Event: pushEventStage, Phase: pushPhaseFinished, StageId: stageID}); err != nil {
+			return err
+		}
+
+		// The sources are on the server only, there is no path of the client to record
+		// as origin.
+		deployRequest.ImageURL = stage.ImageURL
+		deployRequest.Stage = models.StageRef{ID: stageID}
+		deployRequest.Origin = models.ApplicationOrigin{}
+	}
+
+	if err := send(&rpc.PushEvent{Event: pushEventDeploy, Phase: pushPhaseStarted}); err != nil {
+		return err
+	}
+	deployed, err := client.AppDeploy(deployRequest)
+	if err != nil {
+		return statusError(err)
+	}
+	if _, err := client.AppRunning(appRef); err != nil {
+		return statusError(err)
+	}
+	if err := send(&rpc.PushEvent{Event: pushEventDeploy, Phase: pushPhaseFinished}); err != nil {
+		return err
+	}
+
+	for _, route := range deployed.Routes {
+		if err := send(&rpc.PushEvent{Event: pushEventRoute, Route: route}); err != nil {
+			return err
+		}
+	}
+	return send(&rpc.PushEvent{Event: pushEventDone})
+}
+
+// receiveSources writes the sources of the push requests to the file, until the client
+// closes its side of the stream.
+func receiveSources(stream rpc.Applications_PushServer, first *rpc.PushRequest, file io.Writer) error {
+	received := 0
+	for request := first; ; {
+		if _, err := file.Write(request.Source); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		received += len(request.Source)
+
+		var err error
+		request, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if received == 0 {
+		return status.Error(codes.InvalidArgument, "push of neither image nor sources")
+	}
+	return nil
+}
+
+// Logs streams the logs of the application, or of its staging.
+func (a *applications) Logs(in *rpc.LogsRequest, stream rpc.Applications_LogsServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The logs are streamed over a websocket, which tells nothing about its failure. The
+	// application is looked up first, for a missing one, or missing permissions, to fail
+	// with their status.
+	if in.StageId == "" {
+		if _, err := client.AppShow(in.Namespace, in.Name); err != nil {
+			return statusError(err)
+		}
+	}
+
+	params := models.LogParameters{
+		Instance: in.Instance,
+		Since:    time.Duration(in.SinceSeconds) * time.Second,
+		Filter:   in.Filter,
+	}
+	if in.Tail > 0 {
+		params.Tail = &in.Tail
+	}
+
+	var sendErr error
+	err = client.AppLogsContext(ctx, in.Namespace, in.Name, in.StageId, in.Follow, params,
+		func(line tailer.ContainerLogLine) {
+			if sendErr != nil {
+				return
+			}
+			sendErr = stream.Send(&rpc.LogLine{
+				Message:   line.Message,
+				Namespace: line.Namespace,
+				Instance:  line.PodName,
+				Container: line.ContainerName,
+			})
+			if sendErr != nil {
+				cancel()
+			}
+		})
+	if sendErr != nil {
+		return sendErr
+	}
+	return statusError(err)
+}
+
+// Exec runs a shell in an instance of the application, with the input of the requests, and
+// the output sent back, until the shell exits.
+func (a *applications) Exec(stream rpc.Applications_ExecServer) error {
+	ctx := stream.Context()
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "no exec request")
+	}
+	if err != nil {
+		return err
+	}
+	if first.Namespace == "" || first.Name == "" {
+		return status.Error(codes.InvalidArgument, "the first exec request has to name the application")
+	}
+
+	stdin, stdinWriter := io.Pipe()
+	defer stdin.Close()
+	sizes := make(terminalSizes, 1)
+
+	// The requests are read in the background, the input of the shell ends with them.
+	go func() {
+		defer stdinWriter.Close()
+		defer close(sizes)
+
+		for request := first; ; {
+			if request.Resize != nil {
+				size := remotecommand.TerminalSize{
+					Width:  uint16(request.Resize.Width),
+					Height: uint16(request.Resize.Height),
+				}
+				select {
+				case sizes <- size:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(request.Stdin) > 0 {
+				if _, err := stdinWriter.Write(request.Stdin); err != nil {
+					return
+				}
+			}
+
+			var err error
+			request, err = stream.Recv()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	stdout := &execOutput{stream: stream}
+	err = client.AppExecStream(first.Namespace, first.Name, first.Instance, remotecommand.StreamOptions{
+		Stdin:             stdin,
+		Stdout:            stdout,
+		Tty:               true,
+		TerminalSizeQueue: sizes,
+	})
+
+	var exitErr exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return stdout.send(&rpc.ExecResponse{Exited: true, ExitCode: int32(exitErr.ExitStatus())})
+	case err != nil:
+		return statusError(err)
+	}
+	return stdout.send(&rpc.ExecResponse{Exited: true})
+}
+
+// terminalSizes is the queue of the sizes of the terminal of a shell.
+type terminalSizes chan remotecommand.TerminalSize
+
+// Next returns the next size of the terminal, or nil when there are no more.
+func (s terminalSizes) Next() *remotecommand.TerminalSize {
+	size, ok := <-s
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+// execOutput sends the output of a shell to the stream.
+type execOutput struct {
+	mutex  sync.Mutex
+	stream rpc.Applications_ExecServer
+}
+
+func (o *execOutput) Write(p []byte) (int, error) {
+	if err := o.send(&rpc.ExecResponse{Stdout: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (o *execOutput) send(response *rpc.ExecResponse) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.stream.Send(response)
+}
@@ -3,12 +3,14 @@
 package s3manager
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/epinio/epinio/helpers/kubernetes"
 	"github.com/epinio/epinio/internal/helmchart"
@@ -22,6 +24,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// StreamPartSize is the size of the parts buffered when uploading a stream of unknown size.
+const StreamPartSize = 16 * 1024 * 1024
+
+// Limits of the parts of uploads in parts, as imposed by S3.
+const (
+	MinPartSize = 5 * 1024 * 1024
+	MaxParts    = 10000
+)
+
 type Manager struct {
 	minioClient       *minio.Client
 	connectionDetails ConnectionDetails
@@ -200,10 +211,18 @@ func (m *Manager) UploadStream(ctx context.Context, file io.Reader, size int64,
 	objectName := uuid.New().String()
 	contentType := "application/tar"
 
+	// Without size the client buffers a part of the stream at a time. The default part
+	// size for unknown sizes is too large to keep in memory.
+	var partSize uint64
+	if size < 0 {
+		partSize = StreamPartSize
+	}
+
 	_, err := m.minioClient.PutObject(ctx, m.connectionDetails.Bucket,
 		objectName, file, size, minio.PutObjectOptions{
 			ContentType:  contentType,
 			UserMetadata: metadata,
+			PartSize:     partSize,
 		})
 	if err != nil {
 		return "", errors.Wrap(err, "writing the new object")
@@ -234,6 +253,158 @@ func (m *Manager) Upload(ctx context.Context, filepath string, metadata map[stri
 	return objectName, nil
 }
 
+// StartUpload begins an upload in parts. It returns the blobUID of the object the parts are
+// assembled into, and the id of the upload, for use with UploadPart, CompleteUpload, and
+// AbortUpload.
+func (m *Manager) StartUpload(ctx context.Context, metadata map[string]string) (string, string, error) {
+	if err := m.EnsureBucket(ctx); err != nil {
+		return "", "", errors.Wrap(err, "ensuring bucket")
+	}
+
+	objectName := uuid.New().String()
+	contentType := "application/tar"
+
+	uploadID, err := m.core().NewMultipartUpload(ctx, m.connectionDetails.Bucket,
+		objectName, minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: metadata,
+		})
+	if err != nil {
+		return "", "", errors.Wrap(err, "starting the upload of the new object")
+	}
+
+	return objectName, uploadID, nil
+}
+
+// UploadPart stores the numbered part of the upload. Parts are numbered from 1, and all but
+// the last must have at least MinPartSize bytes. Uploading a part again replaces it, i.e. a
+// failed part can be retried.
+func (m *Manager) UploadPart(ctx context.Context, blobUID, uploadID string, part int, data io.Reader, size int64) error {
+	_, err := m.core().PutObjectPart(ctx, m.connectionDetails.Bucket,
+		blobUID, uploadID, part, data, size, "", "", nil)
+	if err != nil {
+		return errors.Wrapf(err, "writing part %d of the object", part)
+	}
+
+	return nil
+}
+
+// CompleteUpload assembles the uploaded parts into the object of the upload, in the order of
+// their numbers.
+func (m *Manager) CompleteUpload(ctx context.Context, blobUID, uploadID string) error {
+	uploaded, err := m.uploadParts(ctx, blobUID, uploadID)
+	if err != nil {
+		return err
+	}
+
+	if len(uploaded) == 0 {
+		return errors.New("no parts uploaded")
+	}
+
+	parts := []minio.CompletePart{}
+	for _, part := range uploaded {
+		parts = append(parts, minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+
+	_, err = m.core().CompleteMultipartUpload(ctx, m.connectionDetails.Bucket,
+		blobUID, uploadID, parts, minio.PutObjectOptions{})
+	if err != nil {
+		return errors.Wrap(err, "assembling the parts of the object")
+	}
+
+	return nil
+}
+
+// UploadSize returns the total size of the parts of the upload stored so far.
+func (m *Manager) UploadSize(ctx context.Context, blobUID, uploadID string) (int64, error) {
+	parts, err := m.uploadParts(ctx, blobUID, uploadID)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, part := range parts {
+		size += part.Size
+	}
+	return size, nil
+}
+
+// PartSizes returns the sizes of the parts of the upload stored so far, by part number.
+func (m *Manager) PartSizes(ctx context.Context, blobUID, uploadID string) (map[int]int64, error) {
+	parts, err := m.uploadParts(ctx, blobUID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := map[int]int64{}
+	for _, part := range parts {
+		sizes[part.PartNumber] = part.Size
+	}
+	return sizes, nil
+}
+
+// uploadParts returns the parts of the upload stored so far, in the order of their numbers.
+func (m *Manager) uploadParts(ctx context.Context, blobUID, uploadID string) ([]minio.ObjectPart, error) {
+	parts := []minio.ObjectPart{}
+	marker := 0
+	for {
+		result, err := m.core().ListObjectParts(ctx, m.connectionDetails.Bucket,
+			blobUID, uploadID, marker, MaxParts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing the parts of the object")
+		}
+
+		parts = append(parts, result.ObjectParts...)
+
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextPartNumberMarker
+	}
+
+	return parts, nil
+}
+
+// AbortUpload discards the upload, and its parts.
+func (m *Manager) AbortUpload(ctx context.Context, blobUID, uploadID string) error {
+	err := m.core().AbortMultipartUpload(ctx, m.connectionDetails.Bucket, blobUID, uploadID)
+	if err != nil {
+		return errors.Wrap(err, "aborting the upload of the object")
+	}
+
+	return nil
+}
+
+// AbortUploadsBefore discards the uploads in parts started before the given time, and not
+// completed, nor aborted, since. It returns the number of discarded uploads.
+func (m *Manager) AbortUploadsBefore(ctx context.Context, before time.Time) (int, error) {
+	uploads := m.minioClient.ListIncompleteUploads(ctx, m.connectionDetails.Bucket, "", true)
+
+	aborted := 0
+	for upload := range uploads {
+		if upload.Err != nil {
+			return aborted, errors.Wrap(upload.Err, "listing the uploads")
+		}
+		if !upload.Initiated.Before(before) {
+			continue
+		}
+		if err := m.AbortUpload(ctx, upload.Key, upload.UploadID); err != nil {
+			return aborted, err
+		}
+		aborted++
+	}
+
+	return aborted, nil
+}
+
+// core returns the low-level client, for uploads in parts.
+func (m *Manager) core() minio.Core {
+	return minio.Core{Client: m.minioClient}
+}
+
 // EnsureBucket creates our bucket if it's missing
 func (m *Manager) EnsureBucket(ctx context.Context) error {
 	exists, err := m.minioClient.BucketExists(ctx, m.connectionDetails.Bucket)
@@ -248,8 +419,136 @@ func (m *Manager) EnsureBucket(ctx context.Context) error {
 		minio.MakeBucketOptions{Region: m.connectionDetails.Location})
 }
 
+// Ping checks the storage is reachable, and has our bucket.
+func (m *Manager) Ping(ctx context.Context) error {
+	exists, err := m.minioClient.BucketExists(ctx, m.connectionDetails.Bucket)
+	if err != nil {
+		return errors.Wrapf(err, "checking bucket %s exists", m.connectionDetails.Bucket)
+	}
+	if !exists {
+		return errors.Errorf("bucket %s not found", m.connectionDetails.Bucket)
+	}
+	return nil
+}
+
 // DeleteObject deletes the specified object from the storage
 func (m *Manager) DeleteObject(ctx context.Context, objectID string) error {
 	return m.minioClient.RemoveObject(ctx, m.connectionDetails.Bucket, objectID,
 		minio.RemoveObjectOptions{})
 }
+
+// StoreObject uploads the given data to the S3 endpoint, under the given name. An existing
+// object of that name is replaced.
+func (m *Manager) StoreObject(ctx context.Context, objectName string, data []byte, contentType string, metadata map[string]string) error {
+	if err := m.EnsureBucket(ctx); err != nil {
+		return errors.Wrap(err, "ensuring bucket")
+	}
+
+	_, err := m.minioClient.PutObject(ctx, m.connectionDetails.Bucket,
+		objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: metadata,
+		})
+	if err != nil {
+		return errors.Wrap(err, "writing the object")
+	}
+
+	return nil
+}
+
+// StoreObjectStream uploads the data read from the reader to the S3 endpoint, under the
+// given name. An existing object of that name is replaced.
+func (m *Manager) StoreObjectStream(ctx context.Context, objectName string, data io.Reader, size int64, contentType string, metadata map[string]string) error {
+	if err := m.EnsureBucket(ctx); err != nil {
+		return errors.Wrap(err, "ensuring bucket")
+	}
+
+	_, err := m.minioClient.PutObject(ctx, m.connectionDetails.Bucket,
+		objectName, data, size, minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: metadata,
+		})
+	if err != nil {
+		return errors.Wrap(err, "writing the object")
+	}
+
+	return nil
+}
+
+// OpenObject returns a reader for the contents of the named object. The caller has to close
+// it.
+func (m *Manager) OpenObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	object, err := m.minioClient.GetObject(ctx, m.connectionDetails.Bucket, objectName,
+		minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the object")
+	}
+
+	return object, nil
+}
+
+// ObjectNames returns the names of the objects having the given prefix.
+func (m *Manager) ObjectNames(ctx context.Context, prefix string) ([]string, error) {
+	if err := m.EnsureBucket(ctx); err != nil {
+		return nil, errors.Wrap(err, "ensuring bucket")
+	}
+
+	objects := m.minioClient.ListObjects(ctx, m.connectionDetails.Bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	names := []string{}
+	for object := range objects {
+		if object.Err != nil {
+			return nil, errors.Wrap(object.Err, "listing the objects")
+		}
+		names = append(names, object.Key)
+	}
+
+	return names, nil
+}
+
+// FetchObject returns the contents of the named object. A missing object is signaled by a
+// nil result, and no error.
+func (m *Manager) FetchObject(ctx context.Context, objectName string) ([]byte, error) {
+	object, err := m.minioClient.GetObject(ctx, m.connectionDetails.Bucket, objectName,
+		minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the object")
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "reading the object")
+	}
+
+	return data, nil
+}
+
+// DeleteObjectsBefore deletes the objects having the given prefix, and last modified
+// before the given time.
+func (m *Manager) DeleteObjectsBefore(ctx context.Context, prefix string, before time.Time) error {
+	objects := m.minioClient.ListObjects(ctx, m.connectionDetails.Bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for object := range objects {
+		if object.Err != nil {
+			return errors.Wrap(object.Err, "listing the objects")
+		}
+		if !object.LastModified.Before(before) {
+			continue
+		}
+		if err := m.DeleteObject(ctx, object.Key); err != nil {
+			return errors.Wrapf(err, "deleting object %s", object.Key)
+		}
+	}
+
+	return nil
+}
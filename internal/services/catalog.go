@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	apiv1 "github.com/epinio/application/api/v1"
 	"github.com/epinio/epinio/internal/helmchart"
@@ -37,6 +38,7 @@ func (s *ServiceClient) GetCatalogService(ctx context.Context, serviceName strin
 	return service, nil
 }
 
+// ListCatalogServices returns the services of the catalog, sorted by name.
 func (s *ServiceClient) ListCatalogServices(ctx context.Context) ([]*models.CatalogService, error) {
 	listResult, err := s.serviceKubeClient.Namespace(helmchart.Namespace()).List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -47,6 +49,7 @@ func (s *ServiceClient) ListCatalogServices(ctx context.Context) ([]*models.Cata
 	if err != nil {
 		return nil, errors.Wrap(err, "error converting listResult into Catalog Services")
 	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Meta.Name < services[j].Meta.Name })
 
 	return services, nil
 }
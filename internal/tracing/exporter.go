@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// Setup records the spans of the service, and exports them, in batches, to the OTLP endpoint,
+// i.e. the base URL of the collector. Its traces are at `/v1/traces`. The returned function
+// sends the last spans, and stops the export.
+func Setup(ctx context.Context, logger logr.Logger, endpoint, service string, headers map[string]string) (func(context.Context) error, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, errors.Errorf("otlp endpoint `%s` is not an http(s) url", endpoint)
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(parsed.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(parsed.Path, "/") + "/v1/traces"),
+		otlptracehttp.WithHeaders(headers),
+	}
+	if parsed.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the otlp exporter")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(service))),
+	)
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Error(err, "exporting spans failed")
+	}))
+	otel.SetTextMapPropagator(Propagator)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// ParseHeaders parses the headers of the requests to the collector, given as comma-separated
+// assignments `KEY=VALUE`, as in OTEL_EXPORTER_OTLP_HEADERS.
+func ParseHeaders(spec string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, assignment := range strings.Split(spec, ",") {
+		assignment = strings.TrimSpace(assignment)
+		if assignment == "" {
+			continue
+		}
+		pieces := strings.SplitN(assignment, "=", 2)
+		if len(pieces) < 2 || pieces[0] == "" {
+			return nil, errors.Errorf("bad header '%s', expected KEY=VALUE", assignment)
+		}
+		headers[strings.TrimSpace(pieces[0])] = strings.TrimSpace(pieces[1])
+	}
+	return headers, nil
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/epinio/epinio/internal/cli/server/requestctx"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware traces the requests as server spans, continuing the trace of the client, if it
+// sent one. The route is the one of gin, e.g. `/api/v1/namespaces/:namespace`. The logger of
+// the request gets the trace id, to find the logs of a trace.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := Start(ctx, c.Request.Method+" "+route, KindServer)
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = requestctx.WithLogger(ctx, requestctx.Logger(ctx).WithValues("traceId", sc.TraceID().String()))
+		}
+		span.SetAttributes(
+			semconv.HTTPMethodKey.String(c.Request.Method),
+			semconv.HTTPRouteKey.String(route),
+			semconv.HTTPTargetKey.String(c.Request.URL.Path),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if user := requestctx.User(c.Request.Context()).Username; user != "" {
+			span.SetAttributes(semconv.EnduserIDKey.String(user))
+		}
+
+		var err error
+		if status >= http.StatusInternalServerError {
+			err = fmt.Errorf("status %d", status)
+		}
+		Finish(span, err)
+	}
+}
+
+// Transport traces the requests of the kube client, made in the context of a span, as client
+// spans. The requests carry the trace context, for the API server.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripper(func(request *http.Request) (*http.Response, error) {
+		if !trace.SpanContextFromContext(request.Context()).IsValid() {
+			return next.RoundTrip(request)
+		}
+
+		ctx, span := Start(request.Context(), "kube "+request.Method, KindClient)
+		span.SetAttributes(
+			semconv.HTTPMethodKey.String(request.Method),
+			semconv.HTTPURLKey.String(request.URL.Path),
+			semconv.NetPeerNameKey.String(request.URL.Host),
+		)
+
+		request = request.Clone(ctx)
+		Propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+		response, err := next.RoundTrip(request)
+		if err == nil {
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(response.StatusCode))
+			if response.StatusCode >= http.StatusInternalServerError {
+				err = fmt.Errorf("status %d", response.StatusCode)
+			}
+		}
+		Finish(span, err)
+
+		return response, err
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
@@ -0,0 +1,75 @@
+// Package tracing records the work of the server as OpenTelemetry spans: the requests of the
+// API, the requests of the kube client, and the helm operations. The spans are recorded with
+// the OpenTelemetry SDK, and exported to an OTLP collector, over HTTP, see Setup.
+//
+// Trace context arrives, and leaves, in the W3C `traceparent` header. The CLI sends the
+// context of its command with every request, so that a push is a single trace, from the
+// upload to the deployment.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header is the header carrying the trace context.
+const Header = "traceparent"
+
+// instrumentation is the name of the tracer of the spans.
+const instrumentation = "github.com/epinio/epinio"
+
+// Kinds of spans.
+const (
+	KindInternal = trace.SpanKindInternal
+	KindServer   = trace.SpanKindServer
+	KindClient   = trace.SpanKindClient
+)
+
+// Propagator reads, and writes, the trace context of the requests, in the traceparent header.
+var Propagator = propagation.TraceContext{}
+
+// Enabled returns true if the spans are recorded, i.e. after Setup.
+func Enabled() bool {
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	return ok
+}
+
+// Start starts a span of the kind, as child of the span of the context, if any, else as the
+// root of a new trace. It returns the context with the new span. Without tracing the span
+// records nothing.
+func Start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// Finish ends the span, failed with the error, if any.
+func Finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SpanContext returns the span context of the traceparent header value, with its sampled
+// flag, if the value is a valid one. Else it returns the context of the root span of a new,
+// sampled, trace.
+func SpanContext(traceparent string) trace.SpanContext {
+	ctx := Propagator.Extract(context.Background(), propagation.MapCarrier{Header: traceparent})
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc
+	}
+
+	_, span := sdktrace.NewTracerProvider().Tracer(instrumentation).Start(context.Background(), "epinio")
+	return span.SpanContext()
+}
+
+// Inject sets the traceparent header of the span context in the headers.
+func Inject(sc trace.SpanContext, header http.Header) {
+	Propagator.Inject(trace.ContextWithSpanContext(context.Background(), sc), propagation.HeaderCarrier(header))
+}
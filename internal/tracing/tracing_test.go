@@ -0,0 +1,205 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/epinio/epinio/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// ended returns the ended spans of the recorder, by name.
+func ended(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	result := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		result[span.Name()] = span
+	}
+	return result
+}
+
+var _ = Describe("SpanContext", func() {
+	It("keeps the trace of the traceparent, and its sampled flag", func() {
+		sc := tracing.SpanContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		Expect(sc.TraceID().String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(sc.SpanID().String()).To(Equal("00f067aa0ba902b7"))
+		Expect(sc.IsSampled()).To(BeTrue())
+
+		sc = tracing.SpanContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		Expect(sc.IsSampled()).To(BeFalse())
+
+		header := http.Header{}
+		tracing.Inject(sc, header)
+		Expect(header.Get(tracing.Header)).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	})
+
+	DescribeTable("starts a new sampled trace for bad values",
+		func(value string) {
+			sc := tracing.SpanContext(value)
+			Expect(sc.IsValid()).To(BeTrue())
+			Expect(sc.IsSampled()).To(BeTrue())
+			Expect(sc.TraceID().String()).ToNot(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		},
+		Entry("empty", ""),
+		Entry("missing flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"),
+		Entry("bad version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+		Entry("short trace id", "00-4bf92f3577b34da6-00f067aa0ba902b7-01"),
+		Entry("zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"),
+	)
+})
+
+var _ = Describe("ParseHeaders", func() {
+	It("parses the assignments", func() {
+		headers, err := tracing.ParseHeaders("Authorization=Basic abc=, x-tenant = epinio")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(headers).To(Equal(map[string]string{"Authorization": "Basic abc=", "x-tenant": "epinio"}))
+	})
+
+	It("fails for a bad assignment", func() {
+		_, err := tracing.ParseHeaders("Authorization")
+		Expect(err).To(MatchError(ContainSubstring("expected KEY=VALUE")))
+	})
+})
+
+var _ = Describe("Setup", func() {
+	AfterEach(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	It("needs an http(s) endpoint", func() {
+		_, err := tracing.Setup(context.Background(), logr.Discard(), "collector:4318", "epinio-test", nil)
+		Expect(err).To(MatchError(ContainSubstring("is not an http(s) url")))
+	})
+
+	It("exports the spans to the collector", func() {
+		var mutex sync.Mutex
+		var path, tenant string
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			path = r.URL.Path
+			tenant = r.Header.Get("X-Tenant")
+		}))
+		defer collector.Close()
+
+		shutdown, err := tracing.Setup(context.Background(), logr.Discard(), collector.URL+"/otlp/", "epinio-test", map[string]string{"X-Tenant": "epinio"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tracing.Enabled()).To(BeTrue())
+
+		_, span := tracing.Start(context.Background(), "operation", tracing.KindInternal)
+		tracing.Finish(span, nil)
+		Expect(shutdown(context.Background())).To(Succeed())
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(path).To(Equal("/otlp/v1/traces"))
+		Expect(tenant).To(Equal("epinio"))
+	})
+})
+
+var _ = Describe("Spans", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	It("records nothing without tracing", func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		Expect(tracing.Enabled()).To(BeFalse())
+
+		ctx, span := tracing.Start(context.Background(), "nothing", tracing.KindInternal)
+		Expect(span.IsRecording()).To(BeFalse())
+		Expect(trace.SpanContextFromContext(ctx).IsValid()).To(BeFalse())
+		tracing.Finish(span, nil)
+	})
+
+	It("records children in the trace of their parent", func() {
+		ctx, parent := tracing.Start(context.Background(), "parent", tracing.KindInternal)
+		_, child := tracing.Start(ctx, "child", tracing.KindInternal)
+		child.SetAttributes(attribute.String("key", "value"))
+		tracing.Finish(child, errors.New("boom"))
+		tracing.Finish(parent, nil)
+
+		spans := ended(recorder)
+		Expect(spans).To(HaveLen(2))
+		Expect(spans["parent"].Parent().IsValid()).To(BeFalse())
+		Expect(spans["child"].Parent().SpanID()).To(Equal(spans["parent"].SpanContext().SpanID()))
+		Expect(spans["child"].SpanContext().TraceID()).To(Equal(spans["parent"].SpanContext().TraceID()))
+		Expect(spans["child"].Status().Code).To(Equal(codes.Error))
+		Expect(spans["child"].Status().Description).To(Equal("boom"))
+		Expect(spans["child"].Attributes()).To(ContainElement(attribute.String("key", "value")))
+	})
+
+	It("continues the trace of the client in the middleware", func() {
+		remote := tracing.SpanContext("")
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(tracing.Middleware())
+		router.GET("/api/v1/namespaces/:namespace", func(c *gin.Context) {
+			Expect(trace.SpanContextFromContext(c.Request.Context()).TraceID()).To(Equal(remote.TraceID()))
+			c.Status(http.StatusInternalServerError)
+		})
+
+		request := httptest.NewRequest("GET", "/api/v1/namespaces/workspace", nil)
+		tracing.Inject(remote, request.Header)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		span := ended(recorder)["GET /api/v1/namespaces/:namespace"]
+		Expect(span).ToNot(BeNil())
+		Expect(span.Parent().SpanID()).To(Equal(remote.SpanID()))
+		Expect(span.Parent().IsRemote()).To(BeTrue())
+		Expect(span.SpanKind()).To(Equal(tracing.KindServer))
+		Expect(span.Status().Description).To(Equal("status 500"))
+	})
+
+	It("traces the requests of the kube client, in a span", func() {
+		var got string
+		transport := tracing.Transport(roundTripper(func(r *http.Request) (*http.Response, error) {
+			got = r.Header.Get(tracing.Header)
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}))
+
+		request := httptest.NewRequest("GET", "https://kube/api/v1/pods", nil)
+		_, err := transport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeEmpty())
+
+		ctx, parent := tracing.Start(context.Background(), "parent", tracing.KindInternal)
+		_, err = transport.RoundTrip(request.WithContext(ctx))
+		Expect(err).ToNot(HaveOccurred())
+		tracing.Finish(parent, nil)
+
+		sc := tracing.SpanContext(got)
+		Expect(sc.TraceID()).To(Equal(parent.SpanContext().TraceID()))
+
+		spans := ended(recorder)
+		Expect(spans).To(HaveLen(2))
+		Expect(spans["kube GET"].Parent().SpanID()).To(Equal(spans["parent"].SpanContext().SpanID()))
+		Expect(spans["kube GET"].SpanContext().SpanID()).To(Equal(sc.SpanID()))
+	})
+})
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/epinio/epinio/internal/events"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// Delivery of the events: a request has deliveryTimeout to succeed, failed ones are retried
+// deliveryAttempts times in all, waiting retryDelay, doubled after each attempt.
+const (
+	deliveryTimeout  = 10 * time.Second
+	deliveryAttempts = 4
+	retryDelay       = 2 * time.Second
+)
+
+// Sender posts the events to the webhooks.
+type Sender struct {
+	Client *http.Client
+	// RetryDelay is the wait before the first retry of a failed delivery.
+	RetryDelay time.Duration
+}
+
+// NewSender returns a sender with the default timeout and retry delay.
+func NewSender() *Sender {
+	return &Sender{
+		Client:     &http.Client{Timeout: deliveryTimeout},
+		RetryDelay: retryDelay,
+	}
+}
+
+// Deliver posts the event to the webhook, retrying failed attempts, until the context is done.
+func (s *Sender) Deliver(ctx context.Context, hook Webhook, event models.PlatformEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	delay := s.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err = s.post(ctx, hook, event, payload)
+		if err == nil || attempt == deliveryAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes a single attempt at delivering the payload of the event.
+func (s *Sender) post(ctx context.Context, hook Webhook, event models.PlatformEvent, payload []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, event.Type)
+	request.Header.Set(DeliveryHeader, strconv.FormatUint(event.ID, 10))
+	request.Header.Set(SignatureHeader, Sign(hook.Secret, payload))
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "posting event")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.Errorf("webhook returned '%s'", response.Status)
+	}
+	return nil
+}
+
+// DeliverLoop posts the events of the default bus to the matching webhooks, until the context
+// is done. The webhooks are read for each event, changes apply to the next one. A slow
+// webhook does not hold back the others, each delivery is made on its own.
+func DeliverLoop(ctx context.Context, logger logr.Logger) {
+	sender := NewSender()
+
+	var lastID uint64
+	for {
+		subscription, missed := events.Default.Subscribe(lastID, nil)
+		for _, event := range missed {
+			lastID = event.ID
+			dispatch(ctx, logger, sender, event)
+		}
+
+		// The bus closes the subscription when the loop falls behind. The next one
+		// catches up from the recent events.
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				events.Default.Unsubscribe(subscription)
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					open = false
+					break
+				}
+				lastID = event.ID
+				dispatch(ctx, logger, sender, event)
+			}
+		}
+	}
+}
+
+// dispatch delivers the event to the webhooks it matches, in the background.
+func dispatch(ctx context.Context, logger logr.Logger, sender *Sender, event models.PlatformEvent) {
+	store, err := NewStoreFromContext(ctx)
+	if err != nil {
+		logger.Error(err, "webhooks: no cluster")
+		return
+	}
+	hooks, err := store.List(ctx)
+	if err != nil {
+		logger.Error(err, "webhooks: listing the webhooks failed")
+		return
+	}
+
+	for _, hook := range hooks {
+		if !hook.Matches(event) {
+			continue
+		}
+		go func(hook Webhook) {
+			if err := sender.Deliver(ctx, hook, event); err != nil {
+				logger.Error(err, "webhooks: delivery failed", "webhook", hook.Name, "event", event.ID, "type", event.Type)
+			}
+		}(hook)
+	}
+}
@@ -0,0 +1,222 @@
+// Package webhooks posts the platform events, see models.PlatformEvent, to the webhooks
+// configured by the admins, e.g. of chat bots, or of CI systems. The payload is the event as
+// JSON, signed with the secret of the webhook, so that the receiver can check it comes from
+// Epinio, see Sign.
+//
+// The webhooks are stored as secrets of the epinio namespace.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/epinio/epinio/helpers/kubernetes"
+	"github.com/epinio/epinio/internal/helmchart"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// Headers of the requests to the webhooks.
+const (
+	// SignatureHeader carries the signature of the payload, see Sign.
+	SignatureHeader = "X-Epinio-Signature"
+	// EventHeader carries the type of the event.
+	EventHeader = "X-Epinio-Event"
+	// DeliveryHeader carries the id of the event, the same for the retries of a delivery.
+	DeliveryHeader = "X-Epinio-Delivery"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// Webhook is a receiver of the events of the types it asks for, all without types. A type
+// also stands for the types starting with it, e.g. `app` for all the events of apps.
+type Webhook struct {
+	Name   string
+	URL    string
+	Secret string
+	Events []string
+}
+
+// newWebhookFromSecret creates a webhook from its secret.
+func newWebhookFromSecret(secret corev1.Secret) Webhook {
+	events := []string{}
+	for _, event := range strings.Split(string(secret.Data["events"]), "\n") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+
+	return Webhook{
+		Name:   string(secret.Data["name"]),
+		URL:    string(secret.Data["url"]),
+		Secret: string(secret.Data["secret"]),
+		Events: events,
+	}
+}
+
+// Matches returns true if the webhook receives the event.
+func (w Webhook) Matches(event models.PlatformEvent) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, t := range w.Events {
+		if event.Type == t || strings.HasPrefix(event.Type, t+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL checks the URL of a webhook is an http(s) one.
+func ValidateURL(webhookURL string) error {
+	address, err := url.Parse(webhookURL)
+	if err != nil || address.Host == "" || (address.Scheme != "http" && address.Scheme != "https") {
+		return errors.Errorf("webhook url `%s` is not an http(s) url", webhookURL)
+	}
+	return nil
+}
+
+// Sign returns the signature of the payload, the hex encoded HMAC-SHA256 of the payload with
+// the secret, prefixed with `sha256=`.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random secret, for webhooks created without one.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "generating webhook secret")
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Store keeps the webhooks in the secrets of the epinio namespace.
+type Store struct {
+	SecretInterface typedcorev1.SecretInterface
+}
+
+// NewStoreFromContext returns the store of the webhooks of the cluster.
+func NewStoreFromContext(ctx context.Context) (*Store, error) {
+	cluster, err := kubernetes.GetCluster(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting kubernetes cluster")
+	}
+
+	return &Store{
+		SecretInterface: cluster.Kubectl.CoreV1().Secrets(helmchart.Namespace()),
+	}, nil
+}
+
+// List returns the webhooks, sorted by name.
+func (s *Store) List(ctx context.Context) ([]Webhook, error) {
+	selector := labels.Set(map[string]string{
+		kubernetes.EpinioWebhookLabelKey: kubernetes.EpinioWebhookLabelValue,
+	}).AsSelector().String()
+
+	secretList, err := s.SecretInterface.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the webhook secrets")
+	}
+
+	hooks := []Webhook{}
+	for _, secret := range secretList.Items {
+		hooks = append(hooks, newWebhookFromSecret(secret))
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].Name < hooks[j].Name })
+
+	return hooks, nil
+}
+
+// Get returns the webhook with the name, or ErrWebhookNotFound.
+func (s *Store) Get(ctx context.Context, name string) (Webhook, error) {
+	secret, err := s.SecretInterface.Get(ctx, webhookSecretName(name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Webhook{}, ErrWebhookNotFound
+	}
+	if err != nil {
+		return Webhook{}, errors.Wrap(err, fmt.Sprintf("error getting the webhook secret [%s]", name))
+	}
+	if secret.Labels[kubernetes.EpinioWebhookLabelKey] != kubernetes.EpinioWebhookLabelValue {
+		return Webhook{}, ErrWebhookNotFound
+	}
+
+	return newWebhookFromSecret(*secret), nil
+}
+
+// Set creates the webhook, or replaces the existing one. An empty secret keeps the secret of
+// the existing webhook. It returns the webhook as saved.
+func (s *Store) Set(ctx context.Context, hook Webhook) (Webhook, error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.SecretInterface.Get(ctx, webhookSecretName(hook.Name), metav1.GetOptions{})
+		isNew := apierrors.IsNotFound(err)
+		if isNew {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: webhookSecretName(hook.Name),
+					Labels: map[string]string{
+						kubernetes.EpinioWebhookLabelKey: kubernetes.EpinioWebhookLabelValue,
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		} else if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error getting the webhook secret [%s]", hook.Name))
+		}
+
+		if hook.Secret == "" {
+			hook.Secret = newWebhookFromSecret(*secret).Secret
+		}
+		if hook.Secret == "" {
+			if hook.Secret, err = NewSecret(); err != nil {
+				return err
+			}
+		}
+
+		secret.Data = map[string][]byte{
+			"name":   []byte(hook.Name),
+			"url":    []byte(hook.URL),
+			"secret": []byte(hook.Secret),
+			"events": []byte(strings.Join(hook.Events, "\n")),
+		}
+
+		if isNew {
+			_, err = s.SecretInterface.Create(ctx, secret, metav1.CreateOptions{})
+		} else {
+			_, err = s.SecretInterface.Update(ctx, secret, metav1.UpdateOptions{})
+		}
+		return err
+	})
+
+	return hook, err
+}
+
+// Delete removes the webhook.
+func (s *Store) Delete(ctx context.Context, name string) error {
+	err := s.SecretInterface.Delete(ctx, webhookSecretName(name), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrWebhookNotFound
+	}
+	return errors.Wrap(err, fmt.Sprintf("error deleting the webhook secret [%s]", name))
+}
+
+func webhookSecretName(name string) string {
+	return "webhook-" + name
+}
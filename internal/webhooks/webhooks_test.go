@@ -0,0 +1,159 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/epinio/epinio/internal/webhooks"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Webhook", func() {
+	event := models.PlatformEvent{ID: 7, Type: models.EventStagingFailed, Namespace: "workspace", Name: "app"}
+
+	It("receives all the events without types", func() {
+		Expect(webhooks.Webhook{}.Matches(event)).To(BeTrue())
+	})
+
+	It("receives the events of its types, and of the types starting with them", func() {
+		Expect(webhooks.Webhook{Events: []string{models.EventStagingFailed}}.Matches(event)).To(BeTrue())
+		Expect(webhooks.Webhook{Events: []string{"app"}}.Matches(event)).To(BeTrue())
+		Expect(webhooks.Webhook{Events: []string{"app.staging"}}.Matches(event)).To(BeTrue())
+		Expect(webhooks.Webhook{Events: []string{"ap"}}.Matches(event)).To(BeFalse())
+		Expect(webhooks.Webhook{Events: []string{"service"}}.Matches(event)).To(BeFalse())
+	})
+
+	It("accepts only http(s) urls", func() {
+		Expect(webhooks.ValidateURL("https://chat.example.com/hooks/1")).To(Succeed())
+		Expect(webhooks.ValidateURL("ftp://chat.example.com")).ToNot(Succeed())
+		Expect(webhooks.ValidateURL("chat.example.com")).ToNot(Succeed())
+	})
+
+	It("signs the payload with the HMAC-SHA256 of the secret", func() {
+		// echo -n 'payload' | openssl dgst -sha256 -hmac secret
+		Expect(webhooks.Sign("secret", []byte("payload"))).To(Equal("sha256=b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4"))
+	})
+})
+
+var _ = Describe("Store", func() {
+	var store *webhooks.Store
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		store = &webhooks.Store{SecretInterface: kubefake.NewSimpleClientset().CoreV1().Secrets("epinio")}
+	})
+
+	It("saves the webhooks, and lists them by name", func() {
+		_, err := store.Set(ctx, webhooks.Webhook{Name: "chat", URL: "https://chat.example.com", Secret: "s", Events: []string{"app"}})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = store.Set(ctx, webhooks.Webhook{Name: "ci", URL: "https://ci.example.com", Secret: "t"})
+		Expect(err).ToNot(HaveOccurred())
+
+		hooks, err := store.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hooks).To(HaveLen(2))
+		Expect(hooks[0]).To(Equal(webhooks.Webhook{Name: "chat", URL: "https://chat.example.com", Secret: "s", Events: []string{"app"}}))
+		Expect(hooks[1].Name).To(Equal("ci"))
+		Expect(hooks[1].Events).To(BeEmpty())
+	})
+
+	It("generates a secret for a new webhook, and keeps it on changes without secret", func() {
+		hook, err := store.Set(ctx, webhooks.Webhook{Name: "chat", URL: "https://chat.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hook.Secret).To(HaveLen(64))
+
+		changed, err := store.Set(ctx, webhooks.Webhook{Name: "chat", URL: "https://chat.example.org"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed.Secret).To(Equal(hook.Secret))
+
+		hooks, err := store.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hooks).To(HaveLen(1))
+		Expect(hooks[0].URL).To(Equal("https://chat.example.org"))
+	})
+
+	It("returns the webhook by name, and reports unknown ones", func() {
+		_, err := store.Set(ctx, webhooks.Webhook{Name: "chat", URL: "https://chat.example.com", Secret: "s"})
+		Expect(err).ToNot(HaveOccurred())
+
+		hook, err := store.Get(ctx, "chat")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hook.URL).To(Equal("https://chat.example.com"))
+
+		_, err = store.Get(ctx, "ci")
+		Expect(err).To(MatchError(webhooks.ErrWebhookNotFound))
+	})
+
+	It("deletes webhooks, and reports unknown ones", func() {
+		_, err := store.Set(ctx, webhooks.Webhook{Name: "chat", URL: "https://chat.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(store.Delete(ctx, "chat")).To(Succeed())
+		Expect(store.Delete(ctx, "chat")).To(MatchError(webhooks.ErrWebhookNotFound))
+	})
+})
+
+var _ = Describe("Sender", func() {
+	event := models.PlatformEvent{ID: 7, Type: models.EventAppDeployed, Namespace: "workspace", Name: "app"}
+
+	It("posts the signed event", func() {
+		var request *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		hook := webhooks.Webhook{Name: "chat", URL: server.URL, Secret: "secret"}
+		Expect(webhooks.NewSender().Deliver(context.Background(), hook, event)).To(Succeed())
+
+		Expect(request.Method).To(Equal(http.MethodPost))
+		Expect(request.Header.Get(webhooks.EventHeader)).To(Equal(models.EventAppDeployed))
+		Expect(request.Header.Get(webhooks.DeliveryHeader)).To(Equal("7"))
+		Expect(request.Header.Get(webhooks.SignatureHeader)).To(Equal(webhooks.Sign("secret", body)))
+
+		var received models.PlatformEvent
+		Expect(json.Unmarshal(body, &received)).To(Succeed())
+		Expect(received).To(Equal(event))
+	})
+
+	It("retries failed deliveries", func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer server.Close()
+
+		sender := webhooks.NewSender()
+		sender.RetryDelay = time.Millisecond
+		Expect(sender.Deliver(context.Background(), webhooks.Webhook{URL: server.URL}, event)).To(Succeed())
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+	})
+
+	It("gives up after the last attempt", func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		sender := webhooks.NewSender()
+		sender.RetryDelay = time.Millisecond
+		err := sender.Deliver(context.Background(), webhooks.Webhook{URL: server.URL}, event)
+		Expect(err).To(MatchError(ContainSubstring("500")))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(4)))
+	})
+})
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// APITokens returns the API tokens of the user
+func (c *Client) APITokens() (models.APITokenList, error) {
+	var resp models.APITokenList
+
+	data, err := c.get(api.Routes.Path("APITokens"))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// APITokenShow returns the API token of the user, without its secret
+func (c *Client) APITokenShow(id string) (models.APIToken, error) {
+	resp := models.APIToken{}
+
+	data, err := c.get(api.Routes.Path("APITokenShow", id))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// APITokenCreate issues a new API token to the user
+func (c *Client) APITokenCreate(req models.APITokenCreateRequest) (models.APITokenCreateResponse, error) {
+	var resp models.APITokenCreateResponse
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("APITokenCreate"), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// APITokenDelete revokes the API token with the id
+func (c *Client) APITokenDelete(id string) (models.Response, error) {
+	resp := models.Response{}
+
+	data, err := c.delete(api.Routes.Path("APITokenDelete", id))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
@@ -0,0 +1,86 @@
+package client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func DescribeAppList() {
+
+	var epinioClient *client.Client
+	var apps models.AppList
+	var paged bool
+	var requests int
+
+	JustBeforeEach(func() {
+		requests = 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The server predates the discovery of API versions, and v2
+			if r.URL.Path == "/api/versions" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			requests++
+
+			page := apps
+			if paged {
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				end := offset + limit
+				if end > len(apps) {
+					end = len(apps)
+				}
+				page = apps[offset:end]
+				w.Header().Set("X-Total-Count", strconv.Itoa(len(apps)))
+			}
+
+			out, _ := json.Marshal(page)
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, string(out))
+		}))
+
+		epinioClient = client.New(srv.URL, "", "", "")
+	})
+
+	BeforeEach(func() {
+		apps = models.AppList{}
+		for i := 0; i < 250; i++ {
+			apps = append(apps, models.App{Meta: models.NewAppRef(fmt.Sprintf("app-%03d", i), "workspace")})
+		}
+	})
+
+	When("the server pages the list", func() {
+		BeforeEach(func() {
+			paged = true
+		})
+
+		It("requests all pages", func() {
+			list, err := epinioClient.Apps("workspace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list).To(HaveLen(250))
+			Expect(list[249].Meta.Name).To(Equal("app-249"))
+			Expect(requests).To(Equal(3))
+		})
+	})
+
+	When("the server does not page the list", func() {
+		BeforeEach(func() {
+			paged = false
+		})
+
+		It("takes the list at once", func() {
+			list, err := epinioClient.Apps("workspace")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list).To(HaveLen(250))
+			Expect(requests).To(Equal(1))
+		})
+	})
+}
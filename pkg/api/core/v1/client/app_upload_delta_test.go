@@ -0,0 +1,158 @@
+package client_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func DescribeAppUploadDelta() {
+
+	var epinioClient *client.Client
+	var dir string
+
+	// Server state
+	var mu sync.Mutex
+	var cached map[string][]byte
+	var uploaded []string
+	var files []models.SourceFile
+	var withDelta bool
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	hashOf := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "epinio-sources")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		write("main.go", "package main")
+		write("src/app.go", "package app")
+		write(".git/HEAD", "ref: main")
+
+		cached = map[string][]byte{}
+		uploaded = []string{}
+		files = nil
+		withDelta = true
+	})
+
+	JustBeforeEach(func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !withDelta {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors":[{"status":404,"title":"not found"}]}`)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			Expect(err).ToNot(HaveOccurred())
+
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/store/chunks"):
+				var req models.SourceChunksRequest
+				Expect(json.Unmarshal(body, &req)).To(Succeed())
+				missing := []string{}
+				for _, hash := range req.Chunks {
+					if _, ok := cached[hash]; !ok {
+						missing = append(missing, hash)
+					}
+				}
+				Expect(json.NewEncoder(w).Encode(models.SourceChunksResponse{Missing: missing})).To(Succeed())
+
+			case r.Method == http.MethodPut:
+				hash := filepath.Base(r.URL.Path)
+				Expect(hashOf(string(body))).To(Equal(hash))
+				cached[hash] = body
+				uploaded = append(uploaded, string(body))
+				fmt.Fprint(w, `{"status":"ok"}`)
+
+			case strings.HasSuffix(r.URL.Path, "/store/delta"):
+				var req models.SourceDeltaRequest
+				Expect(json.Unmarshal(body, &req)).To(Succeed())
+				files = req.Files
+				fmt.Fprint(w, `{"blobuid":"delta"}`)
+			}
+		}))
+		DeferCleanup(srv.Close)
+
+		epinioClient = client.New(srv.URL, "", "", "")
+	})
+
+	It("uploads the files, with the chunks the server does not have", func() {
+		resp, err := epinioClient.AppUploadDelta("namespace-foo", "appname", dir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.BlobUID).To(Equal("delta"))
+		Expect(uploaded).To(ConsistOf("package main", "package app"))
+
+		Expect(files).To(HaveLen(3))
+		Expect(files[0].Path).To(Equal("main.go"))
+		Expect(files[0].Size).To(Equal(int64(12)))
+		Expect(files[0].Chunks).To(Equal([]string{hashOf("package main")}))
+		Expect(files[1].Path).To(Equal("src"))
+		Expect(os.FileMode(files[1].Mode).IsDir()).To(BeTrue())
+		Expect(files[2].Path).To(Equal("src/app.go"))
+
+		By("changing a file")
+		uploaded = []string{}
+		write("src/app.go", "package app // changed")
+
+		_, err = epinioClient.AppUploadDelta("namespace-foo", "appname", dir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(uploaded).To(Equal([]string{"package app // changed"}))
+		Expect(files[2].Chunks).To(Equal([]string{hashOf("package app // changed")}))
+	})
+
+	It("splits large files into chunks", func() {
+		large := strings.Repeat("x", client.SourceChunkSize) + "tail"
+		write("large.bin", large)
+
+		reported := int64(0)
+		_, err := epinioClient.AppUploadDelta("namespace-foo", "appname", dir, func(sent, total int64) {
+			reported = sent
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(files[0].Path).To(Equal("large.bin"))
+		Expect(files[0].Chunks).To(Equal([]string{
+			hashOf(large[:client.SourceChunkSize]),
+			hashOf("tail"),
+		}))
+		Expect(reported).To(Equal(int64(len(large) + len("package main") + len("package app"))))
+	})
+
+	When("the server does not support delta uploads", func() {
+		BeforeEach(func() {
+			withDelta = false
+		})
+
+		It("returns ErrDeltaUnsupported", func() {
+			_, err := epinioClient.AppUploadDelta("namespace-foo", "appname", dir, nil)
+			Expect(err).To(Equal(client.ErrDeltaUnsupported))
+		})
+	})
+}
@@ -0,0 +1,153 @@
+package client_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func DescribeAppUpload() {
+
+	var epinioClient *client.Client
+	var tarball string
+	var content []byte
+
+	// Server state
+	var mu sync.Mutex
+	var parts map[string][]byte
+	var failures map[string]int
+	var rejected string
+	var aborted bool
+	var withParts bool
+
+	BeforeEach(func() {
+		dir, err := os.MkdirTemp("", "epinio-upload")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		content = bytes.Repeat([]byte("0123456789abcdef"), (2*client.UploadPartSize+100)/16)
+		tarball = filepath.Join(dir, "blob.tar")
+		Expect(os.WriteFile(tarball, content, 0600)).To(Succeed())
+
+		parts = map[string][]byte{}
+		failures = map[string]int{}
+		rejected = ""
+		aborted = false
+		withParts = true
+	})
+
+	JustBeforeEach(func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			path := r.URL.Path
+			switch {
+			case strings.HasSuffix(path, "/store"):
+				file, _, err := r.FormFile("file")
+				Expect(err).ToNot(HaveOccurred())
+				data, err := io.ReadAll(file)
+				Expect(err).ToNot(HaveOccurred())
+				parts["1"] = data
+				fmt.Fprint(w, `{"blobuid":"streamed"}`)
+
+			case !withParts:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors":[{"status":404,"title":"not found"}]}`)
+
+			case strings.HasSuffix(path, "/store/parts"):
+				fmt.Fprint(w, `{"blobuid":"blob","uploadid":"upload"}`)
+
+			case r.Method == http.MethodPut:
+				Expect(path).To(HaveSuffix("/store/parts/blob"))
+				Expect(r.URL.Query().Get("upload")).To(Equal("upload"))
+				part := r.URL.Query().Get("part")
+				data, err := io.ReadAll(r.Body)
+				Expect(err).ToNot(HaveOccurred())
+				if part == rejected {
+					w.WriteHeader(http.StatusBadRequest)
+					fmt.Fprint(w, `{"errors":[{"status":400,"title":"bad part"}]}`)
+					return
+				}
+				if failures[part] > 0 {
+					failures[part]--
+					w.WriteHeader(http.StatusServiceUnavailable)
+					fmt.Fprint(w, `{"errors":[{"status":503,"title":"unavailable"}]}`)
+					return
+				}
+				parts[part] = data
+				fmt.Fprint(w, `{"status":"ok"}`)
+
+			case r.Method == http.MethodPost:
+				fmt.Fprint(w, `{"blobuid":"blob"}`)
+
+			case r.Method == http.MethodDelete:
+				aborted = true
+				fmt.Fprint(w, `{"status":"ok"}`)
+			}
+		}))
+		DeferCleanup(srv.Close)
+
+		epinioClient = client.New(srv.URL, "", "", "")
+	})
+
+	When("the server supports uploads in parts", func() {
+		BeforeEach(func() {
+			failures["2"] = 1
+		})
+
+		It("sends the tarball in parts, retrying failed parts", func() {
+			reported := []int64{}
+			resp, err := epinioClient.AppUpload("namespace-foo", "appname", tarball, func(sent, total int64) {
+				Expect(total).To(Equal(int64(len(content))))
+				reported = append(reported, sent)
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.BlobUID).To(Equal("blob"))
+
+			Expect(parts).To(HaveLen(3))
+			Expect(parts["1"]).To(HaveLen(client.UploadPartSize))
+			Expect(parts["2"]).To(HaveLen(client.UploadPartSize))
+			Expect(bytes.Join([][]byte{parts["1"], parts["2"], parts["3"]}, nil)).To(Equal(content))
+
+			Expect(reported).To(Equal([]int64{client.UploadPartSize, 2 * client.UploadPartSize, int64(len(content))}))
+			Expect(aborted).To(BeFalse())
+		})
+	})
+
+	When("the server rejects a part", func() {
+		BeforeEach(func() {
+			rejected = "2"
+		})
+
+		It("discards the upload and returns an error", func() {
+			_, err := epinioClient.AppUpload("namespace-foo", "appname", tarball, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to send part 2"))
+			Expect(aborted).To(BeTrue())
+		})
+	})
+
+	When("the server does not support uploads in parts", func() {
+		BeforeEach(func() {
+			withParts = false
+		})
+
+		It("streams the tarball in a single request", func() {
+			resp, err := epinioClient.AppUpload("namespace-foo", "appname", tarball, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.BlobUID).To(Equal("streamed"))
+			Expect(parts["1"]).To(Equal(content))
+		})
+	})
+}
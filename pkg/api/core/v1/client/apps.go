@@ -1,6 +1,9 @@
 package client
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,8 +12,11 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/avast/retry-go"
@@ -70,7 +76,7 @@ func (c *Client) AppCreate(req models.ApplicationCreateRequest, namespace string
 		return resp, nil
 	}
 
-	data, err := c.post(api.Routes.Path("AppCreate", namespace), string(b))
+	data, err := c.postIdempotent(api.Routes.Path("AppCreate", namespace), string(b))
 	if err != nil {
 		return resp, err
 	}
@@ -84,11 +90,16 @@ func (c *Client) AppCreate(req models.ApplicationCreateRequest, namespace string
 	return resp, nil
 }
 
-// Apps returns a list of all apps in an namespace
-func (c *Client) Apps(namespace string) (models.AppList, error) {
-	var resp models.AppList
+// AppApply creates the app of the manifest, or changes the existing one to match it
+func (c *Client) AppApply(req models.ApplicationApplyRequest, namespace string) (models.ApplicationApplyResponse, error) {
+	var resp models.ApplicationApplyResponse
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
 
-	data, err := c.get(api.Routes.Path("Apps", namespace))
+	data, err := c.postIdempotent(api.Routes.Path("AppApply", namespace), string(b))
 	if err != nil {
 		return resp, err
 	}
@@ -102,11 +113,16 @@ func (c *Client) Apps(namespace string) (models.AppList, error) {
 	return resp, nil
 }
 
-// AllApps returns a list of all apps
-func (c *Client) AllApps() (models.AppList, error) {
-	var resp models.AppList
+// AppAdopt turns an existing deployment of the namespace into an app
+func (c *Client) AppAdopt(req models.AppAdoptRequest, namespace string) (models.AppAdoptResponse, error) {
+	var resp models.AppAdoptResponse
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
 
-	data, err := c.get(api.Routes.Path("AllApps"))
+	data, err := c.postIdempotent(api.Routes.Path("AppAdopt", namespace), string(b))
 	if err != nil {
 		return resp, err
 	}
@@ -120,6 +136,48 @@ func (c *Client) AllApps() (models.AppList, error) {
 	return resp, nil
 }
 
+// Apps returns a list of all apps in an namespace, requested page by page
+func (c *Client) Apps(namespace string) (models.AppList, error) {
+	var resp models.AppList
+
+	err := c.getAll("Apps", api.Routes.Path("Apps", namespace), func(data []byte) (int, error) {
+		var page models.AppList
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		resp = append(resp, page...)
+		return len(page), nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AllApps returns a list of all apps, requested page by page
+func (c *Client) AllApps() (models.AppList, error) {
+	var resp models.AppList
+
+	err := c.getAll("AllApps", api.Routes.Path("AllApps"), func(data []byte) (int, error) {
+		var page models.AppList
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		resp = append(resp, page...)
+		return len(page), nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
 // AppShow shows an app
 func (c *Client) AppShow(namespace string, appName string) (models.App, error) {
 	var resp models.App
@@ -157,7 +215,7 @@ func (c *Client) AppGetPart(namespace, appName, part, destinationPath string) er
 		return err
 	}
 
-	request.SetBasicAuth(c.user, c.password)
+	c.authorize(request)
 
 	response, err := (&http.Client{}).Do(request)
 
@@ -240,15 +298,82 @@ func (c *Client) AppDelete(namespace string, name string) (models.ApplicationDel
 	return resp, nil
 }
 
-// AppUpload uploads a tarball for the named app, which is later used in staging
-func (c *Client) AppUpload(namespace string, name string, tarball string) (models.UploadResponse, error) {
+// AppBatchDelete deletes several apps of a namespace in one call
+func (c *Client) AppBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+	resp := models.BatchDeleteResponse{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.do(api.Routes.Path("AppBatchDelete", namespace), "DELETE", string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// Parameters of the upload of app sources in parts.
+const (
+	// UploadPartSize is the size of the parts of the tarball, as read into memory and sent
+	// one at a time. It is above the minimum size of parts accepted by S3 stores.
+	UploadPartSize = 8 * 1024 * 1024
+
+	// Failed parts are retried with a growing delay, for long enough to get past the
+	// replacement of the server during an upgrade.
+	uploadRetries       = 8
+	uploadRetryDelay    = time.Second
+	uploadRetryMaxDelay = 10 * time.Second
+)
+
+// UploadProgress is called during an upload, with the number of bytes sent so far, and the
+// total.
+type UploadProgress func(sent, total int64)
+
+// AppUpload uploads a tarball for the named app, which is later used in staging.
+// The tarball is sent in parts, and a failed part is sent again, a few times, before the
+// upload is given up on and discarded. The parts sent are kept by the store, so an upload
+// severed by the restart of the server resumes with the failed part. Servers not supporting uploads in parts get the
+// tarball streamed in a single request. The progress callback is optional.
+func (c *Client) AppUpload(namespace string, name string, tarball string, progress UploadProgress) (models.UploadResponse, error) {
 	resp := models.UploadResponse{}
 
-	data, err := c.upload(api.Routes.Path("AppUpload", namespace, name), tarball)
+	data, err := c.post(api.Routes.Path("AppUploadStart", namespace, name), "")
 	if err != nil {
+		var rerr *responseError
+		if errors.As(err, &rerr) && rerr.StatusCode() == http.StatusNotFound {
+			return c.appUploadStream(namespace, name, tarball, progress)
+		}
+		return resp, errors.Wrap(err, "can't start upload of archive")
+	}
+
+	start := models.UploadStartResponse{}
+	if err := json.Unmarshal(data, &start); err != nil {
+		return resp, errors.Wrap(err, "response body is not JSON")
+	}
+
+	err = c.appUploadParts(namespace, name, tarball, start, progress)
+	if err != nil {
+		_, abortErr := c.delete(appUploadPath("AppUploadAbort", namespace, name, start, 0))
+		if abortErr != nil {
+			c.log.V(1).Info("failed to abort upload", "error", abortErr.Error())
+		}
 		return resp, errors.Wrap(err, "can't upload archive")
 	}
 
+	data, err = c.post(appUploadPath("AppUploadFinish", namespace, name, start, 0), "")
+	if err != nil {
+		return resp, errors.Wrap(err, "can't finish upload of archive")
+	}
+
 	if err := json.Unmarshal(data, &resp); err != nil {
 		return resp, errors.Wrap(err, "response body is not JSON")
 	}
@@ -258,6 +383,343 @@ func (c *Client) AppUpload(namespace string, name string, tarball string) (model
 	return resp, nil
 }
 
+// appUploadParts sends the parts of the tarball for the started upload. Only one part at a
+// time is held in memory.
+func (c *Client) appUploadParts(namespace, name, tarball string, start models.UploadStartResponse, progress UploadProgress) error {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return errors.Wrap(err, "failed to open tarball")
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to stat tarball")
+	}
+
+	buffer := make([]byte, UploadPartSize)
+	sent := int64(0)
+
+	for part := 1; ; part++ {
+		n, err := io.ReadFull(file, buffer)
+		if err == io.EOF && part > 1 {
+			return nil
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return errors.Wrap(err, "failed to read tarball")
+		}
+
+		endpoint := appUploadPath("AppUploadPart", namespace, name, start, part)
+		if err := c.putRetried(endpoint, buffer[:n]); err != nil {
+			return errors.Wrapf(err, "failed to send part %d", part)
+		}
+
+		sent += int64(n)
+		if progress != nil {
+			progress(sent, info.Size())
+		}
+
+		if n < len(buffer) {
+			return nil
+		}
+	}
+}
+
+// putRetried sends the data to the endpoint, retrying a few times on failures other than
+// the rejection of the data by the server.
+func (c *Client) putRetried(endpoint string, data []byte) error {
+	return retry.Do(
+		func() error {
+			_, err := c.put(endpoint, data)
+			return err
+		},
+		retry.RetryIf(func(err error) bool {
+			// Rejections by the server are final.
+			if r, ok := err.(interface{ StatusCode() int }); ok {
+				code := r.StatusCode()
+				return code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+			}
+			return true
+		}),
+		retry.OnRetry(func(n uint, err error) {
+			c.log.V(1).WithValues(
+				"endpoint", endpoint,
+				"tries", fmt.Sprintf("%d/%d", n, uploadRetries),
+				"error", err.Error(),
+			).Info("Retrying upload")
+		}),
+		retry.Delay(uploadRetryDelay),
+		retry.MaxDelay(uploadRetryMaxDelay),
+		retry.Attempts(uploadRetries),
+		retry.LastErrorOnly(true),
+	)
+}
+
+// appUploadStream uploads the tarball in a single request, streamed from the file.
+func (c *Client) appUploadStream(namespace, name, tarball string, progress UploadProgress) (models.UploadResponse, error) {
+	resp := models.UploadResponse{}
+
+	data, err := c.upload(api.Routes.Path("AppUpload", namespace, name), tarball, progress)
+	if err != nil {
+		return resp, errors.Wrap(err, "can't upload archive")
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, errors.Wrap(err, "response body is not JSON")
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// appUploadPath returns the endpoint of the named route for the started upload, with the
+// part number, if any.
+func appUploadPath(route, namespace, name string, start models.UploadStartResponse, part int) string {
+	query := url.Values{}
+	query.Add("upload", start.UploadID)
+	if part > 0 {
+		query.Add("part", strconv.Itoa(part))
+	}
+	return api.Routes.Path(route, namespace, name, start.BlobUID) + "?" + query.Encode()
+}
+
+// Parameters of the upload of app sources as delta.
+const (
+	// SourceChunkSize is the size of the chunks the files of the sources are split into.
+	// Chunks are identified by their sha256 hash.
+	SourceChunkSize = 4 * 1024 * 1024
+
+	chunkUploadWorkers = 4
+)
+
+// ErrDeltaUnsupported is returned by AppUploadDelta for servers not supporting uploads of app
+// sources as delta.
+var ErrDeltaUnsupported = errors.New("server does not support delta uploads")
+
+// sourceChunk locates a chunk of app sources in its file.
+type sourceChunk struct {
+	path   string
+	offset int64
+	size   int64
+}
+
+// AppUploadDelta uploads the app sources in the directory for the named app as delta, i.e.
+// only the chunks of files the server does not have from the previous upload are sent. The
+// server assembles the tarball used in staging from them. Files are selected as for
+// helpers.Tar. The progress callback is optional, and reports the chunks sent.
+func (c *Client) AppUploadDelta(namespace, name, dir string, progress UploadProgress) (models.UploadResponse, error) {
+	resp := models.UploadResponse{}
+
+	files, chunks, err := sourceFiles(dir)
+	if err != nil {
+		return resp, errors.Wrap(err, "can't collect sources")
+	}
+
+	request := models.SourceChunksRequest{Chunks: []string{}}
+	for hash := range chunks {
+		request.Chunks = append(request.Chunks, hash)
+	}
+	sort.Strings(request.Chunks)
+
+	out, err := json.Marshal(request)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("AppChunksMissing", namespace, name), string(out))
+	if err != nil {
+		var rerr *responseError
+		if errors.As(err, &rerr) && rerr.StatusCode() == http.StatusNotFound {
+			return resp, ErrDeltaUnsupported
+		}
+		return resp, errors.Wrap(err, "can't check cached chunks")
+	}
+
+	missing := models.SourceChunksResponse{}
+	if err := json.Unmarshal(data, &missing); err != nil {
+		return resp, errors.Wrap(err, "response body is not JSON")
+	}
+
+	c.log.V(1).Info("delta upload", "files", len(files), "chunks", len(chunks), "missing", len(missing.Missing))
+
+	if err := c.appUploadChunks(namespace, name, chunks, missing.Missing, progress); err != nil {
+		return resp, errors.Wrap(err, "can't upload chunks")
+	}
+
+	out, err = json.Marshal(models.SourceDeltaRequest{Files: files})
+	if err != nil {
+		return resp, err
+	}
+
+	data, err = c.post(api.Routes.Path("AppUploadDelta", namespace, name), string(out))
+	if err != nil {
+		return resp, errors.Wrap(err, "can't assemble sources")
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, errors.Wrap(err, "response body is not JSON")
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// appUploadChunks sends the missing chunks, a few in parallel.
+func (c *Client) appUploadChunks(namespace, name string, chunks map[string]sourceChunk, missing []string, progress UploadProgress) error {
+	total := int64(0)
+	for _, hash := range missing {
+		chunk, ok := chunks[hash]
+		if !ok {
+			return errors.Errorf("server requested unknown chunk '%s'", hash)
+		}
+		total += chunk.size
+	}
+
+	var (
+		mu       sync.Mutex
+		sent     int64
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	hashes := make(chan string)
+	for i := 0; i < chunkUploadWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hash := range hashes {
+				chunk := chunks[hash]
+				err := c.appUploadChunk(namespace, name, hash, chunk)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				sent += chunk.size
+				if err == nil && progress != nil {
+					progress(sent, total)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, hash := range missing {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		hashes <- hash
+	}
+	close(hashes)
+	wg.Wait()
+
+	return firstErr
+}
+
+// appUploadChunk reads the chunk from its file and sends it.
+func (c *Client) appUploadChunk(namespace, name, hash string, chunk sourceChunk) error {
+	file, err := os.Open(chunk.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open source file")
+	}
+	defer file.Close()
+
+	data := make([]byte, chunk.size)
+	if _, err := file.ReadAt(data, chunk.offset); err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to read source file")
+	}
+
+	// The file may have changed since it was hashed.
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != hash {
+		return errors.Errorf("source file '%s' changed during upload", chunk.path)
+	}
+
+	endpoint := api.Routes.Path("AppChunkUpload", namespace, name, hash)
+	if err := c.putRetried(endpoint, data); err != nil {
+		return errors.Wrapf(err, "failed to send chunk of '%s'", chunk.path)
+	}
+
+	return nil
+}
+
+// sourceFiles returns the files of the app sources in the directory, for an upload as delta,
+// and the locations of their chunks, by hash.
+func sourceFiles(dir string) ([]models.SourceFile, map[string]sourceChunk, error) {
+	files := []models.SourceFile{}
+	chunks := map[string]sourceChunk{}
+
+	err := helpers.WalkSources(dir, func(name, fpath string, info os.FileInfo) error {
+		file := models.SourceFile{
+			Path: name,
+			Mode: uint32(info.Mode()),
+		}
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(fpath)
+			if err != nil {
+				return err
+			}
+			file.Link = filepath.ToSlash(link)
+
+		case info.Mode().IsRegular():
+			hashes, err := chunkFile(fpath, chunks)
+			if err != nil {
+				return err
+			}
+			file.Size = info.Size()
+			file.Chunks = hashes
+
+		case !info.IsDir():
+			// Sockets, devices, and the like are no sources.
+			return nil
+		}
+
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, chunks, nil
+}
+
+// chunkFile returns the hashes of the chunks of the file, and records their locations.
+func chunkFile(fpath string, chunks map[string]sourceChunk) ([]string, error) {
+	file, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hashes := []string{}
+	offset := int64(0)
+	buffer := make([]byte, SourceChunkSize)
+	for {
+		n, err := io.ReadFull(file, buffer)
+		if n > 0 {
+			sum := sha256.Sum256(buffer[:n])
+			hash := hex.EncodeToString(sum[:])
+			hashes = append(hashes, hash)
+			chunks[hash] = sourceChunk{path: fpath, offset: offset, size: int64(n)}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return hashes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
 // AppImportGit asks the server to import a git repo and put in into the blob store
 func (c *Client) AppImportGit(app models.AppRef, gitRef models.GitRef) (*models.ImportGitResponse, error) {
 	data := url.Values{}
@@ -269,7 +731,7 @@ func (c *Client) AppImportGit(app models.AppRef, gitRef models.GitRef) (*models.
 	if err != nil {
 		return nil, errors.Wrap(err, "constructing the request")
 	}
-	request.SetBasicAuth(c.user, c.password)
+	c.authorize(request)
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	request.Header.Add("Content-Length", strconv.Itoa(len(data.Encode())))
 
@@ -305,7 +767,7 @@ func (c *Client) AppStage(req models.StageRequest) (*models.StageResponse, error
 		return nil, errors.Wrap(err, "can't marshal stage request")
 	}
 
-	b, err := c.post(api.Routes.Path("AppStage", req.App.Namespace, req.App.Name), string(out))
+	b, err := c.postIdempotent(api.Routes.Path("AppStage", req.App.Namespace, req.App.Name), string(out))
 	if err != nil {
 		return nil, errors.Wrap(err, "can't stage app")
 	}
@@ -323,12 +785,23 @@ func (c *Client) AppStage(req models.StageRequest) (*models.StageResponse, error
 
 // AppDeploy deploys a staged app
 func (c *Client) AppDeploy(req models.DeployRequest) (*models.DeployResponse, error) {
+	return c.appDeploy(req, api.Routes.Path("AppDeploy", req.App.Namespace, req.App.Name))
+}
+
+// AppDeployAsync deploys the app in the background. The response has the id of the operation
+// doing it, see OperationShow. Servers without asynchronous deployments deploy right away, and
+// respond with the routes of the app instead.
+func (c *Client) AppDeployAsync(req models.DeployRequest) (*models.DeployResponse, error) {
+	return c.appDeploy(req, api.Routes.Path("AppDeploy", req.App.Namespace, req.App.Name)+"?async=true")
+}
+
+func (c *Client) appDeploy(req models.DeployRequest, endpoint string) (*models.DeployResponse, error) {
 	out, err := json.Marshal(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't marshal deploy request")
 	}
 
-	b, err := c.post(api.Routes.Path("AppDeploy", req.App.Namespace, req.App.Name), string(out))
+	b, err := c.postIdempotent(endpoint, string(out))
 	if err != nil {
 		return nil, errors.Wrap(err, "can't deploy app")
 	}
@@ -347,11 +820,14 @@ func (c *Client) AppDeploy(req models.DeployRequest) (*models.DeployResponse, er
 // AppLogs streams the logs of all the application instances, in the targeted namespace
 // If stageID is an empty string, runtime application logs are streamed. If stageID
 // is set, then the matching staging logs are streamed.
-// Logs are streamed through the returned channel.
-// There are 2 ways of stopping this method:
-// 1. The websocket connection closes.
-// 2. The context is canceled (used by the caller when printing of logs should be stopped).
-func (c *Client) AppLogs(namespace, appName, stageID string, follow bool, printCallback func(tailer.ContainerLogLine)) error {
+// Logs are streamed through the callback, until the websocket connection closes.
+func (c *Client) AppLogs(namespace, appName, stageID string, follow bool, params models.LogParameters, printCallback func(tailer.ContainerLogLine)) error {
+	return c.AppLogsContext(context.Background(), namespace, appName, stageID, follow, params, printCallback)
+}
+
+// AppLogsContext streams the logs like AppLogs, until the websocket connection closes, or the
+// context is done, e.g. when the logs are no longer wanted.
+func (c *Client) AppLogsContext(ctx context.Context, namespace, appName, stageID string, follow bool, params models.LogParameters, printCallback func(tailer.ContainerLogLine)) error {
 
 	token, err := c.AuthToken()
 	if err != nil {
@@ -362,6 +838,18 @@ func (c *Client) AppLogs(namespace, appName, stageID string, follow bool, printC
 	queryParams.Add("follow", strconv.FormatBool(follow))
 	queryParams.Add("stage_id", stageID)
 	queryParams.Add("authtoken", token)
+	if params.Instance != "" {
+		queryParams.Add("instance", params.Instance)
+	}
+	if params.Since > 0 {
+		queryParams.Add("since", params.Since.String())
+	}
+	if params.Tail != nil {
+		queryParams.Add("tail", strconv.FormatInt(*params.Tail, 10))
+	}
+	if params.Filter != "" {
+		queryParams.Add("filter", params.Filter)
+	}
 
 	var endpoint string
 	if stageID == "" {
@@ -371,10 +859,22 @@ func (c *Client) AppLogs(namespace, appName, stageID string, follow bool, printC
 	}
 
 	websocketURL := fmt.Sprintf("%s%s/%s?%s", c.WsURL, api.WsRoot, endpoint, queryParams.Encode())
-	webSocketConn, resp, err := websocket.DefaultDialer.Dial(websocketURL, http.Header{})
+	webSocketConn, resp, err := websocket.DefaultDialer.DialContext(ctx, websocketURL, http.Header{})
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("Failed to connect to websockets endpoint. Response was = %+v\nThe error is", resp))
 	}
+	defer webSocketConn.Close()
+
+	// Closing the connection ends the reading below.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			webSocketConn.Close()
+		case <-done:
+		}
+	}()
 
 	var logLine tailer.ContainerLogLine
 	for {
@@ -391,6 +891,42 @@ func (c *Client) AppLogs(namespace, appName, stageID string, follow bool, printC
 	}
 }
 
+// AppEvents streams the events of an app to the callback, until the server closes the
+// connection. When following, the server keeps the connection open, for new events.
+func (c *Client) AppEvents(namespace, appName string, follow bool, callback func(models.AppEvent)) error {
+	token, err := c.AuthToken()
+	if err != nil {
+		return err
+	}
+
+	queryParams := url.Values{}
+	queryParams.Add("follow", strconv.FormatBool(follow))
+	queryParams.Add("authtoken", token)
+
+	endpoint := api.WsRoutes.Path("AppEvents", namespace, appName)
+
+	websocketURL := fmt.Sprintf("%s%s/%s?%s", c.WsURL, api.WsRoot, endpoint, queryParams.Encode())
+	webSocketConn, resp, err := websocket.DefaultDialer.Dial(websocketURL, http.Header{})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to connect to websockets endpoint. Response was = %+v\nThe error is", resp))
+	}
+	defer webSocketConn.Close()
+
+	for {
+		_, message, err := webSocketConn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+
+		var event models.AppEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			return errors.Wrap(err, "error parsing event")
+		}
+
+		callback(event)
+	}
+}
+
 // StagingComplete checks if the staging process is complete
 func (c *Client) StagingComplete(namespace string, id string) (models.Response, error) {
 	resp := models.Response{}
@@ -440,6 +976,24 @@ func (c *Client) StagingComplete(namespace string, id string) (models.Response,
 	return resp, nil
 }
 
+// StagingQueue returns the position of a staging process in the queue of staging jobs
+func (c *Client) StagingQueue(namespace string, id string) (models.StagingQueueResponse, error) {
+	resp := models.StagingQueueResponse{}
+
+	data, err := c.get(api.Routes.Path("StagingQueue", namespace, id))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
 // AppRunning checks if the app is running
 func (c *Client) AppRunning(app models.AppRef) (models.Response, error) {
 	resp := models.Response{}
@@ -486,6 +1040,39 @@ func (c *Client) AppRunning(app models.AppRef) (models.Response, error) {
 }
 
 func (c *Client) AppExec(namespace string, appName, instance string, tty kubectlterm.TTY) error {
+	exec, err := c.appExecutor(namespace, appName, instance)
+	if err != nil {
+		return err
+	}
+
+	fn := func() error {
+		options := remotecommand.StreamOptions{
+			Stdin:             tty.In,
+			Stdout:            tty.Out,
+			Stderr:            tty.Out, // Not used when tty. Check `exec.Stream` docs.
+			Tty:               tty.Raw,
+			TerminalSizeQueue: tty.MonitorSize(tty.GetSize()),
+		}
+
+		return exec.Stream(options)
+	}
+
+	return tty.Safe(fn)
+}
+
+// AppExecStream runs a shell in the instance of the app, like AppExec, with the streams, and
+// the terminal size queue, of the options, instead of a terminal.
+func (c *Client) AppExecStream(namespace string, appName, instance string, options remotecommand.StreamOptions) error {
+	exec, err := c.appExecutor(namespace, appName, instance)
+	if err != nil {
+		return err
+	}
+
+	return exec.Stream(options)
+}
+
+// appExecutor returns the executor of the shells in the instance of the app.
+func (c *Client) appExecutor(namespace string, appName, instance string) (remotecommand.Executor, error) {
 	endpoint := fmt.Sprintf("%s%s/%s",
 		c.URL, api.WsRoot, api.WsRoutes.Path("AppExec", namespace, appName))
 
@@ -498,11 +1085,11 @@ func (c *Client) AppExec(namespace string, appName, instance string, tty kubectl
 
 	execURL, err := url.Parse(endpoint)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := c.addAuthTokenToURL(execURL); err != nil {
-		return err
+		return nil, err
 	}
 
 	if instance != "" {
@@ -512,24 +1099,7 @@ func (c *Client) AppExec(namespace string, appName, instance string, tty kubectl
 	}
 
 	// upgradeRoundTripper implements both interfaces, Roundtripper and Upgrader
-	exec, err := remotecommand.NewSPDYExecutorForTransports(upgradeRoundTripper, upgradeRoundTripper, "GET", execURL)
-	if err != nil {
-		return err
-	}
-
-	fn := func() error {
-		options := remotecommand.StreamOptions{
-			Stdin:             tty.In,
-			Stdout:            tty.Out,
-			Stderr:            tty.Out, // Not used when tty. Check `exec.Stream` docs.
-			Tty:               tty.Raw,
-			TerminalSizeQueue: tty.MonitorSize(tty.GetSize()),
-		}
-
-		return exec.Stream(options)
-	}
-
-	return tty.Safe(fn)
+	return remotecommand.NewSPDYExecutorForTransports(upgradeRoundTripper, upgradeRoundTripper, "GET", execURL)
 }
 
 type PortForwardOpts struct {
@@ -590,7 +1160,12 @@ func (c *Client) AppPortForward(namespace string, appName, instance string, opts
 		PingPeriod:               time.Second * 5,
 	})
 
-	wrapper := transport.NewBasicAuthRoundTripper(c.user, c.password, upgradeRoundTripper)
+	var wrapper http.RoundTripper
+	if c.token != "" {
+		wrapper = transport.NewBearerAuthRoundTripper(c.token, upgradeRoundTripper)
+	} else {
+		wrapper = transport.NewBasicAuthRoundTripper(c.user, c.password, upgradeRoundTripper)
+	}
 
 	dialer := gospdy.NewDialer(upgradeRoundTripper, &http.Client{Transport: wrapper}, "GET", portForwardURL)
 	fw, err := portforward.NewOnAddresses(dialer, opts.Address, opts.Ports, opts.StopChannel, opts.ReadyChannel, opts.Out, opts.ErrOut)
@@ -625,3 +1200,220 @@ func (c *Client) AppRestart(namespace string, appName string) error {
 
 	return nil
 }
+
+// AppBatchRestart restarts the named apps, or all active ones, of the namespace, in one call
+func (c *Client) AppBatchRestart(namespace string, req models.BatchRestartRequest) (models.BatchRestartResponse, error) {
+	resp := models.BatchRestartResponse{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("AppBatchRestart", namespace), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AppSleep puts an app to sleep
+func (c *Client) AppSleep(namespace string, appName string) error {
+	endpoint := api.Routes.Path("AppSleep", namespace, appName)
+
+	if _, err := c.post(endpoint, ""); err != nil {
+		errorMsg := fmt.Sprintf("error putting app %s in namespace %s to sleep", appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
+
+// AppWake wakes a sleeping app
+func (c *Client) AppWake(namespace string, appName string) error {
+	endpoint := api.Routes.Path("AppWake", namespace, appName)
+
+	if _, err := c.post(endpoint, ""); err != nil {
+		errorMsg := fmt.Sprintf("error waking app %s in namespace %s", appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
+
+// AppCanaryPromote shifts traffic to the canary of an app, or makes it the stable version
+func (c *Client) AppCanaryPromote(namespace string, appName string, weight int) error {
+	b, err := json.Marshal(models.ApplicationCanaryPromoteRequest{Weight: weight})
+	if err != nil {
+		return errors.Wrap(err, "can't marshal canary promote request")
+	}
+
+	endpoint := api.Routes.Path("AppCanaryPromote", namespace, appName)
+
+	if _, err := c.post(endpoint, string(b)); err != nil {
+		errorMsg := fmt.Sprintf("error promoting canary of app %s in namespace %s", appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
+
+// AppTasks returns the scheduled tasks of an app
+func (c *Client) AppTasks(namespace string, appName string) (models.AppTaskList, error) {
+	var resp models.AppTaskList
+
+	data, err := c.get(api.Routes.Path("AppTasks", namespace, appName))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AppTaskRun runs a scheduled task of an app now
+func (c *Client) AppTaskRun(namespace string, appName string, taskName string) (models.AppTaskRunResponse, error) {
+	var resp models.AppTaskRunResponse
+
+	data, err := c.post(api.Routes.Path("AppTaskRun", namespace, appName, taskName), "")
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AppDomains returns the custom domains of an app
+func (c *Client) AppDomains(namespace string, appName string) (models.AppDomainList, error) {
+	var resp models.AppDomainList
+
+	data, err := c.get(api.Routes.Path("AppDomains", namespace, appName))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AppDomainAdd adds a custom domain to an app, or retries the verification of its ownership
+func (c *Client) AppDomainAdd(namespace string, appName string, domain string) (models.AppDomainStatus, error) {
+	var resp models.AppDomainStatus
+
+	b, err := json.Marshal(models.AppDomainRequest{Domain: domain})
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("AppDomainAdd", namespace, appName), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AppDomainRemove removes a custom domain from an app
+func (c *Client) AppDomainRemove(namespace string, appName string, domain string) error {
+	endpoint := api.Routes.Path("AppDomainRemove", namespace, appName, domain)
+
+	if _, err := c.delete(endpoint); err != nil {
+		errorMsg := fmt.Sprintf("error removing domain %s of app %s in namespace %s", domain, appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
+
+// AppCanaryAbort removes the canary of an app
+func (c *Client) AppCanaryAbort(namespace string, appName string) error {
+	endpoint := api.Routes.Path("AppCanaryAbort", namespace, appName)
+
+	if _, err := c.delete(endpoint); err != nil {
+		errorMsg := fmt.Sprintf("error aborting canary of app %s in namespace %s", appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
+
+// AppRevisions returns the recorded deployment revisions of an app
+func (c *Client) AppRevisions(namespace string, appName string) (models.AppRevisionList, error) {
+	var resp models.AppRevisionList
+
+	data, err := c.get(api.Routes.Path("AppRevisions", namespace, appName))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// AppRename renames an app
+func (c *Client) AppRename(namespace string, appName string, newName string) error {
+	b, err := json.Marshal(models.ApplicationRenameRequest{Name: newName})
+	if err != nil {
+		return errors.Wrap(err, "can't marshal rename request")
+	}
+
+	endpoint := api.Routes.Path("AppRename", namespace, appName)
+
+	if _, err := c.post(endpoint, string(b)); err != nil {
+		errorMsg := fmt.Sprintf("error renaming app %s in namespace %s", appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
+
+// AppRollback rolls an app back to a previous revision
+func (c *Client) AppRollback(namespace string, appName string, revision int) error {
+	b, err := json.Marshal(models.ApplicationRollbackRequest{Revision: revision})
+	if err != nil {
+		return errors.Wrap(err, "can't marshal rollback request")
+	}
+
+	endpoint := api.Routes.Path("AppRollback", namespace, appName)
+
+	if _, err := c.post(endpoint, string(b)); err != nil {
+		errorMsg := fmt.Sprintf("error rolling back app %s in namespace %s", appName, namespace)
+		return errors.Wrap(err, errorMsg)
+	}
+
+	return nil
+}
@@ -6,4 +6,8 @@ import (
 
 var _ = Describe("Client Apps unit tests", func() {
 	Describe("AppRestart", DescribeAppRestart)
+	Describe("AppRollback", DescribeAppRollback)
+	Describe("AppUpload", DescribeAppUpload)
+	Describe("AppUploadDelta", DescribeAppUploadDelta)
+	Describe("Apps", DescribeAppList)
 })
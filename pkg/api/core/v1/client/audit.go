@@ -0,0 +1,38 @@
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// AuditEntries returns the recent audit entries of the user in the namespace, at most limit
+// of them. Empty user and namespace return the entries of all users, and namespaces.
+func (c *Client) AuditEntries(user, namespace string, limit int) (models.AuditEntryList, error) {
+	var resp models.AuditEntryList
+
+	query := url.Values{}
+	query.Add("limit", strconv.Itoa(limit))
+	if user != "" {
+		query.Add("user", user)
+	}
+	if namespace != "" {
+		query.Add("namespace", namespace)
+	}
+
+	data, err := c.get(api.Routes.Path("AuditEntries") + "?" + query.Encode())
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Backups returns the backups of the platform state
+func (c *Client) Backups() (models.BackupList, error) {
+	var resp models.BackupList
+
+	data, err := c.get(api.Routes.Path("Backups"))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// BackupCreate backs up the platform state
+func (c *Client) BackupCreate(req models.BackupCreateRequest) (models.Backup, error) {
+	resp := models.Backup{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("BackupCreate"), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// BackupRestore restores the platform state from the backup
+func (c *Client) BackupRestore(name string) (models.BackupRestoreResponse, error) {
+	resp := models.BackupRestoreResponse{}
+
+	data, err := c.post(api.Routes.Path("BackupRestore", name), "")
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
@@ -2,8 +2,13 @@
 package client
 
 import (
+	"net/http"
+	"os"
+
 	"github.com/epinio/epinio/helpers/tracelog"
+	"github.com/epinio/epinio/internal/tracing"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client provides functionality for talking to an Epinio API
@@ -14,17 +19,49 @@ type Client struct {
 	WsURL    string // only stored here for the memo, the websocket client is not part of the epinioapi, yet.
 	user     string
 	password string
+	token    string
+	retry    RetryPolicy
+	trace    trace.SpanContext
+	etags    *etagCache
+	versions *versionsMemo
 }
 
 // New returns a new Epinio API client
 func New(url string, wsURL string, user string, password string) *Client {
 	log := tracelog.NewLogger().WithName("EpinioApiClient").V(3)
 
+	// All the requests of a client, i.e. of a command, are part of one trace. TRACEPARENT
+	// makes the command part of the trace of its caller, e.g. of a CI pipeline, sampled, or
+	// not, as the caller decided.
+	sc := tracing.SpanContext(os.Getenv("TRACEPARENT"))
+	log.Info("trace", "traceId", sc.TraceID().String(), "sampled", sc.IsSampled())
+
 	return &Client{
 		log:      log,
 		URL:      url,
 		WsURL:    wsURL,
 		user:     user,
 		password: password,
+		retry:    DefaultRetryPolicy,
+		trace:    sc,
+		etags:    newETagCache(),
+		versions: &versionsMemo{},
+	}
+}
+
+// SetToken makes the client authenticate with the bearer token, an OIDC ID token, instead
+// of the user and password.
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// authorize adds the credentials of the client to the request, and the trace context of the
+// client.
+func (c *Client) authorize(request *http.Request) {
+	tracing.Inject(c.trace, request.Header)
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+		return
 	}
+	request.SetBasicAuth(c.user, c.password)
 }
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// ClientCertificateCreate returns a client certificate issued for the certificate request
+func (c *Client) ClientCertificateCreate(req models.ClientCertificateRequest) (models.ClientCertificateResponse, error) {
+	var resp models.ClientCertificateResponse
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("ClientCertificateCreate"), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -9,37 +9,45 @@ import (
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 )
 
-// Configurations returns a list of configurations for the specified namespace
+// Configurations returns a list of configurations for the specified namespace, requested
+// page by page
 func (c *Client) Configurations(namespace string) (models.ConfigurationResponseList, error) {
 	resp := models.ConfigurationResponseList{}
 
-	data, err := c.get(api.Routes.Path("Configurations", namespace))
+	err := c.getAll("Configurations", api.Routes.Path("Configurations", namespace), func(data []byte) (int, error) {
+		var page models.ConfigurationResponseList
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		resp = append(resp, page...)
+		return len(page), nil
+	})
 	if err != nil {
 		return resp, err
 	}
 
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return resp, err
-	}
-
 	c.log.V(1).Info("response decoded", "response", resp)
 
 	return resp, nil
 }
 
-// AllConfigurations returns a list of all configurations, across all namespaces
+// AllConfigurations returns a list of all configurations, across all namespaces, requested
+// page by page
 func (c *Client) AllConfigurations() (models.ConfigurationResponseList, error) {
 	resp := models.ConfigurationResponseList{}
 
-	data, err := c.get(api.Routes.Path("AllConfigurations"))
+	err := c.getAll("AllConfigurations", api.Routes.Path("AllConfigurations"), func(data []byte) (int, error) {
+		var page models.ConfigurationResponseList
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		resp = append(resp, page...)
+		return len(page), nil
+	})
 	if err != nil {
 		return resp, err
 	}
 
-	if err := json.Unmarshal(data, &resp); err != nil {
-		return resp, err
-	}
-
 	c.log.V(1).Info("response decoded", "response", resp)
 
 	return resp, nil
@@ -116,6 +124,29 @@ func (c *Client) ConfigurationDelete(req models.ConfigurationDeleteRequest, name
 	return resp, nil
 }
 
+// ConfigurationBatchDelete deletes several configurations of a namespace in one call
+func (c *Client) ConfigurationBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+	resp := models.BatchDeleteResponse{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.do(api.Routes.Path("ConfigurationBatchDelete", namespace), "DELETE", string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
 // ConfigurationCreate creates a configuration by invoking the associated API endpoint
 func (c *Client) ConfigurationCreate(req models.ConfigurationCreateRequest, namespace string) (models.Response, error) {
 	resp := models.Response{}
@@ -127,7 +158,7 @@ func (c *Client) ConfigurationCreate(req models.ConfigurationCreateRequest, name
 		return resp, nil
 	}
 
-	data, err := c.post(api.Routes.Path("ConfigurationCreate", namespace), string(b))
+	data, err := c.postIdempotent(api.Routes.Path("ConfigurationCreate", namespace), string(b))
 	if err != nil {
 		return resp, err
 	}
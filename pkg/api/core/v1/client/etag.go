@@ -0,0 +1,49 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+)
+
+// etagCacheSize is the number of responses a client keeps for conditional requests.
+const etagCacheSize = 64
+
+// cachedResponse is a response to a GET request, with its ETag.
+type cachedResponse struct {
+	etag   string
+	body   []byte
+	header http.Header
+}
+
+// etagCache keeps the tagged responses of the GET requests of a client, by URI. The requests
+// are repeated with the tag, and a 304 from the server stands for the kept response. Polling
+// an unchanged resource costs the server no body then.
+type etagCache struct {
+	mutex     sync.Mutex
+	responses map[string]cachedResponse
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{responses: map[string]cachedResponse{}}
+}
+
+// get returns the response kept for the URI, if any.
+func (e *etagCache) get(uri string) (cachedResponse, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	cached, ok := e.responses[uri]
+	return cached, ok
+}
+
+// put keeps the response for the URI. A full cache is emptied first, polling clients request
+// a few URIs over and over.
+func (e *etagCache) put(uri string, cached cachedResponse) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, ok := e.responses[uri]; !ok && len(e.responses) >= etagCacheSize {
+		e.responses = map[string]cachedResponse{}
+	}
+	e.responses[uri] = cached
+}
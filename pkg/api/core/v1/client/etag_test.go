@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/epinio/epinio/internal/api/v1/response"
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client conditional requests", func() {
+
+	var epinioClient *client.Client
+	var app models.App
+	var statuses []int
+
+	BeforeEach(func() {
+		app = models.App{Meta: models.AppRef{Meta: models.Meta{Name: "appname", Namespace: "namespace-foo"}}}
+		statuses = []int{}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Next()
+			statuses = append(statuses, c.Writer.Status())
+		})
+		router.GET("/api/v1/namespaces/:namespace/applications/:app", func(c *gin.Context) {
+			response.OKReturnETag(c, app)
+		})
+		srv := httptest.NewServer(router)
+		DeferCleanup(srv.Close)
+
+		epinioClient = client.New(srv.URL, "", "", "")
+	})
+
+	It("gets an unchanged resource as not modified", func() {
+		first, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).ToNot(HaveOccurred())
+
+		second, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second).To(Equal(first))
+
+		Expect(statuses).To(Equal([]int{http.StatusOK, http.StatusNotModified}))
+	})
+
+	It("gets a changed resource in full", func() {
+		_, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).ToNot(HaveOccurred())
+
+		app.Configuration.Instances = new(int32)
+		*app.Configuration.Instances = 3
+
+		changed, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*changed.Configuration.Instances).To(Equal(int32(3)))
+
+		Expect(statuses).To(Equal([]int{http.StatusOK, http.StatusOK}))
+	})
+})
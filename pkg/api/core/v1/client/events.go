@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// Events streams the platform events of the namespace, or of all namespaces of the user, to
+// the callback, until the server closes the connection. Types restrict the events to these
+// types, or to types starting with them. When the server drops the connection for falling
+// behind, it reconnects, and continues after the last event received.
+func (c *Client) Events(namespace string, types []string, callback func(models.PlatformEvent)) error {
+	var after uint64
+	for {
+		token, err := c.AuthToken()
+		if err != nil {
+			return err
+		}
+
+		queryParams := url.Values{}
+		queryParams.Add("authtoken", token)
+		if namespace != "" {
+			queryParams.Add("namespace", namespace)
+		}
+		if len(types) > 0 {
+			queryParams.Add("type", strings.Join(types, ","))
+		}
+		if after > 0 {
+			queryParams.Add("after", strconv.FormatUint(after, 10))
+		}
+
+		endpoint := api.WsRoutes.Path("Events")
+
+		websocketURL := fmt.Sprintf("%s%s/%s?%s", c.WsURL, api.WsRoot, endpoint, queryParams.Encode())
+		webSocketConn, resp, err := websocket.DefaultDialer.Dial(websocketURL, http.Header{})
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to connect to websockets endpoint. Response was = %+v\nThe error is", resp))
+		}
+
+		err = readEvents(webSocketConn, func(event models.PlatformEvent) {
+			after = event.ID
+			callback(event)
+		})
+		if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+			return err
+		}
+
+		c.log.V(1).Info("reconnecting to the event stream", "after", after)
+	}
+}
+
+// readEvents passes the events of the connection to the callback, until it is closed. Only
+// bad events, and the connection closing to try again later, are errors.
+func readEvents(conn *websocket.Conn, callback func(models.PlatformEvent)) error {
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+				return err
+			}
+			return nil
+		}
+
+		var event models.PlatformEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			return errors.Wrap(err, "error parsing event")
+		}
+
+		callback(event)
+	}
+}
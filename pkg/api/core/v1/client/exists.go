@@ -0,0 +1,61 @@
+package client
+
+import (
+	api "github.com/epinio/epinio/internal/api/v1"
+)
+
+// exists returns true if the resource of the show route exists, and false if the server
+// reports it as not found. Other errors are returned.
+func (c *Client) exists(route string, params ...interface{}) (bool, error) {
+	_, err := c.get(api.Routes.Path(route, params...))
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	c.log.V(1).Info("resource exists", "route", route, "params", params)
+
+	return true, nil
+}
+
+// NamespaceExists returns true if the namespace exists
+func (c *Client) NamespaceExists(namespace string) (bool, error) {
+	return c.exists("NamespaceShow", namespace)
+}
+
+// AppExists returns true if the app exists in the namespace
+func (c *Client) AppExists(namespace, name string) (bool, error) {
+	return c.exists("AppShow", namespace, name)
+}
+
+// ConfigurationExists returns true if the configuration exists in the namespace
+func (c *Client) ConfigurationExists(namespace, name string) (bool, error) {
+	return c.exists("ConfigurationShow", namespace, name)
+}
+
+// ServiceExists returns true if the service exists in the namespace
+func (c *Client) ServiceExists(namespace, name string) (bool, error) {
+	return c.exists("ServiceShow", namespace, name)
+}
+
+// ChartExists returns true if the app chart exists
+func (c *Client) ChartExists(name string) (bool, error) {
+	return c.exists("ChartShow", name)
+}
+
+// TeamExists returns true if the team exists
+func (c *Client) TeamExists(name string) (bool, error) {
+	return c.exists("TeamShow", name)
+}
+
+// WebhookExists returns true if the webhook exists
+func (c *Client) WebhookExists(name string) (bool, error) {
+	return c.exists("WebhookShow", name)
+}
+
+// APITokenExists returns true if the API token of the user exists
+func (c *Client) APITokenExists(id string) (bool, error) {
+	return c.exists("APITokenShow", id)
+}
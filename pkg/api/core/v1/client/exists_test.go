@@ -0,0 +1,67 @@
+package client_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client resource lookup unit tests", func() {
+
+	var epinioClient *client.Client
+
+	BeforeEach(func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case api.Root + "/namespaces/workspace/applications/app":
+				fmt.Fprint(w, `{"meta":{"name":"app","namespace":"workspace"}}`)
+			case api.Root + "/teams/backend":
+				fmt.Fprint(w, `{"name":"backend","members":["jane"]}`)
+			case api.Root + "/webhooks/broken":
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"errors":[{"status":500,"title":"broken"}]}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors":[{"status":404,"title":"not found"}]}`)
+			}
+		}))
+		DeferCleanup(srv.Close)
+
+		epinioClient = client.New(srv.URL, "", "", "")
+	})
+
+	It("reports existing, and missing, resources", func() {
+		exists, err := epinioClient.AppExists("workspace", "app")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		exists, err = epinioClient.AppExists("workspace", "other")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+
+		exists, err = epinioClient.TeamExists("backend")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeTrue())
+	})
+
+	It("returns the errors other than not found", func() {
+		_, err := epinioClient.WebhookExists("broken")
+		Expect(err).To(HaveOccurred())
+		Expect(client.IsNotFound(err)).To(BeFalse())
+	})
+
+	It("shows resources by id, and reports missing ones as not found", func() {
+		team, err := epinioClient.TeamShow("backend")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(team.Name).To(Equal("backend"))
+		Expect(team.Members).To(Equal([]string{"jane"}))
+
+		_, err = epinioClient.APITokenShow("unknown")
+		Expect(client.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -14,10 +14,12 @@ import (
 	"strings"
 
 	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/internal/idempotency"
 	apierrors "github.com/epinio/epinio/pkg/api/core/v1/errors"
 	"github.com/epinio/epinio/pkg/api/core/v1/models"
 
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -33,6 +35,28 @@ func wrapResponseError(err error, code int) *responseError {
 	return &responseError{error: err, statusCode: code}
 }
 
+// unreachableError wraps the errors of requests which got no response from the server, e.g.
+// because it is down, or the network is.
+type unreachableError struct {
+	error
+}
+
+func (ue *unreachableError) Unwrap() error { return ue.error }
+
+// IsUnreachable returns true if the error is from a request which got no response from the
+// server.
+func IsUnreachable(err error) bool {
+	var uerr *unreachableError
+	return errors.As(err, &uerr)
+}
+
+// IsNotFound returns true if the error is from a request for a resource which does not
+// exist.
+func IsNotFound(err error) bool {
+	var rerr *responseError
+	return errors.As(err, &rerr) && rerr.StatusCode() == http.StatusNotFound
+}
+
 func (c *Client) get(endpoint string) ([]byte, error) {
 	return c.do(endpoint, "GET", "")
 }
@@ -41,6 +65,17 @@ func (c *Client) post(endpoint string, data string) ([]byte, error) {
 	return c.do(endpoint, "POST", data)
 }
 
+// postIdempotent is post with an idempotency key, for requests creating, or deploying,
+// things. The server runs the request once, and replays its response to retries, so that
+// they are retried like the idempotent requests.
+func (c *Client) postIdempotent(endpoint string, data string) ([]byte, error) {
+	header := http.Header{}
+	header.Set(idempotency.KeyHeader, uuid.New().String())
+
+	body, _, err := c.doRequest(api.Root, endpoint, "POST", strings.NewReader(data), data, header)
+	return body, err
+}
+
 func (c *Client) patch(endpoint string, data string) ([]byte, error) {
 	return c.do(endpoint, "PATCH", data)
 }
@@ -49,8 +84,14 @@ func (c *Client) delete(endpoint string) ([]byte, error) {
 	return c.do(endpoint, "DELETE", "")
 }
 
-// upload the given path as param "file" in a multipart form
-func (c *Client) upload(endpoint string, path string) ([]byte, error) {
+// put sends the binary data, as is
+func (c *Client) put(endpoint string, data []byte) ([]byte, error) {
+	return c.doBody(endpoint, "PUT", bytes.NewReader(data), fmt.Sprintf("<%d bytes>", len(data)))
+}
+
+// upload the given path as param "file" in a multipart form. The form is streamed, i.e.
+// the file is read while sending it.
+func (c *Client) upload(endpoint string, path string, progress UploadProgress) ([]byte, error) {
 	uri := fmt.Sprintf("%s%s/%s", c.URL, api.Root, endpoint)
 
 	// open the tarball
@@ -60,31 +101,38 @@ func (c *Client) upload(endpoint string, path string) ([]byte, error) {
 	}
 	defer file.Close()
 
-	// create multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", filepath.Base(file.Name()))
+	info, err := file.Stat()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create multiform part")
+		return nil, errors.Wrap(err, "failed to stat tarball")
 	}
 
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to write to multiform part")
-	}
+	// create multipart form, written by a goroutine while the request reads it
+	body, pipe := io.Pipe()
+	writer := multipart.NewWriter(pipe)
+	go func() {
+		part, err := writer.CreateFormFile("file", filepath.Base(file.Name()))
+		if err != nil {
+			_ = pipe.CloseWithError(errors.Wrap(err, "failed to create multiform part"))
+			return
+		}
 
-	err = writer.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to close multiform")
-	}
+		_, err = io.Copy(part, &progressReader{Reader: file, total: info.Size(), progress: progress})
+		if err != nil {
+			_ = pipe.CloseWithError(errors.Wrap(err, "failed to write to multiform part"))
+			return
+		}
+
+		_ = pipe.CloseWithError(writer.Close())
+	}()
 
 	// make the request
 	request, err := http.NewRequest("POST", uri, body)
 	if err != nil {
+		_ = body.Close()
 		return nil, errors.Wrap(err, "failed to build request")
 	}
 
-	request.SetBasicAuth(c.user, c.password)
+	c.authorize(request)
 	request.Header.Add("Content-Type", writer.FormDataContentType())
 
 	response, err := (&http.Client{}).Do(request)
@@ -108,32 +156,82 @@ func (c *Client) upload(endpoint string, path string) ([]byte, error) {
 	return bodyBytes, nil
 }
 
+// progressReader reports the bytes read through it to the progress callback, if any.
+type progressReader struct {
+	io.Reader
+	sent     int64
+	total    int64
+	progress UploadProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.sent += int64(n)
+	if r.progress != nil && n > 0 {
+		r.progress(r.sent, r.total)
+	}
+	return n, err
+}
+
 func (c *Client) do(endpoint, method, requestBody string) ([]byte, error) {
-	uri := fmt.Sprintf("%s%s/%s", c.URL, api.Root, endpoint)
+	return c.doBody(endpoint, method, strings.NewReader(requestBody), requestBody)
+}
+
+// doBody sends the body, and logs the description of it
+func (c *Client) doBody(endpoint, method string, body io.Reader, logBody string) ([]byte, error) {
+	data, _, err := c.doBodyHeader(endpoint, method, body, logBody)
+	return data, err
+}
+
+// doBodyHeader is doBody, also returning the header of the response
+func (c *Client) doBodyHeader(endpoint, method string, body io.Reader, logBody string) ([]byte, http.Header, error) {
+	return c.doRootBodyHeader(api.Root, endpoint, method, body, logBody)
+}
+
+// doRootBodyHeader is doBodyHeader, for the endpoint of the API root, i.e. version
+func (c *Client) doRootBodyHeader(root, endpoint, method string, body io.Reader, logBody string) ([]byte, http.Header, error) {
+	return c.doRequest(root, endpoint, method, body, logBody, nil)
+}
+
+// doRequest is doRootBodyHeader, adding the header, if any, to the request
+func (c *Client) doRequest(root, endpoint, method string, body io.Reader, logBody string, header http.Header) ([]byte, http.Header, error) {
+	uri := fmt.Sprintf("%s%s/%s", c.URL, root, endpoint)
 	c.log.Info(fmt.Sprintf("%s %s", method, uri))
 
-	reqLog := requestLogger(c.log, method, uri, requestBody)
+	reqLog := requestLogger(c.log, method, uri, logBody)
 
-	request, err := http.NewRequest(method, uri, strings.NewReader(requestBody))
+	request, err := http.NewRequest(method, uri, body)
 	if err != nil {
 		reqLog.V(1).Error(err, "cannot build request")
-		return []byte{}, err
+		return []byte{}, nil, err
 	}
 
-	request.SetBasicAuth(c.user, c.password)
+	c.authorize(request)
+	for name, values := range header {
+		request.Header[name] = values
+	}
 
-	response, err := (&http.Client{}).Do(request)
+	// Repeated GET requests are conditional, see etagCache.
+	cached, isCached := cachedResponse{}, false
+	if method == http.MethodGet {
+		cached, isCached = c.etags.get(uri)
+		if isCached {
+			request.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+
+	response, err := c.send(request, reqLog)
 	if err != nil {
 		reqLog.V(1).Error(err, "request failed")
 		castedErr, ok := err.(*url.Error)
 		if !ok {
-			return []byte{}, errors.New("couldn't cast request Error!")
+			return []byte{}, nil, errors.New("couldn't cast request Error!")
 		}
 		if castedErr.Timeout() {
-			return []byte{}, errors.New("request cancelled or timed out")
+			return []byte{}, nil, &unreachableError{errors.New("request cancelled or timed out")}
 		}
 
-		return []byte{}, errors.Wrap(err, "making the request")
+		return []byte{}, nil, &unreachableError{errors.Wrap(err, "making the request")}
 	}
 	defer response.Body.Close()
 	reqLog.V(1).Info("request finished")
@@ -142,13 +240,23 @@ func (c *Client) do(endpoint, method, requestBody string) ([]byte, error) {
 	respLog := responseLogger(c.log, response, string(bodyBytes))
 	if err != nil {
 		respLog.V(1).Error(err, "failed to read response body")
-		return []byte{}, wrapResponseError(err, response.StatusCode)
+		return []byte{}, response.Header, wrapResponseError(err, response.StatusCode)
 	}
 
 	respLog.V(1).Info("response received")
 
-	if response.StatusCode == http.StatusCreated {
-		return bodyBytes, nil
+	if response.Header.Get("Deprecation") != "" {
+		respLog.Info("deprecated endpoint",
+			"successor", response.Header.Get("Link"),
+			"sunset", response.Header.Get("Sunset"))
+	}
+
+	if response.StatusCode == http.StatusCreated || response.StatusCode == http.StatusAccepted {
+		return bodyBytes, response.Header, nil
+	}
+
+	if response.StatusCode == http.StatusNotModified && isCached {
+		return cached.body, cached.header, nil
 	}
 
 	// TODO why is != 200 an error? there are valid codes in the 2xx, 3xx range
@@ -160,10 +268,14 @@ func (c *Client) do(endpoint, method, requestBody string) ([]byte, error) {
 		}
 		respLog.V(1).Info("response is not StatusOK: " + err.Error())
 
-		return bodyBytes, wrapResponseError(err, response.StatusCode)
+		return bodyBytes, response.Header, wrapResponseError(err, response.StatusCode)
 	}
 
-	return bodyBytes, nil
+	if etag := response.Header.Get("ETag"); etag != "" && method == http.MethodGet {
+		c.etags.put(uri, cachedResponse{etag: etag, body: bodyBytes, header: response.Header})
+	}
+
+	return bodyBytes, response.Header, nil
 }
 
 type ErrorFunc = func(response *http.Response, bodyBytes []byte, err error) error
@@ -188,9 +300,9 @@ func (c *Client) doWithCustomErrorHandling(endpoint, method, requestBody string,
 		return []byte{}, err
 	}
 
-	request.SetBasicAuth(c.user, c.password)
+	c.authorize(request)
 
-	response, err := (&http.Client{}).Do(request)
+	response, err := c.send(request, reqLog)
 	if err != nil {
 		reqLog.V(1).Error(err, "request failed")
 		return []byte{}, err
@@ -24,3 +24,19 @@ func (c *Client) Info() (models.InfoResponse, error) {
 
 	return resp, nil
 }
+
+// OIDCConfig returns the OIDC issuer, and client, the server accepts ID tokens of
+func (c *Client) OIDCConfig() (models.OIDCConfigResponse, error) {
+	var resp models.OIDCConfigResponse
+
+	data, err := c.get(api.PublicRoutes.Path("OIDCConfig"))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	apiv2 "github.com/epinio/epinio/internal/api/v2"
+	"github.com/epinio/epinio/internal/listing"
+	v2models "github.com/epinio/epinio/pkg/api/core/v2/models"
+)
+
+// listPageSize is the number of items the list requests ask for at once.
+const listPageSize = 100
+
+// getAll gets the list of the endpoint of the named route page by page. The page function
+// decodes the items of a page, collects them, and returns their number. Servers supporting
+// the route in v2 are asked for v2 pages, the page function gets their items. Servers without
+// paging return all items at once, without total count.
+func (c *Client) getAll(route, endpoint string, page func(data []byte) (int, error)) error {
+	options := listing.Options{Limit: listPageSize}
+	v2 := c.serves(apiv2.Version, route)
+
+	for {
+		var data []byte
+		var total int
+		var err error
+		if v2 {
+			data, total, err = c.getPage(endpoint, options)
+		} else {
+			data, total, err = c.getV1Page(endpoint, options)
+		}
+		if err != nil {
+			return err
+		}
+
+		count, err := page(data)
+		if err != nil {
+			return err
+		}
+
+		options.Offset += count
+		if total < 0 || count == 0 || options.Offset >= total {
+			return nil
+		}
+	}
+}
+
+// getV1Page returns the body of the v1 response for the page of the list, and the total count
+// of items, -1 if the server did not tell.
+func (c *Client) getV1Page(endpoint string, options listing.Options) ([]byte, int, error) {
+	data, header, err := c.doBodyHeader(endpoint+"?"+options.Query().Encode(), "GET", strings.NewReader(""), "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := strconv.Atoi(header.Get(listing.TotalCountHeader))
+	if err != nil {
+		return data, -1, nil
+	}
+	return data, total, nil
+}
+
+// getPage returns the items of the v2 page of the list, and the total count of items.
+func (c *Client) getPage(endpoint string, options listing.Options) ([]byte, int, error) {
+	data, _, err := c.doRootBodyHeader(apiv2.Root, endpoint+"?"+options.Query().Encode(), "GET", strings.NewReader(""), "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page := struct {
+		v2models.Page
+		Items json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, 0, err
+	}
+	return page.Items, page.Total, nil
+}
@@ -16,7 +16,7 @@ func (c *Client) NamespaceCreate(req models.NamespaceCreateRequest) (models.Resp
 		return resp, err
 	}
 
-	data, err := c.post(api.Routes.Path("Namespaces"), string(b))
+	data, err := c.postIdempotent(api.Routes.Path("Namespaces"), string(b))
 	if err != nil {
 		return resp, err
 	}
@@ -48,6 +48,98 @@ func (c *Client) NamespaceDelete(namespace string) (models.Response, error) {
 	return resp, nil
 }
 
+// NamespaceUpdate replaces the settings of a namespace
+func (c *Client) NamespaceUpdate(namespace string, req models.NamespaceUpdateRequest) (models.Response, error) {
+	resp := models.Response{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.patch(api.Routes.Path("NamespaceUpdate", namespace), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// NamespacePodSecuritySet changes the pod security level enforced in a namespace
+func (c *Client) NamespacePodSecuritySet(namespace string, req models.NamespacePodSecurityRequest) (models.Response, error) {
+	resp := models.Response{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.put(api.Routes.Path("NamespacePodSecuritySet", namespace), b)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// NamespaceQuotaSet replaces the quota of a namespace
+func (c *Client) NamespaceQuotaSet(namespace string, req models.NamespaceQuotaRequest) (models.Response, error) {
+	resp := models.Response{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.put(api.Routes.Path("NamespaceQuotaSet", namespace), b)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// NamespaceUploadLimitSet changes the size limit of the uploads to a namespace
+func (c *Client) NamespaceUploadLimitSet(namespace string, req models.NamespaceUploadLimitRequest) (models.Response, error) {
+	resp := models.Response{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.put(api.Routes.Path("NamespaceUploadLimitSet", namespace), b)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
 // NamespaceShow shows a namespace
 func (c *Client) NamespaceShow(namespace string) (models.Namespace, error) {
 	resp := models.Namespace{}
@@ -66,6 +158,24 @@ func (c *Client) NamespaceShow(namespace string) (models.Namespace, error) {
 	return resp, nil
 }
 
+// NamespaceExport returns the manifests of the namespace and its resources, as YAML stream.
+// The data of the secrets is only included if asked for.
+func (c *Client) NamespaceExport(namespace string, secrets bool) ([]byte, error) {
+	endpoint := api.Routes.Path("NamespaceExport", namespace)
+	if secrets {
+		endpoint += "?secrets=true"
+	}
+
+	data, err := c.get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log.V(1).Info("response received", "bytes", len(data))
+
+	return data, nil
+}
+
 // NamespacesMatch returns all matching namespaces for the prefix
 func (c *Client) NamespacesMatch(prefix string) (models.NamespacesMatchResponse, error) {
 	resp := models.NamespacesMatchResponse{}
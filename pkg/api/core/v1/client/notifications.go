@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Notifications returns the notification targets of the namespace
+func (c *Client) Notifications(namespace string) (models.NotificationTargetList, error) {
+	var resp models.NotificationTargetList
+
+	data, err := c.get(api.Routes.Path("Notifications", namespace))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// NotificationShow returns the notification target of the namespace
+func (c *Client) NotificationShow(namespace, name string) (models.NotificationTarget, error) {
+	resp := models.NotificationTarget{}
+
+	data, err := c.get(api.Routes.Path("NotificationShow", namespace, name))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// NotificationSet creates the notification target of the namespace, or replaces its settings
+func (c *Client) NotificationSet(namespace, name string, req models.NotificationTargetRequest) (models.Response, error) {
+	resp := models.Response{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.put(api.Routes.Path("NotificationSet", namespace, name), b)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// NotificationDelete deletes the notification target of the namespace
+func (c *Client) NotificationDelete(namespace, name string) (models.Response, error) {
+	resp := models.Response{}
+
+	data, err := c.delete(api.Routes.Path("NotificationDelete", namespace, name))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// OperationShow returns the long-running operation, e.g. a staging, with its phase, progress,
+// and error
+func (c *Client) OperationShow(id string) (models.Operation, error) {
+	var resp models.Operation
+
+	data, err := c.get(api.Routes.Path("OperationShow", id))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/epinio/epinio/internal/idempotency"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy controls the retrying of requests failing for transient reasons, like the
+// ingress or load balancer in front of the server not being able to reach it for a moment.
+// Attempts is the total number of attempts, one or less disables retries. The wait before
+// the second attempt is Backoff, doubled for each further attempt, up to MaxBackoff.
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of new clients.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	Backoff:    1 * time.Second,
+	MaxBackoff: 10 * time.Second,
+}
+
+// SetRetryPolicy changes the retry policy of the client.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// send makes the request, retrying transient failures as per the retry policy of the client.
+// Requests with a body are only retried if the body can be sent again. Requests which are not
+// idempotent are only retried if they did not reach the server at all, as the server may have
+// acted on them otherwise. Requests with an idempotency key count as idempotent.
+func (c *Client) send(request *http.Request, log logr.Logger) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := (&http.Client{}).Do(request)
+
+		if attempt >= c.retry.Attempts || !transient(request, response, err) {
+			return response, err
+		}
+		if request.Body != nil && request.Body != http.NoBody {
+			if request.GetBody == nil {
+				return response, err
+			}
+			body, berr := request.GetBody()
+			if berr != nil {
+				return response, err
+			}
+			request.Body = body
+		}
+
+		wait := c.retry.backoff(attempt, response)
+		if err != nil {
+			log.V(1).Info("retrying request", "attempt", attempt, "wait", wait, "error", err.Error())
+		} else {
+			log.V(1).Info("retrying request", "attempt", attempt, "wait", wait, "status", response.StatusCode)
+			_ = response.Body.Close()
+		}
+
+		time.Sleep(wait)
+	}
+}
+
+// transient returns true if the request failed for a reason which may go away on retry. This
+// is no connection to the server, or a gateway reporting that the server is not available.
+// For requests which are not idempotent only failures to connect count. For requests with an
+// idempotency key a conflict with their first attempt counts too.
+func transient(request *http.Request, response *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		return idempotent(request)
+	}
+
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(request)
+	case http.StatusConflict:
+		// The first attempt with the idempotency key is still running.
+		return request.Header.Get(idempotency.KeyHeader) != "" && response.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// idempotent returns true for the requests which can be repeated without changing the
+// result, by their method, or their idempotency key.
+func idempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return request.Header.Get(idempotency.KeyHeader) != ""
+}
+
+// backoff returns the time to wait after the given failed attempt. A server asking for a
+// time with `Retry-After` is given it, within the maximum backoff.
+func (p RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,77 @@
+package client_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client retry unit tests", func() {
+
+	var epinioClient *client.Client
+	var requests int
+	var failures int
+
+	BeforeEach(func() {
+		requests = 0
+		failures = 1
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, `{ "status": "OK" }`)
+		}))
+		DeferCleanup(srv.Close)
+
+		epinioClient = client.New(srv.URL, "", "", "")
+		epinioClient.SetRetryPolicy(client.RetryPolicy{
+			Attempts:   3,
+			Backoff:    time.Millisecond,
+			MaxBackoff: 10 * time.Millisecond,
+		})
+	})
+
+	It("retries idempotent requests", func() {
+		_, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests).To(Equal(2))
+	})
+
+	It("gives up after the last attempt", func() {
+		failures = 5
+
+		_, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).To(HaveOccurred())
+		Expect(requests).To(Equal(3))
+	})
+
+	It("does not retry requests which are not idempotent", func() {
+		err := epinioClient.AppRestart("namespace-foo", "appname")
+		Expect(err).To(HaveOccurred())
+		Expect(requests).To(Equal(1))
+	})
+
+	It("retries requests with an idempotency key", func() {
+		_, err := epinioClient.AppCreate(models.ApplicationCreateRequest{Name: "appname"}, "namespace-foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests).To(Equal(2))
+	})
+
+	It("does not retry when disabled", func() {
+		epinioClient.SetRetryPolicy(client.RetryPolicy{Attempts: 1})
+
+		_, err := epinioClient.AppShow("namespace-foo", "appname")
+		Expect(err).To(HaveOccurred())
+		Expect(requests).To(Equal(1))
+	})
+})
@@ -41,14 +41,26 @@ func (c *Client) ServiceCatalogShow(serviceName string) (*models.ServiceCatalogS
 	return &resp, nil
 }
 
-func (c *Client) ServiceCreate(req *models.ServiceCreateRequest, namespace string) error {
+func (c *Client) ServiceCreate(req *models.ServiceCreateRequest, namespace string) (models.ServiceCreateResponse, error) {
+	resp := models.ServiceCreateResponse{}
+
 	b, err := json.Marshal(req)
 	if err != nil {
-		return err
+		return resp, err
 	}
 
-	_, err = c.post(api.Routes.Path("ServiceCreate", namespace), string(b))
-	return err
+	data, err := c.postIdempotent(api.Routes.Path("ServiceCreate", namespace), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
 }
 
 func (c *Client) ServiceShow(req *models.ServiceShowRequest, namespace string) (*models.ServiceShowResponse, error) {
@@ -97,6 +109,29 @@ func (c *Client) ServiceDelete(req models.ServiceDeleteRequest, namespace string
 	return resp, nil
 }
 
+// ServiceBatchDelete deletes several services of a namespace in one call
+func (c *Client) ServiceBatchDelete(namespace string, req models.BatchDeleteRequest) (models.BatchDeleteResponse, error) {
+	resp := models.BatchDeleteResponse{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.do(api.Routes.Path("ServiceBatchDelete", namespace), "DELETE", string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
 func (c *Client) ServiceBind(req *models.ServiceBindRequest, namespace, name string) error {
 	b, err := json.Marshal(req)
 	if err != nil {
@@ -117,14 +152,24 @@ func (c *Client) ServiceUnbind(req *models.ServiceUnbindRequest, namespace, name
 	return err
 }
 
+// ServiceList returns the services of the namespace, requested page by page
 func (c *Client) ServiceList(namespace string) (*models.ServiceListResponse, error) {
-	data, err := c.get(api.Routes.Path("ServiceList", namespace))
-	if err != nil {
-		return nil, err
-	}
-
 	var resp models.ServiceListResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
+
+	err := c.getAll("ServiceList", api.Routes.Path("ServiceList", namespace), func(data []byte) (int, error) {
+		var page []*models.Service
+		if err := json.Unmarshal(data, &page); err != nil {
+			// v1 wraps the services of the page
+			var v1Page models.ServiceListResponse
+			if err := json.Unmarshal(data, &v1Page); err != nil {
+				return 0, err
+			}
+			page = v1Page.Services
+		}
+		resp.Services = append(resp.Services, page...)
+		return len(page), nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
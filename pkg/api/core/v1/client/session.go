@@ -0,0 +1,69 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// SessionCreate starts a session of the user, and returns its tokens. The user is
+// authenticated with the password, whatever the credentials of the client.
+func (c *Client) SessionCreate(user, password string) (models.SessionResponse, error) {
+	var resp models.SessionResponse
+
+	basic := *c
+	basic.user = user
+	basic.password = password
+	basic.token = ""
+
+	data, err := basic.post(api.Routes.Path("SessionCreate"), "")
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// SessionRefresh returns new tokens for the session of the refresh token
+func (c *Client) SessionRefresh(refreshToken string) (models.SessionResponse, error) {
+	var resp models.SessionResponse
+
+	b, err := json.Marshal(models.SessionRefreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.PublicRoutes.Path("SessionRefresh"), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// SessionDelete ends the session of the token of the client
+func (c *Client) SessionDelete() (models.Response, error) {
+	resp := models.Response{}
+
+	data, err := c.delete(api.Routes.Path("SessionDelete"))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
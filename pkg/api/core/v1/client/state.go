@@ -0,0 +1,50 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// StateExport returns the state of the platform, for a migration
+func (c *Client) StateExport() (models.PlatformState, error) {
+	resp := models.PlatformState{}
+
+	data, err := c.get(api.Routes.Path("StateExport"))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	// The users of the state carry their passwords, they are not logged.
+	c.log.V(1).Info("response decoded", "namespaces", len(resp.Namespaces), "users", len(resp.Users))
+
+	return resp, nil
+}
+
+// StateUserImport creates the users of a migration
+func (c *Client) StateUserImport(req models.UserImportRequest) (models.UserImportResponse, error) {
+	resp := models.UserImportResponse{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.post(api.Routes.Path("StateUserImport"), string(b))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
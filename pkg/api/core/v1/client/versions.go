@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	apiv2 "github.com/epinio/epinio/internal/api/v2"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	"github.com/pkg/errors"
+)
+
+// versionsMemo holds the versions of the API the server supports, once asked for.
+type versionsMemo struct {
+	once     sync.Once
+	versions models.APIVersionsResponse
+	err      error
+}
+
+// APIVersions returns the versions of the API the server supports. Servers without version
+// discovery support only v1. The versions are asked for once per client.
+func (c *Client) APIVersions() (models.APIVersionsResponse, error) {
+	c.versions.once.Do(func() {
+		c.versions.versions, c.versions.err = c.apiVersions()
+	})
+	return c.versions.versions, c.versions.err
+}
+
+func (c *Client) apiVersions() (models.APIVersionsResponse, error) {
+	resp := models.APIVersionsResponse{}
+
+	data, _, err := c.doRootBodyHeader("", strings.TrimPrefix(apiv2.VersionsPath, "/"), "GET", strings.NewReader(""), "")
+	if err != nil {
+		var rerr *responseError
+		if errors.As(err, &rerr) && rerr.StatusCode() == http.StatusNotFound {
+			resp.Versions = []models.APIVersion{{Version: "v1", Root: api.Root}}
+			return resp, nil
+		}
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, errors.Wrap(err, "response body is not JSON")
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// serves returns true if the server supports the named endpoint in the version. Failing to
+// discover the versions is treated as supporting v1 only.
+func (c *Client) serves(version, route string) bool {
+	versions, err := c.APIVersions()
+	if err != nil {
+		c.log.V(1).Info("failed to discover the API versions", "error", err.Error())
+		return false
+	}
+
+	for _, v := range versions.Versions {
+		if v.Version != version {
+			continue
+		}
+		for _, name := range v.Routes {
+			if name == route {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	apiv2 "github.com/epinio/epinio/internal/api/v2"
+	"github.com/epinio/epinio/pkg/api/core/v1/client"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+	v2models "github.com/epinio/epinio/pkg/api/core/v2/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client API versions unit tests", func() {
+
+	var epinioClient *client.Client
+	var paths []string
+
+	BeforeEach(func() {
+		paths = []string{}
+
+		services := []*models.Service{}
+		for i := 0; i < 150; i++ {
+			services = append(services, &models.Service{Meta: models.Meta{Name: fmt.Sprintf("service-%03d", i), Namespace: "workspace"}})
+		}
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+
+			var out []byte
+			switch r.URL.Path {
+			case apiv2.VersionsPath:
+				out, _ = json.Marshal(apiv2.VersionsResponse(time.Time{}))
+			case apiv2.Root + "/namespaces/workspace/services":
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				end := offset + limit
+				if end > len(services) {
+					end = len(services)
+				}
+				out, _ = json.Marshal(v2models.Page{Items: services[offset:end], Total: len(services), Offset: offset, Limit: limit})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, string(out))
+		}))
+		DeferCleanup(srv.Close)
+
+		epinioClient = client.New(srv.URL, "", "", "")
+	})
+
+	It("discovers the versions of the API", func() {
+		versions, err := epinioClient.APIVersions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versions.Versions).To(HaveLen(2))
+		Expect(versions.Versions[1].Version).To(Equal(apiv2.Version))
+
+		_, err = epinioClient.APIVersions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(Equal([]string{apiv2.VersionsPath}))
+	})
+
+	It("lists with v2 pages when the server supports them", func() {
+		resp, err := epinioClient.ServiceList("workspace")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Services).To(HaveLen(150))
+		Expect(resp.Services[149].Meta.Name).To(Equal("service-149"))
+
+		Expect(paths).To(Equal([]string{
+			apiv2.VersionsPath,
+			apiv2.Root + "/namespaces/workspace/services",
+			apiv2.Root + "/namespaces/workspace/services",
+		}))
+	})
+})
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+
+	api "github.com/epinio/epinio/internal/api/v1"
+	"github.com/epinio/epinio/pkg/api/core/v1/models"
+)
+
+// Webhooks returns the webhooks
+func (c *Client) Webhooks() (models.WebhookList, error) {
+	var resp models.WebhookList
+
+	data, err := c.get(api.Routes.Path("Webhooks"))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// WebhookShow returns the webhook, without its secret
+func (c *Client) WebhookShow(name string) (models.Webhook, error) {
+	resp := models.Webhook{}
+
+	data, err := c.get(api.Routes.Path("WebhookShow", name))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
+
+// WebhookSet creates the webhook, or replaces its url, event types, and secret
+func (c *Client) WebhookSet(name string, req models.WebhookRequest) (models.WebhookSetResponse, error) {
+	resp := models.WebhookSetResponse{}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err := c.put(api.Routes.Path("WebhookSet", name), b)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded")
+
+	return resp, nil
+}
+
+// WebhookDelete deletes the webhook
+func (c *Client) WebhookDelete(name string) (models.Response, error) {
+	resp := models.Response{}
+
+	data, err := c.delete(api.Routes.Path("WebhookDelete", name))
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	c.log.V(1).Info("response decoded", "response", resp)
+
+	return resp, nil
+}
@@ -51,6 +51,15 @@ func NewAPIError(title string, details string, status int) APIError {
 	}
 }
 
+// Message returns the first of the errors as a single line, with its details, if any.
+func Message(errs APIErrors) string {
+	first := errs.Errors()[0]
+	if first.Details == "" {
+		return first.Title
+	}
+	return first.Title + ": " + first.Details
+}
+
 // MultiError fulfills the APIErrors interface. It contains multiple errors.
 type MultiError struct {
 	errors []APIError
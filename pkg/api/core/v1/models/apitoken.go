@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// APIToken describes an API token of the user, without its secret value. Tokens with the
+// read scope can only make requests which change nothing. Tokens with namespaces only give
+// access to these namespaces.
+type APIToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Scope      string    `json:"scope"`
+	Namespaces []string  `json:"namespaces,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// APITokenList is a list of API tokens
+type APITokenList []APIToken
+
+// APITokenCreateRequest contains the settings of the API token to create. The expiry is a
+// duration, like `720h`.
+type APITokenCreateRequest struct {
+	Name       string   `json:"name"`
+	Scope      string   `json:"scope"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Expiry     string   `json:"expiry"`
+}
+
+// APITokenCreateResponse contains the created API token, and its secret value. The value is
+// returned only once, and cannot be retrieved later.
+type APITokenCreateResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
@@ -1,6 +1,11 @@
 package models
 
 import (
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/epinio/epinio/internal/names"
 )
 
@@ -8,11 +13,46 @@ const (
 	EpinioStageIDPrevious   = "epinio.suse.org/previous-stage-id"
 	EpinioStageIDLabel      = "epinio.suse.org/stage-id"
 	EpinioStageBlobUIDLabel = "epinio.suse.org/blob-uid"
+	EpinioTaskLabel         = "epinio.suse.org/task"
+
+	// ProcessWeb is the process type run by the main deployment of an application. All
+	// other process types get a deployment of their own.
+	ProcessWeb = "web"
+
+	// VolumeSizeDefault is the size of application volumes without explicit size.
+	VolumeSizeDefault = "1Gi"
+
+	// Kinds of exposure of the raw ports of an application.
+	PortExposeNodePort     = "NodePort"
+	PortExposeLoadBalancer = "LoadBalancer"
+
+	// DomainChallengePrefix is prepended to a custom domain to name the TXT record verifying
+	// its ownership.
+	DomainChallengePrefix = "_epinio-challenge."
+
+	DomainCertificateNone    = "none"    // no issuer configured, plain http
+	DomainCertificatePending = "pending" // requested from the issuer, not yet issued
+	DomainCertificateReady   = "ready"
+
+	// Sources of the resource usage reported for application instances.
+	MetricsSourceServer     = "metrics-server"
+	MetricsSourcePrometheus = "prometheus"
+
+	ApplicationCreated  = "created"
+	ApplicationStaging  = "staging"
+	ApplicationRunning  = "running"
+	ApplicationSleeping = "sleeping"
+	ApplicationError    = "error"
 
-	ApplicationCreated = "created"
-	ApplicationStaging = "staging"
-	ApplicationRunning = "running"
-	ApplicationError   = "error"
+	RevisionDeployed = "deployed"
+	RevisionFailed   = "failed"
+
+	HookSucceeded = "succeeded"
+	HookFailed    = "failed"
+
+	StrategyCanary = "canary"
+
+	CanaryWeightDefault = 10
 )
 
 type ApplicationStatus string
@@ -36,15 +76,20 @@ type App struct {
 	StatusMessage string                   `json:"statusmessage"`
 	StageID       string                   `json:"stage_id,omitempty"` // staging id, last run
 	ImageURL      string                   `json:"image_url"`
+	Staging       ApplicationStage         `json:"staging,omitempty"` // staging settings, last run
 }
 
+// PodInfo describes an instance of an application, with its resource usage and limits. A
+// zero limit means that the instance is not limited.
 type PodInfo struct {
-	Name        string `json:"name"`
-	MemoryBytes int64  `json:"memoryBytes"`
-	MilliCPUs   int64  `json:"millicpus"`
-	CreatedAt   string `json:"createdAt,omitempty"`
-	Restarts    int32  `json:"restarts"`
-	Ready       bool   `json:"ready"`
+	Name             string `json:"name"`
+	MemoryBytes      int64  `json:"memoryBytes"`
+	MilliCPUs        int64  `json:"millicpus"`
+	MemoryLimitBytes int64  `json:"memoryLimitBytes,omitempty"`
+	MilliCPUsLimit   int64  `json:"millicpusLimit,omitempty"`
+	CreatedAt        string `json:"createdAt,omitempty"`
+	Restarts         int32  `json:"restarts"`
+	Ready            bool   `json:"ready"`
 }
 
 // AppDeployment contains all the information specific to an active
@@ -61,6 +106,11 @@ type AppDeployment struct {
 	StageID         string              `json:"stage_id,omitempty"` // staging id, running app
 	Status          string              `json:"status,omitempty"`   // app replica status
 	Routes          []string            `json:"routes,omitempty"`   // app routes
+	MemoryBytes     int64               `json:"memoryBytes"`        // summed over the replicas
+	MilliCPUs       int64               `json:"millicpus"`          // summed over the replicas
+	Restarts        int32               `json:"restarts"`           // summed over the replicas
+	MetricsSource   string              `json:"metrics,omitempty"`  // empty if no metrics are available
+	Ports           []AppExposedPort    `json:"ports,omitempty"`    // raw ports exposed outside of the cluster
 }
 
 // NewApp returns a new app for name and namespace
@@ -80,6 +130,112 @@ func (a *App) AppRef() AppRef {
 	return a.Meta
 }
 
+// AppRevision describes a single past deployment of an application. It records the image
+// which was deployed, together with the environment and configuration bindings active at
+// that time. This is the information needed to roll the application back to it. The
+// remainder (user, hash, result, hooks) is for auditing.
+type AppRevision struct {
+	Number         int               `json:"number"`
+	ImageURL       string            `json:"image_url"`
+	StageID        string            `json:"stage_id,omitempty"`
+	Origin         ApplicationOrigin `json:"origin"`
+	Environment    EnvVariableMap    `json:"environment,omitempty"`
+	EnvHash        string            `json:"env_hash,omitempty"`
+	Configurations []string          `json:"configurations,omitempty"`
+	Username       string            `json:"username,omitempty"`
+	Result         string            `json:"result,omitempty"` // RevisionDeployed, or RevisionFailed
+	Hooks          []AppHookResult   `json:"hooks,omitempty"`
+	CreatedAt      metav1.Time       `json:"createdAt,omitempty"`
+}
+
+// AppHookResult records the run of a deployment hook for a revision, with the tail of its
+// logs.
+type AppHookResult struct {
+	Name   string `json:"name"`
+	Phase  string `json:"phase"`
+	Result string `json:"result"` // HookSucceeded, or HookFailed
+	Logs   string `json:"logs,omitempty"`
+}
+
+// Failed returns true if the hook did not succeed
+func (r *AppHookResult) Failed() bool {
+	return r.Result == HookFailed
+}
+
+// Failed returns true if the revision did not deploy successfully
+func (r *AppRevision) Failed() bool {
+	return r.Result == RevisionFailed
+}
+
+// AppRevisionList is a collection of app revisions, ordered from oldest to newest
+type AppRevisionList []AppRevision
+
+// AppCanary describes the canary deployment of an application, i.e. a new version of the
+// application running next to the stable version, and receiving the given percentage of
+// the route traffic. A canary whose error rate, in percent of its requests, reaches the error
+// threshold is rolled back automatically. Zero disables the rollback.
+type AppCanary struct {
+	ImageURL       string            `json:"image"`
+	StageID        string            `json:"stageid,omitempty"`
+	Origin         ApplicationOrigin `json:"origin"`
+	Weight         int               `json:"weight"`
+	ErrorThreshold int               `json:"error_threshold,omitempty"`
+}
+
+// AppTaskStatus describes a scheduled task of an application, and its activity: the last
+// time it was run, and the number of currently running jobs.
+type AppTaskStatus struct {
+	AppTask
+	LastSchedule *metav1.Time `json:"lastschedule,omitempty"`
+	Active       int          `json:"active"`
+}
+
+// AppTaskList is a collection of app task states, ordered by name
+type AppTaskList []AppTaskStatus
+
+// AppDomain is a custom domain of an application. The domain is routed to the application
+// after its ownership was verified, through a DNS TXT record holding the token.
+type AppDomain struct {
+	Domain   string `json:"domain"`
+	Token    string `json:"token"`
+	Verified bool   `json:"verified"`
+}
+
+// AppDomainStatus describes a custom domain of an application, the TXT record to create for
+// verifying its ownership, and the state of its certificate: one of the DomainCertificate
+// constants.
+type AppDomainStatus struct {
+	AppDomain
+	Record      string `json:"record"`
+	Certificate string `json:"certificate"`
+}
+
+// AppDomainList is a collection of app domain states, ordered by domain
+type AppDomainList []AppDomainStatus
+
+// AppEvent describes a kube event of a resource of an application, i.e. instances,
+// deployments, and jobs for tasks, migrations, and stagings, or a condition of its
+// deployment. The id identifies the event across updates, which increase the count.
+type AppEvent struct {
+	ID      string      `json:"id"`
+	Time    metav1.Time `json:"time"`
+	Type    string      `json:"type"`
+	Object  string      `json:"object"`
+	Reason  string      `json:"reason"`
+	Message string      `json:"message"`
+	Count   int32       `json:"count"`
+}
+
+// LogParameters restrict the logs of an application to the lines of a single instance, the
+// lines younger than a duration, the last lines of each container, and the lines matching a
+// regular expression. The zero value restricts nothing.
+type LogParameters struct {
+	Instance string
+	Since    time.Duration
+	Tail     *int64
+	Filter   string
+}
+
 // AppList is a collection of app references
 type AppList []App
 
@@ -146,6 +302,114 @@ func (ar *AppRef) MakeScaleSecretName() string {
 	return names.GenerateResourceName(ar.Name + "-scale")
 }
 
+// MakeRevisionsSecretName returns the name of the kube secret holding the deployment
+// revisions of the referenced application
+func (ar *AppRef) MakeRevisionsSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-revs")
+}
+
+// MakeCanarySecretName returns the name of the kube secret holding the canary
+// deployment of the referenced application
+func (ar *AppRef) MakeCanarySecretName() string {
+	return names.GenerateResourceName(ar.Name + "-canary")
+}
+
+// MakeStagingSecretName returns the name of the kube secret holding the staging
+// settings of the referenced application
+func (ar *AppRef) MakeStagingSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-staging")
+}
+
+// MakeTasksSecretName returns the name of the kube secret holding the scheduled
+// tasks of the referenced application
+func (ar *AppRef) MakeTasksSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-tasks")
+}
+
+// MakeSidecarsSecretName returns the name of the kube secret holding the sidecar
+// containers of the referenced application
+func (ar *AppRef) MakeSidecarsSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-sidecars")
+}
+
+// MakeMigrationSecretName returns the name of the kube secret holding the pre-deploy
+// migration of the referenced application
+func (ar *AppRef) MakeMigrationSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-migration")
+}
+
+// MakeHooksSecretName returns the name of the kube secret holding the deployment
+// hooks of the referenced application
+func (ar *AppRef) MakeHooksSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-hooks")
+}
+
+// MakePullSecretName returns the name of the kube secret holding the image pull
+// credentials of the referenced application
+func (ar *AppRef) MakePullSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-pull")
+}
+
+// MakeChartValuesSecretName returns the name of the kube secret holding the values
+// passed into the app chart of the referenced application
+func (ar *AppRef) MakeChartValuesSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-chartvalues")
+}
+
+// MakeDomainsSecretName returns the name of the kube secret holding the custom domains of
+// the referenced application
+func (ar *AppRef) MakeDomainsSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-domains")
+}
+
+// MakeRouteAnnotationsSecretName returns the name of the kube secret holding the ingress
+// annotations of the routes of the referenced application
+func (ar *AppRef) MakeRouteAnnotationsSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-routeannotations")
+}
+
+// MakePortsSecretName returns the name of the kube secret holding the raw ports
+// exposed by the referenced application
+func (ar *AppRef) MakePortsSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-ports")
+}
+
+// MakePortsServiceName returns the name of the kube service exposing the raw ports of the
+// referenced application, for the kind of exposure, i.e. NodePort, or LoadBalancer
+func (ar *AppRef) MakePortsServiceName(expose string) string {
+	return names.GenerateResourceName(ar.Name + "-" + strings.ToLower(expose))
+}
+
+// MakePlacementSecretName returns the name of the kube secret holding the node placement
+// constraints of the referenced application
+func (ar *AppRef) MakePlacementSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-placement")
+}
+
+// MakeEntrypointSecretName returns the name of the kube secret holding the start command
+// override of the referenced application
+func (ar *AppRef) MakeEntrypointSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-entrypoint")
+}
+
+// MakeTerminationSecretName returns the name of the kube secret holding the shutdown
+// settings of the referenced application
+func (ar *AppRef) MakeTerminationSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-termination")
+}
+
+// MakeVolumesSecretName returns the name of the kube secret holding the persistent
+// volumes of the referenced application
+func (ar *AppRef) MakeVolumesSecretName() string {
+	return names.GenerateResourceName(ar.Name + "-volumes")
+}
+
+// MakeVolumeClaimName returns the name of the kube pvc backing the named volume of the
+// referenced application.
+func (ar *AppRef) MakeVolumeClaimName(volume string) string {
+	return names.GenerateResourceName(ar.Name, "volume", volume)
+}
+
 // MakePVCName returns the name of the kube pvc to use with/for the referenced application.
 func (ar *AppRef) MakePVCName() string {
 	return names.GenerateResourceName(ar.Namespace, ar.Name)
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Results of audited requests
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditEntry records a request of the API changing something: who made it, the HTTP verb, the
+// resource changed, the namespace of the resource, if any, and how it ended.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	User      string    `json:"user"`
+	Verb      string    `json:"verb"`
+	Resource  string    `json:"resource"`
+	Namespace string    `json:"namespace,omitempty"`
+	Status    int       `json:"status"`
+	Result    string    `json:"result"`
+}
+
+// AuditEntryList is a list of audit entries, the newest first
+type AuditEntryList []AuditEntry
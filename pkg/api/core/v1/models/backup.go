@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Backup describes a backup of the platform state, kept in the blob store. Counts holds the
+// number of resources of each part of the backup, e.g. `apps`, or `configurations`.
+type Backup struct {
+	Name       string         `json:"name"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Version    string         `json:"version"`
+	Namespaces []string       `json:"namespaces"`
+	Counts     map[string]int `json:"counts"`
+	Registry   BackupRegistry `json:"registry"`
+}
+
+// BackupRegistry is the meta data of the registry of the backed up installation, and the
+// images of its apps. The images themselves are not part of the backup.
+type BackupRegistry struct {
+	URL       string   `json:"url,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Images    []string `json:"images,omitempty"`
+}
+
+// BackupList is a collection of backups
+type BackupList []Backup
+
+// BackupCreateRequest names the backup to create. Without name the backup is named after the
+// time of its creation.
+type BackupCreateRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+// BackupRestoreResponse reports the outcome of a restore. Created holds the number of
+// resources created, by part, and Skipped the resources which existed already, as
+// `part/namespace/name`. The restored apps with an image are deployed again.
+type BackupRestoreResponse struct {
+	Created  map[string]int `json:"created"`
+	Skipped  []string       `json:"skipped,omitempty"`
+	Deployed []string       `json:"deployed,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ClientCertificateRequest contains the PEM encoded certificate request of a machine to be
+// enrolled for mutual TLS. Its key stays on the machine.
+type ClientCertificateRequest struct {
+	CSR string `json:"csr"`
+}
+
+// ClientCertificateResponse contains the PEM encoded client certificate issued to the user,
+// and the URL of the API port requiring it.
+type ClientCertificateResponse struct {
+	Certificate string    `json:"certificate"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	URL         string    `json:"url"`
+	WsURL       string    `json:"ws_url"`
+}
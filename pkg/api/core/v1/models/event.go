@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Types of platform events
+const (
+	EventStagingStarted     = "app.staging.started"
+	EventStagingSucceeded   = "app.staging.succeeded"
+	EventStagingFailed      = "app.staging.failed"
+	EventAppCreated         = "app.created"
+	EventAppDeployed        = "app.deployed"
+	EventAppScaled          = "app.scaled"
+	EventAppUpdated         = "app.updated"
+	EventAppRestarted       = "app.restarted"
+	EventAppDeleted         = "app.deleted"
+	EventServiceCreated     = "service.created"
+	EventServiceProvisioned = "service.provisioned"
+	EventServiceFailed      = "service.failed"
+	EventServiceBound       = "service.bound"
+	EventServiceUnbound     = "service.unbound"
+	EventServiceDeleted     = "service.deleted"
+	EventNamespaceCreated   = "namespace.created"
+	EventNamespaceDeleted   = "namespace.deleted"
+)
+
+// PlatformEvent is a change of the platform, made through the API or by Epinio itself, e.g. a
+// staging job ending. The name is the one of the changed app, service, or namespace. The user
+// is the one making the change, if any. The details depend on the type, e.g. the stage id of
+// a staging, or the number of instances of a scaled app.
+type PlatformEvent struct {
+	ID        uint64            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Type      string            `json:"type"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	User      string            `json:"user,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IDSeparator separates the namespace and the name in the ids of namespaced resources.
+const IDSeparator = "/"
+
+// ID returns the stable id of the resource, `NAMESPACE/NAME`, e.g. for the state of tools
+// managing the resources declaratively. The name is the identity of a resource, the id so
+// does not change for its life. A renamed app is a new resource, with a new id. It is the
+// inverse of ParseID.
+func (m Meta) ID() string {
+	return m.Namespace + IDSeparator + m.Name
+}
+
+// ID returns the stable id of the non-namespaced resource, its name.
+func (m MetaLite) ID() string {
+	return m.Name
+}
+
+// ParseID returns the namespace and name of the id of a namespaced resource, see Meta.ID.
+func ParseID(id string) (string, string, error) {
+	pieces := strings.Split(id, IDSeparator)
+	if len(pieces) != 2 || pieces[0] == "" || pieces[1] == "" {
+		return "", "", fmt.Errorf("bad id '%s', expected NAMESPACE%sNAME", id, IDSeparator)
+	}
+	return pieces[0], pieces[1], nil
+}
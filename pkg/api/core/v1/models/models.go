@@ -85,8 +85,11 @@ type ConfigurationDeleteResponse struct {
 }
 
 // BindRequest represents and contains the data needed to bind configurations to an application.
+// Path, if set, is the directory the keys of the single named configuration are projected
+// into as files, instead of the default `/configurations/<name>`.
 type BindRequest struct {
 	Names []string `json:"names"`
+	Path  string   `json:"path,omitempty"`
 }
 
 // BindResponse represents the server's response to the successful binding of configurations to
@@ -106,12 +109,29 @@ type ApplicationManifest struct {
 }
 
 // ApplicationStage is the part of the manifest holding information
-// relevant to staging the application's sources. This is, currently,
-// only the reference to the Paketo builder image to use.
+// relevant to staging the application's sources. This is the reference
+// to the Paketo builder image to use, with an optional ordered list of
+// buildpacks overriding the builder's own, or, alternatively, the path of
+// the Dockerfile to build the sources with, relative to the sources.
+// The cache selects where the build keeps its cache between stagings,
+// i.e. one of the BuildCache* constants. The default is BuildCacheVolume.
+// The environment holds build-time variables, e.g. BP_JVM_VERSION, which
+// are given to the staging job only, on top of the runtime environment.
 type ApplicationStage struct {
-	Builder string `yaml:"builder,omitempty"`
+	Builder     string         `json:"builder,omitempty"     yaml:"builder,omitempty"`
+	Buildpacks  []string       `json:"buildpacks,omitempty"  yaml:"buildpacks,omitempty"`
+	Dockerfile  string         `json:"dockerfile,omitempty"  yaml:"dockerfile,omitempty"`
+	Cache       string         `json:"cache,omitempty"       yaml:"cache,omitempty"`
+	Environment EnvVariableMap `json:"environment,omitempty" yaml:"environment,omitempty"`
 }
 
+// build cache kinds for `ApplicationStage.Cache`.
+const (
+	BuildCacheVolume   = "volume"   // app-specific persistent volume
+	BuildCacheRegistry = "registry" // cache image in the epinio registry
+	BuildCacheNone     = "none"     // build from scratch, always
+)
+
 // ApplicationOrigin is the part of the manifest describing the origin of the application
 // (sources). At most one of the fields may be specified / not empty.
 type ApplicationOrigin struct {
@@ -157,17 +177,268 @@ type ApplicationCreateRequest struct {
 	Configuration ApplicationUpdateRequest `json:"configuration" yaml:"configuration,omitempty"`
 }
 
+// ApplicationApplyRequest is the complete manifest of an application, for the apply endpoint.
+// The application is created, or changed, to match it, see ApplicationUpdateRequest for the
+// settings. The settings missing from the manifest are reset to their defaults. The origin
+// is a container image, to deploy if it changed. Sources are pushed, not applied.
+type ApplicationApplyRequest struct {
+	Name          string                   `json:"name"`
+	Configuration ApplicationUpdateRequest `json:"configuration"`
+	Origin        ApplicationOrigin        `json:"origin"`
+}
+
+// ApplicationApplyResponse tells what applying a manifest changed: whether the application was
+// created, the names of the changed settings, and whether it was deployed, with its routes.
+type ApplicationApplyResponse struct {
+	Created  bool     `json:"created"`
+	Changes  []string `json:"changes"`
+	Deployed bool     `json:"deployed"`
+	Routes   []string `json:"routes,omitempty"`
+}
+
+// AppAdoptRequest names an existing deployment of the namespace to turn into an application,
+// with its services and ingresses. The name of the application defaults to the name of the
+// deployment, and the adopted container to the first. A dry run only reports the adoption.
+type AppAdoptRequest struct {
+	Deployment string `json:"deployment"`
+	Name       string `json:"name,omitempty"`
+	Container  string `json:"container,omitempty"`
+	DryRun     bool   `json:"dryrun,omitempty"`
+}
+
+// AppAdoptResponse tells how a deployment was adopted: the image and settings of the new
+// application, the resources it replaced, as `KIND/NAME`, and the parts of the deployment
+// which were not carried over. The routes are those of the deployed application.
+type AppAdoptResponse struct {
+	Name          string                   `json:"name"`
+	ImageURL      string                   `json:"image"`
+	Configuration ApplicationUpdateRequest `json:"configuration"`
+	Adopted       []string                 `json:"adopted"`
+	Warnings      []string                 `json:"warnings"`
+	Routes        []string                 `json:"routes,omitempty"`
+}
+
 // ApplicationUpdateRequest represents and contains the data needed to update
 // an application. Specifically to modify the number of replicas to
 // run, and the configurations bound to it.
 // Note: Instances is a pointer to give us a nil value separate from
 // actual integers, as means of communicating `default`/`no change`.
+// Similarly, nil Tasks and Sidecars mean `no change`, whereas an empty slice removes them all.
+// A nil Migration means `no change`, whereas one without command removes it.
+// Hooks, like Tasks, replace the deployment hooks. nil means `no change`, whereas an empty
+// slice removes them all.
+// Volumes are merged into the existing ones by name, nil or empty means `no change`.
+// ConfigurationPaths maps bound configurations to the directories their keys are projected
+// into as files. They are merged into the existing paths, an empty path restores the
+// default `/configurations/<name>`.
+// Processes maps the additional process types of the application, i.e. everything but
+// `web`, to their desired instances. Process types not mentioned are left unchanged.
+// RouteAnnotations replace the ingress annotations of the application's routes. An empty
+// map removes them.
+// AutoSleep is the idle time in minutes, without requests through the routes, after which
+// the application is put to sleep, i.e. scaled to zero. Zero disables it, nil means
+// `no change`.
+// Ports replace the raw TCP and UDP ports of the application exposed outside of the cluster.
+// nil means `no change`, whereas an empty slice removes them all.
+// Placement constrains the nodes the application's instances run on. nil means `no change`,
+// whereas an empty placement removes all constraints.
+// A nil Termination means `no change`, whereas an empty one restores the defaults.
+// A nil Entrypoint means `no change`, see AppEntrypoint for its parts.
+// ChartValues are passed into the app chart, subject to the settings the chart declares. They
+// are merged into the existing values, an empty value removes its setting.
+// A nil PullSecret means `no change`, whereas one without registry removes it.
 type ApplicationUpdateRequest struct {
-	Instances      *int32         `json:"instances"          yaml:"instances,omitempty"`
-	Configurations []string       `json:"configurations"     yaml:"configurations,omitempty"`
-	Environment    EnvVariableMap `json:"environment"        yaml:"environment,omitempty"`
-	Routes         []string       `json:"routes"             yaml:"routes,omitempty"`
-	AppChart       string         `json:"appchart,omitempty" yaml:"appchart,omitempty"`
+	Instances          *int32              `json:"instances"                    yaml:"instances,omitempty"`
+	Configurations     []string            `json:"configurations"               yaml:"configurations,omitempty"`
+	Environment        EnvVariableMap      `json:"environment"                  yaml:"environment,omitempty"`
+	Routes             []string            `json:"routes"                       yaml:"routes,omitempty"`
+	AppChart           string              `json:"appchart,omitempty"           yaml:"appchart,omitempty"`
+	Rollout            *AppRollout         `json:"rollout,omitempty"            yaml:"rollout,omitempty"`
+	Tasks              []AppTask           `json:"tasks"                        yaml:"tasks,omitempty"`
+	Processes          map[string]int32    `json:"processes,omitempty"          yaml:"processes,omitempty"`
+	Sidecars           []AppSidecar        `json:"sidecars"                     yaml:"sidecars,omitempty"`
+	Migration          *AppMigration       `json:"migration,omitempty"          yaml:"migration,omitempty"`
+	Hooks              []AppHook           `json:"hooks"                        yaml:"hooks,omitempty"`
+	Volumes            []AppVolume         `json:"volumes,omitempty"            yaml:"volumes,omitempty"`
+	ConfigurationPaths map[string]string   `json:"configurationpaths,omitempty" yaml:"configurationpaths,omitempty"`
+	RouteAnnotations   AppRouteAnnotations `json:"routeannotations"             yaml:"routeannotations,omitempty"`
+	AutoSleep          *int32              `json:"autosleep,omitempty"          yaml:"autosleep,omitempty"`
+	Ports              []AppPort           `json:"ports"                        yaml:"ports,omitempty"`
+	Placement          *AppPlacement       `json:"placement,omitempty"          yaml:"placement,omitempty"`
+	Termination        *AppTermination     `json:"termination,omitempty"        yaml:"termination,omitempty"`
+	Entrypoint         *AppEntrypoint      `json:"entrypoint,omitempty"         yaml:"entrypoint,omitempty"`
+	ChartValues        map[string]string   `json:"chartvalues,omitempty"        yaml:"chartvalues,omitempty"`
+	PullSecret         *AppPullSecret      `json:"pullsecret,omitempty"         yaml:"pullsecret,omitempty"`
+}
+
+// AppRouteAnnotations maps routes of an application, given as domain and path, to the
+// controller-specific annotations of their ingresses, e.g. timeouts, body size, or sticky
+// sessions. The annotations are restricted to the ones allowed by the operator.
+type AppRouteAnnotations map[string]map[string]string
+
+// AppPullSecret holds the credentials for pulling the application image from a private
+// registry, e.g. for an image deployed with `--container-image-url`. The registry is the
+// host (and port) of the registry, like `ghcr.io`. The password is never returned. A
+// request without password keeps the one saved for the same registry and username.
+type AppPullSecret struct {
+	Registry string `json:"registry"           yaml:"registry"`
+	Username string `json:"username"           yaml:"username"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// AppRollout holds the rolling update parameters used when (re)deploying an application.
+// Each value is either an absolute number of instances, or a percentage of the desired
+// instances, like "25%". An empty value leaves the choice to the app chart.
+// The instances kept running during a deploy are the desired instances minus MaxUnavailable.
+// I.e. a MaxUnavailable of "0" keeps the full capacity, at the cost of MaxSurge additional
+// instances.
+type AppRollout struct {
+	MaxUnavailable string `json:"maxunavailable,omitempty" yaml:"maxunavailable,omitempty"`
+	MaxSurge       string `json:"maxsurge,omitempty"       yaml:"maxsurge,omitempty"`
+}
+
+// AppTask is a scheduled task of an application, i.e. a command run on a cron schedule, in
+// the application image, and with the application's environment and bound configurations.
+type AppTask struct {
+	Name     string   `json:"name"     yaml:"name"`
+	Schedule string   `json:"schedule" yaml:"schedule"`
+	Command  []string `json:"command"  yaml:"command"`
+}
+
+// AppMigration is a command run to completion in the application image, before a new version
+// of the application starts serving, e.g. `rake db:migrate`. It has the application's
+// environment and bound configurations. The timeout is in seconds, zero uses the default.
+type AppMigration struct {
+	Command []string `json:"command"           yaml:"command"`
+	Timeout int64    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// AppHook is a command run to completion in the application image, with the application's
+// environment and bound configurations, at a phase of the deployment of a new revision:
+// `pre-deploy` before the revision is deployed, `post-deploy` after it is deployed, and
+// `post-rollback` after a rollback to it. A failed pre-deploy hook fails the deployment,
+// leaving the current version serving. The timeout is in seconds, zero uses the default.
+type AppHook struct {
+	Name    string   `json:"name"              yaml:"name"`
+	Phase   string   `json:"phase"             yaml:"phase"`
+	Command []string `json:"command"           yaml:"command"`
+	Timeout int64    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Deployment hook phases
+const (
+	HookPreDeploy    = "pre-deploy"
+	HookPostDeploy   = "post-deploy"
+	HookPostRollback = "post-rollback"
+)
+
+// AppVolume is a persistent volume mounted into the application's instances at the given
+// path. The size is a kube quantity, and defaults to VolumeSizeDefault. The access mode
+// is `ReadWriteOnce` (default), or `ReadWriteMany`, which is required to share the volume
+// between multiple instances.
+type AppVolume struct {
+	Name       string `json:"name"                 yaml:"name"`
+	Path       string `json:"path"                 yaml:"path"`
+	Size       string `json:"size,omitempty"       yaml:"size,omitempty"`
+	AccessMode string `json:"accessmode,omitempty" yaml:"accessmode,omitempty"`
+}
+
+// AppPort is a raw port of the application exposed outside of the cluster, for services
+// which are not HTTP, e.g. databases, or message brokers. The protocol is `TCP` (default),
+// or `UDP`. The port is exposed through a `NodePort` service (default), or a `LoadBalancer`
+// service. The node port is chosen by the cluster, unless given.
+type AppPort struct {
+	Port     int32  `json:"port"               yaml:"port"`
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Expose   string `json:"expose,omitempty"   yaml:"expose,omitempty"`
+	NodePort int32  `json:"nodeport,omitempty" yaml:"nodeport,omitempty"`
+}
+
+// AppExposedPort is a raw port of the application as exposed by the cluster, with the node
+// port assigned to it, and the address of its load balancer, if any.
+type AppExposedPort struct {
+	AppPort
+	Address string `json:"address,omitempty"`
+}
+
+// AppEntrypoint overrides the start command of the staged image for the application's main
+// process, e.g. to run the image of another application as a worker. The command replaces
+// the image's entrypoint, and the args the image's arguments. In updates a nil part means
+// `no change`, whereas an empty one restores the image's default.
+type AppEntrypoint struct {
+	Command []string `json:"command" yaml:"command,omitempty"`
+	Args    []string `json:"args"    yaml:"args,omitempty"`
+}
+
+// AppTermination controls how the instances of an application shut down during redeploys and
+// scale-downs, e.g. to drain websockets, or queues. The PreStop command is run in the
+// instance before it is sent the termination signal. The grace period in seconds bounds the
+// time from the start of the shutdown, PreStop included, until the instance is killed. Zero
+// uses the cluster default of 30 seconds.
+type AppTermination struct {
+	GracePeriod int64    `json:"graceperiod,omitempty" yaml:"graceperiod,omitempty"`
+	PreStop     []string `json:"prestop,omitempty"     yaml:"prestop,omitempty"`
+}
+
+// AppPlacement constrains the nodes the instances of an application are scheduled on, e.g.
+// for GPU or compliance-bound workloads. The node selector requires node labels. The
+// tolerations allow scheduling onto tainted nodes. The topology spread constraints spread the
+// instances across zones, nodes, etc.
+type AppPlacement struct {
+	NodeSelector   map[string]string   `json:"nodeselector,omitempty"   yaml:"nodeselector,omitempty"`
+	Tolerations    []AppToleration     `json:"tolerations,omitempty"    yaml:"tolerations,omitempty"`
+	TopologySpread []AppTopologySpread `json:"topologyspread,omitempty" yaml:"topologyspread,omitempty"`
+}
+
+// AppToleration allows the instances of an application onto nodes with a matching taint. The
+// operator is `Equal` (default), or `Exists`, matching any value. An empty effect matches all
+// effects. The seconds limit how long a `NoExecute` taint is tolerated, nil tolerates it
+// forever.
+type AppToleration struct {
+	Key               string `json:"key,omitempty"               yaml:"key,omitempty"`
+	Operator          string `json:"operator,omitempty"          yaml:"operator,omitempty"`
+	Value             string `json:"value,omitempty"             yaml:"value,omitempty"`
+	Effect            string `json:"effect,omitempty"            yaml:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"tolerationseconds,omitempty" yaml:"tolerationseconds,omitempty"`
+}
+
+// AppTopologySpread spreads the instances of an application across the domains of the
+// topology key, e.g. `topology.kubernetes.io/zone`, with at most MaxSkew (default 1) more
+// instances in one domain than in another. WhenUnsatisfiable is `DoNotSchedule` (default),
+// or `ScheduleAnyway`.
+type AppTopologySpread struct {
+	TopologyKey       string `json:"topologykey"                 yaml:"topologykey"`
+	MaxSkew           int32  `json:"maxskew,omitempty"           yaml:"maxskew,omitempty"`
+	WhenUnsatisfiable string `json:"whenunsatisfiable,omitempty" yaml:"whenunsatisfiable,omitempty"`
+}
+
+// AppSidecar is an additional container run in the application's pods, next to the main
+// container, e.g. a sql proxy, or a log shipper. It may mount configurations bound to the
+// application.
+type AppSidecar struct {
+	Name   string            `json:"name"             yaml:"name"`
+	Image  string            `json:"image"            yaml:"image"`
+	Env    EnvVariableMap    `json:"env,omitempty"    yaml:"env,omitempty"`
+	Mounts []AppSidecarMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+}
+
+// AppSidecarMount makes a configuration bound to the application available to a sidecar,
+// under the given path.
+type AppSidecarMount struct {
+	Configuration string `json:"configuration" yaml:"configuration"`
+	Path          string `json:"path"          yaml:"path"`
+}
+
+// AppDomainRequest represents and contains the data needed to add a custom domain to an
+// application.
+type AppDomainRequest struct {
+	Domain string `json:"domain"`
+}
+
+// AppTaskRunResponse represents the server's response to a manual run of a task. It names the
+// job running the task.
+type AppTaskRunResponse struct {
+	Job string `json:"job"`
 }
 
 type ImportGitResponse struct {
@@ -181,17 +452,71 @@ type UploadResponse struct {
 	BlobUID string `json:"blobuid,omitempty"`
 }
 
+// UploadStartResponse represents the server's response to the start of an app sources upload
+// in parts. The parts are sent for the blob and upload ids, and assembled into the blob when
+// all are sent.
+type UploadStartResponse struct {
+	BlobUID  string `json:"blobuid"`
+	UploadID string `json:"uploadid"`
+}
+
+// SourceFile is an entry of app sources uploaded as delta, i.e. as the list of their files,
+// with the contents of regular files given by the sha256 hashes of their chunks. Only the
+// chunks not known to the server are uploaded. The path is relative to the sources, with
+// slashes. The mode holds the type and permission bits of the entry, as per `os.FileMode`.
+// Symbolic links have a target instead of chunks.
+type SourceFile struct {
+	Path   string   `json:"path"`
+	Mode   uint32   `json:"mode"`
+	Size   int64    `json:"size,omitempty"`
+	Chunks []string `json:"chunks,omitempty"`
+	Link   string   `json:"link,omitempty"`
+}
+
+// SourceChunksRequest holds the hashes of the chunks of app sources to upload as delta.
+type SourceChunksRequest struct {
+	Chunks []string `json:"chunks"`
+}
+
+// SourceChunksResponse holds the hashes of the requested chunks the server does not have,
+// i.e. which have to be uploaded.
+type SourceChunksResponse struct {
+	Missing []string `json:"missing"`
+}
+
+// SourceDeltaRequest holds the files of app sources uploaded as delta. The server assembles
+// the sources tarball from them, and their chunks.
+type SourceDeltaRequest struct {
+	Files []SourceFile `json:"files"`
+}
+
 // StageRequest represents and contains the data needed to stage an application
+// A Dockerfile path selects a Dockerfile build of the sources, instead of buildpacks.
+// The environment holds the build-time variables. Without them the variables of the
+// last staging are used.
 type StageRequest struct {
-	App          AppRef `json:"app,omitempty"`
-	BlobUID      string `json:"blobuid,omitempty"`
-	BuilderImage string `json:"builderimage,omitempty"`
+	App          AppRef         `json:"app,omitempty"`
+	BlobUID      string         `json:"blobuid,omitempty"`
+	BuilderImage string         `json:"builderimage,omitempty"`
+	Buildpacks   []string       `json:"buildpacks,omitempty"`
+	Dockerfile   string         `json:"dockerfile,omitempty"`
+	Cache        string         `json:"cache,omitempty"`
+	Environment  EnvVariableMap `json:"environment,omitempty"`
 }
 
 // StageResponse represents the server's response to a successful app staging
+// A non-zero queue position indicates that the staging job waits for a free slot.
 type StageResponse struct {
-	Stage    StageRef `json:"stage,omitempty"`
-	ImageURL string   `json:"image,omitempty"`
+	Stage         StageRef `json:"stage,omitempty"`
+	ImageURL      string   `json:"image,omitempty"`
+	QueuePosition int      `json:"queueposition,omitempty"`
+	OperationID   string   `json:"operation_id,omitempty"`
+}
+
+// StagingQueueResponse represents the server's response to a query for the queue position
+// of a staging job. A zero position indicates that the job is running, or done.
+type StagingQueueResponse struct {
+	Position int `json:"position"`
 }
 
 // DeployRequest represents and contains the data needed to deploy an application
@@ -199,16 +524,42 @@ type StageResponse struct {
 // already known server side, through AppCreate/AppUpdate requests.
 // This request not only comes with the image to deploy, but also the
 // information where the sources of that image came from.
+// The optional strategy selects how the image is rolled out. The default replaces the
+// running version. StrategyCanary deploys the image next to it instead, with Weight
+// percent of the traffic, rolled back when its error rate reaches ErrorThreshold percent.
 type DeployRequest struct {
-	App      AppRef            `json:"app,omitempty"`
-	Stage    StageRef          `json:"stage,omitempty"`
-	ImageURL string            `json:"image,omitempty"`
-	Origin   ApplicationOrigin `json:"origin,omitempty"`
+	App            AppRef            `json:"app,omitempty"`
+	Stage          StageRef          `json:"stage,omitempty"`
+	ImageURL       string            `json:"image,omitempty"`
+	Origin         ApplicationOrigin `json:"origin,omitempty"`
+	Strategy       string            `json:"strategy,omitempty"`
+	Weight         int               `json:"weight,omitempty"`
+	ErrorThreshold int               `json:"error_threshold,omitempty"`
 }
 
-// DeployResponse represents the server's response to a successful app deployment
+// DeployResponse represents the server's response to a successful app deployment, or, for an
+// asynchronous deployment, the id of the operation doing it
 type DeployResponse struct {
-	Routes []string `json:"routes,omitempty"`
+	Routes      []string `json:"routes,omitempty"`
+	OperationID string   `json:"operation_id,omitempty"`
+}
+
+// ApplicationRenameRequest represents and contains the data needed to rename an application.
+type ApplicationRenameRequest struct {
+	Name string `json:"name"`
+}
+
+// ApplicationRollbackRequest represents and contains the data needed to roll an application
+// back to a previous revision. A zero Revision selects the revision before the current one.
+type ApplicationRollbackRequest struct {
+	Revision int `json:"revision,omitempty"`
+}
+
+// ApplicationCanaryPromoteRequest represents and contains the data needed to promote the
+// canary deployment of an application. A Weight below 100 shifts that percentage of the
+// traffic to the canary. Zero, or 100, makes the canary the new stable version.
+type ApplicationCanaryPromoteRequest struct {
+	Weight int `json:"weight,omitempty"`
 }
 
 // ApplicationDeleteResponse represents the server's response to a successful app deletion
@@ -216,6 +567,51 @@ type ApplicationDeleteResponse struct {
 	UnboundConfigurations []string `json:"unboundconfigurations"`
 }
 
+// BatchDeleteRequest represents and contains the data needed to delete several applications,
+// configurations, or services of a namespace in one call. All deletes all of them, instead of
+// the named ones. Unbind is for configurations and services, see ConfigurationDeleteRequest.
+type BatchDeleteRequest struct {
+	Names  []string `json:"names,omitempty"`
+	All    bool     `json:"all,omitempty"`
+	Unbind bool     `json:"unbind,omitempty"`
+}
+
+// BatchDeleteResult is the outcome of the deletion of one resource of a batch. Error is empty
+// for deleted resources. The configurations unbound from deleted applications, and the
+// applications bound to configurations and services, are reported as for single deletions.
+type BatchDeleteResult struct {
+	Name                  string   `json:"name"`
+	Error                 string   `json:"error,omitempty"`
+	UnboundConfigurations []string `json:"unboundconfigurations,omitempty"`
+	BoundApps             []string `json:"boundapps,omitempty"`
+}
+
+// BatchDeleteResponse represents the server's response to a batch deletion, with a result per
+// resource, in the order of the request. The response is successful even if deletions failed.
+type BatchDeleteResponse struct {
+	Results []BatchDeleteResult `json:"results"`
+}
+
+// BatchRestartRequest contains the names of the applications of a namespace to restart in one
+// call. All restarts all the active applications, instead of the named ones.
+type BatchRestartRequest struct {
+	Names []string `json:"names,omitempty"`
+	All   bool     `json:"all,omitempty"`
+}
+
+// BatchRestartResult is the outcome of the restart of one application of a batch. Error is
+// empty for restarted applications.
+type BatchRestartResult struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchRestartResponse represents the server's response to a batch restart, with a result per
+// application, in the order of the request. The response is successful even if restarts failed.
+type BatchRestartResponse struct {
+	Results []BatchRestartResult `json:"results"`
+}
+
 // EnvMatchResponse contains the list of names for matching envs
 type EnvMatchResponse struct {
 	Names []string `json:"names,omitempty"`
@@ -240,9 +636,120 @@ type AuthTokenResponse struct {
 	Token string `json:"token,omitempty"`
 }
 
-// NamespaceCreateRequest contains the name of the namespace that should be created
+// OIDCConfigResponse names the OIDC issuer, and the client at it, whose ID tokens are
+// accepted by the API
+type OIDCConfigResponse struct {
+	Issuer   string `json:"issuer"`
+	ClientID string `json:"client_id"`
+}
+
+// UserRoleRequest contains the role to give a user, or team, in a namespace. The role is one of
+// reader, developer, or admin. The role "none" removes the user, or team, from the namespace.
+// Users can be given the role temporarily, it expires after the duration, e.g. "72h".
+type UserRoleRequest struct {
+	Namespace string `json:"namespace"`
+	Role      string `json:"role"`
+	Expires   string `json:"expires,omitempty"`
+}
+
+// UserFederationRequest marks a user as federated, i.e. as the same person as the user of the
+// same name at the LDAP server, or the OIDC issuer, or removes the mark.
+type UserFederationRequest struct {
+	Federated bool `json:"federated"`
+}
+
+// PasswordChangeRequest contains the current password of the user, and the new one replacing
+// it.
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordResetRequest contains the new password of a user. Without one a random password is
+// generated.
+type PasswordResetRequest struct {
+	Password string `json:"password,omitempty"`
+}
+
+// PasswordResetResponse returns the new password of the user.
+type PasswordResetResponse struct {
+	Password string `json:"password"`
+}
+
+// Team is a group of users sharing access to namespaces. Its members are Epinio users, and
+// the users of the OIDC groups of the team.
+type Team struct {
+	Name           string            `json:"name"`
+	Members        []string          `json:"members,omitempty"`
+	Groups         []string          `json:"groups,omitempty"`
+	Namespaces     []string          `json:"namespaces,omitempty"`
+	NamespaceRoles map[string]string `json:"namespace_roles,omitempty"`
+}
+
+// TeamList is a collection of teams
+type TeamList []Team
+
+// TeamRequest contains the members, and OIDC groups, of a team. They replace the existing
+// members and groups.
+type TeamRequest struct {
+	Members []string `json:"members,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+}
+
+// Webhook receives the platform events of its types, or all without types, see
+// PlatformEvent. Its secret, signing the events, is not shown.
+type Webhook struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookList is a collection of webhooks
+type WebhookList []Webhook
+
+// WebhookRequest contains the url, secret, and event types of a webhook. They replace those
+// of the existing webhook, except for an empty secret, which keeps the existing one, or
+// generates one for a new webhook.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookSetResponse contains the secret the events posted to the webhook are signed with.
+type WebhookSetResponse struct {
+	Secret string `json:"secret"`
+}
+
+// NamespaceCreateRequest contains the name of the namespace that should be created, and its
+// quota, if any
 type NamespaceCreateRequest struct {
-	Name string `json:"name,omitempty"`
+	Name  string         `json:"name,omitempty"`
+	Quota NamespaceQuota `json:"quota,omitempty"`
+}
+
+// NamespaceUpdateRequest contains the new settings of the namespace to update. They replace
+// the existing settings.
+type NamespaceUpdateRequest struct {
+	Settings NamespaceSettings `json:"settings"`
+}
+
+// NamespacePodSecurityRequest contains the pod security level to enforce in the namespace,
+// one of privileged, baseline, or restricted.
+type NamespacePodSecurityRequest struct {
+	Level string `json:"level"`
+}
+
+// NamespaceQuotaRequest contains the new quota of the namespace. An empty quota removes it.
+type NamespaceQuotaRequest struct {
+	Quota NamespaceQuota `json:"quota"`
+}
+
+// NamespaceUploadLimitRequest contains the new size limit of the application sources uploaded
+// to the namespace, as kubernetes quantity, e.g. `2Gi`. Zero is no limit, the empty string
+// restores the default limit of the server.
+type NamespaceUploadLimitRequest struct {
+	Limit string `json:"limit"`
 }
 
 // NamespacesMatchResponse contains the list of names for matching namespaces
@@ -347,12 +854,25 @@ type ServiceListResponse struct {
 
 // AppChart matches github.com/epinio/application/api/v1 AppChartSpec
 // Reason for existence: Do not expose the internal CRD struct in the API.
+// The settings declare the values users may pass into the chart, keyed by name.
 type AppChart struct {
-	Meta             MetaLite `json:"meta,omitempty"`
-	Description      string   `json:"description,omitempty"`
-	ShortDescription string   `json:"short_description,omitempty"`
-	HelmChart        string   `json:"helm_chart,omitempty"`
-	HelmRepo         string   `json:"helm_repo,omitempty"`
+	Meta             MetaLite                   `json:"meta,omitempty"`
+	Description      string                     `json:"description,omitempty"`
+	ShortDescription string                     `json:"short_description,omitempty"`
+	HelmChart        string                     `json:"helm_chart,omitempty"`
+	HelmRepo         string                     `json:"helm_repo,omitempty"`
+	Settings         map[string]AppChartSetting `json:"settings,omitempty"`
+}
+
+// AppChartSetting declares a value users may pass into an app chart, see
+// ApplicationUpdateRequest.ChartValues. The type is one of `string` (default), `bool`,
+// `integer`, or `number`. Integers and numbers may be bounded by minimum and maximum,
+// strings may be restricted to the values of the enum.
+type AppChartSetting struct {
+	Type    string   `json:"type"`
+	Minimum string   `json:"minimum,omitempty"`
+	Maximum string   `json:"maximum,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
 }
 
 // AppChartList is a collection of app charts
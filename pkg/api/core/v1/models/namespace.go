@@ -1,11 +1,85 @@
 package models
 
-// Namespace has all the namespace properties, i.e. name, app names, and configuration names
-// It is used in the CLI and API responses.
+// Namespace has all the namespace properties, i.e. name, app names, configuration names,
+// settings, the enforced pod security level, the quota, and the size limit of uploads. It is
+// used in the CLI and API responses.
 type Namespace struct {
-	Meta           MetaLite `json:"meta,omitempty"`
-	Apps           []string `json:"apps,omitempty"`
-	Configurations []string `json:"configurations,omitempty"`
+	Meta           MetaLite               `json:"meta,omitempty"`
+	Apps           []string               `json:"apps,omitempty"`
+	Configurations []string               `json:"configurations,omitempty"`
+	Settings       NamespaceSettings      `json:"settings,omitempty"`
+	PodSecurity    string                 `json:"pod_security,omitempty"`
+	Quota          map[string]QuotaStatus `json:"quota,omitempty"`
+	UploadLimit    string                 `json:"upload_limit,omitempty"`
+}
+
+// NamespaceQuota limits the resources of a namespace. The keys are `cpu`, `memory` and
+// `storage`, requested by all the pods of the namespace, and `apps`, the number of
+// applications. The values are kubernetes quantities, e.g. `20`, or `64Gi`.
+type NamespaceQuota map[string]string
+
+// QuotaStatus is the limit of a resource of a namespace, and its current usage.
+type QuotaStatus struct {
+	Limit string `json:"limit"`
+	Used  string `json:"used"`
+}
+
+// NamespaceSettings holds the defaults for the applications of a namespace. They are used
+// where an application does not make a choice of its own.
+// The staging concurrency limits the number of staging jobs running at the same time for
+// the namespace. More jobs are queued. Zero means no limit.
+// The log sink, if any, receives the logs of the applications of the namespace.
+// The app chart is used by the applications created without a chart of their own. The
+// allowed app charts, if any, restrict the charts the applications of the namespace may use.
+type NamespaceSettings struct {
+	BuilderImage       string           `json:"builderimage,omitempty"`
+	Buildpacks         []string         `json:"buildpacks,omitempty"`
+	Staging            StagingResources `json:"staging"`
+	StagingConcurrency int              `json:"stagingconcurrency,omitempty"`
+	LogSink            *LogSink         `json:"logsink,omitempty"`
+	AppChart           string           `json:"appchart,omitempty"`
+	AllowedAppCharts   []string         `json:"allowedappcharts,omitempty"`
+}
+
+// AppChartAllowed returns true if the applications of the namespace may use the named app
+// chart. Without restriction all charts are allowed.
+func (s NamespaceSettings) AppChartAllowed(name string) bool {
+	if len(s.AllowedAppCharts) == 0 {
+		return true
+	}
+	for _, allowed := range s.AllowedAppCharts {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LogSink describes the external destination the application logs of a namespace are
+// forwarded to. The type is one of `loki`, `syslog`, or `http`. The url is the push endpoint
+// of a Loki server, the `udp://` or `tcp://` address of a syslog server, or the endpoint
+// receiving the log lines as JSON posts.
+type LogSink struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Log sink types
+const (
+	LogSinkLoki   = "loki"
+	LogSinkSyslog = "syslog"
+	LogSinkHTTP   = "http"
+)
+
+// StagingResources holds the compute resources (requests and limits, as kubernetes
+// quantities) of staging jobs, and the time (in seconds) they may run before they are
+// stopped. Empty values defer to the global settings.
+type StagingResources struct {
+	CPURequest    string `json:"cpurequest,omitempty"`
+	CPULimit      string `json:"cpulimit,omitempty"`
+	MemoryRequest string `json:"memoryrequest,omitempty"`
+	MemoryLimit   string `json:"memorylimit,omitempty"`
+	Timeout       int64  `json:"timeout,omitempty"`
 }
 
 // NamespaceList is a collection of namespaces
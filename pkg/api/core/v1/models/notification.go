@@ -0,0 +1,34 @@
+package models
+
+// Notification target types
+const (
+	NotificationSlack   = "slack"
+	NotificationTeams   = "teams"
+	NotificationGeneric = "generic"
+)
+
+// NotificationTarget is a chat channel, or other receiver, of messages about the events of a
+// namespace, see PlatformEvent. The type is one of `slack`, `teams`, or `generic`, and the url
+// the incoming webhook of the channel, or the endpoint receiving the generic messages as JSON
+// posts. Without events the target receives the outcomes of pushes, and of the provisioning
+// of services. A type also stands for the types starting with it, e.g. `app` for all events of
+// apps. The template, if any, replaces the default message. It is a Go template of the event.
+type NotificationTarget struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	URL      string   `json:"url"`
+	Events   []string `json:"events,omitempty"`
+	Template string   `json:"template,omitempty"`
+}
+
+// NotificationTargetList is a collection of notification targets
+type NotificationTargetList []NotificationTarget
+
+// NotificationTargetRequest contains the settings of a notification target. They replace
+// those of the existing target.
+type NotificationTargetRequest struct {
+	Type     string   `json:"type"`
+	URL      string   `json:"url"`
+	Events   []string `json:"events,omitempty"`
+	Template string   `json:"template,omitempty"`
+}
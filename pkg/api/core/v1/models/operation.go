@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Kinds of long-running operations
+const (
+	OperationStage         = "stage"
+	OperationDeploy        = "deploy"
+	OperationServiceCreate = "service.create"
+)
+
+// OperationPhase is the state of a long-running operation.
+type OperationPhase string
+
+// Phases of long-running operations. Succeeded, and failed, are final.
+const (
+	OperationPending   OperationPhase = "pending"
+	OperationRunning   OperationPhase = "running"
+	OperationSucceeded OperationPhase = "succeeded"
+	OperationFailed    OperationPhase = "failed"
+)
+
+// Done returns true for the final phases.
+func (p OperationPhase) Done() bool {
+	return p == OperationSucceeded || p == OperationFailed
+}
+
+// Operation is a long-running operation of the server, e.g. a staging, or the provisioning of
+// a service, which goes on independent of the request starting it. The name is the one of the
+// app, or service, it works on. The progress describes the current step. A failed operation
+// has the title, and details, of its error. The details depend on the kind, e.g. the routes of
+// a deployment.
+type Operation struct {
+	ID           string            `json:"id"`
+	Kind         string            `json:"kind"`
+	Namespace    string            `json:"namespace"`
+	Name         string            `json:"name"`
+	User         string            `json:"user,omitempty"`
+	Phase        OperationPhase    `json:"phase"`
+	Progress     string            `json:"progress,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	ErrorDetails string            `json:"error_details,omitempty"`
+	Details      map[string]string `json:"details,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// ServiceCreateResponse is the response of the creation of a service, with the operation
+// tracking its provisioning.
+type ServiceCreateResponse struct {
+	Response
+	OperationID string `json:"operation_id,omitempty"`
+}
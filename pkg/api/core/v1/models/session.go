@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SessionResponse contains the tokens of a session of the user. The token is sent as bearer
+// token until it expires, then the refresh token gets a new one, until the session expires.
+type SessionResponse struct {
+	Token            string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// SessionRefreshRequest contains the refresh token of the session to get a new token for
+type SessionRefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// PlatformState is the export format of the state of an Epinio installation, for its migration
+// to another one: the namespaces with their configurations and apps, and the users. Registry
+// is the registry, with namespace, of the images staged by the installation, and Domain its
+// main domain, i.e. the domain of the default routes of the apps. The configurations created
+// by services are not exported, neither are the services.
+type PlatformState struct {
+	Version    string           `json:"version"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Registry   string           `json:"registry,omitempty"`
+	Domain     string           `json:"domain,omitempty"`
+	Namespaces []NamespaceState `json:"namespaces"`
+	Users      []UserState      `json:"users"`
+}
+
+// NamespaceState is the exported state of a namespace.
+type NamespaceState struct {
+	Name           string               `json:"name"`
+	Configurations []ConfigurationState `json:"configurations"`
+	Apps           []AppState           `json:"apps"`
+}
+
+// ConfigurationState is the exported state of a configuration, with its data.
+type ConfigurationState struct {
+	Name string            `json:"name"`
+	Data map[string]string `json:"data"`
+}
+
+// AppState is the exported state of an app, i.e. its image, and its configuration, including
+// the bindings to configurations.
+type AppState struct {
+	Name          string                   `json:"name"`
+	ImageURL      string                   `json:"image,omitempty"`
+	Configuration ApplicationUpdateRequest `json:"configuration"`
+}
+
+// UserState is the exported state of a user, with its password, and its roles, by namespace.
+// Federated users are also those of the LDAP server, or the OIDC issuer, of the same name.
+type UserState struct {
+	Username   string            `json:"username"`
+	Password   string            `json:"password"`
+	Role       string            `json:"role"`
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+	Federated  bool              `json:"federated,omitempty"`
+}
+
+// UserImportRequest contains the users to create.
+type UserImportRequest struct {
+	Users []UserState `json:"users"`
+}
+
+// UserImportResponse names the users created, and those skipped, as they exist already.
+type UserImportResponse struct {
+	Created []string `json:"created"`
+	Skipped []string `json:"skipped,omitempty"`
+}
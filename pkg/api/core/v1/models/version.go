@@ -0,0 +1,17 @@
+package models
+
+// APIVersion describes a version of the API served by the server. Routes names the endpoints of
+// the version, and Deprecated those which have a successor in a later version. Deprecated
+// endpoints are removed from the version at Sunset, an RFC 3339 date, if set.
+type APIVersion struct {
+	Version    string   `json:"version"`
+	Root       string   `json:"root"`
+	Routes     []string `json:"routes"`
+	Deprecated []string `json:"deprecated,omitempty"`
+	Sunset     string   `json:"sunset,omitempty"`
+}
+
+// APIVersionsResponse lists the versions of the API served by the server, oldest first.
+type APIVersionsResponse struct {
+	Versions []APIVersion `json:"versions"`
+}
@@ -0,0 +1,37 @@
+// Package rpc contains the Go bindings of the gRPC API of Epinio, generated from epinio.proto,
+// and the credentials of its clients.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative epinio.proto
+
+import (
+	"context"
+	"encoding/base64"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// authorization is the authorization metadata of the calls, for grpc.WithPerRPCCredentials.
+type authorization string
+
+var _ credentials.PerRPCCredentials = authorization("")
+
+// BasicAuth returns the credentials of the user, to dial the server with, as
+// grpc.WithPerRPCCredentials(rpc.BasicAuth(user, password)). They are sent over TLS only.
+func BasicAuth(user, password string) credentials.PerRPCCredentials {
+	return authorization("Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+}
+
+// TokenAuth returns the credentials of the bearer token, an API token, or an OIDC ID token,
+// like BasicAuth.
+func TokenAuth(token string) credentials.PerRPCCredentials {
+	return authorization("Bearer " + token)
+}
+
+func (a authorization) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": string(a)}, nil
+}
+
+func (a authorization) RequireTransportSecurity() bool {
+	return true
+}
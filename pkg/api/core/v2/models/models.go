@@ -0,0 +1,12 @@
+// Package models contains the types of the requests and responses of the API v2 which differ
+// from those of v1. All other endpoints of v2 use the types of v1.
+package models
+
+// Page is the response of the list endpoints, a page of the matching items. Total is the
+// number of matching items, over all pages.
+type Page struct {
+	Items  interface{} `json:"items"`
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit,omitempty"`
+}